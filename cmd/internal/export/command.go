@@ -0,0 +1,80 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/googleapis/genai-toolbox/cmd/internal"
+	"github.com/googleapis/genai-toolbox/internal/server"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(opts *internal.ToolboxOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Print the configuration of the loaded tools",
+		Long: `Print the configuration of the tools as the server loads them, after
+defaults and normalization are applied, as YAML. Parameters that tools generate
+are described in comments.
+Example:
+  toolbox export --tools-file tools.yaml`,
+		Args: cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			return runExport(c, opts)
+		},
+	}
+	return cmd
+}
+
+func runExport(cmd *cobra.Command, opts *internal.ToolboxOptions) error {
+	ctx, cancel := context.WithCancel(cmd.Context())
+	defer cancel()
+
+	// The configuration is the only output of the command, so the logs are
+	// written to the error stream.
+	out := opts.IOStreams.Out
+	opts.IOStreams.Out = opts.IOStreams.ErrOut
+	ctx, shutdown, err := opts.Setup(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = shutdown(ctx)
+	}()
+
+	_, err = opts.LoadConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, _, _, toolsMap, _, _, _, err := server.InitializeConfigs(ctx, opts.Cfg)
+	if err != nil {
+		errMsg := fmt.Errorf("failed to initialize resources: %w", err)
+		opts.Logger.ErrorContext(ctx, errMsg.Error())
+		return errMsg
+	}
+
+	output, err := server.ExportToolConfigs(toolsMap)
+	if err != nil {
+		errMsg := fmt.Errorf("failed to export tools: %w", err)
+		opts.Logger.ErrorContext(ctx, errMsg.Error())
+		return errMsg
+	}
+	fmt.Fprint(out, string(output))
+
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/googleapis/genai-toolbox/cmd/internal"
+	_ "github.com/googleapis/genai-toolbox/internal/sources/sqlite"
+	_ "github.com/googleapis/genai-toolbox/internal/tools/sqlite/sqlitesql"
+	"github.com/spf13/cobra"
+)
+
+func exportCommand(args []string) (string, error) {
+	parentCmd := &cobra.Command{Use: "toolbox"}
+
+	buf := new(bytes.Buffer)
+	errBuf := new(bytes.Buffer)
+	opts := internal.NewToolboxOptions(internal.WithIOStreams(buf, errBuf))
+	internal.PersistentFlags(parentCmd, opts)
+
+	cmd := NewCommand(opts)
+	parentCmd.AddCommand(cmd)
+	parentCmd.SetArgs(args)
+
+	err := parentCmd.Execute()
+	return buf.String(), err
+}
+
+func TestExportTools(t *testing.T) {
+	tmpDir := t.TempDir()
+	toolsFileContent := `
+kind: sources
+name: my-sqlite
+type: sqlite
+database: test.db
+---
+kind: tools
+name: hello-sqlite
+type: sqlite-sql
+source: my-sqlite
+description: hello tool
+statement: SELECT 'hello' as greeting
+timeout: 10s
+`
+	toolsFilePath := filepath.Join(tmpDir, "tools.yaml")
+	if err := os.WriteFile(toolsFilePath, []byte(toolsFileContent), 0644); err != nil {
+		t.Fatalf("failed to write tools file: %v", err)
+	}
+
+	got, err := exportCommand([]string{"export", "--tools-file", toolsFilePath})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"kind: tools\n", "name: hello-sqlite\n", "statement: SELECT 'hello' as greeting\n", "timeout: 10s\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected export to contain %q, got:\n%s", want, got)
+		}
+	}
+	// The logs are not mixed with the exported configuration.
+	if strings.Contains(got, "INFO") {
+		t.Errorf("expected export to contain no logs, got:\n%s", got)
+	}
+}
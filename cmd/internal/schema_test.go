@@ -0,0 +1,79 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/googleapis/genai-toolbox/internal/prebuiltconfigs"
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	"github.com/googleapis/genai-toolbox/internal/testutils"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+)
+
+// TestToolInputSchemas checks that the MCP input schema of every tool of the
+// prebuilt configs is a valid JSON Schema draft-07 schema. Tools are
+// initialized without their sources, so tools that need their source to build
+// their parameters are skipped.
+func TestToolInputSchemas(t *testing.T) {
+	// prebuilt configs whose sources cannot be parsed without real settings
+	needRealSettings := map[string]string{
+		// the password defaults to empty, but the postgres source requires it
+		"alloydb-omni": "ALLOYDB_OMNI_PASSWORD",
+	}
+	ctx, err := testutils.ContextWithNewLogger()
+	if err != nil {
+		t.Fatalf("unable to create logger: %s", err)
+	}
+	requiredEnv := regexp.MustCompile(`\$\{(\w+)\}`)
+	checked := make(map[string]bool)
+	for _, name := range prebuiltconfigs.GetPrebuiltSources() {
+		if env, ok := needRealSettings[name]; ok {
+			t.Logf("skipping prebuilt config %q, which needs %s to be set", name, env)
+			continue
+		}
+		raw, err := prebuiltconfigs.Get(name)
+		if err != nil {
+			t.Fatalf("unable to get prebuilt config %q: %s", name, err)
+		}
+		for _, m := range requiredEnv.FindAllStringSubmatch(string(raw), -1) {
+			t.Setenv(m[1], "1")
+		}
+		toolsFile, err := parseToolsFile(ctx, raw)
+		if err != nil {
+			t.Fatalf("unable to parse prebuilt config %q: %s", name, err)
+		}
+		for toolName, cfg := range toolsFile.Tools {
+			var tool tools.Tool
+			if c, ok := cfg.(tools.ToolConfigWithContext); ok {
+				tool, err = c.InitializeWithContext(ctx, map[string]sources.Source{})
+			} else {
+				tool, err = cfg.Initialize(map[string]sources.Source{})
+			}
+			if err != nil {
+				continue
+			}
+			if err := testutils.ValidateJSONSchema(tool.McpManifest().InputSchema); err != nil {
+				t.Errorf("invalid input schema of tool %q of prebuilt config %q: %s", toolName, name, err)
+			}
+			checked[cfg.ToolConfigType()] = true
+		}
+	}
+	if len(checked) == 0 {
+		t.Fatalf("no tool was checked")
+	}
+	t.Logf("checked the input schemas of %d tool types", len(checked))
+}
@@ -64,16 +64,15 @@ func parseEnv(input string) (string, error) {
 // parseToolsFile parses the provided yaml into appropriate configs.
 func parseToolsFile(ctx context.Context, raw []byte) (ToolsFile, error) {
 	var toolsFile ToolsFile
-	// Replace environment variables if found
-	output, err := parseEnv(string(raw))
+	raw, err := convertToolsFile(raw)
 	if err != nil {
-		return toolsFile, fmt.Errorf("error parsing environment variables: %s", err)
+		return toolsFile, fmt.Errorf("error converting tools file: %s", err)
 	}
-	raw = []byte(output)
 
-	raw, err = convertToolsFile(raw)
+	// Replace environment variables if found
+	raw, err = parseEnvOutsideTools(raw)
 	if err != nil {
-		return toolsFile, fmt.Errorf("error converting tools file: %s", err)
+		return toolsFile, fmt.Errorf("error parsing environment variables: %s", err)
 	}
 
 	// Parse contents
@@ -84,6 +83,38 @@ func parseToolsFile(ctx context.Context, raw []byte) (ToolsFile, error) {
 	return toolsFile, nil
 }
 
+// parseEnvOutsideTools applies parseEnv to the documents of the tools file
+// raw, except those of tools. The fields of tools opt into the expansion of
+// environment variables, so that values such as SQL statements are kept as
+// is, see tools.ExpandConfig.
+func parseEnvOutsideTools(raw []byte) ([]byte, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(raw), yaml.UseOrderedMap())
+	var buf bytes.Buffer
+	for {
+		var doc yaml.MapSlice
+		if err := decoder.Decode(&doc); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		b, err := yaml.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		if kind, _ := doc.ToMap()["kind"].(string); kind != "tools" {
+			output, err := parseEnv(string(b))
+			if err != nil {
+				return nil, err
+			}
+			b = []byte(output)
+		}
+		buf.WriteString("---\n")
+		buf.Write(b)
+	}
+	return buf.Bytes(), nil
+}
+
 func convertToolsFile(raw []byte) ([]byte, error) {
 	var input yaml.MapSlice
 	decoder := yaml.NewDecoder(bytes.NewReader(raw), yaml.UseOrderedMap())
@@ -92,7 +123,7 @@ func convertToolsFile(raw []byte) ([]byte, error) {
 	var buf bytes.Buffer
 	encoder := yaml.NewEncoder(&buf)
 
-	v1keys := []string{"sources", "authSources", "authServices", "embeddingModels", "tools", "toolsets", "prompts"}
+	v1keys := []string{"sources", "authSources", "authServices", "embeddingModels", "tools", "toolsets", "prompts", "policies"}
 	for {
 		if err := decoder.Decode(&input); err != nil {
 			if err == io.EOF {
@@ -77,6 +77,11 @@ func TestParseEnv(t *testing.T) {
 			in:   "${FOO:bar}",
 			want: "hello",
 		},
+		{
+			desc: "with default starting with a dash",
+			in:   "${FOO:-bar}",
+			want: "-bar",
+		},
 	}
 	for _, tc := range tcs {
 		t.Run(tc.desc, func(t *testing.T) {
@@ -198,6 +203,32 @@ type: gemini
 model: gemini-embedding-001
 apiKey: some-key
 dimension: 768
+`,
+		},
+		{
+			desc: "convert policies",
+			in: `
+            policies:
+                analytics:
+                    writeMode: blocked
+                    allowedDatasets:
+                        - my-project.sales
+            sources:
+                my-bigquery:
+                    kind: bigquery
+                    project: my-project
+                    policy: analytics`,
+			want: `kind: policies
+name: analytics
+writeMode: blocked
+allowedDatasets:
+- my-project.sales
+---
+kind: sources
+name: my-bigquery
+type: bigquery
+project: my-project
+policy: analytics
 `,
 		},
 		{
@@ -1460,6 +1491,43 @@ func TestEnvVarReplacement(t *testing.T) {
 	}
 }
 
+func TestEnvVarReplacementOutsideTools(t *testing.T) {
+	ctx, err := testutils.ContextWithNewLogger()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	t.Setenv("PG_PROJECT", "my-project")
+	in := `
+	kind: sources
+	name: my-pg-instance
+	type: cloud-sql-postgres
+	project: ${PG_PROJECT}
+	region: my-region
+	instance: my-instance
+	database: my_db
+	user: my_user
+	password: my_pass
+---
+	kind: tools
+	name: example_tool
+	type: postgres-sql
+	source: my-pg-instance
+	description: some description
+	statement: SELECT '${PG_UNSET}';
+	`
+	toolsFile, err := parseToolsFile(ctx, testutils.FormatYaml(in))
+	if err != nil {
+		t.Fatalf("failed to parse input: %v", err)
+	}
+	if got := toolsFile.Sources["my-pg-instance"].(cloudsqlpgsrc.Config).Project; got != "my-project" {
+		t.Errorf("incorrect source project: got %q, want %q", got, "my-project")
+	}
+	// Fields of tools are only expanded when they opt in.
+	if got := toolsFile.Tools["example_tool"].(postgressql.Config).Statement; got != "SELECT '${PG_UNSET}';" {
+		t.Errorf("incorrect tool statement: got %q", got)
+	}
+}
+
 func TestPrebuiltTools(t *testing.T) {
 	// Get prebuilt configs
 	alloydb_omni_config, _ := prebuiltconfigs.Get("alloydb-omni")
@@ -0,0 +1,85 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/googleapis/genai-toolbox/cmd/internal"
+	"github.com/googleapis/genai-toolbox/internal/server"
+	"github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(opts *internal.ToolboxOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Check the BigQuery sources and tools against the live service",
+		Long: `Check the credentials and allowed datasets of the BigQuery sources, and the
+statements of their tools with dry runs, without running any query. All the
+checks are printed as JSON, and the command fails if any of them fails, so
+that it can gate a deployment.
+Example:
+  toolbox validate --tools-file tools.yaml`,
+		Args: cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			return runValidate(c, opts)
+		},
+	}
+	return cmd
+}
+
+func runValidate(cmd *cobra.Command, opts *internal.ToolboxOptions) error {
+	ctx, cancel := context.WithCancel(cmd.Context())
+	defer cancel()
+
+	// The report is the only output of the command, so the logs are written
+	// to the error stream.
+	out := opts.IOStreams.Out
+	opts.IOStreams.Out = opts.IOStreams.ErrOut
+	ctx, shutdown, err := opts.Setup(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = shutdown(ctx)
+	}()
+
+	_, err = opts.LoadConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	sourcesMap, _, _, toolsMap, _, _, _, err := server.InitializeConfigs(ctx, opts.Cfg)
+	if err != nil {
+		errMsg := fmt.Errorf("failed to initialize resources: %w", err)
+		opts.Logger.ErrorContext(ctx, errMsg.Error())
+		return errMsg
+	}
+
+	report := bigquerycommon.ValidateTools(ctx, sourcesMap, toolsMap)
+	output, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode the report: %w", err)
+	}
+	fmt.Fprintln(out, string(output))
+
+	if !report.OK {
+		return fmt.Errorf("validation failed")
+	}
+	return nil
+}
@@ -32,13 +32,11 @@ import (
 	"github.com/fsnotify/fsnotify"
 	// Importing the cmd/internal package also import packages for side effect of registration
 	"github.com/googleapis/genai-toolbox/cmd/internal"
+	"github.com/googleapis/genai-toolbox/cmd/internal/export"
 	"github.com/googleapis/genai-toolbox/cmd/internal/invoke"
 	"github.com/googleapis/genai-toolbox/cmd/internal/skills"
-	"github.com/googleapis/genai-toolbox/internal/auth"
-	"github.com/googleapis/genai-toolbox/internal/embeddingmodels"
-	"github.com/googleapis/genai-toolbox/internal/prompts"
+	"github.com/googleapis/genai-toolbox/cmd/internal/validate"
 	"github.com/googleapis/genai-toolbox/internal/server"
-	"github.com/googleapis/genai-toolbox/internal/sources"
 	"github.com/googleapis/genai-toolbox/internal/tools"
 	"github.com/googleapis/genai-toolbox/internal/util"
 	"github.com/spf13/cobra"
@@ -126,6 +124,11 @@ func NewCommand(opts *internal.ToolboxOptions) *cobra.Command {
 	flags.StringSliceVar(&opts.Cfg.AllowedOrigins, "allowed-origins", []string{"*"}, "Specifies a list of origins permitted to access this server. Defaults to '*'.")
 	flags.StringSliceVar(&opts.Cfg.AllowedHosts, "allowed-hosts", []string{"*"}, "Specifies a list of hosts permitted to access this server. Defaults to '*'.")
 	flags.IntVar(&opts.Cfg.PollInterval, "poll-interval", 0, "Specifies the polling frequency (seconds) for configuration file updates.")
+	flags.IntVar(&opts.Cfg.EmbeddingCacheSize, "embedding-cache-size", 0, "Memory (MiB) used to cache the embeddings of parameters across tools. Disabled if 0.")
+	flags.DurationVar(&opts.Cfg.EmbeddingCacheTTL, "embedding-cache-ttl", time.Hour, "How long embeddings are cached when --embedding-cache-size is set.")
+	flags.BoolVar(&opts.Cfg.RequireDestructiveConfirmation, "require-destructive-confirmation", false, "Refuse to invoke tools annotated as destructive unless the request explicitly confirms the invocation.")
+	flags.IntVar(&opts.Cfg.MaxStreamBufferedChunks, "max-stream-buffered-chunks", tools.DefaultMaxBufferedChunks, "Number of chunks of a streamed tool result buffered for the client before the tool is paused.")
+	flags.BoolVar(&opts.Cfg.StrictBearerTokens, "strict-bearer-tokens", false, "Require client access tokens to be sent as 'Bearer <token>', rejecting tokens sent without a scheme.")
 
 	// wrap RunE command so that we have access to original Command object
 	cmd.RunE = func(*cobra.Command, []string) error { return run(cmd, opts) }
@@ -134,6 +137,10 @@ func NewCommand(opts *internal.ToolboxOptions) *cobra.Command {
 	cmd.AddCommand(invoke.NewCommand(opts))
 	// Register subcommands for skill generation
 	cmd.AddCommand(skills.NewCommand(opts))
+	// Register subcommands for configuration export
+	cmd.AddCommand(export.NewCommand(opts))
+	// Register subcommands for configuration validation
+	cmd.AddCommand(validate.NewCommand(opts))
 
 	return cmd
 }
@@ -144,28 +151,6 @@ func handleDynamicReload(ctx context.Context, toolsFile internal.ToolsFile, s *s
 		panic(err)
 	}
 
-	sourcesMap, authServicesMap, embeddingModelsMap, toolsMap, toolsetsMap, promptsMap, promptsetsMap, err := validateReloadEdits(ctx, toolsFile)
-	if err != nil {
-		errMsg := fmt.Errorf("unable to validate reloaded edits: %w", err)
-		logger.WarnContext(ctx, errMsg.Error())
-		return err
-	}
-
-	s.ResourceMgr.SetResources(sourcesMap, authServicesMap, embeddingModelsMap, toolsMap, toolsetsMap, promptsMap, promptsetsMap)
-
-	return nil
-}
-
-// validateReloadEdits checks that the reloaded tools file configs can initialized without failing
-func validateReloadEdits(
-	ctx context.Context, toolsFile internal.ToolsFile,
-) (map[string]sources.Source, map[string]auth.AuthService, map[string]embeddingmodels.EmbeddingModel, map[string]tools.Tool, map[string]tools.Toolset, map[string]prompts.Prompt, map[string]prompts.Promptset, error,
-) {
-	logger, err := util.LoggerFromContext(ctx)
-	if err != nil {
-		panic(err)
-	}
-
 	instrumentation, err := util.InstrumentationFromContext(ctx)
 	if err != nil {
 		panic(err)
@@ -186,14 +171,15 @@ func validateReloadEdits(
 		PromptConfigs:         toolsFile.Prompts,
 	}
 
-	sourcesMap, authServicesMap, embeddingModelsMap, toolsMap, toolsetsMap, promptsMap, promptsetsMap, err := server.InitializeConfigs(ctx, reloadedConfig)
-	if err != nil {
-		errMsg := fmt.Errorf("unable to initialize reloaded configs: %w", err)
+	// sources and tools that fail to initialize keep their previous instance,
+	// and the rest of the reload is applied
+	if err := server.ReloadResources(ctx, reloadedConfig, s.ResourceMgr); err != nil {
+		errMsg := fmt.Errorf("unable to validate reloaded edits: %w", err)
 		logger.WarnContext(ctx, errMsg.Error())
-		return nil, nil, nil, nil, nil, nil, nil, err
+		return err
 	}
 
-	return sourcesMap, authServicesMap, embeddingModelsMap, toolsMap, toolsetsMap, promptsMap, promptsetsMap, nil
+	return nil
 }
 
 // Helper to check if a file has a newer ModTime than stored in the map
@@ -36,6 +36,7 @@ import (
 	"github.com/googleapis/genai-toolbox/internal/server"
 	"github.com/googleapis/genai-toolbox/internal/telemetry"
 	"github.com/googleapis/genai-toolbox/internal/testutils"
+	"github.com/googleapis/genai-toolbox/internal/tools"
 	"github.com/googleapis/genai-toolbox/internal/util"
 	"github.com/spf13/cobra"
 )
@@ -63,6 +64,12 @@ func withDefaults(c server.ServerConfig) server.ServerConfig {
 	if c.UserAgentMetadata == nil {
 		c.UserAgentMetadata = []string{}
 	}
+	if c.EmbeddingCacheTTL == 0 {
+		c.EmbeddingCacheTTL = time.Hour
+	}
+	if c.MaxStreamBufferedChunks == 0 {
+		c.MaxStreamBufferedChunks = tools.DefaultMaxBufferedChunks
+	}
 	return c
 }
 
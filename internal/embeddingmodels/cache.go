@@ -0,0 +1,217 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embeddingmodels
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// cacheEntryOverhead approximates the memory used by a cache entry besides
+// its key and vector.
+const cacheEntryOverhead = 128
+
+// CacheKey identifies an embedding in a Cache.
+type CacheKey struct {
+	Model                string
+	TaskType             string
+	OutputDimensionality int32
+	Text                 string
+}
+
+type cacheEntry struct {
+	key     CacheKey
+	vector  []float32
+	size    int64
+	expires time.Time
+}
+
+// Cache is a least recently used cache of embeddings, bounded by the memory
+// used by its entries. It is safe for concurrent use.
+type Cache struct {
+	maxBytes int64
+	ttl      time.Duration
+	// lookups counts cache lookups, with a "hit" attribute. It may be nil.
+	lookups metric.Int64Counter
+	// now returns the current time. It can be overridden for testing.
+	now func() time.Time
+
+	mu      sync.Mutex
+	bytes   int64
+	order   *list.List // of *cacheEntry, most recently used first
+	entries map[CacheKey]*list.Element
+}
+
+// NewCache returns a Cache holding up to maxBytes of embeddings, each for at
+// most ttl. A ttl of zero keeps entries until they are evicted. Lookups are
+// counted in lookups, if not nil.
+func NewCache(maxBytes int64, ttl time.Duration, lookups metric.Int64Counter) *Cache {
+	return &Cache{
+		maxBytes: maxBytes,
+		ttl:      ttl,
+		lookups:  lookups,
+		now:      time.Now,
+		order:    list.New(),
+		entries:  make(map[CacheKey]*list.Element),
+	}
+}
+
+// NormalizeText returns the form of text used in cache keys, with leading and
+// trailing whitespace removed and inner whitespace collapsed.
+func NormalizeText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// Get returns the embedding cached for key, if any.
+func (c *Cache) Get(ctx context.Context, key CacheKey) ([]float32, bool) {
+	c.mu.Lock()
+	vector, ok := c.get(key)
+	c.mu.Unlock()
+	if c.lookups != nil {
+		c.lookups.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("model_name", key.Model),
+			attribute.Bool("hit", ok),
+		))
+	}
+	return vector, ok
+}
+
+func (c *Cache) get(key CacheKey) ([]float32, bool) {
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if !entry.expires.IsZero() && !c.now().Before(entry.expires) {
+		c.remove(elem)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return entry.vector, true
+}
+
+// Add caches vector for key, evicting the least recently used entries if the
+// cache is full. Vectors larger than the cache are not cached.
+func (c *Cache) Add(key CacheKey, vector []float32) {
+	size := int64(4*len(vector)+len(key.Model)+len(key.TaskType)+len(key.Text)) + cacheEntryOverhead
+	if size > c.maxBytes {
+		return
+	}
+	entry := &cacheEntry{key: key, vector: vector, size: size}
+	if c.ttl > 0 {
+		entry.expires = c.now().Add(c.ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	c.bytes += size
+	for c.bytes > c.maxBytes {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *Cache) remove(elem *list.Element) {
+	entry := c.order.Remove(elem).(*cacheEntry)
+	delete(c.entries, entry.key)
+	c.bytes -= entry.size
+}
+
+// Purge removes all cached embeddings.
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	clear(c.entries)
+	c.bytes = 0
+}
+
+// Len returns the number of cached embeddings.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// WithCache returns an EmbeddingModel that serves the embeddings of texts
+// already embedded by the model named name from cache, and only sends the
+// other texts to model.
+func WithCache(name string, model EmbeddingModel, cache *Cache) EmbeddingModel {
+	return cachedEmbeddingModel{EmbeddingModel: model, name: name, cache: cache}
+}
+
+type cachedEmbeddingModel struct {
+	EmbeddingModel
+	name  string
+	cache *Cache
+}
+
+func (m cachedEmbeddingModel) key(text string, opts EmbedOptions) CacheKey {
+	return CacheKey{
+		Model:                m.name,
+		TaskType:             opts.TaskType,
+		OutputDimensionality: opts.OutputDimensionality,
+		Text:                 NormalizeText(text),
+	}
+}
+
+func (m cachedEmbeddingModel) EmbedParameters(ctx context.Context, texts []string, opts EmbedOptions) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	// texts that are not cached, with the indexes they are used at
+	var missing []string
+	missingIndexes := make(map[string][]int)
+	for i, text := range texts {
+		key := m.key(text, opts)
+		if vector, ok := m.cache.Get(ctx, key); ok {
+			// copy the vector, so that callers cannot modify the cached one
+			embeddings[i] = slices.Clone(vector)
+			continue
+		}
+		if _, ok := missingIndexes[key.Text]; !ok {
+			missing = append(missing, text)
+		}
+		missingIndexes[key.Text] = append(missingIndexes[key.Text], i)
+	}
+	if len(missing) == 0 {
+		return embeddings, nil
+	}
+
+	vectors, err := m.EmbeddingModel.EmbedParameters(ctx, missing, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(missing) {
+		return nil, fmt.Errorf("model %s returned %d embeddings for %d inputs", m.name, len(vectors), len(missing))
+	}
+	for i, text := range missing {
+		key := m.key(text, opts)
+		m.cache.Add(key, slices.Clone(vectors[i]))
+		for _, idx := range missingIndexes[key.Text] {
+			embeddings[idx] = vectors[i]
+		}
+	}
+	return embeddings, nil
+}
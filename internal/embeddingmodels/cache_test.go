@@ -0,0 +1,209 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embeddingmodels
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// countingModel embeds a text as a vector holding its length, and records the
+// texts it is called with.
+type countingModel struct {
+	mu    sync.Mutex
+	calls [][]string
+}
+
+func (m *countingModel) EmbeddingModelType() string              { return "fake" }
+func (m *countingModel) ToConfig() EmbeddingModelConfig          { return nil }
+func (m *countingModel) ValidateEmbedOptions(EmbedOptions) error { return nil }
+func (m *countingModel) EmbedParameters(_ context.Context, texts []string, _ EmbedOptions) ([][]float32, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, texts)
+	m.mu.Unlock()
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = []float32{float32(len(text))}
+	}
+	return vectors, nil
+}
+
+func TestCachedEmbeddingModel(t *testing.T) {
+	ctx := context.Background()
+	reader := sdkmetric.NewManualReader()
+	lookups, err := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test").Int64Counter("lookups")
+	if err != nil {
+		t.Fatalf("unable to create counter: %s", err)
+	}
+	model := &countingModel{}
+	cached := WithCache("model", model, NewCache(1<<20, 0, lookups))
+
+	got, err := cached.EmbedParameters(ctx, []string{"top customers", "a", "top  customers "}, EmbedOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diff := cmp.Diff([][]float32{{13}, {1}, {13}}, got); diff != "" {
+		t.Fatalf("unexpected embeddings (-want +got):\n%s", diff)
+	}
+	// texts that normalize to the same key are embedded once
+	if diff := cmp.Diff([][]string{{"top customers", "a"}}, model.calls); diff != "" {
+		t.Fatalf("unexpected model calls (-want +got):\n%s", diff)
+	}
+
+	got, err = cached.EmbedParameters(ctx, []string{"a", "bb", " top customers"}, EmbedOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diff := cmp.Diff([][]float32{{1}, {2}, {13}}, got); diff != "" {
+		t.Fatalf("unexpected embeddings (-want +got):\n%s", diff)
+	}
+	// only the text that was not cached is sent to the model
+	if diff := cmp.Diff([][]string{{"top customers", "a"}, {"bb"}}, model.calls); diff != "" {
+		t.Fatalf("unexpected model calls (-want +got):\n%s", diff)
+	}
+
+	if _, err := cached.EmbedParameters(ctx, []string{"a"}, EmbedOptions{}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(model.calls) != 2 {
+		t.Fatalf("expected a fully cached batch to skip the model, got %v", model.calls)
+	}
+
+	// embeddings with other options are cached separately
+	if _, err := cached.EmbedParameters(ctx, []string{"a"}, EmbedOptions{TaskType: "RETRIEVAL_QUERY"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := cached.EmbedParameters(ctx, []string{"a"}, EmbedOptions{OutputDimensionality: 8}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(model.calls) != 4 {
+		t.Fatalf("expected embeddings with other options to be sent to the model, got %v", model.calls)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatalf("unable to collect metrics: %s", err)
+	}
+	hits, misses := int64(0), int64(0)
+	for _, dp := range rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64]).DataPoints {
+		if hit, _ := dp.Attributes.Value("hit"); hit.AsBool() {
+			hits += dp.Value
+		} else {
+			misses += dp.Value
+		}
+	}
+	if hits != 3 || misses != 6 {
+		t.Fatalf("got %d hits and %d misses, want 3 and 6", hits, misses)
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	ctx := context.Background()
+	vector := make([]float32, 100)
+	entrySize := int64(4*len(vector)+len("model")+len("t0")) + cacheEntryOverhead
+	cache := NewCache(3*entrySize, 0, nil)
+
+	for i := range 3 {
+		cache.Add(CacheKey{Model: "model", Text: fmt.Sprintf("t%d", i)}, vector)
+	}
+	// use t0, so that t1 is the least recently used
+	if _, ok := cache.Get(ctx, CacheKey{Model: "model", Text: "t0"}); !ok {
+		t.Fatalf("expected t0 to be cached")
+	}
+	cache.Add(CacheKey{Model: "model", Text: "t3"}, vector)
+
+	if cache.Len() != 3 {
+		t.Fatalf("expected 3 cached embeddings, got %d", cache.Len())
+	}
+	if _, ok := cache.Get(ctx, CacheKey{Model: "model", Text: "t1"}); ok {
+		t.Fatalf("expected the least recently used embedding to be evicted")
+	}
+	for _, text := range []string{"t0", "t2", "t3"} {
+		if _, ok := cache.Get(ctx, CacheKey{Model: "model", Text: text}); !ok {
+			t.Fatalf("expected %s to be cached", text)
+		}
+	}
+
+	// keys differing by model or task type do not collide
+	if _, ok := cache.Get(ctx, CacheKey{Model: "other", Text: "t0"}); ok {
+		t.Fatalf("expected no embedding for another model")
+	}
+	if _, ok := cache.Get(ctx, CacheKey{Model: "model", TaskType: "RETRIEVAL_QUERY", Text: "t0"}); ok {
+		t.Fatalf("expected no embedding for another task type")
+	}
+
+	cache.Add(CacheKey{Model: "model", Text: "huge"}, make([]float32, 1000))
+	if _, ok := cache.Get(ctx, CacheKey{Model: "model", Text: "huge"}); ok {
+		t.Fatalf("expected an embedding larger than the cache not to be cached")
+	}
+
+	cache.Purge()
+	if cache.Len() != 0 {
+		t.Fatalf("expected an empty cache after Purge, got %d entries", cache.Len())
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewCache(1<<20, time.Minute, nil)
+	cache.now = func() time.Time { return now }
+
+	key := CacheKey{Model: "model", Text: "text"}
+	cache.Add(key, []float32{1})
+	now = now.Add(59 * time.Second)
+	if _, ok := cache.Get(ctx, key); !ok {
+		t.Fatalf("expected the embedding to be cached before its TTL")
+	}
+	now = now.Add(time.Second)
+	if _, ok := cache.Get(ctx, key); ok {
+		t.Fatalf("expected the embedding to expire after its TTL")
+	}
+	if cache.Len() != 0 {
+		t.Fatalf("expected the expired embedding to be removed")
+	}
+}
+
+func TestCacheConcurrency(t *testing.T) {
+	model := &countingModel{}
+	cached := WithCache("model", model, NewCache(10*1024, 0, nil))
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			texts := []string{fmt.Sprintf("text %d", i%5), fmt.Sprintf("text %d", i)}
+			got, err := cached.EmbedParameters(context.Background(), texts, EmbedOptions{})
+			if err != nil {
+				t.Errorf("unexpected error: %s", err)
+				return
+			}
+			for j, text := range texts {
+				if got[j][0] != float32(len(text)) {
+					t.Errorf("got %v for %q", got[j], text)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
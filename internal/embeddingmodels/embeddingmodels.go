@@ -29,7 +29,23 @@ type EmbeddingModelConfig interface {
 type EmbeddingModel interface {
 	EmbeddingModelType() string
 	ToConfig() EmbeddingModelConfig
-	EmbedParameters(context.Context, []string) ([][]float32, error)
+	EmbedParameters(context.Context, []string, EmbedOptions) ([][]float32, error)
+	// ValidateEmbedOptions returns an error if the model does not support
+	// embedding with opts.
+	ValidateEmbedOptions(opts EmbedOptions) error
+}
+
+// EmbedOptions configure how the value of a parameter is embedded. Zero
+// values use the defaults of the model.
+type EmbedOptions struct {
+	// TaskType is the task the embeddings are optimized for, such as
+	// RETRIEVAL_QUERY or RETRIEVAL_DOCUMENT.
+	TaskType string
+	// OutputDimensionality is the number of dimensions of the embeddings.
+	OutputDimensionality int32
+	// Truncate sets whether inputs longer than the maximum input length of the
+	// model are truncated rather than rejected.
+	Truncate *bool
 }
 
 type VectorFormatter func(vectorFloats []float32) any
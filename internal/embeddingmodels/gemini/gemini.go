@@ -17,6 +17,7 @@ package gemini
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	"github.com/googleapis/genai-toolbox/internal/embeddingmodels"
 	"github.com/googleapis/genai-toolbox/internal/util"
@@ -25,6 +26,21 @@ import (
 
 const EmbeddingModelType string = "gemini"
 
+// defaultTaskType is the task type used for parameters that do not set one.
+const defaultTaskType = "SEMANTIC_SIMILARITY"
+
+// taskTypes are the task types supported by Gemini embedding models.
+var taskTypes = []string{
+	"SEMANTIC_SIMILARITY",
+	"CLASSIFICATION",
+	"CLUSTERING",
+	"RETRIEVAL_DOCUMENT",
+	"RETRIEVAL_QUERY",
+	"CODE_RETRIEVAL_QUERY",
+	"QUESTION_ANSWERING",
+	"FACT_VERIFICATION",
+}
+
 // validate interface
 var _ embeddingmodels.EmbeddingModelConfig = Config{}
 
@@ -78,7 +94,32 @@ func (m EmbeddingModel) ToConfig() embeddingmodels.EmbeddingModelConfig {
 	return m.Config
 }
 
-func (m EmbeddingModel) EmbedParameters(ctx context.Context, parameters []string) ([][]float32, error) {
+// ValidateEmbedOptions checks that the task type is supported by Gemini, that
+// the dimensionality agrees with the dimension of the model, and that
+// truncation is only requested from Vertex AI, which truncates inputs by
+// default.
+func (m EmbeddingModel) ValidateEmbedOptions(opts embeddingmodels.EmbedOptions) error {
+	if opts.TaskType != "" && !slices.Contains(taskTypes, opts.TaskType) {
+		return fmt.Errorf("task type %q is not supported, must be one of %q", opts.TaskType, taskTypes)
+	}
+	if opts.OutputDimensionality < 0 {
+		return fmt.Errorf("output dimensionality must be positive, got %d", opts.OutputDimensionality)
+	}
+	if opts.OutputDimensionality > 0 && m.Dimension > 0 && opts.OutputDimensionality != m.Dimension {
+		return fmt.Errorf("output dimensionality %d does not match the dimension %d of the model", opts.OutputDimensionality, m.Dimension)
+	}
+	if opts.Truncate != nil {
+		if m.Client == nil || m.Client.ClientConfig().Backend != genai.BackendVertexAI {
+			return fmt.Errorf("truncation can only be configured when using Vertex AI")
+		}
+		if !*opts.Truncate {
+			return fmt.Errorf("disabling truncation is not supported")
+		}
+	}
+	return nil
+}
+
+func (m EmbeddingModel) EmbedParameters(ctx context.Context, parameters []string, opts embeddingmodels.EmbedOptions) ([][]float32, error) {
 	logger, err := util.LoggerFromContext(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("unable to get logger from ctx: %s", err)
@@ -87,10 +128,16 @@ func (m EmbeddingModel) EmbedParameters(ctx context.Context, parameters []string
 	contents := convertStringsToContents(parameters)
 
 	embedConfig := &genai.EmbedContentConfig{
-		TaskType: "SEMANTIC_SIMILARITY",
+		TaskType:     defaultTaskType,
+		AutoTruncate: opts.Truncate != nil && *opts.Truncate,
+	}
+	if opts.TaskType != "" {
+		embedConfig.TaskType = opts.TaskType
 	}
 
-	if m.Dimension > 0 {
+	if opts.OutputDimensionality > 0 {
+		embedConfig.OutputDimensionality = genai.Ptr(opts.OutputDimensionality)
+	} else if m.Dimension > 0 {
 		embedConfig.OutputDimensionality = genai.Ptr(m.Dimension)
 	}
 
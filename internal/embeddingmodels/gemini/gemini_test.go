@@ -16,6 +16,7 @@ package gemini_test
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -122,3 +123,50 @@ func TestFailParseFromYamlGemini(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateEmbedOptions(t *testing.T) {
+	model := gemini.EmbeddingModel{Config: gemini.Config{Name: "model", Model: "gemini-embedding-001", Dimension: 768}}
+	truncate := true
+	tcs := []struct {
+		desc string
+		opts embeddingmodels.EmbedOptions
+		err  string
+	}{
+		{
+			desc: "defaults",
+		},
+		{
+			desc: "supported options",
+			opts: embeddingmodels.EmbedOptions{TaskType: "RETRIEVAL_QUERY", OutputDimensionality: 768},
+		},
+		{
+			desc: "unknown task type",
+			opts: embeddingmodels.EmbedOptions{TaskType: "SUMMARIZATION"},
+			err:  `task type "SUMMARIZATION" is not supported`,
+		},
+		{
+			desc: "dimensionality not matching the model",
+			opts: embeddingmodels.EmbedOptions{OutputDimensionality: 256},
+			err:  "output dimensionality 256 does not match the dimension 768 of the model",
+		},
+		{
+			desc: "truncation with the Gemini API",
+			opts: embeddingmodels.EmbedOptions{Truncate: &truncate},
+			err:  "truncation can only be configured when using Vertex AI",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := model.ValidateEmbedOptions(tc.opts)
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Fatalf("unexpected error: got %v, want %q", err, tc.err)
+			}
+		})
+	}
+}
@@ -49,6 +49,9 @@ func NewValueTextHandler(out io.Writer, opts *slog.HandlerOptions) *ValueTextHan
 }
 
 func (h *ValueTextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if minLevel, ok := levelFromContext(ctx); ok {
+		return level >= minLevel
+	}
 	return h.h.Enabled(ctx, level)
 }
 
@@ -128,6 +131,15 @@ func handlerWithSpanContext(handler slog.Handler) *spanContextLogHandler {
 	return &spanContextLogHandler{Handler: handler}
 }
 
+// Enabled overrides slog.Handler's Enabled method, to honor the level of a
+// logger returned by WithLevel.
+func (t *spanContextLogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if minLevel, ok := levelFromContext(ctx); ok {
+		return level >= minLevel
+	}
+	return t.Handler.Enabled(ctx, level)
+}
+
 // Handle overrides slog.Handler's Handle method. This adds attributes from the
 // span context to the slog.Record.
 func (t *spanContextLogHandler) Handle(ctx context.Context, record slog.Record) error {
@@ -147,3 +159,42 @@ func (t *spanContextLogHandler) Handle(ctx context.Context, record slog.Record)
 	}
 	return t.Handler.Handle(ctx, record)
 }
+
+// levelKey is the key of the level of a logger returned by WithLevel within
+// the context of the messages it logs.
+type levelKey struct{}
+
+func contextWithLevel(ctx context.Context, level slog.Level) context.Context {
+	return context.WithValue(ctx, levelKey{}, level)
+}
+
+func levelFromContext(ctx context.Context) (slog.Level, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	level, ok := ctx.Value(levelKey{}).(slog.Level)
+	return level, ok
+}
+
+// levelHandler is an slog.Handler that handles records at level and above,
+// instead of the level of its handler.
+type levelHandler struct {
+	handler slog.Handler
+	level   slog.Level
+}
+
+func (h *levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(contextWithLevel(ctx, h.level), level)
+}
+
+func (h *levelHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.handler.Handle(contextWithLevel(ctx, h.level), r)
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{handler: h.handler.WithAttrs(attrs), level: h.level}
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{handler: h.handler.WithGroup(name), level: h.level}
+}
@@ -483,3 +483,59 @@ func TestStructuredLoggerDebugLog(t *testing.T) {
 		})
 	}
 }
+
+func TestWithLevel(t *testing.T) {
+	tcs := []struct {
+		name        string
+		loggerLevel string
+		level       slog.Level
+		wantDebug   bool
+		wantInfo    bool
+	}{
+		{
+			name:        "debug on an info logger",
+			loggerLevel: "info",
+			level:       slog.LevelDebug,
+			wantDebug:   true,
+			wantInfo:    true,
+		},
+		{
+			name:        "info on a debug logger",
+			loggerLevel: "debug",
+			level:       slog.LevelInfo,
+			wantInfo:    true,
+		},
+		{
+			name:        "warn on an info logger",
+			loggerLevel: "info",
+			level:       slog.LevelWarn,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, format := range []string{"standard", "json"} {
+				outW := new(bytes.Buffer)
+				logger, err := NewLogger(format, tc.loggerLevel, outW, outW)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				logger = WithLevel(logger, tc.level)
+				ctx := context.Background()
+				logger.DebugContext(ctx, "log debug")
+				logger.InfoContext(ctx, "log info")
+				logger.SlogLogger().DebugContext(ctx, "slog debug")
+
+				out := outW.String()
+				if got := strings.Contains(out, "log debug"); got != tc.wantDebug {
+					t.Errorf("%s: got debug message logged %t, want %t: %q", format, got, tc.wantDebug, out)
+				}
+				if got := strings.Contains(out, "slog debug"); got != tc.wantDebug {
+					t.Errorf("%s: got slog debug message logged %t, want %t: %q", format, got, tc.wantDebug, out)
+				}
+				if got := strings.Contains(out, "log info"); got != tc.wantInfo {
+					t.Errorf("%s: got info message logged %t, want %t: %q", format, got, tc.wantInfo, out)
+				}
+			}
+		})
+	}
+}
@@ -33,3 +33,38 @@ type Logger interface {
 	// errLogger based on log levels
 	SlogLogger() *slog.Logger
 }
+
+// WithLevel returns a Logger that logs the messages of logger at level and
+// above, instead of the level of logger. It is honored by the loggers of this
+// package.
+func WithLevel(logger Logger, level slog.Level) Logger {
+	if l, ok := logger.(levelLogger); ok {
+		logger = l.Logger
+	}
+	return levelLogger{Logger: logger, level: level}
+}
+
+type levelLogger struct {
+	Logger
+	level slog.Level
+}
+
+func (l levelLogger) DebugContext(ctx context.Context, msg string, args ...any) {
+	l.Logger.DebugContext(contextWithLevel(ctx, l.level), msg, args...)
+}
+
+func (l levelLogger) InfoContext(ctx context.Context, msg string, args ...any) {
+	l.Logger.InfoContext(contextWithLevel(ctx, l.level), msg, args...)
+}
+
+func (l levelLogger) WarnContext(ctx context.Context, msg string, args ...any) {
+	l.Logger.WarnContext(contextWithLevel(ctx, l.level), msg, args...)
+}
+
+func (l levelLogger) ErrorContext(ctx context.Context, msg string, args ...any) {
+	l.Logger.ErrorContext(contextWithLevel(ctx, l.level), msg, args...)
+}
+
+func (l levelLogger) SlogLogger() *slog.Logger {
+	return slog.New(&levelHandler{handler: l.Logger.SlogLogger().Handler(), level: l.level})
+}
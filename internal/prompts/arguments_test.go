@@ -214,7 +214,7 @@ func TestParseArguments(t *testing.T) {
 			},
 			want: parameters.ParamValues{
 				{Name: "name", Value: "another-name"},
-				{Name: "count", Value: nil},
+				{Name: "count", Value: nil, Omitted: true},
 			},
 		},
 		{
@@ -15,17 +15,18 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 	"github.com/googleapis/genai-toolbox/internal/tools"
 	"github.com/googleapis/genai-toolbox/internal/util"
-	"github.com/googleapis/genai-toolbox/internal/util/parameters"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/metric"
@@ -134,11 +135,12 @@ func toolGetHandler(s *Server, w http.ResponseWriter, r *http.Request) {
 // toolInvokeHandler handles the API request to invoke a specific Tool.
 func toolInvokeHandler(s *Server, w http.ResponseWriter, r *http.Request) {
 	ctx, span := s.instrumentation.Tracer.Start(r.Context(), "toolbox/server/tool/invoke")
+	ctx, requestID := tools.WithRequestID(ctx, r.Header)
+	w.Header().Set(tools.RequestIDHeader, requestID)
 	r = r.WithContext(ctx)
 	ctx = util.WithLogger(r.Context(), s.logger)
 
 	toolName := chi.URLParam(r, "toolName")
-	s.logger.DebugContext(ctx, fmt.Sprintf("tool name: %s", toolName))
 	span.SetAttributes(attribute.String("tool_name", toolName))
 	var err error
 	defer func() {
@@ -159,30 +161,52 @@ func toolInvokeHandler(s *Server, w http.ResponseWriter, r *http.Request) {
 		)
 	}()
 
-	tool, ok := s.ResourceMgr.GetTool(toolName)
+	// the logger of the invocation adds its request ID to the messages
+	logger, err := util.LoggerFromContext(ctx)
+	if err != nil {
+		_ = render.Render(w, r, newErrResponse(err, http.StatusInternalServerError))
+		return
+	}
+	logger.DebugContext(ctx, fmt.Sprintf("tool name: %s", toolName))
+
+	tool, sourceProvider, ok := s.ResourceMgr.GetToolWithSources(toolName)
 	if !ok {
 		err = fmt.Errorf("invalid tool name: tool with name %q does not exist", toolName)
-		s.logger.DebugContext(ctx, err.Error())
+		logger.DebugContext(ctx, err.Error())
 		_ = render.Render(w, r, newErrResponse(err, http.StatusNotFound))
 		return
 	}
 
-	// Extract OAuth access token from the "Authorization" header (currently for
-	// BigQuery end-user credentials usage only)
-	accessToken := tools.AccessToken(r.Header.Get("Authorization"))
+	// the tool's log level applies to the rest of the invocation
+	ctx = tools.WithToolLogLevel(ctx, tool)
+	if logger, err = util.LoggerFromContext(ctx); err != nil {
+		_ = render.Render(w, r, newErrResponse(err, http.StatusInternalServerError))
+		return
+	}
+
+	// Extract OAuth access token from the tool's auth token header, which is
+	// "Authorization" unless configured otherwise
+	authTokenHeaderName, err := tool.GetAuthTokenHeaderName(sourceProvider)
+	if err != nil {
+		errMsg := fmt.Errorf("error during invocation: %w", err)
+		logger.DebugContext(ctx, errMsg.Error())
+		_ = render.Render(w, r, newErrResponse(errMsg, http.StatusInternalServerError))
+		return
+	}
+	accessToken := tools.AccessToken(r.Header.Get(authTokenHeaderName))
 
 	// Check if this specific tool requires the standard authorization header
-	clientAuth, err := tool.RequiresClientAuthorization(s.ResourceMgr)
+	clientAuth, err := tool.RequiresClientAuthorization(sourceProvider)
 	if err != nil {
 		errMsg := fmt.Errorf("error during invocation: %w", err)
-		s.logger.DebugContext(ctx, errMsg.Error())
+		logger.DebugContext(ctx, errMsg.Error())
 		_ = render.Render(w, r, newErrResponse(errMsg, http.StatusNotFound))
 		return
 	}
 	if clientAuth {
 		if accessToken == "" {
-			err = fmt.Errorf("tool requires client authorization but access token is missing from the request header")
-			s.logger.DebugContext(ctx, err.Error())
+			err = fmt.Errorf("tool requires client authorization but access token is missing from the %q header", authTokenHeaderName)
+			logger.DebugContext(ctx, err.Error())
 			_ = render.Render(w, r, newErrResponse(err, http.StatusUnauthorized))
 			return
 		}
@@ -194,7 +218,7 @@ func toolInvokeHandler(s *Server, w http.ResponseWriter, r *http.Request) {
 	for _, aS := range s.ResourceMgr.GetAuthServiceMap() {
 		claims, err := aS.GetClaimsFromHeader(ctx, r.Header)
 		if err != nil {
-			s.logger.DebugContext(ctx, err.Error())
+			logger.DebugContext(ctx, err.Error())
 			continue
 		}
 		if claims == nil {
@@ -216,35 +240,54 @@ func toolInvokeHandler(s *Server, w http.ResponseWriter, r *http.Request) {
 	isAuthorized := tool.Authorized(verifiedAuthServices)
 	if !isAuthorized {
 		err = fmt.Errorf("tool invocation not authorized. Please make sure you specify correct auth headers")
-		s.logger.DebugContext(ctx, err.Error())
+		logger.DebugContext(ctx, err.Error())
 		_ = render.Render(w, r, newErrResponse(err, http.StatusUnauthorized))
 		return
 	}
-	s.logger.DebugContext(ctx, "tool invocation authorized")
+	logger.DebugContext(ctx, "tool invocation authorized")
+
+	// plans have no side effects, so they need no confirmation
+	plan := strings.EqualFold(r.Header.Get(tools.PlanHeader), "true")
+	if plan {
+		if !tools.SupportsPlanning(tool) {
+			err = fmt.Errorf("tool %q does not support planning", toolName)
+			logger.DebugContext(ctx, err.Error())
+			_ = render.Render(w, r, newErrResponse(err, http.StatusBadRequest))
+			return
+		}
+	} else {
+		confirmed := strings.EqualFold(r.Header.Get(tools.ConfirmDestructiveHeader), "true")
+		if err = s.ResourceMgr.CheckDestructiveConfirmation(toolName, tool, confirmed); err != nil {
+			err = fmt.Errorf("%w: set the %q header to \"true\" to confirm it", err, tools.ConfirmDestructiveHeader)
+			logger.DebugContext(ctx, err.Error())
+			_ = render.Render(w, r, newErrResponse(err, http.StatusPreconditionRequired))
+			return
+		}
+	}
 
 	var data map[string]any
 	if err = util.DecodeJSON(r.Body, &data); err != nil {
 		render.Status(r, http.StatusBadRequest)
 		err = fmt.Errorf("request body was invalid JSON: %w", err)
-		s.logger.DebugContext(ctx, err.Error())
+		logger.DebugContext(ctx, err.Error())
 		_ = render.Render(w, r, newErrResponse(err, http.StatusBadRequest))
 		return
 	}
 
-	params, err := parameters.ParseParams(tool.GetParameters(), data, claimsFromAuth)
+	params, err := tools.ParseParams(tool, data, claimsFromAuth)
 	if err != nil {
 		var clientServerErr *util.ClientServerError
 
 		// Return 401 Authentication errors
 		if errors.As(err, &clientServerErr) && clientServerErr.Code == http.StatusUnauthorized {
-			s.logger.DebugContext(ctx, fmt.Sprintf("auth error: %v", err))
+			logger.DebugContext(ctx, fmt.Sprintf("auth error: %v", err))
 			_ = render.Render(w, r, newErrResponse(err, http.StatusUnauthorized))
 			return
 		}
 
 		var agentErr *util.AgentError
 		if errors.As(err, &agentErr) {
-			s.logger.DebugContext(ctx, fmt.Sprintf("agent validation error: %v", err))
+			logger.DebugContext(ctx, fmt.Sprintf("agent validation error: %v", err))
 			errMap := map[string]string{"error": err.Error()}
 			errMarshal, _ := json.Marshal(errMap)
 
@@ -253,23 +296,58 @@ func toolInvokeHandler(s *Server, w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Return 500 if it's a specific ClientServerError that isn't a 401, or any other unexpected error
-		s.logger.ErrorContext(ctx, fmt.Sprintf("internal server error: %v", err))
+		logger.ErrorContext(ctx, fmt.Sprintf("internal server error: %v", err))
 		_ = render.Render(w, r, newErrResponse(err, http.StatusInternalServerError))
 		return
 	}
-	s.logger.DebugContext(ctx, fmt.Sprintf("invocation params: %s", params))
+	logger.DebugContext(ctx, fmt.Sprintf("invocation params: %v", tools.LoggableParams(tool, sourceProvider, params)))
+	if coerced := params.Coerced(); len(coerced) > 0 {
+		logger.DebugContext(ctx, fmt.Sprintf("coerced string inputs of parameters: %q", coerced))
+	}
 
 	params, err = tool.EmbedParams(ctx, params, s.ResourceMgr.GetEmbeddingModelMap())
 	if err != nil {
 		err = fmt.Errorf("error embedding parameters: %w", err)
-		s.logger.DebugContext(ctx, err.Error())
+		logger.DebugContext(ctx, err.Error())
 		_ = render.Render(w, r, newErrResponse(err, http.StatusBadRequest))
 		return
 	}
 
-	res, err := tool.Invoke(ctx, s.ResourceMgr, params, accessToken)
+	// tools that cannot stream send their result at once
+	stream := !plan && strings.EqualFold(r.Header.Get(tools.StreamHeader), "true") && tools.SupportsStreaming(tool)
+	var res any
+	if stream {
+		started := false
+		err = tools.StreamWithTimeout(ctx, toolName, tool, sourceProvider, params, accessToken, s.ResourceMgr.GetMaxStreamBufferedChunks(), func(chunk any) error {
+			if !started {
+				w.Header().Set("Content-Type", streamContentType)
+				started = true
+			}
+			return writeStreamLine(w, streamLine{Chunk: chunk})
+		})
+		// errors before the first chunk are returned like the errors of
+		// invocations that are not streamed
+		if err == nil || started {
+			var tbErr util.ToolboxError
+			if errors.As(err, &tbErr) {
+				logger.DebugContext(ctx, fmt.Sprintf("Tool invocation stream error: %v", err))
+			}
+			if !started {
+				w.Header().Set("Content-Type", streamContentType)
+			}
+			endStream(ctx, w, tbErr)
+			return
+		}
+	} else {
+		invoke := tools.InvokeWithTimeout
+		if plan {
+			invoke = tools.PlanWithTimeout
+		}
+		res, err = invoke(ctx, toolName, tool, sourceProvider, params, accessToken)
+	}
 
 	// Determine what error to return to the users.
+	var errorInfo *util.ErrorInfo
 	if err != nil {
 		var tbErr util.ToolboxError
 
@@ -277,10 +355,12 @@ func toolInvokeHandler(s *Server, w http.ResponseWriter, r *http.Request) {
 			switch tbErr.Category() {
 			case util.CategoryAgent:
 				// Agent Errors -> 200 OK
-				s.logger.DebugContext(ctx, fmt.Sprintf("Tool invocation agent error: %v", err))
+				logger.DebugContext(ctx, fmt.Sprintf("Tool invocation agent error: %v", err))
 				res = map[string]string{
 					"error": err.Error(),
 				}
+				info := util.RequestErrorInfo(ctx, tbErr)
+				errorInfo = &info
 
 			case util.CategoryServer:
 				// Server Errors -> Check the specific code inside
@@ -297,7 +377,7 @@ func toolInvokeHandler(s *Server, w http.ResponseWriter, r *http.Request) {
 				if statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden {
 					if clientAuth {
 						// Token error, pass through 401/403
-						s.logger.DebugContext(ctx, fmt.Sprintf("Client credentials lack authorization: %v", err))
+						logger.DebugContext(ctx, fmt.Sprintf("Client credentials lack authorization: %v", err))
 						_ = render.Render(w, r, newErrResponse(err, statusCode))
 						return
 					}
@@ -305,34 +385,38 @@ func toolInvokeHandler(s *Server, w http.ResponseWriter, r *http.Request) {
 					statusCode = http.StatusInternalServerError
 				}
 
-				s.logger.ErrorContext(ctx, fmt.Sprintf("Tool invocation server error: %v", err))
+				logger.ErrorContext(ctx, fmt.Sprintf("Tool invocation server error: %v", err))
 				_ = render.Render(w, r, newErrResponse(err, statusCode))
 				return
 			}
 		} else {
 			// Unknown error -> 500
-			s.logger.ErrorContext(ctx, fmt.Sprintf("Tool invocation unknown error: %v", err))
+			logger.ErrorContext(ctx, fmt.Sprintf("Tool invocation unknown error: %v", err))
 			_ = render.Render(w, r, newErrResponse(err, http.StatusInternalServerError))
 			return
 		}
 	}
 
+	includeMetadata := strings.EqualFold(r.Header.Get(tools.IncludeMetadataHeader), "true")
+	res, metadata := tools.UnwrapResult(res, includeMetadata)
 	resMarshal, err := json.Marshal(res)
 	if err != nil {
 		err = fmt.Errorf("unable to marshal result: %w", err)
-		s.logger.DebugContext(ctx, err.Error())
+		logger.DebugContext(ctx, err.Error())
 		_ = render.Render(w, r, newErrResponse(err, http.StatusInternalServerError))
 		return
 	}
 
-	_ = render.Render(w, r, &resultResponse{Result: string(resMarshal)})
+	_ = render.Render(w, r, &resultResponse{Result: string(resMarshal), Metadata: metadata, ErrorInfo: errorInfo})
 }
 
 var _ render.Renderer = &resultResponse{} // Renderer interface for managing response payloads.
 
 // resultResponse is the response sent back when the tool was invocated successfully.
 type resultResponse struct {
-	Result string `json:"result"` // result of tool invocation
+	Result    string          `json:"result"`              // result of tool invocation
+	Metadata  map[string]any  `json:"metadata,omitempty"`  // execution metadata of the result, if requested
+	ErrorInfo *util.ErrorInfo `json:"errorInfo,omitempty"` // code of the error, if the result is a tool execution error
 }
 
 // Render renders a single payload and respond to the client request.
@@ -341,17 +425,63 @@ func (rr resultResponse) Render(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
+// streamContentType is the content type of the responses of streamed
+// invocations, which hold a JSON object per line.
+const streamContentType = "application/x-ndjson"
+
+// streamLine is a line of the response of a streamed invocation. Each chunk
+// of the result is sent in its own line, followed by a last line that
+// reports whether the invocation succeeded.
+type streamLine struct {
+	Chunk     any             `json:"chunk,omitempty"`
+	Done      bool            `json:"done,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	ErrorInfo *util.ErrorInfo `json:"errorInfo,omitempty"`
+}
+
+// writeStreamLine writes line to the response of a streamed invocation, and
+// flushes it to the client.
+func writeStreamLine(w http.ResponseWriter, line streamLine) error {
+	b, err := json.Marshal(line)
+	if err != nil {
+		return fmt.Errorf("unable to marshal chunk: %w", err)
+	}
+	if _, err := w.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	// responses that cannot be flushed are sent when the handler returns
+	_ = http.NewResponseController(w).Flush()
+	return nil
+}
+
+// endStream writes the last line of the response of a streamed invocation,
+// with the error that ended it, if any.
+func endStream(ctx context.Context, w http.ResponseWriter, err util.ToolboxError) {
+	line := streamLine{Done: true}
+	if err != nil {
+		info := util.RequestErrorInfo(ctx, err)
+		line = streamLine{Error: err.Error(), ErrorInfo: &info}
+	}
+	_ = writeStreamLine(w, line)
+}
+
 var _ render.Renderer = &errResponse{} // Renderer interface for managing response payloads.
 
 // newErrResponse is a helper function initializing an ErrResponse
 func newErrResponse(err error, code int) *errResponse {
-	return &errResponse{
+	resp := &errResponse{
 		Err:            err,
 		HTTPStatusCode: code,
 
 		StatusText: http.StatusText(code),
 		ErrorText:  err.Error(),
 	}
+	var tbErr util.ToolboxError
+	if errors.As(err, &tbErr) {
+		info := tbErr.ErrorInfo()
+		resp.ErrorInfo = &info
+	}
+	return resp
 }
 
 // errResponse is the response sent back when an error has been encountered.
@@ -361,9 +491,14 @@ type errResponse struct {
 
 	StatusText string `json:"status"`          // user-level status message
 	ErrorText  string `json:"error,omitempty"` // application-level error message, for debugging
+	// code, retryable and details of the error, if it is a ToolboxError
+	*util.ErrorInfo
 }
 
 func (e *errResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	if e.ErrorInfo != nil {
+		e.ErrorInfo.RequestID = util.RequestIDFromContext(r.Context())
+	}
 	render.Status(r, e.HTTPStatusCode)
 	return nil
 }
@@ -16,14 +16,23 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/auth"
+	"github.com/googleapis/genai-toolbox/internal/log"
+	"github.com/googleapis/genai-toolbox/internal/server/resources"
+	"github.com/googleapis/genai-toolbox/internal/sources"
 	"github.com/googleapis/genai-toolbox/internal/tools"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
 )
 
 func TestToolsetEndpoint(t *testing.T) {
@@ -295,3 +304,666 @@ func TestToolInvokeEndpoint(t *testing.T) {
 		})
 	}
 }
+
+func TestToolInvokeEndpointRedactsSensitiveParams(t *testing.T) {
+	apiKey := parameters.NewStringParameter("api_key", "an API key")
+	apiKey.Sensitive = true
+	sensitiveTool := MockTool{
+		Name: "sensitive_params",
+		Params: parameters.Parameters{
+			parameters.NewStringParameter("user", "a user name"),
+			apiKey,
+		},
+	}
+	toolsMap, toolsets, _, _ := setUpResources(t, []MockTool{tool1, sensitiveTool}, nil)
+
+	var logs bytes.Buffer
+	testLogger, err := log.NewStdLogger(&logs, &logs, "debug")
+	if err != nil {
+		t.Fatalf("unable to initialize logger: %s", err)
+	}
+	r, shutdown := setUpServerWithLogger(t, "api", testLogger, toolsMap, toolsets, nil, nil)
+	defer shutdown()
+	ts := runServer(r, false)
+	defer ts.Close()
+
+	body := bytes.NewBuffer([]byte(`{"user": "alice", "api_key": "top-secret-key"}`))
+	resp, respBody, err := runRequest(ts, http.MethodPost, fmt.Sprintf("/tool/%s/invoke", sensitiveTool.Name), body, nil)
+	if err != nil {
+		t.Fatalf("unexpected error during request: %s", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("response status code is not 200, got %d, %s", resp.StatusCode, string(respBody))
+	}
+
+	got := logs.String()
+	if !strings.Contains(got, "invocation params") || !strings.Contains(got, "alice") {
+		t.Fatalf("expected the invocation params to be logged, got %q", got)
+	}
+	if strings.Contains(got, "top-secret-key") {
+		t.Fatalf("sensitive value found in logs: %q", got)
+	}
+	if !strings.Contains(got, parameters.RedactedValue) {
+		t.Fatalf("expected %q in logs, got %q", parameters.RedactedValue, got)
+	}
+}
+
+func TestToolInvokeEndpointDestructiveConfirmation(t *testing.T) {
+	destructiveTool := MockTool{
+		Name:        "destructive",
+		Params:      parameters.Parameters{},
+		Annotations: tools.NewDestructiveAnnotations(),
+	}
+	toolsMap, toolsets, _, _ := setUpResources(t, []MockTool{tool1, destructiveTool}, nil)
+
+	testLogger, err := log.NewStdLogger(os.Stdout, os.Stderr, "info")
+	if err != nil {
+		t.Fatalf("unable to initialize logger: %s", err)
+	}
+	resourceManager := resources.NewResourceManager(nil, nil, nil, toolsMap, toolsets, nil, nil)
+	resourceManager.SetRequireDestructiveConfirmation(true)
+	r, shutdown := setUpServerWithResourceManager(t, "api", testLogger, resourceManager)
+	defer shutdown()
+	ts := runServer(r, false)
+	defer ts.Close()
+
+	tcs := []struct {
+		desc       string
+		toolName   string
+		header     map[string]string
+		wantStatus int
+	}{
+		{
+			desc:       "destructive tool without confirmation",
+			toolName:   destructiveTool.Name,
+			wantStatus: http.StatusPreconditionRequired,
+		},
+		{
+			desc:       "destructive tool with a false confirmation",
+			toolName:   destructiveTool.Name,
+			header:     map[string]string{tools.ConfirmDestructiveHeader: "false"},
+			wantStatus: http.StatusPreconditionRequired,
+		},
+		{
+			desc:       "destructive tool with confirmation",
+			toolName:   destructiveTool.Name,
+			header:     map[string]string{tools.ConfirmDestructiveHeader: "true"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			desc:       "tool without annotations",
+			toolName:   tool1.Name,
+			wantStatus: http.StatusOK,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			resp, body, err := runRequest(ts, http.MethodPost, fmt.Sprintf("/tool/%s/invoke", tc.toolName), bytes.NewBuffer([]byte(`{}`)), tc.header)
+			if err != nil {
+				t.Fatalf("unexpected error during request: %s", err)
+			}
+			if resp.StatusCode != tc.wantStatus {
+				t.Fatalf("unexpected status code: got %d, want %d, %s", resp.StatusCode, tc.wantStatus, string(body))
+			}
+			if tc.wantStatus == http.StatusPreconditionRequired && !strings.Contains(string(body), tools.ConfirmDestructiveHeader) {
+				t.Fatalf("expected the error to name the confirmation header, got %s", string(body))
+			}
+		})
+	}
+}
+
+func TestToolInvokeEndpointPlan(t *testing.T) {
+	plannableTool := MockPlannableTool{MockTool{
+		Name:        "plannable",
+		Params:      parameters.Parameters{},
+		Annotations: tools.NewDestructiveAnnotations(),
+	}}
+	toolsMap, toolsets, _, _ := setUpResources(t, []MockTool{tool1, tool2}, nil)
+	toolsMap[plannableTool.Name] = plannableTool
+
+	testLogger, err := log.NewStdLogger(os.Stdout, os.Stderr, "info")
+	if err != nil {
+		t.Fatalf("unable to initialize logger: %s", err)
+	}
+	resourceManager := resources.NewResourceManager(nil, nil, nil, toolsMap, toolsets, nil, nil)
+	resourceManager.SetRequireDestructiveConfirmation(true)
+	r, shutdown := setUpServerWithResourceManager(t, "api", testLogger, resourceManager)
+	defer shutdown()
+	ts := runServer(r, false)
+	defer ts.Close()
+
+	tcs := []struct {
+		desc       string
+		toolName   string
+		header     map[string]string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			desc:       "plan of a destructive tool needs no confirmation",
+			toolName:   plannableTool.Name,
+			header:     map[string]string{tools.PlanHeader: "true"},
+			wantStatus: http.StatusOK,
+			wantBody:   `{\"plan\":\"plannable\"}`,
+		},
+		{
+			desc:       "invocation of a plannable tool",
+			toolName:   plannableTool.Name,
+			header:     map[string]string{tools.ConfirmDestructiveHeader: "true"},
+			wantStatus: http.StatusOK,
+			wantBody:   `[\"plannable\"]`,
+		},
+		{
+			desc:       "plan of a tool without planning",
+			toolName:   tool1.Name,
+			header:     map[string]string{tools.PlanHeader: "true"},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `tool \"no_params\" does not support planning`,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			resp, body, err := runRequest(ts, http.MethodPost, fmt.Sprintf("/tool/%s/invoke", tc.toolName), bytes.NewBuffer([]byte(`{}`)), tc.header)
+			if err != nil {
+				t.Fatalf("unexpected error during request: %s", err)
+			}
+			if resp.StatusCode != tc.wantStatus {
+				t.Fatalf("unexpected status code: got %d, want %d, %s", resp.StatusCode, tc.wantStatus, string(body))
+			}
+			if !strings.Contains(string(body), tc.wantBody) {
+				t.Fatalf("expected the body to contain %s, got %s", tc.wantBody, string(body))
+			}
+		})
+	}
+}
+
+func TestToolInvokeEndpointStream(t *testing.T) {
+	streamingTool := MockStreamingTool{
+		MockTool: MockTool{Name: "streaming", Params: parameters.Parameters{}},
+		Chunks:   [][]any{{"a", "b"}, {"c"}},
+	}
+	failingTool := MockStreamingTool{
+		MockTool: MockTool{Name: "failing", Params: parameters.Parameters{}, Err: util.NewAgentError("query failed", nil)},
+		Chunks:   [][]any{{"a"}},
+	}
+	toolsMap, toolsets, _, _ := setUpResources(t, []MockTool{tool1, tool2}, nil)
+	toolsMap[streamingTool.Name] = streamingTool
+	toolsMap[failingTool.Name] = failingTool
+
+	testLogger, err := log.NewStdLogger(os.Stdout, os.Stderr, "info")
+	if err != nil {
+		t.Fatalf("unable to initialize logger: %s", err)
+	}
+	resourceManager := resources.NewResourceManager(nil, nil, nil, toolsMap, toolsets, nil, nil)
+	resourceManager.SetMaxStreamBufferedChunks(1)
+	r, shutdown := setUpServerWithResourceManager(t, "api", testLogger, resourceManager)
+	defer shutdown()
+	ts := runServer(r, false)
+	defer ts.Close()
+
+	tcs := []struct {
+		desc            string
+		toolName        string
+		header          map[string]string
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			desc:            "streamed invocation",
+			toolName:        streamingTool.Name,
+			header:          map[string]string{tools.StreamHeader: "true"},
+			wantContentType: "application/x-ndjson",
+			wantBody:        "{\"chunk\":[\"a\",\"b\"]}\n{\"chunk\":[\"c\"]}\n{\"done\":true}\n",
+		},
+		{
+			desc:            "error after the first chunk",
+			toolName:        failingTool.Name,
+			header:          map[string]string{tools.StreamHeader: "true"},
+			wantContentType: "application/x-ndjson",
+			wantBody:        "{\"chunk\":[\"a\"]}\n{\"error\":\"query failed\",\"errorInfo\":{\"code\":\"invalid_argument\",",
+		},
+		{
+			desc:            "streaming tool invoked without streaming",
+			toolName:        streamingTool.Name,
+			wantContentType: "application/json",
+			wantBody:        `{"result":"[\"a\",\"b\",\"c\"]"}` + "\n",
+		},
+		{
+			desc:            "stream of a tool without streaming",
+			toolName:        tool1.Name,
+			header:          map[string]string{tools.StreamHeader: "true"},
+			wantContentType: "application/json",
+			wantBody:        `{"result":"[\"no_params\"]"}` + "\n",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			resp, body, err := runRequest(ts, http.MethodPost, fmt.Sprintf("/tool/%s/invoke", tc.toolName), bytes.NewBuffer([]byte(`{}`)), tc.header)
+			if err != nil {
+				t.Fatalf("unexpected error during request: %s", err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("unexpected status code: got %d, %s", resp.StatusCode, string(body))
+			}
+			if got := resp.Header.Get("Content-Type"); !strings.HasPrefix(got, tc.wantContentType) {
+				t.Fatalf("unexpected content type: got %q, want %q", got, tc.wantContentType)
+			}
+			// the error info of failed streams ends with the request ID
+			if !strings.HasPrefix(string(body), tc.wantBody) {
+				t.Fatalf("unexpected body: got %q, want %q", string(body), tc.wantBody)
+			}
+		})
+	}
+}
+
+func TestToolInvokeEndpointAuthParams(t *testing.T) {
+	authServices := []parameters.ParamAuthService{{Name: "my-auth", Field: "email"}}
+	echoTool := MockEchoTool{MockTool{
+		Name: "echo",
+		Params: parameters.Parameters{
+			parameters.NewStringParameter("query", "the query"),
+			parameters.NewStringParameterWithAuth("email", "the user's email", authServices),
+		},
+	}}
+	toolsMap, toolsets, _, _ := setUpResources(t, []MockTool{tool1, tool2}, nil)
+	toolsMap[echoTool.Name] = echoTool
+
+	testLogger, err := log.NewStdLogger(os.Stdout, os.Stderr, "info")
+	if err != nil {
+		t.Fatalf("unable to initialize logger: %s", err)
+	}
+	authServicesMap := map[string]auth.AuthService{"my-auth": MockAuthService{Name: "my-auth"}}
+	resourceManager := resources.NewResourceManager(nil, authServicesMap, nil, toolsMap, toolsets, nil, nil)
+	r, shutdown := setUpServerWithResourceManager(t, "api", testLogger, resourceManager)
+	defer shutdown()
+	ts := runServer(r, false)
+	defer ts.Close()
+
+	tcs := []struct {
+		desc       string
+		body       string
+		header     map[string]string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			desc:       "value is taken from the claims",
+			body:       `{"query": "q"}`,
+			header:     map[string]string{"my-auth_token": `{"email": "alice@example.com"}`},
+			wantStatus: http.StatusOK,
+			wantBody:   `\"email\":\"alice@example.com\"`,
+		},
+		{
+			desc:       "client value is ignored",
+			body:       `{"query": "q", "email": "mallory@example.com"}`,
+			header:     map[string]string{"my-auth_token": `{"email": "alice@example.com"}`},
+			wantStatus: http.StatusOK,
+			wantBody:   `\"email\":\"alice@example.com\"`,
+		},
+		{
+			desc:       "missing claim",
+			body:       `{"query": "q"}`,
+			header:     map[string]string{"my-auth_token": `{"name": "alice"}`},
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   "no field named email in claims",
+		},
+		{
+			desc:       "missing token",
+			body:       `{"query": "q", "email": "mallory@example.com"}`,
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   "missing or invalid authentication header",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			resp, body, err := runRequest(ts, http.MethodPost, "/tool/echo/invoke", bytes.NewBuffer([]byte(tc.body)), tc.header)
+			if err != nil {
+				t.Fatalf("unexpected error during request: %s", err)
+			}
+			if resp.StatusCode != tc.wantStatus {
+				t.Fatalf("unexpected status code: got %d, want %d, %s", resp.StatusCode, tc.wantStatus, string(body))
+			}
+			if !strings.Contains(string(body), tc.wantBody) {
+				t.Fatalf("expected the body to contain %s, got %s", tc.wantBody, string(body))
+			}
+		})
+	}
+}
+
+func TestToolInvokeEndpointResultMetadata(t *testing.T) {
+	metadataTool := MockTool{
+		Name:     "metadata",
+		Params:   parameters.Parameters{},
+		Metadata: map[string]any{"jobId": "job_123"},
+	}
+	toolsMap, toolsets, _, _ := setUpResources(t, []MockTool{tool1, metadataTool}, nil)
+	r, shutdown := setUpServer(t, "api", toolsMap, toolsets, nil, nil)
+	defer shutdown()
+	ts := runServer(r, false)
+	defer ts.Close()
+
+	tcs := []struct {
+		desc         string
+		header       map[string]string
+		wantMetadata map[string]any
+	}{
+		{
+			desc: "metadata not requested",
+		},
+		{
+			desc:         "metadata requested",
+			header:       map[string]string{tools.IncludeMetadataHeader: "true"},
+			wantMetadata: map[string]any{"jobId": "job_123"},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			resp, body, err := runRequest(ts, http.MethodPost, "/tool/metadata/invoke", bytes.NewBuffer([]byte(`{}`)), tc.header)
+			if err != nil {
+				t.Fatalf("unexpected error during request: %s", err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("unexpected status code: got %d, want %d, %s", resp.StatusCode, http.StatusOK, string(body))
+			}
+			var got struct {
+				Result   string         `json:"result"`
+				Metadata map[string]any `json:"metadata"`
+			}
+			if err := json.Unmarshal(body, &got); err != nil {
+				t.Fatalf("unexpected error unmarshalling body: %s", err)
+			}
+			if got.Result != `["metadata"]` {
+				t.Fatalf("unexpected result: %s", got.Result)
+			}
+			if diff := cmp.Diff(tc.wantMetadata, got.Metadata); diff != "" {
+				t.Fatalf("unexpected metadata (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestToolInvokeEndpointErrorInfo(t *testing.T) {
+	agentErrTool := MockTool{
+		Name:   "agent_error",
+		Params: parameters.Parameters{},
+		Err: util.NewAgentError("access to dataset 'p.d' is not allowed", nil).
+			WithCode(util.ErrorCodePermissionDenied).
+			WithDetails(map[string]any{"datasets": []string{"p.d"}}),
+	}
+	serverErrTool := MockTool{
+		Name:   "server_error",
+		Params: parameters.Parameters{},
+		Err:    util.NewClientServerError("backend is unavailable", http.StatusServiceUnavailable, nil),
+	}
+	toolsMap, toolsets, _, _ := setUpResources(t, []MockTool{agentErrTool, serverErrTool}, nil)
+	r, shutdown := setUpServer(t, "api", toolsMap, toolsets, nil, nil)
+	defer shutdown()
+	ts := runServer(r, false)
+	defer ts.Close()
+
+	tcs := []struct {
+		desc       string
+		toolName   string
+		wantStatus int
+		want       map[string]any
+	}{
+		{
+			desc:       "agent error",
+			toolName:   agentErrTool.Name,
+			wantStatus: http.StatusOK,
+			want: map[string]any{
+				"result":    `{"error":"access to dataset 'p.d' is not allowed"}`,
+				"errorInfo": map[string]any{"code": "permission_denied", "retryable": false, "details": map[string]any{"datasets": []any{"p.d"}}, "requestId": "request-1"},
+			},
+		},
+		{
+			desc:       "server error",
+			toolName:   serverErrTool.Name,
+			wantStatus: http.StatusServiceUnavailable,
+			want: map[string]any{
+				"status":    "Service Unavailable",
+				"error":     "backend is unavailable",
+				"code":      "backend_unavailable",
+				"retryable": true,
+				"requestId": "request-1",
+			},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			resp, body, err := runRequest(ts, http.MethodPost, fmt.Sprintf("/tool/%s/invoke", tc.toolName), bytes.NewBuffer([]byte(`{}`)), map[string]string{tools.RequestIDHeader: "request-1"})
+			if err != nil {
+				t.Fatalf("unexpected error during request: %s", err)
+			}
+			if resp.StatusCode != tc.wantStatus {
+				t.Fatalf("unexpected status code: got %d, want %d, %s", resp.StatusCode, tc.wantStatus, string(body))
+			}
+			var got map[string]any
+			if err := json.Unmarshal(body, &got); err != nil {
+				t.Fatalf("unexpected error unmarshalling body: %s", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("unexpected response (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// loggingTool is a MockTool that logs before failing with its Err.
+type loggingTool struct {
+	MockTool
+}
+
+func (t loggingTool) Invoke(ctx context.Context, _ tools.SourceProvider, _ parameters.ParamValues, _ tools.AccessToken) (any, util.ToolboxError) {
+	logger, err := util.LoggerFromContext(ctx)
+	if err != nil {
+		return nil, util.NewClientServerError("unable to retrieve logger", http.StatusInternalServerError, err)
+	}
+	logger.DebugContext(ctx, fmt.Sprintf("logging tool %s invoked", t.Name))
+	if t.Err != nil {
+		logger.WarnContext(ctx, "logging tool failed")
+		return nil, t.Err
+	}
+	return t.Name, nil
+}
+
+func TestToolInvokeEndpointRequestID(t *testing.T) {
+	failingTool := MockTool{
+		Name:   "failing_tool",
+		Params: parameters.Parameters{},
+		Err:    util.NewAgentError("invalid query", nil),
+	}
+	toolsMap, toolsets, _, _ := setUpResources(t, []MockTool{failingTool, tool1}, nil)
+	toolsMap[failingTool.Name] = loggingTool{MockTool: failingTool}
+
+	var logs bytes.Buffer
+	testLogger, err := log.NewStdLogger(&logs, &logs, "info")
+	if err != nil {
+		t.Fatalf("unable to initialize logger: %s", err)
+	}
+	r, shutdown := setUpServerWithLogger(t, "api", testLogger, toolsMap, toolsets, nil, nil)
+	defer shutdown()
+	ts := runServer(r, false)
+	defer ts.Close()
+
+	tcs := []struct {
+		desc   string
+		header map[string]string
+		wantID string
+	}{
+		{
+			desc: "generated request ID",
+		},
+		{
+			desc:   "request ID set by the client",
+			header: map[string]string{tools.RequestIDHeader: "client-request-1"},
+			wantID: "client-request-1",
+		},
+		{
+			desc:   "invalid request ID set by the client",
+			header: map[string]string{tools.RequestIDHeader: "invalid request id"},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			logs.Reset()
+			resp, body, err := runRequest(ts, http.MethodPost, fmt.Sprintf("/tool/%s/invoke", failingTool.Name), bytes.NewBuffer([]byte(`{}`)), tc.header)
+			if err != nil {
+				t.Fatalf("unexpected error during request: %s", err)
+			}
+			var got resultResponse
+			if err := json.Unmarshal(body, &got); err != nil {
+				t.Fatalf("unexpected error unmarshalling body: %s", err)
+			}
+			if got.ErrorInfo == nil || got.ErrorInfo.RequestID == "" {
+				t.Fatalf("expected the error info to have a request ID, got %s", string(body))
+			}
+			requestID := got.ErrorInfo.RequestID
+			if tc.wantID != "" && requestID != tc.wantID {
+				t.Errorf("got request ID %q, want %q", requestID, tc.wantID)
+			}
+			if header := resp.Header.Get(tools.RequestIDHeader); header != requestID {
+				t.Errorf("got request ID %q in the response header, want %q", header, requestID)
+			}
+			for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+				if strings.Contains(line, "logging tool failed") && !strings.Contains(line, requestID) {
+					t.Errorf("expected the log of the tool to have request ID %q, got %q", requestID, line)
+				}
+			}
+			if !strings.Contains(logs.String(), "logging tool failed") {
+				t.Errorf("expected the tool to log, got %q", logs.String())
+			}
+		})
+	}
+}
+
+func TestToolInvokeEndpointLogLevel(t *testing.T) {
+	apiKey := parameters.NewStringParameter("api_key", "an API key")
+	apiKey.Sensitive = true
+	params := parameters.Parameters{apiKey}
+	toolsMap, toolsets, _, _ := setUpResources(t, []MockTool{tool1, {Name: "debug_tool", Params: params}, {Name: "info_tool", Params: params}}, nil)
+	for name, level := range map[string]string{"debug_tool": "DEBUG", "info_tool": "INFO"} {
+		tool, err := tools.WithCommonOptions(mockToolConfig{tool: loggingTool{MockTool: MockTool{Name: name, Params: params}}}, tools.CommonOptions{LogLevel: level}).Initialize(nil)
+		if err != nil {
+			t.Fatalf("unable to initialize tool: %s", err)
+		}
+		toolsMap[name] = tool
+	}
+
+	var logs bytes.Buffer
+	testLogger, err := log.NewStdLogger(&logs, &logs, "info")
+	if err != nil {
+		t.Fatalf("unable to initialize logger: %s", err)
+	}
+	r, shutdown := setUpServerWithLogger(t, "api", testLogger, toolsMap, toolsets, nil, nil)
+	defer shutdown()
+	ts := runServer(r, false)
+	defer ts.Close()
+
+	tcs := []struct {
+		toolName  string
+		wantDebug bool
+	}{
+		{toolName: "debug_tool", wantDebug: true},
+		{toolName: "info_tool", wantDebug: false},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.toolName, func(t *testing.T) {
+			logs.Reset()
+			body := bytes.NewBuffer([]byte(`{"api_key": "top-secret-key"}`))
+			resp, respBody, err := runRequest(ts, http.MethodPost, fmt.Sprintf("/tool/%s/invoke", tc.toolName), body, nil)
+			if err != nil {
+				t.Fatalf("unexpected error during request: %s", err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("response status code is not 200, got %d, %s", resp.StatusCode, string(respBody))
+			}
+
+			got := logs.String()
+			if logged := strings.Contains(got, fmt.Sprintf("logging tool %s invoked", tc.toolName)); logged != tc.wantDebug {
+				t.Errorf("got debug message of the tool logged %t, want %t: %q", logged, tc.wantDebug, got)
+			}
+			if logged := strings.Contains(got, "invocation params"); logged != tc.wantDebug {
+				t.Errorf("got debug message of the invocation logged %t, want %t: %q", logged, tc.wantDebug, got)
+			}
+			if strings.Contains(got, "top-secret-key") {
+				t.Errorf("sensitive value found in logs: %q", got)
+			}
+		})
+	}
+}
+
+// mockToolConfig is the config of a MockTool, used to initialize it with the
+// options available to every tool type.
+type mockToolConfig struct {
+	tool tools.Tool
+}
+
+func (c mockToolConfig) ToolConfigType() string { return "mock" }
+func (c mockToolConfig) Initialize(map[string]sources.Source) (tools.Tool, error) {
+	return c.tool, nil
+}
+
+func TestToolInvokeEndpointAuthTokenHeader(t *testing.T) {
+	toolsMap, toolsets, _, _ := setUpResources(t, []MockTool{tool1, tool5}, nil)
+	customHeaderTool, err := tools.WithCommonOptions(mockToolConfig{tool: tool5}, tools.CommonOptions{
+		AuthTokenHeader: "X-Serverless-Authorization",
+		AuthTokenScheme: tools.AuthTokenSchemeRaw,
+	}).Initialize(nil)
+	if err != nil {
+		t.Fatalf("unable to initialize tool: %s", err)
+	}
+	toolsMap["custom_header_tool"] = customHeaderTool
+	r, shutdown := setUpServer(t, "api", toolsMap, toolsets, nil, nil)
+	defer shutdown()
+	ts := runServer(r, false)
+	defer ts.Close()
+
+	tcs := []struct {
+		desc       string
+		toolName   string
+		header     map[string]string
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			desc:       "default header",
+			toolName:   tool5.Name,
+			header:     map[string]string{"Authorization": "Bearer token"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			desc:       "missing default header",
+			toolName:   tool5.Name,
+			wantStatus: http.StatusUnauthorized,
+			wantErr:    `missing from the \"Authorization\" header`,
+		},
+		{
+			desc:       "configured header",
+			toolName:   "custom_header_tool",
+			header:     map[string]string{"X-Serverless-Authorization": "token"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			desc:       "missing configured header",
+			toolName:   "custom_header_tool",
+			header:     map[string]string{"Authorization": "Bearer token"},
+			wantStatus: http.StatusUnauthorized,
+			wantErr:    `missing from the \"X-Serverless-Authorization\" header`,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			resp, body, err := runRequest(ts, http.MethodPost, fmt.Sprintf("/tool/%s/invoke", tc.toolName), bytes.NewBuffer([]byte(`{}`)), tc.header)
+			if err != nil {
+				t.Fatalf("unexpected error during request: %s", err)
+			}
+			if resp.StatusCode != tc.wantStatus {
+				t.Fatalf("unexpected status code: got %d, want %d, %s", resp.StatusCode, tc.wantStatus, string(body))
+			}
+			if !strings.Contains(string(body), tc.wantErr) {
+				t.Fatalf("expected the response to contain %q, got %s", tc.wantErr, string(body))
+			}
+		})
+	}
+}
@@ -131,12 +131,23 @@ func setUpResources(t *testing.T, mockTools []MockTool, mockPrompts []MockPrompt
 
 // setUpServer create a new server with tools, toolsets, prompts, and promptsets.
 func setUpServer(t *testing.T, router string, tools map[string]tools.Tool, toolsets map[string]tools.Toolset, prompts map[string]prompts.Prompt, promptsets map[string]prompts.Promptset) (chi.Router, func()) {
-	ctx, cancel := context.WithCancel(context.Background())
-
 	testLogger, err := log.NewStdLogger(os.Stdout, os.Stderr, "info")
 	if err != nil {
 		t.Fatalf("unable to initialize logger: %s", err)
 	}
+	return setUpServerWithLogger(t, router, testLogger, tools, toolsets, prompts, promptsets)
+}
+
+// setUpServerWithLogger is like setUpServer, but logs to the given logger.
+func setUpServerWithLogger(t *testing.T, router string, testLogger log.Logger, tools map[string]tools.Tool, toolsets map[string]tools.Toolset, prompts map[string]prompts.Prompt, promptsets map[string]prompts.Promptset) (chi.Router, func()) {
+	resourceManager := resources.NewResourceManager(nil, nil, nil, tools, toolsets, prompts, promptsets)
+	return setUpServerWithResourceManager(t, router, testLogger, resourceManager)
+}
+
+// setUpServerWithResourceManager is like setUpServerWithLogger, but serves the
+// resources of the given ResourceManager.
+func setUpServerWithResourceManager(t *testing.T, router string, testLogger log.Logger, resourceManager *resources.ResourceManager) (chi.Router, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
 
 	otelShutdown, err := telemetry.SetupOTel(ctx, fakeVersionString, "", false, "toolbox")
 	if err != nil {
@@ -150,8 +161,6 @@ func setUpServer(t *testing.T, router string, tools map[string]tools.Tool, tools
 
 	sseManager := newSseManager(ctx)
 
-	resourceManager := resources.NewResourceManager(nil, nil, nil, tools, toolsets, prompts, promptsets)
-
 	server := Server{
 		version:         fakeVersionString,
 		logger:          testLogger,
@@ -16,20 +16,31 @@ package server
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"reflect"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/go-playground/validator/v10"
 	yaml "github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/goccy/go-yaml/printer"
+	"github.com/goccy/go-yaml/token"
 	"github.com/googleapis/genai-toolbox/internal/auth"
 	"github.com/googleapis/genai-toolbox/internal/auth/google"
 	"github.com/googleapis/genai-toolbox/internal/embeddingmodels"
 	"github.com/googleapis/genai-toolbox/internal/embeddingmodels/gemini"
+	"github.com/googleapis/genai-toolbox/internal/log"
 	"github.com/googleapis/genai-toolbox/internal/prompts"
 	"github.com/googleapis/genai-toolbox/internal/sources"
 	"github.com/googleapis/genai-toolbox/internal/tools"
 	"github.com/googleapis/genai-toolbox/internal/util"
+	"golang.org/x/net/http/httpguts"
 )
 
 type ServerConfig struct {
@@ -77,6 +88,22 @@ type ServerConfig struct {
 	UserAgentMetadata []string
 	// PollInterval sets the polling frequency for configuration file updates.
 	PollInterval int
+	// EmbeddingCacheSize is the memory, in MiB, used to cache embeddings of
+	// parameters. The cache is disabled if it is zero.
+	EmbeddingCacheSize int
+	// EmbeddingCacheTTL is how long embeddings are cached. Zero keeps them
+	// until they are evicted.
+	EmbeddingCacheTTL time.Duration
+	// RequireDestructiveConfirmation refuses invocations of tools annotated as
+	// destructive unless the request confirms them.
+	RequireDestructiveConfirmation bool
+	// StrictBearerTokens requires client access tokens to be sent as
+	// "Bearer <token>", instead of also accepting tokens sent without a
+	// scheme that look like an OAuth2 access token or a JWT.
+	StrictBearerTokens bool
+	// MaxStreamBufferedChunks is the number of chunks of a streamed
+	// invocation buffered for the client before the tool is paused.
+	MaxStreamBufferedChunks int
 }
 
 type logFormat string
@@ -149,9 +176,18 @@ func UnmarshalResourceConfig(ctx context.Context, raw []byte) (SourceConfigs, Au
 	var promptConfigs PromptConfigs
 	// promptset configs is not yet supported
 
+	// policies are unmarshaled first, so that the resources of the
+	// documents before them can use them
+	type document struct {
+		doc        int
+		kind, name string
+		resource   map[string]any
+	}
+	var docs []document
+	policyConfigs := make(PolicyConfigs)
 	decoder := yaml.NewDecoder(bytes.NewReader(raw))
 	// for loop to unmarshal documents with the `---` separator
-	for {
+	for doc := 0; ; doc++ {
 		var resource map[string]any
 		if err := decoder.DecodeContext(ctx, &resource); err != nil {
 			if err == io.EOF {
@@ -170,8 +206,28 @@ func UnmarshalResourceConfig(ctx context.Context, raw []byte) (SourceConfigs, Au
 		// remove 'kind' from map for strict unmarshaling
 		delete(resource, "kind")
 
+		if kind == "policies" {
+			c, err := UnmarshalYAMLPolicyConfig(name, resource)
+			if err != nil {
+				return nil, nil, nil, nil, nil, nil, fmt.Errorf("error unmarshaling %s: %s", kind, err)
+			}
+			policyConfigs[name] = c
+			continue
+		}
+		docs = append(docs, document{doc: doc, kind: kind, name: name, resource: resource})
+	}
+
+	for _, d := range docs {
+		doc, kind, name, resource := d.doc, d.kind, d.name, d.resource
 		switch kind {
 		case "sources":
+			err := applyPolicy(policyConfigs, "source", name, resource, func(field string) bool {
+				resourceType, _ := resource["type"].(string)
+				return declaresSourceField(ctx, resourceType, name, field)
+			})
+			if err != nil {
+				return nil, nil, nil, nil, nil, nil, fmt.Errorf("error unmarshaling %s: %s", kind, err)
+			}
 			c, err := UnmarshalYAMLSourceConfig(ctx, name, resource)
 			if err != nil {
 				return nil, nil, nil, nil, nil, nil, fmt.Errorf("error unmarshaling %s: %s", kind, err)
@@ -190,8 +246,16 @@ func UnmarshalResourceConfig(ctx context.Context, raw []byte) (SourceConfigs, Au
 			}
 			authServiceConfigs[name] = c
 		case "tools":
+			err := applyPolicy(policyConfigs, "tool", name, resource, func(field string) bool {
+				resourceType, _ := resource["type"].(string)
+				return declaresField(ctx, tools.ResolveType(ctx, resourceType), name, field)
+			})
+			if err != nil {
+				return nil, nil, nil, nil, nil, nil, fmt.Errorf("error unmarshaling %s: %s", kind, err)
+			}
 			c, err := UnmarshalYAMLToolConfig(ctx, name, resource)
 			if err != nil {
+				setFieldErrorLine(err, raw, doc)
 				return nil, nil, nil, nil, nil, nil, fmt.Errorf("error unmarshaling %s: %s", kind, err)
 			}
 			if toolConfigs == nil {
@@ -237,6 +301,12 @@ func UnmarshalYAMLSourceConfig(ctx context.Context, name string, r map[string]an
 	if !ok {
 		return nil, fmt.Errorf("missing 'type' field or it is not a string")
 	}
+	// options available to all source types are removed before decoding the
+	// type-specific config
+	commonOpts, err := unmarshalCommonSourceOptions(name, r)
+	if err != nil {
+		return nil, err
+	}
 	dec, err := util.NewStrictDecoder(r)
 	if err != nil {
 		return nil, fmt.Errorf("error creating decoder: %w", err)
@@ -245,9 +315,55 @@ func UnmarshalYAMLSourceConfig(ctx context.Context, name string, r map[string]an
 	if err != nil {
 		return nil, err
 	}
+	if commonOpts != (sources.CommonOptions{}) {
+		sourceConfig = sources.WithCommonOptions(sourceConfig, commonOpts)
+	}
 	return sourceConfig, nil
 }
 
+// unmarshalCommonSourceOptions removes the fields of the options available to
+// all source types from r, and returns the options they set.
+func unmarshalCommonSourceOptions(name string, r map[string]any) (sources.CommonOptions, error) {
+	var opts sources.CommonOptions
+	if rawMax, ok := r["maxConcurrentInvocations"]; ok {
+		delete(r, "maxConcurrentInvocations")
+		var max int64
+		switch v := rawMax.(type) {
+		case int:
+			max = int64(v)
+		case int64:
+			max = v
+		case uint64:
+			max = int64(v)
+		default:
+			return opts, fmt.Errorf("source %q config error: 'maxConcurrentInvocations' must be a positive integer, got %v", name, rawMax)
+		}
+		if max <= 0 {
+			return opts, fmt.Errorf("source %q config error: 'maxConcurrentInvocations' must be a positive integer, got %v", name, rawMax)
+		}
+		opts.MaxConcurrentInvocations = max
+	}
+	if rawTimeout, ok := r["invocationQueueTimeout"]; ok {
+		delete(r, "invocationQueueTimeout")
+		if opts.MaxConcurrentInvocations == 0 {
+			return opts, fmt.Errorf("source %q config error: 'invocationQueueTimeout' requires 'maxConcurrentInvocations'", name)
+		}
+		timeoutStr, ok := rawTimeout.(string)
+		if !ok {
+			return opts, fmt.Errorf("source %q config error: 'invocationQueueTimeout' must be a duration string, e.g. \"30s\"", name)
+		}
+		timeout, err := time.ParseDuration(timeoutStr)
+		if err != nil {
+			return opts, fmt.Errorf("source %q config error: invalid 'invocationQueueTimeout' %q: %w", name, timeoutStr, err)
+		}
+		if timeout <= 0 {
+			return opts, fmt.Errorf("source %q config error: 'invocationQueueTimeout' must be positive, got %q", name, timeoutStr)
+		}
+		opts.InvocationQueueTimeout = timeout
+	}
+	return opts, nil
+}
+
 func UnmarshalYAMLAuthServiceConfig(ctx context.Context, name string, r map[string]any) (auth.AuthServiceConfig, error) {
 	resourceType, ok := r["type"].(string)
 	if !ok {
@@ -291,6 +407,9 @@ func UnmarshalYAMLToolConfig(ctx context.Context, name string, r map[string]any)
 	if !ok {
 		return nil, fmt.Errorf("missing 'type' field or it is not a string")
 	}
+	// Resolve aliases, so that the config holds the canonical type
+	resourceType = tools.ResolveType(ctx, resourceType)
+	r["type"] = resourceType
 	// `authRequired` and `useClientOAuth` cannot be specified together
 	if r["authRequired"] != nil && r["useClientOAuth"] == true {
 		return nil, fmt.Errorf("`authRequired` and `useClientOAuth` are mutually exclusive. Choose only one authentication method")
@@ -299,6 +418,12 @@ func UnmarshalYAMLToolConfig(ctx context.Context, name string, r map[string]any)
 	if r["authRequired"] == nil {
 		r["authRequired"] = []string{}
 	}
+	// options available to all tool types are removed before decoding the
+	// type-specific config
+	commonOpts, err := unmarshalCommonToolOptions(ctx, resourceType, name, r)
+	if err != nil {
+		return nil, err
+	}
 
 	// validify parameter references
 	if rawParams, ok := r["parameters"]; ok {
@@ -338,17 +463,244 @@ func UnmarshalYAMLToolConfig(ctx context.Context, name string, r map[string]any)
 		}
 	}
 
-	dec, err := util.NewStrictDecoder(r)
+	toolCfg, err := decodeToolConfig(ctx, resourceType, name, r)
+	if err != nil {
+		return nil, err
+	}
+	toolCfg, err = tools.ExpandConfig(ctx, toolCfg)
+	if err != nil {
+		return nil, fmt.Errorf("tool %q config error: %w", name, err)
+	}
+	if !reflect.DeepEqual(commonOpts, tools.CommonOptions{}) {
+		toolCfg = tools.WithCommonOptions(toolCfg, commonOpts)
+	}
+	return toolCfg, nil
+}
+
+// declaresField reports whether the config of tool type resourceType declares
+// field, see tools.ToolConfigWithFields.
+func declaresField(ctx context.Context, resourceType, name, field string) bool {
+	cfg, err := tools.DecodeConfig(ctx, resourceType, name, yaml.NewDecoder(strings.NewReader("{}")))
+	if err != nil {
+		return false
+	}
+	c, ok := cfg.(tools.ToolConfigWithFields)
+	return ok && slices.Contains(c.DeclaredFields(), field)
+}
+
+// declaresSourceField reports whether the config of source type resourceType
+// declares field, see sources.SourceConfigWithFields.
+func declaresSourceField(ctx context.Context, resourceType, name, field string) bool {
+	cfg, err := sources.DecodeConfig(ctx, resourceType, name, yaml.NewDecoder(strings.NewReader("{}")))
+	if err != nil {
+		return false
+	}
+	c, ok := cfg.(sources.SourceConfigWithFields)
+	return ok && slices.Contains(c.DeclaredFields(), field)
+}
+
+// decodeToolConfig decodes r, the config of tool name of type resourceType.
+// Fields that the config does not declare are rejected. Errors in fields are
+// reported as a *fieldError.
+func decodeToolConfig(ctx context.Context, resourceType, name string, r map[string]any) (tools.ToolConfig, error) {
+	b, err := yaml.Marshal(r)
 	if err != nil {
 		return nil, fmt.Errorf("error creating decoder: %s", err)
 	}
-	toolCfg, err := tools.DecodeConfig(ctx, resourceType, name, dec)
+	toolCfg, err := tools.DecodeConfig(ctx, resourceType, name, yaml.NewDecoder(bytes.NewReader(b), yaml.Strict(), yaml.Validator(validator.New())))
 	if err != nil {
-		return nil, err
+		var yamlErr yaml.Error
+		if !errors.As(err, &yamlErr) {
+			return nil, err
+		}
+		path := fieldPath(b, yamlErr.GetToken())
+		if path == "" {
+			return nil, err
+		}
+		var pr printer.Printer
+		return nil, &fieldError{tool: name, resourceType: resourceType, path: path, msg: yamlErr.GetMessage(), excerpt: pr.PrintErrorToken(yamlErr.GetToken(), false)}
 	}
 	return toolCfg, nil
 }
 
+// fieldError is an error in a field of the YAML config of a tool.
+type fieldError struct {
+	tool         string
+	resourceType string
+	// path is the YAML path of the field, e.g. "$.parameters[0].type".
+	path string
+	// line is the line of the field in the YAML file, or 0 if unknown.
+	line int
+	msg  string
+	// excerpt is the annotated YAML source around the field.
+	excerpt string
+}
+
+func (e *fieldError) Error() string {
+	path := strings.TrimPrefix(e.path, "$.")
+	if e.line > 0 {
+		return fmt.Sprintf("unable to parse tool %q as type %q: field %q (line %d): %s\n%s", e.tool, e.resourceType, path, e.line, e.msg, e.excerpt)
+	}
+	return fmt.Sprintf("unable to parse tool %q as type %q: field %q: %s\n%s", e.tool, e.resourceType, path, e.msg, e.excerpt)
+}
+
+// setFieldErrorLine sets the line and excerpt of the field of err, if it is a
+// *fieldError, to those in the document at index doc of the YAML file raw.
+func setFieldErrorLine(err error, raw []byte, doc int) {
+	var fe *fieldError
+	if !errors.As(err, &fe) {
+		return
+	}
+	f, perr := parser.ParseBytes(raw, 0)
+	if perr != nil || doc >= len(f.Docs) {
+		return
+	}
+	tok := fieldToken(f.Docs[doc], fe.path)
+	if tok == nil {
+		return
+	}
+	var pr printer.Printer
+	fe.line = tok.Position.Line
+	fe.excerpt = pr.PrintErrorToken(tok, false)
+}
+
+// fieldPath returns the YAML path of the node of tok in the YAML document b,
+// or "" if there is none.
+func fieldPath(b []byte, tok *token.Token) string {
+	if tok == nil {
+		return ""
+	}
+	f, err := parser.ParseBytes(b, 0)
+	if err != nil || len(f.Docs) == 0 {
+		return ""
+	}
+	var path string
+	ast.Walk(nodeVisitor(func(n ast.Node) bool {
+		if path != "" {
+			return false
+		}
+		if t := n.GetToken(); t != nil && t.Value == tok.Value && t.Position.Offset == tok.Position.Offset {
+			path = n.GetPath()
+		}
+		return path == ""
+	}), f.Docs[0].Body)
+	return path
+}
+
+// fieldToken returns the token of the field at path in doc, or nil if there
+// is none.
+func fieldToken(doc *ast.DocumentNode, path string) *token.Token {
+	var tok *token.Token
+	ast.Walk(nodeVisitor(func(n ast.Node) bool {
+		if tok != nil {
+			return false
+		}
+		if n.GetPath() == path {
+			// Report the key of mapping values, as their value can start on
+			// the next line.
+			if mv, ok := n.(*ast.MappingValueNode); ok {
+				n = mv.Key
+			}
+			tok = n.GetToken()
+		}
+		return tok == nil
+	}), doc.Body)
+	return tok
+}
+
+// nodeVisitor visits the nodes of a YAML document for as long as it returns
+// true.
+type nodeVisitor func(ast.Node) bool
+
+func (v nodeVisitor) Visit(n ast.Node) ast.Visitor {
+	if n == nil || !v(n) {
+		return nil
+	}
+	return v
+}
+
+// unmarshalCommonToolOptions removes the fields of the options available to
+// all tool types from r, and returns the options they set. Fields that the
+// config of the tool's type declares itself, such as the "timeout" of some
+// tools, are left to it.
+func unmarshalCommonToolOptions(ctx context.Context, resourceType, name string, r map[string]any) (tools.CommonOptions, error) {
+	var opts tools.CommonOptions
+	if rawTimeout, ok := r["timeout"]; ok && !declaresField(ctx, resourceType, name, "timeout") {
+		delete(r, "timeout")
+		timeoutStr, ok := rawTimeout.(string)
+		if !ok {
+			return opts, fmt.Errorf("tool %q config error: 'timeout' must be a duration string, e.g. \"30s\"", name)
+		}
+		timeout, err := time.ParseDuration(timeoutStr)
+		if err != nil {
+			return opts, fmt.Errorf("tool %q config error: invalid 'timeout' %q: %w", name, timeoutStr, err)
+		}
+		if timeout <= 0 {
+			return opts, fmt.Errorf("tool %q config error: 'timeout' must be positive, got %q", name, timeoutStr)
+		}
+		opts.Timeout = timeout
+	}
+	if rawMode, ok := r["authRequiredMode"]; ok {
+		delete(r, "authRequiredMode")
+		mode, _ := rawMode.(string)
+		switch tools.AuthRequiredMode(mode) {
+		case tools.AuthRequiredAny:
+			// the default, which is not recorded
+		case tools.AuthRequiredAll:
+			opts.AuthRequiredMode = tools.AuthRequiredAll
+		default:
+			return opts, fmt.Errorf("tool %q config error: 'authRequiredMode' must be one of %q or %q, got %v", name, tools.AuthRequiredAny, tools.AuthRequiredAll, rawMode)
+		}
+	}
+	if rawHeader, ok := r["authTokenHeader"]; ok {
+		delete(r, "authTokenHeader")
+		header, _ := rawHeader.(string)
+		if !httpguts.ValidHeaderFieldName(header) {
+			return opts, fmt.Errorf("tool %q config error: invalid 'authTokenHeader' %v", name, rawHeader)
+		}
+		opts.AuthTokenHeader = header
+	}
+	if rawScheme, ok := r["authTokenScheme"]; ok {
+		delete(r, "authTokenScheme")
+		scheme, _ := rawScheme.(string)
+		// custom schemes are a prefix followed by a space, so they cannot
+		// contain spaces themselves
+		if scheme == "" || strings.ContainsAny(scheme, " \t") {
+			return opts, fmt.Errorf("tool %q config error: 'authTokenScheme' must be %q, %q, or a custom prefix without spaces, got %v", name, tools.AuthTokenSchemeBearer, tools.AuthTokenSchemeRaw, rawScheme)
+		}
+		if !strings.EqualFold(scheme, tools.AuthTokenSchemeBearer) {
+			opts.AuthTokenScheme = scheme
+		}
+	}
+	if rawLevel, ok := r["logLevel"]; ok {
+		delete(r, "logLevel")
+		level, _ := rawLevel.(string)
+		if _, err := log.SeverityToLevel(level); err != nil {
+			return opts, fmt.Errorf("tool %q config error: 'logLevel' must be one of 'DEBUG', 'INFO', 'WARN', or 'ERROR', got %v", name, rawLevel)
+		}
+		opts.LogLevel = strings.ToUpper(level)
+	}
+	if rawSources, ok := r["sources"]; ok && !declaresField(ctx, resourceType, name, "sources") {
+		delete(r, "sources")
+		list, _ := rawSources.([]any)
+		for _, rawSource := range list {
+			source, ok := rawSource.(string)
+			if !ok || source == "" || slices.Contains(opts.Sources, source) {
+				return opts, fmt.Errorf("tool %q config error: 'sources' must be a list of unique source names, got %v", name, rawSources)
+			}
+			opts.Sources = append(opts.Sources, source)
+		}
+		if len(opts.Sources) == 0 {
+			return opts, fmt.Errorf("tool %q config error: 'sources' must list at least one source", name)
+		}
+		// the first source is the default of tools that do not set one
+		if _, ok := r["source"]; !ok {
+			r["source"] = opts.Sources[0]
+		}
+	}
+	return opts, nil
+}
+
 func UnmarshalYAMLToolsetConfig(ctx context.Context, name string, r map[string]any) (tools.ToolsetConfig, error) {
 	var toolsetConfig tools.ToolsetConfig
 	toolList, ok := r["tools"].([]any)
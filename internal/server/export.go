@@ -0,0 +1,148 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	yaml "github.com/goccy/go-yaml"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+)
+
+// ExportToolConfigs returns the configs of toolsMap, as loaded by the server
+// after defaults and normalization, as a YAML file with a document per tool in
+// the order of their names. The parameters that a tool generates, rather than
+// takes from its config, are described in a comment before its document.
+func ExportToolConfigs(toolsMap map[string]tools.Tool) ([]byte, error) {
+	var buf bytes.Buffer
+	for i, name := range slices.Sorted(maps.Keys(toolsMap)) {
+		doc, err := exportToolConfig(name, toolsMap[name])
+		if err != nil {
+			return nil, fmt.Errorf("unable to export tool %q: %w", name, err)
+		}
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.Write(doc)
+	}
+	return buf.Bytes(), nil
+}
+
+func exportToolConfig(name string, tool tools.Tool) ([]byte, error) {
+	cfg := tool.ToConfig()
+	b, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var fields yaml.MapSlice
+	if err := yaml.UnmarshalWithOptions(b, &fields, yaml.UseOrderedMap()); err != nil {
+		return nil, err
+	}
+	doc := yaml.MapSlice{{Key: "kind", Value: "tools"}, {Key: "name", Value: name}}
+	for _, f := range pruneZeroValues(fields) {
+		if f.Key != "kind" && f.Key != "name" {
+			doc = append(doc, f)
+		}
+	}
+	out, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var generated []string
+	configured := configParameterNames(cfg)
+	for _, p := range tool.GetParameters() {
+		if _, ok := configured[p.GetName()]; !ok {
+			desc := strings.Join(strings.Fields(p.Manifest().Description), " ")
+			generated = append(generated, fmt.Sprintf("#   %s (%s): %s\n", p.GetName(), p.GetType(), desc))
+		}
+	}
+	if len(generated) == 0 {
+		return out, nil
+	}
+	return append([]byte("# Parameters generated by the tool:\n"+strings.Join(generated, "")), out...), nil
+}
+
+// pruneZeroValues returns fields without the fields whose values are zero,
+// e.g. null or empty, as they decode like absent fields.
+func pruneZeroValues(fields yaml.MapSlice) yaml.MapSlice {
+	pruned := make(yaml.MapSlice, 0, len(fields))
+	for _, f := range fields {
+		if v := pruneValue(f.Value); v != nil {
+			pruned = append(pruned, yaml.MapItem{Key: f.Key, Value: v})
+		}
+	}
+	return pruned
+}
+
+// pruneValue returns v without its zero fields, or nil if v is zero.
+func pruneValue(v any) any {
+	switch v := v.(type) {
+	case yaml.MapSlice:
+		if pruned := pruneZeroValues(v); len(pruned) > 0 {
+			return pruned
+		}
+		return nil
+	case []any:
+		if len(v) == 0 {
+			return nil
+		}
+		items := make([]any, len(v))
+		for i, item := range v {
+			if items[i] = pruneValue(item); items[i] == nil {
+				// keep the zero items of lists, as their positions matter
+				items[i] = item
+			}
+		}
+		return items
+	case string, bool, uint64, int64, float64:
+		if reflect.ValueOf(v).IsZero() {
+			return nil
+		}
+	}
+	return v
+}
+
+// configParameterNames returns the names of the parameters and template
+// parameters set in cfg.
+func configParameterNames(cfg tools.ToolConfig) map[string]struct{} {
+	if c, ok := cfg.(tools.CommonConfig); ok {
+		cfg = c.ToolConfig
+	}
+	names := make(map[string]struct{})
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Struct {
+		return names
+	}
+	for i := 0; i < v.NumField(); i++ {
+		if !v.Type().Field(i).IsExported() {
+			continue
+		}
+		params, ok := v.Field(i).Interface().(parameters.Parameters)
+		if !ok {
+			continue
+		}
+		for _, p := range params {
+			names[p.GetName()] = struct{}{}
+		}
+	}
+	return names
+}
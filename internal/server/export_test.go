@@ -0,0 +1,96 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/server"
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	bigqueryds "github.com/googleapis/genai-toolbox/internal/sources/bigquery"
+	"github.com/googleapis/genai-toolbox/internal/testutils"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	_ "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigqueryconversationalanalytics"
+	_ "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigqueryexecutesql"
+	_ "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerylisttableids"
+	_ "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerysql"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+var update = flag.Bool("update", false, "update the golden files of the tests")
+
+func TestExportToolConfigs(t *testing.T) {
+	ctx, err := testutils.ContextWithNewLogger()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ctx = util.WithUserAgent(ctx, "test-agent")
+	src, err := bigqueryds.Config{Name: "my-bigquery", Type: bigqueryds.SourceType, Project: "my-project", UseClientOAuth: true}.Initialize(ctx, noop.NewTracerProvider().Tracer(""))
+	if err != nil {
+		t.Fatalf("unable to initialize source: %s", err)
+	}
+	srcs := map[string]sources.Source{"my-bigquery": src}
+
+	in, err := os.ReadFile(filepath.Join("testdata", "export_tools.yaml"))
+	if err != nil {
+		t.Fatalf("unable to read tools: %s", err)
+	}
+	_, _, _, toolConfigs, _, _, err := server.UnmarshalResourceConfig(ctx, in)
+	if err != nil {
+		t.Fatalf("unable to unmarshal tools: %s", err)
+	}
+	toolsMap := make(map[string]tools.Tool, len(toolConfigs))
+	for name, cfg := range toolConfigs {
+		tool, err := cfg.Initialize(srcs)
+		if err != nil {
+			t.Fatalf("unable to initialize tool %q: %s", name, err)
+		}
+		toolsMap[name] = tool
+	}
+
+	got, err := server.ExportToolConfigs(toolsMap)
+	if err != nil {
+		t.Fatalf("unable to export tools: %s", err)
+	}
+	golden := filepath.Join("testdata", "export_tools.golden")
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatalf("unable to update golden file: %s", err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("unable to read golden file: %s", err)
+	}
+	if diff := cmp.Diff(string(want), string(got)); diff != "" {
+		t.Errorf("unexpected export (-want +got):\n%s", diff)
+	}
+
+	// The export loads to the configs of the tools.
+	_, _, _, reloaded, _, _, err := server.UnmarshalResourceConfig(ctx, got)
+	if err != nil {
+		t.Fatalf("unable to unmarshal export: %s", err)
+	}
+	for name, tool := range toolsMap {
+		if diff := cmp.Diff(tool.ToConfig(), reloaded[name]); diff != "" {
+			t.Errorf("config of tool %q does not round-trip (-want +got):\n%s", name, diff)
+		}
+	}
+}
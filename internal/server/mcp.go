@@ -51,6 +51,24 @@ type sseSession struct {
 	lastActive time.Time
 }
 
+// notify queues notification as an event of the session. Unlike responses,
+// notifications wait for room in the queue, so that a slow client slows down
+// the request sending them.
+func (s *sseSession) notify(ctx context.Context, notification any) error {
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification to JSON: %w", err)
+	}
+	select {
+	case s.eventQueue <- fmt.Sprintf("event: message\ndata: %s\n\n", data):
+		return nil
+	case <-s.done:
+		return fmt.Errorf("sse session is closed")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // sseManager manages and control access to sse sessions
 type sseManager struct {
 	mu          sync.Mutex
@@ -204,6 +222,8 @@ func (s *stdioSession) readInputStream(ctx context.Context) error {
 		)
 		defer span.End()
 
+		msgCtx = mcputil.WithNotifier(msgCtx, s.write)
+
 		v, res, err := processMcpMessage(msgCtx, []byte(line), s.server, s.protocol, "", "", nil, "")
 		if err != nil {
 			// errors during the processing of message will generate a valid MCP Error response.
@@ -483,6 +503,12 @@ func httpHandler(s *Server, w http.ResponseWriter, r *http.Request) {
 
 	networkProtocolVersion := fmt.Sprintf("%d.%d", r.ProtoMajor, r.ProtoMinor)
 
+	// notifications of the request, such as its progress, are sent as events
+	// of the sse session
+	if session != nil {
+		ctx = mcputil.WithNotifier(ctx, session.notify)
+	}
+
 	v, res, err := processMcpMessage(ctx, body, s, protocolVersion, toolsetName, promptsetName, r.Header, networkProtocolVersion)
 	if err != nil {
 		s.logger.DebugContext(ctx, fmt.Errorf("error processing message: %w", err).Error())
@@ -0,0 +1,101 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/googleapis/genai-toolbox/internal/server/mcp/jsonrpc"
+	"github.com/googleapis/genai-toolbox/internal/server/resources"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+)
+
+// PROGRESS_NOTIFICATION is the method of the notifications reporting the
+// progress of a request.
+const PROGRESS_NOTIFICATION = "notifications/progress"
+
+// ProgressParams are the params of a progress notification.
+type ProgressParams struct {
+	Meta map[string]any `json:"_meta,omitempty"`
+	// The progress token of the request whose progress is reported.
+	ProgressToken jsonrpc.ProgressToken `json:"progressToken"`
+	// The progress so far, which increases with each notification.
+	Progress float64 `json:"progress"`
+	// A description of the progress.
+	Message string `json:"message,omitempty"`
+}
+
+// ProgressNotification is sent from the server to the client to report the
+// progress of a request that asked for it with a progress token.
+type ProgressNotification struct {
+	Jsonrpc string         `json:"jsonrpc"`
+	Method  string         `json:"method"`
+	Params  ProgressParams `json:"params"`
+}
+
+// Notifier sends a notification to the client of the session that received
+// a request.
+type Notifier func(ctx context.Context, notification any) error
+
+type notifierKey struct{}
+
+// WithNotifier returns a context whose requests send their notifications
+// with notify. Transports that cannot send notifications while a request is
+// processed do not set it.
+func WithNotifier(ctx context.Context, notify Notifier) context.Context {
+	return context.WithValue(ctx, notifierKey{}, notify)
+}
+
+// NotifierFromContext returns the notifier of the session of the request, if
+// any.
+func NotifierFromContext(ctx context.Context) (Notifier, bool) {
+	notify, ok := ctx.Value(notifierKey{}).(Notifier)
+	return notify, ok && notify != nil
+}
+
+// InvokeTool invokes the tool named toolName like tools.InvokeWithTimeout.
+//
+// If the tool streams its result, the request asked for progress with
+// progressToken and the session can send notifications, the tool is streamed
+// instead: each chunk of the result is sent in a progress notification as it
+// is read, and the result returned holds all the chunks.
+func InvokeTool(ctx context.Context, toolName string, tool tools.Tool, resourceMgr *resources.ResourceManager, sourceProvider tools.SourceProvider, params parameters.ParamValues, accessToken tools.AccessToken, progressToken jsonrpc.ProgressToken) (any, util.ToolboxError) {
+	notify, ok := NotifierFromContext(ctx)
+	if !ok || progressToken == nil || !tools.SupportsStreaming(tool) {
+		return tools.InvokeWithTimeout(ctx, toolName, tool, sourceProvider, params, accessToken)
+	}
+	var result []any
+	collect := tools.CollectChunks(&result)
+	err := tools.StreamWithTimeout(ctx, toolName, tool, sourceProvider, params, accessToken, resourceMgr.GetMaxStreamBufferedChunks(), func(chunk any) error {
+		_ = collect(chunk)
+		return notify(ctx, ProgressNotification{
+			Jsonrpc: jsonrpc.JSONRPC_VERSION,
+			Method:  PROGRESS_NOTIFICATION,
+			Params: ProgressParams{
+				Meta:          map[string]any{tools.StreamChunkMetaKey: chunk},
+				ProgressToken: progressToken,
+				Progress:      float64(len(result)),
+				Message:       fmt.Sprintf("received %d results", len(result)),
+			},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -24,10 +24,10 @@ import (
 
 	"github.com/googleapis/genai-toolbox/internal/prompts"
 	"github.com/googleapis/genai-toolbox/internal/server/mcp/jsonrpc"
+	mcputil "github.com/googleapis/genai-toolbox/internal/server/mcp/util"
 	"github.com/googleapis/genai-toolbox/internal/server/resources"
 	"github.com/googleapis/genai-toolbox/internal/tools"
 	"github.com/googleapis/genai-toolbox/internal/util"
-	"github.com/googleapis/genai-toolbox/internal/util/parameters"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -67,10 +67,11 @@ func toolsListHandler(id jsonrpc.RequestId, toolset tools.Toolset, body []byte)
 		return jsonrpc.NewError(id, jsonrpc.INVALID_REQUEST, err.Error(), nil), err
 	}
 
-	// exclude annotations from this version
+	// exclude annotations and output schemas from this version
 	manifests := make([]tools.McpManifest, len(toolset.McpManifest))
 	for i, m := range toolset.McpManifest {
 		m.Annotations = nil
+		m.OutputSchema = nil
 		manifests[i] = m
 	}
 
@@ -88,7 +89,9 @@ func toolsListHandler(id jsonrpc.RequestId, toolset tools.Toolset, body []byte)
 func toolsCallHandler(ctx context.Context, id jsonrpc.RequestId, resourceMgr *resources.ResourceManager, body []byte, header http.Header) (any, error) {
 	authServices := resourceMgr.GetAuthServiceMap()
 
-	// retrieve logger from context
+	// retrieve logger from context, which adds the request ID of the
+	// invocation to the messages
+	ctx, _ = tools.WithRequestID(ctx, header)
 	logger, err := util.LoggerFromContext(ctx)
 	if err != nil {
 		return jsonrpc.NewError(id, jsonrpc.INTERNAL_ERROR, err.Error(), nil), err
@@ -112,14 +115,20 @@ func toolsCallHandler(ctx context.Context, id jsonrpc.RequestId, resourceMgr *re
 		attribute.String("gen_ai.operation.name", "execute_tool"),
 	)
 
-	tool, ok := resourceMgr.GetTool(toolName)
+	tool, sourceProvider, ok := resourceMgr.GetToolWithSources(toolName)
 	if !ok {
 		err = fmt.Errorf("invalid tool name: tool with name %q does not exist", toolName)
 		return jsonrpc.NewError(id, jsonrpc.INVALID_PARAMS, err.Error(), nil), err
 	}
 
+	// the tool's log level applies to the rest of the invocation
+	ctx = tools.WithToolLogLevel(ctx, tool)
+	if logger, err = util.LoggerFromContext(ctx); err != nil {
+		return jsonrpc.NewError(id, jsonrpc.INTERNAL_ERROR, err.Error(), nil), err
+	}
+
 	// Get access token
-	authTokenHeadername, err := tool.GetAuthTokenHeaderName(resourceMgr)
+	authTokenHeadername, err := tool.GetAuthTokenHeaderName(sourceProvider)
 	if err != nil {
 		errMsg := fmt.Errorf("error during invocation: %w", err)
 		return jsonrpc.NewError(id, jsonrpc.INTERNAL_ERROR, errMsg.Error(), nil), errMsg
@@ -127,7 +136,7 @@ func toolsCallHandler(ctx context.Context, id jsonrpc.RequestId, resourceMgr *re
 	accessToken := tools.AccessToken(header.Get(authTokenHeadername))
 
 	// Check if this specific tool requires the standard authorization header
-	clientAuth, err := tool.RequiresClientAuthorization(resourceMgr)
+	clientAuth, err := tool.RequiresClientAuthorization(sourceProvider)
 	if err != nil {
 		errMsg := fmt.Errorf("error during invocation: %w", err)
 		return jsonrpc.NewError(id, jsonrpc.INTERNAL_ERROR, errMsg.Error(), nil), errMsg
@@ -135,7 +144,7 @@ func toolsCallHandler(ctx context.Context, id jsonrpc.RequestId, resourceMgr *re
 	if clientAuth {
 		if accessToken == "" {
 			err := util.NewClientServerError(
-				"missing access token in the 'Authorization' header",
+				fmt.Sprintf("missing access token in the '%s' header", authTokenHeadername),
 				http.StatusUnauthorized,
 				nil,
 			)
@@ -143,6 +152,21 @@ func toolsCallHandler(ctx context.Context, id jsonrpc.RequestId, resourceMgr *re
 		}
 	}
 
+	// plans have no side effects, so they need no confirmation
+	plan, _ := req.Params.Meta[tools.PlanMetaKey].(bool)
+	if plan {
+		if !tools.SupportsPlanning(tool) {
+			err = fmt.Errorf("tool %q does not support planning", toolName)
+			return jsonrpc.NewError(id, jsonrpc.INVALID_REQUEST, err.Error(), nil), err
+		}
+	} else {
+		confirmed, _ := req.Params.Meta[tools.ConfirmDestructiveMetaKey].(bool)
+		if err := resourceMgr.CheckDestructiveConfirmation(toolName, tool, confirmed); err != nil {
+			err = fmt.Errorf("%w: set %q to true in the request's _meta to confirm it", err, tools.ConfirmDestructiveMetaKey)
+			return jsonrpc.NewError(id, jsonrpc.INVALID_REQUEST, err.Error(), nil), err
+		}
+	}
+
 	// marshal arguments and decode it using decodeJSON instead to prevent loss between floats/int.
 	aMarshal, err := json.Marshal(toolArgument)
 	if err != nil {
@@ -196,12 +220,15 @@ func toolsCallHandler(ctx context.Context, id jsonrpc.RequestId, resourceMgr *re
 	}
 	logger.DebugContext(ctx, "tool invocation authorized")
 
-	params, err := parameters.ParseParams(tool.GetParameters(), data, claimsFromAuth)
+	params, err := tools.ParseParams(tool, data, claimsFromAuth)
 	if err != nil {
 		err = fmt.Errorf("provided parameters were invalid: %w", err)
 		return jsonrpc.NewError(id, jsonrpc.INVALID_PARAMS, err.Error(), nil), err
 	}
-	logger.DebugContext(ctx, fmt.Sprintf("invocation params: %s", params))
+	logger.DebugContext(ctx, fmt.Sprintf("invocation params: %v", tools.LoggableParams(tool, sourceProvider, params)))
+	if coerced := params.Coerced(); len(coerced) > 0 {
+		logger.DebugContext(ctx, fmt.Sprintf("coerced string inputs of parameters: %q", coerced))
+	}
 
 	embeddingModels := resourceMgr.GetEmbeddingModelMap()
 	params, err = tool.EmbedParams(ctx, params, embeddingModels)
@@ -211,7 +238,14 @@ func toolsCallHandler(ctx context.Context, id jsonrpc.RequestId, resourceMgr *re
 	}
 
 	// run tool invocation and generate response.
-	results, err := tool.Invoke(ctx, resourceMgr, params, accessToken)
+	var results any
+	if plan {
+		results, err = tools.PlanWithTimeout(ctx, toolName, tool, sourceProvider, params, accessToken)
+	} else {
+		// tools that stream their result report its chunks as progress, if
+		// the request asks for it
+		results, err = mcputil.InvokeTool(ctx, toolName, tool, resourceMgr, sourceProvider, params, accessToken, req.Params.Meta["progressToken"])
+	}
 	if err != nil {
 		var tbErr util.ToolboxError
 
@@ -224,10 +258,15 @@ func toolsCallHandler(ctx context.Context, id jsonrpc.RequestId, resourceMgr *re
 					Type: "text",
 					Text: err.Error(),
 				}
+				// the error info is execution metadata, sent if requested
+				var meta map[string]any
+				if includeMetadata, _ := req.Params.Meta[tools.IncludeMetadataMetaKey].(bool); includeMetadata {
+					meta = map[string]any{tools.ErrorInfoMetaKey: util.RequestErrorInfo(ctx, tbErr)}
+				}
 				return jsonrpc.JSONRPCResponse{
 					Jsonrpc: jsonrpc.JSONRPC_VERSION,
 					Id:      id,
-					Result:  CallToolResult{Content: []TextContent{text}, IsError: true},
+					Result:  CallToolResult{Result: jsonrpc.Result{Meta: meta}, Content: []TextContent{text}, IsError: true},
 				}, nil
 
 			case util.CategoryServer:
@@ -245,13 +284,16 @@ func toolsCallHandler(ctx context.Context, id jsonrpc.RequestId, resourceMgr *re
 						}
 					}
 				}
-				return jsonrpc.NewError(id, rpcCode, err.Error(), nil), err
+				return jsonrpc.NewError(id, rpcCode, err.Error(), util.RequestErrorInfo(ctx, tbErr)), err
 			}
 		} else {
 			// Unknown error -> 500
 			return jsonrpc.NewError(id, jsonrpc.INTERNAL_ERROR, err.Error(), nil), err
 		}
 	}
+	includeMetadata, _ := req.Params.Meta[tools.IncludeMetadataMetaKey].(bool)
+	results, metadata := tools.UnwrapResult(results, includeMetadata)
+
 	content := make([]TextContent, 0)
 
 	sliceRes, ok := results.([]any)
@@ -273,7 +315,7 @@ func toolsCallHandler(ctx context.Context, id jsonrpc.RequestId, resourceMgr *re
 	return jsonrpc.JSONRPCResponse{
 		Jsonrpc: jsonrpc.JSONRPC_VERSION,
 		Id:      id,
-		Result:  CallToolResult{Content: content},
+		Result:  CallToolResult{Result: jsonrpc.Result{Meta: metadata}, Content: content},
 	}, nil
 }
 
@@ -27,10 +27,13 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/googleapis/genai-toolbox/internal/log"
 	"github.com/googleapis/genai-toolbox/internal/server/mcp/jsonrpc"
 	"github.com/googleapis/genai-toolbox/internal/server/resources"
 	"github.com/googleapis/genai-toolbox/internal/telemetry"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	"github.com/googleapis/genai-toolbox/internal/util"
 )
 
 const jsonrpcVersion = "2.0"
@@ -363,6 +366,249 @@ func TestMcpEndpointWithoutInitialized(t *testing.T) {
 	}
 }
 
+func TestMcpToolsListOutputSchema(t *testing.T) {
+	outputSchema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"permissions": map[string]any{
+				"type":  "array",
+				"items": map[string]any{"type": "string"},
+			},
+		},
+		"required": []any{"permissions"},
+	}
+	toolWithOutputSchema := MockTool{
+		Name:         "output_schema",
+		OutputSchema: outputSchema,
+		Result:       map[string]any{"permissions": []any{"use"}},
+	}
+	toolsMap, toolsets, promptsMap, promptsets := setUpResources(t, []MockTool{tool1, toolWithOutputSchema}, []MockPrompt{prompt1})
+	r, shutdown := setUpServer(t, "mcp", toolsMap, toolsets, promptsMap, promptsets)
+	defer shutdown()
+	ts := runServer(r, false)
+	defer ts.Close()
+
+	reqMarshal, err := json.Marshal(jsonrpc.JSONRPCRequest{
+		Jsonrpc: jsonrpcVersion,
+		Id:      "tools-list",
+		Request: jsonrpc.Request{Method: "tools/list"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error during marshaling of body")
+	}
+	header := map[string]string{"MCP-Protocol-Version": protocolVersion20250618}
+	_, body, err := runRequest(ts, http.MethodPost, "/", bytes.NewBuffer(reqMarshal), header)
+	if err != nil {
+		t.Fatalf("unexpected error during request: %s", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("unexpected error unmarshalling body: %s", err)
+	}
+	want := []any{
+		map[string]any{
+			"name":        "no_params",
+			"inputSchema": basicInputSchema,
+		},
+		map[string]any{
+			"name":         "output_schema",
+			"inputSchema":  basicInputSchema,
+			"outputSchema": outputSchema,
+		},
+	}
+	result, _ := got["result"].(map[string]any)
+	if !reflect.DeepEqual(result["tools"], want) {
+		t.Fatalf("unexpected tools: got %s, want %+v", body, want)
+	}
+
+	// output schemas are not part of earlier protocol versions
+	_, body, err = runRequest(ts, http.MethodPost, "/", bytes.NewBuffer(reqMarshal), nil)
+	if err != nil {
+		t.Fatalf("unexpected error during request: %s", err)
+	}
+	if strings.Contains(string(body), "outputSchema") {
+		t.Fatalf("unexpected outputSchema for protocol version %s: %s", protocolVersion20250326, body)
+	}
+
+	// the result of the tool is returned as structured content too
+	callMarshal, err := json.Marshal(jsonrpc.JSONRPCRequest{
+		Jsonrpc: jsonrpcVersion,
+		Id:      "tools-call",
+		Request: jsonrpc.Request{Method: "tools/call"},
+		Params:  map[string]any{"name": "output_schema"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error during marshaling of body")
+	}
+	for _, protocolVersion := range []string{protocolVersion20250618, protocolVersion20251125} {
+		_, body, err = runRequest(ts, http.MethodPost, "/", bytes.NewBuffer(callMarshal), map[string]string{"MCP-Protocol-Version": protocolVersion})
+		if err != nil {
+			t.Fatalf("unexpected error during request: %s", err)
+		}
+		var got map[string]any
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Fatalf("unexpected error unmarshalling body: %s", err)
+		}
+		result, _ := got["result"].(map[string]any)
+		want := map[string]any{"permissions": []any{"use"}}
+		if !reflect.DeepEqual(result["structuredContent"], want) {
+			t.Fatalf("unexpected structured content for protocol version %s: got %s, want %+v", protocolVersion, body, want)
+		}
+	}
+}
+
+func TestMcpDestructiveConfirmation(t *testing.T) {
+	destructiveTool := MockTool{
+		Name:        "destructive",
+		Annotations: tools.NewDestructiveAnnotations(),
+	}
+	toolsMap, toolsets, promptsMap, promptsets := setUpResources(t, []MockTool{tool1, destructiveTool}, []MockPrompt{prompt1})
+	testLogger, err := log.NewStdLogger(os.Stdout, os.Stderr, "info")
+	if err != nil {
+		t.Fatalf("unable to initialize logger: %s", err)
+	}
+	resourceManager := resources.NewResourceManager(nil, nil, nil, toolsMap, toolsets, promptsMap, promptsets)
+	resourceManager.SetRequireDestructiveConfirmation(true)
+	r, shutdown := setUpServerWithResourceManager(t, "mcp", testLogger, resourceManager)
+	defer shutdown()
+	ts := runServer(r, false)
+	defer ts.Close()
+
+	run := func(t *testing.T, method string, params map[string]any) map[string]any {
+		reqMarshal, err := json.Marshal(jsonrpc.JSONRPCRequest{
+			Jsonrpc: jsonrpcVersion,
+			Id:      "destructive",
+			Request: jsonrpc.Request{Method: method},
+			Params:  params,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error during marshaling of body")
+		}
+		header := map[string]string{"MCP-Protocol-Version": protocolVersion20250618}
+		_, body, err := runRequest(ts, http.MethodPost, "/", bytes.NewBuffer(reqMarshal), header)
+		if err != nil {
+			t.Fatalf("unexpected error during request: %s", err)
+		}
+		var got map[string]any
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Fatalf("unexpected error unmarshalling body: %s", err)
+		}
+		return got
+	}
+
+	t.Run("tools/list includes annotations", func(t *testing.T) {
+		got := run(t, "tools/list", nil)
+		result, _ := got["result"].(map[string]any)
+		toolsList, _ := result["tools"].([]any)
+		if len(toolsList) != 2 {
+			t.Fatalf("unexpected tools: %+v", got)
+		}
+		if _, ok := toolsList[0].(map[string]any)["annotations"]; ok {
+			t.Fatalf("expected no annotations for a tool without them, got %+v", toolsList[0])
+		}
+		want := map[string]any{"readOnlyHint": false, "destructiveHint": true}
+		if diff := cmp.Diff(want, toolsList[1].(map[string]any)["annotations"]); diff != "" {
+			t.Fatalf("unexpected annotations (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("unconfirmed destructive call", func(t *testing.T) {
+		got := run(t, "tools/call", map[string]any{"name": "destructive"})
+		errObj, ok := got["error"].(map[string]any)
+		if !ok {
+			t.Fatalf("expected an error, got %+v", got)
+		}
+		if errObj["code"] != float64(jsonrpc.INVALID_REQUEST) || !strings.Contains(errObj["message"].(string), tools.ConfirmDestructiveMetaKey) {
+			t.Fatalf("unexpected error: %+v", errObj)
+		}
+	})
+
+	t.Run("confirmed destructive call", func(t *testing.T) {
+		got := run(t, "tools/call", map[string]any{
+			"name":  "destructive",
+			"_meta": map[string]any{tools.ConfirmDestructiveMetaKey: true},
+		})
+		if _, ok := got["result"]; !ok {
+			t.Fatalf("expected a result, got %+v", got)
+		}
+	})
+
+	t.Run("non-destructive call", func(t *testing.T) {
+		got := run(t, "tools/call", map[string]any{"name": "no_params"})
+		if _, ok := got["result"]; !ok {
+			t.Fatalf("expected a result, got %+v", got)
+		}
+	})
+}
+
+func TestMcpToolsCallPlan(t *testing.T) {
+	plannableTool := MockPlannableTool{MockTool{
+		Name:        "plannable",
+		Annotations: tools.NewDestructiveAnnotations(),
+	}}
+	toolsMap, toolsets, promptsMap, promptsets := setUpResources(t, []MockTool{tool1, tool2}, []MockPrompt{prompt1})
+	toolsMap[plannableTool.Name] = plannableTool
+	testLogger, err := log.NewStdLogger(os.Stdout, os.Stderr, "info")
+	if err != nil {
+		t.Fatalf("unable to initialize logger: %s", err)
+	}
+	resourceManager := resources.NewResourceManager(nil, nil, nil, toolsMap, toolsets, promptsMap, promptsets)
+	resourceManager.SetRequireDestructiveConfirmation(true)
+	r, shutdown := setUpServerWithResourceManager(t, "mcp", testLogger, resourceManager)
+	defer shutdown()
+	ts := runServer(r, false)
+	defer ts.Close()
+
+	for _, protocolVersion := range []string{protocolVersion20241105, protocolVersion20250326, protocolVersion20250618, protocolVersion20251125} {
+		t.Run(protocolVersion, func(t *testing.T) {
+			call := func(t *testing.T, name string) map[string]any {
+				reqMarshal, err := json.Marshal(jsonrpc.JSONRPCRequest{
+					Jsonrpc: jsonrpcVersion,
+					Id:      "plan",
+					Request: jsonrpc.Request{Method: "tools/call"},
+					Params: map[string]any{
+						"name":  name,
+						"_meta": map[string]any{tools.PlanMetaKey: true},
+					},
+				})
+				if err != nil {
+					t.Fatalf("unexpected error during marshaling of body")
+				}
+				header := map[string]string{"MCP-Protocol-Version": protocolVersion}
+				_, body, err := runRequest(ts, http.MethodPost, "/", bytes.NewBuffer(reqMarshal), header)
+				if err != nil {
+					t.Fatalf("unexpected error during request: %s", err)
+				}
+				var got map[string]any
+				if err := json.Unmarshal(body, &got); err != nil {
+					t.Fatalf("unexpected error unmarshalling body: %s", err)
+				}
+				return got
+			}
+
+			got := call(t, plannableTool.Name)
+			result, ok := got["result"].(map[string]any)
+			if !ok {
+				t.Fatalf("expected a result, got %+v", got)
+			}
+			want := []any{map[string]any{"type": "text", "text": `{"plan":"plannable"}`}}
+			if diff := cmp.Diff(want, result["content"]); diff != "" {
+				t.Fatalf("unexpected content (-want +got):\n%s", diff)
+			}
+
+			got = call(t, tool1.Name)
+			errObj, ok := got["error"].(map[string]any)
+			if !ok {
+				t.Fatalf("expected an error, got %+v", got)
+			}
+			if errObj["code"] != float64(jsonrpc.INVALID_REQUEST) || !strings.Contains(errObj["message"].(string), "does not support planning") {
+				t.Fatalf("unexpected error: %+v", errObj)
+			}
+		})
+	}
+}
+
 func runInitializeLifecycle(t *testing.T, ts *httptest.Server, protocolVersion string, initializeWant map[string]any, idHeader bool) string {
 	initializeRequestBody := map[string]any{
 		"jsonrpc": jsonrpcVersion,
@@ -1184,3 +1430,171 @@ func TestSseManagerGetNonExistentSession(t *testing.T) {
 		t.Error("expected nil session for non-existent ID")
 	}
 }
+
+func TestMcpToolResultMetadata(t *testing.T) {
+	metadataTool := MockTool{
+		Name:     "metadata",
+		Metadata: map[string]any{"jobId": "job_123"},
+	}
+	toolsMap, toolsets, promptsMap, promptsets := setUpResources(t, []MockTool{tool1, metadataTool}, []MockPrompt{prompt1})
+	testLogger, err := log.NewStdLogger(os.Stdout, os.Stderr, "info")
+	if err != nil {
+		t.Fatalf("unable to initialize logger: %s", err)
+	}
+	resourceManager := resources.NewResourceManager(nil, nil, nil, toolsMap, toolsets, promptsMap, promptsets)
+	r, shutdown := setUpServerWithResourceManager(t, "mcp", testLogger, resourceManager)
+	defer shutdown()
+	ts := runServer(r, false)
+	defer ts.Close()
+
+	tcs := []struct {
+		desc     string
+		params   map[string]any
+		wantMeta any
+	}{
+		{
+			desc:   "metadata not requested",
+			params: map[string]any{"name": "metadata"},
+		},
+		{
+			desc: "metadata requested",
+			params: map[string]any{
+				"name":  "metadata",
+				"_meta": map[string]any{tools.IncludeMetadataMetaKey: true},
+			},
+			wantMeta: map[string]any{"jobId": "job_123"},
+		},
+		{
+			desc: "metadata requested from a tool without metadata",
+			params: map[string]any{
+				"name":  tool1.Name,
+				"_meta": map[string]any{tools.IncludeMetadataMetaKey: true},
+			},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			reqMarshal, err := json.Marshal(jsonrpc.JSONRPCRequest{
+				Jsonrpc: jsonrpcVersion,
+				Id:      "metadata",
+				Request: jsonrpc.Request{Method: "tools/call"},
+				Params:  tc.params,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error during marshaling of body")
+			}
+			header := map[string]string{"MCP-Protocol-Version": protocolVersion20250618}
+			_, body, err := runRequest(ts, http.MethodPost, "/", bytes.NewBuffer(reqMarshal), header)
+			if err != nil {
+				t.Fatalf("unexpected error during request: %s", err)
+			}
+			var got map[string]any
+			if err := json.Unmarshal(body, &got); err != nil {
+				t.Fatalf("unexpected error unmarshalling body: %s", err)
+			}
+			result, ok := got["result"].(map[string]any)
+			if !ok {
+				t.Fatalf("expected a result, got %+v", got)
+			}
+			// the data is the same whether or not the metadata is included
+			wantContent := []any{map[string]any{"type": "text", "text": fmt.Sprintf("%q", tc.params["name"])}}
+			if diff := cmp.Diff(wantContent, result["content"]); diff != "" {
+				t.Fatalf("unexpected content (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantMeta, result["_meta"]); diff != "" {
+				t.Fatalf("unexpected _meta (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMcpToolErrorInfo(t *testing.T) {
+	agentErrTool := MockTool{
+		Name: "agent_error",
+		Err: util.NewAgentError("access to dataset 'p.d' is not allowed", nil).
+			WithCode(util.ErrorCodePermissionDenied).
+			WithDetails(map[string]any{"datasets": []string{"p.d"}}),
+	}
+	serverErrTool := MockTool{
+		Name: "server_error",
+		Err:  util.NewClientServerError("backend is unavailable", http.StatusServiceUnavailable, nil),
+	}
+	toolsMap, toolsets, promptsMap, promptsets := setUpResources(t, []MockTool{agentErrTool, serverErrTool}, []MockPrompt{prompt1})
+	testLogger, err := log.NewStdLogger(os.Stdout, os.Stderr, "info")
+	if err != nil {
+		t.Fatalf("unable to initialize logger: %s", err)
+	}
+	resourceManager := resources.NewResourceManager(nil, nil, nil, toolsMap, toolsets, promptsMap, promptsets)
+	r, shutdown := setUpServerWithResourceManager(t, "mcp", testLogger, resourceManager)
+	defer shutdown()
+	ts := runServer(r, false)
+	defer ts.Close()
+
+	permissionDenied := map[string]any{"code": "permission_denied", "retryable": false, "details": map[string]any{"datasets": []any{"p.d"}}, "requestId": "request-1"}
+	tcs := []struct {
+		desc      string
+		params    map[string]any
+		wantMeta  any
+		wantError any
+	}{
+		{
+			desc:   "agent error without metadata requested",
+			params: map[string]any{"name": agentErrTool.Name},
+		},
+		{
+			desc: "agent error with metadata requested",
+			params: map[string]any{
+				"name":  agentErrTool.Name,
+				"_meta": map[string]any{tools.IncludeMetadataMetaKey: true},
+			},
+			wantMeta: map[string]any{tools.ErrorInfoMetaKey: permissionDenied},
+		},
+		{
+			desc:   "server error",
+			params: map[string]any{"name": serverErrTool.Name},
+			wantError: map[string]any{
+				"code":    float64(jsonrpc.INTERNAL_ERROR),
+				"message": "backend is unavailable",
+				"data":    map[string]any{"code": "backend_unavailable", "retryable": true, "requestId": "request-1"},
+			},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			reqMarshal, err := json.Marshal(jsonrpc.JSONRPCRequest{
+				Jsonrpc: jsonrpcVersion,
+				Id:      "error-info",
+				Request: jsonrpc.Request{Method: "tools/call"},
+				Params:  tc.params,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error during marshaling of body")
+			}
+			header := map[string]string{"MCP-Protocol-Version": protocolVersion20250618, tools.RequestIDHeader: "request-1"}
+			_, body, err := runRequest(ts, http.MethodPost, "/", bytes.NewBuffer(reqMarshal), header)
+			if err != nil {
+				t.Fatalf("unexpected error during request: %s", err)
+			}
+			var got map[string]any
+			if err := json.Unmarshal(body, &got); err != nil {
+				t.Fatalf("unexpected error unmarshalling body: %s", err)
+			}
+			if tc.wantError != nil {
+				if diff := cmp.Diff(tc.wantError, got["error"]); diff != "" {
+					t.Fatalf("unexpected error (-want +got):\n%s", diff)
+				}
+				return
+			}
+			result, ok := got["result"].(map[string]any)
+			if !ok {
+				t.Fatalf("expected a result, got %+v", got)
+			}
+			if result["isError"] != true {
+				t.Fatalf("expected a tool execution error, got %+v", result)
+			}
+			if diff := cmp.Diff(tc.wantMeta, result["_meta"]); diff != "" {
+				t.Fatalf("unexpected _meta (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
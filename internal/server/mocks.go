@@ -16,8 +16,11 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
 
+	"github.com/googleapis/genai-toolbox/internal/auth"
 	"github.com/googleapis/genai-toolbox/internal/embeddingmodels"
 	"github.com/googleapis/genai-toolbox/internal/prompts"
 	"github.com/googleapis/genai-toolbox/internal/tools"
@@ -30,13 +33,27 @@ type MockTool struct {
 	Name                         string
 	Description                  string
 	Params                       []parameters.Parameter
+	OutputSchema                 map[string]any
+	Result                       any
+	Annotations                  *tools.ToolAnnotations
+	Metadata                     map[string]any
+	Err                          util.ToolboxError
 	manifest                     tools.Manifest
 	unauthorized                 bool
 	requiresClientAuthrorization bool
 }
 
 func (t MockTool) Invoke(context.Context, tools.SourceProvider, parameters.ParamValues, tools.AccessToken) (any, util.ToolboxError) {
+	if t.Err != nil {
+		return nil, t.Err
+	}
+	if t.Result != nil {
+		return t.Result, nil
+	}
 	mock := []any{t.Name}
+	if t.Metadata != nil {
+		return tools.NewResult(mock, t.Metadata), nil
+	}
 	return mock, nil
 }
 
@@ -98,9 +115,11 @@ func (t MockTool) McpManifest() tools.McpManifest {
 	}
 
 	mcpManifest := tools.McpManifest{
-		Name:        t.Name,
-		Description: t.Description,
-		InputSchema: toolsSchema,
+		Name:         t.Name,
+		Description:  t.Description,
+		InputSchema:  toolsSchema,
+		OutputSchema: t.OutputSchema,
+		Annotations:  t.Annotations,
 	}
 
 	if len(authParams) > 0 {
@@ -116,6 +135,85 @@ func (t MockTool) GetAuthTokenHeaderName(tools.SourceProvider) (string, error) {
 	return "Authorization", nil
 }
 
+// MockPlannableTool is a MockTool that supports planning. Its plans name the
+// tool.
+type MockPlannableTool struct {
+	MockTool
+}
+
+func (t MockPlannableTool) Plan(context.Context, tools.SourceProvider, parameters.ParamValues, tools.AccessToken) (any, util.ToolboxError) {
+	if t.Err != nil {
+		return nil, t.Err
+	}
+	return map[string]any{"plan": t.Name}, nil
+}
+
+// MockStreamingTool is a MockTool that streams Chunks, and then fails with
+// Err, if set. When it is not streamed, its result holds the items of Chunks.
+type MockStreamingTool struct {
+	MockTool
+	Chunks [][]any
+}
+
+func (t MockStreamingTool) Invoke(context.Context, tools.SourceProvider, parameters.ParamValues, tools.AccessToken) (any, util.ToolboxError) {
+	if t.Err != nil {
+		return nil, t.Err
+	}
+	var res []any
+	for _, chunk := range t.Chunks {
+		res = append(res, chunk...)
+	}
+	return res, nil
+}
+
+func (t MockStreamingTool) InvokeStream(_ context.Context, _ tools.SourceProvider, _ parameters.ParamValues, _ tools.AccessToken, sink tools.ChunkSink) util.ToolboxError {
+	for _, chunk := range t.Chunks {
+		if err := sink(chunk); err != nil {
+			return util.NewClientServerError("unable to send chunk", http.StatusInternalServerError, err)
+		}
+	}
+	return t.Err
+}
+
+// MockEchoTool is a MockTool whose results are the values of its parameters.
+type MockEchoTool struct {
+	MockTool
+}
+
+func (t MockEchoTool) Invoke(_ context.Context, _ tools.SourceProvider, params parameters.ParamValues, _ tools.AccessToken) (any, util.ToolboxError) {
+	return params.AsMap(), nil
+}
+
+// MockAuthService is used to mock auth services in tests. Its claims are read
+// as a JSON object from the "<name>_token" header.
+type MockAuthService struct {
+	Name string
+}
+
+func (a MockAuthService) AuthServiceType() string {
+	return "mock"
+}
+
+func (a MockAuthService) GetName() string {
+	return a.Name
+}
+
+func (a MockAuthService) GetClaimsFromHeader(_ context.Context, h http.Header) (map[string]any, error) {
+	token := h.Get(a.Name + "_token")
+	if token == "" {
+		return nil, nil
+	}
+	var claims map[string]any
+	if err := json.Unmarshal([]byte(token), &claims); err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+	return claims, nil
+}
+
+func (a MockAuthService) ToConfig() auth.AuthServiceConfig {
+	return nil
+}
+
 // MockPrompt is used to mock prompts in tests
 type MockPrompt struct {
 	Name        string
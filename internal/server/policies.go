@@ -0,0 +1,105 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package server
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/googleapis/genai-toolbox/internal/util"
+)
+
+// policyField is the field of the configs of sources and tools that names the
+// policy they use.
+const policyField = "policy"
+
+// PolicyConfig is a named set of restrictions, e.g. the datasets that can be
+// accessed, that sources and tools share by naming it in their 'policy'
+// field. Its fields have the names of the fields of the configs they set.
+type PolicyConfig struct {
+	Name                      string   `yaml:"name" validate:"required"`
+	WriteMode                 string   `yaml:"writeMode"`
+	AllowedDatasets           []string `yaml:"allowedDatasets"`
+	AllowedProjects           []string `yaml:"allowedProjects"`
+	AllowedConnections        []string `yaml:"allowedConnections"`
+	AllowedStorageURIPrefixes []string `yaml:"allowedStorageUriPrefixes"`
+}
+
+type PolicyConfigs map[string]PolicyConfig
+
+// fields returns the fields of the config that p sets, by their YAML names.
+func (p PolicyConfig) fields() map[string]any {
+	fields := make(map[string]any)
+	if p.WriteMode != "" {
+		fields["writeMode"] = p.WriteMode
+	}
+	for field, values := range map[string][]string{
+		"allowedDatasets":           p.AllowedDatasets,
+		"allowedProjects":           p.AllowedProjects,
+		"allowedConnections":        p.AllowedConnections,
+		"allowedStorageUriPrefixes": p.AllowedStorageURIPrefixes,
+	} {
+		if values != nil {
+			fields[field] = slices.Clone(values)
+		}
+	}
+	return fields
+}
+
+func UnmarshalYAMLPolicyConfig(name string, r map[string]any) (PolicyConfig, error) {
+	dec, err := util.NewStrictDecoder(r)
+	if err != nil {
+		return PolicyConfig{}, fmt.Errorf("error creating decoder: %w", err)
+	}
+	policy := PolicyConfig{Name: name}
+	if err := dec.Decode(&policy); err != nil {
+		return PolicyConfig{}, err
+	}
+	return policy, nil
+}
+
+// applyPolicy removes the 'policy' field of r, the config of the resource of
+// kind named name, and sets the fields of the policy it names that the config
+// declares, as reported by declares. The fields that r sets itself take
+// precedence over those of the policy. It returns an error if the policy is
+// not in policies, or if the config declares none of its fields.
+func applyPolicy(policies PolicyConfigs, kind, name string, r map[string]any, declares func(field string) bool) error {
+	rawPolicy, ok := r[policyField]
+	if !ok {
+		return nil
+	}
+	delete(r, policyField)
+	policyName, ok := rawPolicy.(string)
+	if !ok || policyName == "" {
+		return fmt.Errorf("%s %q config error: 'policy' must be the name of a policy", kind, name)
+	}
+	policy, ok := policies[policyName]
+	if !ok {
+		return fmt.Errorf("%s %q config error: policy %q is not defined", kind, name, policyName)
+	}
+	applied := false
+	for field, value := range policy.fields() {
+		if !declares(field) {
+			continue
+		}
+		applied = true
+		if _, ok := r[field]; !ok {
+			r[field] = value
+		}
+	}
+	if !applied {
+		return fmt.Errorf("%s %q config error: policy %q sets none of the fields of its config", kind, name, policyName)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/server"
+	bigqueryds "github.com/googleapis/genai-toolbox/internal/sources/bigquery"
+	"github.com/googleapis/genai-toolbox/internal/testutils"
+	"github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigqueryconversationalanalytics"
+)
+
+const policyConfig = `
+kind: sources
+name: my-bigquery
+type: bigquery
+project: my-project
+policy: analytics
+---
+kind: tools
+name: ask
+type: bigquery-conversational-analytics
+source: my-bigquery
+description: Ask questions.
+policy: analytics
+---
+kind: policies
+name: analytics
+writeMode: blocked
+allowedDatasets:
+  - my-project.sales
+  - other-project.marketing
+allowedProjects:
+  - my-project
+`
+
+func TestPolicies(t *testing.T) {
+	ctx, err := testutils.ContextWithNewLogger()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	sourceConfigs, _, _, toolConfigs, _, _, err := server.UnmarshalResourceConfig(ctx, []byte(policyConfig))
+	if err != nil {
+		t.Fatalf("unable to unmarshal config: %s", err)
+	}
+
+	source, ok := sourceConfigs["my-bigquery"].(bigqueryds.Config)
+	if !ok {
+		t.Fatalf("unexpected source config %T", sourceConfigs["my-bigquery"])
+	}
+	if source.WriteMode != bigqueryds.WriteModeBlocked {
+		t.Errorf("got writeMode %q, want %q", source.WriteMode, bigqueryds.WriteModeBlocked)
+	}
+	if diff := cmp.Diff(bigqueryds.StringOrStringSlice{"my-project.sales", "other-project.marketing"}, source.AllowedDatasets); diff != "" {
+		t.Errorf("unexpected allowed datasets (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(bigqueryds.StringOrStringSlice{"my-project"}, source.AllowedProjects); diff != "" {
+		t.Errorf("unexpected allowed projects of the source (-want +got):\n%s", diff)
+	}
+
+	// the tool only declares the allowed projects of the policy
+	tool, ok := toolConfigs["ask"].(bigqueryconversationalanalytics.Config)
+	if !ok {
+		t.Fatalf("unexpected tool config %T", toolConfigs["ask"])
+	}
+	if diff := cmp.Diff([]string{"my-project"}, tool.AllowedProjects); diff != "" {
+		t.Errorf("unexpected allowed projects of the tool (-want +got):\n%s", diff)
+	}
+}
+
+func TestPolicyPrecedence(t *testing.T) {
+	ctx, err := testutils.ContextWithNewLogger()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	config := strings.Replace(policyConfig, "policy: analytics\n---\nkind: tools", "policy: analytics\nallowedDatasets: my-project.sales\n---\nkind: tools", 1)
+	config = strings.Replace(config, "description: Ask questions.\n", "description: Ask questions.\nallowedProjects:\n  - billing-project\n", 1)
+	sourceConfigs, _, _, toolConfigs, _, _, err := server.UnmarshalResourceConfig(ctx, []byte(config))
+	if err != nil {
+		t.Fatalf("unable to unmarshal config: %s", err)
+	}
+
+	// inline fields take precedence over those of the policy, field by field
+	source := sourceConfigs["my-bigquery"].(bigqueryds.Config)
+	if diff := cmp.Diff(bigqueryds.StringOrStringSlice{"my-project.sales"}, source.AllowedDatasets); diff != "" {
+		t.Errorf("unexpected allowed datasets (-want +got):\n%s", diff)
+	}
+	if source.WriteMode != bigqueryds.WriteModeBlocked {
+		t.Errorf("got writeMode %q, want %q", source.WriteMode, bigqueryds.WriteModeBlocked)
+	}
+	tool := toolConfigs["ask"].(bigqueryconversationalanalytics.Config)
+	if diff := cmp.Diff([]string{"billing-project"}, tool.AllowedProjects); diff != "" {
+		t.Errorf("unexpected allowed projects of the tool (-want +got):\n%s", diff)
+	}
+}
+
+func TestPolicyErrors(t *testing.T) {
+	ctx, err := testutils.ContextWithNewLogger()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	tcs := []struct {
+		desc    string
+		config  string
+		wantErr string
+	}{
+		{
+			desc:    "unknown policy",
+			config:  strings.Replace(policyConfig, "name: my-bigquery\ntype: bigquery\nproject: my-project\npolicy: analytics", "name: my-bigquery\ntype: bigquery\nproject: my-project\npolicy: other", 1),
+			wantErr: `source "my-bigquery" config error: policy "other" is not defined`,
+		},
+		{
+			desc:    "policy that is not a name",
+			config:  strings.Replace(policyConfig, "description: Ask questions.\npolicy: analytics", "description: Ask questions.\npolicy: [analytics]", 1),
+			wantErr: `tool "ask" config error: 'policy' must be the name of a policy`,
+		},
+		{
+			desc:    "policy without fields of the config",
+			config:  strings.Replace(policyConfig, "allowedProjects:\n  - my-project\n", "", 1),
+			wantErr: `tool "ask" config error: policy "analytics" sets none of the fields of its config`,
+		},
+		{
+			desc:    "unknown field of a policy",
+			config:  policyConfig + "allowedTables:\n  - my-project.sales.orders\n",
+			wantErr: `unknown field "allowedTables"`,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, _, _, _, _, _, err := server.UnmarshalResourceConfig(ctx, []byte(tc.config))
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("got error %q, want it to contain %q", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/googleapis/genai-toolbox/internal/log"
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// readinessCheckTimeout bounds the health check of a source.
+	readinessCheckTimeout = 10 * time.Second
+	// readinessCacheTTL is how long the result of the health check of a
+	// source is reused, so that requests to the readiness endpoint do not
+	// call the APIs of the sources every time.
+	readinessCacheTTL = 30 * time.Second
+)
+
+// Statuses of the readiness endpoint and of its sources.
+const (
+	readinessReady    = "ready"
+	readinessNotReady = "not ready"
+)
+
+// sourceReadiness is the result of the health check of a source. The
+// readiness endpoint is not authenticated, so the errors of the checks are
+// logged rather than reported.
+type sourceReadiness struct {
+	Status    string    `json:"status"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// readinessResponse is the body of the readiness endpoint.
+type readinessResponse struct {
+	Status  string                     `json:"status"`
+	Sources map[string]sourceReadiness `json:"sources"`
+}
+
+// readinessChecker runs the health checks of the sources that implement
+// sources.HealthChecker, and caches their results.
+type readinessChecker struct {
+	timeout time.Duration
+	ttl     time.Duration
+	logger  log.Logger
+
+	mu      sync.Mutex
+	results map[string]sourceReadiness
+	group   singleflight.Group
+}
+
+func newReadinessChecker(logger log.Logger) *readinessChecker {
+	return &readinessChecker{
+		timeout: readinessCheckTimeout,
+		ttl:     readinessCacheTTL,
+		logger:  logger,
+		results: make(map[string]sourceReadiness),
+	}
+}
+
+// check returns the readiness of srcs. The sources are checked concurrently,
+// and the result of the check of a source is reused for the ttl of the
+// checker. Concurrent calls share the checks in progress.
+func (c *readinessChecker) check(ctx context.Context, srcs map[string]sources.Source) readinessResponse {
+	resp := readinessResponse{Status: readinessReady, Sources: make(map[string]sourceReadiness)}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, src := range srcs {
+		checker, ok := src.(sources.HealthChecker)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := c.checkSource(ctx, name, checker)
+			mu.Lock()
+			defer mu.Unlock()
+			resp.Sources[name] = result
+			if result.Status != readinessReady {
+				resp.Status = readinessNotReady
+			}
+		}()
+	}
+	wg.Wait()
+	return resp
+}
+
+// checkSource returns the cached readiness of the source named name, or runs
+// its health check if it is not cached.
+func (c *readinessChecker) checkSource(ctx context.Context, name string, checker sources.HealthChecker) sourceReadiness {
+	c.mu.Lock()
+	result, ok := c.results[name]
+	c.mu.Unlock()
+	if ok && time.Since(result.CheckedAt) < c.ttl {
+		return result
+	}
+	v, _, _ := c.group.Do(name, func() (any, error) {
+		// the check is cached for other requests, so it is not canceled
+		// with the request that runs it
+		checkCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.timeout)
+		defer cancel()
+		result := sourceReadiness{Status: readinessReady, CheckedAt: time.Now()}
+		if err := runHealthCheck(checkCtx, checker); err != nil {
+			result.Status = readinessNotReady
+			c.logger.WarnContext(ctx, fmt.Sprintf("health check of source %q failed: %s", name, err))
+		}
+		c.mu.Lock()
+		c.results[name] = result
+		c.mu.Unlock()
+		return result, nil
+	})
+	return v.(sourceReadiness)
+}
+
+// runHealthCheck runs the health check of checker, returning an error if it
+// panics, so that a failing check does not stop the server.
+func runHealthCheck(ctx context.Context, checker sources.HealthChecker) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("health check panicked: %v", r)
+		}
+	}()
+	return checker.HealthCheck(ctx)
+}
+
+// readyzHandler reports whether the sources of the server are ready, with
+// status 200 if they all are and 503 otherwise, and the readiness of each
+// source that has a health check. The reasons sources are not ready are
+// logged.
+func (s *Server) readyzHandler(w http.ResponseWriter, r *http.Request) {
+	resp := s.readiness.check(r.Context(), s.ResourceMgr.GetSourcesMap())
+	w.Header().Set("Content-Type", "application/json")
+	if resp.Status != readinessReady {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
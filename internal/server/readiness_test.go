@@ -0,0 +1,137 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/googleapis/genai-toolbox/internal/log"
+	"github.com/googleapis/genai-toolbox/internal/server/resources"
+	"github.com/googleapis/genai-toolbox/internal/sources"
+)
+
+type healthCheckSource struct {
+	sources.Source
+	err    error
+	panics bool
+	checks atomic.Int32
+}
+
+func (s *healthCheckSource) HealthCheck(ctx context.Context) error {
+	s.checks.Add(1)
+	if s.panics {
+		panic("boom")
+	}
+	return s.err
+}
+
+func TestReadyz(t *testing.T) {
+	testLogger, err := log.NewStdLogger(os.Stdout, os.Stderr, "info")
+	if err != nil {
+		t.Fatalf("unable to initialize logger: %s", err)
+	}
+	healthy := &healthCheckSource{}
+	noToken := &healthCheckSource{err: errors.New("failed to obtain a token: no credentials")}
+	panicking := &healthCheckSource{panics: true}
+	srcs := map[string]sources.Source{
+		"healthy":   healthy,
+		"no-token":  noToken,
+		"panicking": panicking,
+		// sources without health checks are not reported
+		"unchecked": struct{ sources.Source }{},
+	}
+	s := &Server{
+		readiness:   newReadinessChecker(testLogger),
+		ResourceMgr: resources.NewResourceManager(srcs, nil, nil, nil, nil, nil, nil),
+	}
+
+	get := func() (int, readinessResponse) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		s.readyzHandler(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		// the endpoint is not authenticated, so the errors are not reported
+		for _, detail := range []string{"no credentials", "boom", "error"} {
+			if strings.Contains(rec.Body.String(), detail) {
+				t.Fatalf("response %q reports the error detail %q", rec.Body.String(), detail)
+			}
+		}
+		var resp readinessResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("unable to decode response %q: %s", rec.Body.String(), err)
+		}
+		return rec.Code, resp
+	}
+
+	code, resp := get()
+	if code != http.StatusServiceUnavailable || resp.Status != readinessNotReady {
+		t.Errorf("got status %d %q, want 503 %q", code, resp.Status, readinessNotReady)
+	}
+	if len(resp.Sources) != 3 {
+		t.Errorf("got sources %v, want healthy, no-token and panicking", resp.Sources)
+	}
+	if got := resp.Sources["healthy"]; got.Status != readinessReady {
+		t.Errorf("got %+v for the healthy source", got)
+	}
+	if got := resp.Sources["no-token"]; got.Status != readinessNotReady {
+		t.Errorf("got %+v for the source without a token", got)
+	}
+	if got := resp.Sources["panicking"]; got.Status != readinessNotReady {
+		t.Errorf("got %+v for the panicking source", got)
+	}
+
+	// the results are cached
+	get()
+	for name, src := range map[string]*healthCheckSource{"healthy": healthy, "no-token": noToken, "panicking": panicking} {
+		if n := src.checks.Load(); n != 1 {
+			t.Errorf("source %q was checked %d times, want 1", name, n)
+		}
+	}
+
+	// and checked again once they expire
+	s.readiness.ttl = 0
+	noToken.err = nil
+	panicking.panics = false
+	code, resp = get()
+	if code != http.StatusOK || resp.Status != readinessReady {
+		t.Errorf("got status %d %+v, want 200 %q", code, resp, readinessReady)
+	}
+	if n := healthy.checks.Load(); n != 2 {
+		t.Errorf("the healthy source was checked %d times, want 2", n)
+	}
+}
+
+func TestReadyzWithoutHealthChecks(t *testing.T) {
+	testLogger, err := log.NewStdLogger(os.Stdout, os.Stderr, "info")
+	if err != nil {
+		t.Fatalf("unable to initialize logger: %s", err)
+	}
+	s := &Server{
+		readiness:   newReadinessChecker(testLogger),
+		ResourceMgr: resources.NewResourceManager(nil, nil, nil, nil, nil, nil, nil),
+	}
+	rec := httptest.NewRecorder()
+	s.readyzHandler(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "{\"status\":\"ready\",\"sources\":{}}\n" {
+		t.Errorf("got %d %q, want a ready server", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/googleapis/genai-toolbox/internal/server/resources"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	"github.com/googleapis/genai-toolbox/internal/util"
+)
+
+// ReloadResources initializes the resources of a reloaded config and swaps
+// them into resourceMgr. Only changed sources are rebuilt, and only tools that
+// changed or reference a rebuilt source are initialized again. Sources and
+// tools that fail to initialize keep their previous instance; their errors are
+// returned joined, after the swap. Any other error fails the reload and leaves
+// resourceMgr unchanged.
+//
+// Invocations that are in flight during the swap keep using the sources they
+// started with, see resources.ResourceManager.GetToolWithSources.
+func ReloadResources(ctx context.Context, cfg ServerConfig, resourceMgr *resources.ResourceManager) error {
+	logger, err := util.LoggerFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	sourcesMap, authServicesMap, embeddingModelsMap, toolsMap, toolsetsMap, promptsMap, promptsetsMap, reloadErrs, err := initializeConfigs(ctx, cfg, resourceMgr)
+	if err != nil {
+		return fmt.Errorf("unable to initialize reloaded configs: %w", err)
+	}
+	instrumentation, err := util.InstrumentationFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	limiters := newSourceLimiters(cfg.SourceConfigs, resourceMgr, instrumentation.SourceInvocationsInFlight)
+	resourceMgr.SetResources(sourcesMap, authServicesMap, embeddingModelsMap, toolsMap, toolsetsMap, promptsMap, promptsetsMap)
+	resourceMgr.SetSourceLimiters(limiters)
+	if len(reloadErrs) > 0 {
+		logger.WarnContext(ctx, fmt.Sprintf("reloaded with %d resources failing to initialize", len(reloadErrs)))
+		return errors.Join(reloadErrs...)
+	}
+	return nil
+}
+
+// usesChangedSource reports whether the tool of tc references one of the
+// changed sources, including the ones it may select among. Tools whose config
+// has no `Source` field are assumed to reference all sources.
+func usesChangedSource(tc tools.ToolConfig, changedSources map[string]bool) bool {
+	if len(changedSources) == 0 {
+		return false
+	}
+	sourceNames, ok := tools.SourceNames(tc)
+	if !ok {
+		return true
+	}
+	return slices.ContainsFunc(sourceNames, func(name string) bool { return changedSources[name] })
+}
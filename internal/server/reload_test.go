@@ -0,0 +1,352 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/googleapis/genai-toolbox/internal/server/resources"
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	"github.com/googleapis/genai-toolbox/internal/telemetry"
+	"github.com/googleapis/genai-toolbox/internal/testutils"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type reloadSourceConfig struct {
+	Value string
+	Fail  bool
+}
+
+func (c reloadSourceConfig) SourceConfigType() string { return "reload-test" }
+func (c reloadSourceConfig) Initialize(context.Context, trace.Tracer) (sources.Source, error) {
+	if c.Fail {
+		return nil, fmt.Errorf("source failed to initialize")
+	}
+	return &reloadSource{cfg: c}, nil
+}
+
+type reloadSource struct {
+	cfg reloadSourceConfig
+}
+
+func (s *reloadSource) SourceType() string             { return "reload-test" }
+func (s *reloadSource) ToConfig() sources.SourceConfig { return s.cfg }
+
+type reloadToolConfig struct {
+	Name   string
+	Source string
+	Fail   bool
+	// inits counts the initializations of the tool
+	inits *atomic.Int32
+	// if set, invocations signal started and wait for release before they
+	// get their source
+	started chan struct{}
+	release chan struct{}
+}
+
+func (c reloadToolConfig) ToolConfigType() string { return "reload-test" }
+func (c reloadToolConfig) Initialize(srcs map[string]sources.Source) (tools.Tool, error) {
+	if c.Fail {
+		return nil, fmt.Errorf("tool failed to initialize")
+	}
+	if _, ok := srcs[c.Source]; !ok {
+		return nil, fmt.Errorf("no source named %q configured", c.Source)
+	}
+	c.inits.Add(1)
+	return reloadTool{MockTool: MockTool{Name: c.Name}, cfg: c}, nil
+}
+
+type reloadTool struct {
+	MockTool
+	cfg reloadToolConfig
+}
+
+func (t reloadTool) ToConfig() tools.ToolConfig { return t.cfg }
+func (t reloadTool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, _ parameters.ParamValues, _ tools.AccessToken) (any, util.ToolboxError) {
+	if t.cfg.started != nil {
+		t.cfg.started <- struct{}{}
+		<-t.cfg.release
+	}
+	s, ok := resourceMgr.GetSource(t.cfg.Source)
+	if !ok {
+		return nil, util.NewClientServerError("source is missing", http.StatusInternalServerError, nil)
+	}
+	return s.(*reloadSource).cfg.Value, nil
+}
+
+func reloadTestContext(t *testing.T) context.Context {
+	t.Helper()
+	ctx, err := testutils.ContextWithNewLogger()
+	if err != nil {
+		t.Fatalf("unable to initialize logger: %s", err)
+	}
+	instrumentation, err := telemetry.CreateTelemetryInstrumentation(fakeVersionString)
+	if err != nil {
+		t.Fatalf("unable to create custom metrics: %s", err)
+	}
+	return util.WithInstrumentation(ctx, instrumentation)
+}
+
+func newReloadResourceManager(t *testing.T, ctx context.Context, cfg ServerConfig) *resources.ResourceManager {
+	t.Helper()
+	sourcesMap, authServicesMap, embeddingModelsMap, toolsMap, toolsetsMap, promptsMap, promptsetsMap, err := InitializeConfigs(ctx, cfg)
+	if err != nil {
+		t.Fatalf("unable to initialize configs: %s", err)
+	}
+	return resources.NewResourceManager(sourcesMap, authServicesMap, embeddingModelsMap, toolsMap, toolsetsMap, promptsMap, promptsetsMap)
+}
+
+func invokeReloadTool(t *testing.T, resourceMgr *resources.ResourceManager, name string) any {
+	t.Helper()
+	tool, sourceProvider, ok := resourceMgr.GetToolWithSources(name)
+	if !ok {
+		t.Fatalf("tool %q is missing", name)
+	}
+	res, err := tool.Invoke(context.Background(), sourceProvider, nil, "")
+	if err != nil {
+		t.Fatalf("unexpected invoke error: %s", err)
+	}
+	return res
+}
+
+func TestReloadResources(t *testing.T) {
+	ctx := reloadTestContext(t)
+	toolInits, otherInits := &atomic.Int32{}, &atomic.Int32{}
+	cfg := ServerConfig{
+		SourceConfigs: SourceConfigs{
+			"src":   reloadSourceConfig{Value: "v1"},
+			"other": reloadSourceConfig{Value: "other"},
+		},
+		ToolConfigs: ToolConfigs{
+			"tool":       reloadToolConfig{Name: "tool", Source: "src", inits: toolInits},
+			"other_tool": reloadToolConfig{Name: "other_tool", Source: "other", inits: otherInits},
+		},
+	}
+	resourceMgr := newReloadResourceManager(t, ctx, cfg)
+	otherSource, _ := resourceMgr.GetSource("other")
+
+	// a changed source is rebuilt, and only the tools referencing it are
+	// initialized again
+	cfg.SourceConfigs["src"] = reloadSourceConfig{Value: "v2"}
+	if err := ReloadResources(ctx, cfg, resourceMgr); err != nil {
+		t.Fatalf("unexpected reload error: %s", err)
+	}
+	if got := invokeReloadTool(t, resourceMgr, "tool"); got != "v2" {
+		t.Errorf("got %v, want the reloaded source", got)
+	}
+	if s, _ := resourceMgr.GetSource("other"); s != otherSource {
+		t.Errorf("expected the unchanged source to keep its instance")
+	}
+	if got := toolInits.Load(); got != 2 {
+		t.Errorf("tool referencing the changed source initialized %d times, want 2", got)
+	}
+	if got := otherInits.Load(); got != 1 {
+		t.Errorf("tool referencing the unchanged source initialized %d times, want 1", got)
+	}
+
+	// a tool that fails to initialize keeps its previous instance, and the
+	// rest of the reload is applied
+	cfg.SourceConfigs["src"] = reloadSourceConfig{Value: "v3"}
+	cfg.ToolConfigs["other_tool"] = reloadToolConfig{Name: "other_tool", Source: "other", Fail: true, inits: otherInits}
+	err := ReloadResources(ctx, cfg, resourceMgr)
+	if err == nil {
+		t.Fatalf("expected the failing tool to be reported")
+	}
+	if got := invokeReloadTool(t, resourceMgr, "tool"); got != "v3" {
+		t.Errorf("got %v, want the reloaded source", got)
+	}
+	if got := invokeReloadTool(t, resourceMgr, "other_tool"); got != "other" {
+		t.Errorf("got %v from the tool that failed to reload, want its previous instance to be kept", got)
+	}
+
+	// a source that fails to initialize keeps its previous instance
+	cfg.SourceConfigs["src"] = reloadSourceConfig{Fail: true}
+	if err := ReloadResources(ctx, cfg, resourceMgr); err == nil {
+		t.Fatalf("expected the failing source to be reported")
+	}
+	if got := invokeReloadTool(t, resourceMgr, "tool"); got != "v3" {
+		t.Errorf("got %v, want the previous instance of the source that failed to reload", got)
+	}
+
+	// a new tool that fails to initialize is not added
+	cfg.ToolConfigs["new_tool"] = reloadToolConfig{Name: "new_tool", Source: "missing", inits: &atomic.Int32{}}
+	if err := ReloadResources(ctx, cfg, resourceMgr); err == nil {
+		t.Fatalf("expected the failing tool to be reported")
+	}
+	if _, ok := resourceMgr.GetTool("new_tool"); ok {
+		t.Errorf("expected the new tool that failed to initialize not to be added")
+	}
+}
+
+func TestReloadResourcesSelectableSources(t *testing.T) {
+	ctx := reloadTestContext(t)
+	toolInits := &atomic.Int32{}
+	cfg := ServerConfig{
+		SourceConfigs: SourceConfigs{
+			"src":   reloadSourceConfig{Value: "v1"},
+			"other": reloadSourceConfig{Value: "other"},
+		},
+		ToolConfigs: ToolConfigs{
+			"tool": tools.WithCommonOptions(reloadToolConfig{Name: "tool", Source: "src", inits: toolInits}, tools.CommonOptions{Sources: []string{"src", "other"}}),
+		},
+	}
+	resourceMgr := newReloadResourceManager(t, ctx, cfg)
+	// the tool is initialized with each of its sources
+	if got := toolInits.Load(); got != 2 {
+		t.Fatalf("tool with two sources initialized %d times, want 2", got)
+	}
+
+	// a change of a source the tool may select initializes it again, even if
+	// it is not its configured source
+	cfg.SourceConfigs["other"] = reloadSourceConfig{Value: "other v2"}
+	if err := ReloadResources(ctx, cfg, resourceMgr); err != nil {
+		t.Fatalf("unexpected reload error: %s", err)
+	}
+	if got := toolInits.Load(); got != 4 {
+		t.Errorf("tool selecting the changed source initialized %d times, want 4", got)
+	}
+}
+
+func TestReloadResourcesDuringInvocation(t *testing.T) {
+	ctx := reloadTestContext(t)
+	started, release := make(chan struct{}), make(chan struct{})
+	cfg := ServerConfig{
+		SourceConfigs: SourceConfigs{
+			"src": reloadSourceConfig{Value: "v1"},
+		},
+		ToolConfigs: ToolConfigs{
+			"tool": reloadToolConfig{Name: "tool", Source: "src", inits: &atomic.Int32{}, started: started, release: release},
+		},
+	}
+	resourceMgr := newReloadResourceManager(t, ctx, cfg)
+
+	tool, sourceProvider, _ := resourceMgr.GetToolWithSources("tool")
+	done := make(chan any)
+	go func() {
+		res, _ := tool.Invoke(context.Background(), sourceProvider, nil, "")
+		done <- res
+	}()
+	<-started
+
+	// change the source while the invocation is in flight
+	cfg.SourceConfigs["src"] = reloadSourceConfig{Value: "v2"}
+	if err := ReloadResources(ctx, cfg, resourceMgr); err != nil {
+		t.Fatalf("unexpected reload error: %s", err)
+	}
+	close(release)
+
+	if got := <-done; got != "v1" {
+		t.Errorf("got %v, want the in-flight invocation to finish against the old source", got)
+	}
+	s, _ := resourceMgr.GetSource("src")
+	if got := s.(*reloadSource).cfg.Value; got != "v2" {
+		t.Errorf("got source %q after reload, want the reloaded source", got)
+	}
+}
+
+func TestSourceConcurrencyLimit(t *testing.T) {
+	ctx := reloadTestContext(t)
+	started, release := make(chan struct{}), make(chan struct{})
+	limit := sources.CommonOptions{MaxConcurrentInvocations: 2, InvocationQueueTimeout: 20 * time.Millisecond}
+	cfg := ServerConfig{
+		SourceConfigs: SourceConfigs{
+			"src":       sources.WithCommonOptions(reloadSourceConfig{Value: "v1"}, limit),
+			"unlimited": reloadSourceConfig{Value: "unlimited"},
+		},
+		ToolConfigs: ToolConfigs{
+			"tool":           reloadToolConfig{Name: "tool", Source: "src", inits: &atomic.Int32{}, started: started, release: release},
+			"unlimited_tool": reloadToolConfig{Name: "unlimited_tool", Source: "unlimited", inits: &atomic.Int32{}},
+		},
+	}
+	resourceMgr := newReloadResourceManager(t, ctx, cfg)
+	resourceMgr.SetSourceLimiters(newSourceLimiters(cfg.SourceConfigs, nil, nil))
+	limiter, ok := resourceMgr.GetSourceLimiter("src")
+	if !ok {
+		t.Fatalf("expected the limited source to have a limiter")
+	}
+	if _, ok := resourceMgr.GetSourceLimiter("unlimited"); ok {
+		t.Fatalf("expected the unlimited source to have no limiter")
+	}
+
+	invoke := func(name string) (any, util.ToolboxError) {
+		tool, sourceProvider, _ := resourceMgr.GetToolWithSources(name)
+		return tools.InvokeWithTimeout(context.Background(), name, tool, sourceProvider, nil, "")
+	}
+	// fill the slots of the source
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := invoke("tool"); err != nil {
+				t.Errorf("unexpected error of an invocation within the limit: %s", err)
+			}
+		}()
+		<-started
+	}
+	if got := limiter.InFlight(); got != 2 {
+		t.Fatalf("unexpected in-flight count: got %d, want 2", got)
+	}
+
+	// more invocations time out waiting for a slot, while other sources are
+	// not limited
+	_, err := invoke("tool")
+	if err == nil || err.ErrorInfo().Code != util.ErrorCodeRateLimited || !err.ErrorInfo().Retryable {
+		t.Fatalf("expected a retryable rate_limited error, got %v", err)
+	}
+	if err.Category() != util.CategoryAgent {
+		t.Fatalf("expected an agent error, got %s", err.Category())
+	}
+	if got, err := invoke("unlimited_tool"); err != nil || got != "unlimited" {
+		t.Fatalf("unexpected result of the unlimited tool: %v, %v", got, err)
+	}
+
+	// a reload with the same limits keeps the limiter and its in-flight
+	// invocations
+	if err := ReloadResources(ctx, cfg, resourceMgr); err != nil {
+		t.Fatalf("unexpected reload error: %s", err)
+	}
+	if l, _ := resourceMgr.GetSourceLimiter("src"); l != limiter {
+		t.Fatalf("expected the limiter of the unchanged limit to be kept")
+	}
+
+	close(release)
+	wg.Wait()
+	if got := limiter.InFlight(); got != 0 {
+		t.Fatalf("expected no invocations in flight, got %d", got)
+	}
+
+	// a changed limit gets a new limiter, without reinitializing the source
+	s, _ := resourceMgr.GetSource("src")
+	cfg.SourceConfigs["src"] = sources.WithCommonOptions(reloadSourceConfig{Value: "v1"}, sources.CommonOptions{MaxConcurrentInvocations: 5})
+	if err := ReloadResources(ctx, cfg, resourceMgr); err != nil {
+		t.Fatalf("unexpected reload error: %s", err)
+	}
+	if l, _ := resourceMgr.GetSourceLimiter("src"); l == limiter || l.Options().MaxConcurrentInvocations != 5 {
+		t.Fatalf("expected a new limiter for the changed limit")
+	}
+	if got, _ := resourceMgr.GetSource("src"); got != s {
+		t.Fatalf("expected the source to keep its instance when only its limit changes")
+	}
+}
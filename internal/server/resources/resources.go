@@ -15,6 +15,7 @@
 package resources
 
 import (
+	"fmt"
 	"sync"
 
 	"github.com/googleapis/genai-toolbox/internal/auth"
@@ -34,6 +35,17 @@ type ResourceManager struct {
 	toolsets        map[string]tools.Toolset
 	prompts         map[string]prompts.Prompt
 	promptsets      map[string]prompts.Promptset
+	// limiters are the limiters of the sources that limit their concurrent
+	// invocations.
+	limiters map[string]*sources.Limiter
+	// embeddingCache, if set, is shared by all embedding models.
+	embeddingCache *embeddingmodels.Cache
+	// requireDestructiveConfirmation refuses invocations of destructive tools
+	// that are not explicitly confirmed.
+	requireDestructiveConfirmation bool
+	// maxStreamBufferedChunks is the number of chunks of a streamed
+	// invocation buffered for the client.
+	maxStreamBufferedChunks int
 }
 
 func NewResourceManager(
@@ -58,6 +70,78 @@ func NewResourceManager(
 	return resourceMgr
 }
 
+// SetEmbeddingCache serves the embeddings of all embedding models, including
+// the ones set on reload, from cache.
+func (r *ResourceManager) SetEmbeddingCache(cache *embeddingmodels.Cache) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.embeddingCache = cache
+	r.embeddingModels = withEmbeddingCache(r.embeddingModels, cache)
+}
+
+// SetRequireDestructiveConfirmation sets whether invocations of tools
+// annotated as destructive must be explicitly confirmed.
+func (r *ResourceManager) SetRequireDestructiveConfirmation(require bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requireDestructiveConfirmation = require
+}
+
+// SetMaxStreamBufferedChunks sets the number of chunks of a streamed
+// invocation buffered for the client before the tool is paused. The default
+// of the tools package applies if it is not positive.
+func (r *ResourceManager) SetMaxStreamBufferedChunks(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.maxStreamBufferedChunks = n
+}
+
+// GetMaxStreamBufferedChunks returns the number of chunks of a streamed
+// invocation buffered for the client.
+func (r *ResourceManager) GetMaxStreamBufferedChunks() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.maxStreamBufferedChunks
+}
+
+// SetSourceLimiters sets the limiters of the sources that limit their
+// concurrent invocations, by source name.
+func (r *ResourceManager) SetSourceLimiters(limiters map[string]*sources.Limiter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.limiters = limiters
+}
+
+// GetSourceLimiter returns the limiter of the source named sourceName, if it
+// limits its concurrent invocations.
+func (r *ResourceManager) GetSourceLimiter(sourceName string) (*sources.Limiter, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	l, ok := r.limiters[sourceName]
+	return l, ok
+}
+
+// CheckDestructiveConfirmation returns an error if the invocation of tool must
+// be confirmed, because it is annotated as destructive and the server requires
+// confirmation, but was not.
+func (r *ResourceManager) CheckDestructiveConfirmation(toolName string, tool tools.Tool, confirmed bool) error {
+	r.mu.RLock()
+	require := r.requireDestructiveConfirmation
+	r.mu.RUnlock()
+	if !require || confirmed || !tool.McpManifest().Annotations.IsDestructive() {
+		return nil
+	}
+	return fmt.Errorf("tool %q is destructive and its invocation must be confirmed", toolName)
+}
+
+func withEmbeddingCache(models map[string]embeddingmodels.EmbeddingModel, cache *embeddingmodels.Cache) map[string]embeddingmodels.EmbeddingModel {
+	cached := make(map[string]embeddingmodels.EmbeddingModel, len(models))
+	for name, model := range models {
+		cached[name] = embeddingmodels.WithCache(name, model, cache)
+	}
+	return cached
+}
+
 func (r *ResourceManager) GetSource(sourceName string) (sources.Source, bool) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -65,6 +149,45 @@ func (r *ResourceManager) GetSource(sourceName string) (sources.Source, bool) {
 	return source, ok
 }
 
+// GetToolWithSources returns a tool and the sources at the time of the call.
+// Invocations use the returned SourceProvider instead of the ResourceManager,
+// so that they finish against the sources they started with when the
+// resources are reloaded.
+func (r *ResourceManager) GetToolWithSources(toolName string) (tools.Tool, tools.SourceProvider, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tool, ok := r.tools[toolName]
+	return tool, sourceSnapshot{sources: r.sources, limiters: r.limiters}, ok
+}
+
+// sourceSnapshot is a SourceProvider of a sources map and the limiters of its
+// sources. Maps set on the ResourceManager are replaced, never modified, so it
+// needs no lock.
+type sourceSnapshot struct {
+	sources  map[string]sources.Source
+	limiters map[string]*sources.Limiter
+}
+
+var _ tools.SourceLimiterProvider = sourceSnapshot{}
+
+func (s sourceSnapshot) GetSource(sourceName string) (sources.Source, bool) {
+	source, ok := s.sources[sourceName]
+	return source, ok
+}
+
+func (s sourceSnapshot) GetSourcesMap() map[string]sources.Source {
+	copiedMap := make(map[string]sources.Source, len(s.sources))
+	for k, v := range s.sources {
+		copiedMap[k] = v
+	}
+	return copiedMap
+}
+
+func (s sourceSnapshot) GetSourceLimiter(sourceName string) (*sources.Limiter, bool) {
+	l, ok := s.limiters[sourceName]
+	return l, ok
+}
+
 func (r *ResourceManager) GetAuthService(authServiceName string) (auth.AuthService, bool) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -113,12 +236,27 @@ func (r *ResourceManager) SetResources(sourcesMap map[string]sources.Source, aut
 	r.sources = sourcesMap
 	r.authServices = authServicesMap
 	r.embeddingModels = embeddingModelsMap
+	if r.embeddingCache != nil {
+		// models may have been reconfigured under the same name
+		r.embeddingCache.Purge()
+		r.embeddingModels = withEmbeddingCache(embeddingModelsMap, r.embeddingCache)
+	}
 	r.tools = toolsMap
 	r.toolsets = toolsetsMap
 	r.prompts = promptsMap
 	r.promptsets = promptsetsMap
 }
 
+func (r *ResourceManager) GetSourcesMap() map[string]sources.Source {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	copiedMap := make(map[string]sources.Source, len(r.sources))
+	for k, v := range r.sources {
+		copiedMap[k] = v
+	}
+	return copiedMap
+}
+
 func (r *ResourceManager) GetAuthServiceMap() map[string]auth.AuthService {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
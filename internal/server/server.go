@@ -20,6 +20,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"reflect"
 	"slices"
 	"strconv"
 	"strings"
@@ -39,6 +40,7 @@ import (
 	"github.com/googleapis/genai-toolbox/internal/tools"
 	"github.com/googleapis/genai-toolbox/internal/util"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -51,6 +53,7 @@ type Server struct {
 	logger          log.Logger
 	instrumentation *telemetry.Instrumentation
 	sseManager      *sseManager
+	readiness       *readinessChecker
 	ResourceMgr     *resources.ResourceManager
 }
 
@@ -63,6 +66,26 @@ func InitializeConfigs(ctx context.Context, cfg ServerConfig) (
 	map[string]prompts.Prompt,
 	map[string]prompts.Promptset,
 	error,
+) {
+	sourcesMap, authServicesMap, embeddingModelsMap, toolsMap, toolsetsMap, promptsMap, promptsetsMap, _, err := initializeConfigs(ctx, cfg, nil)
+	return sourcesMap, authServicesMap, embeddingModelsMap, toolsMap, toolsetsMap, promptsMap, promptsetsMap, err
+}
+
+// initializeConfigs initializes the resources of cfg. If prev is not nil, cfg
+// is a reload of the resources in prev: unchanged sources, and unchanged tools
+// whose source is unchanged, keep their instances, and sources and tools that
+// fail to initialize keep their previous instance. The errors of those are
+// returned as reload errors instead of failing the reload.
+func initializeConfigs(ctx context.Context, cfg ServerConfig, prev *resources.ResourceManager) (
+	map[string]sources.Source,
+	map[string]auth.AuthService,
+	map[string]embeddingmodels.EmbeddingModel,
+	map[string]tools.Tool,
+	map[string]tools.Toolset,
+	map[string]prompts.Prompt,
+	map[string]prompts.Promptset,
+	[]error,
+	error,
 ) {
 	metadataStr := cfg.Version
 	if len(cfg.UserAgentMetadata) > 0 {
@@ -79,9 +102,32 @@ func InitializeConfigs(ctx context.Context, cfg ServerConfig) (
 		panic(err)
 	}
 
+	var prevSources map[string]sources.Source
+	var prevTools map[string]tools.Tool
+	if prev != nil {
+		prevSources = prev.GetSourcesMap()
+		prevTools = prev.GetToolsMap()
+	}
+	var reloadErrs []error
+
 	// initialize and validate the sources from configs
 	sourcesMap := make(map[string]sources.Source)
+	// changedSources are the names of the sources that have a new instance, or
+	// were removed, on reload
+	changedSources := make(map[string]bool)
+	for name := range prevSources {
+		if _, ok := cfg.SourceConfigs[name]; !ok {
+			changedSources[name] = true
+		}
+	}
 	for name, sc := range cfg.SourceConfigs {
+		old, hasOld := prevSources[name]
+		// the options available to every source type do not change the
+		// source itself, see newSourceLimiters
+		if hasOld && reflect.DeepEqual(old.ToConfig(), sources.UnwrapConfig(sc)) {
+			sourcesMap[name] = old
+			continue
+		}
 		s, err := func() (sources.Source, error) {
 			childCtx, span := instrumentation.Tracer.Start(
 				ctx,
@@ -96,10 +142,19 @@ func InitializeConfigs(ctx context.Context, cfg ServerConfig) (
 			}
 			return s, nil
 		}()
+		if err != nil && prev != nil {
+			reloadErrs = append(reloadErrs, err)
+			if hasOld {
+				l.WarnContext(ctx, fmt.Sprintf("keeping previous instance of source %q: %s", name, err))
+				sourcesMap[name] = old
+			}
+			continue
+		}
 		if err != nil {
-			return nil, nil, nil, nil, nil, nil, nil, err
+			return nil, nil, nil, nil, nil, nil, nil, nil, err
 		}
 		sourcesMap[name] = s
+		changedSources[name] = true
 	}
 	sourceNames := make([]string, 0, len(sourcesMap))
 	for name := range sourcesMap {
@@ -125,7 +180,7 @@ func InitializeConfigs(ctx context.Context, cfg ServerConfig) (
 			return a, nil
 		}()
 		if err != nil {
-			return nil, nil, nil, nil, nil, nil, nil, err
+			return nil, nil, nil, nil, nil, nil, nil, nil, err
 		}
 		authServicesMap[name] = a
 	}
@@ -153,7 +208,7 @@ func InitializeConfigs(ctx context.Context, cfg ServerConfig) (
 			return em, nil
 		}()
 		if err != nil {
-			return nil, nil, nil, nil, nil, nil, nil, err
+			return nil, nil, nil, nil, nil, nil, nil, nil, err
 		}
 		embeddingModelsMap[name] = em
 	}
@@ -163,25 +218,51 @@ func InitializeConfigs(ctx context.Context, cfg ServerConfig) (
 	}
 	l.InfoContext(ctx, fmt.Sprintf("Initialized %d embeddingModels: %s", len(embeddingModelsMap), strings.Join(embeddingModelNames, ", ")))
 
+	l.DebugContext(ctx, fmt.Sprintf("Registered tool types: %s", formatToolTypes(tools.RegisteredTypes())))
+
 	// initialize and validate the tools from configs
 	toolsMap := make(map[string]tools.Tool)
 	for name, tc := range cfg.ToolConfigs {
+		old, hasOld := prevTools[name]
+		if hasOld && reflect.DeepEqual(old.ToConfig(), tc) && !usesChangedSource(tc, changedSources) {
+			if err := old.GetParameters().ValidateEmbedOptions(embeddingModelsMap); err == nil {
+				toolsMap[name] = old
+				continue
+			}
+		}
 		t, err := func() (tools.Tool, error) {
-			_, span := instrumentation.Tracer.Start(
+			childCtx, span := instrumentation.Tracer.Start(
 				ctx,
 				"toolbox/server/tool/init",
 				trace.WithAttributes(attribute.String("tool_type", tc.ToolConfigType())),
 				trace.WithAttributes(attribute.String("tool_name", name)),
 			)
 			defer span.End()
-			t, err := tc.Initialize(sourcesMap)
+			var t tools.Tool
+			var err error
+			if ctc, ok := tc.(tools.ToolConfigWithContext); ok {
+				t, err = ctc.InitializeWithContext(childCtx, sourcesMap)
+			} else {
+				t, err = tc.Initialize(sourcesMap)
+			}
 			if err != nil {
 				return nil, fmt.Errorf("unable to initialize tool %q: %w", name, err)
 			}
+			if err := t.GetParameters().ValidateEmbedOptions(embeddingModelsMap); err != nil {
+				return nil, fmt.Errorf("unable to initialize tool %q: %w", name, err)
+			}
 			return t, nil
 		}()
+		if err != nil && prev != nil {
+			reloadErrs = append(reloadErrs, err)
+			if hasOld {
+				l.WarnContext(ctx, fmt.Sprintf("keeping previous instance of tool %q: %s", name, err))
+				toolsMap[name] = old
+			}
+			continue
+		}
 		if err != nil {
-			return nil, nil, nil, nil, nil, nil, nil, err
+			return nil, nil, nil, nil, nil, nil, nil, nil, err
 		}
 		toolsMap[name] = t
 	}
@@ -218,7 +299,7 @@ func InitializeConfigs(ctx context.Context, cfg ServerConfig) (
 			return t, err
 		}()
 		if err != nil {
-			return nil, nil, nil, nil, nil, nil, nil, err
+			return nil, nil, nil, nil, nil, nil, nil, nil, err
 		}
 		toolsetsMap[name] = t
 	}
@@ -250,7 +331,7 @@ func InitializeConfigs(ctx context.Context, cfg ServerConfig) (
 			return p, nil
 		}()
 		if err != nil {
-			return nil, nil, nil, nil, nil, nil, nil, err
+			return nil, nil, nil, nil, nil, nil, nil, nil, err
 		}
 		promptsMap[name] = p
 	}
@@ -287,7 +368,7 @@ func InitializeConfigs(ctx context.Context, cfg ServerConfig) (
 			return p, err
 		}()
 		if err != nil {
-			return nil, nil, nil, nil, nil, nil, nil, err
+			return nil, nil, nil, nil, nil, nil, nil, nil, err
 		}
 		promptsetsMap[name] = p
 	}
@@ -301,7 +382,50 @@ func InitializeConfigs(ctx context.Context, cfg ServerConfig) (
 	}
 	l.InfoContext(ctx, fmt.Sprintf("Initialized %d promptsets: %s", len(promptsetsMap), strings.Join(promptsetNames, ", ")))
 
-	return sourcesMap, authServicesMap, embeddingModelsMap, toolsMap, toolsetsMap, promptsMap, promptsetsMap, nil
+	return sourcesMap, authServicesMap, embeddingModelsMap, toolsMap, toolsetsMap, promptsMap, promptsetsMap, reloadErrs, nil
+}
+
+// newSourceLimiters returns the limiters of the sources of configs that limit
+// their concurrent invocations. If prev is not nil, sources whose limits are
+// unchanged keep their limiter, so that the invocations in flight during a
+// reload still count towards the limit.
+func newSourceLimiters(configs SourceConfigs, prev *resources.ResourceManager, inFlightMetric metric.Int64UpDownCounter) map[string]*sources.Limiter {
+	limiters := make(map[string]*sources.Limiter)
+	for name, sc := range configs {
+		opts := sources.GetCommonOptions(sc)
+		if prev != nil {
+			if old, ok := prev.GetSourceLimiter(name); ok && old.Options() == opts {
+				limiters[name] = old
+				continue
+			}
+		}
+		if l := sources.NewLimiter(name, opts, inFlightMetric); l != nil {
+			limiters[name] = l
+		}
+	}
+	return limiters
+}
+
+// formatToolTypes lists tool types with their aliases, e.g.
+// "bigquery-sql (aliases: bq-sql [deprecated]), http".
+func formatToolTypes(types []tools.RegisteredType) string {
+	names := make([]string, 0, len(types))
+	for _, t := range types {
+		if len(t.Aliases) == 0 {
+			names = append(names, t.Type)
+			continue
+		}
+		aliases := make([]string, 0, len(t.Aliases))
+		for _, alias := range t.Aliases {
+			if alias.Deprecated {
+				aliases = append(aliases, alias.Name+" [deprecated]")
+			} else {
+				aliases = append(aliases, alias.Name)
+			}
+		}
+		names = append(names, fmt.Sprintf("%s (aliases: %s)", t.Type, strings.Join(aliases, ", ")))
+	}
+	return strings.Join(names, ", ")
 }
 
 func hostCheck(allowedHosts map[string]struct{}) func(http.Handler) http.Handler {
@@ -369,6 +493,15 @@ func NewServer(ctx context.Context, cfg ServerConfig) (*Server, error) {
 	sseManager := newSseManager(ctx)
 
 	resourceManager := resources.NewResourceManager(sourcesMap, authServicesMap, embeddingModelsMap, toolsMap, toolsetsMap, promptsMap, promptsetsMap)
+	resourceManager.SetRequireDestructiveConfirmation(cfg.RequireDestructiveConfirmation)
+	tools.SetStrictBearerTokens(cfg.StrictBearerTokens)
+	resourceManager.SetMaxStreamBufferedChunks(cfg.MaxStreamBufferedChunks)
+	resourceManager.SetSourceLimiters(newSourceLimiters(cfg.SourceConfigs, nil, instrumentation.SourceInvocationsInFlight))
+	if cfg.EmbeddingCacheSize > 0 {
+		cache := embeddingmodels.NewCache(int64(cfg.EmbeddingCacheSize)<<20, cfg.EmbeddingCacheTTL, instrumentation.EmbeddingCacheLookup)
+		resourceManager.SetEmbeddingCache(cache)
+		l.InfoContext(ctx, fmt.Sprintf("Caching embeddings in up to %d MiB", cfg.EmbeddingCacheSize))
+	}
 
 	s := &Server{
 		version:         cfg.Version,
@@ -377,6 +510,7 @@ func NewServer(ctx context.Context, cfg ServerConfig) (*Server, error) {
 		logger:          l,
 		instrumentation: instrumentation,
 		sseManager:      sseManager,
+		readiness:       newReadinessChecker(l),
 		ResourceMgr:     resourceManager,
 	}
 
@@ -429,6 +563,8 @@ func NewServer(ctx context.Context, cfg ServerConfig) (*Server, error) {
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
 		_, _ = w.Write([]byte("🧰 Hello, World! 🧰"))
 	})
+	// readiness of the sources, e.g. for the readiness probes of Kubernetes
+	r.Get("/readyz", s.readyzHandler)
 
 	return s, nil
 }
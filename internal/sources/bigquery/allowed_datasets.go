@@ -0,0 +1,127 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"google.golang.org/api/googleapi"
+)
+
+// Modes of verifyAllowedDatasets, which checks the allowed datasets of the
+// source when it is initialized.
+const (
+	// VerifyAllowedDatasetsStrict fails the initialization of the source if
+	// an allowed dataset does not exist or is not accessible.
+	VerifyAllowedDatasetsStrict = "strict"
+	// VerifyAllowedDatasetsWarn logs these datasets as warnings instead.
+	VerifyAllowedDatasetsWarn = "warn"
+	// VerifyAllowedDatasetsSkip does not look up the allowed datasets, e.g.
+	// for tests without access to BigQuery.
+	VerifyAllowedDatasetsSkip = "skip"
+)
+
+// allowedDatasetWorkers bounds the concurrent lookups of the allowed
+// datasets.
+const allowedDatasetWorkers = 4
+
+// allowedDataset is a dataset of the allowedDatasets of the source.
+type allowedDataset struct {
+	ProjectID string
+	DatasetID string
+}
+
+// verifyAllowedDatasets looks up datasets with the source's ADC credentials,
+// recording their locations like DatasetLocation, in the given mode. In
+// VerifyAllowedDatasetsStrict mode, it returns the error of the first dataset,
+// in the order of datasets, that does not exist or is not accessible. In
+// VerifyAllowedDatasetsWarn mode, these datasets are logged instead. Sources
+// that require client authorization have no credentials to look them up, and
+// are not verified.
+func (s *Source) verifyAllowedDatasets(ctx context.Context, datasets []allowedDataset, mode string) error {
+	if mode == VerifyAllowedDatasetsSkip || len(datasets) == 0 {
+		return nil
+	}
+	if s.Client == nil && s.makeADCClients == nil {
+		return nil
+	}
+	if _, _, err := s.adcClients(); err != nil {
+		if mode == VerifyAllowedDatasetsStrict {
+			return err
+		}
+		s.warnAllowedDataset(ctx, fmt.Sprintf("unable to verify the allowed datasets: %s", err))
+		return nil
+	}
+
+	errs := make([]error, len(datasets))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(allowedDatasetWorkers, len(datasets)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				d := datasets[i]
+				if _, err := s.DatasetLocation(ctx, "", d.ProjectID, d.DatasetID); err != nil {
+					errs[i] = allowedDatasetError(d, err)
+				}
+			}
+		}()
+	}
+	for i := range datasets {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if mode == VerifyAllowedDatasetsStrict {
+			return err
+		}
+		s.warnAllowedDataset(ctx, err.Error())
+	}
+	return nil
+}
+
+// allowedDatasetError returns the error of the lookup of the allowed dataset
+// d that failed with err.
+func allowedDatasetError(d allowedDataset, err error) error {
+	var gerr *googleapi.Error
+	if errors.As(err, &gerr) {
+		switch gerr.Code {
+		case http.StatusNotFound:
+			return fmt.Errorf("allowedDataset '%s' not found in project '%s'", d.DatasetID, d.ProjectID)
+		case http.StatusForbidden:
+			return fmt.Errorf("allowedDataset '%s' in project '%s' is not accessible with the credentials of the source: %w", d.DatasetID, d.ProjectID, err)
+		}
+	}
+	return fmt.Errorf("failed to verify allowedDataset '%s' in project '%s': %w", d.DatasetID, d.ProjectID, err)
+}
+
+// warnAllowedDataset logs msg, a failed verification of the allowed datasets,
+// as a warning.
+func (s *Source) warnAllowedDataset(ctx context.Context, msg string) {
+	if logger, err := util.LoggerFromContext(ctx); err == nil {
+		logger.WarnContext(ctx, fmt.Sprintf("source %q: %s", s.Name, msg))
+	}
+}
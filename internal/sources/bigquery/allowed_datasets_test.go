@@ -0,0 +1,108 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	bigqueryapi "cloud.google.com/go/bigquery"
+	"golang.org/x/oauth2"
+	bigqueryrestapi "google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/option"
+)
+
+func TestVerifyAllowedDatasets(t *testing.T) {
+	var mu sync.Mutex
+	calls := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls[r.URL.Path]++
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/projects/my-project/datasets/sales":
+			fmt.Fprint(w, `{"location": "US"}`)
+		case "/projects/other-project/datasets/eu_sales":
+			fmt.Fprint(w, `{"location": "europe-west1"}`)
+		case "/projects/other-project/datasets/private":
+			http.Error(w, `{"error": {"code": 403, "message": "access denied"}}`, http.StatusForbidden)
+		default:
+			http.Error(w, `{"error": {"code": 404, "message": "not found"}}`, http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	restService, err := bigqueryrestapi.NewService(context.Background(), option.WithEndpoint(server.URL), option.WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatalf("unable to create REST service: %s", err)
+	}
+	newSource := func() *Source {
+		s := &Source{Config: Config{Name: "my-bq", Project: "my-project"}}
+		s.makeADCClients = func() (*bigqueryapi.Client, *bigqueryrestapi.Service, oauth2.TokenSource, error) {
+			return &bigqueryapi.Client{}, restService, nil, nil
+		}
+		return s
+	}
+	ok := []allowedDataset{{"my-project", "sales"}, {"other-project", "eu_sales"}}
+	broken := append(ok, allowedDataset{"my-project", "missing"}, allowedDataset{"other-project", "private"})
+
+	ctx := context.Background()
+	tcs := []struct {
+		desc     string
+		datasets []allowedDataset
+		mode     string
+		wantErr  string
+	}{
+		{desc: "strict", datasets: ok, mode: VerifyAllowedDatasetsStrict},
+		{desc: "strict missing", datasets: broken, mode: VerifyAllowedDatasetsStrict, wantErr: "allowedDataset 'missing' not found in project 'my-project'"},
+		{desc: "strict forbidden", datasets: []allowedDataset{ok[1], {"other-project", "private"}}, mode: VerifyAllowedDatasetsStrict, wantErr: "allowedDataset 'private' in project 'other-project' is not accessible"},
+		{desc: "warn", datasets: broken, mode: VerifyAllowedDatasetsWarn},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := newSource()
+			err := s.verifyAllowedDatasets(ctx, tc.datasets, tc.mode)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("got error %v, want %q", err, tc.wantErr)
+			}
+			// the locations of the verified datasets are cached
+			if location, ok := s.datasetLocationCache().Get("other-project.eu_sales"); !ok || location != "europe-west1" {
+				t.Errorf("expected the location of other-project.eu_sales to be cached, got %v", location)
+			}
+		})
+	}
+
+	mu.Lock()
+	clear(calls)
+	mu.Unlock()
+	if err := newSource().verifyAllowedDatasets(ctx, broken, VerifyAllowedDatasetsSkip); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// sources that require client authorization have no credentials to verify them
+	if err := (&Source{}).verifyAllowedDatasets(ctx, broken, VerifyAllowedDatasetsStrict); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(calls) != 0 {
+		t.Errorf("expected no lookups, got %v", calls)
+	}
+}
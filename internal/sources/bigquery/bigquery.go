@@ -16,10 +16,12 @@ package bigquery
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"math/big"
-	"net/http"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -35,8 +37,6 @@ import (
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	bigqueryrestapi "google.golang.org/api/bigquery/v2"
-	"google.golang.org/api/googleapi"
-	"google.golang.org/api/impersonate"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
@@ -55,8 +55,19 @@ const (
 	WriteModeAllowed string = "allowed"
 )
 
+const (
+	// Every invocation uses the client's access token, and invocations
+	// without one are rejected.
+	ClientAuthorizationRequired string = "required"
+	// Invocations use the client's access token when they provide a valid
+	// one, and the source's ADC credentials otherwise.
+	ClientAuthorizationPreferred string = "preferred"
+	// Every invocation uses the source's ADC credentials.
+	ClientAuthorizationDisabled string = "disabled"
+)
+
 // validate interface
-var _ sources.SourceConfig = Config{}
+var _ sources.SourceConfigWithFields = Config{}
 
 type BigqueryClientCreator func(tokenString string, wantRestService bool) (*bigqueryapi.Client, *bigqueryrestapi.Service, error)
 
@@ -86,10 +97,28 @@ type Config struct {
 	Location                  string              `yaml:"location"`
 	WriteMode                 string              `yaml:"writeMode"`
 	AllowedDatasets           StringOrStringSlice `yaml:"allowedDatasets"`
+	VerifyAllowedDatasets     string              `yaml:"verifyAllowedDatasets"`
+	AllowedConnections        StringOrStringSlice `yaml:"allowedConnections"`
+	AllowedStorageURIPrefixes StringOrStringSlice `yaml:"allowedStorageUriPrefixes"`
 	UseClientOAuth            bool                `yaml:"useClientOAuth"`
+	ClientAuthorizationMode   string              `yaml:"clientAuthorizationMode"`
 	ImpersonateServiceAccount string              `yaml:"impersonateServiceAccount"`
+	ImpersonateDelegates      StringOrStringSlice `yaml:"impersonateDelegates"`
+	AllowedProjects           StringOrStringSlice `yaml:"allowedProjects"`
 	Scopes                    StringOrStringSlice `yaml:"scopes"`
 	MaxQueryResultRows        int                 `yaml:"maxQueryResultRows"`
+	DefaultJobLabels          map[string]string   `yaml:"defaultJobLabels"`
+	MaximumBytesBilled        *int64              `yaml:"maximumBytesBilled"`
+	UseStorageReadAPI         bool                `yaml:"useStorageReadApi"`
+	// RedactSQL replaces string literals in the SQL included in errors and
+	// logs of tool invocations.
+	RedactSQL bool `yaml:"redactSql"`
+	// LogUnredactedSQL also logs the full SQL at debug level when RedactSQL
+	// is set.
+	LogUnredactedSQL bool `yaml:"logUnredactedSql"`
+	// ChatRateLimit limits the Conversational Analytics chat requests of the
+	// tools of the source, which share the limit.
+	ChatRateLimit *ChatRateLimitConfig `yaml:"chatRateLimit"`
 }
 
 // StringOrStringSlice is a custom type that can unmarshal both a single string
@@ -123,6 +152,11 @@ func (r Config) SourceConfigType() string {
 	// Returns BigQuery source type
 	return SourceType
 }
+
+// DeclaredFields returns the fields that policies can set.
+func (r Config) DeclaredFields() []string {
+	return []string{"writeMode", "allowedDatasets", "allowedProjects", "allowedConnections", "allowedStorageUriPrefixes"}
+}
 func (r Config) Initialize(ctx context.Context, tracer trace.Tracer) (sources.Source, error) {
 	if r.WriteMode == "" {
 		r.WriteMode = WriteModeAllowed
@@ -132,86 +166,144 @@ func (r Config) Initialize(ctx context.Context, tracer trace.Tracer) (sources.So
 		r.MaxQueryResultRows = 50
 	}
 
-	if r.WriteMode == WriteModeProtected && r.UseClientOAuth {
+	// useClientOAuth is a shorthand for clientAuthorizationMode 'required'
+	switch {
+	case r.ClientAuthorizationMode == "" && r.UseClientOAuth:
+		r.ClientAuthorizationMode = ClientAuthorizationRequired
+	case r.ClientAuthorizationMode == "":
+		r.ClientAuthorizationMode = ClientAuthorizationDisabled
+	case r.UseClientOAuth && r.ClientAuthorizationMode != ClientAuthorizationRequired:
+		return nil, fmt.Errorf("useClientOAuth 'true' cannot be used with clientAuthorizationMode %q", r.ClientAuthorizationMode)
+	}
+	if r.ClientAuthorizationMode != ClientAuthorizationRequired && r.ClientAuthorizationMode != ClientAuthorizationPreferred && r.ClientAuthorizationMode != ClientAuthorizationDisabled {
+		return nil, fmt.Errorf("invalid clientAuthorizationMode %q: must be one of %q, %q, or %q", r.ClientAuthorizationMode, ClientAuthorizationRequired, ClientAuthorizationPreferred, ClientAuthorizationDisabled)
+	}
+	r.UseClientOAuth = r.ClientAuthorizationMode == ClientAuthorizationRequired
+
+	if r.WriteMode == WriteModeProtected && r.ClientAuthorizationMode != ClientAuthorizationDisabled {
 		// The protected mode only allows write operations to the session's temporary datasets.
 		// when using client OAuth, a new session is created every
 		// time a BigQuery tool is invoked. Therefore, no session data can
 		// be preserved as needed by the protected mode.
-		return nil, fmt.Errorf("writeMode 'protected' cannot be used with useClientOAuth 'true'")
+		return nil, fmt.Errorf("writeMode 'protected' cannot be used with client OAuth")
+	}
+
+	if err := ValidateJobOptions("defaultJobLabels", r.DefaultJobLabels, r.MaximumBytesBilled); err != nil {
+		return nil, err
 	}
 
 	if r.UseClientOAuth && r.ImpersonateServiceAccount != "" {
 		return nil, fmt.Errorf("useClientOAuth cannot be used with impersonateServiceAccount")
 	}
-
-	var client *bigqueryapi.Client
-	var restService *bigqueryrestapi.Service
-	var tokenSource oauth2.TokenSource
-	var clientCreator BigqueryClientCreator
-	var err error
+	if len(r.ImpersonateDelegates) > 0 && r.ImpersonateServiceAccount == "" {
+		return nil, fmt.Errorf("impersonateDelegates requires impersonateServiceAccount")
+	}
+	if r.LogUnredactedSQL && !r.RedactSQL {
+		return nil, fmt.Errorf("logUnredactedSql requires redactSql")
+	}
 
 	s := &Source{
 		Config:             r,
-		Client:             client,
-		RestService:        restService,
-		TokenSource:        tokenSource,
 		MaxQueryResultRows: r.MaxQueryResultRows,
-		ClientCreator:      clientCreator,
 	}
-
-	if r.UseClientOAuth {
-		// use client OAuth
-		baseClientCreator, err := newBigQueryClientCreator(ctx, tracer, r.Project, r.Location, r.Name)
+	if r.ChatRateLimit != nil {
+		limiter, err := r.ChatRateLimit.NewLimiter("source/" + r.Name)
 		if err != nil {
-			return nil, fmt.Errorf("error constructing client creator: %w", err)
+			return nil, fmt.Errorf("invalid chatRateLimit: %w", err)
 		}
-		setupClientCaching(s, baseClientCreator)
+		s.chatRateLimiter = limiter
+	}
 
-	} else {
-		// Initializes a BigQuery Google SQL source
-		client, restService, tokenSource, err = initBigQueryConnection(ctx, tracer, r.Name, r.Project, r.Location, r.ImpersonateServiceAccount, r.Scopes)
+	if r.ClientAuthorizationMode != ClientAuthorizationRequired {
+		// The clients of the ADC credentials are created on first use, and
+		// shared by all invocations. They outlive this call, so they must
+		// not be bound to its cancellation.
+		adcCtx := context.WithoutCancel(ctx)
+		s.makeADCClients = func() (*bigqueryapi.Client, *bigqueryrestapi.Service, oauth2.TokenSource, error) {
+			return initBigQueryConnection(adcCtx, tracer, r.Name, r.Project, s.jobLocation(), r.ImpersonateServiceAccount, r.ImpersonateDelegates, r.Scopes)
+		}
+	}
+
+	if len(r.AllowedProjects) > 0 {
+		for i, project := range r.AllowedProjects {
+			r.AllowedProjects[i] = strings.TrimSpace(project)
+		}
+		userAgent, err := util.UserAgentFromContext(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("error creating client from ADC: %w", err)
+			return nil, err
+		}
+		projectCtx := context.WithoutCancel(ctx)
+		s.makeProjectClient = func(project, tokenString string) (*bigqueryapi.Client, error) {
+			if tokenString != "" {
+				client, _, err := initBigQueryConnectionWithOAuthToken(projectCtx, tracer, project, s.jobLocation(), r.Name, userAgent, tokenString, false)
+				return client, err
+			}
+			client, _, _, err := initBigQueryConnection(projectCtx, tracer, r.Name, project, s.jobLocation(), r.ImpersonateServiceAccount, r.ImpersonateDelegates, r.Scopes)
+			return client, err
 		}
-		s.Client = client
-		s.RestService = restService
-		s.TokenSource = tokenSource
+		s.projectClients = sources.NewCacheWithTTL(tokenClientTTL, func(key string, value any) {
+			if client, ok := value.(*bigqueryapi.Client); ok && client != nil {
+				closeClient(client)
+			}
+		})
 	}
 
+	switch r.VerifyAllowedDatasets {
+	case "":
+		r.VerifyAllowedDatasets = VerifyAllowedDatasetsStrict
+		s.Config.VerifyAllowedDatasets = r.VerifyAllowedDatasets
+	case VerifyAllowedDatasetsStrict, VerifyAllowedDatasetsWarn, VerifyAllowedDatasetsSkip:
+	default:
+		return nil, fmt.Errorf("invalid verifyAllowedDatasets %q: must be one of %q, %q, or %q", r.VerifyAllowedDatasets, VerifyAllowedDatasetsStrict, VerifyAllowedDatasetsWarn, VerifyAllowedDatasetsSkip)
+	}
 	allowedDatasets := make(map[string]struct{})
-	// Get full id of allowed datasets and verify they exist.
-	if len(r.AllowedDatasets) > 0 {
-		for _, allowed := range r.AllowedDatasets {
-			var projectID, datasetID, allowedFullID string
-			if strings.Contains(allowed, ".") {
-				parts := strings.Split(allowed, ".")
-				if len(parts) != 2 {
-					return nil, fmt.Errorf("invalid allowedDataset format: %q, expected 'project.dataset' or 'dataset'", allowed)
-				}
-				projectID = parts[0]
-				datasetID = parts[1]
-				allowedFullID = allowed
-			} else {
-				projectID = r.Project
-				datasetID = allowed
-				allowedFullID = fmt.Sprintf("%s.%s", projectID, datasetID)
+	var datasets []allowedDataset
+	for _, allowed := range r.AllowedDatasets {
+		var projectID, datasetID string
+		if strings.Contains(allowed, ".") {
+			parts := strings.Split(allowed, ".")
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("invalid allowedDataset format: %q, expected 'project.dataset' or 'dataset'", allowed)
 			}
+			projectID = parts[0]
+			datasetID = parts[1]
+		} else {
+			projectID = r.Project
+			datasetID = allowed
+		}
+		// project IDs are case-insensitive, see IsDatasetAllowed
+		key := strings.ToLower(projectID) + "." + datasetID
+		if _, ok := allowedDatasets[key]; !ok {
+			allowedDatasets[key] = struct{}{}
+			datasets = append(datasets, allowedDataset{ProjectID: projectID, DatasetID: datasetID})
+		}
+	}
+	// verify that the allowed datasets exist, and record their locations
+	if err := s.verifyAllowedDatasets(ctx, datasets, r.VerifyAllowedDatasets); err != nil {
+		return nil, err
+	}
+	s.detectLocation(ctx, datasets)
 
-			if s.Client != nil {
-				dataset := s.Client.DatasetInProject(projectID, datasetID)
-				_, err := dataset.Metadata(ctx)
-				if err != nil {
-					if gerr, ok := err.(*googleapi.Error); ok && gerr.Code == http.StatusNotFound {
-						return nil, fmt.Errorf("allowedDataset '%s' not found in project '%s'", datasetID, projectID)
-					}
-					return nil, fmt.Errorf("failed to verify allowedDataset '%s' in project '%s': %w", datasetID, projectID, err)
-				}
-			}
-			allowedDatasets[allowedFullID] = struct{}{}
+	if r.ClientAuthorizationMode != ClientAuthorizationDisabled {
+		// use client OAuth
+		baseClientCreator, err := newBigQueryClientCreator(ctx, tracer, r.Project, s.jobLocation(), r.Name)
+		if err != nil {
+			return nil, fmt.Errorf("error constructing client creator: %w", err)
 		}
+		setupClientCaching(s, baseClientCreator)
 	}
 
 	s.AllowedDatasets = allowedDatasets
+	allowedConnections, err := normalizeAllowedConnections(r)
+	if err != nil {
+		return nil, err
+	}
+	s.AllowedConnections = allowedConnections
+	allowedStorageURIPrefixes, err := normalizeAllowedStorageURIPrefixes(r)
+	if err != nil {
+		return nil, err
+	}
+	s.AllowedStorageURIPrefixes = allowedStorageURIPrefixes
 	s.SessionProvider = s.newBigQuerySessionProvider()
 
 	if r.WriteMode != WriteModeAllowed && r.WriteMode != WriteModeBlocked && r.WriteMode != WriteModeProtected {
@@ -221,12 +313,25 @@ func (r Config) Initialize(ctx context.Context, tracer trace.Tracer) (sources.So
 	return s, nil
 }
 
-// setupClientCaching initializes caches and wraps the base client creator with caching logic.
+// tokenClientTTL is how long the clients created for a client's access token
+// are cached. It can be overridden for testing.
+var tokenClientTTL = 55 * time.Minute
+
+// tokenCacheKey returns the key of the clients of an access token in the
+// client caches, so that the caches do not hold the tokens themselves.
+func tokenCacheKey(tokenString string) string {
+	sum := sha256.Sum256([]byte(tokenString))
+	return hex.EncodeToString(sum[:])
+}
+
+// setupClientCaching initializes caches and wraps the base client creator
+// with caching logic. Clients are cached per access token, and closed when
+// they expire.
 func setupClientCaching(s *Source, baseCreator BigqueryClientCreator) {
 	// Define eviction handlers
 	onBqEvict := func(key string, value interface{}) {
 		if client, ok := value.(*bigqueryapi.Client); ok && client != nil {
-			client.Close()
+			closeClient(client)
 		}
 	}
 	onDataplexEvict := func(key string, value interface{}) {
@@ -236,17 +341,18 @@ func setupClientCaching(s *Source, baseCreator BigqueryClientCreator) {
 	}
 
 	// Initialize caches
-	s.bqClientCache = sources.NewCache(onBqEvict)
-	s.bqRestCache = sources.NewCache(nil)
+	s.bqClientCache = sources.NewCacheWithTTL(tokenClientTTL, onBqEvict)
+	s.bqRestCache = sources.NewCacheWithTTL(tokenClientTTL, nil)
 	s.dataplexCache = sources.NewCache(onDataplexEvict)
 
 	// Create the caching wrapper for the client creator
 	s.ClientCreator = func(tokenString string, wantRestService bool) (*bigqueryapi.Client, *bigqueryrestapi.Service, error) {
 		// Check cache
-		bqClientVal, bqFound := s.bqClientCache.Get(tokenString)
+		key := tokenCacheKey(tokenString)
+		bqClientVal, bqFound := s.bqClientCache.Get(key)
 
 		if wantRestService {
-			restServiceVal, restFound := s.bqRestCache.Get(tokenString)
+			restServiceVal, restFound := s.bqRestCache.Get(key)
 			if bqFound && restFound {
 				// Cache hit for both
 				return bqClientVal.(*bigqueryapi.Client), restServiceVal.(*bigqueryrestapi.Service), nil
@@ -264,9 +370,9 @@ func setupClientCaching(s *Source, baseCreator BigqueryClientCreator) {
 		}
 
 		// Set in cache
-		s.bqClientCache.Set(tokenString, client)
+		s.bqClientCache.Set(key, client)
 		if wantRestService && restService != nil {
-			s.bqRestCache.Set(tokenString, restService)
+			s.bqRestCache.Set(key, restService)
 		}
 
 		return client, restService, nil
@@ -277,21 +383,60 @@ var _ sources.Source = &Source{}
 
 type Source struct {
 	Config
+	// Client, RestService and TokenSource use the source's ADC credentials.
+	// Unless set, they are created on first use by makeADCClients, see
+	// adcClients.
 	Client                    *bigqueryapi.Client
 	RestService               *bigqueryrestapi.Service
 	TokenSource               oauth2.TokenSource
 	MaxQueryResultRows        int
 	ClientCreator             BigqueryClientCreator
 	AllowedDatasets           map[string]struct{}
+	AllowedConnections        map[string]struct{}
+	AllowedStorageURIPrefixes []string
 	sessionMutex              sync.Mutex
 	makeDataplexCatalogClient func() (*dataplexapi.CatalogClient, DataplexClientCreator, error)
 	SessionProvider           BigQuerySessionProvider
 	Session                   *Session
 
+	// adcMu guards the creation of the clients of the ADC credentials by
+	// makeADCClients, which is nil if the source requires client
+	// authorization.
+	adcMu          sync.Mutex
+	makeADCClients func() (*bigqueryapi.Client, *bigqueryrestapi.Service, oauth2.TokenSource, error)
+
+	// projectClients caches the clients of invocations that use one of the
+	// allowed projects instead of the source's project. They are created by
+	// makeProjectClient, see projectClient.
+	projectClientsMu  sync.Mutex
+	projectClients    *sources.Cache
+	makeProjectClient func(project, tokenString string) (*bigqueryapi.Client, error)
+
 	// Caches for OAuth clients
 	bqClientCache *sources.Cache
 	bqRestCache   *sources.Cache
 	dataplexCache *sources.Cache
+
+	// tokenSources caches the token source created for each set of scopes, so
+	// that unexpired tokens are reused across tool invocations.
+	tokenSourcesMu sync.Mutex
+	tokenSources   map[string]oauth2.TokenSource
+	// makeTokenSource creates a token source for the given scopes. It
+	// defaults to newTokenSource and can be overridden for testing.
+	makeTokenSource func(ctx context.Context, scopes []string) (oauth2.TokenSource, error)
+
+	// datasetLocations caches the locations of datasets, see DatasetLocation,
+	// and linkedDatasets their Analytics Hub sources, see
+	// LinkedDatasetSource.
+	datasetLocationsMu sync.Mutex
+	datasetLocations   *sources.Cache
+	linkedDatasets     *sources.Cache
+	// detectedLocation is the location shared by the allowed datasets, see
+	// detectLocation.
+	detectedLocation string
+
+	// chatRateLimiter is set if the source configures a chatRateLimit.
+	chatRateLimiter *ChatRateLimiter
 }
 
 type Session struct {
@@ -311,18 +456,70 @@ func (s *Source) ToConfig() sources.SourceConfig {
 	return s.Config
 }
 
+// adcClients returns the clients of the source's ADC credentials, creating
+// them on first use. They are shared by all invocations that use the ADC
+// credentials. If they cannot be created, the error is returned and creating
+// them is retried on the next use.
+func (s *Source) adcClients() (*bigqueryapi.Client, *bigqueryrestapi.Service, error) {
+	s.adcMu.Lock()
+	defer s.adcMu.Unlock()
+	if s.Client == nil && s.makeADCClients != nil {
+		client, restService, tokenSource, err := s.makeADCClients()
+		if err != nil {
+			return nil, nil, fmt.Errorf("error creating client from ADC: %w", err)
+		}
+		s.Client, s.RestService, s.TokenSource = client, restService, tokenSource
+	}
+	return s.Client, s.RestService, nil
+}
+
+// BigQueryClient returns the shared client of the source's ADC credentials,
+// or nil if the source requires client authorization or the client cannot be
+// created. Use BigQueryClientFor to get the client of an invocation.
 func (s *Source) BigQueryClient() *bigqueryapi.Client {
-	return s.Client
+	client, _, _ := s.adcClients()
+	return client
 }
 
+// BigQueryRestService returns the shared REST service of the source's ADC
+// credentials, or nil if the source requires client authorization or the
+// service cannot be created. Use BigQueryRestServiceFor to get the service of
+// an invocation.
 func (s *Source) BigQueryRestService() *bigqueryrestapi.Service {
-	return s.RestService
+	_, restService, _ := s.adcClients()
+	return restService
+}
+
+// BigQueryClientFor returns the client of an invocation with accessToken, see
+// RetrieveClientAndService.
+func (s *Source) BigQueryClientFor(ctx context.Context, accessToken tools.AccessToken) (*bigqueryapi.Client, error) {
+	client, _, err := s.RetrieveClientAndService(ctx, accessToken)
+	return client, err
+}
+
+// BigQueryRestServiceFor returns the REST service of an invocation with
+// accessToken, see RetrieveClientAndService.
+func (s *Source) BigQueryRestServiceFor(ctx context.Context, accessToken tools.AccessToken) (*bigqueryrestapi.Service, error) {
+	_, restService, err := s.RetrieveClientAndService(ctx, accessToken)
+	return restService, err
 }
 
 func (s *Source) BigQueryWriteMode() string {
 	return s.WriteMode
 }
 
+// BigQueryRedactSQL reports whether string literals are redacted from the SQL
+// in errors and logs.
+func (s *Source) BigQueryRedactSQL() bool {
+	return s.RedactSQL
+}
+
+// BigQueryLogUnredactedSQL reports whether the full SQL is also logged at
+// debug level when it is redacted.
+func (s *Source) BigQueryLogUnredactedSQL() bool {
+	return s.LogUnredactedSQL
+}
+
 func (s *Source) BigQuerySession() BigQuerySessionProvider {
 	return s.SessionProvider
 }
@@ -359,7 +556,7 @@ func (s *Source) newBigQuerySessionProvider() BigQuerySessionProvider {
 						},
 					},
 				}
-				_, err := s.RestService.Jobs.Insert(s.Project, job).Do()
+				_, err := s.BigQueryRestService().Jobs.Insert(s.Project, job).Do()
 				if err == nil {
 					s.Session.LastUsed = time.Now()
 					return s.Session, nil
@@ -375,7 +572,7 @@ func (s *Source) newBigQuerySessionProvider() BigQuerySessionProvider {
 		job := &bigqueryrestapi.Job{
 			JobReference: &bigqueryrestapi.JobReference{
 				ProjectId: s.Project,
-				Location:  s.Location,
+				Location:  s.jobLocation(),
 			},
 			Configuration: &bigqueryrestapi.JobConfiguration{
 				DryRun: true,
@@ -386,7 +583,11 @@ func (s *Source) newBigQuerySessionProvider() BigQuerySessionProvider {
 			},
 		}
 
-		createdJob, err := s.RestService.Jobs.Insert(s.Project, job).Do()
+		_, restService, err := s.adcClients()
+		if err != nil {
+			return nil, err
+		}
+		createdJob, err := restService.Jobs.Insert(s.Project, job).Do()
 		if err != nil {
 			return nil, fmt.Errorf("failed to create new session: %w", err)
 		}
@@ -416,8 +617,63 @@ func (s *Source) newBigQuerySessionProvider() BigQuerySessionProvider {
 	}
 }
 
+// UseClientAuthorization reports whether every invocation must provide the
+// client's access token.
 func (s *Source) UseClientAuthorization() bool {
-	return s.UseClientOAuth
+	return s.ClientAuthorizationMode() == ClientAuthorizationRequired
+}
+
+// ClientAuthorizationMode returns whether invocations use the client's access
+// token: one of ClientAuthorizationRequired, ClientAuthorizationPreferred, or
+// ClientAuthorizationDisabled.
+func (s *Source) ClientAuthorizationMode() string {
+	if s.Config.ClientAuthorizationMode == "" && s.UseClientOAuth {
+		return ClientAuthorizationRequired
+	}
+	if s.Config.ClientAuthorizationMode == "" {
+		return ClientAuthorizationDisabled
+	}
+	return s.Config.ClientAuthorizationMode
+}
+
+// ClientToken returns the client's access token if an invocation with
+// accessToken uses it in mode, or "" if the invocation uses the source's ADC
+// credentials. In preferred mode, invocations without a valid bearer token use
+// the ADC credentials.
+func ClientToken(mode string, accessToken tools.AccessToken) (string, error) {
+	switch mode {
+	case ClientAuthorizationRequired:
+		tokenStr, err := accessToken.ParseBearerToken()
+		if err != nil {
+			return "", fmt.Errorf("error parsing access token: %w", err)
+		}
+		return tokenStr, nil
+	case ClientAuthorizationPreferred:
+		if accessToken == "" {
+			return "", nil
+		}
+		tokenStr, err := accessToken.ParseBearerToken()
+		if err != nil {
+			return "", nil
+		}
+		return tokenStr, nil
+	default:
+		return "", nil
+	}
+}
+
+// LogCredentials records whether an invocation of the tool or source named
+// name used the client's access token or the source's ADC credentials.
+func LogCredentials(ctx context.Context, name string, client bool) {
+	logger, err := util.LoggerFromContext(ctx)
+	if err != nil {
+		return
+	}
+	credentials := "adc"
+	if client {
+		credentials = "client"
+	}
+	logger.InfoContext(ctx, "invocation credentials", "resource", name, "credentials", credentials)
 }
 
 func (s *Source) BigQueryProject() string {
@@ -429,9 +685,17 @@ func (s *Source) BigQueryLocation() string {
 }
 
 func (s *Source) BigQueryTokenSource() oauth2.TokenSource {
+	if _, _, err := s.adcClients(); err != nil {
+		return nil
+	}
+	s.adcMu.Lock()
+	defer s.adcMu.Unlock()
 	return s.TokenSource
 }
 
+// BigQueryTokenSourceWithScope returns a token source for the given scopes,
+// defaulting to the scopes of the source. Token sources are cached per set of
+// scopes and reuse their token until it expires.
 func (s *Source) BigQueryTokenSourceWithScope(ctx context.Context, scopes []string) (oauth2.TokenSource, error) {
 	if len(scopes) == 0 {
 		scopes = s.Scopes
@@ -439,18 +703,40 @@ func (s *Source) BigQueryTokenSourceWithScope(ctx context.Context, scopes []stri
 			scopes = []string{CloudPlatformScope}
 		}
 	}
+	sortedScopes := append([]string(nil), scopes...)
+	sort.Strings(sortedScopes)
+	key := strings.Join(sortedScopes, " ")
 
-	if s.ImpersonateServiceAccount != "" {
-		// Create impersonated credentials token source with the requested scopes
-		ts, err := impersonate.CredentialsTokenSource(ctx, impersonate.CredentialsConfig{
-			TargetPrincipal: s.ImpersonateServiceAccount,
-			Scopes:          scopes,
-		})
-		if err != nil {
-			return nil, fmt.Errorf("failed to create impersonated credentials for %q with scopes %v: %w", s.ImpersonateServiceAccount, scopes, err)
-		}
+	s.tokenSourcesMu.Lock()
+	defer s.tokenSourcesMu.Unlock()
+	if ts, ok := s.tokenSources[key]; ok {
 		return ts, nil
 	}
+
+	makeTokenSource := s.makeTokenSource
+	if makeTokenSource == nil {
+		makeTokenSource = s.newTokenSource
+	}
+	// The token source outlives this call and refreshes tokens in later
+	// invocations, so it must not be bound to the caller's cancellation.
+	ts, err := makeTokenSource(context.WithoutCancel(ctx), scopes)
+	if err != nil {
+		return nil, err
+	}
+	ts = oauth2.ReuseTokenSource(nil, ts)
+	if s.tokenSources == nil {
+		s.tokenSources = make(map[string]oauth2.TokenSource)
+	}
+	s.tokenSources[key] = ts
+	return ts, nil
+}
+
+// newTokenSource creates a token source for the given scopes from the
+// impersonated service account, if configured, or ADC.
+func (s *Source) newTokenSource(ctx context.Context, scopes []string) (oauth2.TokenSource, error) {
+	if s.ImpersonateServiceAccount != "" {
+		return newImpersonatedTokenSource(ctx, s.ImpersonateServiceAccount, s.ImpersonateDelegates, scopes)
+	}
 	return google.DefaultTokenSource(ctx, scopes...)
 }
 
@@ -480,7 +766,7 @@ func (s *Source) IsDatasetAllowed(projectID, datasetID string) bool {
 		return true
 	}
 
-	targetDataset := fmt.Sprintf("%s.%s", projectID, datasetID)
+	targetDataset := fmt.Sprintf("%s.%s", strings.ToLower(projectID), datasetID)
 	_, ok := s.AllowedDatasets[targetDataset]
 	return ok
 }
@@ -497,7 +783,7 @@ func (s *Source) lazyInitDataplexClient(ctx context.Context, tracer trace.Tracer
 
 	return func() (*dataplexapi.CatalogClient, DataplexClientCreator, error) {
 		once.Do(func() {
-			c, cc, e := initDataplexConnection(ctx, tracer, s.Name, s.Project, s.UseClientOAuth, s.ImpersonateServiceAccount, s.Scopes)
+			c, cc, e := initDataplexConnection(ctx, tracer, s.Name, s.Project, s.ClientAuthorizationMode(), s.ImpersonateServiceAccount, s.ImpersonateDelegates, s.Scopes)
 			if e != nil {
 				err = fmt.Errorf("failed to initialize dataplex client: %w", e)
 				return
@@ -505,7 +791,7 @@ func (s *Source) lazyInitDataplexClient(ctx context.Context, tracer trace.Tracer
 			client = c
 
 			// If using OAuth, wrap the provided client creator (cc) with caching logic
-			if s.UseClientOAuth && cc != nil {
+			if cc != nil {
 				clientCreator = func(tokenString string) (*dataplexapi.CatalogClient, error) {
 					// Check cache
 					if val, found := s.dataplexCache.Get(tokenString); found {
@@ -523,7 +809,7 @@ func (s *Source) lazyInitDataplexClient(ctx context.Context, tracer trace.Tracer
 					return dpClient, nil
 				}
 			} else {
-				// Not using OAuth or no creator was returned
+				// Not using OAuth, so no creator was returned
 				clientCreator = cc
 			}
 		})
@@ -531,25 +817,99 @@ func (s *Source) lazyInitDataplexClient(ctx context.Context, tracer trace.Tracer
 	}
 }
 
-func (s *Source) RetrieveClientAndService(accessToken tools.AccessToken) (*bigqueryapi.Client, *bigqueryrestapi.Service, error) {
-	bqClient := s.BigQueryClient()
-	restService := s.BigQueryRestService()
+// RetrieveClientAndService returns the client and REST service of an
+// invocation with accessToken. They use the client's access token if the
+// source uses it for the invocation, and are cached per token; otherwise they
+// are the shared clients of the source's ADC credentials. If ctx has a
+// project set with WithProject, the client runs queries in that project.
+func (s *Source) RetrieveClientAndService(ctx context.Context, accessToken tools.AccessToken) (*bigqueryapi.Client, *bigqueryrestapi.Service, error) {
+	tokenStr, err := ClientToken(s.ClientAuthorizationMode(), accessToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	LogCredentials(ctx, s.Name, tokenStr != "")
 
 	// Initialize new client if using user OAuth token
-	if s.UseClientAuthorization() {
-		tokenStr, err := accessToken.ParseBearerToken()
-		if err != nil {
-			return nil, nil, fmt.Errorf("error parsing access token: %w", err)
-		}
+	var bqClient *bigqueryapi.Client
+	var restService *bigqueryrestapi.Service
+	if tokenStr != "" {
 		bqClient, restService, err = s.BigQueryClientCreator()(tokenStr, true)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error creating client from OAuth access token: %w", err)
 		}
+	} else {
+		bqClient, restService, err = s.adcClients()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// The REST service takes the project of each call, but the client runs
+	// queries in the project it is created for.
+	if project := s.BigQueryProjectFor(ctx); project != s.Project {
+		bqClient, err = s.projectClient(project, tokenStr)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 	return bqClient, restService, nil
 }
 
-func (s *Source) RunSQL(ctx context.Context, bqClient *bigqueryapi.Client, statement, statementType string, params []bigqueryapi.QueryParameter, connProps []*bigqueryapi.ConnectionProperty) (any, error) {
+func (s *Source) RunSQL(ctx context.Context, bqClient *bigqueryapi.Client, statement, statementType string, params []bigqueryapi.QueryParameter, connProps []*bigqueryapi.ConnectionProperty, jobOpts JobOptions) (any, error) {
+	var out []any
+	err := s.StreamSQL(ctx, bqClient, statement, params, connProps, jobOpts, 0, func(rows []any) error {
+		out = append(out, rows...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// If the query returned any rows, return them directly.
+	if len(out) > 0 {
+		return out, nil
+	}
+	return NoRowsResult(statementType), nil
+}
+
+// NoRowsResult is the result of a query of type statementType that returned
+// no rows.
+func NoRowsResult(statementType string) string {
+	// This handles the standard case for a SELECT query that successfully
+	// executes but returns zero rows.
+	if statementType == "SELECT" {
+		return "The query returned 0 rows."
+	}
+	// This is the fallback for a successful query that doesn't return content.
+	// In most cases, this will be for DML/DDL statements like INSERT, UPDATE, CREATE, etc.
+	// However, it is also possible that this was a query that was expected to return rows
+	// but returned none, a case that we cannot distinguish here.
+	return "Query executed successfully and returned no content."
+}
+
+// JobError is the error of a query job that was created, with the reference
+// of the job, e.g. to look up the child jobs of a script that failed.
+type JobError struct {
+	ProjectID string
+	JobID     string
+	Location  string
+	Err       error
+}
+
+func (e *JobError) Error() string { return e.Err.Error() }
+
+func (e *JobError) Unwrap() error { return e.Err }
+
+// newJobError returns the JobError of job for err.
+func newJobError(job *bigqueryapi.Job, err error) *JobError {
+	return &JobError{ProjectID: job.ProjectID(), JobID: job.ID(), Location: job.Location(), Err: err}
+}
+
+// StreamSQL runs statement like RunSQL, and passes its rows to emit in
+// batches of batchSize rows as they are read, or in a single batch if
+// batchSize is not positive. It stops reading the rows as soon as emit
+// returns an error, and returns that error. The errors of the query job once
+// it is created wrap a *JobError.
+func (s *Source) StreamSQL(ctx context.Context, bqClient *bigqueryapi.Client, statement string, params []bigqueryapi.QueryParameter, connProps []*bigqueryapi.ConnectionProperty, jobOpts JobOptions, batchSize int, emit func(rows []any) error) error {
 	query := bqClient.Query(statement)
 	query.Location = bqClient.Location
 	if params != nil {
@@ -558,51 +918,60 @@ func (s *Source) RunSQL(ctx context.Context, bqClient *bigqueryapi.Client, state
 	if connProps != nil {
 		query.ConnectionProperties = connProps
 	}
+	jobOpts.ApplyToQuery(query)
 
 	// This block handles SELECT statements, which return a row set.
 	// We iterate through the results, convert each row into a map of
-	// column names to values, and return the collection of rows.
+	// column names to values, and emit them in batches.
 	job, err := query.Run(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("unable to execute query: %w", err)
+		return fmt.Errorf("unable to execute query: %w", err)
+	}
+	if jobOpts.VerifyStatistics != nil {
+		status, err := job.Wait(ctx)
+		if err == nil {
+			err = status.Err()
+		}
+		if err != nil {
+			return fmt.Errorf("unable to execute query: %w", newJobError(job, err))
+		}
+		if err := jobOpts.VerifyStatistics(ctx, status.Statistics); err != nil {
+			return err
+		}
 	}
-	it, err := job.Read(ctx)
+	// Results of queries in sessions are read with the client of the session.
+	it, err := s.readQueryResults(ctx, bqClient, job, jobOpts.storageReadAPI() && len(connProps) == 0)
 	if err != nil {
-		return nil, fmt.Errorf("unable to read query results: %w", err)
+		return fmt.Errorf("unable to read query results: %w", newJobError(job, err))
 	}
 
-	var out []any
-	for s.MaxQueryResultRows <= 0 || len(out) < s.MaxQueryResultRows {
+	var batch []any
+	for count := 0; s.MaxQueryResultRows <= 0 || count < s.MaxQueryResultRows; count++ {
 		var val []bigqueryapi.Value
 		err = it.Next(&val)
 		if err == iterator.Done {
 			break
 		}
 		if err != nil {
-			return nil, fmt.Errorf("unable to iterate through query results: %w", err)
+			return fmt.Errorf("unable to iterate through query results: %w", err)
 		}
 		schema := it.Schema
 		row := orderedmap.Row{}
 		for i, field := range schema {
 			row.Add(field.Name, NormalizeValue(val[i]))
 		}
-		out = append(out, row)
-	}
-	// If the query returned any rows, return them directly.
-	if len(out) > 0 {
-		return out, nil
+		batch = append(batch, row)
+		if batchSize > 0 && len(batch) == batchSize {
+			if err := emit(batch); err != nil {
+				return err
+			}
+			batch = nil
+		}
 	}
-
-	// This handles the standard case for a SELECT query that successfully
-	// executes but returns zero rows.
-	if statementType == "SELECT" {
-		return "The query returned 0 rows.", nil
+	if len(batch) > 0 {
+		return emit(batch)
 	}
-	// This is the fallback for a successful query that doesn't return content.
-	// In most cases, this will be for DML/DDL statements like INSERT, UPDATE, CREATE, etc.
-	// However, it is also possible that this was a query that was expected to return rows
-	// but returned none, a case that we cannot distinguish here.
-	return "Query executed successfully and returned no content.", nil
+	return nil
 }
 
 // NormalizeValue converts BigQuery specific types to standard JSON-compatible types.
@@ -663,6 +1032,7 @@ func initBigQueryConnection(
 	project string,
 	location string,
 	impersonateServiceAccount string,
+	impersonateDelegates []string,
 	scopes []string,
 ) (*bigqueryapi.Client, *bigqueryrestapi.Service, oauth2.TokenSource, error) {
 	ctx, span := sources.InitConnectionSpan(ctx, tracer, SourceType, name)
@@ -688,12 +1058,9 @@ func initBigQueryConnection(
 	if impersonateServiceAccount != "" {
 		// Create impersonated credentials token source
 		// This broader scope is needed for tools like conversational analytics
-		cloudPlatformTokenSource, err := impersonate.CredentialsTokenSource(ctx, impersonate.CredentialsConfig{
-			TargetPrincipal: impersonateServiceAccount,
-			Scopes:          credScopes,
-		})
+		cloudPlatformTokenSource, err := newImpersonatedTokenSource(ctx, impersonateServiceAccount, impersonateDelegates, credScopes)
 		if err != nil {
-			return nil, nil, nil, fmt.Errorf("failed to create impersonated credentials for %q: %w", impersonateServiceAccount, err)
+			return nil, nil, nil, err
 		}
 		tokenSource = cloudPlatformTokenSource
 		opts = []option.ClientOption{
@@ -719,6 +1086,7 @@ func initBigQueryConnection(
 		return nil, nil, nil, fmt.Errorf("failed to create BigQuery client for project %q: %w", project, err)
 	}
 	client.Location = location
+	registerStorageReader(ctx, client, project, location, opts)
 
 	// Initialize the low-level BigQuery REST service using the same credentials
 	restService, err := bigqueryrestapi.NewService(ctx, opts...)
@@ -750,15 +1118,17 @@ func initBigQueryConnectionWithOAuthToken(
 	ts := oauth2.StaticTokenSource(token)
 
 	// Initialize the BigQuery client with tokenSource
-	client, err := bigqueryapi.NewClient(ctx, project, option.WithUserAgent(userAgent), option.WithTokenSource(ts))
+	opts := []option.ClientOption{option.WithUserAgent(userAgent), option.WithTokenSource(ts)}
+	client, err := bigqueryapi.NewClient(ctx, project, opts...)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create BigQuery client for project %q: %w", project, err)
 	}
 	client.Location = location
+	registerStorageReader(ctx, client, project, location, opts)
 
 	if wantRestService {
 		// Initialize the low-level BigQuery REST service using the same credentials
-		restService, err := bigqueryrestapi.NewService(ctx, option.WithUserAgent(userAgent), option.WithTokenSource(ts))
+		restService, err := bigqueryrestapi.NewService(ctx, opts...)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to create BigQuery v2 service: %w", err)
 		}
@@ -793,8 +1163,9 @@ func initDataplexConnection(
 	tracer trace.Tracer,
 	name string,
 	project string,
-	useClientOAuth bool,
+	clientAuthorizationMode string,
 	impersonateServiceAccount string,
+	impersonateDelegates []string,
 	scopes []string,
 ) (*dataplexapi.CatalogClient, DataplexClientCreator, error) {
 	var client *dataplexapi.CatalogClient
@@ -809,9 +1180,10 @@ func initDataplexConnection(
 		return nil, nil, err
 	}
 
-	if useClientOAuth {
+	if clientAuthorizationMode != ClientAuthorizationDisabled {
 		clientCreator = newDataplexClientCreator(ctx, project, userAgent)
-	} else {
+	}
+	if clientAuthorizationMode != ClientAuthorizationRequired {
 		var opts []option.ClientOption
 
 		credScopes := scopes
@@ -821,12 +1193,9 @@ func initDataplexConnection(
 
 		if impersonateServiceAccount != "" {
 			// Create impersonated credentials token source
-			ts, err := impersonate.CredentialsTokenSource(ctx, impersonate.CredentialsConfig{
-				TargetPrincipal: impersonateServiceAccount,
-				Scopes:          credScopes,
-			})
+			ts, err := newImpersonatedTokenSource(ctx, impersonateServiceAccount, impersonateDelegates, credScopes)
 			if err != nil {
-				return nil, nil, fmt.Errorf("failed to create impersonated credentials for %q: %w", impersonateServiceAccount, err)
+				return nil, nil, err
 			}
 			opts = []option.ClientOption{
 				option.WithUserAgent(userAgent),
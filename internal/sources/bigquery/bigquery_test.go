@@ -18,6 +18,7 @@ import (
 	"context"
 	"math/big"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -25,6 +26,7 @@ import (
 	"github.com/googleapis/genai-toolbox/internal/sources"
 	"github.com/googleapis/genai-toolbox/internal/sources/bigquery"
 	"github.com/googleapis/genai-toolbox/internal/testutils"
+	"github.com/googleapis/genai-toolbox/internal/tools"
 	"github.com/googleapis/genai-toolbox/internal/util"
 	"go.opentelemetry.io/otel/trace/noop"
 )
@@ -115,6 +117,48 @@ func TestParseFromYamlBigQuery(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "with allowed datasets verification example",
+			in: `
+			kind: sources
+			name: my-instance
+			type: bigquery
+			project: my-project
+			allowedDatasets:
+			- my_dataset
+			verifyAllowedDatasets: warn
+			`,
+			want: map[string]sources.SourceConfig{
+				"my-instance": bigquery.Config{
+					Name:                  "my-instance",
+					Type:                  bigquery.SourceType,
+					Project:               "my-project",
+					AllowedDatasets:       []string{"my_dataset"},
+					VerifyAllowedDatasets: "warn",
+				},
+			},
+		},
+		{
+			desc: "with allowed connections example",
+			in: `
+			kind: sources
+			name: my-instance
+			type: bigquery
+			project: my-project
+			location: us
+			allowedConnections:
+			- us.my_connection
+			`,
+			want: map[string]sources.SourceConfig{
+				"my-instance": bigquery.Config{
+					Name:               "my-instance",
+					Type:               bigquery.SourceType,
+					Project:            "my-project",
+					Location:           "us",
+					AllowedConnections: []string{"us.my_connection"},
+				},
+			},
+		},
 		{
 			desc: "with service account impersonation example",
 			in: `
@@ -135,6 +179,27 @@ func TestParseFromYamlBigQuery(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "with service account impersonation delegates example",
+			in: `
+			kind: sources
+			name: my-instance
+			type: bigquery
+			project: my-project
+			impersonateServiceAccount: service-account@my-project.iam.gserviceaccount.com
+			impersonateDelegates:
+			  - delegate@my-project.iam.gserviceaccount.com
+			`,
+			want: map[string]sources.SourceConfig{
+				"my-instance": bigquery.Config{
+					Name:                      "my-instance",
+					Type:                      bigquery.SourceType,
+					Project:                   "my-project",
+					ImpersonateServiceAccount: "service-account@my-project.iam.gserviceaccount.com",
+					ImpersonateDelegates:      []string{"delegate@my-project.iam.gserviceaccount.com"},
+				},
+			},
+		},
 		{
 			desc: "with custom scopes example",
 			in: `
@@ -177,6 +242,44 @@ func TestParseFromYamlBigQuery(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "with storage read api example",
+			in: `
+			kind: sources
+			name: my-instance
+			type: bigquery
+			project: my-project
+			useStorageReadApi: true
+			`,
+			want: map[string]sources.SourceConfig{
+				"my-instance": bigquery.Config{
+					Name:              "my-instance",
+					Type:              bigquery.SourceType,
+					Project:           "my-project",
+					UseStorageReadAPI: true,
+				},
+			},
+		},
+		{
+			desc: "with sql redaction",
+			in: `
+			kind: sources
+			name: my-instance
+			type: bigquery
+			project: my-project
+			redactSql: true
+			logUnredactedSql: true
+			`,
+			want: map[string]sources.SourceConfig{
+				"my-instance": bigquery.Config{
+					Name:             "my-instance",
+					Type:             bigquery.SourceType,
+					Project:          "my-project",
+					RedactSQL:        true,
+					LogUnredactedSQL: true,
+				},
+			},
+		},
 	}
 	for _, tc := range tcs {
 		t.Run(tc.desc, func(t *testing.T) {
@@ -287,6 +390,129 @@ func TestInitialize_MaxQueryResultRows(t *testing.T) {
 	}
 }
 
+func TestInitialize_ClientAuthorizationMode(t *testing.T) {
+	ctx, err := testutils.ContextWithNewLogger()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ctx = util.WithUserAgent(ctx, "test-agent")
+	tracer := noop.NewTracerProvider().Tracer("")
+
+	tcs := []struct {
+		desc    string
+		cfg     bigquery.Config
+		want    string
+		wantErr bool
+	}{
+		{
+			desc: "useClientOAuth",
+			cfg:  bigquery.Config{UseClientOAuth: true},
+			want: bigquery.ClientAuthorizationRequired,
+		},
+		{
+			desc: "required",
+			cfg:  bigquery.Config{ClientAuthorizationMode: bigquery.ClientAuthorizationRequired},
+			want: bigquery.ClientAuthorizationRequired,
+		},
+		{
+			desc:    "useClientOAuth with preferred",
+			cfg:     bigquery.Config{UseClientOAuth: true, ClientAuthorizationMode: bigquery.ClientAuthorizationPreferred},
+			wantErr: true,
+		},
+		{
+			desc:    "invalid mode",
+			cfg:     bigquery.Config{ClientAuthorizationMode: "sometimes"},
+			wantErr: true,
+		},
+		{
+			desc:    "preferred with protected write mode",
+			cfg:     bigquery.Config{ClientAuthorizationMode: bigquery.ClientAuthorizationPreferred, WriteMode: bigquery.WriteModeProtected},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			tc.cfg.Name, tc.cfg.Type, tc.cfg.Project = "my-instance", bigquery.SourceType, "test-project"
+			src, err := tc.cfg.Initialize(ctx, tracer)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected Initialize to fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Initialize failed: %v", err)
+			}
+			bqSrc := src.(*bigquery.Source)
+			if got := bqSrc.ClientAuthorizationMode(); got != tc.want {
+				t.Errorf("ClientAuthorizationMode() = %q, want %q", got, tc.want)
+			}
+			if got, want := bqSrc.UseClientAuthorization(), tc.want == bigquery.ClientAuthorizationRequired; got != want {
+				t.Errorf("UseClientAuthorization() = %t, want %t", got, want)
+			}
+		})
+	}
+}
+
+func TestInitialize_RedactSQL(t *testing.T) {
+	ctx, err := testutils.ContextWithNewLogger()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ctx = util.WithUserAgent(ctx, "test-agent")
+	tracer := noop.NewTracerProvider().Tracer("")
+
+	cfg := bigquery.Config{Name: "my-instance", Type: bigquery.SourceType, Project: "test-project", UseClientOAuth: true, RedactSQL: true, LogUnredactedSQL: true}
+	src, err := cfg.Initialize(ctx, tracer)
+	if err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	bqSrc := src.(*bigquery.Source)
+	if !bqSrc.BigQueryRedactSQL() || !bqSrc.BigQueryLogUnredactedSQL() {
+		t.Errorf("expected SQL redaction with unredacted logs to be enabled")
+	}
+
+	cfg.RedactSQL = false
+	if _, err := cfg.Initialize(ctx, tracer); err == nil || !strings.Contains(err.Error(), "logUnredactedSql requires redactSql") {
+		t.Fatalf("expected logUnredactedSql without redactSql to fail, got %v", err)
+	}
+}
+
+func TestClientToken(t *testing.T) {
+	tcs := []struct {
+		desc    string
+		mode    string
+		token   tools.AccessToken
+		want    string
+		wantErr bool
+	}{
+		{desc: "required with token", mode: bigquery.ClientAuthorizationRequired, token: "Bearer abc", want: "abc"},
+		{desc: "required without token", mode: bigquery.ClientAuthorizationRequired, token: "", wantErr: true},
+		{desc: "required with invalid token", mode: bigquery.ClientAuthorizationRequired, token: "abc", wantErr: true},
+		{desc: "preferred with token", mode: bigquery.ClientAuthorizationPreferred, token: "Bearer abc", want: "abc"},
+		{desc: "preferred without token", mode: bigquery.ClientAuthorizationPreferred, token: "", want: ""},
+		{desc: "preferred with invalid token", mode: bigquery.ClientAuthorizationPreferred, token: "abc", want: ""},
+		{desc: "disabled with token", mode: bigquery.ClientAuthorizationDisabled, token: "Bearer abc", want: ""},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := bigquery.ClientToken(tc.mode, tc.token)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != tc.want {
+				t.Errorf("got token %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
 func TestNormalizeValue(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,229 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/googleapis/genai-toolbox/internal/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// defaultChatRateLimitMaxWait is how long a chat request waits for the rate
+// limiter, if the limit does not set maxWait.
+const defaultChatRateLimitMaxWait = 10 * time.Second
+
+// chatRateLimitQueuedName is the name of the metric of the chat requests
+// waiting for a rate limiter, by "limiter".
+const chatRateLimitQueuedName = "toolbox.server.bigquery.chat.ratelimit.queued"
+
+// chatRateLimitRejectedName is the name of the metric counting the chat
+// requests rejected by a rate limiter, by "limiter".
+const chatRateLimitRejectedName = "toolbox.server.bigquery.chat.ratelimit.rejected"
+
+// ChatRateLimitConfig limits the requests sent to the Conversational
+// Analytics chat API, whose per-project quotas are low.
+type ChatRateLimitConfig struct {
+	// RequestsPerMinute is the sustained rate of requests.
+	RequestsPerMinute int `yaml:"requestsPerMinute"`
+	// Burst is the number of requests that can be sent at once after a quiet
+	// period. Defaults to 1.
+	Burst int `yaml:"burst"`
+	// MaxWait is how long a request waits for its turn before it is
+	// rejected, e.g. "10s". Defaults to 10s.
+	MaxWait string `yaml:"maxWait"`
+}
+
+// NewLimiter returns the limiter enforcing c, whose metrics are recorded
+// under name.
+func (c ChatRateLimitConfig) NewLimiter(name string) (*ChatRateLimiter, error) {
+	if c.RequestsPerMinute <= 0 {
+		return nil, fmt.Errorf("invalid requestsPerMinute %d: must be positive", c.RequestsPerMinute)
+	}
+	burst := c.Burst
+	if burst < 0 {
+		return nil, fmt.Errorf("invalid burst %d: must not be negative", c.Burst)
+	}
+	if burst == 0 {
+		burst = 1
+	}
+	maxWait := defaultChatRateLimitMaxWait
+	if c.MaxWait != "" {
+		d, err := time.ParseDuration(c.MaxWait)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maxWait %q: %w", c.MaxWait, err)
+		}
+		if d < 0 {
+			return nil, fmt.Errorf("invalid maxWait %q: must not be negative", c.MaxWait)
+		}
+		maxWait = d
+	}
+	return newChatRateLimiter(name, c.RequestsPerMinute, burst, maxWait), nil
+}
+
+// ChatRateLimiter returns the limiter of the chat requests of the tools of the
+// source, or nil if the source does not configure a chatRateLimit.
+func (s *Source) ChatRateLimiter() *ChatRateLimiter {
+	return s.chatRateLimiter
+}
+
+// ErrChatRateLimited is the error of a chat request that would have waited
+// longer than the maxWait of its rate limiter.
+var ErrChatRateLimited = errors.New("chat rate limit exceeded")
+
+// ChatRateLimitError is returned by ChatRateLimiter.Wait when a request is
+// rejected. It wraps ErrChatRateLimited.
+type ChatRateLimitError struct {
+	// RetryAfter is how long until the request would be let through.
+	RetryAfter time.Duration
+}
+
+func (e *ChatRateLimitError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrChatRateLimited, e.RetryAfter.Round(time.Millisecond))
+}
+
+func (e *ChatRateLimitError) Unwrap() error { return ErrChatRateLimited }
+
+// ChatRateLimiter is a token bucket shared by the chat requests of a source or
+// of a tool. Requests queue for a token for at most maxWait, and are rejected
+// with a ChatRateLimitError if their turn comes later.
+//
+// Throttle lets the server's Retry-After pause the limiter, so that queued
+// requests wait for the server instead of being sent to fail. A request that
+// is retried after a throttled response does not take another token, so it
+// is not delayed twice.
+type ChatRateLimiter struct {
+	name     string
+	interval time.Duration
+	burst    int
+	maxWait  time.Duration
+	now      func() time.Time
+
+	mu sync.Mutex
+	// next is the earliest time the next token is available. It is up to
+	// burst intervals in the past when tokens are available.
+	next time.Time
+	// pausedUntil is when a Retry-After received with Throttle ends.
+	pausedUntil time.Time
+}
+
+func newChatRateLimiter(name string, requestsPerMinute, burst int, maxWait time.Duration) *ChatRateLimiter {
+	return &ChatRateLimiter{
+		name:     name,
+		interval: time.Minute / time.Duration(requestsPerMinute),
+		burst:    burst,
+		maxWait:  maxWait,
+		now:      time.Now,
+	}
+}
+
+// reserve takes a token for a request at now, and returns how long the
+// request must wait before it is sent. If the wait exceeds maxWait, no token
+// is taken and it returns false.
+func (l *ChatRateLimiter) reserve(now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	next := l.next
+	if earliest := now.Add(-time.Duration(l.burst-1) * l.interval); next.Before(earliest) {
+		next = earliest
+	}
+	at := next
+	if at.Before(l.pausedUntil) {
+		at = l.pausedUntil
+	}
+	wait := max(at.Sub(now), 0)
+	if wait > l.maxWait {
+		return wait, false
+	}
+	// After a pause, the requests queued for it are spread out again.
+	l.next = at.Add(l.interval)
+	return wait, true
+}
+
+// cancel returns the token taken by a request that did not wait for its
+// turn.
+func (l *ChatRateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.next = l.next.Add(-l.interval)
+}
+
+// Wait blocks until a chat request can be sent. It returns a
+// ChatRateLimitError without waiting if the request would wait longer than
+// maxWait, or the error of ctx if it is done first.
+func (l *ChatRateLimiter) Wait(ctx context.Context) error {
+	attrs := metric.WithAttributes(attribute.String("limiter", l.name))
+	wait, ok := l.reserve(l.now())
+	if !ok {
+		if m := chatRateLimitMetrics(); m.rejected != nil {
+			m.rejected.Add(ctx, 1, attrs)
+		}
+		return &ChatRateLimitError{RetryAfter: wait}
+	}
+	if wait <= 0 {
+		return nil
+	}
+
+	m := chatRateLimitMetrics()
+	if m.queued != nil {
+		m.queued.Add(ctx, 1, attrs)
+		defer m.queued.Add(context.WithoutCancel(ctx), -1, attrs)
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		l.cancel()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Throttle pauses the limiter for d, following a Retry-After of the server.
+func (l *ChatRateLimiter) Throttle(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := l.now().Add(d); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+}
+
+type chatRateLimitInstruments struct {
+	queued   metric.Int64UpDownCounter
+	rejected metric.Int64Counter
+}
+
+// chatRateLimitMetrics returns the metrics of the chat rate limiters, created
+// on first use from the global meter provider. Instruments that cannot be
+// created are nil.
+var chatRateLimitMetrics = sync.OnceValue(func() chatRateLimitInstruments {
+	var m chatRateLimitInstruments
+	meter := telemetry.Meter()
+	m.queued, _ = meter.Int64UpDownCounter(
+		chatRateLimitQueuedName,
+		metric.WithDescription("Number of Conversational Analytics chat requests waiting for a rate limiter."),
+	)
+	m.rejected, _ = meter.Int64Counter(
+		chatRateLimitRejectedName,
+		metric.WithDescription("Number of Conversational Analytics chat requests rejected by a rate limiter."),
+	)
+	return m
+})
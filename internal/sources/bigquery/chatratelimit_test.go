@@ -0,0 +1,138 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestChatRateLimitConfigNewLimiter(t *testing.T) {
+	for _, cfg := range []ChatRateLimitConfig{
+		{},
+		{RequestsPerMinute: -1},
+		{RequestsPerMinute: 10, Burst: -1},
+		{RequestsPerMinute: 10, MaxWait: "soon"},
+		{RequestsPerMinute: 10, MaxWait: "-1s"},
+	} {
+		if _, err := cfg.NewLimiter("test"); err == nil {
+			t.Errorf("expected an error for %+v", cfg)
+		}
+	}
+
+	l, err := ChatRateLimitConfig{RequestsPerMinute: 30}.NewLimiter("test")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if l.interval != 2*time.Second || l.burst != 1 || l.maxWait != defaultChatRateLimitMaxWait {
+		t.Errorf("unexpected defaults: interval %s, burst %d, maxWait %s", l.interval, l.burst, l.maxWait)
+	}
+}
+
+func TestChatRateLimiterReserve(t *testing.T) {
+	// one request per second, with a burst of 2 and up to 2s of queueing
+	l := newChatRateLimiter("test", 60, 2, 2*time.Second)
+	now := time.Unix(1000, 0)
+
+	want := []struct {
+		wait time.Duration
+		ok   bool
+	}{
+		// the burst is let through at once
+		{0, true},
+		{0, true},
+		// then requests queue for their turn
+		{time.Second, true},
+		{2 * time.Second, true},
+		// until they would wait longer than maxWait
+		{3 * time.Second, false},
+		{3 * time.Second, false},
+	}
+	for i, w := range want {
+		wait, ok := l.reserve(now)
+		if wait != w.wait || ok != w.ok {
+			t.Errorf("request %d: got (%s, %t), want (%s, %t)", i, wait, ok, w.wait, w.ok)
+		}
+	}
+
+	// rejected requests take no token, so the limiter refills at its rate
+	now = now.Add(4 * time.Second)
+	if wait, ok := l.reserve(now); wait != 0 || !ok {
+		t.Errorf("got (%s, %t) after the queue drained, want a token", wait, ok)
+	}
+}
+
+func TestChatRateLimiterThrottle(t *testing.T) {
+	l := newChatRateLimiter("test", 60, 5, 10*time.Second)
+	now := time.Unix(1000, 0)
+	l.now = func() time.Time { return now }
+
+	// a Retry-After of the API pauses the limiter, whatever its tokens
+	l.Throttle(3 * time.Second)
+	if wait, ok := l.reserve(now); wait != 3*time.Second || !ok {
+		t.Errorf("got (%s, %t), want to wait for the Retry-After", wait, ok)
+	}
+	// and the requests queued for it are spread out again
+	if wait, ok := l.reserve(now); wait != 4*time.Second || !ok {
+		t.Errorf("got (%s, %t), want to wait an interval after the Retry-After", wait, ok)
+	}
+	// a shorter Retry-After does not end the pause early
+	l.Throttle(time.Second)
+	if l.pausedUntil != now.Add(3*time.Second) {
+		t.Errorf("got pause until %s, want the longest Retry-After", l.pausedUntil)
+	}
+}
+
+func TestChatRateLimiterWait(t *testing.T) {
+	// one request per 100ms, with a burst of 2 and up to 150ms of queueing
+	l := newChatRateLimiter("test", 600, 2, 150*time.Millisecond)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatalf("request %d of the burst: unexpected error: %s", i, err)
+		}
+	}
+
+	queued := make(chan time.Duration)
+	go func() {
+		start := time.Now()
+		if err := l.Wait(ctx); err != nil {
+			t.Errorf("queued request: unexpected error: %s", err)
+		}
+		queued <- time.Since(start)
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	err := l.Wait(ctx)
+	var rateErr *ChatRateLimitError
+	if !errors.As(err, &rateErr) || !errors.Is(err, ErrChatRateLimited) {
+		t.Fatalf("expected the request behind the queue to be rejected, got %v", err)
+	}
+	if rateErr.RetryAfter <= 150*time.Millisecond || rateErr.RetryAfter > 200*time.Millisecond {
+		t.Errorf("got RetryAfter %s, want the time until the next free token", rateErr.RetryAfter)
+	}
+	if waited := <-queued; waited < 50*time.Millisecond {
+		t.Errorf("expected the request after the burst to queue, it waited %s", waited)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := l.Wait(canceled); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a canceled wait to return the context's error, got %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	bigqueryapi "cloud.google.com/go/bigquery"
+	"golang.org/x/oauth2"
+	bigqueryrestapi "google.golang.org/api/bigquery/v2"
+)
+
+func TestADCClientsAreShared(t *testing.T) {
+	var calls atomic.Int32
+	s := &Source{Config: Config{ClientAuthorizationMode: ClientAuthorizationPreferred}}
+	s.makeADCClients = func() (*bigqueryapi.Client, *bigqueryrestapi.Service, oauth2.TokenSource, error) {
+		if calls.Add(1) == 1 {
+			return nil, nil, nil, fmt.Errorf("credentials are not available yet")
+		}
+		return &bigqueryapi.Client{}, &bigqueryrestapi.Service{}, oauth2.StaticTokenSource(&oauth2.Token{}), nil
+	}
+
+	// a failure to create the clients is returned, and retried on next use
+	if _, _, err := s.RetrieveClientAndService(context.Background(), ""); err == nil {
+		t.Fatalf("expected the error creating the clients")
+	}
+
+	var wg sync.WaitGroup
+	clients := make([]*bigqueryapi.Client, 10)
+	restServices := make([]*bigqueryrestapi.Service, 10)
+	for i := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client, restService, err := s.RetrieveClientAndService(context.Background(), "")
+			if err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+			clients[i], restServices[i] = client, restService
+		}()
+	}
+	wg.Wait()
+
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("expected the clients to be created once after the failure, got %d calls", got)
+	}
+	for i := range clients {
+		if clients[i] == nil || clients[i] != s.BigQueryClient() {
+			t.Errorf("invocation %d got client %p, want the shared client %p", i, clients[i], s.BigQueryClient())
+		}
+		if restServices[i] == nil || restServices[i] != s.BigQueryRestService() {
+			t.Errorf("invocation %d got REST service %p, want the shared service %p", i, restServices[i], s.BigQueryRestService())
+		}
+	}
+	if s.BigQueryTokenSource() == nil {
+		t.Errorf("expected the token source of the ADC credentials")
+	}
+}
+
+func TestTokenClientCacheEvictsExpiredEntries(t *testing.T) {
+	defer func(ttl time.Duration) { tokenClientTTL = ttl }(tokenClientTTL)
+	tokenClientTTL = 50 * time.Millisecond
+
+	created := map[string]int{}
+	s := &Source{Config: Config{ClientAuthorizationMode: ClientAuthorizationRequired}}
+	setupClientCaching(s, func(tokenString string, wantRestService bool) (*bigqueryapi.Client, *bigqueryrestapi.Service, error) {
+		created[tokenString]++
+		return nil, &bigqueryrestapi.Service{}, nil
+	})
+
+	ctx := context.Background()
+	_, first, err := s.RetrieveClientAndService(ctx, "Bearer token-a")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_, second, err := s.RetrieveClientAndService(ctx, "Bearer token-a")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if first != second || created["token-a"] != 1 {
+		t.Fatalf("expected the clients of a token to be cached, created %d times", created["token-a"])
+	}
+	if _, err := s.BigQueryRestServiceFor(ctx, "Bearer token-b"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if created["token-b"] != 1 {
+		t.Fatalf("expected the clients of another token to be created, created %d times", created["token-b"])
+	}
+
+	// the caches are keyed by a hash of the token, not the token itself
+	if _, ok := s.bqRestCache.Get("token-a"); ok {
+		t.Errorf("expected the cache not to be keyed by the token")
+	}
+	if _, ok := s.bqRestCache.Get(tokenCacheKey("token-a")); !ok {
+		t.Errorf("expected the cache to be keyed by the hash of the token")
+	}
+
+	time.Sleep(2 * tokenClientTTL)
+	if _, ok := s.bqRestCache.Get(tokenCacheKey("token-a")); ok {
+		t.Errorf("expected the expired entry to be evicted")
+	}
+	_, third, err := s.RetrieveClientAndService(ctx, "Bearer token-a")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if third == first || created["token-a"] != 2 {
+		t.Fatalf("expected the clients of an expired token to be created again, created %d times", created["token-a"])
+	}
+}
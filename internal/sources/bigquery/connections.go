@@ -0,0 +1,95 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// NormalizeConnectionID returns the ID of a BigQuery connection as
+// "project.location.connection", with a lowercase location. id is either a
+// resource name ("projects/p/locations/l/connections/c"), or a dotted ID
+// whose project and location default to defaultProject and defaultLocation.
+func NormalizeConnectionID(id, defaultProject, defaultLocation string) (string, error) {
+	var project, location, connection string
+	if rest, ok := strings.CutPrefix(id, "projects/"); ok {
+		parts := strings.Split(rest, "/")
+		if len(parts) != 5 || parts[1] != "locations" || parts[3] != "connections" {
+			return "", fmt.Errorf("invalid connection %q, expected 'projects/PROJECT/locations/LOCATION/connections/CONNECTION'", id)
+		}
+		project, location, connection = parts[0], parts[2], parts[4]
+	} else {
+		parts := strings.Split(id, ".")
+		switch len(parts) {
+		case 3:
+			project, location, connection = parts[0], parts[1], parts[2]
+		case 2:
+			project, location, connection = defaultProject, parts[0], parts[1]
+		case 1:
+			project, location, connection = defaultProject, defaultLocation, parts[0]
+		default:
+			return "", fmt.Errorf("invalid connection %q, expected 'project.location.connection' or 'location.connection'", id)
+		}
+	}
+	if project == "" || location == "" || connection == "" {
+		return "", fmt.Errorf("invalid connection %q, its project, location and name must not be empty", id)
+	}
+	return fmt.Sprintf("%s.%s.%s", project, strings.ToLower(location), connection), nil
+}
+
+// normalizeAllowedConnections returns the set of the normalized IDs of the
+// allowedConnections of the source configured by r.
+func normalizeAllowedConnections(r Config) (map[string]struct{}, error) {
+	allowed := make(map[string]struct{})
+	for _, c := range r.AllowedConnections {
+		id, err := NormalizeConnectionID(strings.TrimSpace(c), r.Project, r.Location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowedConnections: %w", err)
+		}
+		allowed[id] = struct{}{}
+	}
+	return allowed, nil
+}
+
+// BigQueryAllowedConnections returns the normalized IDs of the connections
+// that queries are allowed to use, sorted.
+func (s *Source) BigQueryAllowedConnections() []string {
+	connections := make([]string, 0, len(s.AllowedConnections))
+	for c := range s.AllowedConnections {
+		connections = append(connections, c)
+	}
+	slices.Sort(connections)
+	return connections
+}
+
+// BigQueryRestrictsConnections returns whether queries may only use the
+// allowed connections. Connections are restricted if allowedConnections or
+// allowedDatasets is set, as external connections reach data outside of the
+// allowed datasets.
+func (s *Source) BigQueryRestrictsConnections() bool {
+	return len(s.AllowedConnections) > 0 || len(s.AllowedDatasets) > 0
+}
+
+// IsConnectionAllowed returns whether queries may use the connection with the
+// normalized ID id.
+func (s *Source) IsConnectionAllowed(id string) bool {
+	if !s.BigQueryRestrictsConnections() {
+		return true
+	}
+	_, ok := s.AllowedConnections[id]
+	return ok
+}
@@ -0,0 +1,87 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/sources/bigquery"
+	"github.com/googleapis/genai-toolbox/internal/testutils"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestNormalizeConnectionID(t *testing.T) {
+	tcs := []struct {
+		id      string
+		want    string
+		wantErr string
+	}{
+		{id: "proj.US.conn", want: "proj.us.conn"},
+		{id: "eu.conn", want: "default-proj.eu.conn"},
+		{id: "conn", want: "default-proj.us.conn"},
+		{id: "projects/proj/locations/us-central1/connections/conn", want: "proj.us-central1.conn"},
+		{id: "projects/proj/connections/conn", wantErr: "expected 'projects/PROJECT/locations/LOCATION/connections/CONNECTION'"},
+		{id: "a.b.c.d", wantErr: "expected 'project.location.connection' or 'location.connection'"},
+		{id: "proj..conn", wantErr: "must not be empty"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.id, func(t *testing.T) {
+			got, err := bigquery.NormalizeConnectionID(tc.id, "default-proj", "US")
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != tc.want {
+				t.Fatalf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestInitialize_AllowedConnections(t *testing.T) {
+	ctx, err := testutils.ContextWithNewLogger()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ctx = util.WithUserAgent(ctx, "test-agent")
+	tracer := noop.NewTracerProvider().Tracer("")
+
+	cfg := bigquery.Config{Name: "my-instance", Type: bigquery.SourceType, Project: "test-project", Location: "EU", UseClientOAuth: true, AllowedConnections: []string{"sql_conn", "other-project.us.spanner_conn"}}
+	src, err := cfg.Initialize(ctx, tracer)
+	if err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	bqSrc := src.(*bigquery.Source)
+	want := []string{"other-project.us.spanner_conn", "test-project.eu.sql_conn"}
+	if diff := cmp.Diff(want, bqSrc.BigQueryAllowedConnections()); diff != "" {
+		t.Fatalf("unexpected allowed connections (-want +got):\n%s", diff)
+	}
+	if !bqSrc.BigQueryRestrictsConnections() || !bqSrc.IsConnectionAllowed("test-project.eu.sql_conn") || bqSrc.IsConnectionAllowed("test-project.eu.other_conn") {
+		t.Fatalf("expected only the allowed connections to be allowed")
+	}
+
+	cfg.AllowedConnections = []string{"a.b.c.d"}
+	if _, err := cfg.Initialize(ctx, tracer); err == nil || !strings.Contains(err.Error(), "invalid allowedConnections") {
+		t.Fatalf("expected invalid allowedConnections to fail, got %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	"github.com/googleapis/genai-toolbox/internal/tools/googlehttp"
+	"golang.org/x/oauth2"
+)
+
+// caBaseURL is the endpoint of the Conversational Analytics API, whose
+// reachability the health check verifies.
+var caBaseURL = "https://geminidataanalytics.googleapis.com/v1beta"
+
+var _ sources.HealthChecker = (*Source)(nil)
+
+// HealthCheck checks that the source obtains a token with its credentials,
+// reaches the BigQuery API, by looking up one of its allowed datasets, or the
+// datasets of its project if it has no allowed datasets, and reaches the
+// Conversational Analytics API. Sources that require client authorization
+// have no credentials of their own, and are healthy.
+func (s *Source) HealthCheck(ctx context.Context) error {
+	if s.UseClientAuthorization() {
+		return nil
+	}
+	ts, err := s.BigQueryTokenSourceWithScope(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create the token source: %w", err)
+	}
+	if _, err := ts.Token(); err != nil {
+		return fmt.Errorf("failed to obtain a token: %w", err)
+	}
+	_, restService, err := s.adcClients()
+	if err != nil {
+		return err
+	}
+	if datasets := s.BigQueryAllowedDatasets(); len(datasets) > 0 {
+		projectID, datasetID, _ := strings.Cut(slices.Min(datasets), ".")
+		if _, err := restService.Datasets.Get(projectID, datasetID).Context(ctx).Fields("id").Do(); err != nil {
+			return fmt.Errorf("failed to reach the BigQuery API: %w", err)
+		}
+	} else if _, err := restService.Datasets.List(s.Project).MaxResults(1).Context(ctx).Fields("datasets/id").Do(); err != nil {
+		return fmt.Errorf("failed to reach the BigQuery API: %w", err)
+	}
+	return s.checkCAEndpoint(ctx, ts)
+}
+
+// checkCAEndpoint checks that the Conversational Analytics API is reachable,
+// by listing one data agent of the project of the source. The source's
+// credentials may not be allowed to list data agents, which does not make
+// the API unreachable, so only transport errors and server errors fail.
+func (s *Source) checkCAEndpoint(ctx context.Context, ts oauth2.TokenSource) error {
+	location := s.Location
+	if location == "" {
+		location = "global"
+	}
+	client := googlehttp.Client{Token: googlehttp.FromTokenSource(ts)}
+	err := client.DoJSON(ctx, http.MethodGet, fmt.Sprintf("%s/projects/%s/locations/%s/dataAgents?pageSize=1", caBaseURL, s.Project, location), nil, nil)
+	var apiErr *googlehttp.APIError
+	if err == nil || (errors.As(err, &apiErr) && apiErr.StatusCode < http.StatusInternalServerError) {
+		return nil
+	}
+	return fmt.Errorf("failed to reach the Conversational Analytics API: %w", err)
+}
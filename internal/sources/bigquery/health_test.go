@@ -0,0 +1,139 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	bigqueryapi "cloud.google.com/go/bigquery"
+	"golang.org/x/oauth2"
+	bigqueryrestapi "google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/option"
+)
+
+type failingTokenSource struct{}
+
+func (failingTokenSource) Token() (*oauth2.Token, error) {
+	return nil, errors.New("no credentials")
+}
+
+func TestHealthCheck(t *testing.T) {
+	var paths []string
+	caStatus := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/projects/my-project/datasets", "/projects/my-project/datasets/sales":
+			fmt.Fprint(w, `{}`)
+		case "/ca/projects/my-project/locations/global/dataAgents":
+			w.WriteHeader(caStatus)
+			fmt.Fprint(w, `{}`)
+		default:
+			http.Error(w, `{"error": {"code": 404, "message": "not found"}}`, http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	defer func(url string) { caBaseURL = url }(caBaseURL)
+	caBaseURL = server.URL + "/ca"
+	restService, err := bigqueryrestapi.NewService(context.Background(), option.WithEndpoint(server.URL), option.WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatalf("unable to create REST service: %s", err)
+	}
+	newSource := func(cfg Config, ts oauth2.TokenSource) *Source {
+		s := &Source{Config: cfg}
+		s.makeTokenSource = func(ctx context.Context, scopes []string) (oauth2.TokenSource, error) {
+			return ts, nil
+		}
+		s.makeADCClients = func() (*bigqueryapi.Client, *bigqueryrestapi.Service, oauth2.TokenSource, error) {
+			return &bigqueryapi.Client{}, restService, ts, nil
+		}
+		return s
+	}
+	token := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"})
+
+	const caPath = "/ca/projects/my-project/locations/global/dataAgents"
+	tcs := []struct {
+		desc      string
+		cfg       Config
+		ts        oauth2.TokenSource
+		caStatus  int
+		wantPaths []string
+		wantErr   string
+	}{
+		{desc: "healthy", cfg: Config{Project: "my-project"}, ts: token, wantPaths: []string{"/projects/my-project/datasets", caPath}},
+		{
+			desc:      "healthy with allowed datasets",
+			cfg:       Config{Project: "my-project", AllowedDatasets: []string{"my-project.sales"}},
+			ts:        token,
+			wantPaths: []string{"/projects/my-project/datasets/sales", caPath},
+		},
+		{desc: "token failure", cfg: Config{Project: "my-project"}, ts: failingTokenSource{}, wantErr: "failed to obtain a token"},
+		{
+			desc:      "unreachable dataset",
+			cfg:       Config{Project: "my-project", AllowedDatasets: []string{"my-project.gone"}},
+			ts:        token,
+			wantPaths: []string{"/projects/my-project/datasets/gone"},
+			wantErr:   "failed to reach the BigQuery API",
+		},
+		{
+			desc:      "data agents not allowed",
+			cfg:       Config{Project: "my-project"},
+			ts:        token,
+			caStatus:  http.StatusForbidden,
+			wantPaths: []string{"/projects/my-project/datasets", caPath},
+		},
+		{
+			desc:      "unavailable Conversational Analytics API",
+			cfg:       Config{Project: "my-project"},
+			ts:        token,
+			caStatus:  http.StatusServiceUnavailable,
+			wantPaths: []string{"/projects/my-project/datasets", caPath},
+			wantErr:   "failed to reach the Conversational Analytics API",
+		},
+		{desc: "client authorization", cfg: Config{Project: "my-project", UseClientOAuth: true}, ts: failingTokenSource{}},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			paths = nil
+			caStatus = http.StatusOK
+			if tc.caStatus != 0 {
+				caStatus = tc.caStatus
+			}
+			s := newSource(tc.cfg, tc.ts)
+			s.AllowedDatasets = make(map[string]struct{})
+			for _, d := range tc.cfg.AllowedDatasets {
+				s.AllowedDatasets[d] = struct{}{}
+			}
+			err := s.HealthCheck(context.Background())
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("got error %v, want %q", err, tc.wantErr)
+			}
+			if !slices.Equal(paths, tc.wantPaths) {
+				t.Errorf("got API calls %v, want %v", paths, tc.wantPaths)
+			}
+		})
+	}
+}
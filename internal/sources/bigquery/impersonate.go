@@ -0,0 +1,80 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/impersonate"
+	"google.golang.org/api/option"
+)
+
+// tokenCreatorRole is the role a principal needs on a service account to
+// impersonate it.
+const tokenCreatorRole = "roles/iam.serviceAccountTokenCreator"
+
+// newImpersonatedTokenSource returns a token source of the service account
+// target with the given scopes, impersonated through the chain of delegates,
+// if any. Failures to get a token name the service account and what the
+// credentials are missing to impersonate it.
+func newImpersonatedTokenSource(ctx context.Context, target string, delegates, scopes []string, opts ...option.ClientOption) (oauth2.TokenSource, error) {
+	ts, err := impersonate.CredentialsTokenSource(ctx, impersonate.CredentialsConfig{
+		TargetPrincipal: target,
+		Scopes:          scopes,
+		Delegates:       delegates,
+	}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create impersonated credentials for %q with scopes %v: %w", target, scopes, err)
+	}
+	return &impersonatedTokenSource{ts: ts, target: target, delegates: delegates}, nil
+}
+
+// impersonatedTokenSource translates the errors of the IAM Service Account
+// Credentials API into actionable ones.
+type impersonatedTokenSource struct {
+	ts        oauth2.TokenSource
+	target    string
+	delegates []string
+}
+
+func (i *impersonatedTokenSource) Token() (*oauth2.Token, error) {
+	tok, err := i.ts.Token()
+	if err != nil {
+		return nil, impersonationError(i.target, i.delegates, err)
+	}
+	return tok, nil
+}
+
+// impersonationError returns the error of a failure to get a token of the
+// service account target through the chain of delegates.
+func impersonationError(target string, delegates []string, err error) error {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "SERVICE_DISABLED"):
+		return fmt.Errorf("failed to impersonate service account %q: the IAM Service Account Credentials API (iamcredentials.googleapis.com) is not enabled in the project of the credentials: %w", target, err)
+	case strings.Contains(msg, "status code 403"):
+		if len(delegates) == 0 {
+			return fmt.Errorf("failed to impersonate service account %q: the credentials of the server must have the %q role on it: %w", target, tokenCreatorRole, err)
+		}
+		chain := append(append([]string{"the credentials of the server"}, delegates...), target)
+		return fmt.Errorf("failed to impersonate service account %q: each principal of the delegation chain %s must have the %q role on the next one: %w", target, strings.Join(chain, " -> "), tokenCreatorRole, err)
+	case strings.Contains(msg, "status code 404"):
+		return fmt.Errorf("failed to impersonate service account %q: the service account does not exist: %w", target, err)
+	}
+	return fmt.Errorf("failed to impersonate service account %q: %w", target, err)
+}
@@ -0,0 +1,144 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/api/option"
+)
+
+// rewriteTransport sends all requests to the server at target.
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (r rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = r.target.Scheme, r.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// fakeIAMCredentials starts a fake IAM Service Account Credentials API that
+// records the requests to generate access tokens, and fails with status if
+// it is not http.StatusOK.
+func fakeIAMCredentials(t *testing.T, status int) (*http.Client, *[]string, *map[string]any) {
+	var paths []string
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("unable to decode request: %s", err)
+		}
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"error": {"status": "PERMISSION_DENIED"}}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"accessToken": "impersonated-token",
+			"expireTime":  time.Now().Add(time.Hour).Format(time.RFC3339),
+		})
+	}))
+	t.Cleanup(server.Close)
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return &http.Client{Transport: rewriteTransport{target: target}}, &paths, &body
+}
+
+func TestImpersonatedTokenSource(t *testing.T) {
+	client, paths, body := fakeIAMCredentials(t, http.StatusOK)
+	target := "target@my-project.iam.gserviceaccount.com"
+	delegates := []string{"delegate@my-project.iam.gserviceaccount.com"}
+
+	ts, err := newImpersonatedTokenSource(context.Background(), target, delegates, []string{CloudPlatformScope}, option.WithHTTPClient(client))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	tok, err := ts.Token()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if tok.AccessToken != "impersonated-token" {
+		t.Errorf("got token %q, want the impersonated token", tok.AccessToken)
+	}
+	wantPaths := []string{"/v1/projects/-/serviceAccounts/" + target + ":generateAccessToken"}
+	if diff := cmp.Diff(wantPaths, *paths); diff != "" {
+		t.Errorf("unexpected requests (-want +got):\n%s", diff)
+	}
+	wantBody := map[string]any{
+		"delegates": []any{"projects/-/serviceAccounts/" + delegates[0]},
+		"lifetime":  "3600s",
+		"scope":     []any{CloudPlatformScope},
+	}
+	if diff := cmp.Diff(wantBody, *body); diff != "" {
+		t.Errorf("unexpected request body (-want +got):\n%s", diff)
+	}
+}
+
+func TestImpersonatedTokenSourceErrors(t *testing.T) {
+	target := "target@my-project.iam.gserviceaccount.com"
+	tcs := []struct {
+		desc      string
+		status    int
+		delegates []string
+		want      []string
+	}{
+		{
+			desc:   "missing permission",
+			status: http.StatusForbidden,
+			want:   []string{target, tokenCreatorRole, "credentials of the server"},
+		},
+		{
+			desc:      "missing permission with delegates",
+			status:    http.StatusForbidden,
+			delegates: []string{"delegate@my-project.iam.gserviceaccount.com"},
+			want:      []string{tokenCreatorRole, "the credentials of the server -> delegate@my-project.iam.gserviceaccount.com -> " + target},
+		},
+		{
+			desc:   "missing service account",
+			status: http.StatusNotFound,
+			want:   []string{target, "does not exist"},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			client, _, _ := fakeIAMCredentials(t, tc.status)
+			ts, err := newImpersonatedTokenSource(context.Background(), target, tc.delegates, []string{CloudPlatformScope}, option.WithHTTPClient(client))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			_, err = ts.Token()
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+		})
+	}
+}
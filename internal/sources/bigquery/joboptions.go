@@ -0,0 +1,208 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	bigqueryapi "cloud.google.com/go/bigquery"
+	bigqueryrestapi "google.golang.org/api/bigquery/v2"
+)
+
+// JobOptions are the settings of the query jobs and dry runs of a tool.
+type JobOptions struct {
+	// Labels are added to every job.
+	Labels map[string]string
+	// MaximumBytesBilled limits the bytes billed for a query, which fails
+	// without charge if it would exceed it. Unlimited if 0.
+	MaximumBytesBilled int64
+	// UseStorageReadAPI reads large query results through the Storage Read
+	// API. The source's useStorageReadApi applies if nil.
+	UseStorageReadAPI *bool
+	// QueryLabel is set as the query_label connection property of the jobs,
+	// as "key:value", which also labels the jobs of scripts and sessions.
+	QueryLabel string
+	// DestinationTable receives the results of queries, if set.
+	DestinationTable *bigqueryrestapi.TableReference
+	// Priority is the priority of the jobs, PriorityInteractive or
+	// PriorityBatch. BigQuery runs interactive jobs if empty.
+	Priority string
+	// UseQueryCache sets whether queries may be answered from cached results.
+	// BigQuery uses the cache if nil.
+	UseQueryCache *bool
+	// VerifyStatistics, if set, is called by RunSQL and StreamSQL with the
+	// statistics of the query job once it completes, before its results are
+	// read. The query fails with its error.
+	VerifyStatistics func(ctx context.Context, stats *bigqueryapi.JobStatistics) error
+}
+
+// Priorities of query jobs.
+const (
+	PriorityInteractive = string(bigqueryapi.InteractivePriority)
+	PriorityBatch       = string(bigqueryapi.BatchPriority)
+)
+
+// queryLabelProperty is the key of the connection property of QueryLabel.
+const queryLabelProperty = "query_label"
+
+// Merge returns the options o, overridden by the options that override sets.
+// Labels are merged, with the labels of override winning for the same keys.
+// Neither o nor override is modified.
+func (o JobOptions) Merge(override JobOptions) JobOptions {
+	merged := o
+	if len(o.Labels) > 0 || len(override.Labels) > 0 {
+		merged.Labels = make(map[string]string, len(o.Labels)+len(override.Labels))
+		maps.Copy(merged.Labels, o.Labels)
+		maps.Copy(merged.Labels, override.Labels)
+	}
+	if override.MaximumBytesBilled != 0 {
+		merged.MaximumBytesBilled = override.MaximumBytesBilled
+	}
+	if override.UseStorageReadAPI != nil {
+		merged.UseStorageReadAPI = override.UseStorageReadAPI
+	}
+	if override.QueryLabel != "" {
+		merged.QueryLabel = override.QueryLabel
+	}
+	if override.DestinationTable != nil {
+		merged.DestinationTable = override.DestinationTable
+	}
+	if override.Priority != "" {
+		merged.Priority = override.Priority
+	}
+	if override.UseQueryCache != nil {
+		merged.UseQueryCache = override.UseQueryCache
+	}
+	if override.VerifyStatistics != nil {
+		merged.VerifyStatistics = override.VerifyStatistics
+	}
+	return merged
+}
+
+// storageReadAPI reports whether the options read large query results
+// through the Storage Read API.
+func (o JobOptions) storageReadAPI() bool {
+	return o.UseStorageReadAPI != nil && *o.UseStorageReadAPI
+}
+
+// ApplyToQuery sets the options on q. It must be called after the connection
+// properties of q are set, as the query label is added to them.
+func (o JobOptions) ApplyToQuery(q *bigqueryapi.Query) {
+	if len(o.Labels) > 0 {
+		q.Labels = maps.Clone(o.Labels)
+	}
+	q.MaxBytesBilled = o.MaximumBytesBilled
+	if o.QueryLabel != "" && !slices.ContainsFunc(q.ConnectionProperties, func(p *bigqueryapi.ConnectionProperty) bool { return p.Key == queryLabelProperty }) {
+		q.ConnectionProperties = append(slices.Clone(q.ConnectionProperties), &bigqueryapi.ConnectionProperty{Key: queryLabelProperty, Value: o.QueryLabel})
+	}
+	if t := o.DestinationTable; t != nil {
+		q.Dst = &bigqueryapi.Table{ProjectID: t.ProjectId, DatasetID: t.DatasetId, TableID: t.TableId}
+	}
+	if o.Priority != "" {
+		q.Priority = bigqueryapi.QueryPriority(o.Priority)
+	}
+	if o.UseQueryCache != nil {
+		q.DisableQueryCache = !*o.UseQueryCache
+	}
+}
+
+// ApplyToJob sets the options on the configuration of the query job cfg, like
+// ApplyToQuery.
+func (o JobOptions) ApplyToJob(cfg *bigqueryrestapi.JobConfiguration) {
+	if len(o.Labels) > 0 {
+		cfg.Labels = maps.Clone(o.Labels)
+	}
+	q := cfg.Query
+	if q == nil {
+		return
+	}
+	q.MaximumBytesBilled = o.MaximumBytesBilled
+	if o.QueryLabel != "" && !slices.ContainsFunc(q.ConnectionProperties, func(p *bigqueryrestapi.ConnectionProperty) bool { return p.Key == queryLabelProperty }) {
+		q.ConnectionProperties = append(slices.Clone(q.ConnectionProperties), &bigqueryrestapi.ConnectionProperty{Key: queryLabelProperty, Value: o.QueryLabel})
+	}
+	if t := o.DestinationTable; t != nil {
+		q.DestinationTable = &bigqueryrestapi.TableReference{ProjectId: t.ProjectId, DatasetId: t.DatasetId, TableId: t.TableId}
+	}
+	if o.Priority != "" {
+		q.Priority = o.Priority
+	}
+	if o.UseQueryCache != nil {
+		useQueryCache := *o.UseQueryCache
+		q.UseQueryCache = &useQueryCache
+	}
+}
+
+// BigQueryJobOptions returns the options of the jobs of a tool with the
+// options tool, which override the defaultJobLabels, maximumBytesBilled and
+// useStorageReadApi of the source.
+func (s *Source) BigQueryJobOptions(tool JobOptions) JobOptions {
+	defaults := JobOptions{Labels: s.DefaultJobLabels}
+	if s.UseStorageReadAPI {
+		defaults.UseStorageReadAPI = &s.UseStorageReadAPI
+	}
+	if s.MaximumBytesBilled != nil {
+		defaults.MaximumBytesBilled = *s.MaximumBytesBilled
+	}
+	return defaults.Merge(tool)
+}
+
+// labelKeyRegex and labelValueRegex match the keys and values of BigQuery
+// labels, see https://cloud.google.com/bigquery/docs/labels-intro#requirements.
+var (
+	labelKeyRegex   = regexp.MustCompile(`^[\p{Ll}\p{Lo}][\p{Ll}\p{Lo}\p{N}_-]{0,62}$`)
+	labelValueRegex = regexp.MustCompile(`^[\p{Ll}\p{Lo}\p{N}_-]{0,63}$`)
+)
+
+// ValidateQueryLabel returns an error if label is not a query_label of the
+// form "key:value", with a valid label key and value.
+func ValidateQueryLabel(label string) error {
+	key, value, ok := strings.Cut(label, ":")
+	if !ok || !labelKeyRegex.MatchString(key) || !labelValueRegex.MatchString(value) {
+		return fmt.Errorf("invalid query label %q: must be of the form \"key:value\", with a key starting with a lowercase letter, and a key and value containing only lowercase letters, digits, underscores and dashes", label)
+	}
+	return nil
+}
+
+// ValidatePriority returns an error if priority is not empty, PriorityInteractive
+// or PriorityBatch.
+func ValidatePriority(priority string) error {
+	if priority != "" && priority != PriorityInteractive && priority != PriorityBatch {
+		return fmt.Errorf("invalid priority %q: must be %q or %q", priority, PriorityInteractive, PriorityBatch)
+	}
+	return nil
+}
+
+// ValidateJobOptions returns an error if labels are not valid BigQuery labels,
+// or maximumBytesBilled is set and not positive. field is the name of the
+// configuration field of the labels.
+func ValidateJobOptions(field string, labels map[string]string, maximumBytesBilled *int64) error {
+	for k, v := range labels {
+		if !labelKeyRegex.MatchString(k) {
+			return fmt.Errorf("invalid label key %q in %q: keys must start with a lowercase letter, and contain only lowercase letters, digits, underscores and dashes, up to 63 characters", k, field)
+		}
+		if !labelValueRegex.MatchString(v) {
+			return fmt.Errorf("invalid value %q of label %q in %q: values must contain only lowercase letters, digits, underscores and dashes, up to 63 characters", v, k, field)
+		}
+	}
+	if maximumBytesBilled != nil && *maximumBytesBilled <= 0 {
+		return fmt.Errorf("invalid maximumBytesBilled %d: must be positive", *maximumBytesBilled)
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery_test
+
+import (
+	"testing"
+
+	bigqueryapi "cloud.google.com/go/bigquery"
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/sources/bigquery"
+	bigqueryrestapi "google.golang.org/api/bigquery/v2"
+)
+
+func TestBigQueryJobOptions(t *testing.T) {
+	sourceBytes := int64(1000)
+	enabled, disabled := true, false
+	tcs := []struct {
+		desc   string
+		source bigquery.Config
+		tool   bigquery.JobOptions
+		want   bigquery.JobOptions
+	}{
+		{
+			desc: "no options",
+			want: bigquery.JobOptions{},
+		},
+		{
+			desc:   "source options",
+			source: bigquery.Config{DefaultJobLabels: map[string]string{"team": "data"}, MaximumBytesBilled: &sourceBytes},
+			want:   bigquery.JobOptions{Labels: map[string]string{"team": "data"}, MaximumBytesBilled: 1000},
+		},
+		{
+			desc:   "tool options override the source's",
+			source: bigquery.Config{DefaultJobLabels: map[string]string{"team": "data", "env": "prod"}, MaximumBytesBilled: &sourceBytes},
+			tool:   bigquery.JobOptions{Labels: map[string]string{"team": "sales", "tool": "report"}, MaximumBytesBilled: 10},
+			want:   bigquery.JobOptions{Labels: map[string]string{"team": "sales", "env": "prod", "tool": "report"}, MaximumBytesBilled: 10},
+		},
+		{
+			desc:   "source reads through the Storage Read API",
+			source: bigquery.Config{UseStorageReadAPI: true},
+			want:   bigquery.JobOptions{UseStorageReadAPI: &enabled},
+		},
+		{
+			desc:   "tool does not read through the Storage Read API",
+			source: bigquery.Config{UseStorageReadAPI: true},
+			tool:   bigquery.JobOptions{UseStorageReadAPI: &disabled},
+			want:   bigquery.JobOptions{UseStorageReadAPI: &disabled},
+		},
+		{
+			desc: "tool reads through the Storage Read API",
+			tool: bigquery.JobOptions{UseStorageReadAPI: &enabled},
+			want: bigquery.JobOptions{UseStorageReadAPI: &enabled},
+		},
+		{
+			desc: "tool options only",
+			tool: bigquery.JobOptions{Labels: map[string]string{"tool": "report"}},
+			want: bigquery.JobOptions{Labels: map[string]string{"tool": "report"}},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := &bigquery.Source{Config: tc.source}
+			got := s.BigQueryJobOptions(tc.tool)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected options (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	// merging must not modify the labels of the source
+	s := &bigquery.Source{Config: bigquery.Config{DefaultJobLabels: map[string]string{"team": "data"}}}
+	s.BigQueryJobOptions(bigquery.JobOptions{Labels: map[string]string{"team": "sales"}})
+	if got := s.DefaultJobLabels["team"]; got != "data" {
+		t.Errorf("expected the labels of the source to be kept, got team=%q", got)
+	}
+}
+
+func TestJobOptionsApplyToDryRunsAndQueries(t *testing.T) {
+	opts := bigquery.JobOptions{Labels: map[string]string{"team": "data"}, MaximumBytesBilled: 1000}
+
+	cfg := &bigqueryrestapi.JobConfiguration{DryRun: true, Query: &bigqueryrestapi.JobConfigurationQuery{Query: "SELECT 1"}}
+	opts.ApplyToJob(cfg)
+	q := (&bigqueryapi.Client{}).Query("SELECT 1")
+	opts.ApplyToQuery(q)
+
+	if diff := cmp.Diff(q.Labels, cfg.Labels); diff != "" {
+		t.Errorf("expected dry runs to carry the labels of queries (-query +dry run):\n%s", diff)
+	}
+	if cfg.Query.MaximumBytesBilled != 1000 || q.MaxBytesBilled != 1000 {
+		t.Errorf("got maximum bytes billed %d for the dry run and %d for the query, want 1000", cfg.Query.MaximumBytesBilled, q.MaxBytesBilled)
+	}
+}
+
+func TestValidateJobOptions(t *testing.T) {
+	zero, negative, positive := int64(0), int64(-1), int64(1)
+	tcs := []struct {
+		desc               string
+		labels             map[string]string
+		maximumBytesBilled *int64
+		wantErr            bool
+	}{
+		{desc: "valid", labels: map[string]string{"team": "data-eng_1", "empty": ""}, maximumBytesBilled: &positive},
+		{desc: "unset", labels: nil, maximumBytesBilled: nil},
+		{desc: "uppercase key", labels: map[string]string{"Team": "data"}, wantErr: true},
+		{desc: "key starting with a digit", labels: map[string]string{"1team": "data"}, wantErr: true},
+		{desc: "invalid value", labels: map[string]string{"team": "Data Eng"}, wantErr: true},
+		{desc: "zero bytes", maximumBytesBilled: &zero, wantErr: true},
+		{desc: "negative bytes", maximumBytesBilled: &negative, wantErr: true},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := bigquery.ValidateJobOptions("defaultJobLabels", tc.labels, tc.maximumBytesBilled)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("got error %v, want error: %t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestJobOptionsMerge(t *testing.T) {
+	enabled, disabled := true, false
+	base := bigquery.JobOptions{
+		Labels:             map[string]string{"team": "data", "env": "prod"},
+		MaximumBytesBilled: 1000,
+		QueryLabel:         "app:toolbox",
+		Priority:           bigquery.PriorityBatch,
+		UseQueryCache:      &enabled,
+	}
+	override := bigquery.JobOptions{
+		Labels:           map[string]string{"team": "sales"},
+		DestinationTable: &bigqueryrestapi.TableReference{ProjectId: "p", DatasetId: "d", TableId: "t"},
+		UseQueryCache:    &disabled,
+	}
+
+	got := base.Merge(override)
+	want := bigquery.JobOptions{
+		Labels:             map[string]string{"team": "sales", "env": "prod"},
+		MaximumBytesBilled: 1000,
+		QueryLabel:         "app:toolbox",
+		DestinationTable:   override.DestinationTable,
+		Priority:           bigquery.PriorityBatch,
+		UseQueryCache:      &disabled,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected merged options (-want +got):\n%s", diff)
+	}
+	if base.Labels["team"] != "data" {
+		t.Errorf("expected the labels of the base options to be kept, got team=%q", base.Labels["team"])
+	}
+	if diff := cmp.Diff(base, base.Merge(bigquery.JobOptions{})); diff != "" {
+		t.Errorf("expected merging empty options to keep the options (-want +got):\n%s", diff)
+	}
+}
+
+func TestJobOptionsApplyQuerySettings(t *testing.T) {
+	disabled := false
+	opts := bigquery.JobOptions{
+		QueryLabel:       "app:toolbox",
+		DestinationTable: &bigqueryrestapi.TableReference{ProjectId: "p", DatasetId: "d", TableId: "t"},
+		Priority:         bigquery.PriorityBatch,
+		UseQueryCache:    &disabled,
+	}
+
+	cfg := &bigqueryrestapi.JobConfiguration{Query: &bigqueryrestapi.JobConfigurationQuery{
+		Query:                "SELECT 1",
+		ConnectionProperties: []*bigqueryrestapi.ConnectionProperty{{Key: "session_id", Value: "s"}},
+	}}
+	opts.ApplyToJob(cfg)
+	wantProps := []*bigqueryrestapi.ConnectionProperty{{Key: "session_id", Value: "s"}, {Key: "query_label", Value: "app:toolbox"}}
+	if diff := cmp.Diff(wantProps, cfg.Query.ConnectionProperties); diff != "" {
+		t.Errorf("unexpected connection properties of the job (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(opts.DestinationTable, cfg.Query.DestinationTable); diff != "" {
+		t.Errorf("unexpected destination table of the job (-want +got):\n%s", diff)
+	}
+	if cfg.Query.Priority != "BATCH" || cfg.Query.UseQueryCache == nil || *cfg.Query.UseQueryCache {
+		t.Errorf("got priority %q and useQueryCache %v, want BATCH and false", cfg.Query.Priority, cfg.Query.UseQueryCache)
+	}
+
+	q := (&bigqueryapi.Client{}).Query("SELECT 1")
+	q.ConnectionProperties = []*bigqueryapi.ConnectionProperty{{Key: "query_label", Value: "set:bytool"}}
+	opts.ApplyToQuery(q)
+	if len(q.ConnectionProperties) != 1 || q.ConnectionProperties[0].Value != "set:bytool" {
+		t.Errorf("expected a query_label already set to be kept, got %+v", q.ConnectionProperties)
+	}
+	if q.Dst == nil || q.Dst.ProjectID != "p" || q.Dst.DatasetID != "d" || q.Dst.TableID != "t" {
+		t.Errorf("unexpected destination table of the query: %+v", q.Dst)
+	}
+	if q.Priority != bigqueryapi.BatchPriority || !q.DisableQueryCache {
+		t.Errorf("got priority %q and DisableQueryCache %t, want BATCH and true", q.Priority, q.DisableQueryCache)
+	}
+}
+
+func TestValidateQueryLabelAndPriority(t *testing.T) {
+	for _, label := range []string{"app:toolbox", "app:", "team_1:data-eng"} {
+		if err := bigquery.ValidateQueryLabel(label); err != nil {
+			t.Errorf("unexpected error for query label %q: %s", label, err)
+		}
+	}
+	for _, label := range []string{"", "app", ":toolbox", "App:toolbox", "app:Tool box"} {
+		if err := bigquery.ValidateQueryLabel(label); err == nil {
+			t.Errorf("expected an error for query label %q", label)
+		}
+	}
+	for _, priority := range []string{"", "INTERACTIVE", "BATCH"} {
+		if err := bigquery.ValidatePriority(priority); err != nil {
+			t.Errorf("unexpected error for priority %q: %s", priority, err)
+		}
+	}
+	if err := bigquery.ValidatePriority("urgent"); err == nil {
+		t.Errorf("expected an error for an unknown priority")
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	"github.com/googleapis/genai-toolbox/internal/util"
+)
+
+// datasetLocationTTL is how long the locations of datasets are cached.
+// Datasets cannot move, but can be deleted and created again elsewhere.
+const datasetLocationTTL = time.Hour
+
+// DatasetLocation returns the location of the dataset projectID.datasetID,
+// e.g. "US" or "europe-west1", looked up with the REST service of an
+// invocation with accessToken. Locations are cached by the source, and shared
+// by all of its tools; only successful lookups are cached.
+func (s *Source) DatasetLocation(ctx context.Context, accessToken tools.AccessToken, projectID, datasetID string) (string, error) {
+	key := strings.ToLower(projectID) + "." + datasetID
+	if location, ok := s.datasetLocationCache().Get(key); ok {
+		return location.(string), nil
+	}
+	location, _, err := s.lookupDataset(ctx, accessToken, projectID, datasetID)
+	return location, err
+}
+
+// LinkedDatasetSource returns the source dataset, "project.dataset", of the
+// dataset projectID.datasetID if it is a linked dataset of an Analytics Hub
+// listing, or "" if it is not, looked up like DatasetLocation. Linked datasets
+// have ordinary names, but their access is granted by their listing. The
+// results are cached with the locations of the datasets.
+func (s *Source) LinkedDatasetSource(ctx context.Context, accessToken tools.AccessToken, projectID, datasetID string) (string, error) {
+	key := strings.ToLower(projectID) + "." + datasetID
+	if source, ok := s.linkedDatasetCache().Get(key); ok {
+		return source.(string), nil
+	}
+	_, source, err := s.lookupDataset(ctx, accessToken, projectID, datasetID)
+	return source, err
+}
+
+// InvalidateDataset drops the cached location and linked dataset source of
+// the dataset projectID.datasetID, e.g. because it was deleted and created
+// again in another location, so that they are looked up again. It returns
+// whether either was cached. Both also expire after datasetLocationTTL, and
+// are then dropped in the background.
+func (s *Source) InvalidateDataset(projectID, datasetID string) bool {
+	key := strings.ToLower(projectID) + "." + datasetID
+	_, locationCached := s.datasetLocationCache().Get(key)
+	_, linkedCached := s.linkedDatasetCache().Get(key)
+	s.datasetLocationCache().Delete(key)
+	s.linkedDatasetCache().Delete(key)
+	return locationCached || linkedCached
+}
+
+// lookupDataset looks up the location and the linked dataset source of the
+// dataset projectID.datasetID, and caches them.
+func (s *Source) lookupDataset(ctx context.Context, accessToken tools.AccessToken, projectID, datasetID string) (location, linkedSource string, err error) {
+	_, restService, err := s.RetrieveClientAndService(ctx, accessToken)
+	if err != nil {
+		return "", "", err
+	}
+	dataset, err := restService.Datasets.Get(projectID, datasetID).Context(ctx).Fields("location", "linkedDatasetSource").Do()
+	if err != nil {
+		return "", "", err
+	}
+	if l := dataset.LinkedDatasetSource; l != nil && l.SourceDataset != nil {
+		linkedSource = l.SourceDataset.ProjectId + "." + l.SourceDataset.DatasetId
+	}
+	key := strings.ToLower(projectID) + "." + datasetID
+	s.datasetLocationCache().Set(key, dataset.Location)
+	s.linkedDatasetCache().Set(key, linkedSource)
+	return dataset.Location, linkedSource, nil
+}
+
+// datasetLocationCache returns the cache of DatasetLocation, creating it on
+// first use.
+func (s *Source) datasetLocationCache() *sources.Cache {
+	s.datasetLocationsMu.Lock()
+	defer s.datasetLocationsMu.Unlock()
+	if s.datasetLocations == nil {
+		s.datasetLocations = sources.NewCacheWithTTL(datasetLocationTTL, nil)
+	}
+	return s.datasetLocations
+}
+
+// linkedDatasetCache returns the cache of LinkedDatasetSource, creating it
+// on first use.
+func (s *Source) linkedDatasetCache() *sources.Cache {
+	s.datasetLocationsMu.Lock()
+	defer s.datasetLocationsMu.Unlock()
+	if s.linkedDatasets == nil {
+		s.linkedDatasets = sources.NewCacheWithTTL(datasetLocationTTL, nil)
+	}
+	return s.linkedDatasets
+}
+
+// ResolveLocation returns the location of the BigQuery jobs or the
+// Conversational Analytics requests of a tool, in order of precedence:
+// override, the location set in the configuration of the tool; configured, the
+// location of the source; detected, the location shared by the allowed
+// datasets of the source, see BigQueryDetectedLocation; and fallback, the
+// default of the API. The default of BigQuery jobs is "", which lets BigQuery
+// locate them from the tables they reference.
+func ResolveLocation(override, configured, detected, fallback string) string {
+	for _, location := range []string{override, configured, detected} {
+		if location != "" {
+			return location
+		}
+	}
+	return fallback
+}
+
+// BigQueryDetectedLocation returns the location shared by the allowed datasets
+// of the source, detected when it is initialized, or "" if it has no allowed
+// datasets, they are in different locations, or they were not looked up.
+func (s *Source) BigQueryDetectedLocation() string {
+	return s.detectedLocation
+}
+
+// jobLocation returns the location of the BigQuery jobs of the source and of
+// the clients that run them.
+func (s *Source) jobLocation() string {
+	return ResolveLocation("", s.Location, s.detectedLocation, "")
+}
+
+// detectLocation records the location shared by datasets, the allowed datasets
+// of the source, from the locations looked up by verifyAllowedDatasets, and
+// sets it on the clients of the ADC credentials, if they were created by the
+// lookups. It logs the location of the jobs of the source at debug level.
+func (s *Source) detectLocation(ctx context.Context, datasets []allowedDataset) {
+	if s.Location == "" {
+		cache := s.datasetLocationCache()
+		for _, d := range datasets {
+			location, ok := cache.Get(strings.ToLower(d.ProjectID) + "." + d.DatasetID)
+			if !ok || (s.detectedLocation != "" && !strings.EqualFold(location.(string), s.detectedLocation)) {
+				s.detectedLocation = ""
+				break
+			}
+			s.detectedLocation = location.(string)
+		}
+		if s.detectedLocation != "" {
+			s.adcMu.Lock()
+			if s.Client != nil {
+				s.Client.Location = s.detectedLocation
+			}
+			s.adcMu.Unlock()
+		}
+	}
+
+	logger, err := util.LoggerFromContext(ctx)
+	if err != nil {
+		return
+	}
+	switch {
+	case s.Location != "":
+		logger.DebugContext(ctx, fmt.Sprintf("source %q runs its BigQuery jobs in its location %q", s.Name, s.Location))
+	case s.detectedLocation != "":
+		logger.DebugContext(ctx, fmt.Sprintf("source %q runs its BigQuery jobs in %q, the location of its allowed datasets", s.Name, s.detectedLocation))
+	default:
+		logger.DebugContext(ctx, fmt.Sprintf("source %q has no location, so BigQuery locates its jobs from the tables they reference", s.Name))
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	bigqueryapi "cloud.google.com/go/bigquery"
+	"golang.org/x/oauth2"
+	bigqueryrestapi "google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/option"
+)
+
+func TestDatasetLocation(t *testing.T) {
+	calls := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls[r.URL.Path]++
+		switch r.URL.Path {
+		case "/projects/my-project/datasets/sales":
+			fmt.Fprint(w, `{"location": "US"}`)
+		case "/projects/my-project/datasets/eu_sales":
+			fmt.Fprint(w, `{"location": "europe-west1"}`)
+		default:
+			http.Error(w, `{"error": {"code": 404, "message": "not found"}}`, http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	restService, err := bigqueryrestapi.NewService(context.Background(), option.WithEndpoint(server.URL), option.WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatalf("unable to create REST service: %s", err)
+	}
+	s := &Source{Config: Config{Project: "my-project"}}
+	s.makeADCClients = func() (*bigqueryapi.Client, *bigqueryrestapi.Service, oauth2.TokenSource, error) {
+		return &bigqueryapi.Client{}, restService, nil, nil
+	}
+
+	ctx := context.Background()
+	for _, tc := range []struct{ project, dataset, want string }{
+		{"my-project", "sales", "US"},
+		{"my-project", "eu_sales", "europe-west1"},
+		// project IDs are case-insensitive, and share the cache
+		{"My-Project", "sales", "US"},
+		{"my-project", "eu_sales", "europe-west1"},
+	} {
+		got, err := s.DatasetLocation(ctx, "", tc.project, tc.dataset)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if got != tc.want {
+			t.Fatalf("got location %q of %s.%s, want %q", got, tc.project, tc.dataset, tc.want)
+		}
+	}
+	if calls["/projects/my-project/datasets/sales"] != 1 || calls["/projects/my-project/datasets/eu_sales"] != 1 {
+		t.Fatalf("expected each location to be looked up once, got %v", calls)
+	}
+
+	// failed lookups are not cached
+	for range 2 {
+		if _, err := s.DatasetLocation(ctx, "", "my-project", "missing"); err == nil {
+			t.Fatalf("expected an error for a missing dataset")
+		}
+	}
+	if calls["/projects/my-project/datasets/missing"] != 2 {
+		t.Fatalf("expected failed lookups to be retried, got %v", calls)
+	}
+}
+
+func TestInvalidateDataset(t *testing.T) {
+	location := "europe-west1"
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprintf(w, `{"location": %q}`, location)
+	}))
+	defer server.Close()
+	restService, err := bigqueryrestapi.NewService(context.Background(), option.WithEndpoint(server.URL), option.WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatalf("unable to create REST service: %s", err)
+	}
+	s := &Source{Config: Config{Project: "my-project"}}
+	s.makeADCClients = func() (*bigqueryapi.Client, *bigqueryrestapi.Service, oauth2.TokenSource, error) {
+		return &bigqueryapi.Client{}, restService, nil, nil
+	}
+
+	ctx := context.Background()
+	if s.InvalidateDataset("my-project", "sales") {
+		t.Fatalf("expected nothing to be invalidated before the first lookup")
+	}
+	if got, err := s.DatasetLocation(ctx, "", "my-project", "sales"); err != nil || got != "europe-west1" {
+		t.Fatalf("got location %q, %v, want %q", got, err, "europe-west1")
+	}
+
+	// the dataset is created again in another location
+	location = "US"
+	if got, _ := s.DatasetLocation(ctx, "", "my-project", "sales"); got != "europe-west1" {
+		t.Fatalf("expected the cached location, got %q", got)
+	}
+	if !s.InvalidateDataset("My-Project", "sales") {
+		t.Fatalf("expected the cached location to be invalidated")
+	}
+	if got, err := s.DatasetLocation(ctx, "", "my-project", "sales"); err != nil || got != "US" {
+		t.Fatalf("got location %q, %v, want %q", got, err, "US")
+	}
+	if calls != 2 {
+		t.Fatalf("expected the location to be looked up again once, got %d lookups", calls)
+	}
+}
+
+func TestLinkedDatasetSource(t *testing.T) {
+	calls := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls[r.URL.Path]++
+		switch r.URL.Path {
+		case "/projects/my-project/datasets/shared_weather":
+			fmt.Fprint(w, `{"location": "US", "linkedDatasetSource": {"sourceDataset": {"projectId": "publisher", "datasetId": "weather"}}}`)
+		case "/projects/my-project/datasets/sales":
+			fmt.Fprint(w, `{"location": "US"}`)
+		default:
+			http.Error(w, `{"error": {"code": 404, "message": "not found"}}`, http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	restService, err := bigqueryrestapi.NewService(context.Background(), option.WithEndpoint(server.URL), option.WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatalf("unable to create REST service: %s", err)
+	}
+	s := &Source{Config: Config{Project: "my-project"}}
+	s.makeADCClients = func() (*bigqueryapi.Client, *bigqueryrestapi.Service, oauth2.TokenSource, error) {
+		return &bigqueryapi.Client{}, restService, nil, nil
+	}
+
+	ctx := context.Background()
+	for _, tc := range []struct{ dataset, want string }{
+		{"shared_weather", "publisher.weather"},
+		{"sales", ""},
+		{"shared_weather", "publisher.weather"},
+	} {
+		got, err := s.LinkedDatasetSource(ctx, "", "my-project", tc.dataset)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if got != tc.want {
+			t.Fatalf("got linked dataset source %q of %s, want %q", got, tc.dataset, tc.want)
+		}
+	}
+	// the location is cached by the same lookup
+	if got, err := s.DatasetLocation(ctx, "", "my-project", "shared_weather"); err != nil || got != "US" {
+		t.Fatalf("got location %q, %v, want %q", got, err, "US")
+	}
+	if calls["/projects/my-project/datasets/shared_weather"] != 1 || calls["/projects/my-project/datasets/sales"] != 1 {
+		t.Fatalf("expected each dataset to be looked up once, got %v", calls)
+	}
+	if _, err := s.LinkedDatasetSource(ctx, "", "my-project", "missing"); err == nil {
+		t.Fatalf("expected an error for a missing dataset")
+	}
+}
+
+func TestResolveLocation(t *testing.T) {
+	tcs := []struct {
+		desc                                     string
+		override, configured, detected, fallback string
+		want                                     string
+	}{
+		{desc: "override", override: "asia-northeast1", configured: "US", detected: "EU", fallback: "us", want: "asia-northeast1"},
+		{desc: "configured", configured: "US", detected: "EU", fallback: "us", want: "US"},
+		{desc: "detected", detected: "EU", fallback: "us", want: "EU"},
+		{desc: "fallback", fallback: "us", want: "us"},
+		{desc: "none", want: ""},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := ResolveLocation(tc.override, tc.configured, tc.detected, tc.fallback); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDetectLocation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/projects/my-project/datasets/eu_sales", "/projects/my-project/datasets/eu_marketing":
+			fmt.Fprint(w, `{"location": "EU"}`)
+		case "/projects/my-project/datasets/us_sales":
+			fmt.Fprint(w, `{"location": "US"}`)
+		default:
+			http.Error(w, `{"error": {"code": 404, "message": "not found"}}`, http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	restService, err := bigqueryrestapi.NewService(context.Background(), option.WithEndpoint(server.URL), option.WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatalf("unable to create REST service: %s", err)
+	}
+
+	tcs := []struct {
+		desc     string
+		location string
+		datasets []allowedDataset
+		want     string
+	}{
+		{desc: "same location", datasets: []allowedDataset{{"my-project", "eu_sales"}, {"my-project", "eu_marketing"}}, want: "EU"},
+		{desc: "different locations", datasets: []allowedDataset{{"my-project", "eu_sales"}, {"my-project", "us_sales"}}},
+		{desc: "location of the source", location: "US", datasets: []allowedDataset{{"my-project", "eu_sales"}}},
+		{desc: "dataset not found", datasets: []allowedDataset{{"my-project", "eu_sales"}, {"my-project", "missing"}}},
+		{desc: "no allowed datasets"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctx := context.Background()
+			client := &bigqueryapi.Client{}
+			s := &Source{Config: Config{Name: "my-bq", Project: "my-project", Location: tc.location}}
+			s.makeADCClients = func() (*bigqueryapi.Client, *bigqueryrestapi.Service, oauth2.TokenSource, error) {
+				return client, restService, nil, nil
+			}
+			if err := s.verifyAllowedDatasets(ctx, tc.datasets, VerifyAllowedDatasetsWarn); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			s.detectLocation(ctx, tc.datasets)
+			if got := s.BigQueryDetectedLocation(); got != tc.want {
+				t.Errorf("got detected location %q, want %q", got, tc.want)
+			}
+			if tc.want != "" && client.Location != tc.want {
+				t.Errorf("expected the location of the client to be %q, got %q", tc.want, client.Location)
+			}
+			if want := ResolveLocation("", tc.location, tc.want, ""); s.jobLocation() != want {
+				t.Errorf("got job location %q, want %q", s.jobLocation(), want)
+			}
+		})
+	}
+}
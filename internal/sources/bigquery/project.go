@@ -0,0 +1,93 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	bigqueryapi "cloud.google.com/go/bigquery"
+)
+
+// projectKey is the context key of the project override of an invocation.
+type projectKey struct{}
+
+// BigQueryAllowedProjects returns the projects that invocations can use
+// instead of the source's project, see WithProject.
+func (s *Source) BigQueryAllowedProjects() []string {
+	return s.AllowedProjects
+}
+
+// WithProject returns ctx with the project that invocations with it use
+// instead of the source's project: the clients returned by
+// RetrieveClientAndService run queries in it, and BigQueryProjectFor returns
+// it. An empty project, or the source's project, leaves ctx unchanged. Any
+// other project must be one of the allowedProjects of the source.
+func (s *Source) WithProject(ctx context.Context, project string) (context.Context, error) {
+	project = strings.TrimSpace(project)
+	if project == "" || project == s.Project {
+		return ctx, nil
+	}
+	if !slices.Contains(s.AllowedProjects, project) {
+		if len(s.AllowedProjects) == 0 {
+			return nil, fmt.Errorf("project %q is not allowed; source %q only uses project %q", project, s.Name, s.Project)
+		}
+		return nil, fmt.Errorf("project %q is not allowed; allowed projects are %q and %s", project, s.Project, strings.Join(quoteAll(s.AllowedProjects), ", "))
+	}
+	return context.WithValue(ctx, projectKey{}, project), nil
+}
+
+// BigQueryProjectFor returns the project of invocations with ctx: the project
+// set with WithProject, or the source's project.
+func (s *Source) BigQueryProjectFor(ctx context.Context) string {
+	if project, ok := ctx.Value(projectKey{}).(string); ok {
+		return project
+	}
+	return s.Project
+}
+
+// projectClient returns the client of an invocation that uses project instead
+// of the source's project, with the client's access token tokenString or, if
+// empty, the source's ADC credentials. Clients are cached per project and
+// token.
+func (s *Source) projectClient(project, tokenString string) (*bigqueryapi.Client, error) {
+	key := project
+	if tokenString != "" {
+		key += "/" + tokenCacheKey(tokenString)
+	}
+	// Concurrent invocations must not create a client each, as replacing a
+	// cached client closes it.
+	s.projectClientsMu.Lock()
+	defer s.projectClientsMu.Unlock()
+	if client, ok := s.projectClients.Get(key); ok {
+		return client.(*bigqueryapi.Client), nil
+	}
+	client, err := s.makeProjectClient(project, tokenString)
+	if err != nil {
+		return nil, fmt.Errorf("error creating client for project %q: %w", project, err)
+	}
+	s.projectClients.Set(key, client)
+	return client, nil
+}
+
+func quoteAll(values []string) []string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return quoted
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	bigqueryapi "cloud.google.com/go/bigquery"
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	"golang.org/x/oauth2"
+	bigqueryrestapi "google.golang.org/api/bigquery/v2"
+)
+
+func TestWithProject(t *testing.T) {
+	tcs := []struct {
+		desc            string
+		allowedProjects []string
+		project         string
+		want            string
+		wantErr         bool
+	}{
+		{desc: "no override", allowedProjects: []string{"tenant-a"}, project: "", want: "my-project"},
+		{desc: "source project", allowedProjects: []string{"tenant-a"}, project: "my-project", want: "my-project"},
+		{desc: "allowed project", allowedProjects: []string{"tenant-a", "tenant-b"}, project: " tenant-b ", want: "tenant-b"},
+		{desc: "rejected project", allowedProjects: []string{"tenant-a"}, project: "tenant-c", wantErr: true},
+		{desc: "single project source", project: "tenant-a", wantErr: true},
+		{desc: "single project source without override", project: "", want: "my-project"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := &Source{Config: Config{Name: "my-source", Project: "my-project", AllowedProjects: tc.allowedProjects}}
+			ctx, err := s.WithProject(context.Background(), tc.project)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected project %q to be rejected", tc.project)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got := s.BigQueryProjectFor(ctx); got != tc.want {
+				t.Errorf("BigQueryProjectFor() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRetrieveClientAndServiceWithProject(t *testing.T) {
+	defaultClient, restService := &bigqueryapi.Client{}, &bigqueryrestapi.Service{}
+	created := map[string]int{}
+	s := &Source{Config: Config{
+		Project:                 "my-project",
+		AllowedProjects:         []string{"tenant-a"},
+		ClientAuthorizationMode: ClientAuthorizationPreferred,
+	}}
+	s.makeADCClients = func() (*bigqueryapi.Client, *bigqueryrestapi.Service, oauth2.TokenSource, error) {
+		return defaultClient, restService, nil, nil
+	}
+	setupClientCaching(s, func(tokenString string, wantRestService bool) (*bigqueryapi.Client, *bigqueryrestapi.Service, error) {
+		return &bigqueryapi.Client{}, restService, nil
+	})
+	s.projectClients = sources.NewCacheWithTTL(time.Hour, nil)
+	s.makeProjectClient = func(project, tokenString string) (*bigqueryapi.Client, error) {
+		created[project+"/"+tokenString]++
+		return &bigqueryapi.Client{}, nil
+	}
+
+	ctx := context.Background()
+	client, _, err := s.RetrieveClientAndService(ctx, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if client != defaultClient || len(created) != 0 {
+		t.Fatalf("expected invocations without an override to use the source's client")
+	}
+
+	tenantCtx, err := s.WithProject(ctx, "tenant-a")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	first, gotRestService, err := s.RetrieveClientAndService(tenantCtx, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if first == defaultClient {
+		t.Fatalf("expected an invocation with an override to use a client of the project")
+	}
+	if gotRestService != restService {
+		t.Errorf("expected the REST service to be shared across projects")
+	}
+	second, _, err := s.RetrieveClientAndService(tenantCtx, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if second != first || created["tenant-a/"] != 1 {
+		t.Errorf("expected the client of the project to be cached, created %d times", created["tenant-a/"])
+	}
+
+	// Clients of the client's access tokens are cached separately.
+	if _, _, err := s.RetrieveClientAndService(tenantCtx, "Bearer user-token"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if created["tenant-a/user-token"] != 1 {
+		t.Errorf("expected a client of the project for the user's token, created %d times", created["tenant-a/user-token"])
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	"github.com/googleapis/genai-toolbox/internal/tools/googlehttp"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// secretScheme is the URI scheme of Secret Manager references, e.g.
+// "secret://projects/my-project/secrets/my-secret".
+const secretScheme = "secret"
+
+// secretManagerBaseURL is the endpoint of the Secret Manager API.
+var secretManagerBaseURL = "https://secretmanager.googleapis.com/v1"
+
+// secretTokenSource returns the token source secrets are read with.
+var secretTokenSource = func(ctx context.Context) (oauth2.TokenSource, error) {
+	return google.DefaultTokenSource(ctx, "https://www.googleapis.com/auth/cloud-platform")
+}
+
+func init() {
+	if !tools.RegisterSecretResolver(secretScheme, resolveSecret) {
+		panic(fmt.Sprintf("secret resolver %q already registered", secretScheme))
+	}
+}
+
+// resolveSecret returns the Secret Manager secret of ref, either
+// secret://projects/PROJECT/secrets/SECRET for its latest version or
+// secret://projects/PROJECT/secrets/SECRET/versions/VERSION. The secret is
+// read with the application default credentials.
+func resolveSecret(ctx context.Context, ref string) (string, error) {
+	name := strings.TrimPrefix(ref, secretScheme+"://")
+	parts := strings.Split(name, "/")
+	if len(parts) == 4 {
+		parts = append(parts, "versions", "latest")
+	}
+	if len(parts) != 6 || parts[0] != "projects" || parts[2] != "secrets" || parts[4] != "versions" || slices.Contains(parts, "") {
+		return "", fmt.Errorf("invalid secret reference %q: must be of the form secret://projects/PROJECT/secrets/SECRET[/versions/VERSION]", ref)
+	}
+	ts, err := secretTokenSource(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to create the token source: %w", err)
+	}
+	client := googlehttp.Client{Token: googlehttp.FromTokenSource(ts)}
+	var resp struct {
+		Payload struct {
+			Data []byte `json:"data"`
+		} `json:"payload"`
+	}
+	if err := client.DoJSON(ctx, http.MethodGet, fmt.Sprintf("%s/%s:access", secretManagerBaseURL, strings.Join(parts, "/")), nil, &resp); err != nil {
+		return "", err
+	}
+	return string(resp.Payload.Data), nil
+}
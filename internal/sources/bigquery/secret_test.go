@@ -0,0 +1,87 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func TestResolveSecret(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, `{"error": {"code": 401, "message": "unauthenticated"}}`, http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/projects/p/secrets/s/versions/latest:access", "/projects/p/secrets/s/versions/2:access":
+			// "my-secret" in base64
+			fmt.Fprint(w, `{"payload": {"data": "bXktc2VjcmV0"}}`)
+		default:
+			http.Error(w, `{"error": {"code": 404, "message": "not found"}}`, http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	oldURL, oldTokenSource := secretManagerBaseURL, secretTokenSource
+	t.Cleanup(func() { secretManagerBaseURL, secretTokenSource = oldURL, oldTokenSource })
+	secretManagerBaseURL = server.URL
+	secretTokenSource = func(context.Context) (oauth2.TokenSource, error) {
+		return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"}), nil
+	}
+
+	tcs := []struct {
+		desc     string
+		ref      string
+		want     string
+		wantPath string
+		wantErr  string
+	}{
+		{desc: "latest version", ref: "secret://projects/p/secrets/s", want: "my-secret", wantPath: "/projects/p/secrets/s/versions/latest:access"},
+		{desc: "version", ref: "secret://projects/p/secrets/s/versions/2", want: "my-secret", wantPath: "/projects/p/secrets/s/versions/2:access"},
+		{desc: "missing secret", ref: "secret://projects/p/secrets/gone", wantPath: "/projects/p/secrets/gone/versions/latest:access", wantErr: "not found"},
+		{desc: "invalid reference", ref: "secret://projects/p/s", wantErr: "invalid secret reference"},
+		{desc: "empty segment", ref: "secret://projects//secrets/s", wantErr: "invalid secret reference"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			paths = nil
+			got, err := resolveSecret(context.Background(), tc.ref)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tc.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+			if tc.wantPath == "" && len(paths) > 0 {
+				t.Errorf("expected no API call, got %v", paths)
+			}
+			if tc.wantPath != "" && (len(paths) != 1 || paths[0] != tc.wantPath) {
+				t.Errorf("got API calls %v, want %s", paths, tc.wantPath)
+			}
+		})
+	}
+}
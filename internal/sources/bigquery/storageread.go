@@ -0,0 +1,122 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	bigqueryapi "cloud.google.com/go/bigquery"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"google.golang.org/api/option"
+)
+
+// storageReadMinRows is the number of result rows from which reading them
+// through the Storage Read API is faster than paging through them.
+const storageReadMinRows = 10000
+
+// storageReader creates, on first use, the client that reads the results of
+// the queries of another client through the Storage Read API. It uses the
+// credentials and project of that client.
+type storageReader struct {
+	once   sync.Once
+	create func() (*bigqueryapi.Client, error)
+	client *bigqueryapi.Client
+	err    error
+}
+
+// storageReaders holds the storageReader of each client created by sources.
+var storageReaders sync.Map
+
+// registerStorageReader records how to create the Storage Read API client of
+// client, which was created for project and location with opts.
+func registerStorageReader(ctx context.Context, client *bigqueryapi.Client, project, location string, opts []option.ClientOption) {
+	// The client is created on first use, after the call creating client.
+	ctx = context.WithoutCancel(ctx)
+	storageReaders.Store(client, &storageReader{create: func() (*bigqueryapi.Client, error) {
+		c, err := bigqueryapi.NewClient(ctx, project, opts...)
+		if err != nil {
+			return nil, err
+		}
+		c.Location = location
+		if err := c.EnableStorageReadClient(ctx, opts...); err != nil {
+			c.Close()
+			return nil, err
+		}
+		return c, nil
+	}})
+}
+
+// storageReadClient returns the Storage Read API client of client.
+func storageReadClient(client *bigqueryapi.Client) (*bigqueryapi.Client, error) {
+	v, ok := storageReaders.Load(client)
+	if !ok {
+		return nil, fmt.Errorf("no Storage Read API client is available for the client")
+	}
+	r := v.(*storageReader)
+	r.once.Do(func() {
+		r.client, r.err = r.create()
+	})
+	return r.client, r.err
+}
+
+// closeClient closes client and its Storage Read API client, if created.
+func closeClient(client *bigqueryapi.Client) {
+	if v, ok := storageReaders.LoadAndDelete(client); ok {
+		if r := v.(*storageReader); r.client != nil {
+			r.client.Close()
+		}
+	}
+	client.Close()
+}
+
+// readQueryResults returns the iterator of the results of job, which was run
+// by client. If useStorageReadAPI is set and the results that are returned
+// are large enough, they are read through the Storage Read API. Otherwise, or
+// if the results cannot be read through it, e.g. because they are not in a
+// table, they are paged through with the client.
+func (s *Source) readQueryResults(ctx context.Context, client *bigqueryapi.Client, job *bigqueryapi.Job, useStorageReadAPI bool) (*bigqueryapi.RowIterator, error) {
+	it, err := job.Read(ctx)
+	if err != nil || !useStorageReadAPI {
+		return it, err
+	}
+	rows := it.TotalRows
+	if s.MaxQueryResultRows > 0 && rows > uint64(s.MaxQueryResultRows) {
+		rows = uint64(s.MaxQueryResultRows)
+	}
+	if rows < storageReadMinRows {
+		return it, nil
+	}
+
+	storageIt, err := func() (*bigqueryapi.RowIterator, error) {
+		storageClient, err := storageReadClient(client)
+		if err != nil {
+			return nil, err
+		}
+		storageJob, err := storageClient.JobFromIDLocation(ctx, job.ID(), job.Location())
+		if err != nil {
+			return nil, err
+		}
+		return storageJob.Read(ctx)
+	}()
+	if err != nil {
+		if logger, lerr := util.LoggerFromContext(ctx); lerr == nil {
+			logger.DebugContext(ctx, fmt.Sprintf("reading the results of job %q without the Storage Read API: %s", job.ID(), err))
+		}
+		return it, nil
+	}
+	return storageIt, nil
+}
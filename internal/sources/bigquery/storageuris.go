@@ -0,0 +1,62 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"fmt"
+	"strings"
+)
+
+// normalizeAllowedStorageURIPrefixes returns the allowedStorageUriPrefixes of
+// the source configured by r. A prefix naming only a bucket, e.g.
+// "gs://bucket", is returned with a trailing slash, so that it does not allow
+// the buckets whose names start with it.
+func normalizeAllowedStorageURIPrefixes(r Config) ([]string, error) {
+	var prefixes []string
+	for _, p := range r.AllowedStorageURIPrefixes {
+		p = strings.TrimSpace(p)
+		scheme, path, ok := strings.Cut(p, "://")
+		bucket, _, hasPath := strings.Cut(path, "/")
+		if !ok || scheme == "" || bucket == "" || strings.Contains(p, "*") {
+			return nil, fmt.Errorf("invalid allowedStorageUriPrefixes %q: expected a URI prefix starting with a bucket and without wildcards, e.g. 'gs://bucket/path/'", p)
+		}
+		if !hasPath {
+			p += "/"
+		}
+		prefixes = append(prefixes, p)
+	}
+	return prefixes, nil
+}
+
+// BigQueryRestrictsStorageURIs returns whether the external tables and loads
+// of queries may only read the URIs under the allowedStorageUriPrefixes.
+func (s *Source) BigQueryRestrictsStorageURIs() bool {
+	return len(s.AllowedStorageURIPrefixes) > 0
+}
+
+// IsStorageURIAllowed returns whether queries may read external data from
+// uri, which may contain wildcards. A URI with a wildcard is only allowed if
+// the part before the wildcard starts with an allowed prefix.
+func (s *Source) IsStorageURIAllowed(uri string) bool {
+	if !s.BigQueryRestrictsStorageURIs() {
+		return true
+	}
+	for _, prefix := range s.AllowedStorageURIPrefixes {
+		if strings.HasPrefix(uri, prefix) {
+			return true
+		}
+	}
+	return false
+}
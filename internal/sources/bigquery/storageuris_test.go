@@ -0,0 +1,73 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/sources/bigquery"
+	"github.com/googleapis/genai-toolbox/internal/testutils"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestInitialize_AllowedStorageURIPrefixes(t *testing.T) {
+	ctx, err := testutils.ContextWithNewLogger()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ctx = util.WithUserAgent(ctx, "test-agent")
+	tracer := noop.NewTracerProvider().Tracer("")
+
+	cfg := bigquery.Config{Name: "my-instance", Type: bigquery.SourceType, Project: "test-project", UseClientOAuth: true, AllowedStorageURIPrefixes: []string{"gs://lake/raw/", " gs://exports "}}
+	src, err := cfg.Initialize(ctx, tracer)
+	if err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	bqSrc := src.(*bigquery.Source)
+	if diff := cmp.Diff([]string{"gs://lake/raw/", "gs://exports/"}, bqSrc.AllowedStorageURIPrefixes); diff != "" {
+		t.Fatalf("unexpected prefixes (-want +got):\n%s", diff)
+	}
+	if !bqSrc.BigQueryRestrictsStorageURIs() {
+		t.Fatalf("expected storage URIs to be restricted")
+	}
+	for uri, want := range map[string]bool{
+		"gs://lake/raw/2026/*.parquet": true,
+		"gs://lake/raw/*":              true,
+		"gs://exports/a.csv":           true,
+		"gs://lake/curated/a.parquet":  false,
+		"gs://lake/*":                  false,
+		"gs://lake/raw*":               false,
+		"gs://exports-other/a.csv":     false,
+		"s3://lake/raw/a.parquet":      false,
+	} {
+		if got := bqSrc.IsStorageURIAllowed(uri); got != want {
+			t.Errorf("IsStorageURIAllowed(%q) = %t, want %t", uri, got, want)
+		}
+	}
+
+	for _, prefix := range []string{"lake/raw/", "gs://", "gs://lake/*/raw/"} {
+		cfg.AllowedStorageURIPrefixes = []string{prefix}
+		if _, err := cfg.Initialize(ctx, tracer); err == nil || !strings.Contains(err.Error(), "invalid allowedStorageUriPrefixes") {
+			t.Errorf("expected prefix %q to fail, got %v", prefix, err)
+		}
+	}
+
+	if (&bigquery.Source{}).BigQueryRestrictsStorageURIs() || !(&bigquery.Source{}).IsStorageURIAllowed("gs://any/*") {
+		t.Errorf("expected storage URIs to be unrestricted without prefixes")
+	}
+}
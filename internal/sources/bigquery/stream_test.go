@@ -0,0 +1,138 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	bigqueryapi "cloud.google.com/go/bigquery"
+	"github.com/googleapis/genai-toolbox/internal/sources/bigquery"
+	"google.golang.org/api/option"
+)
+
+// newQuerySource returns a BigQuery source whose queries return the rows 0
+// to n-1 of the column n.
+func newQuerySource(t *testing.T, n int) (*bigquery.Source, *bigqueryapi.Client) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ref := map[string]any{"projectId": "my-project", "jobId": "job", "location": "US"}
+		if r.Method == http.MethodPost {
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"jobReference":  ref,
+				"configuration": map[string]any{"query": map[string]any{"query": "SELECT n"}},
+				"status":        map[string]any{"state": "DONE"},
+			})
+			return
+		}
+		rows := []any{}
+		for i := 0; i < n; i++ {
+			rows = append(rows, map[string]any{"f": []any{map[string]any{"v": fmt.Sprint(i)}}})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"jobComplete":  true,
+			"jobReference": ref,
+			"schema":       map[string]any{"fields": []any{map[string]any{"name": "n", "type": "INTEGER"}}},
+			"rows":         rows,
+			"totalRows":    fmt.Sprint(n),
+		})
+	}))
+	t.Cleanup(server.Close)
+	client, err := bigqueryapi.NewClient(context.Background(), "my-project", option.WithEndpoint(server.URL), option.WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatalf("unable to create client: %s", err)
+	}
+	return &bigquery.Source{Config: bigquery.Config{Name: "my-bq", Type: "bigquery", Project: "my-project"}, Client: client}, client
+}
+
+func TestStreamSQL(t *testing.T) {
+	errStop := errors.New("client is gone")
+	tcs := []struct {
+		desc      string
+		maxRows   int
+		failAfter int
+		want      string
+		wantErr   error
+	}{
+		{
+			desc: "batches in order",
+			want: `[[{"n":0},{"n":1}],[{"n":2},{"n":3}],[{"n":4}]]`,
+		},
+		{
+			desc:    "maximum rows",
+			maxRows: 3,
+			want:    `[[{"n":0},{"n":1}],[{"n":2}]]`,
+		},
+		{
+			desc:      "emit fails",
+			failAfter: 1,
+			want:      `[[{"n":0},{"n":1}]]`,
+			wantErr:   errStop,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			source, client := newQuerySource(t, 5)
+			source.MaxQueryResultRows = tc.maxRows
+			var batches [][]any
+			err := source.StreamSQL(context.Background(), client, "SELECT n", nil, nil, bigquery.JobOptions{}, 2, func(rows []any) error {
+				if tc.failAfter > 0 && len(batches) == tc.failAfter {
+					return errStop
+				}
+				batches = append(batches, rows)
+				return nil
+			})
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("unexpected error: got %v, want %v", err, tc.wantErr)
+			}
+			got, err := json.Marshal(batches)
+			if err != nil {
+				t.Fatalf("unable to marshal batches: %s", err)
+			}
+			if string(got) != tc.want {
+				t.Fatalf("unexpected batches: got %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRunSQLCollectsAllRows(t *testing.T) {
+	source, client := newQuerySource(t, 5)
+	res, err := source.RunSQL(context.Background(), client, "SELECT n", "SELECT", nil, nil, bigquery.JobOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	got, err := json.Marshal(res)
+	if err != nil {
+		t.Fatalf("unable to marshal result: %s", err)
+	}
+	if want := `[{"n":0},{"n":1},{"n":2},{"n":3},{"n":4}]`; string(got) != want {
+		t.Fatalf("unexpected result: got %s, want %s", got, want)
+	}
+
+	source, client = newQuerySource(t, 0)
+	res, err = source.RunSQL(context.Background(), client, "SELECT n", "SELECT", nil, nil, bigquery.JobOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if res != bigquery.NoRowsResult("SELECT") {
+		t.Fatalf("unexpected result: %v", res)
+	}
+}
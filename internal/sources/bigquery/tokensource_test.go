@@ -0,0 +1,98 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// countingTokenSource mints a new token on every call to Token.
+type countingTokenSource struct {
+	calls  int
+	expiry time.Duration
+}
+
+func (c *countingTokenSource) Token() (*oauth2.Token, error) {
+	c.calls++
+	return &oauth2.Token{AccessToken: fmt.Sprintf("token-%d", c.calls), Expiry: time.Now().Add(c.expiry)}, nil
+}
+
+func TestBigQueryTokenSourceWithScopeReusesTokens(t *testing.T) {
+	fakes := map[string]*countingTokenSource{}
+	s := &Source{}
+	s.makeTokenSource = func(ctx context.Context, scopes []string) (oauth2.TokenSource, error) {
+		fake := &countingTokenSource{expiry: time.Hour}
+		fakes[fmt.Sprint(scopes)] = fake
+		return fake, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	for i := 0; i < 3; i++ {
+		ts, err := s.BigQueryTokenSourceWithScope(ctx, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		tok, err := ts.Token()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if tok.AccessToken != "token-1" {
+			t.Fatalf("expected the first token to be reused, got %q", tok.AccessToken)
+		}
+	}
+	cancel()
+	if len(fakes) != 1 {
+		t.Fatalf("expected a single token source to be created, got %d", len(fakes))
+	}
+	if got := fakes[fmt.Sprint([]string{CloudPlatformScope})].calls; got != 1 {
+		t.Fatalf("expected Token to be fetched once while valid, got %d", got)
+	}
+
+	// Scopes are cached independently of their order.
+	for _, scopes := range [][]string{{"b", "a"}, {"a", "b"}} {
+		if _, err := s.BigQueryTokenSourceWithScope(context.Background(), scopes); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	if len(fakes) != 2 {
+		t.Fatalf("expected one token source per set of scopes, got %d", len(fakes))
+	}
+}
+
+func TestBigQueryTokenSourceWithScopeRefreshesExpiredTokens(t *testing.T) {
+	fake := &countingTokenSource{expiry: -time.Minute}
+	s := &Source{}
+	s.makeTokenSource = func(ctx context.Context, scopes []string) (oauth2.TokenSource, error) {
+		return fake, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		ts, err := s.BigQueryTokenSourceWithScope(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if _, err := ts.Token(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	if fake.calls != 2 {
+		t.Fatalf("expected expired tokens to be re-fetched, got %d calls", fake.calls)
+	}
+}
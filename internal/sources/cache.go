@@ -37,18 +37,27 @@ type Cache struct {
 	mu      sync.RWMutex
 	items   map[string]Item
 	onEvict OnEvictFunc
+	ttl     time.Duration
 }
 
+// defaultTTL is how long items are cached by caches created with NewCache.
+const defaultTTL = 55 * time.Minute
+
 // NewCache creates a new cache and cleans up every 55 min
 func NewCache(onEvict OnEvictFunc) *Cache {
-	const cleanupInterval = 55 * time.Minute
+	return NewCacheWithTTL(defaultTTL, onEvict)
+}
 
+// NewCacheWithTTL creates a new cache whose items expire after ttl, and cleans
+// up expired items every ttl.
+func NewCacheWithTTL(ttl time.Duration, onEvict OnEvictFunc) *Cache {
 	c := &Cache{
 		items:   make(map[string]Item),
 		onEvict: onEvict,
+		ttl:     ttl,
 	}
 
-	go c.startCleanup(cleanupInterval)
+	go c.startCleanup(ttl)
 	return c
 }
 
@@ -72,8 +81,7 @@ func (c *Cache) delete(key string, item Item) {
 
 // Set adds an item to the cache
 func (c *Cache) Set(key string, value any) {
-	const ttl = 55 * time.Minute
-	expires := time.Now().Add(ttl).UnixNano()
+	expires := time.Now().Add(c.ttl).UnixNano()
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -89,16 +97,23 @@ func (c *Cache) Set(key string, value any) {
 	}
 }
 
-// Get retrieves an item from the cache
+// Get retrieves an item from the cache. An expired item is evicted.
 func (c *Cache) Get(key string) (any, bool) {
 	c.mu.RLock()
 	item, found := c.items[key]
-	if !found || item.IsExpired() {
-		c.mu.RUnlock()
+	c.mu.RUnlock()
+	if !found {
+		return nil, false
+	}
+	if item.IsExpired() {
+		c.mu.Lock()
+		// the item may have been replaced since it was read
+		if item, found := c.items[key]; found && item.IsExpired() {
+			c.delete(key, item)
+		}
+		c.mu.Unlock()
 		return nil, false
 	}
-	c.mu.RUnlock()
-
 	return item.Value, true
 }
 
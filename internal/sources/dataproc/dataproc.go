@@ -27,6 +27,7 @@ import (
 	"github.com/goccy/go-yaml"
 	"github.com/googleapis/genai-toolbox/internal/sources"
 	"github.com/googleapis/genai-toolbox/internal/util"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
@@ -125,10 +126,7 @@ func (s *Source) Close() error {
 }
 
 // ListClustersResponse is the response from the list clusters API.
-type ListClustersResponse struct {
-	Clusters      []Cluster `json:"clusters"`
-	NextPageToken string    `json:"nextPageToken"`
-}
+type ListClustersResponse = parameters.Page[Cluster]
 
 // Cluster represents a single Dataproc cluster.
 type Cluster struct {
@@ -141,7 +139,7 @@ type Cluster struct {
 }
 
 // ListClusters executes the list clusters operation.
-func (s *Source) ListClusters(ctx context.Context, pageSize *int, pageToken, filter string) (any, error) {
+func (s *Source) ListClusters(ctx context.Context, page parameters.PageRequest, filter string) (any, error) {
 	client := s.GetClusterControllerClient()
 
 	req := &dataprocpb.ListClustersRequest{
@@ -149,22 +147,14 @@ func (s *Source) ListClusters(ctx context.Context, pageSize *int, pageToken, fil
 		Region:    s.Region,
 	}
 
-	if pageSize != nil {
-		req.PageSize = int32(*pageSize)
-	}
-	if pageToken != "" {
-		req.PageToken = pageToken
-	}
+	req.PageSize = int32(page.Size)
+	req.PageToken = page.Token
 	if filter != "" {
 		req.Filter = filter
 	}
 
 	it := client.ListClusters(ctx, req)
-	ps := 0
-	if pageSize != nil {
-		ps = *pageSize
-	}
-	pager := iterator.NewPager(it, ps, req.PageToken)
+	pager := iterator.NewPager(it, page.Size, req.PageToken)
 
 	var clusterPbs []*dataprocpb.Cluster
 	nextPageToken, err := pager.NextPage(&clusterPbs)
@@ -177,7 +167,7 @@ func (s *Source) ListClusters(ctx context.Context, pageSize *int, pageToken, fil
 		return nil, err
 	}
 
-	return ListClustersResponse{Clusters: clusters, NextPageToken: nextPageToken}, nil
+	return parameters.NewPage(clusters, nextPageToken), nil
 }
 
 // ToClusters converts a slice of protobuf Cluster messages to a slice of Cluster structs.
@@ -250,10 +240,7 @@ func (s *Source) GetCluster(ctx context.Context, clusterName string) (any, error
 }
 
 // ListJobsResponse is the response from the list jobs API.
-type ListJobsResponse struct {
-	Jobs          []Job  `json:"jobs"`
-	NextPageToken string `json:"nextPageToken"`
-}
+type ListJobsResponse = parameters.Page[Job]
 
 // Job represents a single Dataproc job.
 type Job struct {
@@ -268,7 +255,7 @@ type Job struct {
 }
 
 // ListJobs executes the list jobs operation.
-func (s *Source) ListJobs(ctx context.Context, pageSize *int, pageToken, filter, jobStateMatcher string) (any, error) {
+func (s *Source) ListJobs(ctx context.Context, page parameters.PageRequest, filter, jobStateMatcher string) (any, error) {
 	client := s.GetJobControllerClient()
 
 	req := &dataprocpb.ListJobsRequest{
@@ -276,12 +263,8 @@ func (s *Source) ListJobs(ctx context.Context, pageSize *int, pageToken, filter,
 		Region:    s.Region,
 	}
 
-	if pageSize != nil {
-		req.PageSize = int32(*pageSize)
-	}
-	if pageToken != "" {
-		req.PageToken = pageToken
-	}
+	req.PageSize = int32(page.Size)
+	req.PageToken = page.Token
 	if filter != "" {
 		req.Filter = filter
 	}
@@ -294,11 +277,7 @@ func (s *Source) ListJobs(ctx context.Context, pageSize *int, pageToken, filter,
 	}
 
 	it := client.ListJobs(ctx, req)
-	ps := 0
-	if pageSize != nil {
-		ps = *pageSize
-	}
-	pager := iterator.NewPager(it, ps, req.PageToken)
+	pager := iterator.NewPager(it, page.Size, req.PageToken)
 
 	var jobPbs []*dataprocpb.Job
 	nextPageToken, err := pager.NextPage(&jobPbs)
@@ -311,7 +290,7 @@ func (s *Source) ListJobs(ctx context.Context, pageSize *int, pageToken, filter,
 		return nil, err
 	}
 
-	return ListJobsResponse{Jobs: jobs, NextPageToken: nextPageToken}, nil
+	return parameters.NewPage(jobs, nextPageToken), nil
 }
 
 // ToJobs converts a slice of protobuf Job messages to a slice of Job structs.
@@ -0,0 +1,27 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sources
+
+import "context"
+
+// HealthChecker is implemented by sources that can check that they are able
+// to serve invocations, e.g. that their credentials obtain a token and their
+// API is reachable. The readiness endpoint of the server runs the checks.
+type HealthChecker interface {
+	// HealthCheck returns an error if the source cannot serve invocations.
+	// It should be cheap, as it is run periodically, and must return when
+	// ctx is done.
+	HealthCheck(ctx context.Context) error
+}
@@ -0,0 +1,174 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	yaml "github.com/goccy/go-yaml"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/semaphore"
+)
+
+// DefaultInvocationQueueTimeout is how long an invocation waits for a slot of
+// a source that limits its concurrent invocations, if the source does not set
+// invocationQueueTimeout.
+const DefaultInvocationQueueTimeout = 30 * time.Second
+
+// CommonOptions are the options available to every source type. They are set
+// by fields that are removed from a source's config before it is decoded for
+// the source's type.
+type CommonOptions struct {
+	// MaxConcurrentInvocations bounds the invocations of the tools of the
+	// source that run at the same time, if positive.
+	MaxConcurrentInvocations int64
+	// InvocationQueueTimeout is how long an invocation waits for a slot
+	// before it fails. Defaults to DefaultInvocationQueueTimeout.
+	InvocationQueueTimeout time.Duration
+}
+
+// CommonConfig is a SourceConfig with the options available to every source
+// type. Its sources are the sources of the config of the source's type, so
+// that tools can use them as is.
+type CommonConfig struct {
+	SourceConfig
+	CommonOptions
+}
+
+// WithCommonOptions returns a SourceConfig whose sources use opts.
+func WithCommonOptions(cfg SourceConfig, opts CommonOptions) SourceConfig {
+	return CommonConfig{SourceConfig: cfg, CommonOptions: opts}
+}
+
+// MarshalYAML marshals the config of the source's type with the fields of the
+// options that are set, so that the config decodes to c again.
+func (c CommonConfig) MarshalYAML() (any, error) {
+	b, err := yaml.Marshal(c.SourceConfig)
+	if err != nil {
+		return nil, err
+	}
+	var fields yaml.MapSlice
+	if err := yaml.UnmarshalWithOptions(b, &fields, yaml.UseOrderedMap()); err != nil {
+		return nil, err
+	}
+	if c.MaxConcurrentInvocations > 0 {
+		fields = append(fields, yaml.MapItem{Key: "maxConcurrentInvocations", Value: c.MaxConcurrentInvocations})
+	}
+	if c.InvocationQueueTimeout > 0 {
+		fields = append(fields, yaml.MapItem{Key: "invocationQueueTimeout", Value: c.InvocationQueueTimeout.String()})
+	}
+	return fields, nil
+}
+
+func (c CommonConfig) Initialize(ctx context.Context, tracer trace.Tracer) (Source, error) {
+	return c.SourceConfig.Initialize(ctx, tracer)
+}
+
+// GetCommonOptions returns the options available to every source type that
+// cfg sets.
+func GetCommonOptions(cfg SourceConfig) CommonOptions {
+	if c, ok := cfg.(CommonConfig); ok {
+		return c.CommonOptions
+	}
+	return CommonOptions{}
+}
+
+// UnwrapConfig returns the config of the source's type of cfg, which is the
+// config its sources return from ToConfig.
+func UnwrapConfig(cfg SourceConfig) SourceConfig {
+	if c, ok := cfg.(CommonConfig); ok {
+		return c.SourceConfig
+	}
+	return cfg
+}
+
+// ErrInvocationQueueTimeout is the error of an invocation that waited longer
+// than the invocation queue timeout of its source.
+var ErrInvocationQueueTimeout = errors.New("timed out waiting for a concurrent invocation slot")
+
+// Limiter bounds the concurrent invocations of the tools of a source.
+type Limiter struct {
+	sourceName string
+	opts       CommonOptions
+	sem        *semaphore.Weighted
+	inFlight   atomic.Int64
+	// inFlightMetric, if set, records the invocations in flight.
+	inFlightMetric metric.Int64UpDownCounter
+}
+
+// NewLimiter returns the Limiter of the source named sourceName, or nil if
+// opts do not limit its concurrent invocations. The invocations in flight are
+// recorded to inFlightMetric, if not nil.
+func NewLimiter(sourceName string, opts CommonOptions, inFlightMetric metric.Int64UpDownCounter) *Limiter {
+	if opts.MaxConcurrentInvocations <= 0 {
+		return nil
+	}
+	return &Limiter{
+		sourceName:     sourceName,
+		opts:           opts,
+		sem:            semaphore.NewWeighted(opts.MaxConcurrentInvocations),
+		inFlightMetric: inFlightMetric,
+	}
+}
+
+// Options returns the options the limiter enforces.
+func (l *Limiter) Options() CommonOptions {
+	return l.opts
+}
+
+// InFlight returns the number of invocations holding a slot.
+func (l *Limiter) InFlight() int64 {
+	return l.inFlight.Load()
+}
+
+// Acquire waits for a slot for an invocation, for at most the invocation queue
+// timeout of the source. The returned func releases the slot. It returns an
+// error wrapping ErrInvocationQueueTimeout if the timeout passes first, or the
+// error of ctx if it is done first.
+func (l *Limiter) Acquire(ctx context.Context) (func(), error) {
+	timeout := l.opts.InvocationQueueTimeout
+	if timeout <= 0 {
+		timeout = DefaultInvocationQueueTimeout
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := l.sem.Acquire(waitCtx, 1); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("source %q allows %d concurrent invocations: %w after %s", l.sourceName, l.opts.MaxConcurrentInvocations, ErrInvocationQueueTimeout, timeout)
+	}
+	l.add(ctx, 1)
+	var released atomic.Bool
+	return func() {
+		if released.CompareAndSwap(false, true) {
+			l.add(context.Background(), -1)
+			l.sem.Release(1)
+		}
+	}, nil
+}
+
+func (l *Limiter) add(ctx context.Context, n int64) {
+	l.inFlight.Add(n)
+	if l.inFlightMetric != nil {
+		l.inFlightMetric.Add(ctx, n, metric.WithAttributes(attribute.String("source_name", l.sourceName)))
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sources_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	yaml "github.com/goccy/go-yaml"
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/server"
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	"github.com/googleapis/genai-toolbox/internal/testutils"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestLimiterBoundsConcurrency(t *testing.T) {
+	limiter := sources.NewLimiter("my-source", sources.CommonOptions{MaxConcurrentInvocations: 2, InvocationQueueTimeout: time.Minute}, nil)
+	var running, maxRunning atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := limiter.Acquire(context.Background())
+			if err != nil {
+				t.Errorf("unexpected error: %s", err)
+				return
+			}
+			defer release()
+			n := running.Add(1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			if got := limiter.InFlight(); got > 2 {
+				t.Errorf("unexpected in-flight count: %d", got)
+			}
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+		}()
+	}
+	wg.Wait()
+	if got := maxRunning.Load(); got != 2 {
+		t.Fatalf("unexpected maximum of concurrent invocations: got %d, want 2", got)
+	}
+	if got := limiter.InFlight(); got != 0 {
+		t.Fatalf("expected no invocations in flight, got %d", got)
+	}
+}
+
+func TestLimiterQueueTimeout(t *testing.T) {
+	limiter := sources.NewLimiter("my-source", sources.CommonOptions{MaxConcurrentInvocations: 1, InvocationQueueTimeout: 10 * time.Millisecond}, nil)
+	release, err := limiter.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, err = limiter.Acquire(context.Background())
+	if !errors.Is(err, sources.ErrInvocationQueueTimeout) {
+		t.Fatalf("expected a queue timeout error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), `source "my-source" allows 1 concurrent invocations`) {
+		t.Fatalf("unexpected error message: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := limiter.Acquire(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the context error, got %v", err)
+	}
+
+	// releasing twice frees a single slot
+	release()
+	release()
+	if got := limiter.InFlight(); got != 0 {
+		t.Fatalf("expected no invocations in flight, got %d", got)
+	}
+	release, err = limiter.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error after release: %s", err)
+	}
+	defer release()
+	if _, err := limiter.Acquire(context.Background()); !errors.Is(err, sources.ErrInvocationQueueTimeout) {
+		t.Fatalf("expected a queue timeout error, got %v", err)
+	}
+}
+
+func TestNewLimiterWithoutLimit(t *testing.T) {
+	if l := sources.NewLimiter("my-source", sources.CommonOptions{}, nil); l != nil {
+		t.Fatalf("expected no limiter, got %v", l)
+	}
+}
+
+type limitTestConfig struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+}
+
+func (c limitTestConfig) SourceConfigType() string { return "limit-test" }
+func (c limitTestConfig) Initialize(context.Context, trace.Tracer) (sources.Source, error) {
+	return nil, nil
+}
+
+func TestCommonOptionsConfig(t *testing.T) {
+	sources.Register("limit-test", func(ctx context.Context, name string, decoder *yaml.Decoder) (sources.SourceConfig, error) {
+		actual := limitTestConfig{Name: name}
+		if err := decoder.DecodeContext(ctx, &actual); err != nil {
+			return nil, err
+		}
+		return actual, nil
+	})
+	ctx := context.Background()
+	base := limitTestConfig{Name: "my-source", Type: "limit-test"}
+	tcs := []struct {
+		desc    string
+		fields  string
+		want    sources.SourceConfig
+		wantErr string
+	}{
+		{
+			desc: "no options",
+			want: base,
+		},
+		{
+			desc:   "max concurrent invocations",
+			fields: "maxConcurrentInvocations: 4",
+			want:   sources.CommonConfig{SourceConfig: base, CommonOptions: sources.CommonOptions{MaxConcurrentInvocations: 4}},
+		},
+		{
+			desc:   "with queue timeout",
+			fields: "maxConcurrentInvocations: 4\ninvocationQueueTimeout: 5s",
+			want:   sources.CommonConfig{SourceConfig: base, CommonOptions: sources.CommonOptions{MaxConcurrentInvocations: 4, InvocationQueueTimeout: 5 * time.Second}},
+		},
+		{
+			desc:    "zero max concurrent invocations",
+			fields:  "maxConcurrentInvocations: 0",
+			wantErr: "'maxConcurrentInvocations' must be a positive integer",
+		},
+		{
+			desc:    "queue timeout without limit",
+			fields:  "invocationQueueTimeout: 5s",
+			wantErr: "'invocationQueueTimeout' requires 'maxConcurrentInvocations'",
+		},
+		{
+			desc:    "invalid queue timeout",
+			fields:  "maxConcurrentInvocations: 4\ninvocationQueueTimeout: soon",
+			wantErr: `invalid 'invocationQueueTimeout' "soon"`,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			in := "kind: sources\nname: my-source\ntype: limit-test\n" + tc.fields
+			got, _, _, _, _, _, err := server.UnmarshalResourceConfig(ctx, testutils.FormatYaml(in))
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unable to unmarshal: %s", err)
+			}
+			if diff := cmp.Diff(server.SourceConfigs{"my-source": tc.want}, got); diff != "" {
+				t.Fatalf("incorrect parse (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(base, sources.UnwrapConfig(got["my-source"])); diff != "" {
+				t.Fatalf("unexpected unwrapped config (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/goccy/go-yaml"
 	"github.com/googleapis/genai-toolbox/internal/sources"
 	"github.com/googleapis/genai-toolbox/internal/util"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
@@ -175,10 +176,7 @@ func (s *Source) CreateBatch(ctx context.Context, batch *dataprocpb.Batch) (map[
 }
 
 // ListBatchesResponse is the response from the list batches API.
-type ListBatchesResponse struct {
-	Batches       []Batch `json:"batches"`
-	NextPageToken string  `json:"nextPageToken"`
-}
+type ListBatchesResponse = parameters.Page[Batch]
 
 // Batch represents a single batch job.
 type Batch struct {
@@ -192,7 +190,7 @@ type Batch struct {
 	LogsURL    string `json:"logsUrl"`
 }
 
-func (s *Source) ListBatches(ctx context.Context, ps *int, pt, filter string) (any, error) {
+func (s *Source) ListBatches(ctx context.Context, page parameters.PageRequest, filter string) (any, error) {
 	client := s.GetBatchControllerClient()
 	parent := fmt.Sprintf("projects/%s/locations/%s", s.GetProject(), s.GetLocation())
 	req := &dataprocpb.ListBatchesRequest{
@@ -200,12 +198,8 @@ func (s *Source) ListBatches(ctx context.Context, ps *int, pt, filter string) (a
 		OrderBy: "create_time desc",
 	}
 
-	if ps != nil {
-		req.PageSize = int32(*ps)
-	}
-	if pt != "" {
-		req.PageToken = pt
-	}
+	req.PageSize = int32(page.Size)
+	req.PageToken = page.Token
 	if filter != "" {
 		req.Filter = filter
 	}
@@ -224,7 +218,7 @@ func (s *Source) ListBatches(ctx context.Context, ps *int, pt, filter string) (a
 		return nil, err
 	}
 
-	return ListBatchesResponse{Batches: batches, NextPageToken: nextPageToken}, nil
+	return parameters.NewPage(batches, nextPageToken), nil
 }
 
 // ToBatches converts a slice of protobuf Batch messages to a slice of Batch structs.
@@ -59,6 +59,15 @@ type SourceConfig interface {
 	Initialize(ctx context.Context, tracer trace.Tracer) (Source, error)
 }
 
+// SourceConfigWithFields is implemented by source configs that declare fields
+// a policy can set, such as `allowedDatasets`. The server only sets the fields
+// of a policy that are listed by DeclaredFields.
+type SourceConfigWithFields interface {
+	SourceConfig
+	// DeclaredFields returns the YAML names of these fields.
+	DeclaredFields() []string
+}
+
 // Source is the interface for the source itself.
 type Source interface {
 	SourceType() string
@@ -31,6 +31,10 @@ const (
 	toolInvokeCountName = "toolbox.server.tool.invoke.count"
 	mcpSseCountName     = "toolbox.server.mcp.sse.count"
 	mcpPostCountName    = "toolbox.server.mcp.post.count"
+
+	embeddingCacheLookupCountName = "toolbox.server.embedding.cache.lookup.count"
+
+	sourceInvocationsInFlightName = "toolbox.server.source.invocations.inflight"
 )
 
 // Instrumentation defines the telemetry instrumentation for toolbox
@@ -42,6 +46,25 @@ type Instrumentation struct {
 	ToolInvoke metric.Int64Counter
 	McpSse     metric.Int64Counter
 	McpPost    metric.Int64Counter
+	// EmbeddingCacheLookup counts embedding cache lookups. Its "hit"
+	// attribute gives the hit rate.
+	EmbeddingCacheLookup metric.Int64Counter
+	// SourceInvocationsInFlight is the number of invocations of the tools of
+	// sources that limit their concurrent invocations, by "source_name".
+	SourceInvocationsInFlight metric.Int64UpDownCounter
+}
+
+// Tracer returns the tracer of toolbox from the global tracer provider, for
+// spans started where the Instrumentation is not at hand, such as in tools.
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// Meter returns the meter of toolbox from the global meter provider, for
+// metrics recorded where the Instrumentation is not at hand, such as in
+// sources.
+func Meter() metric.Meter {
+	return otel.Meter(MetricName)
 }
 
 func CreateTelemetryInstrumentation(versionString string) (*Instrumentation, error) {
@@ -96,6 +119,24 @@ func CreateTelemetryInstrumentation(versionString string) (*Instrumentation, err
 		return nil, fmt.Errorf("unable to create %s metric: %w", mcpPostCountName, err)
 	}
 
+	embeddingCacheLookup, err := meter.Int64Counter(
+		embeddingCacheLookupCountName,
+		metric.WithDescription("Number of embedding cache lookups."),
+		metric.WithUnit("{lookup}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create %s metric: %w", embeddingCacheLookupCountName, err)
+	}
+
+	sourceInvocationsInFlight, err := meter.Int64UpDownCounter(
+		sourceInvocationsInFlightName,
+		metric.WithDescription("Number of in-flight tool invocations of sources that limit concurrent invocations."),
+		metric.WithUnit("{invocation}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create %s metric: %w", sourceInvocationsInFlightName, err)
+	}
+
 	instrumentation := &Instrumentation{
 		Tracer:     tracer,
 		meter:      meter,
@@ -104,6 +145,9 @@ func CreateTelemetryInstrumentation(versionString string) (*Instrumentation, err
 		ToolInvoke: toolInvoke,
 		McpSse:     mcpSse,
 		McpPost:    mcpPost,
+
+		EmbeddingCacheLookup:      embeddingCacheLookup,
+		SourceInvocationsInFlight: sourceInvocationsInFlight,
 	}
 	return instrumentation, nil
 }
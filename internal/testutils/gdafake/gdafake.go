@@ -0,0 +1,545 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gdafake is a fake of the Gemini Data Analytics API for the tests of
+// the tools that call it. It serves data agents and conversations kept in
+// memory, and canned chat message streams, records the requests it receives,
+// and can be told to fail or slow down requests.
+//
+// Tests point the tool at the fake by setting the base URL of the API to
+// Server.URL:
+//
+//	fake := gdafake.New(t)
+//	fake.AddDataAgent("projects/p/locations/global/dataAgents/a", nil)
+//	gdaBaseURL = fake.URL
+package gdafake
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Request is a request received by the fake.
+type Request struct {
+	Method string
+	// Path is the path of the request, e.g.
+	// "/projects/p/locations/global/dataAgents/a".
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// Fault makes the fake fail or slow down requests instead of serving them.
+type Fault struct {
+	// Method and PathSuffix select the requests of the fault. Empty values
+	// select requests of any method or path.
+	Method     string
+	PathSuffix string
+	// Times is the number of requests the fault applies to. Zero applies it
+	// to every selected request.
+	Times int
+	// Delay is waited before responding, or until the request is canceled.
+	Delay time.Duration
+	// Status is the status of the response. Zero serves the request after
+	// Delay, to simulate a slow response.
+	Status int
+	// RetryAfter is sent as the Retry-After header of the response, if set.
+	RetryAfter string
+	// Body is the body of the response. It defaults to an error of Status in
+	// the format of Google APIs.
+	Body string
+}
+
+func (f *Fault) matches(r *http.Request) bool {
+	return (f.Method == "" || f.Method == r.Method) && strings.HasSuffix(r.URL.Path, f.PathSuffix)
+}
+
+// Server is a fake Gemini Data Analytics API. Its methods are safe for
+// concurrent use.
+type Server struct {
+	// URL is the base URL of the fake, to use instead of
+	// "https://geminidataanalytics.googleapis.com/v1beta".
+	URL string
+
+	mu            sync.Mutex
+	agents        map[string]map[string]any
+	policies      map[string]map[string]any
+	conversations map[string]map[string]any
+	messages      map[string][]map[string]any
+	chat          []any
+	faults        []*Fault
+	requests      []Request
+	operations    int
+	pageSize      int
+}
+
+// New starts a fake, which is closed at the end of the test.
+func New(t testing.TB) *Server {
+	t.Helper()
+	s := &Server{
+		agents:        make(map[string]map[string]any),
+		policies:      make(map[string]map[string]any),
+		conversations: make(map[string]map[string]any),
+		messages:      make(map[string][]map[string]any),
+		pageSize:      50,
+	}
+	server := httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(server.Close)
+	s.URL = server.URL
+	return s
+}
+
+// AddDataAgent adds or replaces the data agent with the resource name name,
+// "projects/P/locations/L/dataAgents/A". Its "name" field is set to name.
+func (s *Server) AddDataAgent(name string, agent map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.agents[name] = withName(agent, name)
+}
+
+// DataAgent returns the data agent with the resource name name, e.g. to check
+// the agents created or updated by a tool.
+func (s *Server) DataAgent(name string) (map[string]any, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	agent, ok := s.agents[name]
+	if !ok {
+		return nil, false
+	}
+	return cloneJSON(agent), true
+}
+
+// AddConversation adds or replaces the conversation with the resource name
+// name, "projects/P/locations/L/conversations/C", and its messages.
+func (s *Server) AddConversation(name string, conversation map[string]any, messages ...map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.conversations[name] = withName(conversation, name)
+	s.messages[name] = messages
+}
+
+// SetChatMessages sets the messages streamed in response to chat requests.
+func (s *Server) SetChatMessages(messages ...any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chat = messages
+}
+
+// SetPageSize sets the maximum number of items of the pages of list
+// responses, which defaults to 50. The pageSize of requests lowers it.
+func (s *Server) SetPageSize(size int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pageSize = size
+}
+
+// Inject adds a fault. Faults apply in the order they are added.
+func (s *Server) Inject(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// Requests returns the requests received so far, including failed ones.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.requests)
+}
+
+// Paths returns the paths of the requests received so far.
+func (s *Server) Paths() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	paths := make([]string, len(s.requests))
+	for i, r := range s.requests {
+		paths[i] = r.Path
+	}
+	return paths
+}
+
+// ResetRequests forgets the requests received so far.
+func (s *Server) ResetRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+}
+
+var (
+	dataAgentPath       = regexp.MustCompile(`^/(projects/[^/]+/locations/[^/]+/dataAgents/[^/:]+)(?::(\w+))?$`)
+	dataAgentsPath      = regexp.MustCompile(`^/(projects/[^/]+/locations/[^/]+)/dataAgents$`)
+	conversationPath    = regexp.MustCompile(`^/(projects/[^/]+/locations/[^/]+/conversations/[^/]+)$`)
+	conversationsPath   = regexp.MustCompile(`^/(projects/[^/]+/locations/[^/]+)/conversations$`)
+	messagesPath        = regexp.MustCompile(`^/(projects/[^/]+/locations/[^/]+/conversations/[^/]+)/messages$`)
+	chatPath            = regexp.MustCompile(`^/projects/[^/]+/locations/[^/]+:chat$`)
+	errorStatusByStatus = map[int]string{
+		http.StatusBadRequest:          "INVALID_ARGUMENT",
+		http.StatusUnauthorized:        "UNAUTHENTICATED",
+		http.StatusForbidden:           "PERMISSION_DENIED",
+		http.StatusNotFound:            "NOT_FOUND",
+		http.StatusConflict:            "ALREADY_EXISTS",
+		http.StatusTooManyRequests:     "RESOURCE_EXHAUSTED",
+		http.StatusInternalServerError: "INTERNAL",
+		http.StatusServiceUnavailable:  "UNAVAILABLE",
+	}
+)
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Query: r.URL.Query(), Header: r.Header.Clone(), Body: body})
+	fault := s.fault(r)
+	s.mu.Unlock()
+
+	if fault != nil {
+		if fault.Delay > 0 {
+			select {
+			case <-time.After(fault.Delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if fault.Status != 0 {
+			if fault.RetryAfter != "" {
+				w.Header().Set("Retry-After", fault.RetryAfter)
+			}
+			if fault.Body != "" {
+				w.WriteHeader(fault.Status)
+				_, _ = io.WriteString(w, fault.Body)
+				return
+			}
+			writeError(w, fault.Status, http.StatusText(fault.Status))
+			return
+		}
+	}
+
+	path := r.URL.Path
+	switch {
+	case r.Method == http.MethodPost && chatPath.MatchString(path):
+		s.serveChat(w)
+	case dataAgentPath.MatchString(path):
+		m := dataAgentPath.FindStringSubmatch(path)
+		s.serveDataAgent(w, r, m[1], m[2], body)
+	case r.Method == http.MethodGet && dataAgentsPath.MatchString(path):
+		s.serveList(w, r, "dataAgents", dataAgentsPath.FindStringSubmatch(path)[1]+"/dataAgents/", s.agents)
+	case r.Method == http.MethodPost && dataAgentsPath.MatchString(path):
+		s.createDataAgent(w, r, dataAgentsPath.FindStringSubmatch(path)[1], body)
+	case r.Method == http.MethodGet && conversationPath.MatchString(path):
+		s.serveGet(w, conversationPath.FindStringSubmatch(path)[1], s.conversations)
+	case r.Method == http.MethodGet && conversationsPath.MatchString(path):
+		s.serveList(w, r, "conversations", conversationsPath.FindStringSubmatch(path)[1]+"/conversations/", s.conversations)
+	case r.Method == http.MethodGet && messagesPath.MatchString(path):
+		s.serveMessages(w, r, messagesPath.FindStringSubmatch(path)[1])
+	default:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("%s %s is not served by the fake", r.Method, path))
+	}
+}
+
+// fault returns the fault of r, if any, counting it against its Times. The
+// lock must be held.
+func (s *Server) fault(r *http.Request) *Fault {
+	for i, f := range s.faults {
+		if !f.matches(r) {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = slices.Delete(s.faults, i, i+1)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+func (s *Server) serveChat(w http.ResponseWriter) {
+	s.mu.Lock()
+	messages := slices.Clone(s.chat)
+	s.mu.Unlock()
+
+	// The messages are streamed as a JSON array, one message at a time.
+	w.Header().Set("Content-Type", "application/json")
+	flusher, _ := w.(http.Flusher)
+	_, _ = io.WriteString(w, "[")
+	for i, msg := range messages {
+		if i > 0 {
+			_, _ = io.WriteString(w, ",\n")
+		}
+		b, err := json.Marshal(msg)
+		if err != nil {
+			panic(fmt.Sprintf("gdafake: unable to marshal chat message %d: %s", i, err))
+		}
+		_, _ = w.Write(b)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	_, _ = io.WriteString(w, "]")
+}
+
+func (s *Server) serveDataAgent(w http.ResponseWriter, r *http.Request, name, method string, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	agent, ok := s.agents[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("data agent %q not found", name))
+		return
+	}
+	switch {
+	case method == "" && r.Method == http.MethodGet:
+		writeJSON(w, agent)
+	case method == "" && r.Method == http.MethodPatch:
+		var update map[string]any
+		if err := json.Unmarshal(body, &update); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		mask := r.URL.Query().Get("updateMask")
+		if mask == "" {
+			writeError(w, http.StatusBadRequest, "updateMask is required")
+			return
+		}
+		updated := cloneJSON(agent)
+		for _, path := range strings.Split(mask, ",") {
+			path = camelCase(path)
+			if value, ok := lookup(update, path); ok {
+				set(updated, path, value)
+			}
+		}
+		s.agents[name] = updated
+		writeJSON(w, s.operation(name, updated))
+	case method == "" && r.Method == http.MethodDelete:
+		delete(s.agents, name)
+		delete(s.policies, name)
+		writeJSON(w, s.operation(name, map[string]any{}))
+	case method == "getIamPolicy" && r.Method == http.MethodPost:
+		policy, ok := s.policies[name]
+		if !ok {
+			policy = map[string]any{"etag": "BwAAAA=="}
+		}
+		writeJSON(w, policy)
+	case method == "setIamPolicy" && r.Method == http.MethodPost:
+		var req struct {
+			Policy map[string]any `json:"policy"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil || req.Policy == nil {
+			writeError(w, http.StatusBadRequest, "policy is required")
+			return
+		}
+		s.policies[name] = req.Policy
+		writeJSON(w, req.Policy)
+	case method == "testIamPermissions" && r.Method == http.MethodPost:
+		// Every permission is granted.
+		var req struct {
+			Permissions []string `json:"permissions"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, map[string]any{"permissions": req.Permissions})
+	default:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("%s %s is not served by the fake", r.Method, r.URL.Path))
+	}
+}
+
+func (s *Server) createDataAgent(w http.ResponseWriter, r *http.Request, parent string, body []byte) {
+	id := r.URL.Query().Get("dataAgentId")
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "dataAgentId is required")
+		return
+	}
+	var agent map[string]any
+	if err := json.Unmarshal(body, &agent); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	name := parent + "/dataAgents/" + id
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.agents[name]; ok {
+		writeError(w, http.StatusConflict, fmt.Sprintf("data agent %q already exists", name))
+		return
+	}
+	s.agents[name] = withName(agent, name)
+	writeJSON(w, s.operation(name, s.agents[name]))
+}
+
+// operation returns a completed long-running operation of the resource name
+// with the response response. The lock must be held.
+func (s *Server) operation(name string, response map[string]any) map[string]any {
+	s.operations++
+	location := name[:strings.Index(name, "/dataAgents/")]
+	return map[string]any{
+		"name":     fmt.Sprintf("%s/operations/operation-%d", location, s.operations),
+		"done":     true,
+		"response": response,
+	}
+}
+
+func (s *Server) serveGet(w http.ResponseWriter, name string, resources map[string]map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	resource, ok := resources[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("%q not found", name))
+		return
+	}
+	writeJSON(w, resource)
+}
+
+// serveList serves the page of the resources whose names start with prefix,
+// sorted by name, as the field field of the response.
+func (s *Server) serveList(w http.ResponseWriter, r *http.Request, field, prefix string, resources map[string]map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var names []string
+	for name := range resources {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	items := make([]map[string]any, len(names))
+	for i, name := range names {
+		items[i] = resources[name]
+	}
+	s.writePage(w, r, field, items)
+}
+
+func (s *Server) serveMessages(w http.ResponseWriter, r *http.Request, conversation string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.conversations[conversation]; !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("conversation %q not found", conversation))
+		return
+	}
+	s.writePage(w, r, "messages", s.messages[conversation])
+}
+
+// writePage writes the page of items requested by the pageSize and pageToken
+// of r. Page tokens are the offsets of the pages. The lock must be held.
+func (s *Server) writePage(w http.ResponseWriter, r *http.Request, field string, items []map[string]any) {
+	size := s.pageSize
+	if requested, err := strconv.Atoi(r.URL.Query().Get("pageSize")); err == nil && requested > 0 && requested < size {
+		size = requested
+	}
+	offset := 0
+	if token := r.URL.Query().Get("pageToken"); token != "" {
+		var err error
+		if offset, err = strconv.Atoi(token); err != nil || offset < 0 || offset > len(items) {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid page token %q", token))
+			return
+		}
+	}
+	end := min(offset+size, len(items))
+	resp := map[string]any{field: items[offset:end]}
+	if end < len(items) {
+		resp["nextPageToken"] = strconv.Itoa(end)
+	}
+	writeJSON(w, resp)
+}
+
+func withName(resource map[string]any, name string) map[string]any {
+	resource = cloneJSON(resource)
+	resource["name"] = name
+	return resource
+}
+
+// cloneJSON deep copies a JSON object, so that the resources of the fake are
+// not shared with tests.
+func cloneJSON(v map[string]any) map[string]any {
+	if v == nil {
+		return map[string]any{}
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("gdafake: resource is not a JSON object: %s", err))
+	}
+	var clone map[string]any
+	if err := json.Unmarshal(b, &clone); err != nil {
+		panic(fmt.Sprintf("gdafake: resource is not a JSON object: %s", err))
+	}
+	return clone
+}
+
+// camelCase returns the field mask path, whose fields are in snake case, e.g.
+// "data_analytics_agent.published_context", with the fields in lower camel
+// case, as in JSON.
+func camelCase(path string) string {
+	parts := strings.Split(path, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// lookup returns the value at the dotted path of the field mask path in v.
+func lookup(v map[string]any, path string) (any, bool) {
+	first, rest, nested := strings.Cut(path, ".")
+	value, ok := v[first]
+	if !ok || !nested {
+		return value, ok
+	}
+	child, ok := value.(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	return lookup(child, rest)
+}
+
+// set sets the value at the dotted path of the field mask path in v.
+func set(v map[string]any, path string, value any) {
+	first, rest, nested := strings.Cut(path, ".")
+	if !nested {
+		v[first] = value
+		return
+	}
+	child, ok := v[first].(map[string]any)
+	if !ok {
+		child = map[string]any{}
+		v[first] = child
+	}
+	set(child, rest, value)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		panic(fmt.Sprintf("gdafake: unable to encode response: %s", err))
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"error": map[string]any{"code": status, "message": message, "status": errorStatusByStatus[status]},
+	})
+}
@@ -0,0 +1,199 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gdafake_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/testutils/gdafake"
+)
+
+const agentName = "projects/p/locations/global/dataAgents/a"
+
+// do sends a request to the fake, and returns the status and decoded body of
+// its response.
+func do(t *testing.T, fake *gdafake.Server, method, path, body string) (int, map[string]any) {
+	t.Helper()
+	req, err := http.NewRequest(method, fake.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("unable to create request: %s", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unable to send request: %s", err)
+	}
+	defer resp.Body.Close()
+	var got map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil && !errors.Is(err, io.EOF) {
+		t.Fatalf("unable to decode response: %s", err)
+	}
+	return resp.StatusCode, got
+}
+
+func TestDataAgents(t *testing.T) {
+	fake := gdafake.New(t)
+	fake.AddDataAgent(agentName, map[string]any{"description": "sales"})
+
+	status, got := do(t, fake, http.MethodGet, "/"+agentName, "")
+	if status != http.StatusOK || got["name"] != agentName || got["description"] != "sales" {
+		t.Fatalf("unexpected response %d: %v", status, got)
+	}
+	if status, got := do(t, fake, http.MethodGet, "/projects/p/locations/global/dataAgents/missing", ""); status != http.StatusNotFound || got["error"].(map[string]any)["status"] != "NOT_FOUND" {
+		t.Fatalf("unexpected response %d: %v", status, got)
+	}
+
+	status, got = do(t, fake, http.MethodPost, "/projects/p/locations/global/dataAgents?dataAgentId=b", `{"description": "logs"}`)
+	if status != http.StatusOK || got["done"] != true {
+		t.Fatalf("unexpected response %d: %v", status, got)
+	}
+	created, ok := fake.DataAgent("projects/p/locations/global/dataAgents/b")
+	if !ok || created["description"] != "logs" {
+		t.Fatalf("expected the data agent to be created, got %v", created)
+	}
+	if status, _ := do(t, fake, http.MethodPost, "/projects/p/locations/global/dataAgents?dataAgentId=b", `{}`); status != http.StatusConflict {
+		t.Fatalf("expected a conflict for an existing agent, got %d", status)
+	}
+
+	body := `{"description": "ignored", "dataAnalyticsAgent": {"publishedContext": {"systemInstruction": "Be brief."}}}`
+	if status, _ := do(t, fake, http.MethodPatch, "/"+agentName+"?updateMask=data_analytics_agent.published_context.system_instruction", body); status != http.StatusOK {
+		t.Fatalf("unexpected status %d", status)
+	}
+	updated, _ := fake.DataAgent(agentName)
+	want := map[string]any{
+		"name":               agentName,
+		"description":        "sales",
+		"dataAnalyticsAgent": map[string]any{"publishedContext": map[string]any{"systemInstruction": "Be brief."}},
+	}
+	if diff := cmp.Diff(want, updated); diff != "" {
+		t.Fatalf("unexpected updated agent (-want +got):\n%s", diff)
+	}
+
+	fake.SetPageSize(1)
+	status, got = do(t, fake, http.MethodGet, "/projects/p/locations/global/dataAgents", "")
+	if status != http.StatusOK || len(got["dataAgents"].([]any)) != 1 || got["nextPageToken"] != "1" {
+		t.Fatalf("unexpected first page %d: %v", status, got)
+	}
+	status, got = do(t, fake, http.MethodGet, "/projects/p/locations/global/dataAgents?pageToken=1", "")
+	if status != http.StatusOK || got["dataAgents"].([]any)[0].(map[string]any)["name"] != "projects/p/locations/global/dataAgents/b" || got["nextPageToken"] != nil {
+		t.Fatalf("unexpected last page %d: %v", status, got)
+	}
+
+	status, got = do(t, fake, http.MethodPost, "/"+agentName+":testIamPermissions", `{"permissions": ["geminidataanalytics.dataAgents.get"]}`)
+	if status != http.StatusOK || len(got["permissions"].([]any)) != 1 {
+		t.Fatalf("unexpected response %d: %v", status, got)
+	}
+}
+
+func TestConversations(t *testing.T) {
+	fake := gdafake.New(t)
+	name := "projects/p/locations/global/conversations/c"
+	fake.AddConversation(name, nil, map[string]any{"userMessage": map[string]any{"text": "Hi"}}, map[string]any{"systemMessage": map[string]any{}})
+
+	status, got := do(t, fake, http.MethodGet, "/"+name+"/messages?pageSize=1", "")
+	if status != http.StatusOK || len(got["messages"].([]any)) != 1 || got["nextPageToken"] != "1" {
+		t.Fatalf("unexpected first page %d: %v", status, got)
+	}
+	status, got = do(t, fake, http.MethodGet, "/"+name+"/messages?pageSize=1&pageToken=1", "")
+	if status != http.StatusOK || len(got["messages"].([]any)) != 1 || got["nextPageToken"] != nil {
+		t.Fatalf("unexpected last page %d: %v", status, got)
+	}
+	if status, _ := do(t, fake, http.MethodGet, "/projects/p/locations/global/conversations/missing/messages", ""); status != http.StatusNotFound {
+		t.Fatalf("expected a missing conversation to be not found, got %d", status)
+	}
+}
+
+func TestChat(t *testing.T) {
+	fake := gdafake.New(t)
+	fake.SetChatMessages(
+		map[string]any{"systemMessage": map[string]any{"text": map[string]any{"parts": []string{"Hello"}}}},
+		map[string]any{"systemMessage": map[string]any{"text": map[string]any{"parts": []string{"World"}}}},
+	)
+	resp, err := http.Post(fake.URL+"/projects/p/locations/us:chat", "application/json", strings.NewReader(`{"project": "projects/p"}`))
+	if err != nil {
+		t.Fatalf("unable to send request: %s", err)
+	}
+	defer resp.Body.Close()
+	var got []map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("unable to decode the stream: %s", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 messages, got %v", got)
+	}
+
+	reqs := fake.Requests()
+	if len(reqs) != 1 || reqs[0].Method != http.MethodPost || string(reqs[0].Body) != `{"project": "projects/p"}` || reqs[0].Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected captured requests: %+v", reqs)
+	}
+	fake.ResetRequests()
+	if len(fake.Paths()) != 0 {
+		t.Fatalf("expected the requests to be reset")
+	}
+}
+
+func TestFaults(t *testing.T) {
+	fake := gdafake.New(t)
+	fake.AddDataAgent(agentName, nil)
+	fake.Inject(gdafake.Fault{PathSuffix: "/dataAgents/a", Times: 2, Status: http.StatusTooManyRequests, RetryAfter: "1"})
+
+	for i := range 2 {
+		resp, err := http.Get(fake.URL + "/" + agentName)
+		if err != nil {
+			t.Fatalf("unable to send request: %s", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "1" {
+			t.Fatalf("request %d: expected a throttled response, got %d %v", i, resp.StatusCode, resp.Header)
+		}
+	}
+	if status, _ := do(t, fake, http.MethodGet, "/"+agentName, ""); status != http.StatusOK {
+		t.Fatalf("expected the fault to stop after 2 requests, got %d", status)
+	}
+
+	fake.Inject(gdafake.Fault{Method: http.MethodPost, Status: http.StatusServiceUnavailable, Body: "down"})
+	if status, _ := do(t, fake, http.MethodGet, "/"+agentName, ""); status != http.StatusOK {
+		t.Fatalf("expected the fault not to apply to other methods, got %d", status)
+	}
+	resp, err := http.Post(fake.URL+"/projects/p/locations/us:chat", "application/json", nil)
+	if err != nil {
+		t.Fatalf("unable to send request: %s", err)
+	}
+	b, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || string(b) != "down" {
+		t.Fatalf("unexpected response %d %q", resp.StatusCode, b)
+	}
+}
+
+func TestSlowResponses(t *testing.T) {
+	fake := gdafake.New(t)
+	fake.AddDataAgent(agentName, nil)
+	fake.Inject(gdafake.Fault{Delay: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, fake.URL+"/"+agentName, nil)
+	if _, err := http.DefaultClient.Do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the request to time out, got %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// schemaURL is the URL the schemas under test are compiled at.
+const schemaURL = "toolbox://schema.json"
+
+// ValidateJSONSchema checks that schema, once marshaled to JSON, is a valid
+// JSON Schema draft-07 schema. Beyond the meta-schema, the default, enum
+// values and examples of the schema and of each of its subschemas must be
+// valid against the subschema declaring them.
+func ValidateJSONSchema(schema any) error {
+	c, doc, err := newSchemaCompiler(schema)
+	if err != nil {
+		return err
+	}
+	if _, err := c.Compile(schemaURL); err != nil {
+		return fmt.Errorf("schema is not a valid draft-07 schema: %w", err)
+	}
+	return checkAnnotations(c, doc, "")
+}
+
+// ValidateJSONValue checks that value, once marshaled to JSON, is valid
+// against the draft-07 schema.
+func ValidateJSONValue(schema, value any) error {
+	c, _, err := newSchemaCompiler(schema)
+	if err != nil {
+		return err
+	}
+	s, err := c.Compile(schemaURL)
+	if err != nil {
+		return fmt.Errorf("schema is not a valid draft-07 schema: %w", err)
+	}
+	doc, err := toJSONValue(value)
+	if err != nil {
+		return err
+	}
+	return s.Validate(doc)
+}
+
+// newSchemaCompiler returns a compiler of draft-07 schemas with schema added
+// at schemaURL, and schema as decoded from its JSON encoding.
+func newSchemaCompiler(schema any) (*jsonschema.Compiler, any, error) {
+	doc, err := toJSONValue(schema)
+	if err != nil {
+		return nil, nil, err
+	}
+	c := jsonschema.NewCompiler()
+	c.DefaultDraft(jsonschema.Draft7)
+	if err := c.AddResource(schemaURL, doc); err != nil {
+		return nil, nil, fmt.Errorf("unable to add schema: %w", err)
+	}
+	return c, doc, nil
+}
+
+// toJSONValue returns v as decoded from its JSON encoding.
+func toJSONValue(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal to JSON: %w", err)
+	}
+	out, err := jsonschema.UnmarshalJSON(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("unable to unmarshal JSON: %w", err)
+	}
+	return out, nil
+}
+
+// checkAnnotations checks the default, enum values and examples of schema,
+// the subschema at the JSON pointer ptr of the schema compiled by c, and of
+// its subschemas against the subschema declaring them.
+func checkAnnotations(c *jsonschema.Compiler, schema any, ptr string) error {
+	s, ok := schema.(map[string]any)
+	if !ok {
+		return nil
+	}
+	var values []any
+	var names []string
+	if d, ok := s["default"]; ok {
+		values = append(values, d)
+		names = append(names, "default")
+	}
+	enum, _ := s["enum"].([]any)
+	for i, v := range enum {
+		values = append(values, v)
+		names = append(names, fmt.Sprintf("enum value %d", i))
+	}
+	examples, _ := s["examples"].([]any)
+	for i, v := range examples {
+		values = append(values, v)
+		names = append(names, fmt.Sprintf("example %d", i))
+	}
+	if len(values) > 0 {
+		sub, err := c.Compile(schemaURL + "#" + ptr)
+		if err != nil {
+			return fmt.Errorf("unable to compile #%s: %w", ptr, err)
+		}
+		for i, v := range values {
+			if err := sub.Validate(v); err != nil {
+				return fmt.Errorf("%s of #%s is not valid against its schema: %w", names[i], ptr, err)
+			}
+		}
+	}
+
+	for _, key := range []string{"properties", "definitions", "patternProperties"} {
+		m, _ := s[key].(map[string]any)
+		names := make([]string, 0, len(m))
+		for name := range m {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := checkAnnotations(c, m[name], ptr+"/"+key+"/"+escapePointer(name)); err != nil {
+				return err
+			}
+		}
+	}
+	for _, key := range []string{"items", "additionalItems", "additionalProperties", "contains", "propertyNames", "not", "if", "then", "else"} {
+		if sub, ok := s[key]; ok {
+			if err := checkAnnotations(c, sub, ptr+"/"+key); err != nil {
+				return err
+			}
+		}
+	}
+	for _, key := range []string{"items", "allOf", "anyOf", "oneOf"} {
+		subs, _ := s[key].([]any)
+		for i, sub := range subs {
+			if err := checkAnnotations(c, sub, fmt.Sprintf("%s/%s/%d", ptr, key, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// escapePointer escapes name as a reference token of a JSON pointer.
+func escapePointer(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
@@ -101,7 +101,7 @@ func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error)
 
 	cfg.NLConfigParameters = append([]parameters.Parameter{newQuestionParam}, cfg.NLConfigParameters...)
 
-	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, cfg.NLConfigParameters, nil)
+	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, cfg.NLConfigParameters, nil, nil)
 
 	t := Tool{
 		Config:      cfg,
@@ -135,21 +135,28 @@ func (t Tool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, para
 		return nil, util.NewClientServerError("source used is not compatible with the tool", http.StatusInternalServerError, err)
 	}
 
-	sliceParams := params.AsSlice()
-	allParamValues := make([]any, len(sliceParams)+1)
-	allParamValues[0] = fmt.Sprintf("%s", sliceParams[0]) // nl_question
-	allParamValues[1] = t.NLConfig                        // nl_config
-	for i, param := range sliceParams[1:] {
-		allParamValues[i+2] = fmt.Sprintf("%s", param)
-	}
-
+	allParamValues := t.sqlValues(params.AsSlice())
 	resp, err := source.RunSQL(ctx, t.Statement, allParamValues)
 	if err != nil {
-		return nil, util.NewClientServerError(fmt.Sprintf("error running SQL query: %v. Query: %v , Values: %v. Toolbox v0.19.0+ is only compatible with AlloyDB AI NL v1.0.3+. Please ensure that you are using the latest AlloyDB AI NL extension", err, t.Statement, allParamValues), http.StatusBadRequest, err)
+		// the error is returned to the client, so sensitive values are redacted
+		err = params.RedactError(err)
+		return nil, util.NewClientServerError(fmt.Sprintf("error running SQL query: %v. Query: %v , Values: %v. Toolbox v0.19.0+ is only compatible with AlloyDB AI NL v1.0.3+. Please ensure that you are using the latest AlloyDB AI NL extension", err, t.Statement, t.sqlValues(params.AsRedactedSlice())), http.StatusBadRequest, err)
 	}
 	return resp, nil
 }
 
+// sqlValues returns the arguments of the statement for the parameter values:
+// the question, the nl_config, then the values of the other parameters.
+func (t Tool) sqlValues(paramValues []any) []any {
+	allParamValues := make([]any, len(paramValues)+1)
+	allParamValues[0] = fmt.Sprintf("%s", paramValues[0]) // nl_question
+	allParamValues[1] = t.NLConfig                        // nl_config
+	for i, param := range paramValues[1:] {
+		allParamValues[i+2] = fmt.Sprintf("%s", param)
+	}
+	return allParamValues
+}
+
 func (t Tool) EmbedParams(ctx context.Context, paramValues parameters.ParamValues, embeddingModelsMap map[string]embeddingmodels.EmbeddingModel) (parameters.ParamValues, error) {
 	return parameters.EmbedParams(ctx, t.Parameters, paramValues, embeddingModelsMap, nil)
 }
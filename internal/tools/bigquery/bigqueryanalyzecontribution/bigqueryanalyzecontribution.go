@@ -50,21 +50,40 @@ func newConfig(ctx context.Context, name string, decoder *yaml.Decoder) (tools.T
 }
 
 type compatibleSource interface {
-	BigQueryClient() *bigqueryapi.Client
+	bqutil.ProjectOverrideSource
+	bqutil.SQLRedactionSource
 	UseClientAuthorization() bool
 	IsDatasetAllowed(projectID, datasetID string) bool
 	BigQueryAllowedDatasets() []string
 	BigQuerySession() bigqueryds.BigQuerySessionProvider
-	RetrieveClientAndService(tools.AccessToken) (*bigqueryapi.Client, *bigqueryrestapi.Service, error)
-	RunSQL(context.Context, *bigqueryapi.Client, string, string, []bigqueryapi.QueryParameter, []*bigqueryapi.ConnectionProperty) (any, error)
+	RetrieveClientAndService(context.Context, tools.AccessToken) (*bigqueryapi.Client, *bigqueryrestapi.Service, error)
+	RunSQL(context.Context, *bigqueryapi.Client, string, string, []bigqueryapi.QueryParameter, []*bigqueryapi.ConnectionProperty, bigqueryds.JobOptions) (any, error)
+	BigQueryJobOptions(bigqueryds.JobOptions) bigqueryds.JobOptions
 }
 
 type Config struct {
-	Name         string   `yaml:"name" validate:"required"`
-	Type         string   `yaml:"type" validate:"required"`
-	Source       string   `yaml:"source" validate:"required"`
-	Description  string   `yaml:"description" validate:"required"`
-	AuthRequired []string `yaml:"authRequired"`
+	Name         string                 `yaml:"name" validate:"required"`
+	Type         string                 `yaml:"type" validate:"required"`
+	Source       string                 `yaml:"source" validate:"required"`
+	Description  string                 `yaml:"description" validate:"required"`
+	AuthRequired []string               `yaml:"authRequired"`
+	Annotations  *tools.ToolAnnotations `yaml:"annotations,omitempty"`
+	// JobLabels are added to the query jobs and dry runs of the tool, and
+	// override the defaultJobLabels of the source with the same keys.
+	JobLabels map[string]string `yaml:"jobLabels"`
+	// MaximumBytesBilled overrides the maximumBytesBilled of the source.
+	MaximumBytesBilled *int64 `yaml:"maximumBytesBilled"`
+	// QueryLabel is set as the query_label connection property of the jobs
+	// of the tool, as "key:value".
+	QueryLabel string `yaml:"queryLabel"`
+	// Priority is the priority of the query jobs of the tool, "interactive"
+	// or "batch".
+	Priority string `yaml:"priority"`
+	// UseQueryCache sets whether queries may be answered from cached results.
+	UseQueryCache *bool `yaml:"useQueryCache"`
+	// ExpandDescriptions expands the source properties referenced in the
+	// descriptions of the tool and its parameters, e.g. {{ .Source.Project }}.
+	ExpandDescriptions bool `yaml:"expandDescriptions"`
 }
 
 // validate interface
@@ -75,6 +94,17 @@ func (cfg Config) ToolConfigType() string {
 }
 
 func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error) {
+	jobOptions, err := bqutil.ToolJobOptions(bqutil.JobConfig{
+		Labels:             cfg.JobLabels,
+		MaximumBytesBilled: cfg.MaximumBytesBilled,
+		QueryLabel:         cfg.QueryLabel,
+		Priority:           cfg.Priority,
+		UseQueryCache:      cfg.UseQueryCache,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration of tool %q: %w", cfg.Name, err)
+	}
+
 	// verify source exists
 	rawS, ok := srcs[cfg.Source]
 	if !ok {
@@ -84,7 +114,7 @@ func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error)
 	// verify the source is compatible
 	s, ok := rawS.(compatibleSource)
 	if !ok {
-		return nil, fmt.Errorf("invalid source for %q tool: source %q not compatible", resourceType, cfg.Source)
+		return nil, tools.IncompatibleSourceError[compatibleSource](rawS, cfg.Source, resourceType, srcs)
 	}
 
 	allowedDatasets := s.BigQueryAllowedDatasets()
@@ -128,12 +158,21 @@ func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error)
 		topKInsightsParameter,
 		pruningMethodParameter,
 	}
+	params = bqutil.AppendProjectOverrideParameter(params, s)
+
+	description, err := bqutil.ExpandDescriptions(cfg.Name, cfg.ExpandDescriptions, rawS, cfg.Description, params)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Description = description
 
-	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, params, nil)
+	annotations := tools.GetAnnotationsOrDefault(cfg.Annotations, tools.NewReadOnlyAnnotations)
+	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, params, annotations, nil)
 
 	// finish tool setup
 	t := Tool{
 		Config:      cfg,
+		jobOptions:  jobOptions,
 		Parameters:  params,
 		manifest:    tools.Manifest{Description: cfg.Description, Parameters: params.Manifest(), AuthRequired: cfg.AuthRequired},
 		mcpManifest: mcpManifest,
@@ -142,13 +181,14 @@ func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error)
 }
 
 // validate interface
-var _ tools.Tool = Tool{}
+var _ tools.RedactingTool = Tool{}
 
 type Tool struct {
 	Config
 	Parameters  parameters.Parameters `yaml:"parameters"`
 	manifest    tools.Manifest
 	mcpManifest tools.McpManifest
+	jobOptions  bigqueryds.JobOptions
 }
 
 func (t Tool) ToConfig() tools.ToolConfig {
@@ -168,7 +208,13 @@ func (t Tool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, para
 		return nil, util.NewAgentError(fmt.Sprintf("unable to cast input_data parameter %s", paramsMap["input_data"]), nil)
 	}
 
-	bqClient, restService, err := source.RetrieveClientAndService(accessToken)
+	ctx, tbErr := bqutil.WithProjectOverride(ctx, t.Name, source, paramsMap)
+	if tbErr != nil {
+		return nil, tbErr
+	}
+
+	jobOpts := bqutil.ResolveJobOptions(source, t.jobOptions, bqutil.JobOptions{})
+	bqClient, restService, err := source.RetrieveClientAndService(ctx, accessToken)
 	if err != nil {
 		return nil, util.NewClientServerError("failed to retrieve BigQuery client", http.StatusInternalServerError, err)
 	}
@@ -216,9 +262,9 @@ func (t Tool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, para
 					{Key: "session_id", Value: session.ID},
 				}
 			}
-			dryRunJob, err := bqutil.DryRunQuery(ctx, restService, source.BigQueryClient().Project(), source.BigQueryClient().Location, inputData, nil, connProps)
+			dryRunJob, err := bqutil.DryRunQuery(ctx, restService, bqClient.Project(), bqClient.Location, inputData, nil, "", connProps, jobOpts)
 			if err != nil {
-				return nil, util.ProcessGcpError(err)
+				return nil, bqutil.ProcessQueryError("error processing GCP request", source, err, inputData)
 			}
 			statementType := dryRunJob.Statistics.Query.StatementType
 			if statementType != "SELECT" {
@@ -239,18 +285,12 @@ func (t Tool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, para
 		inputDataSource = fmt.Sprintf("(%s)", inputData)
 	} else {
 		if len(source.BigQueryAllowedDatasets()) > 0 {
-			parts := strings.Split(inputData, ".")
-			var projectID, datasetID string
-			switch len(parts) {
-			case 3: // project.dataset.table
-				projectID, datasetID = parts[0], parts[1]
-			case 2: // dataset.table
-				projectID, datasetID = source.BigQueryClient().Project(), parts[0]
-			default:
-				return nil, util.NewAgentError(fmt.Sprintf("invalid table ID format for 'input_data': %q. Expected 'dataset.table' or 'project.dataset.table'", inputData), nil)
+			ref, err := bqutil.CanonicalTableID(inputData, bqClient.Project())
+			if err != nil {
+				return nil, util.NewAgentError(fmt.Sprintf("invalid table ID format for 'input_data': %q. Expected 'dataset.table' or 'project.dataset.table'", inputData), err)
 			}
-			if !source.IsDatasetAllowed(projectID, datasetID) {
-				return nil, util.NewAgentError(fmt.Sprintf("access to dataset '%s.%s' (from table '%s') is not allowed", projectID, datasetID, inputData), nil)
+			if !source.IsDatasetAllowed(ref.ProjectID, ref.DatasetID) {
+				return nil, bqutil.RestrictionError(ctx, t.Name, fmt.Sprintf("access to dataset '%s' (from table '%s') is not allowed", ref.Dataset(), inputData), ref.Dataset())
 			}
 		}
 		inputDataSource = fmt.Sprintf("SELECT * FROM `%s`", inputData)
@@ -281,17 +321,18 @@ func (t Tool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, para
 		// If not in protected mode, create a session for this invocation.
 		createModelQuery.CreateSession = true
 	}
+	jobOpts.ApplyToQuery(createModelQuery)
 	createModelJob, err := createModelQuery.Run(ctx)
 	if err != nil {
-		return nil, util.ProcessGcpError(err)
+		return nil, bqutil.ProcessQueryError("error processing GCP request", source, err, createModelSQL)
 	}
 
 	status, err := createModelJob.Wait(ctx)
 	if err != nil {
-		return nil, util.ProcessGcpError(err)
+		return nil, bqutil.ProcessQueryError("error processing GCP request", source, err, createModelSQL)
 	}
 	if err := status.Err(); err != nil {
-		return nil, util.ProcessGcpError(err)
+		return nil, bqutil.ProcessQueryError("error processing GCP request", source, err, createModelSQL)
 	}
 
 	// Determine the session ID to use for subsequent queries.
@@ -308,13 +349,23 @@ func (t Tool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, para
 	getInsightsSQL := fmt.Sprintf("SELECT * FROM ML.GET_INSIGHTS(MODEL %s)", modelID)
 	connProps := []*bigqueryapi.ConnectionProperty{{Key: "session_id", Value: sessionID}}
 
-	resp, err := source.RunSQL(ctx, bqClient, getInsightsSQL, "SELECT", nil, connProps)
+	resp, err := source.RunSQL(ctx, bqClient, getInsightsSQL, "SELECT", nil, connProps, jobOpts)
 	if err != nil {
-		return nil, util.ProcessGcpError(err)
+		return nil, bqutil.ProcessAPIError("error processing GCP request", err)
 	}
 	return resp, nil
 }
 
+// RedactParams redacts the string literals of the SQL of the input_data parameter if
+// the source redacts SQL.
+func (t Tool) RedactParams(resourceMgr tools.SourceProvider, params parameters.ParamValues) map[string]any {
+	source, err := tools.GetCompatibleSource[compatibleSource](resourceMgr, t.Source, t.Name, t.Type)
+	if err != nil {
+		return params.AsRedactedMap()
+	}
+	return bqutil.RedactSQLParams(source, params, "input_data")
+}
+
 func (t Tool) EmbedParams(ctx context.Context, paramValues parameters.ParamValues, embeddingModelsMap map[string]embeddingmodels.EmbeddingModel) (parameters.ParamValues, error) {
 	return parameters.EmbedParams(ctx, t.Parameters, paramValues, embeddingModelsMap, nil)
 }
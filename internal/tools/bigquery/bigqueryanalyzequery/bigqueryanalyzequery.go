@@ -0,0 +1,380 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigqueryanalyzequery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	bigqueryapi "cloud.google.com/go/bigquery"
+	yaml "github.com/goccy/go-yaml"
+	"github.com/googleapis/genai-toolbox/internal/embeddingmodels"
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	bigqueryds "github.com/googleapis/genai-toolbox/internal/sources/bigquery"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	bqutil "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+	bigqueryrestapi "google.golang.org/api/bigquery/v2"
+)
+
+const resourceType string = "bigquery-analyze-query"
+
+const (
+	sqlKey    = "sql"
+	dryRunKey = "dry_run"
+)
+
+// Verdicts of the restrictions of the source.
+const (
+	VerdictAllowed    = "allowed"
+	VerdictDisallowed = "disallowed"
+	// VerdictUnverified is for queries whose restrictions could not be
+	// checked, e.g. because their tables could not be parsed.
+	VerdictUnverified = "unverified"
+)
+
+// WarningNotParsed is the reason of the warning for queries that the parser
+// could not analyze, whose tables, connections and storage URIs are those of
+// their dry run only.
+const WarningNotParsed = "notParsed"
+
+func init() {
+	if !tools.Register(resourceType, newConfig) {
+		panic(fmt.Sprintf("tool type %q already registered", resourceType))
+	}
+}
+
+func newConfig(ctx context.Context, name string, decoder *yaml.Decoder) (tools.ToolConfig, error) {
+	actual := Config{Name: name}
+	if err := decoder.DecodeContext(ctx, &actual); err != nil {
+		return nil, err
+	}
+	return actual, nil
+}
+
+type compatibleSource interface {
+	bqutil.ProjectOverrideSource
+	bqutil.SQLRedactionSource
+	BigQueryProjectFor(context.Context) string
+	BigQueryWriteMode() string
+	UseClientAuthorization() bool
+	IsDatasetAllowed(projectID, datasetID string) bool
+	BigQueryAllowedDatasets() []string
+	BigQueryRestrictsConnections() bool
+	IsConnectionAllowed(string) bool
+	BigQueryRestrictsStorageURIs() bool
+	IsStorageURIAllowed(string) bool
+	RetrieveClientAndService(context.Context, tools.AccessToken) (*bigqueryapi.Client, *bigqueryrestapi.Service, error)
+}
+
+type Config struct {
+	Name         string                 `yaml:"name" validate:"required"`
+	Type         string                 `yaml:"type" validate:"required"`
+	Source       string                 `yaml:"source" validate:"required"`
+	Description  string                 `yaml:"description" validate:"required"`
+	AuthRequired []string               `yaml:"authRequired"`
+	Annotations  *tools.ToolAnnotations `yaml:"annotations,omitempty"`
+	// ExpandDescriptions expands the source properties referenced in the
+	// descriptions of the tool and its parameters, e.g. {{ .Source.Project }}.
+	ExpandDescriptions bool `yaml:"expandDescriptions"`
+}
+
+// validate interface
+var _ tools.ToolConfig = Config{}
+
+func (cfg Config) ToolConfigType() string {
+	return resourceType
+}
+
+func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error) {
+	// verify source exists
+	rawS, ok := srcs[cfg.Source]
+	if !ok {
+		return nil, fmt.Errorf("no source named %q configured", cfg.Source)
+	}
+
+	// verify the source is compatible
+	s, ok := rawS.(compatibleSource)
+	if !ok {
+		return nil, tools.IncompatibleSourceError[compatibleSource](rawS, cfg.Source, resourceType, srcs)
+	}
+
+	sqlParameter := parameters.NewStringParameter(sqlKey, "The SQL to analyze. It is not executed.")
+	dryRunParameter := parameters.NewBooleanParameterWithDefault(
+		dryRunKey,
+		false,
+		"If set to true, the query is also validated by a dry run, which reports the bytes it would process "+
+			"and the tables it references more reliably than parsing. Defaults to false.",
+	)
+	params := bqutil.AppendProjectOverrideParameter(parameters.Parameters{sqlParameter, dryRunParameter}, s)
+	description, err := bqutil.ExpandDescriptions(cfg.Name, cfg.ExpandDescriptions, rawS, cfg.Description, params)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Description = description
+
+	annotations := tools.GetAnnotationsOrDefault(cfg.Annotations, tools.NewReadOnlyAnnotations)
+	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, params, annotations, nil)
+
+	// finish tool setup
+	t := Tool{
+		Config:      cfg,
+		Parameters:  params,
+		manifest:    tools.Manifest{Description: cfg.Description, Parameters: params.Manifest(), AuthRequired: cfg.AuthRequired},
+		mcpManifest: mcpManifest,
+	}
+	return t, nil
+}
+
+// validate interface
+var _ tools.Tool = Tool{}
+
+type Tool struct {
+	Config
+	Parameters  parameters.Parameters `yaml:"parameters"`
+	manifest    tools.Manifest
+	mcpManifest tools.McpManifest
+}
+
+func (t Tool) ToConfig() tools.ToolConfig {
+	return t.Config
+}
+
+// Restrictions is the verdict of the restrictions of the source on a query.
+type Restrictions struct {
+	// Verdict is VerdictAllowed, VerdictDisallowed or VerdictUnverified.
+	Verdict string `json:"verdict"`
+	// Reasons explain a verdict other than VerdictAllowed, one per
+	// restriction.
+	Reasons []string `json:"reasons,omitempty"`
+	// Datasets are the datasets of the query outside of the allowed
+	// datasets.
+	Datasets []string `json:"datasets,omitempty"`
+}
+
+// Report is the result of the tool.
+type Report struct {
+	// Tables are the tables referenced by the query, "project.dataset.table".
+	Tables []string `json:"tables"`
+	// UnresolvedTables are the names of one part where tables are expected,
+	// see bqutil.UnresolvedTableParser.
+	UnresolvedTables []string `json:"unresolvedTables,omitempty"`
+	// Connections are the BigQuery connections used by the query.
+	Connections []string `json:"connections,omitempty"`
+	// StorageURIs are the URIs of the external data read by the query.
+	StorageURIs []string `json:"storageUris,omitempty"`
+	// StatementTypes are the types of the statements of the query, see
+	// bqutil.ClassifyStatements.
+	StatementTypes []string `json:"statementTypes"`
+	// DryRun is whether the query was validated by a dry run.
+	DryRun bool `json:"dryRun"`
+	// StatementType is the statement type reported by the dry run.
+	StatementType string `json:"statementType,omitempty"`
+	// TotalBytesProcessed is the estimate of the dry run of the bytes the
+	// query would process.
+	TotalBytesProcessed *int64 `json:"totalBytesProcessed,omitempty"`
+	// Restrictions is the verdict of the restrictions of the source.
+	Restrictions Restrictions `json:"restrictions"`
+	// Warnings are the parts of the query that were not fully analyzed.
+	Warnings []bqutil.ValidationWarning `json:"warnings,omitempty"`
+}
+
+// restrict records that the query is not allowed by a restriction, or that
+// it could not be checked if verdict is VerdictUnverified. A disallowed query
+// stays disallowed.
+func (r *Restrictions) restrict(verdict, reason string) {
+	if r.Verdict != VerdictDisallowed {
+		r.Verdict = verdict
+	}
+	r.Reasons = append(r.Reasons, reason)
+}
+
+func (t Tool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, params parameters.ParamValues, accessToken tools.AccessToken) (any, util.ToolboxError) {
+	source, err := tools.GetCompatibleSource[compatibleSource](resourceMgr, t.Source, t.Name, t.Type)
+	if err != nil {
+		return nil, util.NewClientServerError("source used is not compatible with the tool", http.StatusInternalServerError, err)
+	}
+
+	paramsMap := params.AsMap()
+	sql, ok := paramsMap[sqlKey].(string)
+	if !ok {
+		return nil, util.NewAgentError(fmt.Sprintf("unable to cast sql parameter %s", paramsMap[sqlKey]), nil)
+	}
+	dryRun, ok := paramsMap[dryRunKey].(bool)
+	if !ok {
+		return nil, util.NewAgentError(fmt.Sprintf("unable to cast dry_run parameter %s", paramsMap[dryRunKey]), nil)
+	}
+
+	ctx, tbErr := bqutil.WithProjectOverride(ctx, t.Name, source, paramsMap)
+	if tbErr != nil {
+		return nil, tbErr
+	}
+
+	projectID := source.BigQueryProjectFor(ctx)
+	var dryRunJob *bigqueryrestapi.Job
+	if dryRun {
+		bqClient, restService, err := source.RetrieveClientAndService(ctx, accessToken)
+		if err != nil {
+			return nil, util.NewClientServerError("failed to retrieve BigQuery client", http.StatusInternalServerError, err)
+		}
+		projectID = bqClient.Project()
+		dryRunJob, err = bqutil.DryRunQuery(ctx, restService, projectID, bqClient.Location, sql, nil, "", nil, bqutil.JobOptions{})
+		if err != nil {
+			return nil, bqutil.ProcessQueryError("query validation failed", source, err, sql)
+		}
+	}
+
+	report := Report{
+		StatementTypes: append([]string{}, bqutil.ClassifyStatements(sql)...),
+		DryRun:         dryRun,
+		Restrictions:   Restrictions{Verdict: VerdictAllowed},
+	}
+	refs, parseErr := bqutil.ParseSQL(ctx, sql, bqutil.ParseOptions{DefaultProjectID: projectID})
+	if parseErr != nil {
+		if tbErr := bqutil.ParseTimeoutError(parseErr); tbErr != nil {
+			return nil, tbErr
+		}
+		report.Warnings = append(report.Warnings, bqutil.ValidationWarning{
+			Reason:  WarningNotParsed,
+			Message: fmt.Sprintf("the query could not be parsed: %s", parseErr),
+		})
+	}
+	report.Tables = append([]string{}, sortedUnion(refs.TableIDs, bqutil.ReferencedTables(dryRunJob))...)
+	report.UnresolvedTables = sortedUnion(refs.UnresolvedTables, nil)
+	report.Connections = sortedUnion(refs.ConnectionIDs, bqutil.ReferencedConnections(dryRunJob, projectID))
+	report.StorageURIs = sortedUnion(refs.StorageURIs, bqutil.ReferencedStorageURIs(dryRunJob))
+	if dryRunJob != nil && dryRunJob.Statistics != nil {
+		report.TotalBytesProcessed = &dryRunJob.Statistics.TotalBytesProcessed
+		if dryRunJob.Statistics.Query != nil {
+			report.StatementType = dryRunJob.Statistics.Query.StatementType
+		}
+	}
+
+	t.checkRestrictions(ctx, source, accessToken, sql, projectID, dryRunJob, parseErr, &report)
+	return report, nil
+}
+
+// checkRestrictions sets the verdict of the restrictions of source on sql,
+// like bigquery-execute-sql checks them before running it, and adds the
+// warnings of the validation to report.
+func (t Tool) checkRestrictions(ctx context.Context, source compatibleSource, accessToken tools.AccessToken, sql, projectID string, dryRunJob *bigqueryrestapi.Job, parseErr error, report *Report) {
+	r := &report.Restrictions
+	if source.BigQueryWriteMode() == bigqueryds.WriteModeBlocked {
+		statementTypes := report.StatementTypes
+		if report.StatementType != "" {
+			statementTypes = []string{report.StatementType}
+		}
+		for _, statementType := range statementTypes {
+			if statementType != "SELECT" {
+				r.restrict(VerdictDisallowed, "write mode is 'blocked', only SELECT statements are allowed")
+				break
+			}
+		}
+	}
+
+	validation, tbErr := bqutil.ValidateQueryAgainstAllowedDatasets(ctx, t.Name, source, accessToken, sql, projectID, dryRunJob)
+	if tbErr != nil {
+		// the restrictions of the source are reported as permission errors
+		info := tbErr.ErrorInfo()
+		if info.Code != util.ErrorCodePermissionDenied {
+			r.restrict(VerdictUnverified, tbErr.Error())
+		} else {
+			r.restrict(VerdictDisallowed, tbErr.Error())
+			if datasets, ok := info.Details["datasets"].([]string); ok {
+				r.Datasets = datasets
+			}
+		}
+	}
+	for _, w := range validation.Warnings {
+		// without a dry run, the tables are expected to be found by the
+		// parser
+		if dryRunJob == nil && (w.Reason == bqutil.WarningDryRunStatisticsMissing || w.Reason == bqutil.WarningParserFallback) {
+			continue
+		}
+		report.Warnings = append(report.Warnings, w)
+	}
+
+	if source.BigQueryRestrictsConnections() {
+		var violations []string
+		for _, id := range report.Connections {
+			if !source.IsConnectionAllowed(id) {
+				violations = append(violations, id)
+			}
+		}
+		switch {
+		case len(violations) > 0:
+			r.restrict(VerdictDisallowed, fmt.Sprintf("query uses connections that are not in the allowed list: '%s'", strings.Join(violations, "', '")))
+		case parseErr != nil:
+			r.restrict(VerdictUnverified, "could not parse connections from query to validate against allowed connections")
+		}
+	}
+	if source.BigQueryRestrictsStorageURIs() {
+		var violations []string
+		for _, uri := range report.StorageURIs {
+			if !source.IsStorageURIAllowed(uri) {
+				violations = append(violations, uri)
+			}
+		}
+		switch {
+		case len(violations) > 0:
+			r.restrict(VerdictDisallowed, fmt.Sprintf("query reads external data that is not under the allowed storage URI prefixes: '%s'", strings.Join(violations, "', '")))
+		case parseErr != nil:
+			r.restrict(VerdictUnverified, "could not parse storage URIs from query to validate against allowed storage URI prefixes")
+		}
+	}
+}
+
+// sortedUnion returns the values of a and b, sorted and without duplicates.
+func sortedUnion(a, b []string) []string {
+	values := slices.Concat(a, b)
+	slices.Sort(values)
+	return slices.Compact(values)
+}
+
+func (t Tool) EmbedParams(ctx context.Context, paramValues parameters.ParamValues, embeddingModelsMap map[string]embeddingmodels.EmbeddingModel) (parameters.ParamValues, error) {
+	return parameters.EmbedParams(ctx, t.Parameters, paramValues, embeddingModelsMap, nil)
+}
+
+func (t Tool) Manifest() tools.Manifest {
+	return t.manifest
+}
+
+func (t Tool) McpManifest() tools.McpManifest {
+	return t.mcpManifest
+}
+
+func (t Tool) Authorized(verifiedAuthServices []string) bool {
+	return tools.IsAuthorized(t.AuthRequired, verifiedAuthServices)
+}
+
+func (t Tool) RequiresClientAuthorization(resourceMgr tools.SourceProvider) (bool, error) {
+	source, err := tools.GetCompatibleSource[compatibleSource](resourceMgr, t.Source, t.Name, t.Type)
+	if err != nil {
+		return false, err
+	}
+	return source.UseClientAuthorization(), nil
+}
+
+func (t Tool) GetAuthTokenHeaderName(resourceMgr tools.SourceProvider) (string, error) {
+	return "Authorization", nil
+}
+
+func (t Tool) GetParameters() parameters.Parameters {
+	return t.Parameters
+}
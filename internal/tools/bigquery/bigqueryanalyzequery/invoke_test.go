@@ -0,0 +1,303 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigqueryanalyzequery_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	bigqueryapi "cloud.google.com/go/bigquery"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	bigqueryds "github.com/googleapis/genai-toolbox/internal/sources/bigquery"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	"github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigqueryanalyzequery"
+	bqutil "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+	bigqueryrestapi "google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/option"
+)
+
+type sourceProvider map[string]sources.Source
+
+func (p sourceProvider) GetSource(name string) (sources.Source, bool) {
+	s, ok := p[name]
+	return s, ok
+}
+
+// newDryRunSource returns a BigQuery source whose dry runs are answered by
+// handler, and the number of dry runs it received. The lookups of datasets
+// fail.
+func newDryRunSource(t *testing.T, handler http.HandlerFunc) (*bigqueryds.Source, *int) {
+	t.Helper()
+	dryRuns := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, "/jobs") {
+			http.Error(w, `{"error": {"code": 404, "message": "not found"}}`, http.StatusNotFound)
+			return
+		}
+		dryRuns++
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+	ctx := context.Background()
+	client, err := bigqueryapi.NewClient(ctx, "my-project", option.WithEndpoint(server.URL), option.WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatalf("unable to create client: %s", err)
+	}
+	restService, err := bigqueryrestapi.NewService(ctx, option.WithEndpoint(server.URL), option.WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatalf("unable to create REST service: %s", err)
+	}
+	return &bigqueryds.Source{
+		Config:      bigqueryds.Config{Name: "my-bq", Type: "bigquery", Project: "my-project", WriteMode: bigqueryds.WriteModeAllowed},
+		Client:      client,
+		RestService: restService,
+	}, &dryRuns
+}
+
+// analyze invokes a bigquery-analyze-query tool of source with sql.
+func analyze(t *testing.T, source *bigqueryds.Source, sql string, dryRun bool) (bigqueryanalyzequery.Report, util.ToolboxError) {
+	t.Helper()
+	srcs := sourceProvider{"my-bq": source}
+	cfg := bigqueryanalyzequery.Config{Name: "analyze_query", Type: "bigquery-analyze-query", Source: "my-bq", Description: "analyze sql"}
+	tool, err := cfg.Initialize(srcs)
+	if err != nil {
+		t.Fatalf("unable to initialize tool: %s", err)
+	}
+	params, err := parameters.ParseParams(tool.GetParameters(), map[string]any{"sql": sql, "dry_run": dryRun}, nil)
+	if err != nil {
+		t.Fatalf("unable to parse params: %s", err)
+	}
+	res, tbErr := tools.InvokeWithTimeout(context.Background(), "analyze_query", tool, srcs, params, "")
+	if tbErr != nil {
+		return bigqueryanalyzequery.Report{}, tbErr
+	}
+	report, ok := res.(bigqueryanalyzequery.Report)
+	if !ok {
+		t.Fatalf("unexpected result %T", res)
+	}
+	return report, nil
+}
+
+func TestAnalyzeWithParser(t *testing.T) {
+	tcs := []struct {
+		desc string
+		sql  string
+		// configure sets the restrictions of the source
+		configure func(*bigqueryds.Source)
+		want      bigqueryanalyzequery.Report
+	}{
+		{
+			desc: "no restrictions",
+			sql:  "SELECT * FROM my_dataset.orders JOIN `other-project.crm.customers` USING (id)",
+			want: bigqueryanalyzequery.Report{
+				Tables:         []string{"my-project.my_dataset.orders", "other-project.crm.customers"},
+				StatementTypes: []string{"SELECT"},
+				Restrictions:   bigqueryanalyzequery.Restrictions{Verdict: bigqueryanalyzequery.VerdictAllowed},
+			},
+		},
+		{
+			desc: "allowed datasets",
+			sql:  "INSERT INTO my_dataset.orders SELECT * FROM my_dataset.staging",
+			configure: func(s *bigqueryds.Source) {
+				s.AllowedDatasets = map[string]struct{}{"my-project.my_dataset": {}}
+			},
+			want: bigqueryanalyzequery.Report{
+				Tables:         []string{"my-project.my_dataset.orders", "my-project.my_dataset.staging"},
+				StatementTypes: []string{"INSERT"},
+				Restrictions:   bigqueryanalyzequery.Restrictions{Verdict: bigqueryanalyzequery.VerdictAllowed},
+			},
+		},
+		{
+			desc: "dataset outside of the allowed datasets",
+			sql:  "INSERT INTO my_dataset.orders SELECT * FROM other_dataset.staging",
+			configure: func(s *bigqueryds.Source) {
+				s.AllowedDatasets = map[string]struct{}{"my-project.my_dataset": {}}
+			},
+			want: bigqueryanalyzequery.Report{
+				Tables:         []string{"my-project.my_dataset.orders", "my-project.other_dataset.staging"},
+				StatementTypes: []string{"INSERT"},
+				Restrictions: bigqueryanalyzequery.Restrictions{
+					Verdict:  bigqueryanalyzequery.VerdictDisallowed,
+					Reasons:  []string{"query accesses dataset 'my-project.other_dataset', which is not in the allowed list"},
+					Datasets: []string{"my-project.other_dataset"},
+				},
+			},
+		},
+		{
+			desc: "blocked writes",
+			sql:  "SELECT 1; DELETE FROM my_dataset.orders WHERE TRUE",
+			configure: func(s *bigqueryds.Source) {
+				s.WriteMode = bigqueryds.WriteModeBlocked
+			},
+			want: bigqueryanalyzequery.Report{
+				Tables:         []string{"my-project.my_dataset.orders"},
+				StatementTypes: []string{"SELECT", "DELETE"},
+				Restrictions: bigqueryanalyzequery.Restrictions{
+					Verdict: bigqueryanalyzequery.VerdictDisallowed,
+					Reasons: []string{"write mode is 'blocked', only SELECT statements are allowed"},
+				},
+			},
+		},
+		{
+			desc: "connection outside of the allowed connections",
+			sql:  "SELECT * FROM EXTERNAL_QUERY('my-project.us.spanner_conn', 'SELECT 1')",
+			configure: func(s *bigqueryds.Source) {
+				s.AllowedConnections = map[string]struct{}{"my-project.us.sql_conn": {}}
+			},
+			want: bigqueryanalyzequery.Report{
+				Tables:         []string{},
+				Connections:    []string{"my-project.us.spanner_conn"},
+				StatementTypes: []string{"SELECT"},
+				Restrictions: bigqueryanalyzequery.Restrictions{
+					Verdict: bigqueryanalyzequery.VerdictDisallowed,
+					Reasons: []string{"query uses connections that are not in the allowed list: 'my-project.us.spanner_conn'"},
+				},
+			},
+		},
+		{
+			desc: "query that cannot be parsed",
+			sql:  "EXECUTE IMMEDIATE CONCAT('SELECT * FROM ', table_name)",
+			configure: func(s *bigqueryds.Source) {
+				s.AllowedDatasets = map[string]struct{}{"my-project.my_dataset": {}}
+			},
+			want: bigqueryanalyzequery.Report{
+				Tables:         []string{},
+				StatementTypes: []string{"EXECUTE_IMMEDIATE"},
+				Restrictions: bigqueryanalyzequery.Restrictions{
+					Verdict: bigqueryanalyzequery.VerdictUnverified,
+					// the allowed datasets also restrict the connections
+					Reasons: []string{
+						"could not parse tables from query to validate against allowed datasets",
+						"could not parse connections from query to validate against allowed connections",
+					},
+				},
+				Warnings: []bqutil.ValidationWarning{{Reason: bigqueryanalyzequery.WarningNotParsed}},
+			},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			source, dryRuns := newDryRunSource(t, func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "unexpected dry run", http.StatusInternalServerError)
+			})
+			if tc.configure != nil {
+				tc.configure(source)
+			}
+			got, tbErr := analyze(t, source, tc.sql, false)
+			if tbErr != nil {
+				t.Fatalf("unexpected error: %s", tbErr)
+			}
+			// the messages of the errors and warnings wrap those of the
+			// parser, only their start is compared
+			for i, reason := range got.Restrictions.Reasons {
+				if len(tc.want.Restrictions.Reasons) > i && strings.HasPrefix(reason, tc.want.Restrictions.Reasons[i]) {
+					got.Restrictions.Reasons[i] = tc.want.Restrictions.Reasons[i]
+				}
+			}
+			if diff := cmp.Diff(tc.want, got, cmpopts.IgnoreFields(bqutil.ValidationWarning{}, "Message")); diff != "" {
+				t.Errorf("unexpected report (-want +got):\n%s", diff)
+			}
+			if *dryRuns != 0 {
+				t.Errorf("expected no dry run, got %d", *dryRuns)
+			}
+		})
+	}
+}
+
+func TestAnalyzeWithDryRun(t *testing.T) {
+	dryRun := func(statementType string, tables ...string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			var refs []map[string]any
+			for _, table := range tables {
+				parts := strings.Split(table, ".")
+				refs = append(refs, map[string]any{"projectId": parts[0], "datasetId": parts[1], "tableId": parts[2]})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"statistics": map[string]any{
+					"totalBytesProcessed": "1024",
+					"query":               map[string]any{"statementType": statementType, "referencedTables": refs},
+				},
+			})
+		}
+	}
+	bytes := int64(1024)
+
+	t.Run("allowed", func(t *testing.T) {
+		source, dryRuns := newDryRunSource(t, dryRun("SELECT", "my-project.my_dataset.orders"))
+		source.AllowedDatasets = map[string]struct{}{"my-project.my_dataset": {}}
+		got, tbErr := analyze(t, source, "SELECT * FROM my_dataset.orders", true)
+		if tbErr != nil {
+			t.Fatalf("unexpected error: %s", tbErr)
+		}
+		want := bigqueryanalyzequery.Report{
+			Tables:              []string{"my-project.my_dataset.orders"},
+			StatementTypes:      []string{"SELECT"},
+			DryRun:              true,
+			StatementType:       "SELECT",
+			TotalBytesProcessed: &bytes,
+			Restrictions:        bigqueryanalyzequery.Restrictions{Verdict: bigqueryanalyzequery.VerdictAllowed},
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("unexpected report (-want +got):\n%s", diff)
+		}
+		if *dryRuns != 1 {
+			t.Errorf("expected a dry run, got %d", *dryRuns)
+		}
+	})
+
+	t.Run("tables of a view", func(t *testing.T) {
+		// the dry run reports the tables that the parser cannot see
+		source, _ := newDryRunSource(t, dryRun("SELECT", "my-project.my_dataset.orders_view", "my-project.private.orders"))
+		source.AllowedDatasets = map[string]struct{}{"my-project.my_dataset": {}}
+		got, tbErr := analyze(t, source, "SELECT * FROM my_dataset.orders_view", true)
+		if tbErr != nil {
+			t.Fatalf("unexpected error: %s", tbErr)
+		}
+		if diff := cmp.Diff([]string{"my-project.my_dataset.orders_view", "my-project.private.orders"}, got.Tables); diff != "" {
+			t.Errorf("unexpected tables (-want +got):\n%s", diff)
+		}
+		want := bigqueryanalyzequery.Restrictions{
+			Verdict:  bigqueryanalyzequery.VerdictDisallowed,
+			Reasons:  []string{"query accesses dataset 'my-project.private', which is not in the allowed list"},
+			Datasets: []string{"my-project.private"},
+		}
+		if diff := cmp.Diff(want, got.Restrictions); diff != "" {
+			t.Errorf("unexpected restrictions (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		source, _ := newDryRunSource(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{
+				"code":    http.StatusBadRequest,
+				"message": "Syntax error",
+				"errors":  []map[string]any{{"reason": "invalidQuery", "location": "q", "message": "Syntax error"}},
+			}})
+		})
+		_, tbErr := analyze(t, source, "SELEC 1", true)
+		if tbErr == nil || tbErr.ErrorInfo().Code != util.ErrorCodeInvalidArgument {
+			t.Fatalf("expected an invalid argument error, got %v", tbErr)
+		}
+	})
+}
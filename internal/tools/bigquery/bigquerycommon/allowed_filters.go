@@ -0,0 +1,48 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon
+
+import (
+	"fmt"
+	"slices"
+)
+
+// DatasetRef is a dataset, e.g. of the listing of a project.
+type DatasetRef struct {
+	ProjectID string
+	DatasetID string
+}
+
+// String returns the ID of the dataset, "project.dataset".
+func (r DatasetRef) String() string {
+	return fmt.Sprintf("%s.%s", r.ProjectID, r.DatasetID)
+}
+
+// FilterAllowedDatasets returns the datasets of refs in the allowed datasets
+// of source, in their order. Listing tools filter their results with it, so
+// that a dataset is listed if and only if the tools accept it.
+func FilterAllowedDatasets(source DatasetAllowlistSource, refs []DatasetRef) []DatasetRef {
+	return slices.DeleteFunc(slices.Clone(refs), func(ref DatasetRef) bool {
+		return !source.IsDatasetAllowed(ref.ProjectID, ref.DatasetID)
+	})
+}
+
+// FilterAllowedTables returns the tables of refs in the allowed datasets of
+// source, in their order, like FilterAllowedDatasets.
+func FilterAllowedTables(source DatasetAllowlistSource, refs []TableRef) []TableRef {
+	return slices.DeleteFunc(slices.Clone(refs), func(ref TableRef) bool {
+		return !source.IsDatasetAllowed(ref.ProjectID, ref.DatasetID)
+	})
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon_test
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+)
+
+func TestFilterAllowedDatasets(t *testing.T) {
+	source := allowlist{"p.sales": true, "q.sales": true}
+	refs := []bigquerycommon.DatasetRef{
+		{ProjectID: "q", DatasetID: "sales"},
+		{ProjectID: "p", DatasetID: "hr"},
+		{ProjectID: "p", DatasetID: "sales"},
+		// the dataset of another project is not allowed
+		{ProjectID: "r", DatasetID: "sales"},
+	}
+	want := []bigquerycommon.DatasetRef{{ProjectID: "q", DatasetID: "sales"}, {ProjectID: "p", DatasetID: "sales"}}
+	if diff := cmp.Diff(want, bigquerycommon.FilterAllowedDatasets(source, refs)); diff != "" {
+		t.Errorf("unexpected datasets (-want +got):\n%s", diff)
+	}
+	if len(refs) != 4 {
+		t.Errorf("expected refs not to be modified, got %v", refs)
+	}
+}
+
+// TestFilterAllowedProperties checks, for random listings, that the filters
+// keep exactly the datasets and tables that the source allows, in order.
+func TestFilterAllowedProperties(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	names := []string{"p", "q", "sales", "hr", "p.sales", "t"}
+	pick := func() string { return names[rng.Intn(len(names))] }
+	for range 200 {
+		source := allowlist{}
+		for range 1 + rng.Intn(3) {
+			source[pick()+"."+pick()] = true
+		}
+		var datasets []bigquerycommon.DatasetRef
+		var tables []bigquerycommon.TableRef
+		for range rng.Intn(10) {
+			datasets = append(datasets, bigquerycommon.DatasetRef{ProjectID: pick(), DatasetID: pick()})
+			tables = append(tables, bigquerycommon.TableRef{ProjectID: pick(), DatasetID: pick(), TableID: pick()})
+		}
+
+		gotDatasets := bigquerycommon.FilterAllowedDatasets(source, datasets)
+		var wantDatasets []bigquerycommon.DatasetRef
+		for _, ref := range datasets {
+			if source.IsDatasetAllowed(ref.ProjectID, ref.DatasetID) {
+				wantDatasets = append(wantDatasets, ref)
+			}
+		}
+		if !slices.Equal(gotDatasets, wantDatasets) {
+			t.Fatalf("FilterAllowedDatasets(%v, %v) = %v, want %v", source, datasets, gotDatasets, wantDatasets)
+		}
+
+		gotTables := bigquerycommon.FilterAllowedTables(source, tables)
+		for _, ref := range gotTables {
+			if !source.IsDatasetAllowed(ref.ProjectID, ref.DatasetID) {
+				t.Fatalf("FilterAllowedTables(%v, %v) kept %v, which is not allowed", source, tables, ref)
+			}
+		}
+		var kept int
+		for _, ref := range tables {
+			if source.IsDatasetAllowed(ref.ProjectID, ref.DatasetID) {
+				kept++
+			}
+		}
+		if len(gotTables) != kept {
+			t.Fatalf("FilterAllowedTables(%v, %v) = %v, want %d tables", source, tables, gotTables, kept)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"google.golang.org/api/googleapi"
+)
+
+// apiErrorStatuses maps the reasons of BigQuery API errors to the HTTP
+// statuses that tools report them with. The reason is more precise than the
+// status of the API response, e.g. rate limits are reported with status 403.
+var apiErrorStatuses = map[string]int{
+	"invalidQuery":      http.StatusBadRequest,
+	"accessDenied":      http.StatusForbidden,
+	"notFound":          http.StatusNotFound,
+	"rateLimitExceeded": http.StatusTooManyRequests,
+	"backendError":      http.StatusServiceUnavailable,
+}
+
+// ProcessAPIError returns the ToolboxError for err, an error of a BigQuery API
+// call, with message msg. The status of a *googleapi.Error in the chain of err
+// is taken from the reason of its first known error item, e.g. "notFound", or
+// else from the status of the response. Errors the agent can correct, such as
+// invalid queries, are returned as agent errors, and all other statuses as
+// client or server errors with the status. The reason and location of the
+// error item, e.g. the position of a syntax error, are added to the details of
+// the error. err is kept as its cause, so errors.As still finds the
+// *googleapi.Error.
+func ProcessAPIError(msg string, err error) util.ToolboxError {
+	return processAPIError(msg, err, nil)
+}
+
+// ProcessQueryError is ProcessAPIError for err, an error of a dry run or a
+// job of sql, with the string literals of sql redacted from its message if
+// source redacts SQL, like RedactSQLError. If the message has the position of
+// the error in sql, e.g. "at [3:15]", the ErrorExcerpt of sql at the position
+// is added to the details of the error as "sqlExcerpt", with the contents of
+// the string literals masked if the source redacts SQL.
+func ProcessQueryError(msg string, source SQLRedactionSource, err error, sql string) util.ToolboxError {
+	excerptSQL := sql
+	if source.BigQueryRedactSQL() {
+		excerptSQL = maskSQLLiterals(sql)
+	}
+	var details map[string]any
+	if excerpt := ErrorExcerpt(err.Error(), excerptSQL); excerpt != "" {
+		details = map[string]any{"sqlExcerpt": excerpt}
+	}
+	return processAPIError(msg, RedactSQLError(source, err, sql), details)
+}
+
+// processAPIError implements ProcessAPIError, starting from details, which
+// may be nil, for the details of the error.
+func processAPIError(msg string, err error, details map[string]any) util.ToolboxError {
+	if len(details) == 0 {
+		details = nil
+	}
+	var gErr *googleapi.Error
+	if !errors.As(err, &gErr) {
+		if details == nil {
+			return util.NewAgentError(msg, err)
+		}
+		return util.NewAgentError(msg, err).WithDetails(details)
+	}
+	status := gErr.Code
+	for i, item := range gErr.Errors {
+		if s, ok := apiErrorStatuses[item.Reason]; ok {
+			status = s
+			if details == nil {
+				details = make(map[string]any)
+			}
+			details["reason"] = item.Reason
+			if location := errorItemLocation(gErr.Body, i); location != "" {
+				details["location"] = location
+			}
+			break
+		}
+	}
+	code, _ := util.ErrorCodeFromStatus(status)
+	if code == util.ErrorCodeInvalidArgument {
+		return util.NewAgentError(msg, err).WithDetails(details)
+	}
+	if status < 400 || status > 599 {
+		status = http.StatusInternalServerError
+	}
+	return util.NewClientServerError(msg, status, err).WithDetails(details)
+}
+
+// errorItemLocation returns the location of the i-th error item of the JSON
+// body of an API error, which googleapi.ErrorItem does not keep, or "" if it
+// has none.
+func errorItemLocation(body string, i int) string {
+	var resp struct {
+		Error struct {
+			Errors []struct {
+				Location string `json:"location"`
+			} `json:"errors"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(body), &resp); err != nil || i >= len(resp.Error.Errors) {
+		return ""
+	}
+	return resp.Error.Errors[i].Location
+}
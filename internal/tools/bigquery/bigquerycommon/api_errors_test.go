@@ -0,0 +1,131 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"google.golang.org/api/googleapi"
+)
+
+func apiError(code int, reason, location string) error {
+	body := fmt.Sprintf(`{"error":{"code":%d,"errors":[{"reason":%q,"location":%q}]}}`, code, reason, location)
+	gErr := &googleapi.Error{Code: code, Body: body, Errors: []googleapi.ErrorItem{{Reason: reason}}}
+	return fmt.Errorf("failed to insert dry run job: %w", gErr)
+}
+
+func TestProcessAPIError(t *testing.T) {
+	tcs := []struct {
+		desc         string
+		err          error
+		wantCategory util.ErrorCategory
+		wantStatus   int
+		wantInfo     util.ErrorInfo
+	}{
+		{
+			desc:         "invalid query",
+			err:          apiError(http.StatusBadRequest, "invalidQuery", "q"),
+			wantCategory: util.CategoryAgent,
+			wantInfo:     util.ErrorInfo{Code: util.ErrorCodeInvalidArgument, Details: map[string]any{"reason": "invalidQuery", "location": "q"}},
+		},
+		{
+			desc:         "not found",
+			err:          apiError(http.StatusNotFound, "notFound", ""),
+			wantCategory: util.CategoryServer,
+			wantStatus:   http.StatusNotFound,
+			wantInfo:     util.ErrorInfo{Code: util.ErrorCodeNotFound, Details: map[string]any{"reason": "notFound"}},
+		},
+		{
+			desc:         "access denied",
+			err:          apiError(http.StatusForbidden, "accessDenied", ""),
+			wantCategory: util.CategoryServer,
+			wantStatus:   http.StatusForbidden,
+			wantInfo:     util.ErrorInfo{Code: util.ErrorCodePermissionDenied, Details: map[string]any{"reason": "accessDenied"}},
+		},
+		{
+			desc:         "rate limit exceeded, reported with status 403",
+			err:          apiError(http.StatusForbidden, "rateLimitExceeded", ""),
+			wantCategory: util.CategoryServer,
+			wantStatus:   http.StatusTooManyRequests,
+			wantInfo:     util.ErrorInfo{Code: util.ErrorCodeRateLimited, Retryable: true, Details: map[string]any{"reason": "rateLimitExceeded"}},
+		},
+		{
+			desc:         "backend error",
+			err:          apiError(http.StatusInternalServerError, "backendError", ""),
+			wantCategory: util.CategoryServer,
+			wantStatus:   http.StatusServiceUnavailable,
+			wantInfo:     util.ErrorInfo{Code: util.ErrorCodeBackendUnavailable, Retryable: true, Details: map[string]any{"reason": "backendError"}},
+		},
+		{
+			desc:         "unknown reason uses the status",
+			err:          apiError(http.StatusUnauthorized, "authError", ""),
+			wantCategory: util.CategoryServer,
+			wantStatus:   http.StatusUnauthorized,
+			wantInfo:     util.ErrorInfo{Code: util.ErrorCodePermissionDenied},
+		},
+		{
+			desc:         "unknown reason with a client status",
+			err:          apiError(http.StatusConflict, "duplicate", ""),
+			wantCategory: util.CategoryAgent,
+			wantInfo:     util.ErrorInfo{Code: util.ErrorCodeInvalidArgument},
+		},
+		{
+			desc:         "not an API error",
+			err:          errors.New("connection reset"),
+			wantCategory: util.CategoryAgent,
+			wantInfo:     util.ErrorInfo{Code: util.ErrorCodeInvalidArgument},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := bigquerycommon.ProcessAPIError("query validation failed", tc.err)
+			if got.Category() != tc.wantCategory {
+				t.Errorf("got category %s, want %s", got.Category(), tc.wantCategory)
+			}
+			if cse, ok := got.(*util.ClientServerError); ok && cse.Code != tc.wantStatus {
+				t.Errorf("got status %d, want %d", cse.Code, tc.wantStatus)
+			}
+			if diff := cmp.Diff(tc.wantInfo, got.ErrorInfo()); diff != "" {
+				t.Errorf("unexpected error info (-want +got):\n%s", diff)
+			}
+			if !errors.Is(got, tc.err) {
+				t.Errorf("error %v does not wrap %v", got, tc.err)
+			}
+			var gErr *googleapi.Error
+			if wantAPIError := errors.As(tc.err, &gErr); errors.As(got, &gErr) != wantAPIError {
+				t.Errorf("errors.As(*googleapi.Error) = %t, want %t", !wantAPIError, wantAPIError)
+			}
+		})
+	}
+}
+
+func TestProcessAPIErrorKeepsRedaction(t *testing.T) {
+	sql := "SELECT * FROM t WHERE email = 'alice@example.com'"
+	err := bigquerycommon.RedactSQLError(fakeRedactionSource{redact: true}, apiError(http.StatusBadRequest, "invalidQuery", "q"), sql)
+	got := bigquerycommon.ProcessAPIError("query validation failed", err)
+	var gErr *googleapi.Error
+	if !errors.As(got, &gErr) || gErr.Code != http.StatusBadRequest {
+		t.Fatalf("errors.As did not find the API error in %v", got)
+	}
+	if got.ErrorInfo().Details["location"] != "q" {
+		t.Errorf("got details %v, want location q", got.ErrorInfo().Details)
+	}
+}
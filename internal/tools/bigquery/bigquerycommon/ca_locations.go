@@ -0,0 +1,123 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	bigqueryds "github.com/googleapis/genai-toolbox/internal/sources/bigquery"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	"github.com/googleapis/genai-toolbox/internal/util"
+)
+
+// DefaultCALocation is the location of the Conversational Analytics API for
+// tools whose location is neither configured nor detected.
+const DefaultCALocation = "us"
+
+// LocationSource is the view of a BigQuery source needed to resolve the
+// location of its tools.
+type LocationSource interface {
+	BigQueryLocation() string
+}
+
+// detectedLocationSource is implemented by sources that detect their location
+// from their allowed datasets.
+type detectedLocationSource interface {
+	BigQueryDetectedLocation() string
+}
+
+// ResolveLocation returns the location of a tool of source, like the BigQuery
+// jobs of the source, with bigqueryds.ResolveLocation: override, the location
+// of the configuration of the tool, the location of the source, the location
+// detected from its allowed datasets, and fallback, e.g. DefaultCALocation.
+func ResolveLocation(override string, source LocationSource, fallback string) string {
+	var detected string
+	if s, ok := source.(detectedLocationSource); ok {
+		detected = s.BigQueryDetectedLocation()
+	}
+	return bigqueryds.ResolveLocation(override, source.BigQueryLocation(), detected, fallback)
+}
+
+// DatasetLocator looks up the locations of datasets with the credentials of an
+// invocation.
+type DatasetLocator interface {
+	DatasetLocation(ctx context.Context, accessToken tools.AccessToken, projectID, datasetID string) (string, error)
+}
+
+// CALocationCanRead returns whether the Conversational Analytics API in
+// caLocation can read tables in datasetLocation. The "global" location reads
+// any dataset, the "us" and "eu" locations read their multi-region and the
+// regions in it, e.g. "us-central1" or "europe-west1", and any other location
+// only reads the region of the same name.
+func CALocationCanRead(caLocation, datasetLocation string) bool {
+	caLocation, datasetLocation = strings.ToLower(caLocation), strings.ToLower(datasetLocation)
+	switch caLocation {
+	case "global":
+		return true
+	case "us":
+		return datasetLocation == "us" || strings.HasPrefix(datasetLocation, "us-")
+	case "eu":
+		return datasetLocation == "eu" || strings.HasPrefix(datasetLocation, "europe-")
+	default:
+		return datasetLocation == caLocation
+	}
+}
+
+// CheckDatasetLocations returns an error listing the datasets, as
+// "project.dataset", that the Conversational Analytics API in caLocation
+// cannot read, so that tools fail before calling the API, which rejects them
+// with an unclear error. Datasets whose location cannot be looked up are
+// skipped, and left for the API to report. If locator is a
+// DatasetCacheInvalidator, the location of a dataset that the API cannot read
+// is looked up once more without its cache, as the dataset may have been
+// created again in another location.
+func CheckDatasetLocations(ctx context.Context, toolName string, locator DatasetLocator, accessToken tools.AccessToken, caLocation string, datasets []string) util.ToolboxError {
+	mismatched := make(map[string]any)
+	var listed []string
+	seen := make(map[string]bool, len(datasets))
+	for _, ds := range datasets {
+		if seen[ds] {
+			continue
+		}
+		seen[ds] = true
+		projectID, datasetID, ok := strings.Cut(ds, ".")
+		if !ok {
+			continue
+		}
+		location, err := locator.DatasetLocation(ctx, accessToken, projectID, datasetID)
+		if err == nil && !CALocationCanRead(caLocation, location) && InvalidateDatasets(ctx, toolName, locator, []string{ds}) {
+			location, err = locator.DatasetLocation(ctx, accessToken, projectID, datasetID)
+		}
+		if err != nil {
+			if logger, lErr := util.LoggerFromContext(ctx); lErr == nil {
+				logger.DebugContext(ctx, "unable to look up the location of a dataset", "tool", toolName, "dataset", ds, "error", err)
+			}
+			continue
+		}
+		if !CALocationCanRead(caLocation, location) {
+			mismatched[ds] = location
+			listed = append(listed, fmt.Sprintf("'%s' (%s)", ds, location))
+		}
+	}
+	if len(mismatched) == 0 {
+		return nil
+	}
+	slices.Sort(listed)
+	msg := fmt.Sprintf("the Conversational Analytics API in location '%s' cannot read the tables of datasets %s; use tables of datasets in a location it can read, or set the location of the source to theirs", caLocation, strings.Join(listed, ", "))
+	return util.NewAgentError(msg, nil).WithDetails(map[string]any{"location": caLocation, "datasets": mismatched})
+}
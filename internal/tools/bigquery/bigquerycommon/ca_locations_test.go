@@ -0,0 +1,175 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	"github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+)
+
+// fakeLocator has datasets in two regions.
+type fakeLocator map[string]string
+
+func (l fakeLocator) DatasetLocation(ctx context.Context, accessToken tools.AccessToken, projectID, datasetID string) (string, error) {
+	if location, ok := l[projectID+"."+datasetID]; ok {
+		return location, nil
+	}
+	return "", fmt.Errorf("dataset %s.%s not found", projectID, datasetID)
+}
+
+// cachingLocator caches the locations of datasets like the BigQuery source.
+type cachingLocator struct {
+	// locations are the current locations of the datasets.
+	locations fakeLocator
+	cached    map[string]string
+	lookups   int
+}
+
+func (l *cachingLocator) DatasetLocation(ctx context.Context, accessToken tools.AccessToken, projectID, datasetID string) (string, error) {
+	if location, ok := l.cached[projectID+"."+datasetID]; ok {
+		return location, nil
+	}
+	l.lookups++
+	location, err := l.locations.DatasetLocation(ctx, accessToken, projectID, datasetID)
+	if err == nil {
+		l.cached[projectID+"."+datasetID] = location
+	}
+	return location, err
+}
+
+func (l *cachingLocator) InvalidateDataset(projectID, datasetID string) bool {
+	_, ok := l.cached[projectID+"."+datasetID]
+	delete(l.cached, projectID+"."+datasetID)
+	return ok
+}
+
+func TestCheckDatasetLocationsStaleCache(t *testing.T) {
+	ctx := context.Background()
+	// p.sales was deleted and created again in the US since its location was
+	// cached
+	locator := &cachingLocator{
+		locations: fakeLocator{"p.sales": "US", "p.eu_sales": "europe-west1"},
+		cached:    map[string]string{"p.sales": "europe-west1"},
+	}
+	if err := bigquerycommon.CheckDatasetLocations(ctx, "ask", locator, "", "us", []string{"p.sales"}); err != nil {
+		t.Fatalf("unexpected error after invalidating the cached location: %s", err)
+	}
+	if locator.lookups != 1 || locator.cached["p.sales"] != "US" {
+		t.Fatalf("expected the location to be looked up again once, got %d lookups and cache %v", locator.lookups, locator.cached)
+	}
+
+	// a dataset that really is in another location is looked up once more
+	if _, err := locator.DatasetLocation(ctx, "", "p", "eu_sales"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err := bigquerycommon.CheckDatasetLocations(ctx, "ask", locator, "", "us", []string{"p.sales", "p.eu_sales"})
+	if err == nil || !strings.Contains(err.Error(), "'p.eu_sales' (europe-west1)") {
+		t.Fatalf("expected an error for p.eu_sales, got %v", err)
+	}
+	if locator.lookups != 3 {
+		t.Fatalf("expected a single lookup of p.eu_sales without its cache, got %d lookups", locator.lookups)
+	}
+}
+
+func TestCALocationCanRead(t *testing.T) {
+	tcs := []struct {
+		ca, dataset string
+		want        bool
+	}{
+		{"global", "asia-northeast1", true},
+		{"us", "US", true},
+		{"US", "us-central1", true},
+		{"us", "EU", false},
+		{"us", "europe-west1", false},
+		{"eu", "EU", true},
+		{"eu", "europe-west1", true},
+		{"eu", "us-east1", false},
+		{"europe-west1", "europe-west1", true},
+		{"europe-west1", "EU", false},
+	}
+	for _, tc := range tcs {
+		if got := bigquerycommon.CALocationCanRead(tc.ca, tc.dataset); got != tc.want {
+			t.Errorf("CALocationCanRead(%q, %q) = %t, want %t", tc.ca, tc.dataset, got, tc.want)
+		}
+	}
+}
+
+func TestCheckDatasetLocations(t *testing.T) {
+	locator := fakeLocator{"p.us_sales": "US", "p.eu_sales": "europe-west1", "q.eu_logs": "EU"}
+	ctx := context.Background()
+
+	if err := bigquerycommon.CheckDatasetLocations(ctx, "ask", locator, "", "us", []string{"p.us_sales", "p.missing"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := bigquerycommon.CheckDatasetLocations(ctx, "ask", locator, "", "global", []string{"p.us_sales", "p.eu_sales"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err := bigquerycommon.CheckDatasetLocations(ctx, "ask", locator, "", "us", []string{"q.eu_logs", "p.us_sales", "p.eu_sales", "p.eu_sales"})
+	if err == nil {
+		t.Fatalf("expected an error for datasets outside of the US")
+	}
+	if want := "cannot read the tables of datasets 'p.eu_sales' (europe-west1), 'q.eu_logs' (EU)"; !strings.Contains(err.Error(), want) {
+		t.Fatalf("expected error containing %q, got %q", want, err)
+	}
+	wantDetails := map[string]any{
+		"location": "us",
+		"datasets": map[string]any{"p.eu_sales": "europe-west1", "q.eu_logs": "EU"},
+	}
+	if diff := cmp.Diff(wantDetails, err.ErrorInfo().Details); diff != "" {
+		t.Fatalf("unexpected details (-want +got):\n%s", diff)
+	}
+}
+
+// fakeLocationSource has a configured location.
+type fakeLocationSource struct{ location string }
+
+func (s fakeLocationSource) BigQueryLocation() string { return s.location }
+
+// fakeDetectingSource also has a location detected from its allowed datasets.
+type fakeDetectingSource struct {
+	fakeLocationSource
+	detected string
+}
+
+func (s fakeDetectingSource) BigQueryDetectedLocation() string { return s.detected }
+
+func TestResolveLocation(t *testing.T) {
+	tcs := []struct {
+		desc     string
+		override string
+		source   bigquerycommon.LocationSource
+		want     string
+	}{
+		{desc: "override", override: "asia-northeast1", source: fakeDetectingSource{fakeLocationSource{"US"}, "EU"}, want: "asia-northeast1"},
+		{desc: "location of the source", source: fakeDetectingSource{fakeLocationSource{"US"}, "EU"}, want: "US"},
+		{desc: "detected location", source: fakeDetectingSource{detected: "EU"}, want: "EU"},
+		{desc: "source without detection", source: fakeLocationSource{}, want: bigquerycommon.DefaultCALocation},
+		{desc: "fallback", source: fakeDetectingSource{}, want: bigquerycommon.DefaultCALocation},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := bigquerycommon.ResolveLocation(tc.override, tc.source, bigquerycommon.DefaultCALocation); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+)
+
+// ContextVersionKey is the name of the parameter selecting the context of a
+// data agent.
+const ContextVersionKey = "context_version"
+
+// Versions of the context of a data agent. The published context is the one
+// used by default; the staging context holds unpublished changes.
+const (
+	ContextVersionPublished = "published"
+	ContextVersionStaging   = "staging"
+)
+
+// contextFields are the fields of a data agent's dataAnalyticsAgent holding
+// its contexts, by the version they belong to. lastPublishedContext is a
+// snapshot of the published context, so it belongs to that version.
+var contextFields = map[string][]string{
+	ContextVersionPublished: {"publishedContext", "lastPublishedContext"},
+	ContextVersionStaging:   {"stagingContext"},
+}
+
+// NewContextVersionParameter returns the parameter selecting the context of a
+// data agent, which defaults to the published context.
+func NewContextVersionParameter(desc string) parameters.Parameter {
+	p := parameters.NewStringParameterWithEnumDefault(ContextVersionKey, ContextVersionPublished, desc, []string{ContextVersionPublished, ContextVersionStaging})
+	p.EnumIgnoreCase = true
+	return p
+}
+
+// ContextVersionEnum returns the value of the ContextVersion enum of the
+// Gemini Data Analytics API for version. An empty version is the published
+// one.
+func ContextVersionEnum(version string) string {
+	if version == ContextVersionStaging {
+		return "STAGING"
+	}
+	return "PUBLISHED"
+}
+
+// SelectContext returns a copy of the data agent document agent that only
+// holds the contexts of the given version, or of the published version if
+// version is empty. The document itself is not modified, so it can be shared,
+// e.g. by a cache.
+func SelectContext(agent map[string]any, version string) map[string]any {
+	if version == "" {
+		version = ContextVersionPublished
+	}
+	daa, ok := agent["dataAnalyticsAgent"].(map[string]any)
+	if !ok {
+		return agent
+	}
+	filtered := make(map[string]any, len(daa))
+	for k, v := range daa {
+		if !isOtherContext(k, version) {
+			filtered[k] = v
+		}
+	}
+	out := maps.Clone(agent)
+	out["dataAnalyticsAgent"] = filtered
+	return out
+}
+
+// isOtherContext reports whether field holds a context of another version
+// than version.
+func isOtherContext(field, version string) bool {
+	for other, fields := range contextFields {
+		if other != version && slices.Contains(fields, field) {
+			return true
+		}
+	}
+	return false
+}
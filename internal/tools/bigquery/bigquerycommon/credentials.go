@@ -0,0 +1,80 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	bigqueryds "github.com/googleapis/genai-toolbox/internal/sources/bigquery"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"golang.org/x/oauth2"
+)
+
+// CredentialSource is the view of a BigQuery source needed to choose the
+// credentials of the API calls of a tool invocation.
+type CredentialSource interface {
+	ClientAuthorizationMode() string
+	BigQueryTokenSourceWithScope(ctx context.Context, scopes []string) (oauth2.TokenSource, error)
+}
+
+// GetAPIToken returns the access token the tool named toolName calls Google
+// Cloud APIs with for an invocation with accessToken, and whether it is the
+// client's token. It is the client's token if the source uses it for the
+// invocation, and a token of the source's ADC credentials otherwise. If
+// validator is not nil, client tokens are validated before use; in preferred
+// mode, invalid client tokens fall back to the ADC credentials.
+func GetAPIToken(ctx context.Context, toolName string, source CredentialSource, accessToken tools.AccessToken, validator *TokenValidator) (string, bool, util.ToolboxError) {
+	mode := source.ClientAuthorizationMode()
+	if mode == bigqueryds.ClientAuthorizationRequired && accessToken == "" {
+		return "", false, util.NewClientServerError("tool is configured for client OAuth but no token was provided in the request header", http.StatusUnauthorized, nil)
+	}
+	tokenStr, err := bigqueryds.ClientToken(mode, accessToken)
+	if err != nil {
+		return "", false, util.NewClientServerError("error parsing access token", http.StatusUnauthorized, err)
+	}
+	if tokenStr != "" && validator != nil {
+		if err := validator.Validate(ctx, tokenStr, CloudPlatformScope); err != nil {
+			switch {
+			case !errors.Is(err, ErrInvalidToken):
+				return "", false, util.NewClientServerError("failed to validate client OAuth token", http.StatusInternalServerError, err)
+			case mode == bigqueryds.ClientAuthorizationRequired:
+				return "", false, util.NewClientServerError("client OAuth token is not valid", http.StatusUnauthorized, err)
+			default:
+				tokenStr = ""
+			}
+		}
+	}
+	if tokenStr != "" {
+		bigqueryds.LogCredentials(ctx, toolName, true)
+		return tokenStr, true, nil
+	}
+
+	tokenSource, err := source.BigQueryTokenSourceWithScope(ctx, nil)
+	if err != nil {
+		return "", false, util.NewClientServerError("failed to get token source", http.StatusInternalServerError, err)
+	}
+	if tokenSource == nil {
+		return "", false, util.NewClientServerError("cloud-platform token source is missing", http.StatusInternalServerError, nil)
+	}
+	token, err := tokenSource.Token()
+	if err != nil {
+		return "", false, util.NewClientServerError("failed to get token from cloud-platform token source", http.StatusInternalServerError, err)
+	}
+	bigqueryds.LogCredentials(ctx, toolName, false)
+	return token.AccessToken, false, nil
+}
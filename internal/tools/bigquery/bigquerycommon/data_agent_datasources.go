@@ -0,0 +1,195 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/googleapis/genai-toolbox/internal/util"
+)
+
+// Modes of the checkDataAgentDatasets option of the tools using data agents,
+// which check the BigQuery tables of the agents against the allowed datasets
+// of their source.
+const (
+	// DataAgentDatasetsEnforce refuses data agents with tables outside of the
+	// allowed datasets.
+	DataAgentDatasetsEnforce = "enforce"
+	// DataAgentDatasetsWarn uses such data agents, and lists the datasets
+	// outside of the allowed datasets as warnings in the result metadata.
+	DataAgentDatasetsWarn = "warn"
+)
+
+// ValidateDataAgentDatasetsMode returns an error if mode is not empty,
+// DataAgentDatasetsEnforce or DataAgentDatasetsWarn.
+func ValidateDataAgentDatasetsMode(mode string) error {
+	if mode != "" && mode != DataAgentDatasetsEnforce && mode != DataAgentDatasetsWarn {
+		return fmt.Errorf("invalid checkDataAgentDatasets %q: must be %q or %q", mode, DataAgentDatasetsEnforce, DataAgentDatasetsWarn)
+	}
+	return nil
+}
+
+// DataAgentDatasources are the datasources of a context of a data agent.
+type DataAgentDatasources struct {
+	// Kinds are the kinds of the datasources, e.g. "bq" or "looker", sorted.
+	Kinds []string
+	// Tables are the BigQuery tables of the "bq" datasource.
+	Tables []TableRef
+}
+
+// DisallowedDatasets returns the sorted datasets, "project.dataset", of the
+// BigQuery tables of d that isAllowed rejects. Datasources of other kinds,
+// such as Looker explores, are not BigQuery datasets and are not checked.
+func (d DataAgentDatasources) DisallowedDatasets(isAllowed func(projectID, datasetID string) bool) []string {
+	var datasets []string
+	for _, t := range d.Tables {
+		if !isAllowed(t.ProjectID, t.DatasetID) {
+			datasets = append(datasets, t.Dataset())
+		}
+	}
+	slices.Sort(datasets)
+	return slices.Compact(datasets)
+}
+
+// ParseDataAgentDatasources returns the datasources of the context of the
+// given version of the data agent document agent, as returned by the Gemini
+// Data Analytics API. The published context is used if version is empty, or
+// its last published snapshot if the agent has no published context.
+//
+// Field names are accepted in lowerCamelCase, as returned by the JSON API,
+// and in snake_case, as in older documents and the API reference. It is an
+// error if the context has no datasource references, or if a table reference
+// lacks its project or dataset, as the datasources cannot be verified then.
+func ParseDataAgentDatasources(agent map[string]any, version string) (DataAgentDatasources, error) {
+	if version == "" {
+		version = ContextVersionPublished
+	}
+	daa, ok := lookupField(agent, "dataAnalyticsAgent").(map[string]any)
+	if !ok {
+		return DataAgentDatasources{}, fmt.Errorf("data agent has no dataAnalyticsAgent")
+	}
+	var refs map[string]any
+	for _, field := range contextFields[version] {
+		if ctx, ok := lookupField(daa, field).(map[string]any); ok {
+			if refs, ok = lookupField(ctx, "datasourceReferences").(map[string]any); ok {
+				break
+			}
+		}
+	}
+	if refs == nil {
+		return DataAgentDatasources{}, fmt.Errorf("the %s context of the data agent has no datasource references", version)
+	}
+
+	var d DataAgentDatasources
+	for key, v := range refs {
+		if v == nil {
+			continue
+		}
+		d.Kinds = append(d.Kinds, camelCase(key))
+	}
+	slices.Sort(d.Kinds)
+
+	bq, _ := lookupField(refs, "bq").(map[string]any)
+	tableRefs, _ := lookupField(bq, "tableReferences").([]any)
+	for i, raw := range tableRefs {
+		ref, _ := raw.(map[string]any)
+		projectID, _ := lookupField(ref, "projectId").(string)
+		datasetID, _ := lookupField(ref, "datasetId").(string)
+		tableID, _ := lookupField(ref, "tableId").(string)
+		if projectID == "" || datasetID == "" {
+			return DataAgentDatasources{}, fmt.Errorf("table reference %d of the data agent has no project or dataset", i)
+		}
+		d.Tables = append(d.Tables, TableRef{ProjectID: strings.ToLower(projectID), DatasetID: datasetID, TableID: tableID})
+	}
+	return d, nil
+}
+
+// lookupField returns the field of m named camel, in lowerCamelCase, or in
+// its snake_case form. It returns nil if m has neither.
+func lookupField(m map[string]any, camel string) any {
+	if v, ok := m[camel]; ok {
+		return v
+	}
+	return m[snakeCase(camel)]
+}
+
+// snakeCase returns the snake_case form of the lowerCamelCase name s.
+func snakeCase(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsUpper(r) {
+			b.WriteByte('_')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// camelCase returns the lowerCamelCase form of the snake_case name s. Names
+// without underscores are returned as is.
+func camelCase(s string) string {
+	parts := strings.Split(s, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// DatasetAllowlistSource is implemented by sources that restrict the datasets
+// that tools can access.
+type DatasetAllowlistSource interface {
+	IsDatasetAllowed(projectID, datasetID string) bool
+	BigQueryAllowedDatasets() []string
+}
+
+// DataAgentDatasetsWarningsKey is the key of the result metadata listing the
+// warnings of DataAgentDatasetsWarn.
+const DataAgentDatasetsWarningsKey = "datasetWarnings"
+
+// CheckDataAgentDatasets checks the BigQuery tables of the context of the
+// given version of the data agent document agent against the allowed
+// datasets of source, in the checkDataAgentDatasets mode. In
+// DataAgentDatasetsEnforce mode, it returns a restriction error if a table is
+// outside of the allowed datasets, or if the datasources of the agent cannot
+// be verified. In DataAgentDatasetsWarn mode, it returns them as warnings
+// instead. Sources without allowed datasets are not checked.
+func CheckDataAgentDatasets(ctx context.Context, toolName string, source DatasetAllowlistSource, mode string, agent map[string]any, version string) ([]string, util.ToolboxError) {
+	if len(source.BigQueryAllowedDatasets()) == 0 {
+		return nil, nil
+	}
+	var msg string
+	var datasets []string
+	datasources, err := ParseDataAgentDatasources(agent, version)
+	if err != nil {
+		msg = fmt.Sprintf("unable to verify the datasets of the data agent against the allowed datasets: %s", err)
+	} else if datasets = datasources.DisallowedDatasets(source.IsDatasetAllowed); len(datasets) > 0 {
+		msg = fmt.Sprintf("the data agent uses datasets that are not in the allowed list: '%s'", strings.Join(datasets, "', '"))
+	}
+	switch {
+	case msg == "":
+		return nil, nil
+	case mode == DataAgentDatasetsWarn:
+		return []string{msg}, nil
+	default:
+		return nil, RestrictionError(ctx, toolName, msg, datasets...)
+	}
+}
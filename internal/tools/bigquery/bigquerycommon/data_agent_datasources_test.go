@@ -0,0 +1,138 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseDataAgentDatasources(t *testing.T) {
+	tcs := []struct {
+		desc    string
+		agent   string
+		version string
+		want    DataAgentDatasources
+		wantErr string
+	}{
+		{
+			desc:  "camel case",
+			agent: `{"dataAnalyticsAgent": {"publishedContext": {"datasourceReferences": {"bq": {"tableReferences": [{"projectId": "My-Project", "datasetId": "sales", "tableId": "orders"}]}}}}}`,
+			want:  DataAgentDatasources{Kinds: []string{"bq"}, Tables: []TableRef{{ProjectID: "my-project", DatasetID: "sales", TableID: "orders"}}},
+		},
+		{
+			desc:  "snake case",
+			agent: `{"data_analytics_agent": {"published_context": {"datasource_references": {"bq": {"table_references": [{"project_id": "p", "dataset_id": "sales", "table_id": "orders"}]}}}}}`,
+			want:  DataAgentDatasources{Kinds: []string{"bq"}, Tables: []TableRef{{ProjectID: "p", DatasetID: "sales", TableID: "orders"}}},
+		},
+		{
+			desc:  "last published context",
+			agent: `{"dataAnalyticsAgent": {"lastPublishedContext": {"datasourceReferences": {"bq": {"tableReferences": [{"projectId": "p", "datasetId": "sales"}]}}}}}`,
+			want:  DataAgentDatasources{Kinds: []string{"bq"}, Tables: []TableRef{{ProjectID: "p", DatasetID: "sales"}}},
+		},
+		{
+			desc:    "staging",
+			agent:   `{"dataAnalyticsAgent": {"publishedContext": {"datasourceReferences": {"bq": {}}}, "stagingContext": {"datasourceReferences": {"bq": {"tableReferences": [{"projectId": "p", "datasetId": "hr", "tableId": "t"}]}}}}}`,
+			version: ContextVersionStaging,
+			want:    DataAgentDatasources{Kinds: []string{"bq"}, Tables: []TableRef{{ProjectID: "p", DatasetID: "hr", TableID: "t"}}},
+		},
+		{
+			desc:  "looker",
+			agent: `{"dataAnalyticsAgent": {"publishedContext": {"datasourceReferences": {"looker": {"explore_references": [{"lookml_model": "m", "explore": "e"}]}}}}}`,
+			want:  DataAgentDatasources{Kinds: []string{"looker"}},
+		},
+		{
+			desc:    "no datasource references",
+			agent:   `{"dataAnalyticsAgent": {"stagingContext": {"datasourceReferences": {"bq": {}}}}}`,
+			wantErr: "the published context of the data agent has no datasource references",
+		},
+		{
+			desc:    "table without dataset",
+			agent:   `{"dataAnalyticsAgent": {"publishedContext": {"datasourceReferences": {"bq": {"tableReferences": [{"projectId": "p", "tableId": "t"}]}}}}}`,
+			wantErr: "table reference 0 of the data agent has no project or dataset",
+		},
+		{
+			desc:    "not a data analytics agent",
+			agent:   `{"name": "projects/p/locations/global/dataAgents/a"}`,
+			wantErr: "data agent has no dataAnalyticsAgent",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			var agent map[string]any
+			if err := json.Unmarshal([]byte(tc.agent), &agent); err != nil {
+				t.Fatalf("invalid test agent: %s", err)
+			}
+			got, err := ParseDataAgentDatasources(agent, tc.version)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected datasources (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+type fakeAllowlistSource []string
+
+func (s fakeAllowlistSource) IsDatasetAllowed(projectID, datasetID string) bool {
+	return slices.Contains(s, projectID+"."+datasetID)
+}
+
+func (s fakeAllowlistSource) BigQueryAllowedDatasets() []string { return s }
+
+func TestCheckDataAgentDatasets(t *testing.T) {
+	agent := map[string]any{"dataAnalyticsAgent": map[string]any{"publishedContext": map[string]any{"datasourceReferences": map[string]any{
+		"bq": map[string]any{"tableReferences": []any{
+			map[string]any{"projectId": "p", "datasetId": "sales", "tableId": "orders"},
+			map[string]any{"projectId": "p", "datasetId": "hr", "tableId": "salaries"},
+			map[string]any{"projectId": "p", "datasetId": "hr", "tableId": "reviews"},
+		}},
+	}}}}
+	ctx := context.Background()
+	const msg = "the data agent uses datasets that are not in the allowed list: 'p.hr'"
+
+	if warnings, err := CheckDataAgentDatasets(ctx, "tool", fakeAllowlistSource(nil), DataAgentDatasetsEnforce, agent, ""); err != nil || warnings != nil {
+		t.Errorf("expected a source without allowed datasets not to be checked, got %v, %v", warnings, err)
+	}
+	if _, err := CheckDataAgentDatasets(ctx, "tool", fakeAllowlistSource{"p.sales"}, DataAgentDatasetsEnforce, agent, ""); err == nil || !strings.Contains(err.Error(), msg) {
+		t.Errorf("expected error containing %q, got %v", msg, err)
+	}
+	warnings, err := CheckDataAgentDatasets(ctx, "tool", fakeAllowlistSource{"p.sales"}, DataAgentDatasetsWarn, agent, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diff := cmp.Diff([]string{msg}, warnings); diff != "" {
+		t.Errorf("unexpected warnings (-want +got):\n%s", diff)
+	}
+	if warnings, err := CheckDataAgentDatasets(ctx, "tool", fakeAllowlistSource{"p.sales", "p.hr"}, DataAgentDatasetsEnforce, agent, ""); err != nil || warnings != nil {
+		t.Errorf("expected an allowed data agent to pass, got %v, %v", warnings, err)
+	}
+	if err := ValidateDataAgentDatasetsMode("always"); err == nil {
+		t.Errorf("expected an invalid mode to be rejected")
+	}
+}
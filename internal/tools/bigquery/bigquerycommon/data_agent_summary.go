@@ -0,0 +1,143 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon
+
+// SummarizeKey is the name of the parameter of the data agent tools returning
+// a summary of the data agent instead of its document.
+const SummarizeKey = "summarize"
+
+// DataAgentSummary is a compact view of a data agent: its instructions and
+// the schemas of its datasources.
+type DataAgentSummary struct {
+	Name              string `json:"name"`
+	DisplayName       string `json:"displayName,omitempty"`
+	Description       string `json:"description,omitempty"`
+	SystemInstruction string `json:"systemInstruction,omitempty"`
+	// Tables are the BigQuery tables of the agent.
+	Tables []TableSummary `json:"tables"`
+	// Explores are the Looker explores of the agent.
+	Explores []ExploreSummary `json:"explores,omitempty"`
+}
+
+// TableSummary is a BigQuery table of a data agent.
+type TableSummary struct {
+	Project     string          `json:"project"`
+	Dataset     string          `json:"dataset"`
+	Table       string          `json:"table"`
+	Description string          `json:"description,omitempty"`
+	Columns     []ColumnSummary `json:"columns"`
+}
+
+// ExploreSummary is a Looker explore of a data agent.
+type ExploreSummary struct {
+	LookerInstanceURI string          `json:"lookerInstanceUri,omitempty"`
+	Model             string          `json:"model"`
+	Explore           string          `json:"explore"`
+	Columns           []ColumnSummary `json:"columns"`
+}
+
+// ColumnSummary is a column of a table or explore of a data agent. The
+// columns of records are listed after the record, as "record.column".
+type ColumnSummary struct {
+	Name        string `json:"name"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// SummarizeDataAgent returns the summary of the context of the given version
+// of the data agent document agent, as returned by the Gemini Data Analytics
+// API, like ParseDataAgentDatasources. Missing fields, such as the schemas of
+// tables that the agent does not describe, are left empty; the rest of the
+// document is dropped.
+func SummarizeDataAgent(agent map[string]any, version string) DataAgentSummary {
+	if version == "" {
+		version = ContextVersionPublished
+	}
+	summary := DataAgentSummary{Tables: []TableSummary{}}
+	summary.Name, _ = agent["name"].(string)
+	summary.DisplayName, _ = lookupField(agent, "displayName").(string)
+	summary.Description, _ = agent["description"].(string)
+
+	daa, _ := lookupField(agent, "dataAnalyticsAgent").(map[string]any)
+	var agentContext map[string]any
+	for _, field := range contextFields[version] {
+		if c, ok := lookupField(daa, field).(map[string]any); ok {
+			agentContext = c
+			break
+		}
+	}
+	summary.SystemInstruction, _ = lookupField(agentContext, "systemInstruction").(string)
+	refs, _ := lookupField(agentContext, "datasourceReferences").(map[string]any)
+
+	bq, _ := lookupField(refs, "bq").(map[string]any)
+	tableRefs, _ := lookupField(bq, "tableReferences").([]any)
+	for _, raw := range tableRefs {
+		ref, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		table := TableSummary{Columns: []ColumnSummary{}}
+		table.Project, _ = lookupField(ref, "projectId").(string)
+		table.Dataset, _ = lookupField(ref, "datasetId").(string)
+		table.Table, _ = lookupField(ref, "tableId").(string)
+		schema, _ := ref["schema"].(map[string]any)
+		table.Description, _ = schema["description"].(string)
+		table.Columns = appendColumns(table.Columns, "", schema)
+		summary.Tables = append(summary.Tables, table)
+	}
+
+	looker, _ := lookupField(refs, "looker").(map[string]any)
+	exploreRefs, _ := lookupField(looker, "exploreReferences").([]any)
+	for _, raw := range exploreRefs {
+		ref, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		explore := ExploreSummary{Columns: []ColumnSummary{}}
+		explore.LookerInstanceURI, _ = lookupField(ref, "lookerInstanceUri").(string)
+		explore.Model, _ = lookupField(ref, "lookmlModel").(string)
+		explore.Explore, _ = ref["explore"].(string)
+		schema, _ := ref["schema"].(map[string]any)
+		explore.Columns = appendColumns(explore.Columns, "", schema)
+		summary.Explores = append(summary.Explores, explore)
+	}
+	return summary
+}
+
+// appendColumns appends the fields of schema, a schema or a record field of
+// the API, and their subfields, to columns. Their names are prefixed with
+// prefix.
+func appendColumns(columns []ColumnSummary, prefix string, schema map[string]any) []ColumnSummary {
+	fields, _ := schema["fields"].([]any)
+	if subfields, ok := schema["subfields"].([]any); ok {
+		fields = subfields
+	}
+	for _, raw := range fields {
+		field, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := field["name"].(string)
+		if name == "" {
+			continue
+		}
+		column := ColumnSummary{Name: prefix + name}
+		column.Type, _ = field["type"].(string)
+		column.Description, _ = field["description"].(string)
+		columns = append(columns, column)
+		columns = appendColumns(columns, column.Name+".", field)
+	}
+	return columns
+}
@@ -0,0 +1,124 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+)
+
+func readDataAgent(t *testing.T, name string) map[string]any {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("unable to read fixture: %s", err)
+	}
+	var agent map[string]any
+	if err := json.Unmarshal(b, &agent); err != nil {
+		t.Fatalf("unable to decode fixture: %s", err)
+	}
+	return agent
+}
+
+func TestSummarizeDataAgent(t *testing.T) {
+	tcs := []struct {
+		desc    string
+		fixture string
+		version string
+		want    bigquerycommon.DataAgentSummary
+	}{
+		{
+			desc:    "bigquery tables",
+			fixture: "data_agent_bigquery.json",
+			want: bigquerycommon.DataAgentSummary{
+				Name:              "projects/my-project/locations/global/dataAgents/sales-agent",
+				DisplayName:       "Sales agent",
+				Description:       "Answers questions about sales.",
+				SystemInstruction: "Revenue is in USD.",
+				Tables: []bigquerycommon.TableSummary{
+					{
+						Project: "my-project", Dataset: "sales", Table: "orders", Description: "One row per order.",
+						Columns: []bigquerycommon.ColumnSummary{
+							{Name: "id", Type: "INT64", Description: "The order ID."},
+							{Name: "amount", Type: "NUMERIC"},
+							{Name: "customer", Type: "RECORD"},
+							{Name: "customer.name", Type: "STRING", Description: "The customer's name."},
+							{Name: "customer.country", Type: "STRING"},
+						},
+					},
+					// tables without a schema have no columns
+					{Project: "my-project", Dataset: "sales", Table: "returns", Columns: []bigquerycommon.ColumnSummary{}},
+				},
+			},
+		},
+		{
+			desc:    "staging context",
+			fixture: "data_agent_bigquery.json",
+			version: bigquerycommon.ContextVersionStaging,
+			want: bigquerycommon.DataAgentSummary{
+				Name:              "projects/my-project/locations/global/dataAgents/sales-agent",
+				DisplayName:       "Sales agent",
+				Description:       "Answers questions about sales.",
+				SystemInstruction: "Draft instructions.",
+				Tables: []bigquerycommon.TableSummary{
+					{Project: "my-project", Dataset: "sales", Table: "draft_orders", Columns: []bigquerycommon.ColumnSummary{}},
+				},
+			},
+		},
+		{
+			desc:    "looker explores in snake_case",
+			fixture: "data_agent_looker.json",
+			want: bigquerycommon.DataAgentSummary{
+				Name:              "projects/my-project/locations/global/dataAgents/looker-agent",
+				DisplayName:       "Looker agent",
+				SystemInstruction: "Use the orders explore.",
+				Tables:            []bigquerycommon.TableSummary{},
+				Explores: []bigquerycommon.ExploreSummary{
+					{
+						LookerInstanceURI: "https://example.looker.com", Model: "thelook", Explore: "orders",
+						Columns: []bigquerycommon.ColumnSummary{
+							{Name: "orders.count", Type: "NUMBER", Description: "Number of orders."},
+							{Name: "orders.created_date", Type: "DATE"},
+						},
+					},
+					{LookerInstanceURI: "https://example.looker.com", Model: "thelook", Explore: "users", Columns: []bigquerycommon.ColumnSummary{}},
+				},
+			},
+		},
+		{
+			desc:    "no context",
+			fixture: "data_agent_looker.json",
+			version: bigquerycommon.ContextVersionStaging,
+			want: bigquerycommon.DataAgentSummary{
+				Name:        "projects/my-project/locations/global/dataAgents/looker-agent",
+				DisplayName: "Looker agent",
+				Tables:      []bigquerycommon.TableSummary{},
+			},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := bigquerycommon.SummarizeDataAgent(readDataAgent(t, tc.fixture), tc.version)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected summary (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
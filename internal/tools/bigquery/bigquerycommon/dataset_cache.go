@@ -0,0 +1,58 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon
+
+import (
+	"context"
+	"strings"
+
+	"github.com/googleapis/genai-toolbox/internal/util"
+)
+
+// DatasetCacheInvalidator is implemented by sources that cache metadata of
+// datasets, such as their locations. The cached metadata expires after a TTL,
+// and is dropped in the background, but goes stale sooner when a dataset is
+// deleted and created again, e.g. in another location.
+type DatasetCacheInvalidator interface {
+	// InvalidateDataset drops the cached metadata of the dataset
+	// projectID.datasetID, and returns whether there was any.
+	InvalidateDataset(projectID, datasetID string) bool
+}
+
+// InvalidateDatasets drops the cached metadata of datasets, as
+// "project.dataset", if source is a DatasetCacheInvalidator, after an
+// operation of the tool named toolName failed in a way that stale metadata can
+// explain, e.g. a dataset that is not found. It returns whether any metadata
+// was cached, in which case the operation is worth retrying once.
+func InvalidateDatasets(ctx context.Context, toolName string, source any, datasets []string) bool {
+	invalidator, ok := source.(DatasetCacheInvalidator)
+	if !ok {
+		return false
+	}
+	var invalidated []string
+	for _, ds := range datasets {
+		projectID, datasetID, ok := strings.Cut(ds, ".")
+		if ok && invalidator.InvalidateDataset(projectID, datasetID) {
+			invalidated = append(invalidated, ds)
+		}
+	}
+	if len(invalidated) == 0 {
+		return false
+	}
+	if logger, err := util.LoggerFromContext(ctx); err == nil {
+		logger.DebugContext(ctx, "invalidated the cached metadata of datasets", "tool", toolName, "datasets", invalidated)
+	}
+	return true
+}
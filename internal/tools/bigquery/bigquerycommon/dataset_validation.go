@@ -0,0 +1,324 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	bigqueryapi "cloud.google.com/go/bigquery"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	bigqueryrestapi "google.golang.org/api/bigquery/v2"
+)
+
+// Reasons of the warnings of ValidateQueryAgainstAllowedDatasets.
+const (
+	// WarningDryRunStatisticsMissing is for dry runs without query
+	// statistics, whose tables and statement type are unknown.
+	WarningDryRunStatisticsMissing = "dryRunStatisticsMissing"
+	// WarningParserFallback is for statements whose dry run reported no
+	// tables, which are validated with the tables found by TableParser.
+	WarningParserFallback = "parserFallback"
+	// WarningUnresolvedTables is for names of one part where the parser
+	// expected tables, which are not validated, as they are common table
+	// expressions, temporary tables, or tables of an unknown dataset.
+	WarningUnresolvedTables = "unresolvedTables"
+	// WarningScriptNotParsed is for scripts that the parser could not
+	// analyze, which are validated with the tables of their dry run only.
+	WarningScriptNotParsed = "scriptNotParsed"
+	// WarningLinkedDatasets is for allowed datasets that are linked datasets
+	// of Analytics Hub listings, whose data is shared from other datasets.
+	WarningLinkedDatasets = "linkedDatasets"
+)
+
+// LinkedDatasetLocator is implemented by sources that look up whether
+// datasets are linked datasets of Analytics Hub listings.
+type LinkedDatasetLocator interface {
+	LinkedDatasetSource(ctx context.Context, accessToken tools.AccessToken, projectID, datasetID string) (string, error)
+}
+
+// ValidationWarning reports a decision of the validation of a query that let
+// it through without fully verifying it, or the provenance of the data it
+// reads.
+type ValidationWarning struct {
+	// Reason is the kind of the decision, e.g. WarningParserFallback.
+	Reason string `json:"reason"`
+	// Identifiers are the tables or names the decision is about, if any.
+	Identifiers []string `json:"identifiers,omitempty"`
+	Message     string   `json:"message"`
+}
+
+// TableReference is a table referenced by a query, with the entry of the
+// allowed datasets that let the query access it.
+type TableReference struct {
+	// Table is the table, "project.dataset.table".
+	Table string `json:"table"`
+	// AllowedDataset is the allowed dataset of the table, "project.dataset".
+	AllowedDataset string `json:"allowedDataset"`
+}
+
+// ValidationResult is the result of ValidateQueryAgainstAllowedDatasets.
+type ValidationResult struct {
+	// Tables are the validated tables, "project.dataset.table".
+	Tables []string
+	// References are the validated tables, sorted and without duplicates,
+	// with the allowed datasets they matched.
+	References []TableReference
+	// Warnings are the lenient decisions of the validation.
+	Warnings []ValidationWarning
+}
+
+// ValidateQueryAgainstAllowedDatasets validates sql, with its dry run
+// dryRunJob, against the allowed datasets of source, for the tool named
+// toolName. It returns a restriction error for the statements that cannot be
+// analyzed and for the tables outside of the allowed datasets, and the
+// decisions that let the query through without fully verifying it as
+// warnings. Sources without allowed datasets are not checked. If source is a
+// LinkedDatasetLocator, the datasets are looked up with accessToken, and the
+// linked datasets of Analytics Hub listings are explained in the restriction
+// error, or reported as a warning if they are allowed.
+func ValidateQueryAgainstAllowedDatasets(ctx context.Context, toolName string, source DatasetAllowlistSource, accessToken tools.AccessToken, sql, defaultProjectID string, dryRunJob *bigqueryrestapi.Job) (ValidationResult, util.ToolboxError) {
+	var result ValidationResult
+	if len(source.BigQueryAllowedDatasets()) == 0 {
+		return result, nil
+	}
+
+	// the query is parsed at most once, when the dry run is not enough
+	parse := sync.OnceValues(func() (SQLReferences, error) {
+		return ParseSQL(ctx, sql, ParseOptions{DefaultProjectID: defaultProjectID})
+	})
+	var statementType string
+	if dryRunJob != nil && dryRunJob.Statistics != nil && dryRunJob.Statistics.Query != nil {
+		statementType = dryRunJob.Statistics.Query.StatementType
+	} else {
+		result.Warnings = append(result.Warnings, ValidationWarning{
+			Reason:  WarningDryRunStatisticsMissing,
+			Message: "the dry run of the query has no statistics, so its tables are found by parsing the query",
+		})
+	}
+
+	switch statementType {
+	case "CREATE_SCHEMA", "DROP_SCHEMA", "ALTER_SCHEMA":
+		return result, RestrictionError(ctx, toolName, fmt.Sprintf("dataset-level operations like '%s' are not allowed when dataset restrictions are in place", statementType))
+	case "CREATE_FUNCTION", "SCRIPT":
+		// temporary functions are analyzed with the rest of the query,
+		// unless their body is not SQL
+		if _, parseErr := parse(); parseErr != nil {
+			if tbErr := ParseTimeoutError(parseErr); tbErr != nil {
+				return result, tbErr
+			}
+			var fnErr *UnanalyzableFunctionError
+			if errors.As(parseErr, &fnErr) {
+				return result, RestrictionError(ctx, toolName, fnErr.Error())
+			}
+			if statementType == "CREATE_FUNCTION" {
+				return result, RestrictionError(ctx, toolName, fmt.Sprintf("creating stored routines ('%s') is not allowed when dataset restrictions are in place, as their contents cannot be safely analyzed", statementType))
+			}
+			result.Warnings = append(result.Warnings, ValidationWarning{
+				Reason:  WarningScriptNotParsed,
+				Message: fmt.Sprintf("the script could not be parsed, so only the tables of its dry run are validated: %s", parseErr),
+			})
+		}
+	case "CREATE_TABLE_FUNCTION", "CREATE_PROCEDURE":
+		return result, RestrictionError(ctx, toolName, fmt.Sprintf("creating stored routines ('%s') is not allowed when dataset restrictions are in place, as their contents cannot be safely analyzed", statementType))
+	case "CALL":
+		return result, RestrictionError(ctx, toolName, fmt.Sprintf("calling stored procedures ('%s') is not allowed when dataset restrictions are in place, as their contents cannot be safely analyzed", statementType))
+	}
+
+	// Get all tables from the dry run result. This is the most reliable method.
+	tableNames := ReferencedTables(dryRunJob)
+	if len(tableNames) == 0 && statementType != "SELECT" {
+		// If dry run yields no tables, fall back to the parser for non-SELECT statements
+		// to catch unsafe operations like EXECUTE IMMEDIATE.
+		refs, parseErr := parse()
+		if parseErr != nil {
+			if tbErr := ParseTimeoutError(parseErr); tbErr != nil {
+				return result, tbErr
+			}
+			// If parsing fails (e.g., EXECUTE IMMEDIATE), we cannot guarantee safety, so we must fail.
+			return result, util.NewAgentError("could not parse tables from query to validate against allowed datasets", parseErr)
+		}
+		tableNames = refs.TableIDs
+		slices.Sort(tableNames)
+		result.Warnings = append(result.Warnings, ValidationWarning{
+			Reason:      WarningParserFallback,
+			Identifiers: tableNames,
+			Message:     "the dry run of the query reported no tables, so its tables are found by parsing the query",
+		})
+		if unresolved := refs.UnresolvedTables; len(unresolved) > 0 {
+			slices.Sort(unresolved)
+			result.Warnings = append(result.Warnings, ValidationWarning{
+				Reason:      WarningUnresolvedTables,
+				Identifiers: unresolved,
+				Message:     "names of one part where tables are expected are not validated, as they are common table expressions, temporary tables, or tables of an unknown dataset",
+			})
+		}
+	}
+
+	var violations []string
+	var references []TableReference
+	for _, tableID := range tableNames {
+		ref, err := CanonicalTableID(tableID, defaultProjectID)
+		if err != nil {
+			return result, util.NewAgentError("could not parse table from query to validate against allowed datasets", err)
+		}
+		if !source.IsDatasetAllowed(ref.ProjectID, ref.DatasetID) {
+			if !slices.Contains(violations, ref.Dataset()) {
+				violations = append(violations, ref.Dataset())
+			}
+			continue
+		}
+		references = append(references, TableReference{Table: ref.String(), AllowedDataset: ref.Dataset()})
+	}
+	locator, _ := source.(LinkedDatasetLocator)
+	if len(violations) > 0 {
+		slices.Sort(violations)
+		msg := fmt.Sprintf("query accesses dataset '%s', which is not in the allowed list", violations[0])
+		if len(violations) > 1 {
+			msg = fmt.Sprintf("query accesses datasets '%s', which are not in the allowed list", strings.Join(violations, "', '"))
+		}
+		linked := linkedDatasets(ctx, locator, accessToken, violations)
+		if len(linked) == 0 {
+			return result, RestrictionError(ctx, toolName, msg, violations...)
+		}
+		msg += fmt.Sprintf(". Linked datasets of Analytics Hub listings: %s. Access to a linked dataset is granted by subscribing to its listing rather than by IAM on the dataset, so each must be added to allowedDatasets explicitly to be queried", describeLinkedDatasets(linked))
+		tbErr := RestrictionError(ctx, toolName, msg, violations...)
+		if agentErr, ok := tbErr.(*util.AgentError); ok {
+			agentErr.Details["linkedDatasets"] = linked
+		}
+		return result, tbErr
+	}
+	slices.SortFunc(references, func(a, b TableReference) int { return strings.Compare(a.Table, b.Table) })
+	result.Tables = tableNames
+	result.References = slices.Compact(references)
+
+	var allowed []string
+	for _, ref := range result.References {
+		if !slices.Contains(allowed, ref.AllowedDataset) {
+			allowed = append(allowed, ref.AllowedDataset)
+		}
+	}
+	if linked := linkedDatasets(ctx, locator, accessToken, allowed); len(linked) > 0 {
+		result.Warnings = append(result.Warnings, ValidationWarning{
+			Reason:      WarningLinkedDatasets,
+			Identifiers: slices.Sorted(maps.Keys(linked)),
+			Message:     fmt.Sprintf("the query reads linked datasets of Analytics Hub listings: %s", describeLinkedDatasets(linked)),
+		})
+	}
+	return result, nil
+}
+
+// ParseTimeoutError returns the error reporting that the query could not be
+// parsed before the context of the invocation was done, if err, the error of
+// ParseSQL, is such an error, and nil otherwise.
+func ParseTimeoutError(err error) util.ToolboxError {
+	if !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return util.NewAgentError("timed out parsing the query to validate it against the restrictions of the source", err)
+}
+
+// linkedDatasets returns the source datasets of the linked datasets among
+// datasets, "project.dataset", by linked dataset, looked up with locator,
+// which may be nil. Datasets that cannot be looked up are left out.
+func linkedDatasets(ctx context.Context, locator LinkedDatasetLocator, accessToken tools.AccessToken, datasets []string) map[string]string {
+	if locator == nil {
+		return nil
+	}
+	linked := make(map[string]string)
+	for _, dataset := range datasets {
+		projectID, datasetID, ok := strings.Cut(dataset, ".")
+		if !ok {
+			continue
+		}
+		source, err := locator.LinkedDatasetSource(ctx, accessToken, projectID, datasetID)
+		if err != nil {
+			if logger, logErr := util.LoggerFromContext(ctx); logErr == nil {
+				logger.DebugContext(ctx, fmt.Sprintf("unable to look up whether dataset '%s' is a linked dataset: %s", dataset, err))
+			}
+			continue
+		}
+		if source != "" {
+			linked[dataset] = source
+		}
+	}
+	return linked
+}
+
+// describeLinkedDatasets lists the linked datasets of linked, sorted, with
+// the datasets they are shared from.
+func describeLinkedDatasets(linked map[string]string) string {
+	var parts []string
+	for _, dataset := range slices.Sorted(maps.Keys(linked)) {
+		parts = append(parts, fmt.Sprintf("'%s' (shared from '%s')", dataset, linked[dataset]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// LogValidationWarnings logs the warnings of the validation of a query of the
+// tool named toolName.
+func LogValidationWarnings(ctx context.Context, toolName string, warnings []ValidationWarning) {
+	logger, err := util.LoggerFromContext(ctx)
+	if err != nil {
+		return
+	}
+	for _, w := range warnings {
+		if w.Reason == WarningLinkedDatasets {
+			logger.InfoContext(ctx, fmt.Sprintf("tool %q: %s", toolName, w.Message), "reason", w.Reason, "identifiers", w.Identifiers)
+			continue
+		}
+		logger.WarnContext(ctx, fmt.Sprintf("tool %q let the query through without fully validating it: %s", toolName, w.Message), "reason", w.Reason, "identifiers", w.Identifiers)
+	}
+}
+
+// Modes of the verification of the tables of executed queries, see
+// DisallowedExecutedTables.
+const (
+	// ExecutedTablesWarn logs and reports the disallowed tables.
+	ExecutedTablesWarn = "warn"
+	// ExecutedTablesStrict also fails the query, without reading its results.
+	ExecutedTablesStrict = "strict"
+)
+
+// DisallowedExecutedTables returns the tables referenced by an executed query
+// job, from its statistics stats, that are outside of the allowed datasets of
+// source, "project.dataset.table", sorted. A query validated with its dry run
+// may reference other tables when it runs, e.g. if a view changed in between.
+// The tables of the jobs of scripts are not reported in their statistics.
+func DisallowedExecutedTables(source DatasetAllowlistSource, stats *bigqueryapi.JobStatistics) []string {
+	if stats == nil || len(source.BigQueryAllowedDatasets()) == 0 {
+		return nil
+	}
+	queryStats, ok := stats.Details.(*bigqueryapi.QueryStatistics)
+	if !ok {
+		return nil
+	}
+	var disallowed []string
+	for _, table := range queryStats.ReferencedTables {
+		if table == nil || source.IsDatasetAllowed(table.ProjectID, table.DatasetID) {
+			continue
+		}
+		ref := TableRef{ProjectID: strings.ToLower(table.ProjectID), DatasetID: table.DatasetID, TableID: table.TableID}
+		disallowed = append(disallowed, ref.String())
+	}
+	slices.Sort(disallowed)
+	return slices.Compact(disallowed)
+}
@@ -0,0 +1,270 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	bigqueryapi "cloud.google.com/go/bigquery"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	"github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+	bigqueryrestapi "google.golang.org/api/bigquery/v2"
+)
+
+type allowlist map[string]bool
+
+func (a allowlist) IsDatasetAllowed(projectID, datasetID string) bool {
+	return a[projectID+"."+datasetID]
+}
+
+func (a allowlist) BigQueryAllowedDatasets() []string {
+	var datasets []string
+	for d := range a {
+		datasets = append(datasets, d)
+	}
+	return datasets
+}
+
+func dryRunJob(statementType string, tables ...*bigqueryrestapi.TableReference) *bigqueryrestapi.Job {
+	return &bigqueryrestapi.Job{Statistics: &bigqueryrestapi.JobStatistics{Query: &bigqueryrestapi.JobStatistics2{
+		StatementType:    statementType,
+		ReferencedTables: tables,
+	}}}
+}
+
+func TestValidateQueryAgainstAllowedDatasets(t *testing.T) {
+	source := allowlist{"p.sales": true}
+	tcs := []struct {
+		desc       string
+		sql        string
+		job        *bigqueryrestapi.Job
+		wantTables []string
+		want       []bigquerycommon.ValidationWarning
+		wantErr    string
+	}{
+		{
+			desc:       "tables of the dry run",
+			sql:        "SELECT * FROM sales.orders",
+			job:        dryRunJob("SELECT", &bigqueryrestapi.TableReference{ProjectId: "p", DatasetId: "sales", TableId: "orders"}),
+			wantTables: []string{"p.sales.orders"},
+		},
+		{
+			desc: "parser fallback",
+			sql:  "CREATE TEMP TABLE staged AS SELECT * FROM sales.orders; INSERT INTO sales.archive SELECT * FROM staged",
+			job:  dryRunJob("SCRIPT"),
+			want: []bigquerycommon.ValidationWarning{
+				{Reason: bigquerycommon.WarningParserFallback, Identifiers: []string{"p.sales.archive", "p.sales.orders"}},
+				{Reason: bigquerycommon.WarningUnresolvedTables, Identifiers: []string{"staged"}},
+			},
+			wantTables: []string{"p.sales.archive", "p.sales.orders"},
+		},
+		{
+			desc: "parser fallback with a disallowed table",
+			sql:  "CREATE TEMP TABLE staged AS SELECT * FROM hr.salaries",
+			job:  dryRunJob("SCRIPT"),
+			// the warnings of a rejected query are not returned
+			wantErr: "query accesses dataset 'p.hr', which is not in the allowed list",
+		},
+		{
+			desc: "missing statistics",
+			sql:  "INSERT INTO sales.archive SELECT * FROM sales.orders",
+			job:  &bigqueryrestapi.Job{},
+			want: []bigquerycommon.ValidationWarning{
+				{Reason: bigquerycommon.WarningDryRunStatisticsMissing},
+				{Reason: bigquerycommon.WarningParserFallback, Identifiers: []string{"p.sales.archive", "p.sales.orders"}},
+			},
+			wantTables: []string{"p.sales.archive", "p.sales.orders"},
+		},
+		{
+			desc: "script the parser cannot analyze",
+			sql:  "DECLARE x INT64; SET x = (SELECT 1 FROM `sales.orders`",
+			job:  dryRunJob("SCRIPT", &bigqueryrestapi.TableReference{ProjectId: "p", DatasetId: "sales", TableId: "orders"}),
+			want: []bigquerycommon.ValidationWarning{
+				{Reason: bigquerycommon.WarningScriptNotParsed},
+			},
+			wantTables: []string{"p.sales.orders"},
+		},
+		{
+			desc:    "stored procedure",
+			sql:     "CALL sales.refresh()",
+			job:     dryRunJob("CALL"),
+			wantErr: "calling stored procedures ('CALL') is not allowed",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, tbErr := bigquerycommon.ValidateQueryAgainstAllowedDatasets(context.Background(), "execute_sql", source, "", tc.sql, "p", tc.job)
+			if tc.wantErr != "" {
+				if tbErr == nil || !strings.Contains(tbErr.Error(), tc.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.wantErr, tbErr)
+				}
+				return
+			}
+			if tbErr != nil {
+				t.Fatalf("unexpected error: %s", tbErr)
+			}
+			if diff := cmp.Diff(tc.wantTables, got.Tables); diff != "" {
+				t.Errorf("unexpected tables (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want, got.Warnings, cmpopts.IgnoreFields(bigquerycommon.ValidationWarning{}, "Message")); diff != "" {
+				t.Errorf("unexpected warnings (-want +got):\n%s", diff)
+			}
+			for _, w := range got.Warnings {
+				if w.Message == "" {
+					t.Errorf("expected a message for the warning %q", w.Reason)
+				}
+			}
+		})
+	}
+
+	// sources without allowed datasets are not checked
+	got, tbErr := bigquerycommon.ValidateQueryAgainstAllowedDatasets(context.Background(), "execute_sql", allowlist{}, "", "SELECT 1", "p", &bigqueryrestapi.Job{})
+	if tbErr != nil || len(got.Warnings) > 0 {
+		t.Errorf("got (%+v, %v), want no validation", got, tbErr)
+	}
+}
+
+// linkedAllowlist is an allowlist whose datasets may be linked datasets of
+// Analytics Hub listings, by their source datasets.
+type linkedAllowlist struct {
+	allowlist
+	linked map[string]string
+}
+
+func (a linkedAllowlist) LinkedDatasetSource(ctx context.Context, accessToken tools.AccessToken, projectID, datasetID string) (string, error) {
+	if projectID+"."+datasetID == "p.broken" {
+		return "", errors.New("dataset not found")
+	}
+	return a.linked[projectID+"."+datasetID], nil
+}
+
+func TestValidateLinkedDatasets(t *testing.T) {
+	source := linkedAllowlist{
+		allowlist: allowlist{"p.sales": true, "p.shared_weather": true},
+		linked:    map[string]string{"p.shared_weather": "publisher.weather", "p.shared_census": "publisher.census"},
+	}
+	table := func(datasetID string) *bigqueryrestapi.TableReference {
+		return &bigqueryrestapi.TableReference{ProjectId: "p", DatasetId: datasetID, TableId: "t"}
+	}
+	ctx := context.Background()
+
+	t.Run("linked dataset not allowed", func(t *testing.T) {
+		_, tbErr := bigquerycommon.ValidateQueryAgainstAllowedDatasets(ctx, "execute_sql", source, "", "SELECT 1", "p", dryRunJob("SELECT", table("sales"), table("shared_census"), table("hr")))
+		if tbErr == nil {
+			t.Fatalf("expected a restriction error")
+		}
+		want := "query accesses datasets 'p.hr', 'p.shared_census', which are not in the allowed list. " +
+			"Linked datasets of Analytics Hub listings: 'p.shared_census' (shared from 'publisher.census'). " +
+			"Access to a linked dataset is granted by subscribing to its listing rather than by IAM on the dataset, so each must be added to allowedDatasets explicitly to be queried"
+		if tbErr.Error() != want {
+			t.Errorf("got error %q, want %q", tbErr.Error(), want)
+		}
+		wantDetails := map[string]any{
+			"datasets":       []string{"p.hr", "p.shared_census"},
+			"linkedDatasets": map[string]string{"p.shared_census": "publisher.census"},
+		}
+		if diff := cmp.Diff(wantDetails, tbErr.ErrorInfo().Details); diff != "" {
+			t.Errorf("unexpected details (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("datasets that are not linked", func(t *testing.T) {
+		_, tbErr := bigquerycommon.ValidateQueryAgainstAllowedDatasets(ctx, "execute_sql", source, "", "SELECT 1", "p", dryRunJob("SELECT", table("hr"), table("broken")))
+		want := "query accesses datasets 'p.broken', 'p.hr', which are not in the allowed list"
+		if tbErr == nil || tbErr.Error() != want {
+			t.Fatalf("got error %v, want %q", tbErr, want)
+		}
+	})
+
+	t.Run("allowed linked dataset", func(t *testing.T) {
+		got, tbErr := bigquerycommon.ValidateQueryAgainstAllowedDatasets(ctx, "execute_sql", source, "", "SELECT 1", "p", dryRunJob("SELECT", table("sales"), table("shared_weather")))
+		if tbErr != nil {
+			t.Fatalf("unexpected error: %s", tbErr)
+		}
+		want := []bigquerycommon.ValidationWarning{{
+			Reason:      bigquerycommon.WarningLinkedDatasets,
+			Identifiers: []string{"p.shared_weather"},
+			Message:     "the query reads linked datasets of Analytics Hub listings: 'p.shared_weather' (shared from 'publisher.weather')",
+		}}
+		if diff := cmp.Diff(want, got.Warnings); diff != "" {
+			t.Errorf("unexpected warnings (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestValidateParseTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sql := "INSERT INTO ds.t SELECT * FROM ds.u"
+	for _, statementType := range []string{"INSERT", "SCRIPT"} {
+		_, tbErr := bigquerycommon.ValidateQueryAgainstAllowedDatasets(ctx, "execute_sql", allowlist{"p.ds": true}, "", sql, "p", dryRunJob(statementType))
+		if tbErr == nil || !errors.Is(tbErr, context.Canceled) {
+			t.Fatalf("got error %v for a %s statement, want one wrapping context.Canceled", tbErr, statementType)
+		}
+		if !strings.HasPrefix(tbErr.Error(), "timed out parsing the query") {
+			t.Errorf("unexpected error message %q", tbErr.Error())
+		}
+	}
+}
+
+func TestDisallowedExecutedTables(t *testing.T) {
+	source := allowlist{"p.sales": true}
+	stats := func(tables ...*bigqueryapi.Table) *bigqueryapi.JobStatistics {
+		return &bigqueryapi.JobStatistics{Details: &bigqueryapi.QueryStatistics{ReferencedTables: tables}}
+	}
+	tcs := []struct {
+		desc   string
+		source allowlist
+		stats  *bigqueryapi.JobStatistics
+		want   []string
+	}{
+		{
+			desc:   "allowed tables",
+			source: source,
+			stats:  stats(&bigqueryapi.Table{ProjectID: "p", DatasetID: "sales", TableID: "orders"}),
+		},
+		{
+			desc:   "tables that the dry run did not report",
+			source: source,
+			stats: stats(
+				&bigqueryapi.Table{ProjectID: "p", DatasetID: "sales", TableID: "orders"},
+				&bigqueryapi.Table{ProjectID: "p", DatasetID: "hr", TableID: "salaries"},
+				&bigqueryapi.Table{ProjectID: "other", DatasetID: "crm", TableID: "customers"},
+				&bigqueryapi.Table{ProjectID: "p", DatasetID: "hr", TableID: "salaries"},
+			),
+			want: []string{"other.crm.customers", "p.hr.salaries"},
+		},
+		{desc: "no statistics", source: source},
+		{desc: "statistics of another job", source: source, stats: &bigqueryapi.JobStatistics{Details: &bigqueryapi.LoadStatistics{}}},
+		{
+			desc:   "no allowed datasets",
+			source: allowlist{},
+			stats:  stats(&bigqueryapi.Table{ProjectID: "p", DatasetID: "hr", TableID: "salaries"}),
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := bigquerycommon.DisallowedExecutedTables(tc.source, tc.stats)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected tables (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon
+
+import "strings"
+
+// maxStatementSummary is the length of the summaries of statements returned
+// by DestructiveStatements.
+const maxStatementSummary = 100
+
+// scriptBlockKeywords start blocks of scripts whose body can directly follow
+// them, e.g. BEGIN DROP TABLE t; END.
+var scriptBlockKeywords = map[string]bool{
+	"begin":  true,
+	"loop":   true,
+	"repeat": true,
+	"else":   true,
+}
+
+// scriptConditionVerbs start script statements whose body follows a THEN or
+// a DO, e.g. IF ... THEN DROP TABLE t; END IF.
+var scriptConditionVerbs = map[string]bool{
+	"if":        true,
+	"elseif":    true,
+	"when":      true,
+	"case":      true,
+	"while":     true,
+	"for":       true,
+	"exception": true,
+}
+
+// DestructiveStatements returns the statements of sql, a query or a script,
+// that drop objects, truncate tables, or delete rows without a WHERE clause,
+// summarized with collapsed whitespace. It only uses the text of sql, as the
+// dry runs of scripts do not report their statements, and the statements in
+// the blocks of scripts, e.g. of IF or BEGIN, are checked as well.
+func DestructiveStatements(sql string) []string {
+	var found []string
+	tokens := sqlTokens(sql)
+	verb, start, depth, hasWhere := "", 0, 0, false
+	end := func(endOffset int) {
+		destructive := verb == verbDrop || verb == verbTruncate || (verb == verbDelete && !hasWhere)
+		if destructive {
+			found = append(found, summarizeStatement(sql[start:endOffset]))
+		}
+		verb, depth, hasWhere = "", 0, false
+	}
+	for _, tok := range tokens {
+		switch {
+		case tok.text == "(":
+			depth++
+		case tok.text == ")":
+			depth--
+		case tok.text == ";" && depth <= 0:
+			end(tok.start)
+		case !tok.word:
+		case verb == "":
+			if !scriptBlockKeywords[tok.text] {
+				verb, start = tok.text, tok.start
+			}
+		case depth == 0 && scriptConditionVerbs[verb] && (tok.text == "then" || tok.text == "do"):
+			// the condition is not a destructive statement, the body is
+			// checked as its own statement
+			verb = ""
+		case depth == 0 && tok.text == "where":
+			hasWhere = true
+		}
+	}
+	if verb != "" {
+		end(len(sql))
+	}
+	return found
+}
+
+// summarizeStatement returns stmt with collapsed whitespace, truncated to
+// maxStatementSummary bytes.
+func summarizeStatement(stmt string) string {
+	return Truncate(strings.Join(strings.Fields(stmt), " "), maxStatementSummary)
+}
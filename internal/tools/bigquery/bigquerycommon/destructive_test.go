@@ -0,0 +1,83 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+)
+
+func TestDestructiveStatements(t *testing.T) {
+	tcs := []struct {
+		desc string
+		sql  string
+		want []string
+	}{
+		{desc: "select", sql: "SELECT * FROM d.t WHERE drop = 'DROP TABLE x'"},
+		{desc: "delete with where", sql: "DELETE FROM d.t WHERE id = 1"},
+		{desc: "delete with where in a subquery only", sql: "DELETE FROM d.t AS t WHERE id IN (SELECT id FROM d.u WHERE x)"},
+		{desc: "delete without where", sql: "DELETE FROM d.t", want: []string{"DELETE FROM d.t"}},
+		{desc: "delete with a trailing comment", sql: "DELETE d.t\n  -- all rows\n;", want: []string{"DELETE d.t -- all rows"}},
+		{desc: "drop", sql: "DROP TABLE IF EXISTS `p.d.t`", want: []string{"DROP TABLE IF EXISTS `p.d.t`"}},
+		{desc: "truncate", sql: "truncate table d.t;", want: []string{"truncate table d.t"}},
+		{desc: "update and insert", sql: "UPDATE d.t SET x = 1 WHERE TRUE; INSERT INTO d.t (x) VALUES (1)"},
+		{desc: "alter table drop column", sql: "ALTER TABLE d.t DROP COLUMN x"},
+		{desc: "merge with delete", sql: "MERGE d.t USING d.u ON t.id = u.id WHEN MATCHED THEN DELETE"},
+		{
+			desc: "script",
+			sql: `DECLARE n INT64 DEFAULT 0;
+			DELETE FROM d.t WHERE id = 1;
+			DELETE FROM d.u;
+			CREATE TEMP TABLE x AS SELECT 1 AS y;
+			DROP TABLE d.v;`,
+			want: []string{"DELETE FROM d.u", "DROP TABLE d.v"},
+		},
+		{
+			desc: "script blocks",
+			sql: `BEGIN
+			  IF (SELECT COUNT(*) FROM d.t WHERE x) > 0 THEN
+			    TRUNCATE TABLE d.t;
+			  ELSE
+			    DELETE FROM d.t WHERE x;
+			  END IF;
+			  WHILE n < 2 DO DELETE d.u; SET n = n + 1; END WHILE;
+			EXCEPTION WHEN ERROR THEN
+			  DROP TABLE d.w;
+			END`,
+			want: []string{"TRUNCATE TABLE d.t", "DELETE d.u", "DROP TABLE d.w"},
+		},
+		{desc: "line endings", sql: "\uFEFF-- cleanup\rDROP TABLE d.t;\r\n-- more\r\nDELETE FROM d.u", want: []string{"DROP TABLE d.t", "DELETE FROM d.u"}},
+		{desc: "keywords in comments and literals", sql: "/* DROP TABLE d.t; */ SELECT 'DELETE FROM d.t' -- TRUNCATE TABLE d.t"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := bigquerycommon.DestructiveStatements(tc.sql)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected destructive statements (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDestructiveStatementsTruncatesSummaries(t *testing.T) {
+	sql := "DROP TABLE d." + strings.Repeat("t", 200)
+	got := bigquerycommon.DestructiveStatements(sql)
+	if len(got) != 1 || !strings.HasSuffix(got[0], "...") || len(got[0]) > 110 {
+		t.Errorf("expected a truncated summary, got %q", got)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/googleapis/genai-toolbox/internal/util"
+)
+
+// APIClientHeader is the header identifying the toolbox to Google APIs.
+const APIClientHeader = "X-Goog-API-Client"
+
+// reservedHeaders are headers that cannot be set through extra headers because
+// they carry credentials, billing or client identity set by the toolbox itself.
+var reservedHeaders = []string{
+	"Authorization",
+	"X-Goog-User-Project",
+	APIClientHeader,
+	"Content-Type",
+	"Host",
+	"Content-Length",
+}
+
+// ValidateExtraHeaders checks that user-configured headers have valid names and
+// values and do not override any reserved header.
+func ValidateExtraHeaders(headers map[string]string) error {
+	for name, value := range headers {
+		if !isHeaderToken(name) {
+			return fmt.Errorf("invalid header name %q", name)
+		}
+		for _, reserved := range reservedHeaders {
+			if strings.EqualFold(name, reserved) {
+				return fmt.Errorf("header %q cannot be set in extraHeaders", http.CanonicalHeaderKey(name))
+			}
+		}
+		if !isHeaderValue(value) {
+			return fmt.Errorf("invalid value for header %q: must not contain control characters", name)
+		}
+	}
+	return nil
+}
+
+// APIClientHeaderValue returns the X-Goog-API-Client value, with suffix
+// appended for partner attribution. The toolbox's own identifier is always kept.
+func APIClientHeaderValue(suffix string) string {
+	suffix = strings.TrimSpace(suffix)
+	if suffix == "" {
+		return util.GDAClientID
+	}
+	return util.GDAClientID + " " + suffix
+}
+
+// ValidateAPIClientSuffix checks that suffix can be appended to the
+// X-Goog-API-Client header.
+func ValidateAPIClientSuffix(suffix string) error {
+	if !isHeaderValue(suffix) {
+		return fmt.Errorf("invalid apiClientSuffix %q: must not contain control characters", suffix)
+	}
+	return nil
+}
+
+// isHeaderToken reports whether s is a valid HTTP header field name (RFC 7230 token).
+func isHeaderToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune("!#$%&'*+-.^_`|~", r):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isHeaderValue reports whether s is a valid HTTP header field value.
+func isHeaderValue(s string) bool {
+	for _, r := range s {
+		if (r < ' ' && r != '\t') || r == 0x7f {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon_test
+
+import (
+	"testing"
+
+	"github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+	"github.com/googleapis/genai-toolbox/internal/util"
+)
+
+func TestValidateExtraHeaders(t *testing.T) {
+	tcs := []struct {
+		desc    string
+		headers map[string]string
+		wantErr bool
+	}{
+		{desc: "no headers", headers: nil},
+		{desc: "custom headers", headers: map[string]string{"X-Routing-Key": "team-a", "X-Partner": "acme corp"}},
+		{desc: "authorization", headers: map[string]string{"Authorization": "Bearer abc"}, wantErr: true},
+		{desc: "authorization lower case", headers: map[string]string{"authorization": "Bearer abc"}, wantErr: true},
+		{desc: "user project", headers: map[string]string{"x-goog-user-project": "billing"}, wantErr: true},
+		{desc: "api client", headers: map[string]string{"X-Goog-Api-Client": "other"}, wantErr: true},
+		{desc: "invalid name", headers: map[string]string{"X Routing": "a"}, wantErr: true},
+		{desc: "empty name", headers: map[string]string{"": "a"}, wantErr: true},
+		{desc: "header injection", headers: map[string]string{"X-Routing": "a\r\nAuthorization: Bearer abc"}, wantErr: true},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := bigquerycommon.ValidateExtraHeaders(tc.headers)
+			if tc.wantErr && err == nil {
+				t.Fatalf("expected an error")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestAPIClientHeaderValue(t *testing.T) {
+	if got := bigquerycommon.APIClientHeaderValue(""); got != util.GDAClientID {
+		t.Errorf("got %q, want %q", got, util.GDAClientID)
+	}
+	want := util.GDAClientID + " partner/acme"
+	if got := bigquerycommon.APIClientHeaderValue(" partner/acme "); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if err := bigquerycommon.ValidateAPIClientSuffix("partner/acme\n"); err == nil {
+		t.Errorf("expected an error for a suffix with control characters")
+	}
+}
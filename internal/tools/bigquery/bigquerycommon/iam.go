@@ -0,0 +1,200 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/googleapis/genai-toolbox/internal/tools/googlehttp"
+	"github.com/googleapis/genai-toolbox/internal/util"
+)
+
+// IAMPolicyVersion is the policy version requested when reading IAM policies,
+// so that conditional bindings are returned and preserved on write.
+const IAMPolicyVersion = 3
+
+// DefaultIAMUpdateAttempts is the number of read-modify-write attempts made by
+// UpdateIAMPolicy before giving up on concurrent modifications.
+const DefaultIAMUpdateAttempts = 3
+
+// ErrIAMPolicyConflict is returned by a set function when the policy's etag no
+// longer matches the stored policy, i.e. it was modified concurrently.
+var ErrIAMPolicyConflict = errors.New("IAM policy was modified concurrently")
+
+// CallDataAgentIAMMethod calls method, e.g. "getIamPolicy", on the data agent
+// resourceName of the Gemini Data Analytics API at baseURL with the token
+// tokenStr, and decodes the response into out. Errors are returned with msg,
+// mapped like the errors of the API. A 409 response, which the API returns when
+// the etag of a policy is stale, wraps ErrIAMPolicyConflict.
+func CallDataAgentIAMMethod(ctx context.Context, baseURL, resourceName, method, tokenStr string, payload, out any, msg string) util.ToolboxError {
+	client := googlehttp.Client{Token: googlehttp.StaticToken(tokenStr)}
+	err := client.DoJSON(ctx, http.MethodPost, fmt.Sprintf("%s/%s:%s", baseURL, resourceName, method), payload, out)
+	if err == nil {
+		return nil
+	}
+	var apiErr *googlehttp.APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict {
+		return util.NewAgentError(msg, fmt.Errorf("%w: %w", ErrIAMPolicyConflict, err))
+	}
+	return googlehttp.ToolboxError(err, msg)
+}
+
+// IAMBinding associates a role with a list of members.
+type IAMBinding struct {
+	Role      string          `json:"role"`
+	Members   []string        `json:"members,omitempty"`
+	Condition json.RawMessage `json:"condition,omitempty"`
+}
+
+// IAMPolicy is an IAM policy as returned by getIamPolicy. Fields that the
+// toolbox does not modify are kept as raw JSON so they round-trip unchanged.
+type IAMPolicy struct {
+	Version      int             `json:"version,omitempty"`
+	Etag         string          `json:"etag,omitempty"`
+	Bindings     []IAMBinding    `json:"bindings,omitempty"`
+	AuditConfigs json.RawMessage `json:"auditConfigs,omitempty"`
+}
+
+// AddMembers grants role to members using the role's unconditional binding.
+// It reports whether the policy was changed.
+func (p *IAMPolicy) AddMembers(role string, members []string) bool {
+	for i := range p.Bindings {
+		b := &p.Bindings[i]
+		if b.Role != role || len(b.Condition) != 0 {
+			continue
+		}
+		changed := false
+		for _, m := range members {
+			if !slices.Contains(b.Members, m) {
+				b.Members = append(b.Members, m)
+				changed = true
+			}
+		}
+		return changed
+	}
+	if len(members) == 0 {
+		return false
+	}
+	p.Bindings = append(p.Bindings, IAMBinding{Role: role, Members: slices.Clone(members)})
+	return true
+}
+
+// RemoveMembers revokes role from members in the role's unconditional binding,
+// dropping the binding when it becomes empty. It reports whether the policy
+// was changed.
+func (p *IAMPolicy) RemoveMembers(role string, members []string) bool {
+	changed := false
+	bindings := p.Bindings[:0]
+	for _, b := range p.Bindings {
+		if b.Role == role && len(b.Condition) == 0 {
+			n := len(b.Members)
+			b.Members = slices.DeleteFunc(b.Members, func(m string) bool {
+				return slices.Contains(members, m)
+			})
+			if len(b.Members) != n {
+				changed = true
+			}
+			if len(b.Members) == 0 {
+				continue
+			}
+		}
+		bindings = append(bindings, b)
+	}
+	p.Bindings = bindings
+	return changed
+}
+
+// UpdateIAMPolicy performs an etag-respecting read-modify-write of an IAM
+// policy. The policy is read with get, changed in place by modify, and written
+// back with set. If set reports ErrIAMPolicyConflict the whole cycle is retried
+// up to attempts times. If modify reports no change, the current policy is
+// returned without being written.
+func UpdateIAMPolicy(ctx context.Context, attempts int, get func(context.Context) (*IAMPolicy, error), set func(context.Context, *IAMPolicy) (*IAMPolicy, error), modify func(*IAMPolicy) bool) (*IAMPolicy, error) {
+	if attempts <= 0 {
+		attempts = DefaultIAMUpdateAttempts
+	}
+	for i := 0; i < attempts; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		policy, err := get(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !modify(policy) {
+			return policy, nil
+		}
+		updated, err := set(ctx, policy)
+		if errors.Is(err, ErrIAMPolicyConflict) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return updated, nil
+	}
+	return nil, fmt.Errorf("giving up after %d attempts: %w", attempts, ErrIAMPolicyConflict)
+}
+
+// iamMemberPrefixes lists the member types accepted in IAM bindings, mapped to
+// whether the identifier after the prefix must be an email address.
+var iamMemberPrefixes = map[string]bool{
+	"user":           true,
+	"serviceAccount": true,
+	"group":          true,
+	"domain":         false,
+}
+
+// ValidateIAMMember checks that member is a well-formed IAM principal such as
+// `user:alice@example.com`, `group:team@example.com`, `domain:example.com`,
+// `allAuthenticatedUsers`, or a `principal://` / `principalSet://` identifier.
+func ValidateIAMMember(member string) error {
+	switch {
+	case member == "allUsers" || member == "allAuthenticatedUsers":
+		return nil
+	case strings.HasPrefix(member, "principal://") || strings.HasPrefix(member, "principalSet://"):
+		if strings.HasSuffix(member, "://") {
+			return fmt.Errorf("invalid IAM member %q: missing principal identifier", member)
+		}
+		return nil
+	}
+
+	kind, id, ok := strings.Cut(member, ":")
+	if !ok {
+		return fmt.Errorf("invalid IAM member %q: expected the form 'type:identifier', e.g. 'user:alice@example.com'", member)
+	}
+	needsEmail, known := iamMemberPrefixes[kind]
+	if !known {
+		return fmt.Errorf("invalid IAM member %q: unsupported member type %q", member, kind)
+	}
+	if id == "" || strings.TrimSpace(id) != id || strings.ContainsAny(id, " \t\n") {
+		return fmt.Errorf("invalid IAM member %q: identifier must be non-empty and must not contain whitespace", member)
+	}
+	if needsEmail {
+		local, domain, ok := strings.Cut(id, "@")
+		if !ok || local == "" || domain == "" || strings.Contains(domain, "@") {
+			return fmt.Errorf("invalid IAM member %q: %s members must be an email address", member, kind)
+		}
+	} else if strings.Contains(id, "@") {
+		return fmt.Errorf("invalid IAM member %q: %s members must be a domain name", member, kind)
+	}
+	return nil
+}
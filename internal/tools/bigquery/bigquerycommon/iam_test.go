@@ -0,0 +1,157 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+)
+
+func TestValidateIAMMember(t *testing.T) {
+	valid := []string{
+		"user:alice@example.com",
+		"serviceAccount:sa@my-project.iam.gserviceaccount.com",
+		"group:team@example.com",
+		"domain:example.com",
+		"allUsers",
+		"allAuthenticatedUsers",
+		"principal://iam.googleapis.com/locations/global/workforcePools/pool/subject/alice",
+		"principalSet://iam.googleapis.com/locations/global/workforcePools/pool/*",
+	}
+	for _, m := range valid {
+		if err := bigquerycommon.ValidateIAMMember(m); err != nil {
+			t.Errorf("ValidateIAMMember(%q) returned unexpected error: %s", m, err)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"alice@example.com",
+		"user:",
+		"user:alice",
+		"user:@example.com",
+		"user:alice@",
+		"user: alice@example.com",
+		"group:a@b@c",
+		"domain:alice@example.com",
+		"robot:alice@example.com",
+		"principal://",
+		"allusers",
+	}
+	for _, m := range invalid {
+		if err := bigquerycommon.ValidateIAMMember(m); err == nil {
+			t.Errorf("ValidateIAMMember(%q) expected an error", m)
+		}
+	}
+}
+
+func TestIAMPolicyAddRemoveMembers(t *testing.T) {
+	cond := json.RawMessage(`{"expression":"true"}`)
+	policy := &bigquerycommon.IAMPolicy{
+		Bindings: []bigquerycommon.IAMBinding{
+			{Role: "roles/viewer", Members: []string{"user:a@x.com"}},
+			{Role: "roles/viewer", Members: []string{"user:c@x.com"}, Condition: cond},
+		},
+	}
+
+	if !policy.AddMembers("roles/viewer", []string{"user:a@x.com", "user:b@x.com"}) {
+		t.Fatalf("expected AddMembers to change the policy")
+	}
+	if policy.AddMembers("roles/viewer", []string{"user:b@x.com"}) {
+		t.Fatalf("expected adding an existing member to be a no-op")
+	}
+	if !policy.AddMembers("roles/editor", []string{"user:a@x.com"}) {
+		t.Fatalf("expected AddMembers to create a new binding")
+	}
+	if !policy.RemoveMembers("roles/editor", []string{"user:a@x.com"}) {
+		t.Fatalf("expected RemoveMembers to change the policy")
+	}
+	if policy.RemoveMembers("roles/viewer", []string{"user:c@x.com"}) {
+		t.Fatalf("expected conditional bindings to be left untouched")
+	}
+
+	want := []bigquerycommon.IAMBinding{
+		{Role: "roles/viewer", Members: []string{"user:a@x.com", "user:b@x.com"}},
+		{Role: "roles/viewer", Members: []string{"user:c@x.com"}, Condition: cond},
+	}
+	if diff := cmp.Diff(want, policy.Bindings); diff != "" {
+		t.Fatalf("incorrect bindings: diff %v", diff)
+	}
+}
+
+func TestUpdateIAMPolicyRetriesOnConflict(t *testing.T) {
+	tcs := []struct {
+		desc      string
+		conflicts int
+		wantSets  int
+		wantErr   bool
+	}{
+		{desc: "no conflict", conflicts: 0, wantSets: 1},
+		{desc: "one conflict", conflicts: 1, wantSets: 2},
+		{desc: "persistent conflict", conflicts: 10, wantSets: 3, wantErr: true},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			gets, sets := 0, 0
+			get := func(context.Context) (*bigquerycommon.IAMPolicy, error) {
+				gets++
+				return &bigquerycommon.IAMPolicy{Etag: "etag"}, nil
+			}
+			set := func(_ context.Context, p *bigquerycommon.IAMPolicy) (*bigquerycommon.IAMPolicy, error) {
+				sets++
+				if sets <= tc.conflicts {
+					return nil, bigquerycommon.ErrIAMPolicyConflict
+				}
+				return p, nil
+			}
+			modify := func(p *bigquerycommon.IAMPolicy) bool {
+				return p.AddMembers("roles/viewer", []string{"user:a@x.com"})
+			}
+
+			got, err := bigquerycommon.UpdateIAMPolicy(context.Background(), 3, get, set, modify)
+			if tc.wantErr {
+				if !errors.Is(err, bigquerycommon.ErrIAMPolicyConflict) {
+					t.Fatalf("expected a conflict error, got %v", err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			} else if len(got.Bindings) != 1 {
+				t.Fatalf("expected the updated policy to be returned, got %+v", got)
+			}
+			if sets != tc.wantSets || gets != tc.wantSets {
+				t.Fatalf("expected %d reads and writes, got %d reads and %d writes", tc.wantSets, gets, sets)
+			}
+		})
+	}
+}
+
+func TestUpdateIAMPolicyNoChangeSkipsWrite(t *testing.T) {
+	get := func(context.Context) (*bigquerycommon.IAMPolicy, error) {
+		return &bigquerycommon.IAMPolicy{Etag: "etag"}, nil
+	}
+	set := func(context.Context, *bigquerycommon.IAMPolicy) (*bigquerycommon.IAMPolicy, error) {
+		t.Fatalf("set must not be called when the policy is unchanged")
+		return nil, nil
+	}
+	modify := func(*bigquerycommon.IAMPolicy) bool { return false }
+	if _, err := bigquerycommon.UpdateIAMPolicy(context.Background(), 3, get, set, modify); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
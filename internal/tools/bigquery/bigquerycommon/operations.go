@@ -0,0 +1,78 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/googleapis/genai-toolbox/internal/tools/googlehttp"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+)
+
+// WaitKey is the name of the parameter of the data agent tools returning
+// long-running operations, which waits for the operation to complete.
+const WaitKey = "wait"
+
+// DefaultOperationMaxWait is how long a long-running operation is waited for
+// when the tool does not set maxWait.
+const DefaultOperationMaxWait = 2 * time.Minute
+
+// operationMaxRetries is the number of times a poll of an operation is
+// retried after a transient error.
+const operationMaxRetries = 2
+
+// NewWaitParameter returns the parameter waiting for the operation of a tool
+// to complete, which defaults to false.
+func NewWaitParameter(desc string) parameters.Parameter {
+	return parameters.NewBooleanParameterWithDefault(WaitKey, false, desc)
+}
+
+// ParseOperationMaxWait parses the maxWait of a tool, e.g. "2m". It returns
+// DefaultOperationMaxWait if maxWait is empty.
+func ParseOperationMaxWait(maxWait string) (time.Duration, error) {
+	if maxWait == "" {
+		return DefaultOperationMaxWait, nil
+	}
+	d, err := time.ParseDuration(maxWait)
+	if err != nil {
+		return 0, fmt.Errorf("invalid maxWait %q: %w", maxWait, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid maxWait %q: must be positive", maxWait)
+	}
+	return d, nil
+}
+
+// WaitForOperation waits up to maxWait for op, an operation of the Gemini
+// Data Analytics API at baseURL, polling it with the token tokenStr. It
+// returns the response of the completed operation, e.g. the created data
+// agent, or the last status of the operation if it is not done in time, so
+// that the agent can report its progress. The error of a failed operation is
+// returned with msg, mapped like the errors of the API.
+func WaitForOperation(ctx context.Context, baseURL, tokenStr string, op googlehttp.Operation, maxWait time.Duration, msg string) (any, util.ToolboxError) {
+	client := googlehttp.Client{Token: googlehttp.StaticToken(tokenStr), MaxRetries: operationMaxRetries}
+	done, err := client.WaitOperation(ctx, baseURL, op, googlehttp.WaitOptions{MaxWait: maxWait})
+	if errors.Is(err, googlehttp.ErrOperationTimeout) {
+		return done, nil
+	}
+	if err != nil {
+		return nil, googlehttp.ToolboxError(err, msg)
+	}
+	return done.Response, nil
+}
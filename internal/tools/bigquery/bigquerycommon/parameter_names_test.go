@@ -0,0 +1,104 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon_test
+
+import (
+	"strings"
+	"testing"
+
+	yaml "github.com/goccy/go-yaml"
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	bigqueryds "github.com/googleapis/genai-toolbox/internal/sources/bigquery"
+	"github.com/googleapis/genai-toolbox/internal/testutils"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	_ "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigqueryanalyzecontribution"
+	_ "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigqueryanalyzequery"
+	"github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+	_ "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigqueryconversationalanalytics"
+	_ "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycreatedataagent"
+	_ "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigqueryexecutesql"
+	_ "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigqueryforecast"
+	_ "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerygetconversationtranscript"
+	_ "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerygetdataagentiampolicy"
+	_ "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerygetdataagentinfo"
+	_ "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerygetdatasetinfo"
+	_ "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerygettableinfo"
+	_ "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerylistdatasetids"
+	_ "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerylisttableids"
+	_ "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerysearchcatalog"
+	_ "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerysetdataagentiampolicy"
+	_ "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerysql"
+	_ "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerytestdataagentiampermissions"
+	_ "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigqueryupdatedataagent"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// TestCanonicalParameterNames checks that the registered BigQuery tools name
+// the parameters identifying a project, a dataset or a table with the
+// canonical names of bigquerycommon.
+func TestCanonicalParameterNames(t *testing.T) {
+	ctx, err := testutils.ContextWithNewLogger()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ctx = util.WithUserAgent(ctx, "test-agent")
+	src, err := bigqueryds.Config{Name: "my-bigquery", Type: bigqueryds.SourceType, Project: "my-project", UseClientOAuth: true}.Initialize(ctx, noop.NewTracerProvider().Tracer(""))
+	if err != nil {
+		t.Fatalf("unable to initialize source: %s", err)
+	}
+	srcs := map[string]sources.Source{"my-bigquery": src}
+	// the fields that tools require besides the common ones
+	requiredFields := map[string]string{
+		"bigquery-sql": "statement: SELECT 1\n",
+	}
+	canonical := map[string]string{
+		"projectid": bigquerycommon.ProjectKey,
+		"datasetid": bigquerycommon.DatasetKey,
+		"tableid":   bigquerycommon.TableKey,
+	}
+
+	var checked int
+	for _, registered := range tools.RegisteredTypes() {
+		if !strings.HasPrefix(registered.Type, "bigquery-") {
+			continue
+		}
+		checked++
+		in := "source: my-bigquery\ndescription: A tool.\n" + requiredFields[registered.Type]
+		cfg, err := tools.DecodeConfig(ctx, registered.Type, "my-tool", yaml.NewDecoder(strings.NewReader(in)))
+		if err != nil {
+			t.Fatalf("unable to decode a %q tool: %s", registered.Type, err)
+		}
+		tool, err := cfg.Initialize(srcs)
+		if err != nil {
+			t.Fatalf("unable to initialize a %q tool: %s", registered.Type, err)
+		}
+		for _, p := range tool.Manifest().Parameters {
+			normalized := strings.ToLower(strings.ReplaceAll(p.Name, "_", ""))
+			if want, ok := canonical[normalized]; ok {
+				t.Errorf("parameter %q of %q tools should be named %q", p.Name, registered.Type, want)
+			}
+			switch p.Name {
+			case bigquerycommon.ProjectKey, bigquerycommon.DatasetKey, bigquerycommon.TableKey:
+				if p.Type != "string" {
+					t.Errorf("parameter %q of %q tools has type %q, want string", p.Name, registered.Type, p.Type)
+				}
+			}
+		}
+	}
+	if checked == 0 {
+		t.Fatalf("no BigQuery tools are registered")
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	bigqueryapi "cloud.google.com/go/bigquery"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+	bigqueryrestapi "google.golang.org/api/bigquery/v2"
+)
+
+// ParameterMode is the style of the query parameters of a statement, as set
+// in the parameterMode of query jobs.
+type ParameterMode string
+
+const (
+	// ParameterModeNamed is for statements with named parameters, e.g. @id.
+	ParameterModeNamed ParameterMode = "NAMED"
+	// ParameterModePositional is for statements with positional parameters,
+	// ?, which take the query parameters in order.
+	ParameterModePositional ParameterMode = "POSITIONAL"
+)
+
+// ParseParameterMode returns the ParameterMode of a tool config value,
+// "named" or "positional" in any case, or "" for an empty value.
+func ParseParameterMode(s string) (ParameterMode, error) {
+	switch mode := ParameterMode(strings.ToUpper(s)); mode {
+	case "", ParameterModeNamed, ParameterModePositional:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid parameterMode %q: must be one of \"named\" or \"positional\"", s)
+	}
+}
+
+// placeholders returns the names of the named parameters of sql, e.g. "id"
+// for @id, and the number of its positional parameters. Placeholders in
+// comments, quoted identifiers and string literals are skipped, as are system
+// variables such as @@project_id.
+func placeholders(sql string) (named []string, positional int) {
+	tokens := sqlTokens(sql)
+	adjacent := func(k int) bool {
+		return k+1 < len(tokens) && tokens[k+1].start == tokens[k].end
+	}
+	for k := 0; k < len(tokens); k++ {
+		switch tokens[k].text {
+		case "?":
+			positional++
+		case "@":
+			if adjacent(k) && tokens[k+1].text == "@" {
+				// a system variable
+				k++
+				if adjacent(k) {
+					k++
+				}
+				continue
+			}
+			if adjacent(k) && tokens[k+1].word {
+				named = append(named, sql[tokens[k+1].start:tokens[k+1].end])
+				k++
+			}
+		}
+	}
+	return named, positional
+}
+
+// StatementParameterMode returns the mode of the query parameters of sql, or
+// "" if it has none. Statements cannot mix named and positional parameters,
+// which BigQuery rejects.
+func StatementParameterMode(sql string) (ParameterMode, error) {
+	named, positional := placeholders(sql)
+	switch {
+	case len(named) > 0 && positional > 0:
+		return "", fmt.Errorf("the statement mixes named (@%s) and positional (?) query parameters; use only one style", named[0])
+	case len(named) > 0:
+		return ParameterModeNamed, nil
+	case positional > 0:
+		return ParameterModePositional, nil
+	default:
+		return "", nil
+	}
+}
+
+// ResolveParameterMode returns the mode of the query parameters of sql:
+// declared, if set, which the placeholders of sql must match, otherwise the
+// mode of its placeholders. Statements without placeholders are named.
+func ResolveParameterMode(sql string, declared ParameterMode) (ParameterMode, error) {
+	mode, err := StatementParameterMode(sql)
+	if err != nil {
+		return "", err
+	}
+	if declared == "" {
+		if mode == "" {
+			return ParameterModeNamed, nil
+		}
+		return mode, nil
+	}
+	if mode != "" && mode != declared {
+		return "", fmt.Errorf("the statement uses %s query parameters, but the parameterMode is %s", strings.ToLower(string(mode)), strings.ToLower(string(declared)))
+	}
+	return declared, nil
+}
+
+// BuildQueryParameters returns the query parameters of sql, a statement with
+// parameters of mode, for the values of params in paramsMap: those of the
+// query and those of its dry run. Named parameters are named after params,
+// and positional parameters are unnamed, in the order of params, which must
+// have one parameter per placeholder of sql.
+func BuildQueryParameters(sql string, mode ParameterMode, params parameters.Parameters, paramsMap map[string]any) ([]bigqueryapi.QueryParameter, []*bigqueryrestapi.QueryParameter, error) {
+	if mode == ParameterModePositional {
+		if _, positional := placeholders(sql); positional != len(params) {
+			return nil, nil, fmt.Errorf("the statement has %d positional query parameters (?), but the tool has %d parameters", positional, len(params))
+		}
+	}
+
+	highLevelParams := make([]bigqueryapi.QueryParameter, 0, len(params))
+	lowLevelParams := make([]*bigqueryrestapi.QueryParameter, 0, len(params))
+	for _, p := range params {
+		name := p.GetName()
+		value := paramsMap[name]
+
+		if arrayParam, ok := p.(*parameters.ArrayParameter); ok {
+			arrayParamValue, ok := value.([]any)
+			if !ok {
+				return nil, nil, fmt.Errorf("unable to convert parameter `%s` to []any", name)
+			}
+			var err error
+			value, err = parameters.ConvertAnySliceToTyped(arrayParamValue, arrayParam.GetItems().GetType())
+			if err != nil {
+				return nil, nil, fmt.Errorf("unable to convert parameter `%s` from []any to typed slice: %w", name, err)
+			}
+		}
+
+		paramName := name
+		if mode == ParameterModePositional {
+			paramName = ""
+		}
+
+		// The parameter of the query, run by the client library.
+		valueType := p.GetType()
+		if arrayParam, ok := p.(*parameters.ArrayParameter); ok {
+			valueType = arrayParam.GetItems().GetType()
+		}
+		highLevelParams = append(highLevelParams, bigqueryapi.QueryParameter{
+			Name:  paramName,
+			Value: QueryParameterValue(valueType, value),
+		})
+
+		// The parameter of the dry run, run with the REST API.
+		lowLevelParam := &bigqueryrestapi.QueryParameter{
+			Name:           paramName,
+			ParameterType:  &bigqueryrestapi.QueryParameterType{},
+			ParameterValue: &bigqueryrestapi.QueryParameterValue{},
+		}
+		if arrayParam, ok := p.(*parameters.ArrayParameter); ok {
+			itemType, err := BQTypeStringFromToolType(arrayParam.GetItems().GetType())
+			if err != nil {
+				return nil, nil, err
+			}
+			lowLevelParam.ParameterType.Type = "ARRAY"
+			lowLevelParam.ParameterType.ArrayType = &bigqueryrestapi.QueryParameterType{Type: itemType}
+
+			sliceVal := reflect.ValueOf(value)
+			arrayValues := make([]*bigqueryrestapi.QueryParameterValue, sliceVal.Len())
+			for i := 0; i < sliceVal.Len(); i++ {
+				arrayValues[i] = &bigqueryrestapi.QueryParameterValue{
+					Value: QueryParameterValueString(sliceVal.Index(i).Interface()),
+				}
+			}
+			lowLevelParam.ParameterValue.ArrayValues = arrayValues
+		} else {
+			bqType, err := BQTypeStringFromToolType(p.GetType())
+			if err != nil {
+				return nil, nil, err
+			}
+			lowLevelParam.ParameterType.Type = bqType
+			lowLevelParam.ParameterValue.Value = QueryParameterValueString(value)
+		}
+		lowLevelParams = append(lowLevelParams, lowLevelParam)
+	}
+	return highLevelParams, lowLevelParams, nil
+}
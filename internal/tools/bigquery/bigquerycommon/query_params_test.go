@@ -0,0 +1,139 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+)
+
+func TestStatementParameterMode(t *testing.T) {
+	tcs := []struct {
+		desc    string
+		sql     string
+		want    bigquerycommon.ParameterMode
+		wantErr bool
+	}{
+		{desc: "no parameters", sql: "SELECT 1"},
+		{desc: "named", sql: "SELECT * FROM t WHERE id = @id AND name IN UNNEST(@names)", want: bigquerycommon.ParameterModeNamed},
+		{desc: "positional", sql: "SELECT * FROM t WHERE id = ? AND name = ?", want: bigquerycommon.ParameterModePositional},
+		{desc: "mixed", sql: "SELECT * FROM t WHERE id = @id AND name = ?", wantErr: true},
+		{desc: "system variables are not parameters", sql: "SELECT @@project_id, * FROM t WHERE id = ?", want: bigquerycommon.ParameterModePositional},
+		{desc: "placeholders in literals", sql: "SELECT 'who?', \"a@b.c\" FROM t WHERE id = @id", want: bigquerycommon.ParameterModeNamed},
+		{desc: "placeholders in comments", sql: "-- why?\nSELECT * /* @x */ FROM t # really?\nWHERE id = @id", want: bigquerycommon.ParameterModeNamed},
+		{desc: "placeholders in quoted identifiers", sql: "SELECT `odd?column` FROM t WHERE id = @id", want: bigquerycommon.ParameterModeNamed},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := bigquerycommon.StatementParameterMode(tc.sql)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("got mode %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestResolveParameterMode(t *testing.T) {
+	tcs := []struct {
+		desc     string
+		sql      string
+		declared bigquerycommon.ParameterMode
+		want     bigquerycommon.ParameterMode
+		wantErr  bool
+	}{
+		{desc: "defaults to named", sql: "SELECT 1", want: bigquerycommon.ParameterModeNamed},
+		{desc: "detected", sql: "SELECT ?", want: bigquerycommon.ParameterModePositional},
+		{desc: "declared without placeholders", sql: "SELECT 1", declared: bigquerycommon.ParameterModePositional, want: bigquerycommon.ParameterModePositional},
+		{desc: "declared and matching", sql: "SELECT @a", declared: bigquerycommon.ParameterModeNamed, want: bigquerycommon.ParameterModeNamed},
+		{desc: "declared and not matching", sql: "SELECT @a", declared: bigquerycommon.ParameterModePositional, wantErr: true},
+		{desc: "mixed", sql: "SELECT @a, ?", declared: bigquerycommon.ParameterModeNamed, wantErr: true},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := bigquerycommon.ResolveParameterMode(tc.sql, tc.declared)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("got mode %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseParameterMode(t *testing.T) {
+	for in, want := range map[string]bigquerycommon.ParameterMode{"": "", "named": bigquerycommon.ParameterModeNamed, "Positional": bigquerycommon.ParameterModePositional} {
+		if got, err := bigquerycommon.ParseParameterMode(in); err != nil || got != want {
+			t.Errorf("ParseParameterMode(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+	if _, err := bigquerycommon.ParseParameterMode("ordinal"); err == nil {
+		t.Errorf("expected an invalid mode to be rejected")
+	}
+}
+
+func TestBuildQueryParameters(t *testing.T) {
+	params := parameters.Parameters{
+		parameters.NewIntParameter("id", "the id"),
+		parameters.NewArrayParameter("names", "the names", parameters.NewStringParameter("name", "a name")),
+	}
+	paramsMap := map[string]any{"id": 7, "names": []any{"a", "b"}}
+
+	tcs := []struct {
+		desc      string
+		sql       string
+		mode      bigquerycommon.ParameterMode
+		wantNames []string
+		wantErr   bool
+	}{
+		{desc: "named", sql: "SELECT * FROM t WHERE id = @id AND name IN UNNEST(@names)", mode: bigquerycommon.ParameterModeNamed, wantNames: []string{"id", "names"}},
+		{desc: "positional", sql: "SELECT * FROM t WHERE id = ? AND name IN UNNEST(?)", mode: bigquerycommon.ParameterModePositional, wantNames: []string{"", ""}},
+		{desc: "too few placeholders", sql: "SELECT * FROM t WHERE id = ?", mode: bigquerycommon.ParameterModePositional, wantErr: true},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			high, low, err := bigquerycommon.BuildQueryParameters(tc.sql, tc.mode, params, paramsMap)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			var highNames, lowNames []string
+			for i := range high {
+				highNames = append(highNames, high[i].Name)
+				lowNames = append(lowNames, low[i].Name)
+			}
+			if diff := cmp.Diff(tc.wantNames, highNames); diff != "" {
+				t.Errorf("unexpected query parameter names (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantNames, lowNames); diff != "" {
+				t.Errorf("unexpected dry run parameter names (-want +got):\n%s", diff)
+			}
+			if got := low[1].ParameterType.ArrayType.Type; got != "STRING" {
+				t.Errorf("got array item type %q, want STRING", got)
+			}
+			if got := len(low[1].ParameterValue.ArrayValues); got != 2 {
+				t.Errorf("got %d array values, want 2", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+)
+
+// RedactedLiteral replaces the contents of string literals in redacted SQL.
+const RedactedLiteral = "<redacted>"
+
+// SQLRedactionSource is the view of a BigQuery source needed to redact the
+// SQL of a tool invocation from its errors and logs.
+type SQLRedactionSource interface {
+	BigQueryRedactSQL() bool
+	BigQueryLogUnredactedSQL() bool
+}
+
+// literalSpan is the byte range of the contents of a string literal, between
+// its quotes.
+type literalSpan struct {
+	start, end int
+}
+
+// stringLiterals returns the contents of the string and bytes literals of sql
+// in order. Comments and quoted identifiers are skipped.
+func stringLiterals(sql string) []literalSpan {
+	var spans []literalSpan
+	for i := 0; i < len(sql); {
+		rest := sql[i:]
+		switch {
+		case strings.HasPrefix(rest, "--"), rest[0] == '#':
+			end := strings.IndexAny(rest, "\r\n")
+			if end < 0 {
+				return spans
+			}
+			i += end + 1
+			continue
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				return spans
+			}
+			i += end + 4
+			continue
+		case rest[0] == '`':
+			end := strings.IndexByte(rest[1:], '`')
+			if end < 0 {
+				return spans
+			}
+			i += end + 2
+			continue
+		}
+
+		// Literals may have raw and bytes prefixes, e.g. r'...' or b"...".
+		prefix := 0
+		if i == 0 || !isIdentifierByte(sql[i-1]) {
+			for prefix < 2 && prefix < len(rest) && strings.ContainsRune("rRbB", rune(rest[prefix])) {
+				prefix++
+			}
+		}
+		if prefix == len(rest) || (rest[prefix] != '\'' && rest[prefix] != '"') {
+			i++
+			continue
+		}
+		raw := strings.ContainsAny(rest[:prefix], "rR")
+		quote := rest[prefix : prefix+1]
+		if strings.HasPrefix(rest[prefix:], strings.Repeat(quote, 3)) {
+			quote = strings.Repeat(quote, 3)
+		}
+		start := i + prefix + len(quote)
+		end := closingQuote(sql, start, quote, raw)
+		if end < 0 {
+			// an unterminated literal runs to the end of the statement
+			spans = append(spans, literalSpan{start, len(sql)})
+			return spans
+		}
+		spans = append(spans, literalSpan{start, end})
+		i = end + len(quote)
+	}
+	return spans
+}
+
+// closingQuote returns the index of the quote closing the literal whose
+// contents start at start, or -1 if it is not closed.
+func closingQuote(sql string, start int, quote string, raw bool) int {
+	for j := start; j < len(sql); j++ {
+		if !raw && sql[j] == '\\' {
+			j++
+			continue
+		}
+		if strings.HasPrefix(sql[j:], quote) {
+			return j
+		}
+	}
+	return -1
+}
+
+func isIdentifierByte(b byte) bool {
+	return b == '_' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+// RedactSQL replaces the contents of the string literals of sql with
+// RedactedLiteral, keeping identifiers, keywords and other literals.
+func RedactSQL(sql string) string {
+	spans := stringLiterals(sql)
+	if len(spans) == 0 {
+		return sql
+	}
+	var b strings.Builder
+	last := 0
+	for _, s := range spans {
+		b.WriteString(sql[last:s.start])
+		if s.end > s.start {
+			b.WriteString(RedactedLiteral)
+		}
+		last = s.end
+	}
+	b.WriteString(sql[last:])
+	return b.String()
+}
+
+// RedactSQLText replaces the contents of the string literals of sql wherever
+// they appear in text, e.g. in an error of the BigQuery API about sql.
+func RedactSQLText(text, sql string) string {
+	var literals []string
+	for _, s := range stringLiterals(sql) {
+		if s.end > s.start {
+			literals = append(literals, sql[s.start:s.end])
+		}
+	}
+	// Replace longer literals first so that literals containing others are
+	// redacted whole.
+	slices.SortFunc(literals, func(a, b string) int { return cmp.Compare(len(b), len(a)) })
+	for _, l := range slices.Compact(literals) {
+		text = strings.ReplaceAll(text, l, RedactedLiteral)
+	}
+	return text
+}
+
+// redactedError is an error whose message has the string literals of a SQL
+// statement redacted.
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+
+func (e *redactedError) Unwrap() error { return e.err }
+
+// RedactSQLError returns err with the string literals of sql redacted from its
+// message if the source redacts SQL, and err otherwise.
+func RedactSQLError(source SQLRedactionSource, err error, sql string) error {
+	if err == nil || !source.BigQueryRedactSQL() {
+		return err
+	}
+	return &redactedError{msg: RedactSQLText(err.Error(), sql), err: err}
+}
+
+// RedactSQLParams returns the values of params as they can be logged, see
+// tools.LoggableParams. If the source redacts SQL, the string literals of the
+// SQL of the parameters names are redacted.
+func RedactSQLParams(source SQLRedactionSource, params parameters.ParamValues, names ...string) map[string]any {
+	m := params.AsRedactedMap()
+	if !source.BigQueryRedactSQL() {
+		return m
+	}
+	for _, name := range names {
+		if sql, ok := m[name].(string); ok {
+			m[name] = RedactSQL(sql)
+		}
+	}
+	return m
+}
+
+// LogQuery logs the query a tool is executing at debug level. If the source
+// redacts SQL, its string literals are redacted, and the full query is only
+// logged if the source also sets logUnredactedSql.
+func LogQuery(ctx context.Context, source SQLRedactionSource, toolType, sql string) error {
+	logger, err := util.LoggerFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !source.BigQueryRedactSQL() {
+		logger.DebugContext(ctx, fmt.Sprintf("executing `%s` tool query: %s", toolType, sql))
+		return nil
+	}
+	logger.DebugContext(ctx, fmt.Sprintf("executing `%s` tool query: %s", toolType, RedactSQL(sql)))
+	if source.BigQueryLogUnredactedSQL() {
+		logger.DebugContext(ctx, fmt.Sprintf("unredacted `%s` tool query: %s", toolType, sql))
+	}
+	return nil
+}
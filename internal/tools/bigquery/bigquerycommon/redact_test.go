@@ -0,0 +1,153 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+	"google.golang.org/api/googleapi"
+)
+
+func TestRedactSQL(t *testing.T) {
+	tcs := []struct {
+		desc string
+		sql  string
+		want string
+	}{
+		{
+			desc: "no literals",
+			sql:  "SELECT id FROM `my-project.my_dataset.users` WHERE age > 30",
+			want: "SELECT id FROM `my-project.my_dataset.users` WHERE age > 30",
+		},
+		{
+			desc: "single and double quotes",
+			sql:  `SELECT * FROM my_dataset.users WHERE email = 'alice@example.com' OR name = "Alice"`,
+			want: `SELECT * FROM my_dataset.users WHERE email = '<redacted>' OR name = "<redacted>"`,
+		},
+		{
+			desc: "escaped quotes",
+			sql:  `SELECT 'it\'s a secret', "say \"hi\""`,
+			want: `SELECT '<redacted>', "<redacted>"`,
+		},
+		{
+			desc: "triple quotes",
+			sql:  "SELECT '''multi\n'line''' , \"\"\"other\"\"\"",
+			want: "SELECT '''<redacted>''' , \"\"\"<redacted>\"\"\"",
+		},
+		{
+			desc: "raw and bytes literals",
+			sql:  `SELECT r'C:\path', b"bytes", RB'\x00'`,
+			want: `SELECT r'<redacted>', b"<redacted>", RB'<redacted>'`,
+		},
+		{
+			desc: "empty literal",
+			sql:  "SELECT ''",
+			want: "SELECT ''",
+		},
+		{
+			desc: "comments and quoted identifiers are kept",
+			sql:  "SELECT `it's` FROM t -- don't\nWHERE a = 'x' /* 'y' */ # 'z'",
+			want: "SELECT `it's` FROM t -- don't\nWHERE a = '<redacted>' /* 'y' */ # 'z'",
+		},
+		{
+			desc: "unterminated literal",
+			sql:  "SELECT 'secret",
+			want: "SELECT '<redacted>",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := bigquerycommon.RedactSQL(tc.sql); got != tc.want {
+				t.Fatalf("unexpected redacted SQL:\ngot  %s\nwant %s", got, tc.want)
+			}
+		})
+	}
+}
+
+type fakeRedactionSource struct {
+	redact bool
+}
+
+func (s fakeRedactionSource) BigQueryRedactSQL() bool { return s.redact }
+
+func (s fakeRedactionSource) BigQueryLogUnredactedSQL() bool { return false }
+
+func TestRedactSQLError(t *testing.T) {
+	sql := "SELECT * FROM `my-project.my_dataset.users` WHERE ssn = '123-45-6789' AND name = 'Alice Smith'"
+	apiErr := &googleapi.Error{
+		Code:    400,
+		Message: "Could not cast literal '123-45-6789' to type INT64 at [1:58]; table my-project.my_dataset.users, value Alice Smith",
+	}
+
+	err := bigquerycommon.RedactSQLError(fakeRedactionSource{redact: true}, apiErr, sql)
+	msg := err.Error()
+	for _, literal := range []string{"123-45-6789", "Alice Smith"} {
+		if strings.Contains(msg, literal) {
+			t.Errorf("expected %q to be redacted, got %q", literal, msg)
+		}
+	}
+	for _, kept := range []string{"[1:58]", "my-project.my_dataset.users", "INT64", "400"} {
+		if !strings.Contains(msg, kept) {
+			t.Errorf("expected %q to be kept, got %q", kept, msg)
+		}
+	}
+	var gErr *googleapi.Error
+	if !errors.As(err, &gErr) || gErr.Code != 400 {
+		t.Errorf("expected the redacted error to wrap the API error, got %v", err)
+	}
+
+	if got := bigquerycommon.RedactSQLError(fakeRedactionSource{}, apiErr, sql); got != error(apiErr) {
+		t.Errorf("expected the error to be unchanged without redaction, got %v", got)
+	}
+}
+
+func TestRedactSQLTextOverlappingLiterals(t *testing.T) {
+	sql := "SELECT 'abc', 'abcdef'"
+	text := fmt.Sprintf("values %s and %s", "abcdef", "abc")
+	want := "values <redacted> and <redacted>"
+	if got := bigquerycommon.RedactSQLText(text, sql); got != want {
+		t.Fatalf("unexpected text: got %q, want %q", got, want)
+	}
+}
+
+func TestRedactSQLParams(t *testing.T) {
+	params := parameters.ParamValues{
+		{Name: "sql", Value: "SELECT * FROM my_dataset.users WHERE ssn = '123-45-6789'"},
+		{Name: "dry_run", Value: false},
+		{Name: "token", Value: "secret", Sensitive: true},
+	}
+
+	got := bigquerycommon.RedactSQLParams(fakeRedactionSource{redact: true}, params, "sql")
+	want := map[string]any{
+		"sql":     "SELECT * FROM my_dataset.users WHERE ssn = '<redacted>'",
+		"dry_run": false,
+		"token":   parameters.RedactedValue,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected params (-want +got):\n%s", diff)
+	}
+
+	got = bigquerycommon.RedactSQLParams(fakeRedactionSource{}, params, "sql")
+	want["sql"] = params[0].Value
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("expected the SQL to be kept without redaction (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// GDABaseURL is the endpoint of the Gemini Data Analytics API. Tools keep it
+// in a package variable, so that their tests can point it to a fake.
+const GDABaseURL = "https://geminidataanalytics.googleapis.com/v1beta"
+
+// Collection IDs of Gemini Data Analytics resources.
+const (
+	DataAgentsCollection    = "dataAgents"
+	ConversationsCollection = "conversations"
+)
+
+// ResolveDataAgentName returns the full resource name of a data agent. The id
+// can either be a bare data agent ID or a full resource name such as
+// `projects/p/locations/global/dataAgents/my-agent`, which is validated
+// against the given project and location.
+func ResolveDataAgentName(id, projectID, location string) (string, error) {
+	return resolveGDAResourceName(DataAgentsCollection, id, projectID, location)
+}
+
+// ResolveConversationName returns the full resource name of a conversation.
+// The id can either be a bare conversation ID or a full resource name, which is
+// validated against the given project and location.
+func ResolveConversationName(id, projectID, location string) (string, error) {
+	return resolveGDAResourceName(ConversationsCollection, id, projectID, location)
+}
+
+func resolveGDAResourceName(collection, id, projectID, location string) (string, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return "", fmt.Errorf("%s ID must not be empty", collection)
+	}
+
+	if !strings.HasPrefix(id, "projects/") {
+		if strings.Contains(id, "/") {
+			return "", fmt.Errorf("invalid %s ID %q: expected a bare ID or a resource name of the form 'projects/{project}/locations/{location}/%s/{id}'", collection, id, collection)
+		}
+		return fmt.Sprintf("projects/%s/locations/%s/%s/%s", projectID, location, collection, url.PathEscape(id)), nil
+	}
+
+	parts := strings.Split(id, "/")
+	if len(parts) != 6 || parts[2] != "locations" || parts[4] != collection || parts[1] == "" || parts[3] == "" || parts[5] == "" {
+		return "", fmt.Errorf("invalid resource name %q: expected 'projects/{project}/locations/{location}/%s/{id}'", id, collection)
+	}
+	if parts[1] != projectID {
+		return "", fmt.Errorf("resource name %q is in project %q, but the source is configured for project %q", id, parts[1], projectID)
+	}
+	if parts[3] != location {
+		return "", fmt.Errorf("resource name %q is in location %q, but the source is configured for location %q", id, parts[3], location)
+	}
+	return id, nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+)
+
+func TestResolveDataAgentName(t *testing.T) {
+	testCases := []struct {
+		name    string
+		id      string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "bare id",
+			id:   "my-agent",
+			want: "projects/my-project/locations/global/dataAgents/my-agent",
+		},
+		{
+			name: "bare id is escaped",
+			id:   "my agent",
+			want: "projects/my-project/locations/global/dataAgents/my%20agent",
+		},
+		{
+			name: "full resource name",
+			id:   "projects/my-project/locations/global/dataAgents/my-agent",
+			want: "projects/my-project/locations/global/dataAgents/my-agent",
+		},
+		{
+			name: "full resource name with surrounding whitespace",
+			id:   "  projects/my-project/locations/global/dataAgents/my-agent\n",
+			want: "projects/my-project/locations/global/dataAgents/my-agent",
+		},
+		{
+			name:    "mismatched project",
+			id:      "projects/other-project/locations/global/dataAgents/my-agent",
+			wantErr: `is in project "other-project", but the source is configured for project "my-project"`,
+		},
+		{
+			name:    "mismatched location",
+			id:      "projects/my-project/locations/us/dataAgents/my-agent",
+			wantErr: `is in location "us", but the source is configured for location "global"`,
+		},
+		{
+			name:    "wrong collection",
+			id:      "projects/my-project/locations/global/conversations/c1",
+			wantErr: "invalid resource name",
+		},
+		{
+			name:    "truncated resource name",
+			id:      "projects/my-project/locations/global/dataAgents",
+			wantErr: "invalid resource name",
+		},
+		{
+			name:    "partial path",
+			id:      "dataAgents/my-agent",
+			wantErr: "expected a bare ID or a resource name",
+		},
+		{
+			name:    "empty",
+			id:      " ",
+			wantErr: "must not be empty",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := bigquerycommon.ResolveDataAgentName(tc.id, "my-project", "global")
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("ResolveDataAgentName(%q) error = %v, want error containing %q", tc.id, err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveDataAgentName(%q) unexpected error: %v", tc.id, err)
+			}
+			if got != tc.want {
+				t.Errorf("ResolveDataAgentName(%q) = %q, want %q", tc.id, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDataAgentIDExamples(t *testing.T) {
+	for _, example := range bigquerycommon.DataAgentIDExamples() {
+		got, err := bigquerycommon.ResolveDataAgentName(example.(string), "my-project", "global")
+		if err != nil {
+			t.Fatalf("unexpected error resolving example %q: %s", example, err)
+		}
+		if want := "projects/my-project/locations/global/dataAgents/my-agent"; got != want {
+			t.Errorf("ResolveDataAgentName(%q) = %q, want %q", example, got, want)
+		}
+	}
+}
+
+func TestResolveConversationName(t *testing.T) {
+	got, err := bigquerycommon.ResolveConversationName("projects/p/locations/us/conversations/c1", "p", "us")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "projects/p/locations/us/conversations/c1" {
+		t.Errorf("unexpected resource name: %q", got)
+	}
+
+	got, err = bigquerycommon.ResolveConversationName("c1", "p", "us")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "projects/p/locations/us/conversations/c1" {
+		t.Errorf("unexpected resource name: %q", got)
+	}
+
+	if _, err := bigquerycommon.ResolveConversationName("projects/p/locations/us/dataAgents/a", "p", "us"); err == nil {
+		t.Errorf("expected an error for a data agent resource name")
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/googleapis/genai-toolbox/internal/util"
+	bigqueryrestapi "google.golang.org/api/bigquery/v2"
+)
+
+// listedColumns is the number of columns named in the errors of results that
+// exceed their limits.
+const listedColumns = 10
+
+// variableCellBytes is the estimated size of the cells of types whose size
+// depends on their value, such as STRING or JSON.
+const variableCellBytes = 64
+
+// cellBytes are the sizes of the cells of the types of fixed size, see
+// https://cloud.google.com/bigquery/pricing#data.
+var cellBytes = map[string]int64{
+	"BOOL":       1,
+	"BOOLEAN":    1,
+	"INT64":      8,
+	"INTEGER":    8,
+	"FLOAT64":    8,
+	"FLOAT":      8,
+	"DATE":       8,
+	"DATETIME":   8,
+	"TIME":       8,
+	"TIMESTAMP":  8,
+	"NUMERIC":    16,
+	"INTERVAL":   16,
+	"RANGE":      16,
+	"BIGNUMERIC": 32,
+}
+
+// ResultLimits bound the result of the queries of a tool, as estimated from
+// the schema of their dry run before they run. Zero values are not limits.
+type ResultLimits struct {
+	// MaxColumns is the maximum number of columns of the result.
+	MaxColumns int
+	// MaxCellBytes is the maximum estimated size in bytes of the cells of a
+	// row of the result.
+	MaxCellBytes int64
+}
+
+// ToolResultLimits validates the maxColumns and maxCellBytes of the
+// configuration of a tool, and returns them as the limits of its results.
+func ToolResultLimits(maxColumns *int, maxCellBytes *int64) (ResultLimits, error) {
+	var limits ResultLimits
+	if maxColumns != nil {
+		if *maxColumns <= 0 {
+			return ResultLimits{}, fmt.Errorf("maxColumns must be positive, got %d", *maxColumns)
+		}
+		limits.MaxColumns = *maxColumns
+	}
+	if maxCellBytes != nil {
+		if *maxCellBytes <= 0 {
+			return ResultLimits{}, fmt.Errorf("maxCellBytes must be positive, got %d", *maxCellBytes)
+		}
+		limits.MaxCellBytes = *maxCellBytes
+	}
+	return limits, nil
+}
+
+// EstimateCellBytes estimates the size in bytes of the cells of a row with
+// fields. Cells of variable size are estimated at variableCellBytes, and
+// repeated fields as if they held one value.
+func EstimateCellBytes(fields []*bigqueryrestapi.TableFieldSchema) int64 {
+	var total int64
+	for _, f := range fields {
+		if f == nil {
+			continue
+		}
+		switch t := strings.ToUpper(f.Type); t {
+		case "RECORD", "STRUCT":
+			total += EstimateCellBytes(f.Fields)
+		default:
+			size, ok := cellBytes[t]
+			if !ok {
+				size = variableCellBytes
+			}
+			total += size
+		}
+	}
+	return total
+}
+
+// CheckResultLimits returns an error, advising the agent to select specific
+// columns, if the result of the query validated by dryRunJob exceeds limits.
+// Queries without a result schema, such as DML statements, are not limited.
+func CheckResultLimits(dryRunJob *bigqueryrestapi.Job, limits ResultLimits) util.ToolboxError {
+	if limits == (ResultLimits{}) || dryRunJob == nil || dryRunJob.Statistics == nil || dryRunJob.Statistics.Query == nil || dryRunJob.Statistics.Query.Schema == nil {
+		return nil
+	}
+	fields := dryRunJob.Statistics.Query.Schema.Fields
+	if limits.MaxColumns > 0 && len(fields) > limits.MaxColumns {
+		return resultLimitError(fmt.Sprintf("the result of the query would have %d columns, more than the %d allowed", len(fields), limits.MaxColumns), fields)
+	}
+	if limits.MaxCellBytes > 0 {
+		if size := EstimateCellBytes(fields); size > limits.MaxCellBytes {
+			return resultLimitError(fmt.Sprintf("the rows of the result of the query are estimated at %d bytes, more than the %d allowed", size, limits.MaxCellBytes), fields)
+		}
+	}
+	return nil
+}
+
+func resultLimitError(reason string, fields []*bigqueryrestapi.TableFieldSchema) util.ToolboxError {
+	names := make([]string, 0, listedColumns)
+	for _, f := range fields {
+		if len(names) == listedColumns {
+			break
+		}
+		if f != nil {
+			names = append(names, f.Name)
+		}
+	}
+	columns := strings.Join(names, ", ")
+	if len(fields) > listedColumns {
+		columns += ", ..."
+	}
+	msg := fmt.Sprintf("%s (%d columns: %s). Select only the specific columns you need instead of all of them, for example instead of `SELECT *`.", reason, len(fields), columns)
+	return util.NewAgentError(msg, nil).WithDetails(map[string]any{"columnCount": len(fields), "columns": names})
+}
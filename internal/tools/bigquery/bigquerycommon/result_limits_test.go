@@ -0,0 +1,139 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	bigqueryrestapi "google.golang.org/api/bigquery/v2"
+)
+
+// dryRunWithSchema returns a dry run job whose result has fields.
+func dryRunWithSchema(fields ...*bigqueryrestapi.TableFieldSchema) *bigqueryrestapi.Job {
+	return &bigqueryrestapi.Job{
+		Statistics: &bigqueryrestapi.JobStatistics{
+			Query: &bigqueryrestapi.JobStatistics2{
+				StatementType: "SELECT",
+				Schema:        &bigqueryrestapi.TableSchema{Fields: fields},
+			},
+		},
+	}
+}
+
+func wideSchema(n int) []*bigqueryrestapi.TableFieldSchema {
+	fields := make([]*bigqueryrestapi.TableFieldSchema, n)
+	for i := range fields {
+		fields[i] = &bigqueryrestapi.TableFieldSchema{Name: fmt.Sprintf("col%d", i), Type: "INT64"}
+	}
+	return fields
+}
+
+func TestEstimateCellBytes(t *testing.T) {
+	fields := []*bigqueryrestapi.TableFieldSchema{
+		{Name: "id", Type: "INTEGER"},
+		{Name: "flag", Type: "BOOL"},
+		{Name: "name", Type: "STRING"},
+		{Name: "address", Type: "RECORD", Fields: []*bigqueryrestapi.TableFieldSchema{
+			{Name: "zip", Type: "NUMERIC"},
+			{Name: "city", Type: "STRING"},
+		}},
+	}
+	if got, want := bigquerycommon.EstimateCellBytes(fields), int64(8+1+64+16+64); got != want {
+		t.Fatalf("got %d bytes, want %d", got, want)
+	}
+}
+
+func TestCheckResultLimits(t *testing.T) {
+	tcs := []struct {
+		desc        string
+		job         *bigqueryrestapi.Job
+		limits      bigquerycommon.ResultLimits
+		wantErr     string
+		wantColumns []string
+	}{
+		{
+			desc:   "no limits",
+			job:    dryRunWithSchema(wideSchema(400)...),
+			limits: bigquerycommon.ResultLimits{},
+		},
+		{
+			desc:   "within limits",
+			job:    dryRunWithSchema(wideSchema(3)...),
+			limits: bigquerycommon.ResultLimits{MaxColumns: 3, MaxCellBytes: 24},
+		},
+		{
+			desc:        "too many columns",
+			job:         dryRunWithSchema(wideSchema(400)...),
+			limits:      bigquerycommon.ResultLimits{MaxColumns: 50},
+			wantErr:     "the result of the query would have 400 columns, more than the 50 allowed (400 columns: col0, col1, col2, col3, col4, col5, col6, col7, col8, col9, ...). Select only the specific columns you need",
+			wantColumns: []string{"col0", "col1", "col2", "col3", "col4", "col5", "col6", "col7", "col8", "col9"},
+		},
+		{
+			desc: "rows too large",
+			job: dryRunWithSchema(
+				&bigqueryrestapi.TableFieldSchema{Name: "id", Type: "INT64"},
+				&bigqueryrestapi.TableFieldSchema{Name: "payload", Type: "JSON"},
+			),
+			limits:      bigquerycommon.ResultLimits{MaxCellBytes: 32},
+			wantErr:     "the rows of the result of the query are estimated at 72 bytes, more than the 32 allowed (2 columns: id, payload)",
+			wantColumns: []string{"id", "payload"},
+		},
+		{
+			desc:   "no result schema",
+			job:    &bigqueryrestapi.Job{Statistics: &bigqueryrestapi.JobStatistics{Query: &bigqueryrestapi.JobStatistics2{StatementType: "INSERT"}}},
+			limits: bigquerycommon.ResultLimits{MaxColumns: 1, MaxCellBytes: 1},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := bigquerycommon.CheckResultLimits(tc.job, tc.limits)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+			info := err.ErrorInfo()
+			if info.Code != util.ErrorCodeInvalidArgument || info.Retryable {
+				t.Fatalf("unexpected error info: %+v", info)
+			}
+			if diff := cmp.Diff(tc.wantColumns, info.Details["columns"]); diff != "" {
+				t.Fatalf("unexpected columns of the error (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestToolResultLimits(t *testing.T) {
+	columns, bytes, zero := 10, int64(1000), 0
+	got, err := bigquerycommon.ToolResultLimits(&columns, &bytes)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := (bigquerycommon.ResultLimits{MaxColumns: 10, MaxCellBytes: 1000}); got != want {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	if _, err := bigquerycommon.ToolResultLimits(&zero, nil); err == nil {
+		t.Fatalf("expected a non-positive maxColumns to be rejected")
+	}
+}
@@ -0,0 +1,265 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	bigqueryds "github.com/googleapis/genai-toolbox/internal/sources/bigquery"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	bigqueryrestapi "google.golang.org/api/bigquery/v2"
+)
+
+// scriptChildJobsPage is the number of child jobs of a failed script that are
+// looked up. Child jobs are listed from the most recent, so the failed one is
+// among the first.
+const scriptChildJobsPage = 50
+
+// scriptBlockVerbs start the statements of scripts that contain other
+// statements, up to their END, e.g. IF ... END IF.
+var scriptBlockVerbs = map[string]bool{
+	"begin":  true,
+	"if":     true,
+	"loop":   true,
+	"while":  true,
+	"for":    true,
+	"repeat": true,
+	"case":   true,
+}
+
+// StatementSpan is a statement of a script, see SplitStatements.
+type StatementSpan struct {
+	// Start and End are the byte offsets of the statement in the script,
+	// without its semicolon.
+	Start, End int
+}
+
+// SplitStatements returns the top-level statements of sql, a query or a
+// script, in order. The blocks of scripts, e.g. IF ... END IF or BEGIN ...
+// END, are single statements, including the statements in them.
+func SplitStatements(sql string) []StatementSpan {
+	var spans []StatementSpan
+	tokens := sqlTokens(sql)
+	// verb is the first word of the current statement, at any depth of
+	// blocks, or "" at the start of a statement
+	verb, start := "", -1
+	blocks, parens, cases := 0, 0, 0
+	closeBlock := func() {
+		blocks--
+		verb = "end"
+	}
+	for i, tok := range tokens {
+		if start < 0 && blocks == 0 && (tok.word || tok.text == "(") {
+			start = tok.start
+		}
+		switch {
+		case tok.text == "(":
+			parens++
+		case tok.text == ")":
+			parens--
+		case tok.text == ";" && parens <= 0:
+			verb, parens, cases = "", 0, 0
+			if blocks == 0 && start >= 0 {
+				spans = append(spans, StatementSpan{Start: start, End: tok.start})
+				start = -1
+			}
+		case !tok.word:
+		case verb == "":
+			switch {
+			case tok.text == "begin" && (i+1 == len(tokens) || tokens[i+1].text == ";" || tokens[i+1].text == "transaction"):
+				// BEGIN TRANSACTION is a statement
+				verb = tok.text
+			case tok.text == "end" && blocks > 0:
+				closeBlock()
+			case tok.text == "else" || tok.text == "begin" || tok.text == "loop" || tok.text == "repeat":
+				// the next statement of the block follows
+				if tok.text != "else" {
+					blocks++
+				}
+			default:
+				verb = tok.text
+				if scriptBlockVerbs[tok.text] {
+					blocks++
+				}
+			}
+		case tok.text == "begin" && verb == "create" && parens == 0:
+			// the body of CREATE PROCEDURE follows
+			blocks++
+			verb = ""
+		case tok.text == "case":
+			cases++
+		case tok.text == "end" && cases > 0:
+			cases--
+		case tok.text == "end" && verb == "until" && parens == 0:
+			// REPEAT ... UNTIL condition END REPEAT
+			closeBlock()
+		case parens == 0 && cases == 0 && (tok.text == "then" || tok.text == "do") && scriptConditionVerbs[verb]:
+			// the body of the condition follows
+			verb = ""
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, StatementSpan{Start: start, End: len(strings.TrimRight(sql, " \t\r\n;"))})
+	}
+	return spans
+}
+
+// FailedStatement is the statement of a script whose job failed.
+type FailedStatement struct {
+	// Index is the position of the statement among the top-level statements
+	// of the script, see SplitStatements, starting at 1.
+	Index int
+	// Start and End are the byte offsets of the statement in the script.
+	Start, End int
+	// Line and Column are the position of the error in the script, if known.
+	Line, Column int
+	// JobID is the ID of the child job of the statement, if known.
+	JobID string
+}
+
+// FailedScriptStatement returns the top-level statement of the script sql
+// whose job failed with err. The statement is found with the stack frame of
+// the child job of the script that failed, listed with restService if err
+// wraps a *bigqueryds.JobError, or else with the position of the error in its
+// message, e.g. "at [4:1]". It returns false for queries of a single
+// statement, and if the statement is not found.
+func FailedScriptStatement(ctx context.Context, restService *bigqueryrestapi.Service, err error, sql string) (FailedStatement, bool) {
+	statements := SplitStatements(sql)
+	if len(statements) < 2 {
+		return FailedStatement{}, false
+	}
+	var failed FailedStatement
+	var jobErr *bigqueryds.JobError
+	if restService != nil && errors.As(err, &jobErr) {
+		line, column, jobID, lookupErr := failedChildJob(ctx, restService, jobErr)
+		if lookupErr != nil {
+			if logger, logErr := util.LoggerFromContext(ctx); logErr == nil {
+				logger.DebugContext(ctx, fmt.Sprintf("unable to look up the child jobs of script job %q: %s", jobErr.JobID, lookupErr))
+			}
+		}
+		failed.Line, failed.Column, failed.JobID = line, column, jobID
+	}
+	if failed.Line == 0 {
+		matches := errorPositionRegex.FindAllStringSubmatch(err.Error(), -1)
+		if len(matches) == 0 {
+			return FailedStatement{}, false
+		}
+		position := matches[len(matches)-1]
+		failed.Line, _ = strconv.Atoi(position[1])
+		failed.Column, _ = strconv.Atoi(position[2])
+	}
+	offset, ok := positionOffset(sql, failed.Line, failed.Column)
+	if !ok {
+		return FailedStatement{}, false
+	}
+	for i, s := range statements {
+		// a position between two statements is reported with the next one
+		if offset < s.End || i == len(statements)-1 {
+			failed.Index, failed.Start, failed.End = i+1, s.Start, s.End
+			return failed, true
+		}
+	}
+	return FailedStatement{}, false
+}
+
+// failedChildJob returns the position in the script of the child job of the
+// script job of jobErr that failed, from the last stack frame of the job,
+// which is in the script, and the ID of the child job. It returns a zero line
+// if no child job failed.
+func failedChildJob(ctx context.Context, restService *bigqueryrestapi.Service, jobErr *bigqueryds.JobError) (line, column int, jobID string, err error) {
+	resp, err := restService.Jobs.List(jobErr.ProjectID).ParentJobId(jobErr.JobID).MaxResults(scriptChildJobsPage).Context(ctx).Do()
+	if err != nil {
+		return 0, 0, "", err
+	}
+	for _, job := range resp.Jobs {
+		if job.ErrorResult == nil || job.Statistics == nil || job.Statistics.ScriptStatistics == nil {
+			continue
+		}
+		frames := job.Statistics.ScriptStatistics.StackFrames
+		if len(frames) == 0 {
+			continue
+		}
+		frame := frames[len(frames)-1]
+		var id string
+		if job.JobReference != nil {
+			id = job.JobReference.JobId
+		}
+		return int(frame.StartLine), int(frame.StartColumn), id, nil
+	}
+	return 0, 0, "", nil
+}
+
+// positionOffset returns the byte offset in sql of the position line:column,
+// both starting at 1, with columns counted like SQLExcerpt. A column past the
+// end of its line is the end of the line.
+func positionOffset(sql string, line, column int) (int, bool) {
+	if line < 1 || column < 1 {
+		return 0, false
+	}
+	offset := 0
+	for n := 1; n < line; n++ {
+		i := strings.IndexByte(sql[offset:], '\n')
+		if i < 0 {
+			return 0, false
+		}
+		offset += i + 1
+	}
+	width := 0
+	for i, r := range sql[offset:] {
+		if r == '\n' || width >= column-1 {
+			return offset + i, true
+		}
+		if r == '\t' {
+			width += excerptTabWidth - width%excerptTabWidth
+		} else {
+			width++
+		}
+	}
+	return len(sql), true
+}
+
+// ProcessScriptError is ProcessQueryError for err, the error of the job of
+// sql. For scripts, the FailedScriptStatement is added to the details of the
+// error, as "statementIndex", "statement", its summary with the contents of
+// string literals masked if source redacts SQL, and "childJobId" if known.
+// If the message of err has no position, the excerpt of the script is taken
+// at the position of the child job.
+func ProcessScriptError(ctx context.Context, msg string, source SQLRedactionSource, restService *bigqueryrestapi.Service, err error, sql string) util.ToolboxError {
+	excerptSQL := sql
+	if source.BigQueryRedactSQL() {
+		excerptSQL = maskSQLLiterals(sql)
+	}
+	details := make(map[string]any)
+	if excerpt := ErrorExcerpt(err.Error(), excerptSQL); excerpt != "" {
+		details["sqlExcerpt"] = excerpt
+	}
+	if failed, ok := FailedScriptStatement(ctx, restService, err, sql); ok {
+		details["statementIndex"] = failed.Index
+		details["statement"] = summarizeStatement(excerptSQL[failed.Start:failed.End])
+		if failed.JobID != "" {
+			details["childJobId"] = failed.JobID
+		}
+		if _, ok := details["sqlExcerpt"]; !ok {
+			if excerpt := SQLExcerpt(excerptSQL, failed.Line, failed.Column, ExcerptOptions{}); excerpt != "" {
+				details["sqlExcerpt"] = excerpt
+			}
+		}
+	}
+	return processAPIError(msg, RedactSQLError(source, err, sql), details)
+}
@@ -0,0 +1,194 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	bigqueryds "github.com/googleapis/genai-toolbox/internal/sources/bigquery"
+	"github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+	bigqueryrestapi "google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/option"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tcs := []struct {
+		desc string
+		sql  string
+		want []string
+	}{
+		{desc: "single statement", sql: "SELECT 1", want: []string{"SELECT 1"}},
+		{desc: "trailing semicolon", sql: "SELECT 1;\n", want: []string{"SELECT 1"}},
+		{
+			desc: "statements",
+			sql:  "DECLARE x INT64 DEFAULT (SELECT 1);\nSET x = x + 1;\n-- comment\nSELECT ';' AS s, x",
+			want: []string{"DECLARE x INT64 DEFAULT (SELECT 1)", "SET x = x + 1", "SELECT ';' AS s, x"},
+		},
+		{
+			desc: "blocks",
+			sql:  "IF x > 0 THEN SELECT 1; ELSE SELECT 2; END IF;\nBEGIN SELECT 3; BEGIN SELECT 4; END; END;\nSELECT 5",
+			want: []string{"IF x > 0 THEN SELECT 1; ELSE SELECT 2; END IF", "BEGIN SELECT 3; BEGIN SELECT 4; END; END", "SELECT 5"},
+		},
+		{
+			desc: "loops",
+			sql:  "WHILE x < 3 DO SET x = x + 1; END WHILE; REPEAT SET x = x - 1; UNTIL x <= 0 END REPEAT; LOOP BREAK; END LOOP; SELECT x",
+			want: []string{"WHILE x < 3 DO SET x = x + 1; END WHILE", "REPEAT SET x = x - 1; UNTIL x <= 0 END REPEAT", "LOOP BREAK; END LOOP", "SELECT x"},
+		},
+		{
+			desc: "case expressions",
+			sql:  "IF CASE WHEN x THEN 1 ELSE 0 END = 1 THEN SELECT CASE x WHEN 1 THEN 'a' END; END IF; SELECT 2",
+			want: []string{"IF CASE WHEN x THEN 1 ELSE 0 END = 1 THEN SELECT CASE x WHEN 1 THEN 'a' END; END IF", "SELECT 2"},
+		},
+		{
+			desc: "transactions and exceptions",
+			sql:  "BEGIN TRANSACTION; DELETE FROM d.t WHERE x; COMMIT TRANSACTION; BEGIN SELECT 1/0; EXCEPTION WHEN ERROR THEN SELECT 2; END",
+			want: []string{"BEGIN TRANSACTION", "DELETE FROM d.t WHERE x", "COMMIT TRANSACTION", "BEGIN SELECT 1/0; EXCEPTION WHEN ERROR THEN SELECT 2; END"},
+		},
+		{
+			desc: "procedure",
+			sql:  "CREATE TEMP PROCEDURE p() BEGIN SELECT 1; SELECT 2; END; CALL p()",
+			want: []string{"CREATE TEMP PROCEDURE p() BEGIN SELECT 1; SELECT 2; END", "CALL p()"},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			var got []string
+			for _, s := range bigquerycommon.SplitStatements(tc.sql) {
+				got = append(got, tc.sql[s.Start:s.End])
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected statements (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// script has four statements, the third on two lines.
+const script = "DECLARE n INT64;\nSET n = 0;\nINSERT INTO d.t\n  SELECT 1 / n;\nSELECT * FROM d.t"
+
+// childJobsServer answers the listing of the child jobs of the job "script"
+// with the JSON childJobs.
+func childJobsServer(t *testing.T, childJobs string) *bigqueryrestapi.Service {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/projects/p/jobs" || r.URL.Query().Get("parentJobId") != "script" {
+			http.Error(w, `{"error": {"code": 404, "message": "not found"}}`, http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, childJobs)
+	}))
+	t.Cleanup(server.Close)
+	restService, err := bigqueryrestapi.NewService(context.Background(), option.WithEndpoint(server.URL), option.WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatalf("unable to create REST service: %s", err)
+	}
+	return restService
+}
+
+func TestFailedScriptStatement(t *testing.T) {
+	childJobs := `{"jobs": [
+		{"jobReference": {"jobId": "script_job_2"}, "errorResult": {"reason": "invalidQuery", "message": "division by zero: 1 / 0"},
+		 "statistics": {"scriptStatistics": {"stackFrames": [{"startLine": 3, "startColumn": 1, "endLine": 4, "endColumn": 15}]}}},
+		{"jobReference": {"jobId": "script_job_1"}, "statistics": {"scriptStatistics": {"stackFrames": [{"startLine": 2, "startColumn": 1}]}}}
+	]}`
+	jobErr := func(msg string) error {
+		return fmt.Errorf("unable to read query results: %w", &bigqueryds.JobError{ProjectID: "p", JobID: "script", Err: errors.New(msg)})
+	}
+	ctx := context.Background()
+
+	tcs := []struct {
+		desc        string
+		restService *bigqueryrestapi.Service
+		err         error
+		sql         string
+		want        bigquerycommon.FailedStatement
+		wantOK      bool
+	}{
+		{
+			desc:        "child job",
+			restService: childJobsServer(t, childJobs),
+			err:         jobErr("division by zero: 1 / 0"),
+			sql:         script,
+			want:        bigquerycommon.FailedStatement{Index: 3, Start: 28, End: 58, Line: 3, Column: 1, JobID: "script_job_2"},
+			wantOK:      true,
+		},
+		{
+			desc:        "frame inside a block",
+			restService: childJobsServer(t, `{"jobs": [{"errorResult": {"reason": "invalidQuery"}, "statistics": {"scriptStatistics": {"stackFrames": [{"startLine": 1, "startColumn": 36}]}}}]}`),
+			err:         jobErr("failed"),
+			sql:         "SELECT 1; IF TRUE THEN SELECT 1; SELECT 1 / 0; END IF",
+			want:        bigquerycommon.FailedStatement{Index: 2, Start: 10, End: 53, Line: 1, Column: 36},
+			wantOK:      true,
+		},
+		{
+			desc:        "position of the message without child jobs",
+			restService: childJobsServer(t, `{"jobs": []}`),
+			err:         jobErr("Query error: division by zero: 1 / 0 at [4:3]"),
+			sql:         script,
+			want:        bigquerycommon.FailedStatement{Index: 3, Start: 28, End: 58, Line: 4, Column: 3},
+			wantOK:      true,
+		},
+		{
+			desc:        "child jobs cannot be listed",
+			restService: childJobsServer(t, `{"jobs": []}`),
+			err:         fmt.Errorf("failed: %w", &bigqueryds.JobError{ProjectID: "p", JobID: "other", Err: errors.New("Syntax error at [5:1]")}),
+			sql:         script,
+			want:        bigquerycommon.FailedStatement{Index: 4, Start: 60, End: 77, Line: 5, Column: 1},
+			wantOK:      true,
+		},
+		{desc: "no position", err: errors.New("connection reset"), sql: script},
+		{desc: "single statement", err: errors.New("Syntax error at [1:8]"), sql: "SELECT 1 / 0"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, ok := bigquerycommon.FailedScriptStatement(ctx, tc.restService, tc.err, tc.sql)
+			if ok != tc.wantOK {
+				t.Fatalf("got found %t, want %t", ok, tc.wantOK)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected statement (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestProcessScriptError(t *testing.T) {
+	sql := "SET n = 0;\nSELECT * FROM d.t WHERE name = 'alice' AND x = 1 / n"
+	childJobs := `{"jobs": [{"jobReference": {"jobId": "script_job_1"}, "errorResult": {"reason": "invalidQuery"},
+		"statistics": {"scriptStatistics": {"stackFrames": [{"startLine": 2, "startColumn": 1}]}}}]}`
+	err := &bigqueryds.JobError{ProjectID: "p", JobID: "script", Err: apiError(http.StatusBadRequest, "invalidQuery", "q")}
+
+	got := bigquerycommon.ProcessScriptError(context.Background(), "error running sql", fakeRedactionSource{redact: true}, childJobsServer(t, childJobs), err, sql)
+	want := map[string]any{
+		"reason":         "invalidQuery",
+		"location":       "q",
+		"statementIndex": 2,
+		"statement":      "SELECT * FROM d.t WHERE name = '*****' AND x = 1 / n",
+		"childJobId":     "script_job_1",
+		"sqlExcerpt": "" +
+			"  1 | SET n = 0;\n" +
+			"> 2 | SELECT * FROM d.t WHERE name = '*****' AND x = 1 / n\n" +
+			"      ^",
+	}
+	if diff := cmp.Diff(want, got.ErrorInfo().Details); diff != "" {
+		t.Errorf("unexpected details (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Defaults of ExcerptOptions.
+const (
+	DefaultExcerptWidth = 80
+	DefaultExcerptLines = 5
+)
+
+// excerptTabWidth is the distance between the tab stops of the columns of
+// positions, which BigQuery reports with tabs expanded to multiples of 8.
+const excerptTabWidth = 8
+
+// excerptEllipsis marks the parts of the lines of excerpts that are cut.
+const excerptEllipsis = "..."
+
+// errorPositionRegex matches the positions of the errors of BigQuery, e.g.
+// "Syntax error: Unexpected end of script at [3:15]".
+var errorPositionRegex = regexp.MustCompile(`\[(\d+):(\d+)\]`)
+
+// ExcerptOptions configures SQLExcerpt.
+type ExcerptOptions struct {
+	// MaxWidth is the maximum number of characters shown of a line. The
+	// longer lines are shown in a window around the column. Defaults to
+	// DefaultExcerptWidth.
+	MaxWidth int
+	// MaxLines is the maximum number of lines shown, around the line of the
+	// position. Defaults to DefaultExcerptLines.
+	MaxLines int
+}
+
+// SQLExcerpt renders the lines of sql around the position line:column, both
+// starting at 1, with their line numbers, the line of the position marked with
+// ">", and a caret under the column, e.g.
+//
+//	  1 | SELECT *
+//	> 2 | FROM d.t WHERE
+//	                    ^
+//
+// The column counts characters, not bytes, with tabs advancing to the next
+// multiple of 8, as in the positions of the errors of BigQuery; tabs are
+// expanded to spaces so that the caret lines up. It returns "" if line is not
+// a line of sql.
+func SQLExcerpt(sql string, line, column int, opts ExcerptOptions) string {
+	maxWidth, maxLines := opts.MaxWidth, opts.MaxLines
+	if maxWidth <= 0 {
+		maxWidth = DefaultExcerptWidth
+	}
+	if maxLines <= 0 {
+		maxLines = DefaultExcerptLines
+	}
+	lines := strings.Split(sql, "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	first := max(1, line-(maxLines-1)/2)
+	last := min(len(lines), first+maxLines-1)
+	first = max(1, min(first, last-maxLines+1))
+	numberWidth := len(strconv.Itoa(last))
+
+	// the lines are shown from the same character, so that the window of
+	// the line of the position is centered on the column
+	target := []rune(expandTabs(strings.TrimSuffix(lines[line-1], "\r")))
+	col := max(0, min(column-1, len(target)))
+	start := 0
+	if len(target) > maxWidth {
+		start = max(0, min(col-maxWidth/2, len(target)-maxWidth))
+	}
+
+	var b strings.Builder
+	for n := first; n <= last; n++ {
+		text := []rune(expandTabs(strings.TrimSuffix(lines[n-1], "\r")))
+		prefix, suffix := "", ""
+		var window []rune
+		if start < len(text) {
+			window = text[start:]
+			if start > 0 {
+				prefix = excerptEllipsis
+			}
+		}
+		if len(window) > maxWidth {
+			window, suffix = window[:maxWidth], excerptEllipsis
+		}
+		marker := " "
+		if n == line {
+			marker = ">"
+		}
+		lineStart := fmt.Sprintf("%s %*d | ", marker, numberWidth, n)
+		b.WriteString(strings.TrimRightFunc(lineStart+prefix+string(window), unicode.IsSpace) + suffix + "\n")
+		if n == line {
+			caret := len(lineStart) + col - start
+			if start > 0 {
+				caret += len(excerptEllipsis)
+			}
+			fmt.Fprintf(&b, "%s^\n", strings.Repeat(" ", caret))
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// expandTabs returns line with its tabs replaced by spaces up to the next tab
+// stop.
+func expandTabs(line string) string {
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		if r == '\t' {
+			spaces := excerptTabWidth - width%excerptTabWidth
+			b.WriteString(strings.Repeat(" ", spaces))
+			width += spaces
+			continue
+		}
+		b.WriteRune(r)
+		width++
+	}
+	return b.String()
+}
+
+// ErrorExcerpt returns the SQLExcerpt of sql at the last position of the
+// error message msg, e.g. "at [3:15]", with the default options, or "" if msg
+// has no position in sql.
+func ErrorExcerpt(msg, sql string) string {
+	matches := errorPositionRegex.FindAllStringSubmatch(msg, -1)
+	if len(matches) == 0 {
+		return ""
+	}
+	position := matches[len(matches)-1]
+	line, err := strconv.Atoi(position[1])
+	if err != nil {
+		return ""
+	}
+	column, err := strconv.Atoi(position[2])
+	if err != nil {
+		return ""
+	}
+	return SQLExcerpt(sql, line, column, ExcerptOptions{})
+}
+
+// Truncate returns sql cut to at most maxLen bytes followed by "...", or sql
+// if it is not longer. The cut is made before the word or quoted identifier
+// it would split, unless that is the first one, and never inside a UTF-8
+// character.
+func Truncate(sql string, maxLen int) string {
+	if len(sql) <= maxLen {
+		return sql
+	}
+	n := max(0, maxLen)
+	for _, tok := range sqlTokens(sql) {
+		if tok.start >= n {
+			break
+		}
+		if tok.word && n < tok.end && strings.TrimSpace(sql[:tok.start]) != "" {
+			n = tok.start
+			break
+		}
+	}
+	for n > 0 && !utf8.RuneStart(sql[n]) {
+		n--
+	}
+	return strings.TrimRightFunc(sql[:n], unicode.IsSpace) + excerptEllipsis
+}
+
+// maskSQLLiterals returns sql with the characters of the contents of its
+// string literals replaced by "*", except for tabs and line breaks, so that
+// the positions in sql are the same in the result.
+func maskSQLLiterals(sql string) string {
+	spans := stringLiterals(sql)
+	if len(spans) == 0 {
+		return sql
+	}
+	var b strings.Builder
+	last := 0
+	for _, s := range spans {
+		b.WriteString(sql[last:s.start])
+		for _, r := range sql[s.start:s.end] {
+			if r == '\t' || r == '\n' || r == '\r' {
+				b.WriteRune(r)
+			} else {
+				b.WriteByte('*')
+			}
+		}
+		last = s.end
+	}
+	b.WriteString(sql[last:])
+	return b.String()
+}
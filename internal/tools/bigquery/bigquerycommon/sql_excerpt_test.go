@@ -0,0 +1,241 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+)
+
+func TestSQLExcerpt(t *testing.T) {
+	tcs := []struct {
+		desc         string
+		sql          string
+		line, column int
+		opts         bigquerycommon.ExcerptOptions
+		want         string
+	}{
+		{
+			desc:   "single line",
+			sql:    "SELECT * FROM d.t WHERE",
+			line:   1,
+			column: 24,
+			want: "" +
+				"> 1 | SELECT * FROM d.t WHERE\n" +
+				"                             ^",
+		},
+		{
+			desc:   "lines around the position",
+			sql:    "SELECT a,\n  b,\n  c\nFROM d.t\nWHER x = 1\nLIMIT 1\nOFFSET 2",
+			line:   5,
+			column: 1,
+			opts:   bigquerycommon.ExcerptOptions{MaxLines: 3},
+			want: "" +
+				"  4 | FROM d.t\n" +
+				"> 5 | WHER x = 1\n" +
+				"      ^\n" +
+				"  6 | LIMIT 1",
+		},
+		{
+			desc:   "first line",
+			sql:    "SELEC 1\nFROM d.t\nWHERE x\nLIMIT 1",
+			line:   1,
+			column: 1,
+			opts:   bigquerycommon.ExcerptOptions{MaxLines: 3},
+			want: "" +
+				"> 1 | SELEC 1\n" +
+				"      ^\n" +
+				"  2 | FROM d.t\n" +
+				"  3 | WHERE x",
+		},
+		{
+			desc:   "line numbers of different widths",
+			sql:    strings.Repeat("SELECT 1;\n", 9) + "SELEC 1",
+			line:   10,
+			column: 1,
+			opts:   bigquerycommon.ExcerptOptions{MaxLines: 2},
+			want: "" +
+				"   9 | SELECT 1;\n" +
+				"> 10 | SELEC 1\n" +
+				"       ^",
+		},
+		{
+			desc:   "multi-byte characters",
+			sql:    "SELECT 'héllo wörld' AS 名前, x FRM d.t",
+			line:   1,
+			column: 32,
+			want: "" +
+				"> 1 | SELECT 'héllo wörld' AS 名前, x FRM d.t\n" +
+				"                                     ^",
+		},
+		{
+			desc:   "tabs are expanded to the positions of BigQuery",
+			sql:    "SELECT\n\tx,\n\t\ty FRM d.t",
+			line:   3,
+			column: 19,
+			want: "" +
+				"  1 | SELECT\n" +
+				"  2 |         x,\n" +
+				"> 3 |                 y FRM d.t\n" +
+				"                        ^",
+		},
+		{
+			desc:   "tab after text",
+			sql:    "ab\tFRM",
+			line:   1,
+			column: 9,
+			want: "" +
+				"> 1 | ab      FRM\n" +
+				"              ^",
+		},
+		{
+			desc:   "long line windowed around the column",
+			sql:    "SELECT " + strings.Repeat("a, ", 20) + "FRM d.t WHERE " + strings.Repeat("b AND ", 20),
+			line:   1,
+			column: 68,
+			opts:   bigquerycommon.ExcerptOptions{MaxWidth: 20},
+			want: "" +
+				"> 1 | ... a, a, a, FRM d.t WH...\n" +
+				"                   ^",
+		},
+		{
+			desc:   "long line at its end",
+			sql:    "SELECT " + strings.Repeat("a, ", 20) + "x",
+			line:   1,
+			column: 69,
+			opts:   bigquerycommon.ExcerptOptions{MaxWidth: 10},
+			want: "" +
+				"> 1 | ...a, a, a, x\n" +
+				"                   ^",
+		},
+		{
+			desc:   "other lines share the window",
+			sql:    "SELECT 1\n" + strings.Repeat("x", 30) + "FRM",
+			line:   2,
+			column: 31,
+			opts:   bigquerycommon.ExcerptOptions{MaxWidth: 10},
+			want: "" +
+				"  1 |\n" +
+				"> 2 | ...xxxxxxxFRM\n" +
+				"                ^",
+		},
+		{
+			desc:   "column past the end of the line",
+			sql:    "SELECT\r\nFROM",
+			line:   1,
+			column: 40,
+			want: "" +
+				"> 1 | SELECT\n" +
+				"            ^\n" +
+				"  2 | FROM",
+		},
+		{desc: "line out of range", sql: "SELECT 1", line: 2, column: 1},
+		{desc: "line zero", sql: "SELECT 1", line: 0, column: 1},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := bigquerycommon.SQLExcerpt(tc.sql, tc.line, tc.column, tc.opts)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected excerpt (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestErrorExcerpt(t *testing.T) {
+	sql := "SELECT *\nFROM d.t\nWHERE"
+	got := bigquerycommon.ErrorExcerpt("Syntax error: Unexpected end of script at [3:6]", sql)
+	want := "" +
+		"  1 | SELECT *\n" +
+		"  2 | FROM d.t\n" +
+		"> 3 | WHERE\n" +
+		"           ^"
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected excerpt (-want +got):\n%s", diff)
+	}
+	if got := bigquerycommon.ErrorExcerpt("Not found: Table p:d.t", sql); got != "" {
+		t.Errorf("expected no excerpt without a position, got %q", got)
+	}
+	if got := bigquerycommon.ErrorExcerpt("error at [9:1]", sql); got != "" {
+		t.Errorf("expected no excerpt for a position outside of the query, got %q", got)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tcs := []struct {
+		desc   string
+		sql    string
+		maxLen int
+		want   string
+	}{
+		{desc: "short", sql: "SELECT 1", maxLen: 8, want: "SELECT 1"},
+		{desc: "at a boundary", sql: "SELECT a FROM d.t", maxLen: 9, want: "SELECT a..."},
+		{desc: "before an identifier", sql: "SELECT column_name FROM d.t", maxLen: 12, want: "SELECT..."},
+		{desc: "before a quoted identifier", sql: "SELECT * FROM `my-project.d.t`", maxLen: 20, want: "SELECT * FROM..."},
+		{desc: "after punctuation", sql: "SELECT a, b FROM d.t", maxLen: 10, want: "SELECT a,..."},
+		{desc: "first word too long", sql: strings.Repeat("x", 20), maxLen: 5, want: "xxxxx..."},
+		{desc: "multi-byte quoted identifier", sql: "SELECT `名前` FROM d.t", maxLen: 10, want: "SELECT..."},
+		{desc: "inside a string literal", sql: "SELECT 'héllo wörld'", maxLen: 11, want: "SELECT 'hé..."},
+		{desc: "tabs", sql: "SELECT\ta,\tb", maxLen: 8, want: "SELECT\ta..."},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := bigquerycommon.Truncate(tc.sql, tc.maxLen)
+			if got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("%q is not valid UTF-8", got)
+			}
+		})
+	}
+}
+
+func TestProcessQueryError(t *testing.T) {
+	sql := "SELECT * FROM t\nWHERE email = 'alice@example.com' AND"
+	apiErr := func() error {
+		err := apiError(http.StatusBadRequest, "invalidQuery", "q")
+		return errors.Join(err, errors.New("Syntax error: Unexpected end of script at [2:38]"))
+	}
+	wantExcerpt := func(literal string) string {
+		return "" +
+			"  1 | SELECT * FROM t\n" +
+			"> 2 | WHERE email = '" + literal + "' AND\n" +
+			"                                           ^"
+	}
+
+	got := bigquerycommon.ProcessQueryError("query validation failed", fakeRedactionSource{}, apiErr(), sql)
+	want := map[string]any{"reason": "invalidQuery", "location": "q", "sqlExcerpt": wantExcerpt("alice@example.com")}
+	if diff := cmp.Diff(want, got.ErrorInfo().Details); diff != "" {
+		t.Errorf("unexpected details (-want +got):\n%s", diff)
+	}
+
+	got = bigquerycommon.ProcessQueryError("query validation failed", fakeRedactionSource{redact: true}, apiErr(), sql)
+	want["sqlExcerpt"] = wantExcerpt(strings.Repeat("*", len("alice@example.com")))
+	if diff := cmp.Diff(want, got.ErrorInfo().Details); diff != "" {
+		t.Errorf("unexpected details of a redacting source (-want +got):\n%s", diff)
+	}
+
+	got = bigquerycommon.ProcessQueryError("query validation failed", fakeRedactionSource{}, errors.New("connection reset"), sql)
+	if got.ErrorInfo().Details != nil {
+		t.Errorf("expected no details without a position, got %v", got.ErrorInfo().Details)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon
+
+import "strings"
+
+// sqlToken is a token of a SQL statement: a word, i.e. a keyword or an
+// unquoted identifier, in lower case, a quoted identifier, or a single
+// punctuation character.
+type sqlToken struct {
+	text string
+	// word is set for words and quoted identifiers.
+	word bool
+	// start and end are the byte offsets of the token in the statement.
+	start, end int
+}
+
+// sqlTokens returns the tokens of sql, skipping whitespace, comments and
+// string literals.
+func sqlTokens(sql string) []sqlToken {
+	literals := stringLiterals(sql)
+	var tokens []sqlToken
+	for i := 0; i < len(sql); {
+		if len(literals) > 0 && i >= literals[0].start {
+			// skip the contents and the closing quote
+			i = literals[0].end + 1
+			literals = literals[1:]
+			continue
+		}
+		rest := sql[i:]
+		switch {
+		case strings.HasPrefix(rest, "--"), rest[0] == '#':
+			end := strings.IndexAny(rest, "\r\n")
+			if end < 0 {
+				return tokens
+			}
+			i += end + 1
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				return tokens
+			}
+			i += end + 4
+		case rest[0] == '`':
+			end := strings.IndexByte(rest[1:], '`')
+			if end < 0 {
+				return tokens
+			}
+			tokens = append(tokens, sqlToken{text: rest[:end+2], word: true, start: i, end: i + end + 2})
+			i += end + 2
+		case isIdentifierByte(rest[0]):
+			j := 1
+			for j < len(rest) && isIdentifierByte(rest[j]) {
+				j++
+			}
+			tokens = append(tokens, sqlToken{text: strings.ToLower(rest[:j]), word: true, start: i, end: i + j})
+			i += j
+		case strings.ContainsRune(" \t\r\n\f'\"", rune(rest[0])):
+			// quotes are skipped with the literals they delimit
+			i++
+		default:
+			tokens = append(tokens, sqlToken{text: rest[:1], start: i, end: i + 1})
+			i++
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,229 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	bigqueryapi "cloud.google.com/go/bigquery"
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+	"golang.org/x/oauth2"
+	bigqueryrestapi "google.golang.org/api/bigquery/v2"
+)
+
+// Checks of ValidateTools.
+const (
+	// CheckCredentials checks that the source obtains a token.
+	CheckCredentials = "credentials"
+	// CheckAllowedDataset checks that an allowed dataset of the source exists
+	// and is accessible.
+	CheckAllowedDataset = "allowedDataset"
+	// CheckDatasetLocation checks that an allowed dataset is in the location
+	// of the source.
+	CheckDatasetLocation = "datasetLocation"
+	// CheckDryRun checks the static statement of a tool with a dry run.
+	CheckDryRun = "dryRun"
+)
+
+// Statuses of the checks of ValidateTools.
+const (
+	CheckPassed  = "pass"
+	CheckFailed  = "fail"
+	CheckSkipped = "skip"
+)
+
+// StartupCheck is the result of a check of ValidateTools.
+type StartupCheck struct {
+	Check  string `json:"check"`
+	Source string `json:"source"`
+	// Tool is the name of the tool of a CheckDryRun.
+	Tool string `json:"tool,omitempty"`
+	// Target is the dataset of a CheckAllowedDataset or CheckDatasetLocation,
+	// "project.dataset".
+	Target  string `json:"target,omitempty"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// StartupReport is the result of ValidateTools. It lists every check, so that
+// all the failures of a configuration are reported at once.
+type StartupReport struct {
+	// OK is whether no check failed.
+	OK     bool           `json:"ok"`
+	Checks []StartupCheck `json:"checks"`
+}
+
+// StartupSource is implemented by the BigQuery sources that ValidateTools
+// checks.
+type StartupSource interface {
+	JobOptionsSource
+	DatasetAllowlistSource
+	BigQueryLocation() string
+	UseClientAuthorization() bool
+	BigQueryTokenSourceWithScope(ctx context.Context, scopes []string) (oauth2.TokenSource, error)
+	DatasetLocation(ctx context.Context, accessToken tools.AccessToken, projectID, datasetID string) (string, error)
+	RetrieveClientAndService(ctx context.Context, accessToken tools.AccessToken) (*bigqueryapi.Client, *bigqueryrestapi.Service, error)
+}
+
+// StaticQuery is the statement of a tool that does not depend on the
+// parameters of its invocations, other than its query parameters.
+type StaticQuery struct {
+	Statement     string
+	Parameters    parameters.Parameters
+	ParameterMode ParameterMode
+	JobOptions    JobOptions
+}
+
+// StaticQueryTool is implemented by tools that run a statement of their
+// configuration, which ValidateTools checks with a dry run.
+type StaticQueryTool interface {
+	// StaticQuery returns the statement of the tool, or false if it is
+	// resolved on invocation, e.g. from template parameters.
+	StaticQuery() (StaticQuery, bool)
+}
+
+// ValidateTools checks the BigQuery sources of toolsMap and the static
+// statements of their tools, without running any query: that the sources
+// obtain credentials, that their allowed datasets exist, are accessible and
+// are in the location of the source, and that the statements pass a dry run
+// with NULL query parameters. Sources that use client authorization are
+// skipped, as they have no credentials before an invocation.
+func ValidateTools(ctx context.Context, srcs map[string]sources.Source, toolsMap map[string]tools.Tool) StartupReport {
+	toolsBySource := make(map[string][]string)
+	for name, tool := range toolsMap {
+		sourceName, ok := tools.SourceName(tool.ToConfig())
+		if !ok {
+			continue
+		}
+		if _, ok := srcs[sourceName].(StartupSource); ok {
+			toolsBySource[sourceName] = append(toolsBySource[sourceName], name)
+		}
+	}
+
+	report := StartupReport{OK: true, Checks: []StartupCheck{}}
+	add := func(c StartupCheck) {
+		if c.Status == CheckFailed {
+			report.OK = false
+		}
+		report.Checks = append(report.Checks, c)
+	}
+	for _, sourceName := range slices.Sorted(maps.Keys(toolsBySource)) {
+		source := srcs[sourceName].(StartupSource)
+		toolNames := toolsBySource[sourceName]
+		slices.Sort(toolNames)
+		validateSource(ctx, sourceName, source, toolNames, toolsMap, add)
+	}
+	return report
+}
+
+// validateSource runs the checks of the source named sourceName and of its
+// tools toolNames, and adds their results with add.
+func validateSource(ctx context.Context, sourceName string, source StartupSource, toolNames []string, toolsMap map[string]tools.Tool, add func(StartupCheck)) {
+	if source.UseClientAuthorization() {
+		add(StartupCheck{Check: CheckCredentials, Source: sourceName, Status: CheckSkipped, Message: "the source uses client authorization"})
+		return
+	}
+	if err := checkCredentials(ctx, source); err != nil {
+		add(StartupCheck{Check: CheckCredentials, Source: sourceName, Status: CheckFailed, Message: err.Error()})
+		return
+	}
+	add(StartupCheck{Check: CheckCredentials, Source: sourceName, Status: CheckPassed})
+
+	location := source.BigQueryLocation()
+	datasets := slices.Clone(source.BigQueryAllowedDatasets())
+	slices.Sort(datasets)
+	for _, dataset := range datasets {
+		projectID, datasetID, _ := strings.Cut(dataset, ".")
+		datasetLocation, err := source.DatasetLocation(ctx, "", projectID, datasetID)
+		if err != nil {
+			add(StartupCheck{Check: CheckAllowedDataset, Source: sourceName, Target: dataset, Status: CheckFailed, Message: err.Error()})
+			continue
+		}
+		add(StartupCheck{Check: CheckAllowedDataset, Source: sourceName, Target: dataset, Status: CheckPassed})
+		if location == "" {
+			continue
+		}
+		if !strings.EqualFold(datasetLocation, location) {
+			add(StartupCheck{Check: CheckDatasetLocation, Source: sourceName, Target: dataset, Status: CheckFailed,
+				Message: fmt.Sprintf("the dataset is in %q, but the source runs its jobs in %q", datasetLocation, location)})
+			continue
+		}
+		add(StartupCheck{Check: CheckDatasetLocation, Source: sourceName, Target: dataset, Status: CheckPassed})
+	}
+
+	for _, toolName := range toolNames {
+		tool, ok := tools.BaseTool(toolsMap[toolName]).(StaticQueryTool)
+		if !ok {
+			continue
+		}
+		q, ok := tool.StaticQuery()
+		if !ok {
+			add(StartupCheck{Check: CheckDryRun, Source: sourceName, Tool: toolName, Status: CheckSkipped, Message: "the statement of the tool is resolved on invocation"})
+			continue
+		}
+		if err := dryRunStaticQuery(ctx, source, q); err != nil {
+			add(StartupCheck{Check: CheckDryRun, Source: sourceName, Tool: toolName, Status: CheckFailed, Message: err.Error()})
+			continue
+		}
+		add(StartupCheck{Check: CheckDryRun, Source: sourceName, Tool: toolName, Status: CheckPassed})
+	}
+}
+
+// checkCredentials returns an error if source cannot obtain a token.
+func checkCredentials(ctx context.Context, source StartupSource) error {
+	ts, err := source.BigQueryTokenSourceWithScope(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create the token source: %w", err)
+	}
+	if _, err := ts.Token(); err != nil {
+		return fmt.Errorf("failed to obtain a token: %w", err)
+	}
+	return nil
+}
+
+// dryRunStaticQuery checks q with a dry run on source, with NULL values for
+// its scalar query parameters and empty arrays for its array parameters.
+func dryRunStaticQuery(ctx context.Context, source StartupSource, q StaticQuery) error {
+	mode, err := ResolveParameterMode(q.Statement, q.ParameterMode)
+	if err != nil {
+		return err
+	}
+	paramsMap := make(map[string]any, len(q.Parameters))
+	for _, p := range q.Parameters {
+		if _, ok := p.(*parameters.ArrayParameter); ok {
+			paramsMap[p.GetName()] = []any{}
+		} else {
+			// an empty value is omitted from the request, which is NULL
+			paramsMap[p.GetName()] = ""
+		}
+	}
+	_, params, err := BuildQueryParameters(q.Statement, mode, q.Parameters, paramsMap)
+	if err != nil {
+		return err
+	}
+	client, restService, err := source.RetrieveClientAndService(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to retrieve BigQuery client: %w", err)
+	}
+	jobOpts := ResolveJobOptions(source, q.JobOptions, JobOptions{})
+	_, err = DryRunQuery(ctx, restService, client.Project(), client.Location, q.Statement, params, mode, nil, jobOpts)
+	return err
+}
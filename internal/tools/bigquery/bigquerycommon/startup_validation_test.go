@@ -0,0 +1,218 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	bigqueryapi "cloud.google.com/go/bigquery"
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	"github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+	"golang.org/x/oauth2"
+	bigqueryrestapi "google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/option"
+)
+
+type startupSource struct {
+	sources.Source
+	location        string
+	allowedDatasets []string
+	clientAuth      bool
+	tokenErr        error
+	client          *bigqueryapi.Client
+	restService     *bigqueryrestapi.Service
+}
+
+func (s startupSource) BigQueryJobOptions(tool bigquerycommon.JobOptions) bigquerycommon.JobOptions {
+	return tool
+}
+
+func (s startupSource) IsDatasetAllowed(projectID, datasetID string) bool { return true }
+
+func (s startupSource) BigQueryAllowedDatasets() []string { return s.allowedDatasets }
+
+func (s startupSource) BigQueryLocation() string { return s.location }
+
+func (s startupSource) UseClientAuthorization() bool { return s.clientAuth }
+
+func (s startupSource) BigQueryTokenSourceWithScope(ctx context.Context, scopes []string) (oauth2.TokenSource, error) {
+	if s.tokenErr != nil {
+		return errTokenSource{s.tokenErr}, nil
+	}
+	return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"}), nil
+}
+
+func (s startupSource) DatasetLocation(ctx context.Context, accessToken tools.AccessToken, projectID, datasetID string) (string, error) {
+	dataset, err := s.restService.Datasets.Get(projectID, datasetID).Context(ctx).Do()
+	if err != nil {
+		return "", err
+	}
+	return dataset.Location, nil
+}
+
+func (s startupSource) RetrieveClientAndService(ctx context.Context, accessToken tools.AccessToken) (*bigqueryapi.Client, *bigqueryrestapi.Service, error) {
+	return s.client, s.restService, nil
+}
+
+type errTokenSource struct{ err error }
+
+func (ts errTokenSource) Token() (*oauth2.Token, error) { return nil, ts.err }
+
+type startupToolConfig struct {
+	Source string
+}
+
+func (cfg startupToolConfig) ToolConfigType() string { return "fake" }
+
+func (cfg startupToolConfig) Initialize(map[string]sources.Source) (tools.Tool, error) {
+	return nil, nil
+}
+
+type startupTool struct {
+	tools.Tool
+	source string
+	query  *bigquerycommon.StaticQuery
+}
+
+func (t startupTool) ToConfig() tools.ToolConfig { return startupToolConfig{Source: t.source} }
+
+func (t startupTool) StaticQuery() (bigquerycommon.StaticQuery, bool) {
+	if t.query == nil {
+		return bigquerycommon.StaticQuery{}, false
+	}
+	return *t.query, true
+}
+
+func TestValidateTools(t *testing.T) {
+	var dryRuns []*bigqueryrestapi.Job
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/datasets/sales"):
+			fmt.Fprint(w, `{"location": "US"}`)
+		case strings.HasSuffix(r.URL.Path, "/datasets/eu_sales"):
+			fmt.Fprint(w, `{"location": "europe-west1"}`)
+		case strings.HasSuffix(r.URL.Path, "/jobs"):
+			var job bigqueryrestapi.Job
+			if err := json.NewDecoder(r.Body).Decode(&job); err != nil {
+				t.Errorf("unable to decode request: %s", err)
+			}
+			dryRuns = append(dryRuns, &job)
+			if strings.Contains(job.Configuration.Query.Query, "missing") {
+				http.Error(w, `{"error": {"code": 404, "message": "Not found: Table my-project:sales.missing"}}`, http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(job)
+		default:
+			http.Error(w, `{"error": {"code": 404, "message": "Not found: Dataset"}}`, http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+	restService, err := bigqueryrestapi.NewService(ctx, option.WithEndpoint(server.URL), option.WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatalf("unable to create REST service: %s", err)
+	}
+	client, err := bigqueryapi.NewClient(ctx, "my-project", option.WithEndpoint(server.URL), option.WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatalf("unable to create client: %s", err)
+	}
+	client.Location = "US"
+
+	srcs := map[string]sources.Source{
+		"bq": startupSource{
+			location:        "us",
+			allowedDatasets: []string{"my-project.sales", "my-project.eu_sales", "my-project.gone"},
+			client:          client,
+			restService:     restService,
+		},
+		"bq-no-credentials": startupSource{tokenErr: errors.New("no default credentials")},
+		"bq-client-auth":    startupSource{clientAuth: true},
+	}
+	idParam := parameters.NewIntParameter("id", "the id")
+	toolsMap := map[string]tools.Tool{
+		"ok": startupTool{source: "bq", query: &bigquerycommon.StaticQuery{
+			Statement:  "SELECT * FROM sales.orders WHERE id = @id",
+			Parameters: parameters.Parameters{idParam},
+		}},
+		"broken":            startupTool{source: "bq", query: &bigquerycommon.StaticQuery{Statement: "SELECT * FROM sales.missing"}},
+		"templated":         startupTool{source: "bq"},
+		"unchecked":         startupTool{source: "bq-no-credentials", query: &bigquerycommon.StaticQuery{Statement: "SELECT 1"}},
+		"client-authorized": startupTool{source: "bq-client-auth", query: &bigquerycommon.StaticQuery{Statement: "SELECT 1"}},
+	}
+
+	report := bigquerycommon.ValidateTools(ctx, srcs, toolsMap)
+	if report.OK {
+		t.Errorf("expected the report to fail")
+	}
+	type check struct{ check, source, target, status string }
+	var got []check
+	for _, c := range report.Checks {
+		if c.Status == bigquerycommon.CheckFailed && c.Message == "" {
+			t.Errorf("expected a message for the failed check %+v", c)
+		}
+		got = append(got, check{c.Check, c.Source, c.Tool + c.Target, c.Status})
+	}
+	want := []check{
+		{"credentials", "bq", "", "pass"},
+		{"allowedDataset", "bq", "my-project.eu_sales", "pass"},
+		{"datasetLocation", "bq", "my-project.eu_sales", "fail"},
+		{"allowedDataset", "bq", "my-project.gone", "fail"},
+		{"allowedDataset", "bq", "my-project.sales", "pass"},
+		{"datasetLocation", "bq", "my-project.sales", "pass"},
+		{"dryRun", "bq", "broken", "fail"},
+		{"dryRun", "bq", "ok", "pass"},
+		{"dryRun", "bq", "templated", "skip"},
+		{"credentials", "bq-client-auth", "", "skip"},
+		{"credentials", "bq-no-credentials", "", "fail"},
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(check{})); diff != "" {
+		t.Errorf("unexpected checks (-want +got):\n%s", diff)
+	}
+
+	if len(dryRuns) != 2 {
+		t.Fatalf("got %d dry runs, want 2", len(dryRuns))
+	}
+	for _, job := range dryRuns {
+		if !job.Configuration.DryRun || job.JobReference.ProjectId != "my-project" || job.JobReference.Location != "US" {
+			t.Errorf("unexpected dry run job %+v", job.JobReference)
+		}
+	}
+	// the query parameters of the dry run are typed NULLs
+	params := dryRuns[1].Configuration.Query.QueryParameters
+	if len(params) != 1 || params[0].Name != "id" || params[0].ParameterType.Type != "INT64" || params[0].ParameterValue.Value != "" {
+		t.Errorf("unexpected query parameters %+v", params)
+	}
+}
+
+func TestValidateToolsReportJSON(t *testing.T) {
+	report := bigquerycommon.ValidateTools(context.Background(), map[string]sources.Source{}, map[string]tools.Tool{})
+	b, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(b) != `{"ok":true,"checks":[]}` {
+		t.Errorf("got %s, want an empty passing report", b)
+	}
+}
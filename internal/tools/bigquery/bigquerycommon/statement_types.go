@@ -0,0 +1,162 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/googleapis/genai-toolbox/internal/util"
+)
+
+// statementObjectWords name the kind of object of CREATE, ALTER, DROP,
+// EXPORT and LOAD statements, e.g. MATERIALIZED VIEW.
+var statementObjectWords = map[string]bool{
+	"table": true, "view": true, "materialized": true, "model": true, "function": true,
+	"procedure": true, "schema": true, "snapshot": true, "external": true, "search": true,
+	"vector": true, "index": true, "row": true, "access": true, "policy": true, "data": true,
+}
+
+// statementModifierWords may precede the kind of object of a statement, e.g.
+// CREATE OR REPLACE TEMP TABLE, and are not part of its type.
+var statementModifierWords = map[string]bool{
+	"or": true, "replace": true, "temp": true, "temporary": true, "if": true, "not": true, "exists": true,
+}
+
+// ClassifyStatements returns the types of the statements of sql, a query or a
+// script, named like the statement types of BigQuery dry runs, e.g. SELECT,
+// INSERT, CREATE_TABLE or DROP_VIEW. Like DestructiveStatements, it only uses
+// the text of sql, as the dry runs of scripts report the type SCRIPT, and the
+// statements in the blocks of scripts are classified as well. The control
+// statements of scripts, such as DECLARE, SET or IF, are not returned.
+// EXECUTE IMMEDIATE is EXECUTE_IMMEDIATE, as its statement is not known.
+func ClassifyStatements(sql string) []string {
+	var types []string
+	verb, depth := "", 0
+	var object []string
+	inObject, asSelect := false, false
+	end := func() {
+		if t := statementType(verb, object, asSelect); t != "" {
+			types = append(types, t)
+		}
+		verb, depth, object, inObject, asSelect = "", 0, nil, false, false
+	}
+	for _, tok := range sqlTokens(sql) {
+		switch {
+		case tok.text == "(":
+			depth++
+			inObject = false
+		case tok.text == ")":
+			depth--
+		case tok.text == ";" && depth <= 0:
+			end()
+		case !tok.word:
+			inObject = false
+		case verb == "":
+			if !scriptBlockKeywords[tok.text] {
+				verb, inObject = tok.text, true
+			}
+		case depth == 0 && scriptConditionVerbs[verb] && (tok.text == "then" || tok.text == "do"):
+			// the body is classified as its own statement
+			verb = ""
+		case inObject && statementModifierWords[tok.text]:
+		case inObject && statementObjectWords[tok.text]:
+			object = append(object, tok.text)
+		default:
+			inObject = false
+			if depth == 0 && tok.text == "as" {
+				asSelect = true
+			}
+		}
+	}
+	if verb != "" {
+		end()
+	}
+	return types
+}
+
+// statementType returns the type of a statement starting with verb, followed
+// by the words object naming the kind of its object. asSelect is whether it
+// has an AS at its top level, as in CREATE TABLE ... AS SELECT.
+func statementType(verb string, object []string, asSelect bool) string {
+	switch verb {
+	case verbSelect, "with":
+		return "SELECT"
+	case verbInsert, verbUpdate, verbDelete, verbMerge, "call", "grant", "revoke":
+		return strings.ToUpper(verb)
+	case verbTruncate:
+		return "TRUNCATE_TABLE"
+	case "execute":
+		return "EXECUTE_IMMEDIATE"
+	case verbCreate, verbAlter, verbDrop, "export", "load":
+		t := strings.ToUpper(strings.Join(append([]string{verb}, object...), "_"))
+		if t == "CREATE_TABLE" && asSelect {
+			t = "CREATE_TABLE_AS_SELECT"
+		}
+		return t
+	}
+	return ""
+}
+
+// NormalizeStatementTypes returns the statement types of the configuration of
+// a tool in upper case, or an error if one of them is empty.
+func NormalizeStatementTypes(statementTypes []string) ([]string, error) {
+	normalized := make([]string, 0, len(statementTypes))
+	for _, t := range statementTypes {
+		t = strings.ToUpper(strings.TrimSpace(t))
+		if t == "" {
+			return nil, fmt.Errorf("allowedStatementTypes must not have empty statement types")
+		}
+		normalized = append(normalized, t)
+	}
+	return normalized, nil
+}
+
+// StatementTypesDescription returns the sentence describing allowed, the
+// statement types that a tool runs, which is added to its description.
+func StatementTypesDescription(allowed []string) string {
+	return fmt.Sprintf("This tool only runs statements of the types %s; other statements are rejected.", strings.Join(allowed, ", "))
+}
+
+// CheckStatementTypes returns a restriction error if sql, whose dry run
+// reported the statement type statementType, is not one of the statement
+// types allowed, in upper case, for the tool named toolName. The statements
+// of scripts are classified with ClassifyStatements, and must all be
+// allowed. Tools without allowed statement types run any statement.
+func CheckStatementTypes(ctx context.Context, toolName string, allowed []string, sql, statementType string) util.ToolboxError {
+	if len(allowed) == 0 {
+		return nil
+	}
+	types := []string{strings.ToUpper(statementType)}
+	if types[0] == "SCRIPT" {
+		types = ClassifyStatements(sql)
+	}
+	for _, t := range types {
+		if slices.Contains(allowed, t) {
+			continue
+		}
+		msg := fmt.Sprintf("statement type '%s' is not allowed by this tool, which only runs statements of the types %s", t, strings.Join(allowed, ", "))
+		if logger, err := util.LoggerFromContext(ctx); err == nil {
+			logger.InfoContext(ctx, fmt.Sprintf("tool %q rejected the invocation: %s", toolName, msg))
+		}
+		return util.NewAgentError(msg, nil).WithCode(util.ErrorCodePermissionDenied).WithDetails(map[string]any{
+			"statementType":         t,
+			"allowedStatementTypes": allowed,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+	"github.com/googleapis/genai-toolbox/internal/util"
+)
+
+func TestClassifyStatements(t *testing.T) {
+	tcs := []struct {
+		sql  string
+		want []string
+	}{
+		{sql: "SELECT * FROM ds.t", want: []string{"SELECT"}},
+		{sql: "WITH a AS (SELECT 1) SELECT * FROM a", want: []string{"SELECT"}},
+		{sql: "(SELECT 1) UNION ALL (SELECT 2)", want: []string{"SELECT"}},
+		{sql: "INSERT INTO ds.t SELECT * FROM ds.u; UPDATE ds.t SET x = 1 WHERE TRUE", want: []string{"INSERT", "UPDATE"}},
+		{sql: "CREATE OR REPLACE TEMP TABLE t AS SELECT 1", want: []string{"CREATE_TABLE_AS_SELECT"}},
+		{sql: "CREATE TABLE IF NOT EXISTS ds.t (x INT64 OPTIONS (description = 'as'))", want: []string{"CREATE_TABLE"}},
+		{sql: "CREATE MATERIALIZED VIEW ds.v AS SELECT 1", want: []string{"CREATE_MATERIALIZED_VIEW"}},
+		{sql: "CREATE TEMP FUNCTION f(x INT64) AS (x + 1); SELECT f(1)", want: []string{"CREATE_FUNCTION", "SELECT"}},
+		{sql: "DROP TABLE IF EXISTS ds.t; TRUNCATE TABLE ds.u", want: []string{"DROP_TABLE", "TRUNCATE_TABLE"}},
+		{sql: "EXPORT DATA OPTIONS (uri = 'gs://b/*') AS SELECT 1", want: []string{"EXPORT_DATA"}},
+		{sql: "EXECUTE IMMEDIATE 'DROP TABLE ds.t'", want: []string{"EXECUTE_IMMEDIATE"}},
+		{
+			sql:  "DECLARE n INT64 DEFAULT 0;\nIF n = 0 THEN\n  SELECT 1;\nELSE\n  DELETE FROM ds.t WHERE TRUE;\nEND IF;\nBEGIN\n  MERGE ds.t USING ds.u ON FALSE WHEN NOT MATCHED THEN INSERT ROW;\nEND;",
+			want: []string{"SELECT", "DELETE", "MERGE"},
+		},
+		{sql: "-- DROP TABLE ds.t\nSELECT 'DELETE FROM ds.t'", want: []string{"SELECT"}},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.sql, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, bigquerycommon.ClassifyStatements(tc.sql)); diff != "" {
+				t.Errorf("unexpected statement types (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCheckStatementTypes(t *testing.T) {
+	allowed := []string{"SELECT", "CREATE_TABLE_AS_SELECT"}
+	tcs := []struct {
+		desc          string
+		allowed       []string
+		sql           string
+		statementType string
+		wantErr       string
+	}{
+		{desc: "allowed", allowed: allowed, sql: "SELECT 1", statementType: "SELECT"},
+		{desc: "disallowed", allowed: allowed, sql: "DELETE FROM ds.t WHERE TRUE", statementType: "DELETE", wantErr: "statement type 'DELETE' is not allowed by this tool, which only runs statements of the types SELECT, CREATE_TABLE_AS_SELECT"},
+		{desc: "script", allowed: allowed, sql: "CREATE TEMP TABLE t AS SELECT 1; SELECT * FROM t", statementType: "SCRIPT"},
+		{
+			desc:          "script with a disallowed statement after an allowed one",
+			allowed:       allowed,
+			sql:           "SELECT 1; BEGIN SELECT 2; DROP TABLE ds.t; END",
+			statementType: "SCRIPT",
+			wantErr:       "statement type 'DROP_TABLE' is not allowed",
+		},
+		{desc: "no restriction", sql: "DROP TABLE ds.t", statementType: "DROP_TABLE"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			tbErr := bigquerycommon.CheckStatementTypes(context.Background(), "execute_sql", tc.allowed, tc.sql, tc.statementType)
+			if tc.wantErr == "" {
+				if tbErr != nil {
+					t.Fatalf("unexpected error: %s", tbErr)
+				}
+				return
+			}
+			if tbErr == nil || !strings.Contains(tbErr.Error(), tc.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantErr, tbErr)
+			}
+			if code := tbErr.ErrorInfo().Code; code != util.ErrorCodePermissionDenied {
+				t.Errorf("unexpected error code: %s", code)
+			}
+		})
+	}
+}
+
+func TestNormalizeStatementTypes(t *testing.T) {
+	got, err := bigquerycommon.NormalizeStatementTypes([]string{"select", " Create_Table "})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diff := cmp.Diff([]string{"SELECT", "CREATE_TABLE"}, got); diff != "" {
+		t.Errorf("unexpected statement types (-want +got):\n%s", diff)
+	}
+	if _, err := bigquerycommon.NormalizeStatementTypes([]string{"SELECT", ""}); err == nil {
+		t.Errorf("expected an error for an empty statement type")
+	}
+}
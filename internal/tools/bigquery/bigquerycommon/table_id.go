@@ -0,0 +1,95 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TableRef is a table identified by CanonicalTableID.
+type TableRef struct {
+	ProjectID string
+	DatasetID string
+	TableID   string
+}
+
+// String returns the ID of the table, "project.dataset.table".
+func (r TableRef) String() string {
+	return fmt.Sprintf("%s.%s.%s", r.ProjectID, r.DatasetID, r.TableID)
+}
+
+// Dataset returns the ID of the dataset of the table, "project.dataset".
+func (r TableRef) Dataset() string {
+	return fmt.Sprintf("%s.%s", r.ProjectID, r.DatasetID)
+}
+
+// CanonicalTableID parses the table identifier raw, as written in a query, a
+// parameter or a dry run, into the table it refers to. Every comparison of
+// tables against allowed datasets goes through it, so that identifiers of
+// the same table are compared equal:
+//
+//   - Whitespace around raw, the brackets of legacy SQL ("[p:d.t]") and
+//     backticks are removed. Dots inside backticks separate parts, like
+//     outside of them: "`p.d`.t" and "`p.d.t`" are p.d.t.
+//   - The legacy colon form "p:d.t" is p.d.t. Projects scoped to a domain
+//     keep their colon: "example.com:p.d.t" is in project "example.com:p".
+//   - Partition and snapshot decorators, "t$20240101" or "t@1700000000", are
+//     removed, as they refer to the table they decorate.
+//   - Project IDs are lowercased, as they are case-insensitive. Dataset and
+//     table names are case-sensitive, and kept as is.
+//   - Identifiers of two parts, "d.t", are in defaultProject. It is an error
+//     if defaultProject is empty, or if raw does not have 2 or 3 non-empty
+//     parts.
+func CanonicalTableID(raw, defaultProject string) (TableRef, error) {
+	id := strings.TrimSpace(raw)
+	if strings.HasPrefix(id, "[") && strings.HasSuffix(id, "]") {
+		id = id[1 : len(id)-1]
+	}
+	id = strings.ReplaceAll(id, "`", "")
+
+	var project string
+	colon := strings.LastIndex(id, ":")
+	if colon >= 0 {
+		project, id = id[:colon], id[colon+1:]
+	}
+	parts := strings.Split(id, ".")
+	switch {
+	case colon >= 0 && len(parts) == 3:
+		// a project scoped to a domain, "example.com:p.d.t"
+		project, parts = project+":"+parts[0], parts[1:]
+	case colon >= 0 && len(parts) == 2:
+		// the legacy colon form, "p:d.t"
+	case len(parts) == 3:
+		project, parts = parts[0], parts[1:]
+	case len(parts) == 2:
+		if defaultProject == "" {
+			return TableRef{}, fmt.Errorf("table '%s' has no project ID, and no default project ID is provided", raw)
+		}
+		project = defaultProject
+	default:
+		return TableRef{}, fmt.Errorf("invalid table ID '%s', expected 'dataset.table' or 'project.dataset.table'", raw)
+	}
+
+	table := parts[1]
+	if i := strings.IndexAny(table, "$@"); i >= 0 {
+		table = table[:i]
+	}
+	ref := TableRef{ProjectID: strings.ToLower(strings.TrimSpace(project)), DatasetID: strings.TrimSpace(parts[0]), TableID: strings.TrimSpace(table)}
+	if ref.ProjectID == "" || ref.DatasetID == "" || ref.TableID == "" {
+		return TableRef{}, fmt.Errorf("invalid table ID '%s', its project, dataset and table must not be empty", raw)
+	}
+	return ref, nil
+}
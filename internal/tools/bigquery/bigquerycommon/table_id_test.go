@@ -0,0 +1,112 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+)
+
+// tableIDCases are identifiers of tables as they are written in queries,
+// parameters and dry runs. Those with inQuery set are also valid in the FROM
+// clause of a query, and must be found by TableParser as the same table.
+var tableIDCases = []struct {
+	raw     string
+	want    string
+	inQuery bool
+	wantErr string
+}{
+	{raw: "proj.ds.tbl", want: "proj.ds.tbl", inQuery: true},
+	{raw: "ds.tbl", want: "default-proj.ds.tbl", inQuery: true},
+	{raw: "`proj.ds.tbl`", want: "proj.ds.tbl", inQuery: true},
+	{raw: "`proj`.`ds`.`tbl`", want: "proj.ds.tbl", inQuery: true},
+	{raw: "`proj.ds`.tbl", want: "proj.ds.tbl", inQuery: true},
+	{raw: "proj.`ds.tbl`", want: "proj.ds.tbl", inQuery: true},
+	{raw: "`proj`.`ds.tbl`", want: "proj.ds.tbl", inQuery: true},
+	{raw: "proj.`ds`.tbl", want: "proj.ds.tbl", inQuery: true},
+	{raw: "`my-project.ds.tbl`", want: "my-project.ds.tbl", inQuery: true},
+	{raw: "My-Project.Ds.Tbl", want: "my-project.Ds.Tbl", inQuery: true},
+	{raw: "`PROJ`.ds.tbl", want: "proj.ds.tbl", inQuery: true},
+	{raw: "`proj.ds.events_2024*`", want: "proj.ds.events_2024*", inQuery: true},
+	{raw: "`proj.ds.tbl$20240101`", want: "proj.ds.tbl", inQuery: true},
+	{raw: "proj.ds.tbl$__UNPARTITIONED__", want: "proj.ds.tbl"},
+	{raw: "proj.ds.tbl@1700000000000", want: "proj.ds.tbl"},
+	{raw: "proj:ds.tbl", want: "proj.ds.tbl"},
+	{raw: "[proj:ds.tbl]", want: "proj.ds.tbl"},
+	{raw: "[Proj:ds.tbl$20240101]", want: "proj.ds.tbl"},
+	{raw: "`example.com:proj.ds.tbl`", want: "example.com:proj.ds.tbl", inQuery: true},
+	{raw: "example.com:proj:ds.tbl", want: "example.com:proj.ds.tbl"},
+	{raw: "  proj.ds.tbl  ", want: "proj.ds.tbl"},
+	{raw: "tbl", wantErr: "expected 'dataset.table' or 'project.dataset.table'"},
+	{raw: "a.b.c.d", wantErr: "expected 'dataset.table' or 'project.dataset.table'"},
+	{raw: "proj..tbl", wantErr: "must not be empty"},
+	{raw: "proj.ds.$20240101", wantErr: "must not be empty"},
+	{raw: ":ds.tbl", wantErr: "must not be empty"},
+}
+
+func TestCanonicalTableID(t *testing.T) {
+	for _, tc := range tableIDCases {
+		t.Run(tc.raw, func(t *testing.T) {
+			got, err := bigquerycommon.CanonicalTableID(tc.raw, "default-proj")
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got.String() != tc.want {
+				t.Fatalf("got %q, want %q", got, tc.want)
+			}
+			// the canonical ID is its own canonical ID
+			again, err := bigquerycommon.CanonicalTableID(got.String(), "")
+			if err != nil || again != got {
+				t.Fatalf("expected %q to be canonical, got %q, %v", got, again, err)
+			}
+		})
+	}
+}
+
+func TestTableParserUsesCanonicalTableID(t *testing.T) {
+	for _, tc := range tableIDCases {
+		if !tc.inQuery {
+			continue
+		}
+		t.Run(tc.raw, func(t *testing.T) {
+			got, err := bigquerycommon.TableParser("SELECT * FROM "+tc.raw+" WHERE x = 1", "default-proj")
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if diff := cmp.Diff([]string{tc.want}, got); diff != "" {
+				t.Fatalf("unexpected tables (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTableRefDataset(t *testing.T) {
+	ref, err := bigquerycommon.CanonicalTableID("`Proj.ds.tbl$1`", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := ref.Dataset(); got != "proj.ds" {
+		t.Fatalf("got dataset %q, want proj.ds", got)
+	}
+}
@@ -15,9 +15,15 @@
 package bigquerycommon
 
 import (
+	"context"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 	"unicode"
+	"unicode/utf8"
+
+	bigqueryds "github.com/googleapis/genai-toolbox/internal/sources/bigquery"
 )
 
 // parserState defines the state of the SQL parser's state machine.
@@ -50,6 +56,9 @@ const (
 	verbUpdate = "update"
 	verbDelete = "delete"
 	verbMerge  = "merge"
+	// verbTruncate is only used to classify statements, TRUNCATE TABLE is
+	// parsed like any other statement.
+	verbTruncate = "truncate"
 )
 
 var tableFollowsKeywords = map[string]bool{
@@ -63,6 +72,36 @@ var tableFollowsKeywords = map[string]bool{
 	"merge":  true, // MERGE my_table
 }
 
+// reservedKeywords are the reserved keywords of GoogleSQL, which cannot be
+// aliases.
+var reservedKeywords = map[string]bool{
+	"all": true, "and": true, "any": true, "array": true, "as": true, "asc": true,
+	"assert_rows_modified": true, "at": true, "between": true, "by": true, "case": true,
+	"cast": true, "collate": true, "contains": true, "create": true, "cross": true,
+	"cube": true, "current": true, "default": true, "define": true, "desc": true,
+	"distinct": true, "else": true, "end": true, "enum": true, "escape": true,
+	"except": true, "exclude": true, "exists": true, "extract": true, "false": true,
+	"fetch": true, "following": true, "for": true, "from": true, "full": true,
+	"group": true, "grouping": true, "groups": true, "hash": true, "having": true,
+	"if": true, "ignore": true, "in": true, "inner": true, "intersect": true,
+	"interval": true, "into": true, "is": true, "join": true, "lateral": true,
+	"left": true, "like": true, "limit": true, "lookup": true, "merge": true,
+	"natural": true, "new": true, "no": true, "not": true, "null": true, "nulls": true,
+	"of": true, "on": true, "or": true, "order": true, "outer": true, "over": true,
+	"partition": true, "preceding": true, "proto": true, "qualify": true,
+	"range": true, "recursive": true, "respect": true, "right": true, "rollup": true,
+	"rows": true, "select": true, "set": true, "some": true, "struct": true,
+	"tablesample": true, "then": true, "to": true, "treat": true, "true": true,
+	"unbounded": true, "union": true, "unnest": true, "using": true, "when": true,
+	"where": true, "window": true, "with": true, "within": true,
+}
+
+// isFromClauseKeyword returns whether tables following keyword, e.g. FROM or
+// JOIN, may have aliases that later paths reference.
+func isFromClauseKeyword(keyword string) bool {
+	return keyword == "from" || keyword == "join"
+}
+
 var tableContextExitKeywords = map[string]bool{
 	"where":  true,
 	"group":  true, // GROUP BY
@@ -75,25 +114,142 @@ var tableContextExitKeywords = map[string]bool{
 	"when":   true, // MERGE ... WHEN
 }
 
+// UnanalyzableFunctionError is returned by TableParser for a temporary
+// function whose body is not written in SQL, such as a JavaScript UDF. Its
+// body is opaque to the parser and to the dry run, so the tables it accesses
+// cannot be validated.
+type UnanalyzableFunctionError struct {
+	// Name is the name of the function.
+	Name string
+	// Language is the language of its body, as written in the query.
+	Language string
+}
+
+func (e *UnanalyzableFunctionError) Error() string {
+	if strings.EqualFold(e.Language, "js") {
+		return fmt.Sprintf("JavaScript UDF '%s' cannot be analyzed when dataset restrictions are in place, only SQL temporary functions are allowed", e.Name)
+	}
+	return fmt.Sprintf("UDF '%s' in language '%s' cannot be analyzed when dataset restrictions are in place, only SQL temporary functions are allowed", e.Name, e.Language)
+}
+
+// tempFunction is the temporary function declared by the statement being
+// parsed.
+type tempFunction struct {
+	name string
+	// expectingLanguage is set after the LANGUAGE keyword.
+	expectingLanguage bool
+}
+
 // TableParser is the main entry point for parsing a SQL string to find all referenced table IDs.
 // It handles multi-statement SQL, comments, and recursive parsing of EXECUTE IMMEDIATE statements.
 func TableParser(sql, defaultProjectID string) ([]string, error) {
+	refs, err := ParseSQL(context.Background(), sql, ParseOptions{DefaultProjectID: defaultProjectID})
+	return refs.TableIDs, err
+}
+
+// ConnectionParser parses a SQL string to find the IDs of the BigQuery
+// connections it uses, in EXTERNAL_QUERY calls and WITH CONNECTION clauses,
+// such as those of CREATE EXTERNAL TABLE. The IDs are normalized by
+// bigqueryds.NormalizeConnectionID, except for the default connection, which
+// is returned as DefaultConnection.
+func ConnectionParser(sql, defaultProjectID string) ([]string, error) {
+	refs, err := ParseSQL(context.Background(), sql, ParseOptions{DefaultProjectID: defaultProjectID})
+	return refs.ConnectionIDs, err
+}
+
+// StorageURIParser parses a SQL string to find the URIs of the external data
+// it reads, in the uris option of CREATE EXTERNAL TABLE statements and of the
+// FILES of LOAD DATA statements. The URIs are returned as written, with their
+// wildcards. The option must be an array of string literals, so that the URIs
+// can be validated.
+func StorageURIParser(sql, defaultProjectID string) ([]string, error) {
+	refs, err := ParseSQL(context.Background(), sql, ParseOptions{DefaultProjectID: defaultProjectID})
+	return refs.StorageURIs, err
+}
+
+// UnresolvedTableParser parses a SQL string to find the names of one part
+// that follow a table keyword, such as FROM or INTO. They are not returned by
+// TableParser, as they are common table expressions, temporary tables, or
+// tables whose dataset cannot be resolved without a default dataset.
+func UnresolvedTableParser(sql, defaultProjectID string) ([]string, error) {
+	refs, err := ParseSQL(context.Background(), sql, ParseOptions{DefaultProjectID: defaultProjectID})
+	return refs.UnresolvedTables, err
+}
+
+// byteOrderMark is the UTF-8 byte order mark that some editors start files
+// with.
+const byteOrderMark = "\uFEFF"
+
+// DefaultConnection is the connection ID returned by ConnectionParser for
+// `WITH CONNECTION DEFAULT`, which cannot be resolved without the
+// configuration of the project.
+const DefaultConnection = "DEFAULT"
+
+// parseCheckInterval is the number of bytes of SQL scanned between the
+// checks of the context of a parse.
+const parseCheckInterval = 4096
+
+// ParseOptions configures ParseSQL.
+type ParseOptions struct {
+	// DefaultProjectID is the project of the tables and connections named
+	// without one.
+	DefaultProjectID string
+}
+
+// SQLReferences are the tables, connections and external data referenced by
+// a SQL string, see ParseSQL.
+type SQLReferences struct {
+	// TableIDs are the tables, see TableParser.
+	TableIDs []string
+	// ConnectionIDs are the connections, see ConnectionParser.
+	ConnectionIDs []string
+	// StorageURIs are the URIs of external data, see StorageURIParser.
+	StorageURIs []string
+	// UnresolvedTables are the names of one part where tables are expected,
+	// see UnresolvedTableParser.
+	UnresolvedTables []string
+}
+
+// ParseSQL parses sql in a single pass to find the tables, connections and
+// external data it references, like TableParser, ConnectionParser,
+// StorageURIParser and UnresolvedTableParser. Parsing stops with the error of
+// ctx once it is done, as large or deeply nested queries can take long to
+// parse; the error wraps context.Canceled or context.DeadlineExceeded.
+func ParseSQL(ctx context.Context, sql string, opts ParseOptions) (SQLReferences, error) {
+	// SQL pasted from editors may start with a byte order mark.
+	sql = strings.TrimPrefix(sql, byteOrderMark)
 	tableIDSet := make(map[string]struct{})
+	connectionIDSet := make(map[string]struct{})
+	storageURISet := make(map[string]struct{})
+	unresolvedTableSet := make(map[string]struct{})
 	visitedSQLs := make(map[string]struct{})
-	if _, err := parseSQL(sql, defaultProjectID, tableIDSet, visitedSQLs, false); err != nil {
-		return nil, err
+	if _, err := parseSQL(ctx, sql, opts.DefaultProjectID, tableIDSet, connectionIDSet, storageURISet, unresolvedTableSet, visitedSQLs, nil, nil, false); err != nil {
+		return SQLReferences{}, err
 	}
+	return SQLReferences{
+		TableIDs:         setKeys(tableIDSet),
+		ConnectionIDs:    setKeys(connectionIDSet),
+		StorageURIs:      setKeys(storageURISet),
+		UnresolvedTables: setKeys(unresolvedTableSet),
+	}, nil
+}
 
-	tableIDs := make([]string, 0, len(tableIDSet))
-	for id := range tableIDSet {
-		tableIDs = append(tableIDs, id)
-	}
-	return tableIDs, nil
+// setKeys returns the keys of set, in no particular order. It returns an
+// empty slice, not nil, for an empty set.
+func setKeys(set map[string]struct{}) []string {
+	return slices.AppendSeq(make([]string, 0, len(set)), maps.Keys(set))
 }
 
 // parseSQL is the core recursive function that processes SQL strings.
 // It uses a state machine to find table names and recursively parse EXECUTE IMMEDIATE.
-func parseSQL(sql, defaultProjectID string, tableIDSet map[string]struct{}, visitedSQLs map[string]struct{}, inSubquery bool) (int, error) {
+// outerAliases are the aliases of the tables and subqueries of the enclosing
+// query, and outerCTEs the names of its common table expressions, which
+// subqueries can reference. It returns the error of ctx once it
+// is done, checked before each recursion and every parseCheckInterval bytes.
+func parseSQL(ctx context.Context, sql, defaultProjectID string, tableIDSet, connectionIDSet, storageURISet, unresolvedTableSet map[string]struct{}, visitedSQLs map[string]struct{}, outerAliases, outerCTEs map[string]bool, inSubquery bool) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, fmt.Errorf("parsing stopped: %w", err)
+	}
 	// Prevent infinite recursion.
 	if _, ok := visitedSQLs[sql]; ok {
 		return len(sql), nil
@@ -103,10 +259,55 @@ func parseSQL(sql, defaultProjectID string, tableIDSet map[string]struct{}, visi
 	state := stateNormal
 	expectingTable := false
 	var lastTableKeyword, lastToken, statementVerb string
-	runes := []rune(sql)
-
-	for i := 0; i < len(runes); {
-		char := runes[i]
+	var function *tempFunction
+	// expectingConnection is set after WITH CONNECTION.
+	expectingConnection := false
+	// expectingOptions is set after OPTIONS and FROM FILES, whose list of
+	// options follows in parentheses. optionsDepth counts the parentheses
+	// open in the list.
+	expectingOptions := false
+	optionsDepth := 0
+	// inScriptSet is set after the SET of a script statement, e.g.
+	// SET (a, b) = (SELECT ...), until its '='. expectingSetValue is set
+	// after the '=', whose parenthesized value is a subquery.
+	inScriptSet := false
+	expectingSetValue := false
+	// parenDepth counts the parentheses open in the statement, other than
+	// those of subqueries and lists of options.
+	parenDepth := 0
+	// inWith is set in the WITH clause of a statement, whose list of common
+	// table expressions is at the parenDepth withDepth.
+	inWith := false
+	withDepth := 0
+	// expectingAlias is set after a table or a subquery in the FROM clause,
+	// which may be followed by its alias.
+	expectingAlias := false
+	// aliases are the lower case aliases of the tables and subqueries of the
+	// statement. Paths through them, e.g. t.arr in FROM t, t.arr, are not
+	// tables.
+	aliases := maps.Clone(outerAliases)
+	if aliases == nil {
+		aliases = make(map[string]bool)
+	}
+	// ctes are the lower case names of the common table expressions of the
+	// statement. Unlike aliases, they are only referenced as a whole, e.g.
+	// `my.cte`, so sales.orders is a table even with a CTE named sales.
+	ctes := maps.Clone(outerCTEs)
+	if ctes == nil {
+		ctes = make(map[string]bool)
+	}
+	// i is a byte offset, as are the lengths consumed by the helpers. A byte
+	// in the middle of a multi-byte rune decodes to utf8.RuneError, which is
+	// skipped like any other punctuation.
+	nextCheck := parseCheckInterval
+	for i := 0; i < len(sql); {
+		if i >= nextCheck {
+			if err := ctx.Err(); err != nil {
+				return 0, fmt.Errorf("parsing stopped: %w", err)
+			}
+			nextCheck = i + parseCheckInterval
+		}
+		char, _ := utf8.DecodeRuneInString(sql[i:])
 		remaining := sql[i:]
 
 		switch state {
@@ -127,9 +328,28 @@ func parseSQL(sql, defaultProjectID string, tableIDSet map[string]struct{}, visi
 				continue
 			}
 			if char == '(' {
+				if expectingOptions || optionsDepth > 0 {
+					// a list of options, not a subquery
+					expectingOptions = false
+					expectingAlias = false
+					optionsDepth++
+					i++
+					continue
+				}
+				if expectingSetValue {
+					// the subquery assigned to the variables of a script
+					// SET, which may read tables
+					expectingSetValue = false
+					consumed, err := parseSQL(ctx, remaining[1:], defaultProjectID, tableIDSet, connectionIDSet, storageURISet, unresolvedTableSet, visitedSQLs, aliases, ctes, true)
+					if err != nil {
+						return 0, err
+					}
+					i += consumed + 1
+					continue
+				}
 				if expectingTable {
 					// The subquery starts after '('.
-					consumed, err := parseSQL(remaining[1:], defaultProjectID, tableIDSet, visitedSQLs, true)
+					consumed, err := parseSQL(ctx, remaining[1:], defaultProjectID, tableIDSet, connectionIDSet, storageURISet, unresolvedTableSet, visitedSQLs, aliases, ctes, true)
 					if err != nil {
 						return 0, err
 					}
@@ -140,11 +360,22 @@ func parseSQL(sql, defaultProjectID string, tableIDSet map[string]struct{}, visi
 					if lastTableKeyword != "from" {
 						expectingTable = false
 					}
+					expectingAlias = isFromClauseKeyword(lastTableKeyword)
 					continue
 				}
+				// e.g. the arguments of a function, which the subquery
+				// does not end with
+				parenDepth++
 			}
 			if char == ')' {
-				if inSubquery {
+				if optionsDepth > 0 {
+					optionsDepth--
+					i++
+					continue
+				}
+				if parenDepth > 0 {
+					parenDepth--
+				} else if inSubquery {
 					return i + 1, nil
 				}
 			}
@@ -152,10 +383,31 @@ func parseSQL(sql, defaultProjectID string, tableIDSet map[string]struct{}, visi
 			if char == ';' {
 				statementVerb = ""
 				lastToken = ""
+				function = nil
+				expectingAlias = false
+				expectingOptions = false
+				optionsDepth = 0
+				inScriptSet = false
+				expectingSetValue = false
+				parenDepth = 0
+				inWith = false
+				clear(aliases)
+				clear(ctes)
 				i++
 				continue
 
 			}
+			if inScriptSet && char == '=' {
+				inScriptSet = false
+				expectingSetValue = true
+				i++
+				continue
+			}
+			if !unicode.IsLetter(char) && char != '`' && char != '_' && !unicode.IsSpace(char) {
+				expectingAlias = false
+				expectingOptions = false
+				expectingSetValue = false
+			}
 
 			// Raw strings must be checked before regular strings.
 			if strings.HasPrefix(remaining, "r'''") || strings.HasPrefix(remaining, "R'''") {
@@ -200,7 +452,7 @@ func parseSQL(sql, defaultProjectID string, tableIDSet map[string]struct{}, visi
 			}
 
 			if unicode.IsLetter(char) || char == '`' {
-				parts, consumed, err := parseIdentifierSequence(remaining)
+				parts, segments, consumed, err := parseIdentifierSequence(remaining)
 				if err != nil {
 					return 0, err
 				}
@@ -208,10 +460,102 @@ func parseSQL(sql, defaultProjectID string, tableIDSet map[string]struct{}, visi
 					i++
 					continue
 				}
+				expectingOptions = false
+				expectingSetValue = false
+
+				// The alias of a table or subquery is registered before
+				// keywords, such as JOIN, take effect.
+				if expectingAlias {
+					if len(parts) == 1 && strings.EqualFold(parts[0], "as") {
+						i += consumed
+						continue
+					}
+					expectingAlias = false
+					if len(segments) == 1 && !reservedKeywords[strings.ToLower(segments[0])] {
+						aliases[aliasKey(segments[0])] = true
+						i += consumed
+						continue
+					}
+				}
+
+				// the names of the common table expressions of a WITH
+				// clause
+				if inWith && parenDepth == withDepth && len(segments) == 1 && cteFollows(remaining[consumed:]) {
+					ctes[aliasKey(segments[0])] = true
+					i += consumed
+					continue
+				}
+
+				if expectingConnection {
+					expectingConnection = false
+					// WITH connection AS (...) is a common table expression
+					if !(len(parts) == 1 && strings.EqualFold(parts[0], "as")) {
+						if err := addConnection(connectionIDSet, strings.Join(parts, "."), defaultProjectID); err != nil {
+							return 0, err
+						}
+						i += consumed
+						continue
+					}
+				}
+
+				if function != nil {
+					keyword := strings.ToLower(parts[0])
+					switch {
+					case function.name == "" && !(len(parts) == 1 && (keyword == "if" || keyword == "not" || keyword == "exists")):
+						function.name = strings.Join(parts, ".")
+						i += consumed
+						continue
+					case function.expectingLanguage:
+						// the body of SQL functions is parsed with the rest
+						// of the query, others are opaque
+						if keyword != "sql" {
+							return 0, &UnanalyzableFunctionError{Name: function.name, Language: parts[0]}
+						}
+						function.expectingLanguage = false
+						i += consumed
+						continue
+					case len(parts) == 1 && keyword == "language":
+						function.expectingLanguage = true
+					}
+				}
 
 				if len(parts) == 1 {
 					keyword := strings.ToLower(parts[0])
 					switch keyword {
+					case "connection":
+						expectingConnection = lastToken == "with"
+					case "external_query":
+						id, ok := externalQueryConnection(remaining[consumed:])
+						if !ok {
+							return 0, fmt.Errorf("EXTERNAL_QUERY is only allowed with a string literal as its connection, so that the connection can be validated")
+						}
+						if err := addConnection(connectionIDSet, id, defaultProjectID); err != nil {
+							return 0, err
+						}
+					case "options":
+						expectingOptions = true
+					case "files":
+						// LOAD DATA ... FROM FILES (...) is not a table
+						if lastToken == "from" {
+							expectingOptions = true
+							expectingTable = false
+						}
+					case "uris":
+						if optionsDepth == 1 {
+							uris, ok := storageURIsOption(remaining[consumed:])
+							if !ok {
+								return 0, fmt.Errorf("the uris option is only allowed with an array of string literals, so that the URIs can be validated")
+							}
+							for _, uri := range uris {
+								storageURISet[uri] = struct{}{}
+							}
+						}
+					case "set":
+						// SET is a clause of UPDATE, MERGE and ALTER
+						// statements, and a statement of scripts
+						if statementVerb != verbUpdate && statementVerb != verbMerge && statementVerb != verbAlter {
+							inScriptSet = true
+						}
 					case "call":
 						return 0, fmt.Errorf("CALL is not allowed when dataset restrictions are in place, as the called procedure's contents cannot be safely analyzed")
 					case "immediate":
@@ -222,10 +566,19 @@ func parseSQL(sql, defaultProjectID string, tableIDSet map[string]struct{}, visi
 						if lastToken == "create" || lastToken == "create or replace" {
 							return 0, fmt.Errorf("unanalyzable statements like '%s %s' are not allowed", strings.ToUpper(lastToken), strings.ToUpper(keyword))
 						}
+						if keyword == "function" && statementVerb == verbCreate && (lastToken == "temp" || lastToken == "temporary") {
+							function = &tempFunction{}
+						}
 					case verbCreate, verbAlter, verbDrop, verbSelect, verbInsert, verbUpdate, verbDelete, verbMerge:
 						if statementVerb == "" {
 							statementVerb = keyword
 						}
+						if parenDepth == withDepth {
+							inWith = false
+						}
+					case "with":
+						inWith = true
+						withDepth = parenDepth
 					}
 
 					if statementVerb == verbCreate || statementVerb == verbAlter || statementVerb == verbDrop {
@@ -234,6 +587,13 @@ func parseSQL(sql, defaultProjectID string, tableIDSet map[string]struct{}, visi
 						}
 					}
 
+					// a name of one part right after a table keyword, other
+					// than a table function of a FROM clause
+					if expectingTable && lastToken == lastTableKeyword && !reservedKeywords[keyword] && !tableFollowsKeywords[keyword] &&
+						!(isFromClauseKeyword(lastTableKeyword) && strings.HasPrefix(strings.TrimSpace(remaining[consumed:]), "(")) {
+						unresolvedTableSet[parts[0]] = struct{}{}
+					}
+
 					if _, ok := tableFollowsKeywords[keyword]; ok {
 						expectingTable = true
 						lastTableKeyword = keyword
@@ -250,7 +610,19 @@ func parseSQL(sql, defaultProjectID string, tableIDSet map[string]struct{}, visi
 					}
 				} else if len(parts) >= 2 {
 					// This is a multi-part identifier. If we were expecting a table, this is it.
-					if expectingTable {
+					if expectingTable && (len(segments) <= 2 && aliases[aliasKey(segments[0])] || len(segments) == 1 && ctes[aliasKey(segments[0])]) {
+						// a path through an alias, e.g. an array column of
+						// a table, or a common table expression whose
+						// backticked name has dots
+						if len(segments) == 1 {
+							unresolvedTableSet[segments[0]] = struct{}{}
+						}
+						expectingAlias = isFromClauseKeyword(lastTableKeyword)
+						if lastTableKeyword != "from" {
+							expectingTable = false
+						}
+					} else if expectingTable {
+						expectingAlias = isFromClauseKeyword(lastTableKeyword)
 						tableID, err := formatTableID(parts, defaultProjectID)
 						if err != nil {
 							return 0, err
@@ -304,7 +676,8 @@ func parseSQL(sql, defaultProjectID string, tableIDSet map[string]struct{}, visi
 				i++
 			}
 		case stateInSingleLineCommentDash, stateInSingleLineCommentHash:
-			if char == '\n' {
+			// both \n and \r end the comment, which covers \r\n
+			if char == '\n' || char == '\r' {
 				state = stateNormal
 			}
 			i++
@@ -348,16 +721,97 @@ func parseSQL(sql, defaultProjectID string, tableIDSet map[string]struct{}, visi
 	return len(sql), nil
 }
 
+// addConnection adds the connection id, used by the query, to
+// connectionIDSet.
+func addConnection(connectionIDSet map[string]struct{}, id, defaultProjectID string) error {
+	if strings.EqualFold(id, DefaultConnection) {
+		connectionIDSet[DefaultConnection] = struct{}{}
+		return nil
+	}
+	normalized, err := bigqueryds.NormalizeConnectionID(id, defaultProjectID, "")
+	if err != nil {
+		return err
+	}
+	connectionIDSet[normalized] = struct{}{}
+	return nil
+}
+
+// externalQueryConnection returns the connection of the EXTERNAL_QUERY call
+// whose arguments start s, if it is a string literal.
+func externalQueryConnection(s string) (string, bool) {
+	s = strings.TrimLeftFunc(s, unicode.IsSpace)
+	rest, ok := strings.CutPrefix(s, "(")
+	if !ok {
+		return "", false
+	}
+	id, _, ok := cutStringLiteral(rest)
+	return id, ok
+}
+
+// storageURIsOption returns the URIs of the uris option whose value follows
+// s, "= ['gs://bucket/path/*', ...]", if it is an array of string literals.
+func storageURIsOption(s string) ([]string, bool) {
+	s = strings.TrimLeftFunc(s, unicode.IsSpace)
+	rest, ok := strings.CutPrefix(s, "=")
+	if !ok {
+		return nil, false
+	}
+	rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+	if rest, ok = strings.CutPrefix(rest, "["); !ok {
+		return nil, false
+	}
+	var uris []string
+	for {
+		uri, after, ok := cutStringLiteral(rest)
+		if !ok {
+			return nil, false
+		}
+		uris = append(uris, uri)
+		rest = strings.TrimLeftFunc(after, unicode.IsSpace)
+		if strings.HasPrefix(rest, "]") {
+			return uris, true
+		}
+		if rest, ok = strings.CutPrefix(rest, ","); !ok {
+			return nil, false
+		}
+	}
+}
+
+// cutStringLiteral returns the value of the string literal that starts s,
+// after spaces, and the rest of s. Literals with escape sequences are
+// rejected, as their values would differ from their text.
+func cutStringLiteral(s string) (string, string, bool) {
+	s = strings.TrimLeftFunc(s, unicode.IsSpace)
+	raw := len(s) > 0 && (s[0] == 'r' || s[0] == 'R')
+	if raw {
+		s = s[1:]
+	}
+	for _, quote := range []string{"'''", `"""`, "'", `"`} {
+		if literal, ok := strings.CutPrefix(s, quote); ok {
+			end := strings.Index(literal, quote)
+			if end == -1 || (!raw && strings.Contains(literal[:end], `\`)) {
+				return "", "", false
+			}
+			return literal[:end], literal[end+len(quote):], true
+		}
+	}
+	return "", "", false
+}
+
 // parseIdentifierSequence parses a sequence of dot-separated identifiers.
-// It returns the parts of the identifier, the number of characters consumed, and an error.
-func parseIdentifierSequence(s string) ([]string, int, error) {
-	var parts []string
+// It returns the parts of the identifier, its segments, the number of characters consumed, and an error.
+// Like BigQuery, it allows spaces and comments around the dots, e.g.
+// "proj /* c */ . ds.t" is proj.ds.t. Dots inside backticks separate parts
+// like outside of them, as in CanonicalTableID. The segments are the
+// identifiers as written, without backticks, and keep the dots inside them:
+// "`my.alias`.col" has the parts my, alias and col, and the segments my.alias
+// and col.
+func parseIdentifierSequence(s string) ([]string, []string, int, error) {
+	var parts, segments []string
 	var totalConsumed int
 
 	for {
-		remaining := s[totalConsumed:]
-		trimmed := strings.TrimLeftFunc(remaining, unicode.IsSpace)
-		totalConsumed += len(remaining) - len(trimmed)
+		totalConsumed += skipSpacesAndComments(s[totalConsumed:])
 		current := s[totalConsumed:]
 
 		if len(current) == 0 {
@@ -370,7 +824,7 @@ func parseIdentifierSequence(s string) ([]string, int, error) {
 		if current[0] == '`' {
 			end := strings.Index(current[1:], "`")
 			if end == -1 {
-				return nil, 0, fmt.Errorf("unclosed backtick identifier")
+				return nil, nil, 0, fmt.Errorf("unclosed backtick identifier")
 			}
 			part = current[1 : end+1]
 			consumed = end + 2
@@ -379,53 +833,93 @@ func parseIdentifierSequence(s string) ([]string, int, error) {
 				return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '_' && r != '-'
 			})
 			if end == -1 {
-				part = current
-				consumed = len(current)
-			} else {
-				part = current[:end]
-				consumed = end
+				end = len(current)
+			}
+			// dashes are allowed in project IDs, but "--" starts a comment
+			if comment := strings.Index(current[:end], "--"); comment >= 0 {
+				end = comment
 			}
+			part = current[:end]
+			consumed = end
 		} else {
 			break
 		}
 
-		if current[0] == '`' && strings.Contains(part, ".") {
-			// This handles cases like `project.dataset.table` but not `project.dataset`.table.
-			// If the character after the quoted identifier is not a dot, we treat it as a full name.
-			if len(current) <= consumed || current[consumed] != '.' {
-				parts = append(parts, strings.Split(part, ".")...)
-				totalConsumed += consumed
-				break
-			}
-		}
-
 		parts = append(parts, strings.Split(part, ".")...)
+		segments = append(segments, part)
 		totalConsumed += consumed
 
-		if len(s) <= totalConsumed || s[totalConsumed] != '.' {
+		// the next part follows a dot, possibly after spaces and comments
+		skipped := skipSpacesAndComments(s[totalConsumed:])
+		if len(s) <= totalConsumed+skipped || s[totalConsumed+skipped] != '.' {
 			break
 		}
-		totalConsumed++
+		totalConsumed += skipped + 1
+	}
+	return parts, segments, totalConsumed, nil
+}
+
+// aliasKey returns the key of the alias name, a segment of
+// parseIdentifierSequence, in the aliases of a statement. Aliases are
+// case-insensitive, and the dots of backticked aliases are part of their
+// name: "`My.Alias`" is the alias my.alias.
+func aliasKey(name string) string {
+	return strings.ToLower(name)
+}
+
+// cteFollows returns whether s, what follows a name in a WITH clause, starts
+// with AS and a parenthesis, which make the name that of a common table
+// expression.
+func cteFollows(s string) bool {
+	s = s[skipSpacesAndComments(s):]
+	if len(s) < 2 || !strings.EqualFold(s[:2], "as") {
+		return false
+	}
+	s = s[2:]
+	if len(s) > 0 && (unicode.IsLetter(rune(s[0])) || unicode.IsNumber(rune(s[0])) || s[0] == '_') {
+		return false
+	}
+	s = s[skipSpacesAndComments(s):]
+	return strings.HasPrefix(s, "(")
+}
+
+// skipSpacesAndComments returns the length of the spaces and comments that
+// start s. An unclosed comment extends to the end of s.
+func skipSpacesAndComments(s string) int {
+	i := 0
+	for {
+		i += len(s[i:]) - len(strings.TrimLeftFunc(s[i:], unicode.IsSpace))
+		rest := s[i:]
+		switch {
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end == -1 {
+				return len(s)
+			}
+			i += end + 4
+		case strings.HasPrefix(rest, "--"), strings.HasPrefix(rest, "#"):
+			end := strings.IndexByte(rest, '\n')
+			if end == -1 {
+				return len(s)
+			}
+			i += end + 1
+		default:
+			return i
+		}
 	}
-	return parts, totalConsumed, nil
 }
 
 func formatTableID(parts []string, defaultProjectID string) (string, error) {
-	if len(parts) < 2 || len(parts) > 3 {
+	id := strings.Join(parts, ".")
+	// projects scoped to a domain, "example.com:project", have more parts
+	if (len(parts) < 2 || len(parts) > 3) && !strings.Contains(id, ":") {
 		// Not a table identifier (could be a CTE, column, etc.).
 		// Return the consumed length so the main loop can skip this identifier.
 		return "", nil
 	}
-
-	var tableID string
-	if len(parts) == 3 { // project.dataset.table
-		tableID = strings.Join(parts, ".")
-	} else { // dataset.table
-		if defaultProjectID == "" {
-			return "", fmt.Errorf("query contains table '%s' without project ID, and no default project ID is provided", strings.Join(parts, "."))
-		}
-		tableID = fmt.Sprintf("%s.%s", defaultProjectID, strings.Join(parts, "."))
+	ref, err := CanonicalTableID(id, defaultProjectID)
+	if err != nil {
+		return "", fmt.Errorf("query contains an invalid table: %w", err)
 	}
-
-	return tableID, nil
+	return ref.String(), nil
 }
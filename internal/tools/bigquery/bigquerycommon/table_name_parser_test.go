@@ -15,6 +15,9 @@
 package bigquerycommon_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"sort"
 	"strings"
 	"testing"
@@ -46,6 +49,217 @@ func TestTableParser(t *testing.T) {
 			want:             []string{"proj1.data1.tbl1"},
 			wantErr:          false,
 		},
+		{
+			name:             "script set with a variable list and a subquery",
+			sql:              "DECLARE a, b INT64;\nSET (a, b) = (SELECT AS STRUCT x, y FROM ds.t);",
+			defaultProjectID: "default-proj",
+			want:             []string{"default-proj.ds.t"},
+		},
+		{
+			name:             "script set with a subquery in an expression",
+			sql:              "DECLARE cfg STRUCT<offset INT64>; SET v = (SELECT MAX(x) FROM ds.t) + cfg.offset",
+			defaultProjectID: "default-proj",
+			want:             []string{"default-proj.ds.t"},
+		},
+		{
+			name:             "script mixing update set and script set",
+			sql:              "UPDATE ds.u SET c = 1 WHERE true; SET (a, b) = (SELECT AS STRUCT x, y FROM ds.t AS t JOIN ds.t2 USING (id)); UPDATE ds.w SET c = a WHERE d = b;",
+			defaultProjectID: "default-proj",
+			want:             []string{"default-proj.ds.t", "default-proj.ds.t2", "default-proj.ds.u", "default-proj.ds.w"},
+		},
+		{
+			name:             "script set in blocks",
+			sql:              "BEGIN SET v = (SELECT COUNT(*) FROM ds.t); IF (SELECT v > 0) THEN UPDATE ds.u SET c = (SELECT MAX(x) FROM ds.s) WHERE true; SET w = (SELECT 1 FROM ds.r); END IF; END",
+			defaultProjectID: "default-proj",
+			want:             []string{"default-proj.ds.r", "default-proj.ds.s", "default-proj.ds.t", "default-proj.ds.u"},
+		},
+		{
+			name:             "byte order mark",
+			sql:              "\uFEFFSELECT * FROM d1.a JOIN d2.b ON a.id = b.id",
+			defaultProjectID: "default-proj",
+			want:             []string{"default-proj.d1.a", "default-proj.d2.b"},
+			wantErr:          false,
+		},
+		{
+			name:             "byte order mark before a table",
+			sql:              "\uFEFFd1.a",
+			defaultProjectID: "default-proj",
+			want:             []string{},
+			wantErr:          false,
+		},
+		{
+			name:             "windows line endings",
+			sql:              "-- first comment\r\nSELECT * FROM d1.a # second comment\r\nJOIN d2.b -- third comment\r\nON a.id = b.id;\r\nDELETE FROM d3.c WHERE TRUE",
+			defaultProjectID: "default-proj",
+			want:             []string{"default-proj.d1.a", "default-proj.d2.b", "default-proj.d3.c"},
+			wantErr:          false,
+		},
+		{
+			name:             "carriage returns only",
+			sql:              "-- comment\rSELECT * FROM d1.a\r-- another comment\rJOIN d2.b ON TRUE",
+			defaultProjectID: "default-proj",
+			want:             []string{"default-proj.d1.a", "default-proj.d2.b"},
+			wantErr:          false,
+		},
+		{
+			name:             "multi-byte characters before tables",
+			sql:              "SELECT 'café', \"naïve\" AS ü FROM d1.a /* déjà vu */ JOIN d2.b ON TRUE",
+			defaultProjectID: "default-proj",
+			want:             []string{"default-proj.d1.a", "default-proj.d2.b"},
+			wantErr:          false,
+		},
+		{
+			name:             "derived tables joined with aliases",
+			sql:              "SELECT * FROM (SELECT 1 AS x) t JOIN (SELECT 2 AS y) u ON t.x = u.y",
+			defaultProjectID: "default-proj",
+			want:             []string{},
+			wantErr:          false,
+		},
+		{
+			name:             "chained derived table joins",
+			sql:              "SELECT * FROM (SELECT x FROM d1.a) t JOIN (SELECT y FROM d1.b) AS u ON t.x = u.y LEFT JOIN (SELECT z FROM d2.c) v ON u.y = v.z JOIN d2.e w ON w.k = t.x",
+			defaultProjectID: "default-proj",
+			want:             []string{"default-proj.d1.a", "default-proj.d1.b", "default-proj.d2.c", "default-proj.d2.e"},
+			wantErr:          false,
+		},
+		{
+			name:             "path through a derived table alias",
+			sql:              "SELECT * FROM (SELECT [1, 2] AS arr) t, t.arr AS a JOIN d1.b ON b.x = a",
+			defaultProjectID: "default-proj",
+			want:             []string{"default-proj.d1.b"},
+			wantErr:          false,
+		},
+		{
+			name:             "path through a table alias in a subquery",
+			sql:              "SELECT * FROM d1.a AS t WHERE EXISTS (SELECT * FROM t.items i JOIN d2.b ON b.id = i.id)",
+			defaultProjectID: "default-proj",
+			want:             []string{"default-proj.d1.a", "default-proj.d2.b"},
+			wantErr:          false,
+		},
+		{
+			name:             "column lists are not aliases",
+			sql:              "INSERT INTO d1.a (d2) SELECT * FROM d2.secret",
+			defaultProjectID: "default-proj",
+			want:             []string{"default-proj.d1.a", "default-proj.d2.secret"},
+			wantErr:          false,
+		},
+		{
+			name:             "aliases do not outlive their statement",
+			sql:              "SELECT * FROM d1.a d2; SELECT * FROM d2.secret",
+			defaultProjectID: "default-proj",
+			want:             []string{"default-proj.d1.a", "default-proj.d2.secret"},
+			wantErr:          false,
+		},
+		{
+			name:             "subquery alias named like the last segment of a table",
+			sql:              "SELECT * FROM (SELECT 1 AS x) AS orders JOIN proj.sales.orders ON TRUE",
+			defaultProjectID: "default-proj",
+			want:             []string{"proj.sales.orders"},
+		},
+		{
+			name:             "table alias named like the last segment of another table",
+			sql:              "SELECT * FROM proj.sales.orders AS orders, `proj.archive.orders` JOIN d1.orders o ON TRUE",
+			defaultProjectID: "default-proj",
+			want:             []string{"default-proj.d1.orders", "proj.archive.orders", "proj.sales.orders"},
+		},
+		{
+			name:             "alias named like a table in a later subquery",
+			sql:              "SELECT * FROM (SELECT * FROM d1.a) AS orders WHERE EXISTS (SELECT 1 FROM d2.orders)",
+			defaultProjectID: "default-proj",
+			want:             []string{"default-proj.d1.a", "default-proj.d2.orders"},
+		},
+		{
+			name:             "common table expression named like the last segment of a table",
+			sql:              "WITH orders AS (SELECT 1) SELECT * FROM orders JOIN sales.orders ON TRUE",
+			defaultProjectID: "default-proj",
+			want:             []string{"default-proj.sales.orders"},
+		},
+		{
+			name:             "common table expression named like the dataset of a table",
+			sql:              "WITH d1 AS (SELECT 1) SELECT * FROM d1.t",
+			defaultProjectID: "default-proj",
+			want:             []string{"default-proj.d1.t"},
+		},
+		{
+			name:             "common table expression named like the dataset of a qualified table",
+			sql:              "WITH sales AS (SELECT 1) SELECT * FROM sales JOIN proj.sales.orders ON TRUE",
+			defaultProjectID: "default-proj",
+			want:             []string{"proj.sales.orders"},
+		},
+		{
+			name:             "subquery alias named like the dataset of a table",
+			sql:              "SELECT * FROM (SELECT 1 AS x) AS sales JOIN proj.sales.orders ON TRUE",
+			defaultProjectID: "default-proj",
+			want:             []string{"proj.sales.orders"},
+		},
+		{
+			name:             "backticked alias with a space",
+			sql:              "SELECT * FROM d1.a AS `my alias`, `my alias`.items JOIN d2.b ON TRUE",
+			defaultProjectID: "default-proj",
+			want:             []string{"default-proj.d1.a", "default-proj.d2.b"},
+		},
+		{
+			name:             "backticked alias with a dot",
+			sql:              "SELECT * FROM d1.a AS `My.Alias`, `my.alias`.items i, `my.alias`.`sub.items`",
+			defaultProjectID: "default-proj",
+			want:             []string{"default-proj.d1.a"},
+		},
+		{
+			name:             "backticked common table expressions",
+			sql:              "WITH `weird name` AS (SELECT * FROM d1.a), `weird.name` AS (SELECT * FROM `weird name`) SELECT * FROM `weird.name` JOIN d2.b ON TRUE",
+			defaultProjectID: "default-proj",
+			want:             []string{"default-proj.d1.a", "default-proj.d2.b"},
+		},
+		{
+			name:             "backticked named window",
+			sql:              "SELECT SUM(x) OVER `my.window` FROM d1.a WINDOW `my.window` AS (PARTITION BY y)",
+			defaultProjectID: "default-proj",
+			want:             []string{"default-proj.d1.a"},
+		},
+		{
+			name:             "common table expressions do not outlive their statement",
+			sql:              "WITH `d2.secret` AS (SELECT 1) SELECT * FROM `d2.secret`; SELECT * FROM `d2.secret`",
+			defaultProjectID: "default-proj",
+			want:             []string{"default-proj.d2.secret"},
+		},
+		{
+			name:             "common table expression named like a dataset",
+			sql:              "WITH sales AS (SELECT 1) SELECT * FROM sales.orders",
+			defaultProjectID: "proj",
+			want:             []string{"proj.sales.orders"},
+		},
+		{
+			name:             "common table expression named like a dataset in a subquery",
+			sql:              "WITH sales AS (SELECT 1) SELECT * FROM (SELECT * FROM sales.orders)",
+			defaultProjectID: "proj",
+			want:             []string{"proj.sales.orders"},
+		},
+		{
+			name:             "backticked common table expression is matched as a whole",
+			sql:              "WITH `d1.t` AS (SELECT 1) SELECT * FROM `d1.t` JOIN d1.t ON TRUE JOIN `d1`.t.x ON TRUE",
+			defaultProjectID: "proj",
+			want:             []string{"d1.t.x", "proj.d1.t"},
+		},
+		{
+			name:             "create table as a subquery is not a common table expression",
+			sql:              "CREATE TABLE `d1.a` AS (SELECT * FROM d2.b)",
+			defaultProjectID: "default-proj",
+			want:             []string{"default-proj.d1.a", "default-proj.d2.b"},
+		},
+		{
+			name:             "named query parameters",
+			sql:              "SELECT * FROM proj1.data1.tbl1 JOIN data2.tbl2 ON id = @id WHERE name IN UNNEST(@names)",
+			defaultProjectID: "default-proj",
+			want:             []string{"proj1.data1.tbl1", "default-proj.data2.tbl2"},
+			wantErr:          false,
+		},
+		{
+			name:             "positional query parameters",
+			sql:              "SELECT * FROM proj1.data1.tbl1 JOIN data2.tbl2 ON id = ? WHERE name IN UNNEST(?)",
+			defaultProjectID: "default-proj",
+			want:             []string{"proj1.data1.tbl1", "default-proj.data2.tbl2"},
+			wantErr:          false,
+		},
 		{
 			name:             "multiple fully qualified tables",
 			sql:              "SELECT * FROM `proj1.data1`.`tbl1` JOIN proj2.`data2.tbl2` ON id",
@@ -471,6 +685,74 @@ func TestTableParser(t *testing.T) {
 			wantErr:          true,
 			wantErrMsg:       "unanalyzable statements like 'CREATE FUNCTION' are not allowed",
 		},
+		{
+			name:             "sql temp function",
+			sql:              "CREATE TEMP FUNCTION f(x FLOAT64) RETURNS FLOAT64 AS ((SELECT MAX(y) FROM proj.data.tbl1) * x); SELECT f(1.0)",
+			defaultProjectID: "default-proj",
+			want:             []string{"proj.data.tbl1"},
+			wantErr:          false,
+		},
+		{
+			name:             "sql temp function with explicit language",
+			sql:              "CREATE TEMPORARY FUNCTION IF NOT EXISTS f(x FLOAT64) RETURNS FLOAT64 LANGUAGE sql AS (x)",
+			defaultProjectID: "default-proj",
+			want:             []string{},
+			wantErr:          false,
+		},
+		{
+			name:             "javascript temp function",
+			sql:              `CREATE TEMP FUNCTION f(x FLOAT64) RETURNS FLOAT64 LANGUAGE js AS "return x;"; SELECT f(1.0)`,
+			defaultProjectID: "default-proj",
+			want:             nil,
+			wantErr:          true,
+			wantErrMsg:       "JavaScript UDF 'f' cannot be analyzed when dataset restrictions are in place",
+		},
+		{
+			name:             "comment between path segments",
+			sql:              "SELECT * FROM proj/*c*/.ds.t",
+			defaultProjectID: "default-proj",
+			want:             []string{"proj.ds.t"},
+		},
+		{
+			name:             "spaces and comments around the dots of a path",
+			sql:              "SELECT * FROM proj /* c */ . /* d */ ds\n.t WHERE x = 1",
+			defaultProjectID: "default-proj",
+			want:             []string{"proj.ds.t"},
+		},
+		{
+			name:             "single-line comments between path segments",
+			sql:              "SELECT * FROM proj-- c\n.ds # d\n.`t` JOIN other-- e.f\n.u ON TRUE",
+			defaultProjectID: "default-proj",
+			want:             []string{"proj.ds.t", "default-proj.other.u"},
+		},
+		{
+			name:             "comment after a backticked segment with a dot",
+			sql:              "SELECT * FROM `proj.ds`/* c */.t",
+			defaultProjectID: "default-proj",
+			want:             []string{"proj.ds.t"},
+		},
+		{
+			name:             "comments after a path are not segments",
+			sql:              "SELECT * FROM ds.t /* .other.x */ JOIN ds.u -- .y.z\nON TRUE",
+			defaultProjectID: "default-proj",
+			want:             []string{"default-proj.ds.t", "default-proj.ds.u"},
+		},
+		{
+			name:             "javascript temp function in script",
+			sql:              "BEGIN\n  CREATE OR REPLACE TEMP FUNCTION `my_fn`(x STRING) RETURNS STRING\n  LANGUAGE js AS r'''return x;''';\n  SELECT my_fn(s) FROM proj.data.tbl1;\nEND;",
+			defaultProjectID: "default-proj",
+			want:             nil,
+			wantErr:          true,
+			wantErrMsg:       "JavaScript UDF 'my_fn' cannot be analyzed",
+		},
+		{
+			name:             "external temp function",
+			sql:              "CREATE TEMP FUNCTION f(x INT64) RETURNS INT64 LANGUAGE python AS r'''def f(x): return x'''",
+			defaultProjectID: "default-proj",
+			want:             nil,
+			wantErr:          true,
+			wantErrMsg:       "UDF 'f' in language 'python' cannot be analyzed",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -494,3 +776,288 @@ func TestTableParser(t *testing.T) {
 		})
 	}
 }
+
+func TestConnectionParser(t *testing.T) {
+	tcs := []struct {
+		name       string
+		sql        string
+		want       []string
+		wantErrMsg string
+	}{
+		{
+			name: "external query",
+			sql:  `SELECT * FROM EXTERNAL_QUERY("other-project.US.my_conn", "SELECT * FROM customers")`,
+			want: []string{"other-project.us.my_conn"},
+		},
+		{
+			name: "external query with resource name",
+			sql:  "SELECT * FROM external_query('projects/p/locations/eu/connections/c', '''SELECT 1''')",
+			want: []string{"p.eu.c"},
+		},
+		{
+			name: "external query with default project",
+			sql:  "SELECT * FROM EXTERNAL_QUERY('us.my_conn', 'SELECT 1')",
+			want: []string{"default-proj.us.my_conn"},
+		},
+		{
+			name: "create external table with connection",
+			sql:  "CREATE EXTERNAL TABLE proj.data.ext WITH CONNECTION `proj.us.lake_conn` OPTIONS (format = 'PARQUET', uris = ['gs://bucket/*'])",
+			want: []string{"proj.us.lake_conn"},
+		},
+		{
+			name: "default connection",
+			sql:  "CREATE EXTERNAL TABLE proj.data.ext WITH CONNECTION DEFAULT OPTIONS (format = 'PARQUET', uris = ['gs://bucket/*'])",
+			want: []string{bigquerycommon.DefaultConnection},
+		},
+		{
+			name: "common table expression named connection",
+			sql:  "WITH connection AS (SELECT 1 AS x) SELECT x FROM connection",
+			want: []string{},
+		},
+		{
+			name: "connections in comments and strings",
+			sql:  "SELECT 'EXTERNAL_QUERY(\"p.us.c\", \"\")' -- WITH CONNECTION p.us.c\nFROM proj.data.tbl",
+			want: []string{},
+		},
+		{
+			name:       "external query without literal connection",
+			sql:        "SELECT * FROM EXTERNAL_QUERY(@conn, 'SELECT 1')",
+			wantErrMsg: "EXTERNAL_QUERY is only allowed with a string literal as its connection",
+		},
+		{
+			name:       "invalid connection",
+			sql:        "SELECT * FROM EXTERNAL_QUERY('my_conn', 'SELECT 1')",
+			wantErrMsg: `invalid connection "my_conn"`,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := bigquerycommon.ConnectionParser(tc.sql, "default-proj")
+			if tc.wantErrMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErrMsg) {
+					t.Fatalf("ConnectionParser() error = %v, want err containing %q", err, tc.wantErrMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ConnectionParser() unexpected error: %v", err)
+			}
+			sort.Strings(got)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ConnectionParser() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStorageURIParser(t *testing.T) {
+	tcs := []struct {
+		name       string
+		sql        string
+		want       []string
+		wantErrMsg string
+	}{
+		{
+			name: "external table with wildcard uri",
+			sql:  "CREATE EXTERNAL TABLE ds.ext OPTIONS(format='PARQUET', uris=['gs://bucket/path/*'])",
+			want: []string{"gs://bucket/path/*"},
+		},
+		{
+			name: "multiple uris",
+			sql:  "CREATE OR REPLACE EXTERNAL TABLE `proj.ds.ext`\nOPTIONS (\n  format = 'CSV',\n  uris = ['gs://a/one.csv', \"gs://b/two/*.csv\", r'gs://c/three.csv']\n)",
+			want: []string{"gs://a/one.csv", "gs://b/two/*.csv", "gs://c/three.csv"},
+		},
+		{
+			name: "external table with connection and partition columns",
+			sql:  "CREATE EXTERNAL TABLE ds.ext WITH PARTITION COLUMNS (dt DATE) WITH CONNECTION `us.lake` OPTIONS (format = 'PARQUET', hive_partition_uri_prefix = 'gs://bucket/root', uris = ['gs://bucket/root/*'])",
+			want: []string{"gs://bucket/root/*"},
+		},
+		{
+			name: "load data from files",
+			sql:  "LOAD DATA INTO ds.t FROM FILES (format = 'AVRO', uris = ['gs://bucket/a/*.avro', 'gs://bucket/b/*.avro'])",
+			want: []string{"gs://bucket/a/*.avro", "gs://bucket/b/*.avro"},
+		},
+		{
+			name: "uris of several statements",
+			sql:  "CREATE EXTERNAL TABLE ds.a OPTIONS (uris = ['gs://x/*']); CREATE EXTERNAL TABLE ds.b OPTIONS (uris = ['gs://y/*', 'gs://x/*'])",
+			want: []string{"gs://x/*", "gs://y/*"},
+		},
+		{
+			name: "column named uris",
+			sql:  "SELECT uris FROM ds.t WHERE ARRAY_LENGTH(uris) > 0",
+			want: []string{},
+		},
+		{
+			name: "uris in comments and strings",
+			sql:  "SELECT \"OPTIONS(uris=['gs://x/*'])\" -- OPTIONS(uris=['gs://y/*'])\nFROM ds.t",
+			want: []string{},
+		},
+		{
+			name:       "uris from a query parameter",
+			sql:        "CREATE EXTERNAL TABLE ds.ext OPTIONS (format = 'CSV', uris = @uris)",
+			wantErrMsg: "the uris option is only allowed with an array of string literals",
+		},
+		{
+			name:       "uri with escape sequences",
+			sql:        `CREATE EXTERNAL TABLE ds.ext OPTIONS (uris = ['gs://bucket/\x2a'])`,
+			wantErrMsg: "the uris option is only allowed with an array of string literals",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := bigquerycommon.StorageURIParser(tc.sql, "default-proj")
+			if tc.wantErrMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErrMsg) {
+					t.Fatalf("StorageURIParser() error = %v, want err containing %q", err, tc.wantErrMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("StorageURIParser() unexpected error: %v", err)
+			}
+			sort.Strings(got)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("StorageURIParser() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	// the options of external tables do not hide their names
+	tables, err := bigquerycommon.TableParser("CREATE EXTERNAL TABLE ds.ext OPTIONS (uris = ['gs://b/*']); LOAD DATA INTO ds.t FROM FILES (uris = ['gs://b/*'])", "p")
+	if err != nil {
+		t.Fatalf("TableParser() unexpected error: %v", err)
+	}
+	sort.Strings(tables)
+	if diff := cmp.Diff([]string{"p.ds.ext", "p.ds.t"}, tables); diff != "" {
+		t.Errorf("TableParser() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestUnresolvedTableParser(t *testing.T) {
+	tcs := []struct {
+		name string
+		sql  string
+		want []string
+	}{
+		{
+			name: "qualified tables",
+			sql:  "SELECT * FROM ds.t JOIN `p.ds.u` USING (id)",
+			want: []string{},
+		},
+		{
+			name: "common table expression",
+			sql:  "WITH recent AS (SELECT * FROM ds.t) SELECT * FROM recent r JOIN ds.u ON r.id = u.id",
+			want: []string{"recent"},
+		},
+		{
+			name: "backticked common table expressions",
+			sql:  "WITH `weird name` AS (SELECT 1), `weird.name` AS (SELECT 2) SELECT * FROM `weird name` JOIN `weird.name` ON TRUE",
+			want: []string{"weird name", "weird.name"},
+		},
+		{
+			name: "temporary table",
+			sql:  "CREATE TEMP TABLE staged AS SELECT 1 AS x; INSERT INTO staged (x) VALUES (2); UPDATE staged SET x = 3 WHERE true",
+			want: []string{"staged"},
+		},
+		{
+			name: "table functions and unnest",
+			sql:  "SELECT * FROM EXTERNAL_QUERY('p.us.conn', 'SELECT 1') JOIN UNNEST([1, 2]) AS n ON true",
+			want: []string{},
+		},
+		{
+			name: "load data from files",
+			sql:  "LOAD DATA INTO ds.t FROM FILES (format = 'AVRO', uris = ['gs://bucket/*.avro'])",
+			want: []string{},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := bigquerycommon.UnresolvedTableParser(tc.sql, "default-proj")
+			if err != nil {
+				t.Fatalf("UnresolvedTableParser() unexpected error: %v", err)
+			}
+			sort.Strings(got)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("UnresolvedTableParser() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// cancelAfterContext is a context that is canceled once its error has been
+// checked after times.
+type cancelAfterContext struct {
+	context.Context
+	after  int
+	checks int
+}
+
+func (c *cancelAfterContext) Err() error {
+	c.checks++
+	if c.checks > c.after {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestParseSQL(t *testing.T) {
+	sql := "SELECT * FROM ds.t JOIN `p.ds.u` USING (id) WHERE x IN (SELECT x FROM recent); " +
+		"CREATE EXTERNAL TABLE ds.e WITH CONNECTION `p.us.conn` OPTIONS (format = 'CSV', uris = ['gs://bucket/*.csv'])"
+	got, err := bigquerycommon.ParseSQL(context.Background(), sql, bigquerycommon.ParseOptions{DefaultProjectID: "default-proj"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for _, refs := range [][]string{got.TableIDs, got.ConnectionIDs, got.StorageURIs, got.UnresolvedTables} {
+		sort.Strings(refs)
+	}
+	want := bigquerycommon.SQLReferences{
+		TableIDs:         []string{"default-proj.ds.e", "default-proj.ds.t", "p.ds.u"},
+		ConnectionIDs:    []string{"p.us.conn"},
+		StorageURIs:      []string{"gs://bucket/*.csv"},
+		UnresolvedTables: []string{"recent"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ParseSQL() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseSQLContext(t *testing.T) {
+	// a large query, with long statements and deeply nested subqueries
+	var b strings.Builder
+	for i := range 200 {
+		fmt.Fprintf(&b, "SELECT '%s' FROM ds.t%d WHERE x IN %sSELECT 1%s;\n", strings.Repeat("x", 100), i, strings.Repeat("(SELECT y FROM ", 20), strings.Repeat(")", 20))
+	}
+	sql := b.String()
+
+	// the parse of the whole query checks the context many times
+	ctx := &cancelAfterContext{Context: context.Background(), after: 1 << 30}
+	refs, err := bigquerycommon.ParseSQL(ctx, sql, bigquerycommon.ParseOptions{DefaultProjectID: "p"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(refs.TableIDs) != 200 {
+		t.Fatalf("got %d tables, want 200", len(refs.TableIDs))
+	}
+	if ctx.checks < 100 {
+		t.Fatalf("expected the context to be checked during the parse, got %d checks", ctx.checks)
+	}
+
+	for _, after := range []int{0, 10, ctx.checks / 2} {
+		t.Run(fmt.Sprintf("canceled after %d checks", after), func(t *testing.T) {
+			ctx := &cancelAfterContext{Context: context.Background(), after: after}
+			_, err := bigquerycommon.ParseSQL(ctx, sql, bigquerycommon.ParseOptions{DefaultProjectID: "p"})
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("got error %v, want context.Canceled", err)
+			}
+			if ctx.checks != after+1 {
+				t.Fatalf("expected the parse to stop at the first check of the canceled context, got %d checks", ctx.checks)
+			}
+			if tbErr := bigquerycommon.ParseTimeoutError(err); tbErr == nil {
+				t.Fatalf("expected a timeout error for %v", err)
+			}
+		})
+	}
+	if tbErr := bigquerycommon.ParseTimeoutError(errors.New("unclosed subquery parenthesis")); tbErr != nil {
+		t.Fatalf("expected no timeout error for a parse error, got %s", tbErr)
+	}
+}
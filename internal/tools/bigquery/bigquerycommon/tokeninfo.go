@@ -0,0 +1,156 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTokenInfoURL is Google's OAuth2 tokeninfo endpoint.
+const DefaultTokenInfoURL = "https://oauth2.googleapis.com/tokeninfo"
+
+// CloudPlatformScope is the OAuth2 scope required by the Gemini Data Analytics API.
+const CloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// ErrInvalidToken is returned by TokenValidator.Validate when a token is
+// rejected by the tokeninfo endpoint, has expired, or lacks a required scope.
+var ErrInvalidToken = errors.New("invalid access token")
+
+// TokenValidator checks OAuth2 access tokens against the tokeninfo endpoint.
+// Successfully validated tokens are cached by their SHA-256 hash until they
+// expire, so each token is only looked up once.
+type TokenValidator struct {
+	tokenInfoURL string
+	client       *http.Client
+	now          func() time.Time
+
+	mu      sync.Mutex
+	expires map[string]time.Time
+}
+
+// NewTokenValidator returns a TokenValidator that uses the given tokeninfo endpoint.
+func NewTokenValidator(tokenInfoURL string) *TokenValidator {
+	return &TokenValidator{
+		tokenInfoURL: tokenInfoURL,
+		client:       http.DefaultClient,
+		now:          time.Now,
+		expires:      make(map[string]time.Time),
+	}
+}
+
+type tokenInfoResponse struct {
+	Scope            string `json:"scope"`
+	Exp              string `json:"exp"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Validate verifies that token has not expired and has been granted scope.
+// Errors caused by the token itself wrap ErrInvalidToken.
+func (v *TokenValidator) Validate(ctx context.Context, token, scope string) error {
+	key := tokenHash(token)
+	now := v.now()
+
+	v.mu.Lock()
+	exp, ok := v.expires[key]
+	v.mu.Unlock()
+	if ok {
+		if now.Before(exp) {
+			return nil
+		}
+		v.mu.Lock()
+		delete(v.expires, key)
+		v.mu.Unlock()
+		return fmt.Errorf("%w: token expired at %s", ErrInvalidToken, exp.UTC().Format(time.RFC3339))
+	}
+
+	info, err := v.lookup(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	secs, err := strconv.ParseInt(info.Exp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid expiry %q in tokeninfo response: %w", info.Exp, err)
+	}
+	exp = time.Unix(secs, 0)
+	if !now.Before(exp) {
+		return fmt.Errorf("%w: token expired at %s", ErrInvalidToken, exp.UTC().Format(time.RFC3339))
+	}
+	if !slices.Contains(strings.Fields(info.Scope), scope) {
+		return fmt.Errorf("%w: token is missing scope %q (granted scopes: %q)", ErrInvalidToken, scope, info.Scope)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for k, e := range v.expires {
+		if !now.Before(e) {
+			delete(v.expires, k)
+		}
+	}
+	v.expires[key] = exp
+	return nil
+}
+
+func (v *TokenValidator) lookup(ctx context.Context, token string) (*tokenInfoResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.tokenInfoURL, strings.NewReader(url.Values{"access_token": {token}}.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tokeninfo request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call tokeninfo endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tokeninfo response: %w", err)
+	}
+
+	var info tokenInfoResponse
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("failed to decode tokeninfo response (status %d): %w", resp.StatusCode, err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized:
+		if info.ErrorDescription == "" {
+			info.ErrorDescription = "token was rejected"
+		}
+		return nil, fmt.Errorf("%w: %s", ErrInvalidToken, info.ErrorDescription)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("tokeninfo endpoint returned status %d: %s", resp.StatusCode, string(body))
+	}
+	return &info, nil
+}
+
+func tokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
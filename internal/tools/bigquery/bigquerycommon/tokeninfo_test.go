@@ -0,0 +1,145 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTokenInfoStub serves tokeninfo responses for a fixed set of tokens and
+// counts the lookups it receives.
+func newTokenInfoStub(t *testing.T, tokens map[string]string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("failed to parse tokeninfo request: %s", err)
+		}
+		resp, ok := tokens[r.PostForm.Get("access_token")]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error": "invalid_token", "error_description": "Invalid Value"}`)
+			return
+		}
+		fmt.Fprint(w, resp)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestTokenValidator(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tokens := map[string]string{
+		"good":    fmt.Sprintf(`{"scope": "openid %s", "exp": "%d"}`, CloudPlatformScope, now.Add(time.Hour).Unix()),
+		"expired": fmt.Sprintf(`{"scope": "%s", "exp": "%d"}`, CloudPlatformScope, now.Add(-time.Minute).Unix()),
+		"scoped":  fmt.Sprintf(`{"scope": "https://www.googleapis.com/auth/bigquery", "exp": "%d"}`, now.Add(time.Hour).Unix()),
+	}
+	server, _ := newTokenInfoStub(t, tokens)
+
+	tcs := []struct {
+		desc    string
+		token   string
+		wantMsg string
+	}{
+		{desc: "valid token", token: "good"},
+		{desc: "expired token", token: "expired", wantMsg: "token expired at 2023-11-14T22:12:20Z"},
+		{desc: "missing scope", token: "scoped", wantMsg: fmt.Sprintf("token is missing scope %q", CloudPlatformScope)},
+		{desc: "rejected token", token: "unknown", wantMsg: "Invalid Value"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			v := NewTokenValidator(server.URL)
+			v.now = func() time.Time { return now }
+			err := v.Validate(context.Background(), tc.token, CloudPlatformScope)
+			if tc.wantMsg == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("expected ErrInvalidToken, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tc.wantMsg) {
+				t.Fatalf("expected error to contain %q, got %q", tc.wantMsg, err)
+			}
+		})
+	}
+}
+
+func TestTokenValidatorCache(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	exp := now.Add(10 * time.Minute)
+	server, calls := newTokenInfoStub(t, map[string]string{
+		"a": fmt.Sprintf(`{"scope": "%s", "exp": "%d"}`, CloudPlatformScope, exp.Unix()),
+		"b": fmt.Sprintf(`{"scope": "%s", "exp": "%d"}`, CloudPlatformScope, exp.Unix()),
+	})
+	v := NewTokenValidator(server.URL)
+	v.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if err := v.Validate(context.Background(), "a", CloudPlatformScope); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected a single tokeninfo lookup for a cached token, got %d", got)
+	}
+	if _, ok := v.expires["a"]; ok {
+		t.Fatalf("tokens must be cached by hash, not in plain text")
+	}
+
+	if err := v.Validate(context.Background(), "b", CloudPlatformScope); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("expected a different token to be looked up, got %d lookups", got)
+	}
+
+	// Once the token's lifetime has passed, the cached entry must not be trusted.
+	now = exp
+	err := v.Validate(context.Background(), "a", CloudPlatformScope)
+	if !errors.Is(err, ErrInvalidToken) || !strings.Contains(err.Error(), "token expired at") {
+		t.Fatalf("expected an expiry error for a cached token past its lifetime, got %v", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("expected the expiry to be detected from the cache, got %d lookups", got)
+	}
+}
+
+func TestTokenValidatorServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	err := NewTokenValidator(server.URL).Validate(context.Background(), "a", CloudPlatformScope)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("an unavailable tokeninfo endpoint must not be reported as an invalid token: %s", err)
+	}
+}
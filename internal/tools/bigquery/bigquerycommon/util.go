@@ -17,16 +17,32 @@ package bigquerycommon
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	bigqueryapi "cloud.google.com/go/bigquery"
+	bigqueryds "github.com/googleapis/genai-toolbox/internal/sources/bigquery"
+	"github.com/googleapis/genai-toolbox/internal/telemetry"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	"github.com/googleapis/genai-toolbox/internal/util"
 	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	bigqueryrestapi "google.golang.org/api/bigquery/v2"
 )
 
+// DryRunSpanName is the name of the span of dry runs, which is a child of the
+// span of the invocation that validates its query.
+const DryRunSpanName = "toolbox/bigquery/dry_run"
+
 // DryRunQuery performs a dry run of the SQL query to validate it and get metadata.
-func DryRunQuery(ctx context.Context, restService *bigqueryrestapi.Service, projectID string, location string, sql string, params []*bigqueryrestapi.QueryParameter, connProps []*bigqueryapi.ConnectionProperty) (*bigqueryrestapi.Job, error) {
+// The job carries the options of jobOpts, like the queries that are run after
+// it. params are the query parameters of sql, of mode, which is
+// ignored for queries without parameters.
+func DryRunQuery(ctx context.Context, restService *bigqueryrestapi.Service, projectID string, location string, sql string, params []*bigqueryrestapi.QueryParameter, mode ParameterMode, connProps []*bigqueryapi.ConnectionProperty, jobOpts JobOptions) (*bigqueryrestapi.Job, error) {
 	useLegacySql := false
 
 	restConnProps := make([]*bigqueryrestapi.ConnectionProperty, len(connProps))
@@ -49,14 +65,107 @@ func DryRunQuery(ctx context.Context, restService *bigqueryrestapi.Service, proj
 			},
 		},
 	}
+	if len(params) > 0 {
+		jobToInsert.Configuration.Query.ParameterMode = string(mode)
+	}
+	jobOpts.ApplyToJob(jobToInsert.Configuration)
 
+	ctx, span := telemetry.Tracer().Start(ctx, DryRunSpanName, trace.WithAttributes(
+		attribute.String("project", projectID),
+		attribute.String("location", location),
+	))
+	defer span.End()
 	insertResponse, err := restService.Jobs.Insert(projectID, jobToInsert).Context(ctx).Do()
 	if err != nil {
+		span.SetStatus(codes.Error, "dry run failed")
 		return nil, fmt.Errorf("failed to insert dry run job: %w", err)
 	}
+	if stats := insertResponse.Statistics; stats != nil && stats.Query != nil {
+		span.SetAttributes(
+			attribute.String("statement_type", stats.Query.StatementType),
+			attribute.Int64("total_bytes_processed", stats.TotalBytesProcessed),
+		)
+	}
 	return insertResponse, nil
 }
 
+// ReferencedTables returns the sorted IDs, "project.dataset.table", of the
+// tables that the dry run job reads, and of the table a DDL statement targets.
+func ReferencedTables(dryRunJob *bigqueryrestapi.Job) []string {
+	if dryRunJob == nil || dryRunJob.Statistics == nil || dryRunJob.Statistics.Query == nil {
+		return nil
+	}
+	queryStats := dryRunJob.Statistics.Query
+	tableRefs := slices.Clone(queryStats.ReferencedTables)
+	if queryStats.DdlTargetTable != nil {
+		tableRefs = append(tableRefs, queryStats.DdlTargetTable)
+	}
+	if queryStats.DdlDestinationTable != nil {
+		tableRefs = append(tableRefs, queryStats.DdlDestinationTable)
+	}
+	tableIDs := make([]string, 0, len(tableRefs))
+	for _, tableRef := range tableRefs {
+		tableIDs = append(tableIDs, fmt.Sprintf("%s.%s.%s", tableRef.ProjectId, tableRef.DatasetId, tableRef.TableId))
+	}
+	slices.Sort(tableIDs)
+	return slices.Compact(tableIDs)
+}
+
+// ReferencedConnections returns the sorted IDs, normalized by
+// bigqueryds.NormalizeConnectionID, of the connections of the external tables
+// defined by the configuration of the dry run job. IDs that cannot be
+// normalized are returned as is.
+func ReferencedConnections(dryRunJob *bigqueryrestapi.Job, defaultProjectID string) []string {
+	if dryRunJob == nil || dryRunJob.Configuration == nil || dryRunJob.Configuration.Query == nil {
+		return nil
+	}
+	var connectionIDs []string
+	for _, def := range dryRunJob.Configuration.Query.TableDefinitions {
+		if def.ConnectionId == "" {
+			continue
+		}
+		id, err := bigqueryds.NormalizeConnectionID(def.ConnectionId, defaultProjectID, "")
+		if err != nil {
+			id = def.ConnectionId
+		}
+		connectionIDs = append(connectionIDs, id)
+	}
+	slices.Sort(connectionIDs)
+	return slices.Compact(connectionIDs)
+}
+
+// ReferencedStorageURIs returns the sorted URIs of the external data of the
+// tables defined by the configuration of the dry run job.
+func ReferencedStorageURIs(dryRunJob *bigqueryrestapi.Job) []string {
+	if dryRunJob == nil || dryRunJob.Configuration == nil || dryRunJob.Configuration.Query == nil {
+		return nil
+	}
+	var uris []string
+	for _, def := range dryRunJob.Configuration.Query.TableDefinitions {
+		uris = append(uris, def.SourceUris...)
+	}
+	slices.Sort(uris)
+	return slices.Compact(uris)
+}
+
+// QueryPlan returns the plan of running sql, described by its dry run job:
+// the statement, its type, the bytes it would process and the tables it
+// references.
+func QueryPlan(sql string, dryRunJob *bigqueryrestapi.Job) map[string]any {
+	plan := map[string]any{
+		"statement":        sql,
+		"referencedTables": ReferencedTables(dryRunJob),
+	}
+	if dryRunJob == nil || dryRunJob.Statistics == nil {
+		return plan
+	}
+	plan["totalBytesProcessed"] = dryRunJob.Statistics.TotalBytesProcessed
+	if queryStats := dryRunJob.Statistics.Query; queryStats != nil {
+		plan["statementType"] = queryStats.StatementType
+	}
+	return plan
+}
+
 // BQTypeStringFromToolType converts a tool parameter type string to a BigQuery standard SQL type string.
 func BQTypeStringFromToolType(toolType string) (string, error) {
 	switch toolType {
@@ -68,53 +177,428 @@ func BQTypeStringFromToolType(toolType string) (string, error) {
 		return "FLOAT64", nil
 	case "boolean":
 		return "BOOL", nil
+	case parameters.TypeTimestamp:
+		return "TIMESTAMP", nil
+	case parameters.TypeDate:
+		return "DATE", nil
 	default:
 		return "", fmt.Errorf("unsupported tool parameter type for BigQuery: %s", toolType)
 	}
 }
 
-// InitializeDatasetParameters generates project and dataset tool parameters based on allowedDatasets.
-func InitializeDatasetParameters(
-	allowedDatasets []string,
-	defaultProjectID string,
-	projectKey, datasetKey string,
-	projectDescription, datasetDescription string,
-) (projectParam, datasetParam parameters.Parameter) {
+// bqTimestampLayout is the canonical format of BigQuery TIMESTAMP parameter values.
+const bqTimestampLayout = "2006-01-02 15:04:05.999999-07:00"
+
+// QueryParameterValueString formats a parsed parameter value as the string
+// value of a BigQuery REST API query parameter.
+func QueryParameterValueString(value any) string {
+	if t, ok := value.(time.Time); ok {
+		return t.Format(bqTimestampLayout)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// QueryParameterValue returns the value of a BigQuery client query parameter
+// for a parsed parameter value of the given tool type. Dates are normalized to
+// "YYYY-MM-DD" strings by the parameters package, so they are explicitly typed
+// as DATE rather than inferred as STRING. Other values are returned as is.
+func QueryParameterValue(toolType string, value any) any {
+	if toolType != parameters.TypeDate {
+		return value
+	}
+	dateType := bigqueryapi.StandardSQLDataType{TypeKind: "DATE"}
+	switch v := value.(type) {
+	case string:
+		return &bigqueryapi.QueryParameterValue{Type: dateType, Value: v}
+	case []string:
+		arrayValue := make([]bigqueryapi.QueryParameterValue, len(v))
+		for i, d := range v {
+			arrayValue[i] = bigqueryapi.QueryParameterValue{Value: d}
+		}
+		return &bigqueryapi.QueryParameterValue{
+			Type:       bigqueryapi.StandardSQLDataType{TypeKind: "ARRAY", ArrayElementType: &dateType},
+			ArrayValue: arrayValue,
+		}
+	default:
+		return value
+	}
+}
+
+// Descriptions of the dataset parameter when datasets are restricted, expanded
+// with ExpandDescription.
+const (
+	singleDatasetDescription   = "{{ .Description }} Must be `{{ .DatasetID }}`."
+	allowedDatasetsDescription = "{{ .Description }} Must be one of the allowed datasets: {{ join .Datasets \"; \" }}."
+)
+
+// Canonical names of the parameters of BigQuery tools that identify a
+// project, a dataset and a table, so that agents using several tools see the
+// same names.
+const (
+	ProjectKey = "project"
+	DatasetKey = "dataset"
+	TableKey   = "table"
+)
+
+// Canonical descriptions of the project, dataset and table parameters of
+// tools that read datasets and tables. Tools that need to say more about them
+// are configured with parameterDescriptions.
+const (
+	ProjectDescription = "The Google Cloud project ID containing the dataset."
+	DatasetDescription = "The ID of the dataset, without its project."
+	TableDescription   = "The ID of the table, without its project and dataset."
+)
+
+// InitializeDatasetParameters generates the ProjectKey and DatasetKey tool
+// parameters based on allowedDatasets, with the canonical descriptions unless
+// descriptions overrides them. When datasets are restricted, the project
+// parameter only accepts the projects of the allowed datasets and the default
+// project.
+func InitializeDatasetParameters(allowedDatasets []string, defaultProjectID string, descriptions tools.ParameterDescriptions) (projectParam, datasetParam parameters.Parameter, err error) {
+	projectDescription := descriptions.Describe(ProjectKey, ProjectDescription)
+	datasetDescription := descriptions.Describe(DatasetKey, DatasetDescription)
 	if len(allowedDatasets) > 0 {
 		if len(allowedDatasets) == 1 {
-			parts := strings.Split(allowedDatasets[0], ".")
-			defaultProjectID = parts[0]
-			datasetID := parts[1]
-			projectDescription += fmt.Sprintf(" Must be `%s`.", defaultProjectID)
-			datasetDescription += fmt.Sprintf(" Must be `%s`.", datasetID)
-			datasetParam = parameters.NewStringParameterWithDefault(datasetKey, datasetID, datasetDescription)
+			project, datasetID, ok := strings.Cut(allowedDatasets[0], ".")
+			if !ok {
+				return nil, nil, fmt.Errorf("invalid allowed dataset %q: must be of the form project.dataset", allowedDatasets[0])
+			}
+			defaultProjectID = project
+			datasetDescription, err = tools.ExpandDescription("", singleDatasetDescription, map[string]any{
+				"Description": datasetDescription,
+				"DatasetID":   datasetID,
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			datasetParam = parameters.WithExamples(parameters.NewStringParameterWithDefault(DatasetKey, datasetID, datasetDescription), datasetID)
+			projectParam = parameters.WithExamples(parameters.NewStringParameterWithEnumDefault(ProjectKey, defaultProjectID, projectDescription, []string{defaultProjectID}), defaultProjectID)
 		} else {
 			datasetIDsByProject := make(map[string][]string)
+			var datasetIDs []string
 			for _, ds := range allowedDatasets {
-				parts := strings.Split(ds, ".")
-				project := parts[0]
-				dataset := parts[1]
+				project, dataset, ok := strings.Cut(ds, ".")
+				if !ok {
+					return nil, nil, fmt.Errorf("invalid allowed dataset %q: must be of the form project.dataset", ds)
+				}
 				datasetIDsByProject[project] = append(datasetIDsByProject[project], fmt.Sprintf("`%s`", dataset))
+				datasetIDs = append(datasetIDs, dataset)
 			}
 
 			var datasetDescriptions, projectIDList []string
 			for project, datasets := range datasetIDsByProject {
 				sort.Strings(datasets)
-				projectIDList = append(projectIDList, fmt.Sprintf("`%s`", project))
+				projectIDList = append(projectIDList, project)
 				datasetList := strings.Join(datasets, ", ")
 				datasetDescriptions = append(datasetDescriptions, fmt.Sprintf("%s from project `%s`", datasetList, project))
 			}
+			// The default project must be accepted even if it has no allowed
+			// datasets, so that a call that omits the project parses.
+			if _, ok := datasetIDsByProject[defaultProjectID]; !ok && defaultProjectID != "" {
+				projectIDList = append(projectIDList, defaultProjectID)
+			}
 			sort.Strings(projectIDList)
 			sort.Strings(datasetDescriptions)
-			projectDescription += fmt.Sprintf(" Must be one of the following: %s.", strings.Join(projectIDList, ", "))
-			datasetDescription += fmt.Sprintf(" Must be one of the allowed datasets: %s.", strings.Join(datasetDescriptions, "; "))
-			datasetParam = parameters.NewStringParameter(datasetKey, datasetDescription)
+			datasetDescription, err = tools.ExpandDescription("", allowedDatasetsDescription, map[string]any{
+				"Description": datasetDescription,
+				"Datasets":    datasetDescriptions,
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			sort.Strings(datasetIDs)
+			datasetParam = parameters.WithExamples(parameters.NewStringParameter(DatasetKey, datasetDescription), toAnySlice(slices.Compact(datasetIDs))...)
+			projectParam = parameters.WithExamples(parameters.NewStringParameterWithEnumDefault(ProjectKey, defaultProjectID, projectDescription, projectIDList), toAnySlice(projectIDList)...)
 		}
 	} else {
-		datasetParam = parameters.NewStringParameter(datasetKey, datasetDescription)
+		datasetParam = parameters.WithExamples(parameters.NewStringParameter(DatasetKey, datasetDescription), exampleDatasetID)
+		projectParam = parameters.WithExamples(parameters.NewStringParameterWithDefault(ProjectKey, defaultProjectID, projectDescription), exampleProjectID)
 	}
 
-	projectParam = parameters.NewStringParameterWithDefault(projectKey, defaultProjectID, projectDescription)
+	return projectParam, datasetParam, nil
+}
 
-	return projectParam, datasetParam
+// NewTableParameter generates the TableKey tool parameter, with the canonical
+// description unless descriptions overrides it.
+func NewTableParameter(descriptions tools.ParameterDescriptions) parameters.Parameter {
+	return parameters.WithExamples(parameters.NewStringParameter(TableKey, descriptions.Describe(TableKey, TableDescription)), exampleTableID)
+}
+
+// DescriptionData returns the properties of a BigQuery source that tool and
+// parameter descriptions can reference as {{ .Source.Project }},
+// {{ .Source.Location }} and {{ .Source.AllowedDatasets }}.
+func DescriptionData(src any) map[string]any {
+	source := make(map[string]any)
+	if s, ok := src.(interface{ BigQueryProject() string }); ok {
+		source["Project"] = s.BigQueryProject()
+	}
+	if s, ok := src.(interface{ BigQueryLocation() string }); ok {
+		source["Location"] = s.BigQueryLocation()
+	}
+	if s, ok := src.(interface{ BigQueryAllowedDatasets() []string }); ok {
+		source["AllowedDatasets"] = s.BigQueryAllowedDatasets()
+	}
+	return map[string]any{"Source": source}
+}
+
+// ExpandDescriptions expands the templates in a tool's description and in the
+// descriptions of its parameters using the properties of src, if the tool
+// enabled them. It returns the expanded tool description. Descriptions are left
+// as is otherwise, since they may contain "{{" for other reasons.
+func ExpandDescriptions(toolName string, enabled bool, src any, description string, params parameters.Parameters) (string, error) {
+	if !enabled {
+		return description, nil
+	}
+	data := DescriptionData(src)
+	expand := func(desc string) (string, error) {
+		return tools.ExpandDescription(toolName, desc, data)
+	}
+	if err := params.ExpandDescriptions(expand); err != nil {
+		return "", err
+	}
+	return expand(description)
+}
+
+// InitializeProjectParameter generates an optional ProjectKey tool parameter
+// that defaults to defaultProjectID. If allowedProjects is non-empty, the
+// description lists the projects that may be used instead of the default.
+func InitializeProjectParameter(defaultProjectID string, allowedProjects []string, projectDescription string) parameters.Parameter {
+	if len(allowedProjects) > 0 {
+		projectIDList := []string{fmt.Sprintf("`%s`", defaultProjectID)}
+		for _, p := range allowedProjects {
+			if p != defaultProjectID {
+				projectIDList = append(projectIDList, fmt.Sprintf("`%s`", p))
+			}
+		}
+		projectDescription += fmt.Sprintf(" Must be one of the following: %s.", strings.Join(projectIDList, ", "))
+		return parameters.WithExamples(parameters.NewStringParameterWithDefault(ProjectKey, defaultProjectID, projectDescription), toAnySlice(allowedProjects)...)
+	}
+	return parameters.WithExamples(parameters.NewStringParameterWithDefault(ProjectKey, defaultProjectID, projectDescription), exampleProjectID)
+}
+
+// Examples of the values of generated parameters that are not restricted to
+// the resources of the source.
+const (
+	exampleProjectID = "my-project"
+	exampleDatasetID = "my_dataset"
+	exampleTableID   = "my_table"
+)
+
+// ExampleDataAgentID is an example of a bare data agent ID.
+const ExampleDataAgentID = "my-agent"
+
+// DataAgentIDExamples returns examples of the values of data agent ID
+// parameters that accept either a bare ID or a full resource name.
+func DataAgentIDExamples() []any {
+	return []any{ExampleDataAgentID, fmt.Sprintf("projects/%s/locations/global/%s/%s", exampleProjectID, DataAgentsCollection, ExampleDataAgentID)}
+}
+
+func toAnySlice(values []string) []any {
+	s := make([]any, len(values))
+	for i, v := range values {
+		s[i] = v
+	}
+	return s
+}
+
+// ResolveProject returns the project to use for a request. An empty project
+// resolves to defaultProjectID, the project of the source. Any other project
+// must be listed in allowedProjects. The project is chosen by the agent, so a
+// tool whose config lists no projects only uses the project of its source
+// instead of any project its credentials can reach.
+func ResolveProject(project, defaultProjectID string, allowedProjects []string) (string, error) {
+	project = strings.TrimSpace(project)
+	if project == "" || project == defaultProjectID {
+		return defaultProjectID, nil
+	}
+	if len(allowedProjects) == 0 {
+		return "", fmt.Errorf("project %q is not allowed; the only allowed project is %q", project, defaultProjectID)
+	}
+	for _, p := range allowedProjects {
+		if p == project {
+			return project, nil
+		}
+	}
+	return "", fmt.Errorf("project %q is not allowed; allowed projects are %q and %s", project, defaultProjectID, strings.Join(quoteAll(allowedProjects), ", "))
+}
+
+func quoteAll(values []string) []string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return quoted
+}
+
+// RestrictionError logs that the tool named toolName rejected an invocation
+// because of the restrictions of its source, and returns the agent error msg
+// reporting it. datasets are the "project.dataset" names of the datasets the
+// invocation is not allowed to access, if any.
+func RestrictionError(ctx context.Context, toolName, msg string, datasets ...string) util.ToolboxError {
+	if logger, err := util.LoggerFromContext(ctx); err == nil {
+		logger.InfoContext(ctx, fmt.Sprintf("tool %q rejected the invocation: %s", toolName, msg))
+	}
+	err := util.NewAgentError(msg, nil).WithCode(util.ErrorCodePermissionDenied)
+	if len(datasets) > 0 {
+		err = err.WithDetails(map[string]any{"datasets": datasets})
+	}
+	return err
+}
+
+// ProjectOverrideSource is implemented by BigQuery sources whose invocations
+// can use one of their allowed projects instead of their own.
+type ProjectOverrideSource interface {
+	BigQueryProject() string
+	BigQueryAllowedProjects() []string
+	WithProject(context.Context, string) (context.Context, error)
+}
+
+// AppendProjectOverrideParameter appends to params the optional parameter
+// that overrides the project of the queries of an invocation, if s has
+// allowed projects. Sources without allowed projects only use their own.
+func AppendProjectOverrideParameter(params parameters.Parameters, s ProjectOverrideSource) parameters.Parameters {
+	if len(s.BigQueryAllowedProjects()) == 0 {
+		return params
+	}
+	return append(params, InitializeProjectParameter(s.BigQueryProject(), s.BigQueryAllowedProjects(), "The Google Cloud project ID to run the query in. Defaults to the project of the source."))
+}
+
+// WithProjectOverride returns ctx with the project requested by the project
+// parameter of an invocation of the tool named toolName, see
+// AppendProjectOverrideParameter. Projects that s does not allow are rejected.
+func WithProjectOverride(ctx context.Context, toolName string, s ProjectOverrideSource, paramsMap map[string]any) (context.Context, util.ToolboxError) {
+	project, _ := paramsMap[ProjectKey].(string)
+	projectCtx, err := s.WithProject(ctx, project)
+	if err != nil {
+		return nil, RestrictionError(ctx, toolName, err.Error())
+	}
+	return projectCtx, nil
+}
+
+// JobOptions are the settings of the query jobs and dry runs of a tool. They
+// are defined with the source, which runs the queries.
+type JobOptions = bigqueryds.JobOptions
+
+// JobConfig holds the fields of the configuration of a tool that set the
+// options of its jobs. Unset fields keep the options of the source.
+type JobConfig struct {
+	// Labels are the jobLabels of the tool.
+	Labels             map[string]string
+	MaximumBytesBilled *int64
+	UseStorageReadAPI  *bool
+	// QueryLabel is the query_label connection property, "key:value".
+	QueryLabel string
+	// DestinationTable is the ID of the table receiving the results of
+	// queries, "project.dataset.table".
+	DestinationTable string
+	// Priority is "interactive" or "batch", in any case.
+	Priority      string
+	UseQueryCache *bool
+}
+
+// ToolJobOptions validates the job options of the configuration of a tool,
+// and returns them as the options of its jobs, which override those of its
+// source.
+func ToolJobOptions(cfg JobConfig) (JobOptions, error) {
+	if err := bigqueryds.ValidateJobOptions("jobLabels", cfg.Labels, cfg.MaximumBytesBilled); err != nil {
+		return JobOptions{}, err
+	}
+	opts := JobOptions{Labels: cfg.Labels, UseStorageReadAPI: cfg.UseStorageReadAPI, UseQueryCache: cfg.UseQueryCache}
+	if cfg.MaximumBytesBilled != nil {
+		opts.MaximumBytesBilled = *cfg.MaximumBytesBilled
+	}
+	if cfg.QueryLabel != "" {
+		if err := bigqueryds.ValidateQueryLabel(cfg.QueryLabel); err != nil {
+			return JobOptions{}, err
+		}
+		opts.QueryLabel = cfg.QueryLabel
+	}
+	if cfg.DestinationTable != "" {
+		parts := strings.Split(cfg.DestinationTable, ".")
+		if len(parts) != 3 || slices.Contains(parts, "") {
+			return JobOptions{}, fmt.Errorf("invalid destinationTable %q: expected 'project.dataset.table'", cfg.DestinationTable)
+		}
+		opts.DestinationTable = &bigqueryrestapi.TableReference{ProjectId: parts[0], DatasetId: parts[1], TableId: parts[2]}
+	}
+	opts.Priority = strings.ToUpper(cfg.Priority)
+	if err := bigqueryds.ValidatePriority(opts.Priority); err != nil {
+		return JobOptions{}, err
+	}
+	return opts, nil
+}
+
+// JobOptionsSource is implemented by sources with default job options.
+type JobOptionsSource interface {
+	BigQueryJobOptions(tool JobOptions) JobOptions
+}
+
+// ResolveJobOptions returns the options of the jobs of an invocation: the
+// defaults of the source s, overridden by the options of the tool, overridden
+// by the options of the invocation.
+func ResolveJobOptions(s JobOptionsSource, tool, invocation JobOptions) JobOptions {
+	return s.BigQueryJobOptions(tool).Merge(invocation)
+}
+
+// Parameters that override the job options of an invocation, see
+// AppendJobParameters.
+const (
+	UseQueryCacheKey = "use_query_cache"
+	PriorityKey      = "priority"
+)
+
+// JobParameterKeys are the parameters added by AppendJobParameters, which the
+// configuration of a tool may pin.
+var JobParameterKeys = []string{UseQueryCacheKey, PriorityKey}
+
+// ValidatePinnedParameters returns an error if pinned, the pinnedParameters
+// of the configuration of a tool, has names other than JobParameterKeys.
+func ValidatePinnedParameters(pinned []string) error {
+	for _, name := range pinned {
+		if !slices.Contains(JobParameterKeys, name) {
+			return fmt.Errorf("invalid pinnedParameters %q: must be one of %q", name, JobParameterKeys)
+		}
+	}
+	return nil
+}
+
+// AppendJobParameters appends to params the optional parameters that
+// override the useQueryCache and priority of the jobs of an invocation,
+// except the pinned ones, whose options are always those of the tool.
+func AppendJobParameters(params parameters.Parameters, pinned []string) parameters.Parameters {
+	if !slices.Contains(pinned, UseQueryCacheKey) {
+		params = append(params, parameters.NewBooleanParameterWithRequired(
+			UseQueryCacheKey,
+			"Whether the query may be answered from cached results. Set to false to read the latest data. "+
+				"Defaults to the configuration of the tool.",
+			false,
+		))
+	}
+	if !slices.Contains(pinned, PriorityKey) {
+		required := false
+		params = append(params, &parameters.StringParameter{
+			CommonParameter: parameters.CommonParameter{
+				Name: PriorityKey,
+				Type: parameters.TypeString,
+				Desc: "The priority of the query job: INTERACTIVE runs it immediately, BATCH queues it until idle resources are available, " +
+					"which suits heavy queries that are not urgent. Defaults to the configuration of the tool.",
+				Required: &required,
+			},
+			Enum:           []string{bigqueryds.PriorityInteractive, bigqueryds.PriorityBatch},
+			EnumIgnoreCase: true,
+		})
+	}
+	return params
+}
+
+// InvocationJobOptions returns the job options of an invocation set by the
+// parameters of AppendJobParameters in paramsMap.
+func InvocationJobOptions(paramsMap map[string]any) JobOptions {
+	var opts JobOptions
+	if useQueryCache, ok := paramsMap[UseQueryCacheKey].(bool); ok {
+		opts.UseQueryCache = &useQueryCache
+	}
+	if priority, ok := paramsMap[PriorityKey].(string); ok {
+		opts.Priority = priority
+	}
+	return opts
 }
@@ -0,0 +1,452 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycommon_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	bigqueryapi "cloud.google.com/go/bigquery"
+	"github.com/google/go-cmp/cmp"
+	bigqueryds "github.com/googleapis/genai-toolbox/internal/sources/bigquery"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	"github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+	bigqueryrestapi "google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/option"
+)
+
+func TestResolveProject(t *testing.T) {
+	tcs := []struct {
+		desc    string
+		project string
+		allowed []string
+		want    string
+		wantErr bool
+	}{
+		{desc: "default project", project: "", allowed: []string{"other"}, want: "source-project"},
+		{desc: "explicit default project", project: "source-project", allowed: []string{"other"}, want: "source-project"},
+		{desc: "allowed override", project: "other", allowed: []string{"other"}, want: "other"},
+		{desc: "rejected override", project: "evil", allowed: []string{"other"}, wantErr: true},
+		{desc: "override without allowed projects", project: "any", wantErr: true},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := bigquerycommon.ResolveProject(tc.project, "source-project", tc.allowed)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != tc.want {
+				t.Fatalf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestInitializeProjectParameter(t *testing.T) {
+	param := bigquerycommon.InitializeProjectParameter("source-project", []string{"other", "source-project"}, "The project.")
+	manifest := param.Manifest()
+	if manifest.Name != "project" || manifest.Required {
+		t.Fatalf("expected an optional 'project' parameter, got %+v", manifest)
+	}
+	want := "The project. Must be one of the following: `source-project`, `other`."
+	if manifest.Description != want {
+		t.Fatalf("got description %q, want %q", manifest.Description, want)
+	}
+	if !strings.Contains(bigquerycommon.InitializeProjectParameter("p", nil, "The project.").Manifest().Description, "The project.") {
+		t.Fatalf("expected description to be kept when no projects are allowed")
+	}
+}
+
+func TestProjectOverride(t *testing.T) {
+	single := &bigqueryds.Source{Config: bigqueryds.Config{Name: "single", Project: "source-project"}}
+	if params := bigquerycommon.AppendProjectOverrideParameter(nil, single); len(params) != 0 {
+		t.Fatalf("expected no project parameter for a source without allowed projects, got %d parameters", len(params))
+	}
+
+	multi := &bigqueryds.Source{Config: bigqueryds.Config{Name: "multi", Project: "source-project", AllowedProjects: []string{"tenant-a"}}}
+	params := bigquerycommon.AppendProjectOverrideParameter(nil, multi)
+	if len(params) != 1 || params[0].GetName() != "project" || params[0].Manifest().Required {
+		t.Fatalf("expected an optional 'project' parameter, got %+v", params)
+	}
+
+	tcs := []struct {
+		desc    string
+		source  *bigqueryds.Source
+		params  map[string]any
+		want    string
+		wantErr bool
+	}{
+		{desc: "no override", source: multi, params: map[string]any{}, want: "source-project"},
+		{desc: "allowed override", source: multi, params: map[string]any{"project": "tenant-a"}, want: "tenant-a"},
+		{desc: "rejected override", source: multi, params: map[string]any{"project": "tenant-b"}, wantErr: true},
+		{desc: "single project source", source: single, params: map[string]any{}, want: "source-project"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctx, tbErr := bigquerycommon.WithProjectOverride(context.Background(), "tool", tc.source, tc.params)
+			if tc.wantErr {
+				if tbErr == nil {
+					t.Fatalf("expected the project to be rejected")
+				}
+				if got := tbErr.ErrorInfo().Code; got != util.ErrorCodePermissionDenied {
+					t.Errorf("got error code %q, want %q", got, util.ErrorCodePermissionDenied)
+				}
+				return
+			}
+			if tbErr != nil {
+				t.Fatalf("unexpected error: %s", tbErr)
+			}
+			if got := tc.source.BigQueryProjectFor(ctx); got != tc.want {
+				t.Errorf("got project %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDryRunQueryJobOptions(t *testing.T) {
+	var got bigqueryrestapi.Job
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("unable to decode request: %s", err)
+		}
+		_ = json.NewEncoder(w).Encode(got)
+	}))
+	defer server.Close()
+	restService, err := bigqueryrestapi.NewService(context.Background(), option.WithEndpoint(server.URL), option.WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	opts := bigqueryds.JobOptions{Labels: map[string]string{"team": "data"}, MaximumBytesBilled: 1000}
+	if _, err := bigquerycommon.DryRunQuery(context.Background(), restService, "my-project", "US", "SELECT 1", nil, "", nil, opts); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !got.Configuration.DryRun {
+		t.Fatalf("expected a dry run")
+	}
+	if diff := cmp.Diff(opts.Labels, got.Configuration.Labels); diff != "" {
+		t.Errorf("unexpected labels (-want +got):\n%s", diff)
+	}
+	if got.Configuration.Query.MaximumBytesBilled != 1000 {
+		t.Errorf("got maximum bytes billed %d, want 1000", got.Configuration.Query.MaximumBytesBilled)
+	}
+}
+
+func TestToolJobOptions(t *testing.T) {
+	bytes := int64(1000)
+	disabled := false
+	got, err := bigquerycommon.ToolJobOptions(bigquerycommon.JobConfig{
+		Labels:             map[string]string{"team": "data"},
+		MaximumBytesBilled: &bytes,
+		QueryLabel:         "app:toolbox",
+		DestinationTable:   "p.d.t",
+		Priority:           "batch",
+		UseQueryCache:      &disabled,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := bigquerycommon.JobOptions{
+		Labels:             map[string]string{"team": "data"},
+		MaximumBytesBilled: 1000,
+		QueryLabel:         "app:toolbox",
+		DestinationTable:   &bigqueryrestapi.TableReference{ProjectId: "p", DatasetId: "d", TableId: "t"},
+		Priority:           bigqueryds.PriorityBatch,
+		UseQueryCache:      &disabled,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected job options (-want +got):\n%s", diff)
+	}
+
+	for _, cfg := range []bigquerycommon.JobConfig{
+		{Labels: map[string]string{"Team": "data"}},
+		{QueryLabel: "toolbox"},
+		{DestinationTable: "d.t"},
+		{DestinationTable: "p..t"},
+		{Priority: "urgent"},
+	} {
+		if _, err := bigquerycommon.ToolJobOptions(cfg); err == nil {
+			t.Errorf("expected an error for %+v", cfg)
+		}
+	}
+}
+
+func TestResolveJobOptions(t *testing.T) {
+	sourceBytes := int64(5000)
+	source := &bigqueryds.Source{Config: bigqueryds.Config{
+		DefaultJobLabels:   map[string]string{"team": "data", "env": "prod"},
+		MaximumBytesBilled: &sourceBytes,
+	}}
+	enabled, disabled := true, false
+	tool := bigquerycommon.JobOptions{
+		Labels:        map[string]string{"team": "sales"},
+		QueryLabel:    "app:toolbox",
+		UseQueryCache: &enabled,
+	}
+	invocation := bigquerycommon.JobOptions{UseQueryCache: &disabled}
+
+	got := bigquerycommon.ResolveJobOptions(source, tool, invocation)
+	want := bigquerycommon.JobOptions{
+		Labels:             map[string]string{"team": "sales", "env": "prod"},
+		MaximumBytesBilled: 5000,
+		QueryLabel:         "app:toolbox",
+		UseQueryCache:      &disabled,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected job options (-want +got):\n%s", diff)
+	}
+}
+
+func TestDryRunQueryParameterMode(t *testing.T) {
+	var got bigqueryrestapi.Job
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = bigqueryrestapi.Job{}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("unable to decode request: %s", err)
+		}
+		_ = json.NewEncoder(w).Encode(got)
+	}))
+	defer server.Close()
+	restService, err := bigqueryrestapi.NewService(context.Background(), option.WithEndpoint(server.URL), option.WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	params := []*bigqueryrestapi.QueryParameter{{
+		ParameterType:  &bigqueryrestapi.QueryParameterType{Type: "INT64"},
+		ParameterValue: &bigqueryrestapi.QueryParameterValue{Value: "1"},
+	}}
+	if _, err := bigquerycommon.DryRunQuery(context.Background(), restService, "my-project", "US", "SELECT ?", params, bigquerycommon.ParameterModePositional, nil, bigqueryds.JobOptions{}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got.Configuration.Query.ParameterMode != "POSITIONAL" {
+		t.Errorf("got parameter mode %q, want POSITIONAL", got.Configuration.Query.ParameterMode)
+	}
+	if _, err := bigquerycommon.DryRunQuery(context.Background(), restService, "my-project", "US", "SELECT 1", nil, bigquerycommon.ParameterModePositional, nil, bigqueryds.JobOptions{}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got.Configuration.Query.ParameterMode != "" {
+		t.Errorf("got parameter mode %q for a query without parameters, want none", got.Configuration.Query.ParameterMode)
+	}
+}
+
+func TestInitializeDatasetParameters(t *testing.T) {
+	tcs := []struct {
+		desc            string
+		allowedDatasets []string
+		wantDefault     any
+		wantEnum        []string
+		wantDatasetDesc string
+	}{
+		{desc: "unrestricted", wantDefault: "source-project", wantDatasetDesc: "The dataset."},
+		{desc: "single dataset", allowedDatasets: []string{"p1.sales"}, wantDefault: "p1", wantEnum: []string{"p1"}, wantDatasetDesc: "The dataset. Must be `sales`."},
+		{
+			desc:            "multiple projects",
+			allowedDatasets: []string{"p2.logs", "p1.sales", "p1.hr"},
+			wantDefault:     "source-project",
+			wantEnum:        []string{"p1", "p2", "source-project"},
+			wantDatasetDesc: "The dataset. Must be one of the allowed datasets: `hr`, `sales` from project `p1`; `logs` from project `p2`.",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			projectParam, datasetParam, err := bigquerycommon.InitializeDatasetParameters(tc.allowedDatasets, "source-project", tools.ParameterDescriptions{"project": "The project.", "dataset": "The dataset."})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			manifest := projectParam.Manifest()
+			if manifest.Default != tc.wantDefault {
+				t.Errorf("got default %v, want %v", manifest.Default, tc.wantDefault)
+			}
+			if diff := cmp.Diff(tc.wantEnum, manifest.Enum); diff != "" {
+				t.Errorf("unexpected project enum (-want +got):\n%s", diff)
+			}
+			if manifest.Description != "The project." {
+				t.Errorf("expected the allowed projects to be listed in the enum only, got description %q", manifest.Description)
+			}
+			if datasetParam.GetName() != "dataset" {
+				t.Errorf("unexpected dataset parameter name %q", datasetParam.GetName())
+			}
+			if got := datasetParam.Manifest().Description; got != tc.wantDatasetDesc {
+				t.Errorf("got dataset description %q, want %q", got, tc.wantDatasetDesc)
+			}
+			if _, err := projectParam.Parse(manifest.Default); err != nil {
+				t.Errorf("invalid default %v of the project parameter: %s", manifest.Default, err)
+			}
+			for _, p := range []parameters.Parameter{projectParam, datasetParam} {
+				if len(p.GetExamples()) == 0 {
+					t.Errorf("expected examples for parameter %q", p.GetName())
+				}
+				for _, example := range p.GetExamples() {
+					if _, err := p.Parse(example); err != nil {
+						t.Errorf("invalid example %v of parameter %q: %s", example, p.GetName(), err)
+					}
+				}
+			}
+		})
+	}
+
+	projectParam, datasetParam, err := bigquerycommon.InitializeDatasetParameters([]string{"p1.sales", "p2.logs"}, "p1", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := projectParam.Parse("p3"); err == nil {
+		t.Errorf("expected a project without allowed datasets to be rejected")
+	}
+	// without overrides, the parameters have the canonical descriptions
+	if got := projectParam.Manifest().Description; got != bigquerycommon.ProjectDescription {
+		t.Errorf("got project description %q, want %q", got, bigquerycommon.ProjectDescription)
+	}
+	if got := datasetParam.Manifest().Description; !strings.HasPrefix(got, bigquerycommon.DatasetDescription+" Must be one of") {
+		t.Errorf("got dataset description %q, want the canonical description", got)
+	}
+	if _, _, err := bigquerycommon.InitializeDatasetParameters([]string{"sales"}, "p1", nil); err == nil {
+		t.Errorf("expected an error for an allowed dataset without a project")
+	}
+	tableParam := bigquerycommon.NewTableParameter(tools.ParameterDescriptions{"table": "The table to describe."})
+	if m := tableParam.Manifest(); m.Name != bigquerycommon.TableKey || m.Description != "The table to describe." || !m.Required {
+		t.Errorf("unexpected table parameter %+v", m)
+	}
+}
+
+func TestQueryPlan(t *testing.T) {
+	job := &bigqueryrestapi.Job{
+		Statistics: &bigqueryrestapi.JobStatistics{
+			TotalBytesProcessed: 1024,
+			Query: &bigqueryrestapi.JobStatistics2{
+				StatementType: "INSERT",
+				ReferencedTables: []*bigqueryrestapi.TableReference{
+					{ProjectId: "p", DatasetId: "sales", TableId: "orders"},
+					{ProjectId: "p", DatasetId: "hr", TableId: "staff"},
+					{ProjectId: "p", DatasetId: "sales", TableId: "orders"},
+				},
+			},
+		},
+	}
+	want := map[string]any{
+		"statement":           "INSERT ...",
+		"statementType":       "INSERT",
+		"totalBytesProcessed": int64(1024),
+		"referencedTables":    []string{"p.hr.staff", "p.sales.orders"},
+	}
+	if diff := cmp.Diff(want, bigquerycommon.QueryPlan("INSERT ...", job)); diff != "" {
+		t.Fatalf("unexpected plan (-want +got):\n%s", diff)
+	}
+
+	job.Statistics.Query = &bigqueryrestapi.JobStatistics2{
+		StatementType:  "CREATE_TABLE",
+		DdlTargetTable: &bigqueryrestapi.TableReference{ProjectId: "p", DatasetId: "sales", TableId: "summary"},
+	}
+	if diff := cmp.Diff([]string{"p.sales.summary"}, bigquerycommon.ReferencedTables(job)); diff != "" {
+		t.Fatalf("unexpected referenced tables (-want +got):\n%s", diff)
+	}
+}
+
+func TestQueryParameterValue(t *testing.T) {
+	ts := time.Date(2025, 3, 4, 5, 6, 7, 890000000, time.UTC)
+	dateType := bigqueryapi.StandardSQLDataType{TypeKind: "DATE"}
+	tcs := []struct {
+		desc     string
+		toolType string
+		value    any
+		want     any
+	}{
+		{desc: "string", toolType: "string", value: "2025-03-04", want: "2025-03-04"},
+		{desc: "timestamp", toolType: parameters.TypeTimestamp, value: ts, want: ts},
+		{desc: "date", toolType: parameters.TypeDate, value: "2025-03-04", want: &bigqueryapi.QueryParameterValue{Type: dateType, Value: "2025-03-04"}},
+		{
+			desc:     "date array",
+			toolType: parameters.TypeDate,
+			value:    []string{"2025-03-04", "2025-03-05"},
+			want: &bigqueryapi.QueryParameterValue{
+				Type:       bigqueryapi.StandardSQLDataType{TypeKind: "ARRAY", ArrayElementType: &dateType},
+				ArrayValue: []bigqueryapi.QueryParameterValue{{Value: "2025-03-04"}, {Value: "2025-03-05"}},
+			},
+		},
+		{desc: "missing date", toolType: parameters.TypeDate, value: nil, want: nil},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := bigquerycommon.QueryParameterValue(tc.toolType, tc.value)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("QueryParameterValue() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if got, want := bigquerycommon.QueryParameterValueString(ts), "2025-03-04 05:06:07.89+00:00"; got != want {
+		t.Errorf("QueryParameterValueString() = %q, want %q", got, want)
+	}
+	if got, want := bigquerycommon.QueryParameterValueString(42), "42"; got != want {
+		t.Errorf("QueryParameterValueString() = %q, want %q", got, want)
+	}
+}
+
+type fakeDescriptionSource struct{}
+
+func (fakeDescriptionSource) BigQueryProject() string  { return "my-project" }
+func (fakeDescriptionSource) BigQueryLocation() string { return "US" }
+func (fakeDescriptionSource) BigQueryAllowedDatasets() []string {
+	return []string{"my-project.sales", "my-project.hr"}
+}
+
+func TestExpandDescriptions(t *testing.T) {
+	params := parameters.Parameters{
+		parameters.NewStringParameter("table", "A table in `{{ .Source.Project }}`."),
+		parameters.NewArrayParameter("datasets", "Datasets to search.", parameters.NewStringParameter("dataset", "One of {{ join .Source.AllowedDatasets \", \" }}.")),
+	}
+	got, err := bigquerycommon.ExpandDescriptions("my-tool", true, fakeDescriptionSource{}, "Runs in {{ .Source.Location }}.", params)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got != "Runs in US." {
+		t.Errorf("got tool description %q", got)
+	}
+	manifest := params.Manifest()
+	if want := "A table in `my-project`."; manifest[0].Description != want {
+		t.Errorf("got parameter description %q, want %q", manifest[0].Description, want)
+	}
+	if want := "One of my-project.sales, my-project.hr."; manifest[1].Items.Description != want {
+		t.Errorf("got items description %q, want %q", manifest[1].Items.Description, want)
+	}
+
+	badParams := parameters.Parameters{parameters.NewStringParameter("table", "A table in {{ .Source.Region }}.")}
+	_, err = bigquerycommon.ExpandDescriptions("my-tool", true, fakeDescriptionSource{}, "d", badParams)
+	if err == nil || !strings.Contains(err.Error(), `"my-tool"`) || !strings.Contains(err.Error(), `"table"`) || !strings.Contains(err.Error(), "Region") {
+		t.Errorf("expected an error naming the tool, parameter and field, got %v", err)
+	}
+
+	// descriptions of tools that do not enable templates are left as is
+	got, err = bigquerycommon.ExpandDescriptions("my-tool", false, fakeDescriptionSource{}, "Uses {{ .Source.Region }}.", badParams)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got != "Uses {{ .Source.Region }}." || badParams.Manifest()[0].Description != "A table in {{ .Source.Region }}." {
+		t.Errorf("expected the descriptions to be left as is, got %q and %q", got, badParams.Manifest()[0].Description)
+	}
+}
@@ -16,26 +16,52 @@ package bigqueryconversationalanalytics
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 
 	bigqueryapi "cloud.google.com/go/bigquery"
 	yaml "github.com/goccy/go-yaml"
 	"github.com/googleapis/genai-toolbox/internal/embeddingmodels"
 	"github.com/googleapis/genai-toolbox/internal/sources"
+	bigqueryds "github.com/googleapis/genai-toolbox/internal/sources/bigquery"
+	"github.com/googleapis/genai-toolbox/internal/telemetry"
 	"github.com/googleapis/genai-toolbox/internal/tools"
+	bqutil "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+	"github.com/googleapis/genai-toolbox/internal/tools/googlehttp"
 	"github.com/googleapis/genai-toolbox/internal/util"
 	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/oauth2"
 )
 
 const resourceType string = "bigquery-conversational-analytics"
 
-const gdaURLFormat = "https://geminidataanalytics.googleapis.com/v1beta/projects/%s/locations/%s:chat"
+// chatSpanName is the name of the span of the calls to the chat API, which is
+// a child of the span of the invocation.
+const chatSpanName = "toolbox/bigquery/conversational_analytics/chat"
+
+const dataAgentIDKey string = "data_agent_id"
+
+// dataAgentMaxRetries is the number of times the lookup of a data agent by
+// checkDataAgentDatasets is retried after a transient error.
+const dataAgentMaxRetries = 2
+
+// defaultDataAgentLocation is used instead of the default chat location when
+// chatting with a data agent and neither the tool nor the source configures a
+// location.
+const defaultDataAgentLocation = "global"
+
+// gdaBaseURL is the Gemini Data Analytics API endpoint. It can be overridden for testing.
+var gdaBaseURL = "https://geminidataanalytics.googleapis.com/v1beta"
 
 const instructions = `**INSTRUCTIONS - FOLLOW THESE RULES:**
 1. **CONTENT:** Your answer should present the supporting data and then provide a conclusion based on that data.
@@ -60,11 +86,16 @@ type compatibleSource interface {
 	BigQueryClient() *bigqueryapi.Client
 	BigQueryTokenSourceWithScope(ctx context.Context, scopes []string) (oauth2.TokenSource, error)
 	BigQueryProject() string
+	BigQueryAllowedProjects() []string
+	WithProject(context.Context, string) (context.Context, error)
 	BigQueryLocation() string
 	GetMaxQueryResultRows() int
 	UseClientAuthorization() bool
+	ClientAuthorizationMode() string
 	IsDatasetAllowed(projectID, datasetID string) bool
 	BigQueryAllowedDatasets() []string
+	DatasetLocation(ctx context.Context, accessToken tools.AccessToken, projectID, datasetID string) (string, error)
+	ChatRateLimiter() *bigqueryds.ChatRateLimiter
 }
 
 type BQTableReference struct {
@@ -100,28 +131,82 @@ type InlineContext struct {
 	Options              Options              `json:"options"`
 }
 
+// DataAgentContext references the context of a data agent, used instead of
+// an inline context.
+type DataAgentContext struct {
+	DataAgent      string `json:"dataAgent"`
+	ContextVersion string `json:"contextVersion"`
+}
+
 type CAPayload struct {
-	Project       string        `json:"project"`
-	Messages      []Message     `json:"messages"`
-	InlineContext InlineContext `json:"inlineContext"`
-	ClientIdEnum  string        `json:"clientIdEnum"`
+	Project          string            `json:"project"`
+	Messages         []Message         `json:"messages"`
+	InlineContext    *InlineContext    `json:"inlineContext,omitempty"`
+	DataAgentContext *DataAgentContext `json:"dataAgentContext,omitempty"`
+	ClientIdEnum     string            `json:"clientIdEnum"`
 }
 
 type Config struct {
-	Name         string   `yaml:"name" validate:"required"`
-	Type         string   `yaml:"type" validate:"required"`
-	Source       string   `yaml:"source" validate:"required"`
-	Description  string   `yaml:"description" validate:"required"`
-	AuthRequired []string `yaml:"authRequired"`
+	Name         string                 `yaml:"name" validate:"required"`
+	Type         string                 `yaml:"type" validate:"required"`
+	Source       string                 `yaml:"source" validate:"required"`
+	Description  string                 `yaml:"description" validate:"required"`
+	AuthRequired []string               `yaml:"authRequired"`
+	Annotations  *tools.ToolAnnotations `yaml:"annotations,omitempty"`
+	// AllowedProjects lists the other projects that can be billed for and
+	// host the conversation.
+	AllowedProjects []string `yaml:"allowedProjects" expand:"true"`
+	// ValidateClientToken checks client OAuth tokens against the tokeninfo
+	// endpoint before calling the API, so that expired or wrongly scoped tokens
+	// are reported with a clear explanation.
+	ValidateClientToken bool `yaml:"validateClientToken"`
+	// ExtraHeaders are added to every request sent to the API, e.g. for
+	// gateway routing. They cannot override credential or identity headers.
+	ExtraHeaders map[string]string `yaml:"extraHeaders" expand:"true"`
+	// APIClientSuffix is appended to the X-Goog-API-Client header for
+	// partner attribution.
+	APIClientSuffix string `yaml:"apiClientSuffix"`
+	// MaxRetries is the number of times a throttled or failed request is
+	// retried. Defaults to 3.
+	MaxRetries *int `yaml:"maxRetries"`
+	// IncludeRetryMetadata sends the number of attempts, the time spent
+	// backing off, and the total latency as the metadata of every result, not
+	// only to clients that request it.
+	IncludeRetryMetadata bool `yaml:"includeRetryMetadata"`
+	// RateLimit limits the chat requests of the tool, instead of the
+	// chatRateLimit of the source.
+	RateLimit *bigqueryds.ChatRateLimitConfig `yaml:"rateLimit"`
+	// CheckDataAgentDatasets allows data agents when the source has allowed
+	// datasets, checking their tables against them: "enforce" refuses data
+	// agents with other tables, "warn" lists them in the result metadata.
+	// Without it, data agents are refused when the source has allowed
+	// datasets.
+	CheckDataAgentDatasets string `yaml:"checkDataAgentDatasets"`
+	// Location is the location of the Conversational Analytics API, instead
+	// of the location of the source.
+	Location string `yaml:"location" expand:"true"`
+	// ValidateGeneratedSQL validates the SQL generated in the response
+	// against the allowed datasets of the source, "parse" with the tables
+	// found by parsing it and "dryRun" with the tables of its dry run, and
+	// adds the verdict next to the SQL. The response is returned either way.
+	ValidateGeneratedSQL string `yaml:"validateGeneratedSql"`
+	// ExpandDescriptions expands the source properties referenced in the
+	// descriptions of the tool and its parameters, e.g. {{ .Source.Project }}.
+	ExpandDescriptions bool `yaml:"expandDescriptions"`
 }
 
 // validate interface
-var _ tools.ToolConfig = Config{}
+var _ tools.ToolConfigWithFields = Config{}
 
 func (cfg Config) ToolConfigType() string {
 	return resourceType
 }
 
+// DeclaredFields returns the fields that policies can set.
+func (cfg Config) DeclaredFields() []string {
+	return []string{"allowedProjects"}
+}
+
 func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error) {
 	// verify source exists
 	rawS, ok := srcs[cfg.Source]
@@ -132,7 +217,33 @@ func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error)
 	// verify the source is compatible
 	s, ok := rawS.(compatibleSource)
 	if !ok {
-		return nil, fmt.Errorf("invalid source for %q tool: source %q not compatible", resourceType, cfg.Source)
+		return nil, tools.IncompatibleSourceError[compatibleSource](rawS, cfg.Source, resourceType, srcs)
+	}
+
+	if err := bqutil.ValidateExtraHeaders(cfg.ExtraHeaders); err != nil {
+		return nil, fmt.Errorf("invalid extraHeaders for tool %q: %w", cfg.Name, err)
+	}
+	if err := bqutil.ValidateAPIClientSuffix(cfg.APIClientSuffix); err != nil {
+		return nil, fmt.Errorf("invalid config for tool %q: %w", cfg.Name, err)
+	}
+
+	if cfg.MaxRetries != nil && *cfg.MaxRetries < 0 {
+		return nil, fmt.Errorf("invalid maxRetries %d for tool %q: must not be negative", *cfg.MaxRetries, cfg.Name)
+	}
+	if err := bqutil.ValidateDataAgentDatasetsMode(cfg.CheckDataAgentDatasets); err != nil {
+		return nil, fmt.Errorf("invalid config for tool %q: %w", cfg.Name, err)
+	}
+	if err := validateGeneratedSQLMode(cfg.ValidateGeneratedSQL, s); err != nil {
+		return nil, fmt.Errorf("invalid config for tool %q: %w", cfg.Name, err)
+	}
+
+	var rateLimiter *bigqueryds.ChatRateLimiter
+	if cfg.RateLimit != nil {
+		limiter, err := cfg.RateLimit.NewLimiter("tool/" + cfg.Name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rateLimit for tool %q: %w", cfg.Name, err)
+		}
+		rateLimiter = limiter
 	}
 
 	allowedDatasets := s.BigQueryAllowedDatasets()
@@ -145,10 +256,29 @@ func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error)
 		tableRefsDescription += fmt.Sprintf(" The tables must only be from datasets in the following list: %s.", strings.Join(datasetIDs, ", "))
 	}
 	userQueryParameter := parameters.NewStringParameter("user_query_with_context", "The user's question, potentially including conversation history and system instructions for context.")
-	tableRefsParameter := parameters.NewStringParameter("table_references", tableRefsDescription)
+	tableRefsParameter := parameters.NewStringParameterWithDefault("table_references", "", tableRefsDescription+fmt.Sprintf(" Required unless `%s` is given.", dataAgentIDKey))
+	allowedProjects := cfg.AllowedProjects
+	if len(allowedProjects) == 0 {
+		allowedProjects = s.BigQueryAllowedProjects()
+	}
+	projectParameter := bqutil.InitializeProjectParameter(s.BigQueryProject(), allowedProjects, "The Google Cloud project ID used to call the Conversational Analytics API. Defaults to the project of the source.")
 
-	params := parameters.Parameters{userQueryParameter, tableRefsParameter}
-	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, params, nil)
+	dataAgentIDParameter := parameters.WithExamples(parameters.NewStringParameterWithDefault(dataAgentIDKey, "", "The ID of a data agent to chat with instead of `table_references`, either a bare ID or a full resource name. The data agent's context is used for the conversation."), bqutil.DataAgentIDExamples()...)
+	contextVersionParameter := bqutil.NewContextVersionParameter("The context of the data agent to use: `published` (the default) or `staging`, which holds unpublished changes. Requires `data_agent_id`.")
+
+	params := parameters.Parameters{userQueryParameter, tableRefsParameter, projectParameter, dataAgentIDParameter, contextVersionParameter}
+	description, err := bqutil.ExpandDescriptions(cfg.Name, cfg.ExpandDescriptions, rawS, cfg.Description, params)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Description = description
+
+	annotations := tools.GetAnnotationsOrDefault(cfg.Annotations, func() *tools.ToolAnnotations {
+		// Answers to the same question may differ between calls.
+		readOnly := true
+		return &tools.ToolAnnotations{ReadOnlyHint: &readOnly}
+	})
+	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, params, annotations, nil)
 
 	// finish tool setup
 	t := Tool{
@@ -156,58 +286,61 @@ func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error)
 		Parameters:  params,
 		manifest:    tools.Manifest{Description: cfg.Description, Parameters: params.Manifest(), AuthRequired: cfg.AuthRequired},
 		mcpManifest: mcpManifest,
+		rateLimiter: rateLimiter,
+	}
+	if cfg.ValidateClientToken {
+		t.tokenValidator = bqutil.NewTokenValidator(bqutil.DefaultTokenInfoURL)
 	}
 	return t, nil
 }
 
 // validate interface
-var _ tools.Tool = Tool{}
+var _ tools.PlannableTool = Tool{}
 
 type Tool struct {
 	Config
 	Parameters  parameters.Parameters `yaml:"parameters"`
 	manifest    tools.Manifest
 	mcpManifest tools.McpManifest
+	// tokenValidator is set when ValidateClientToken is enabled.
+	tokenValidator *bqutil.TokenValidator
+	// rateLimiter is set when RateLimit is configured.
+	rateLimiter *bigqueryds.ChatRateLimiter
 }
 
 func (t Tool) ToConfig() tools.ToolConfig {
 	return t.Config
 }
 
-func (t Tool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, params parameters.ParamValues, accessToken tools.AccessToken) (any, util.ToolboxError) {
+// chatRequest is the request sent to the chat API for an invocation.
+type chatRequest struct {
+	source  compatibleSource
+	url     string
+	headers map[string]string
+	payload CAPayload
+	// datasetWarnings are the warnings of CheckDataAgentDatasets "warn".
+	datasetWarnings []string
+	// projectID is the project of the conversation.
+	projectID string
+	// location is the location of the API.
+	location string
+	// datasets are the datasets of the tables of the inline context, as
+	// "project.dataset".
+	datasets []string
+}
+
+// prepareChat builds the chat request of params. It returns the context to
+// send it with, in the requested project.
+func (t Tool) prepareChat(ctx context.Context, resourceMgr tools.SourceProvider, params parameters.ParamValues, accessToken tools.AccessToken) (context.Context, chatRequest, util.ToolboxError) {
 	source, err := tools.GetCompatibleSource[compatibleSource](resourceMgr, t.Source, t.Name, t.Type)
 	if err != nil {
-		return nil, util.NewClientServerError("source used is not compatible with the tool", http.StatusInternalServerError, err)
+		return nil, chatRequest{}, util.NewClientServerError("source used is not compatible with the tool", http.StatusInternalServerError, err)
 	}
 
-	var tokenStr string
-
 	// Get credentials for the API call
-	if source.UseClientAuthorization() {
-		// Use client-side access token
-		if accessToken == "" {
-			return nil, util.NewClientServerError("tool is configured for client OAuth but no token was provided in the request header", http.StatusUnauthorized, nil)
-		}
-		tokenStr, err = accessToken.ParseBearerToken()
-		if err != nil {
-			return nil, util.NewClientServerError("error parsing access token", http.StatusUnauthorized, err)
-		}
-	} else {
-		// Get a token source for the Gemini Data Analytics API.
-		tokenSource, err := source.BigQueryTokenSourceWithScope(ctx, nil)
-		if err != nil {
-			return nil, util.NewClientServerError("failed to get token source", http.StatusInternalServerError, err)
-		}
-
-		// Use cloud-platform token source for Gemini Data Analytics API
-		if tokenSource == nil {
-			return nil, util.NewClientServerError("cloud-platform token source is missing", http.StatusInternalServerError, nil)
-		}
-		token, err := tokenSource.Token()
-		if err != nil {
-			return nil, util.NewClientServerError("failed to get token from cloud-platform token source", http.StatusInternalServerError, err)
-		}
-		tokenStr = token.AccessToken
+	tokenStr, _, tbErr := bqutil.GetAPIToken(ctx, t.Name, source, accessToken, t.tokenValidator)
+	if tbErr != nil {
+		return nil, chatRequest{}, tbErr
 	}
 
 	// Extract parameters from the map
@@ -220,52 +353,236 @@ func (t Tool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, para
 	var tableRefs []BQTableReference
 	if tableRefsJSON != "" {
 		if err := json.Unmarshal([]byte(tableRefsJSON), &tableRefs); err != nil {
-			return nil, util.NewAgentError("failed to parse 'table_references' JSON string", err)
+			return nil, chatRequest{}, util.NewAgentError("failed to parse 'table_references' JSON string", err)
+		}
+	}
+
+	dataAgentID, _ := mapParams[dataAgentIDKey].(string)
+	contextVersion, _ := mapParams[bqutil.ContextVersionKey].(string)
+	if dataAgentID != "" {
+		if len(tableRefs) > 0 {
+			return nil, chatRequest{}, util.NewAgentError(fmt.Sprintf("'table_references' cannot be used with '%s'; the data agent's context is used instead", dataAgentIDKey), nil)
 		}
+		// Unless they are checked, the datasources of a data agent would
+		// bypass the allowed datasets.
+		if len(source.BigQueryAllowedDatasets()) > 0 && t.CheckDataAgentDatasets == "" {
+			return nil, chatRequest{}, bqutil.RestrictionError(ctx, t.Name, "chatting with a data agent is not allowed when the source restricts the datasets that can be accessed, unless the tool sets checkDataAgentDatasets")
+		}
+	} else if tableRefsJSON == "" {
+		return nil, chatRequest{}, util.NewAgentError(fmt.Sprintf("either 'table_references' or '%s' is required", dataAgentIDKey), nil)
+	} else if contextVersion == bqutil.ContextVersionStaging {
+		return nil, chatRequest{}, util.NewAgentError(fmt.Sprintf("'%s' requires '%s'", bqutil.ContextVersionKey, dataAgentIDKey), nil)
 	}
 
 	if len(source.BigQueryAllowedDatasets()) > 0 {
 		for _, tableRef := range tableRefs {
 			if !source.IsDatasetAllowed(tableRef.ProjectID, tableRef.DatasetID) {
-				return nil, util.NewAgentError(fmt.Sprintf("access to dataset '%s.%s' (from table '%s') is not allowed", tableRef.ProjectID, tableRef.DatasetID, tableRef.TableID), nil)
+				return nil, chatRequest{}, bqutil.RestrictionError(ctx, t.Name, fmt.Sprintf("access to dataset '%s.%s' (from table '%s') is not allowed", tableRef.ProjectID, tableRef.DatasetID, tableRef.TableID), tableRef.ProjectID+"."+tableRef.DatasetID)
 			}
 		}
 	}
 
 	// Construct URL, headers, and payload
-	projectID := source.BigQueryProject()
-	location := source.BigQueryLocation()
-	if location == "" {
-		location = "us"
+	requestedProject, _ := mapParams["project"].(string)
+	projectID := cmp.Or(strings.TrimSpace(requestedProject), source.BigQueryProject())
+	// Tools that do not list allowed projects fall back to the source's,
+	// which are checked below.
+	if len(t.AllowedProjects) > 0 || len(source.BigQueryAllowedProjects()) == 0 {
+		projectID, err = bqutil.ResolveProject(requestedProject, source.BigQueryProject(), t.AllowedProjects)
+		if err != nil {
+			return nil, chatRequest{}, util.NewAgentError("invalid 'project' parameter", err)
+		}
 	}
-	caURL := fmt.Sprintf(gdaURLFormat, projectID, location)
+	// The allowed projects of the source constrain the conversation's too.
+	if len(source.BigQueryAllowedProjects()) > 0 {
+		projectCtx, err := source.WithProject(ctx, projectID)
+		if err != nil {
+			return nil, chatRequest{}, bqutil.RestrictionError(ctx, t.Name, err.Error())
+		}
+		ctx = projectCtx
+	}
+	location := bqutil.ResolveLocation(t.Location, source, bqutil.DefaultCALocation)
+	if dataAgentID != "" {
+		// data agents are not located by the allowed datasets of the source
+		location = bigqueryds.ResolveLocation(t.Location, source.BigQueryLocation(), "", defaultDataAgentLocation)
+	}
+	datasets := make([]string, 0, len(tableRefs))
+	for _, tableRef := range tableRefs {
+		datasets = append(datasets, tableRef.ProjectID+"."+tableRef.DatasetID)
+	}
+	if tbErr := bqutil.CheckDatasetLocations(ctx, t.Name, source, accessToken, location, datasets); tbErr != nil {
+		return nil, chatRequest{}, tbErr
+	}
+	caURL := fmt.Sprintf("%s/projects/%s/locations/%s:chat", gdaBaseURL, projectID, location)
 
-	headers := map[string]string{
-		"Authorization":     fmt.Sprintf("Bearer %s", tokenStr),
-		"Content-Type":      "application/json",
-		"X-Goog-API-Client": util.GDAClientID,
+	headers := make(map[string]string, len(t.ExtraHeaders)+3)
+	for k, v := range t.ExtraHeaders {
+		headers[k] = v
 	}
+	headers["Authorization"] = fmt.Sprintf("Bearer %s", tokenStr)
+	headers["Content-Type"] = "application/json"
+	headers[bqutil.APIClientHeader] = bqutil.APIClientHeaderValue(t.APIClientSuffix)
 
+	var datasetWarnings []string
 	payload := CAPayload{
-		Project:  fmt.Sprintf("projects/%s", projectID),
-		Messages: []Message{{UserMessage: UserMessage{Text: finalQueryText}}},
-		InlineContext: InlineContext{
+		Project:      fmt.Sprintf("projects/%s", projectID),
+		Messages:     []Message{{UserMessage: UserMessage{Text: finalQueryText}}},
+		ClientIdEnum: util.GDAClientID,
+	}
+	if dataAgentID != "" {
+		dataAgent, err := bqutil.ResolveDataAgentName(dataAgentID, projectID, location)
+		if err != nil {
+			return nil, chatRequest{}, util.NewAgentError(fmt.Sprintf("invalid '%s' parameter", dataAgentIDKey), err)
+		}
+		payload.DataAgentContext = &DataAgentContext{DataAgent: dataAgent, ContextVersion: bqutil.ContextVersionEnum(contextVersion)}
+		if len(source.BigQueryAllowedDatasets()) > 0 {
+			warnings, tbErr := t.checkDataAgentDatasets(ctx, source, dataAgent, contextVersion, tokenStr)
+			if tbErr != nil {
+				return nil, chatRequest{}, tbErr
+			}
+			datasetWarnings = warnings
+		}
+	} else {
+		payload.InlineContext = &InlineContext{
 			DatasourceReferences: DatasourceReferences{
 				BQ: BQDatasource{TableReferences: tableRefs},
 			},
 			Options: Options{Chart: ChartOptions{Image: ImageOptions{NoImage: map[string]any{}}}},
-		},
-		ClientIdEnum: util.GDAClientID,
+		}
+	}
+
+	return ctx, chatRequest{source: source, url: caURL, headers: headers, payload: payload, datasetWarnings: datasetWarnings, projectID: projectID, location: location, datasets: datasets}, nil
+}
+
+// checkDataAgentDatasets fetches the data agent, and checks the tables of its
+// context against the allowed datasets of source.
+func (t Tool) checkDataAgentDatasets(ctx context.Context, source compatibleSource, dataAgent, contextVersion, tokenStr string) ([]string, util.ToolboxError) {
+	client := googlehttp.Client{Token: googlehttp.StaticToken(tokenStr), APIClient: bqutil.APIClientHeaderValue(t.APIClientSuffix), MaxRetries: dataAgentMaxRetries}
+	var agent map[string]any
+	if err := client.DoJSON(ctx, http.MethodGet, fmt.Sprintf("%s/%s", gdaBaseURL, dataAgent), nil, &agent); err != nil {
+		return nil, googlehttp.ToolboxError(err, fmt.Sprintf("failed to get data agent %q to check its datasets", dataAgent))
+	}
+	return bqutil.CheckDataAgentDatasets(ctx, t.Name, source, t.CheckDataAgentDatasets, agent, contextVersion)
+}
+
+func (t Tool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, params parameters.ParamValues, accessToken tools.AccessToken) (any, util.ToolboxError) {
+	ctx, req, tbErr := t.prepareChat(ctx, resourceMgr, params, accessToken)
+	if tbErr != nil {
+		return nil, tbErr
+	}
+
+	maxRetries := defaultMaxRetries
+	if t.MaxRetries != nil {
+		maxRetries = *t.MaxRetries
+	}
+
+	limiter := t.rateLimiter
+	if limiter == nil {
+		limiter = req.source.ChatRateLimiter()
+	}
+	if limiter != nil {
+		if tbErr := t.waitForRateLimiter(ctx, limiter); tbErr != nil {
+			return nil, tbErr
+		}
 	}
 
 	// Call the streaming API
-	response, err := getStream(caURL, payload, headers, source.GetMaxQueryResultRows())
+	ctx, span := telemetry.Tracer().Start(ctx, chatSpanName, trace.WithAttributes(attribute.String("url.full", req.url)))
+	validateSQL := t.generatedSQLValidator(ctx, req.source, accessToken, req.projectID)
+	response, stats, err := getStream(ctx, req.url, req.payload, req.headers, req.source.GetMaxQueryResultRows(), maxRetries, limiter, validateSQL)
+	if retry, tbErr := t.recheckDatasets(ctx, req, accessToken, err); tbErr != nil {
+		span.End()
+		return nil, tbErr
+	} else if retry {
+		var retried retryStats
+		response, retried, err = getStream(ctx, req.url, req.payload, req.headers, req.source.GetMaxQueryResultRows(), maxRetries, limiter, validateSQL)
+		retried.Attempts += stats.Attempts
+		retried.TotalBackoff += stats.TotalBackoff
+		retried.Latency += stats.Latency
+		stats = retried
+	}
+	span.SetAttributes(
+		attribute.Int("attempts", stats.Attempts),
+		attribute.Int64("total_backoff_ms", stats.TotalBackoff.Milliseconds()),
+	)
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		span.SetAttributes(attribute.Int("http.response.status_code", apiErr.StatusCode))
+	}
+	if err != nil {
+		span.SetStatus(codes.Error, "chat failed")
+	}
+	span.End()
 	if err != nil {
 		// getStream wraps network errors or non-200 responses
-		return nil, util.NewClientServerError("failed to get response from conversational analytics API", http.StatusInternalServerError, err)
+		return nil, translateAPIError(ctx, t.Name, err)
+	}
+
+	metadata := stats.meta()
+	if len(req.datasetWarnings) > 0 {
+		metadata[bqutil.DataAgentDatasetsWarningsKey] = req.datasetWarnings
 	}
+	// the warnings are sent to every client, as they are meant for the user
+	return tools.Result{Data: response, Metadata: metadata, IncludeMetadata: t.IncludeRetryMetadata || len(req.datasetWarnings) > 0}, nil
+}
 
-	return response, nil
+// recheckDatasets returns whether to retry the chat of req once, after it
+// failed with err. A chat that is not found after the locations of its
+// datasets were checked with cached metadata may have a dataset that was
+// deleted and created again in another location: the metadata is invalidated
+// and the locations are checked again, returning the error of a mismatch.
+func (t Tool) recheckDatasets(ctx context.Context, req chatRequest, accessToken tools.AccessToken, err error) (bool, util.ToolboxError) {
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		return false, nil
+	}
+	if !bqutil.InvalidateDatasets(ctx, t.Name, req.source, req.datasets) {
+		return false, nil
+	}
+	if tbErr := bqutil.CheckDatasetLocations(ctx, t.Name, req.source, accessToken, req.location, req.datasets); tbErr != nil {
+		return false, tbErr
+	}
+	return true, nil
+}
+
+// waitForRateLimiter waits for limiter to let a chat request through. A
+// request that would wait too long fails with a rate_limited error suggesting
+// when to retry.
+func (t Tool) waitForRateLimiter(ctx context.Context, limiter *bigqueryds.ChatRateLimiter) util.ToolboxError {
+	err := limiter.Wait(ctx)
+	var rateErr *bigqueryds.ChatRateLimitError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &rateErr):
+		return util.NewAgentError(fmt.Sprintf("tool %q is rate limited, try again later", t.Name), err).
+			WithCode(util.ErrorCodeRateLimited).
+			WithDetails(map[string]any{"retryAfterMs": rateErr.RetryAfter.Milliseconds()})
+	default:
+		return util.NewAgentError(fmt.Sprintf("tool %q was canceled while waiting for its rate limit", t.Name), err)
+	}
+}
+
+// Plan builds the chat request like Invoke, and returns it without sending it.
+// The values of the credentials and of the extra headers are redacted.
+func (t Tool) Plan(ctx context.Context, resourceMgr tools.SourceProvider, params parameters.ParamValues, accessToken tools.AccessToken) (any, util.ToolboxError) {
+	_, req, tbErr := t.prepareChat(ctx, resourceMgr, params, accessToken)
+	if tbErr != nil {
+		return nil, tbErr
+	}
+	headers := make(map[string]string, len(req.headers))
+	for k, v := range req.headers {
+		if _, extra := t.ExtraHeaders[k]; extra || k == "Authorization" {
+			v = parameters.RedactedValue
+		}
+		headers[k] = v
+	}
+	return map[string]any{
+		"method":  http.MethodPost,
+		"url":     req.url,
+		"headers": headers,
+		"payload": req.payload,
+	}, nil
 }
 
 func (t Tool) EmbedParams(ctx context.Context, paramValues parameters.ParamValues, embeddingModelsMap map[string]embeddingmodels.EmbeddingModel) (parameters.ParamValues, error) {
@@ -370,30 +687,36 @@ type ErrorResponse struct {
 	Message string  `json:"message"`
 }
 
-func getStream(url string, payload CAPayload, headers map[string]string, maxRows int) (string, error) {
+// getStream sends the chat request, and returns its response as the JSON
+// messages of the stream, one per line. If validateSQL is not nil, the
+// validation of the generated SQL that it returns is added to its message.
+func getStream(ctx context.Context, url string, payload CAPayload, headers map[string]string, maxRows int, maxRetries int, limiter *bigqueryds.ChatRateLimiter, validateSQL func(sql string) map[string]any) (string, retryStats, error) {
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal payload: %w", err)
+		return "", retryStats{}, fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payloadBytes))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-	for k, v := range headers {
-		req.Header.Set(k, v)
+	newRequest := func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payloadBytes))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		return req, nil
 	}
 
 	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, stats, err := doWithRetry(ctx, client, maxRetries, limiter, newRequest)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return "", stats, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("API returned non-200 status: %d %s", resp.StatusCode, string(body))
+		return "", stats, &apiError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var messages []map[string]any
@@ -402,9 +725,9 @@ func getStream(url string, payload CAPayload, headers map[string]string, maxRows
 	// The response is a JSON array, so we read the opening bracket.
 	if _, err := decoder.Token(); err != nil {
 		if err == io.EOF {
-			return "", nil // Empty response is valid
+			return "", stats, nil // Empty response is valid
 		}
-		return "", fmt.Errorf("error reading start of json array: %w", err)
+		return "", stats, fmt.Errorf("error reading start of json array: %w", err)
 	}
 
 	for decoder.More() {
@@ -413,7 +736,7 @@ func getStream(url string, payload CAPayload, headers map[string]string, maxRows
 			if err == io.EOF {
 				break
 			}
-			return "", fmt.Errorf("error decoding stream message: %w", err)
+			return "", stats, fmt.Errorf("error decoding stream message: %w", err)
 		}
 
 		var newMessage map[string]any
@@ -423,7 +746,7 @@ func getStream(url string, payload CAPayload, headers map[string]string, maxRows
 			} else if msg.SystemMessage.Schema != nil {
 				newMessage = handleSchemaResponse(msg.SystemMessage.Schema)
 			} else if msg.SystemMessage.Data != nil {
-				newMessage = handleDataResponse(msg.SystemMessage.Data, maxRows)
+				newMessage = handleDataResponse(msg.SystemMessage.Data, maxRows, validateSQL)
 			}
 		} else if msg.Error != nil {
 			newMessage = handleError(msg.Error)
@@ -435,7 +758,7 @@ func getStream(url string, payload CAPayload, headers map[string]string, maxRows
 	for i, msg := range messages {
 		jsonBytes, err := json.MarshalIndent(msg, "", "  ")
 		if err != nil {
-			return "", fmt.Errorf("error marshalling message: %w", err)
+			return "", stats, fmt.Errorf("error marshalling message: %w", err)
 		}
 		acc.Write(jsonBytes)
 		if i < len(messages)-1 {
@@ -443,7 +766,7 @@ func getStream(url string, payload CAPayload, headers map[string]string, maxRows
 		}
 	}
 
-	return acc.String(), nil
+	return acc.String(), stats, nil
 }
 
 func formatBqTableRef(tableRef *BQTableReference) string {
@@ -499,7 +822,7 @@ func handleSchemaResponse(resp *SchemaResponse) map[string]any {
 	return res
 }
 
-func handleDataResponse(resp *DataResponse, maxRows int) map[string]any {
+func handleDataResponse(resp *DataResponse, maxRows int, validateSQL func(sql string) map[string]any) map[string]any {
 	res := make(map[string]any)
 	if resp.Query != nil {
 		res["Retrieval Query"] = map[string]any{
@@ -509,6 +832,9 @@ func handleDataResponse(resp *DataResponse, maxRows int) map[string]any {
 	}
 	if resp.GeneratedSQL != "" {
 		res["SQL Generated"] = resp.GeneratedSQL
+		if validateSQL != nil {
+			res[sqlValidationKey] = validateSQL(resp.GeneratedSQL)
+		}
 	}
 	if resp.Result != nil {
 		var headers []string
@@ -548,6 +874,40 @@ func handleDataResponse(resp *DataResponse, maxRows int) map[string]any {
 	return res
 }
 
+// apiError is a non-200 response of the conversational analytics API.
+type apiError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("API returned non-200 status: %d %s", e.StatusCode, e.Body)
+}
+
+// translateAPIError logs the failed call of the conversational analytics API
+// by the tool named toolName, and returns its error, with the error code and
+// retryability of its cause.
+func translateAPIError(ctx context.Context, toolName string, err error) util.ToolboxError {
+	if logger, logErr := util.LoggerFromContext(ctx); logErr == nil {
+		logger.ErrorContext(ctx, fmt.Sprintf("tool %q failed to get a response from the conversational analytics API: %v", toolName, err))
+	}
+	tbErr := util.NewClientServerError("failed to get response from conversational analytics API", http.StatusInternalServerError, err)
+	var apiErr *apiError
+	var urlErr *url.Error
+	switch {
+	case errors.As(err, &apiErr):
+		code, _ := util.ErrorCodeFromStatus(apiErr.StatusCode)
+		if apiErr.StatusCode >= http.StatusInternalServerError {
+			code = util.ErrorCodeBackendUnavailable
+		}
+		tbErr.ErrCode, tbErr.Retryable = code, isRetryableStatus(apiErr.StatusCode)
+	case errors.As(err, &urlErr):
+		// the request could not be sent
+		tbErr = tbErr.WithCode(util.ErrorCodeBackendUnavailable)
+	}
+	return tbErr
+}
+
 func handleError(resp *ErrorResponse) map[string]any {
 	return map[string]any{
 		"Error": map[string]any{
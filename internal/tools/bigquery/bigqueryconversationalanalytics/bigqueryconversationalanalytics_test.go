@@ -52,6 +52,30 @@ func TestParseFromYamlBigQueryConversationalAnalytics(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "with project allowlist and token validation",
+			in: `
+            kind: tools
+            name: example_tool
+            type: bigquery-conversational-analytics
+            source: my-instance
+            description: some description
+            allowedProjects:
+              - agents-project
+            validateClientToken: true
+            `,
+			want: server.ToolConfigs{
+				"example_tool": bigqueryconversationalanalytics.Config{
+					Name:                "example_tool",
+					Type:                "bigquery-conversational-analytics",
+					Source:              "my-instance",
+					Description:         "some description",
+					AuthRequired:        []string{},
+					AllowedProjects:     []string{"agents-project"},
+					ValidateClientToken: true,
+				},
+			},
+		},
 	}
 	for _, tc := range tcs {
 		t.Run(tc.desc, func(t *testing.T) {
@@ -0,0 +1,127 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigqueryconversationalanalytics
+
+import (
+	"context"
+	"fmt"
+
+	bigqueryapi "cloud.google.com/go/bigquery"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	bqutil "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	bigqueryrestapi "google.golang.org/api/bigquery/v2"
+)
+
+// Modes of ValidateGeneratedSQL.
+const (
+	// generatedSQLParse validates the generated SQL with the tables found by
+	// parsing it.
+	generatedSQLParse = "parse"
+	// generatedSQLDryRun validates the generated SQL with the tables of its
+	// dry run, which also reports SQL that BigQuery would reject.
+	generatedSQLDryRun = "dryRun"
+)
+
+// Verdicts of the validation of generated SQL.
+const (
+	sqlVerdictAllowed    = "allowed"
+	sqlVerdictDisallowed = "disallowed"
+	// sqlVerdictUnverified is for SQL that could not be validated, e.g.
+	// because its dry run failed.
+	sqlVerdictUnverified = "unverified"
+)
+
+// sqlValidationKey is the key of the validation of the generated SQL in the
+// messages of the response, next to the "SQL Generated" key.
+const sqlValidationKey = "SQL Validation"
+
+// dryRunSource is implemented by the sources that can dry run the generated
+// SQL.
+type dryRunSource interface {
+	RetrieveClientAndService(context.Context, tools.AccessToken) (*bigqueryapi.Client, *bigqueryrestapi.Service, error)
+}
+
+// validateGeneratedSQLMode returns an error if mode is not a mode of
+// ValidateGeneratedSQL, or if source cannot run it.
+func validateGeneratedSQLMode(mode string, source compatibleSource) error {
+	switch mode {
+	case "", generatedSQLParse:
+		return nil
+	case generatedSQLDryRun:
+		if _, ok := source.(dryRunSource); !ok {
+			return fmt.Errorf("validateGeneratedSql %q requires a source that can dry run queries", mode)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid validateGeneratedSql %q: must be one of %q or %q", mode, generatedSQLParse, generatedSQLDryRun)
+	}
+}
+
+// generatedSQLValidator returns the function that validates the SQL
+// generated in the response of a chat in the project projectID against the
+// allowed datasets of source, or nil if the tool does not validate it.
+func (t Tool) generatedSQLValidator(ctx context.Context, source compatibleSource, accessToken tools.AccessToken, projectID string) func(sql string) map[string]any {
+	if t.ValidateGeneratedSQL == "" {
+		return nil
+	}
+	return func(sql string) map[string]any {
+		return t.validateGeneratedSQL(ctx, source, accessToken, projectID, sql)
+	}
+}
+
+// validateGeneratedSQL returns the validation of sql, with its "verdict", the
+// "datasets" that it is not allowed to access, a "message" explaining a
+// verdict other than allowed, and the "warnings" of the validation. The
+// validation never fails the chat.
+func (t Tool) validateGeneratedSQL(ctx context.Context, source compatibleSource, accessToken tools.AccessToken, projectID, sql string) map[string]any {
+	var dryRunJob *bigqueryrestapi.Job
+	if t.ValidateGeneratedSQL == generatedSQLDryRun {
+		bqClient, restService, err := source.(dryRunSource).RetrieveClientAndService(ctx, accessToken)
+		if err != nil {
+			return map[string]any{"verdict": sqlVerdictUnverified, "message": fmt.Sprintf("failed to retrieve BigQuery client: %s", err)}
+		}
+		dryRunJob, err = bqutil.DryRunQuery(ctx, restService, bqClient.Project(), bqClient.Location, sql, nil, "", nil, bqutil.JobOptions{})
+		if err != nil {
+			return map[string]any{"verdict": sqlVerdictUnverified, "message": fmt.Sprintf("dry run failed: %s", err)}
+		}
+	}
+
+	result, tbErr := bqutil.ValidateQueryAgainstAllowedDatasets(ctx, t.Name, source, accessToken, sql, projectID, dryRunJob)
+	if tbErr != nil {
+		validation := map[string]any{"verdict": sqlVerdictUnverified, "message": tbErr.Error()}
+		// the restrictions of the source are reported as permission errors
+		if info := tbErr.ErrorInfo(); info.Code == util.ErrorCodePermissionDenied {
+			validation["verdict"] = sqlVerdictDisallowed
+			if datasets, ok := info.Details["datasets"]; ok {
+				validation["datasets"] = datasets
+			}
+		}
+		return validation
+	}
+	validation := map[string]any{"verdict": sqlVerdictAllowed}
+	var warnings []bqutil.ValidationWarning
+	for _, w := range result.Warnings {
+		// without a dry run, the missing statistics are expected
+		if dryRunJob == nil && w.Reason == bqutil.WarningDryRunStatisticsMissing {
+			continue
+		}
+		warnings = append(warnings, w)
+	}
+	if len(warnings) > 0 {
+		validation["warnings"] = warnings
+	}
+	return validation
+}
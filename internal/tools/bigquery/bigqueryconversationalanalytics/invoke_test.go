@@ -0,0 +1,901 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigqueryconversationalanalytics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	bigqueryapi "cloud.google.com/go/bigquery"
+	yaml "github.com/goccy/go-yaml"
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/server"
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	bigqueryds "github.com/googleapis/genai-toolbox/internal/sources/bigquery"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+	"golang.org/x/oauth2"
+)
+
+type fakeSource struct {
+	mode            string
+	allowedProjects []string
+	location        string
+	// detectedLocation is the location detected from the allowed datasets.
+	detectedLocation string
+	// datasetLocations are the locations of the datasets, by
+	// "project.dataset". Other datasets cannot be looked up.
+	datasetLocations map[string]string
+	chatRateLimiter  *bigqueryds.ChatRateLimiter
+	// allowedDatasets are the allowed datasets, "project.dataset". If empty,
+	// all datasets are allowed.
+	allowedDatasets []string
+}
+
+func (s *fakeSource) SourceType() string                  { return "bigquery" }
+func (s *fakeSource) ToConfig() sources.SourceConfig      { return nil }
+func (s *fakeSource) BigQueryClient() *bigqueryapi.Client { return nil }
+func (s *fakeSource) BigQueryProject() string             { return "test-project" }
+func (s *fakeSource) BigQueryLocation() string            { return s.location }
+func (s *fakeSource) BigQueryDetectedLocation() string    { return s.detectedLocation }
+func (s *fakeSource) GetMaxQueryResultRows() int          { return 50 }
+func (s *fakeSource) UseClientAuthorization() bool        { return s.mode == "required" }
+func (s *fakeSource) ClientAuthorizationMode() string     { return s.mode }
+func (s *fakeSource) IsDatasetAllowed(projectID, datasetID string) bool {
+	return len(s.allowedDatasets) == 0 || slices.Contains(s.allowedDatasets, projectID+"."+datasetID)
+}
+func (s *fakeSource) BigQueryAllowedDatasets() []string { return s.allowedDatasets }
+func (s *fakeSource) BigQueryAllowedProjects() []string { return s.allowedProjects }
+func (s *fakeSource) WithProject(ctx context.Context, project string) (context.Context, error) {
+	if project != s.BigQueryProject() && !slices.Contains(s.allowedProjects, project) {
+		return nil, fmt.Errorf("project %q is not allowed", project)
+	}
+	return ctx, nil
+}
+func (s *fakeSource) DatasetLocation(ctx context.Context, accessToken tools.AccessToken, projectID, datasetID string) (string, error) {
+	if location, ok := s.datasetLocations[projectID+"."+datasetID]; ok {
+		return location, nil
+	}
+	return "", fmt.Errorf("dataset %s.%s not found", projectID, datasetID)
+}
+func (s *fakeSource) ChatRateLimiter() *bigqueryds.ChatRateLimiter { return s.chatRateLimiter }
+func (s *fakeSource) BigQueryTokenSourceWithScope(ctx context.Context, scopes []string) (oauth2.TokenSource, error) {
+	return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "adc-token"}), nil
+}
+
+type fakeSourceProvider struct {
+	source sources.Source
+}
+
+func (p fakeSourceProvider) GetSource(name string) (sources.Source, bool) {
+	return p.source, true
+}
+
+// newTestServer starts a stub of the chat endpoint and points the tool at it.
+func newTestServer(t *testing.T, handler http.HandlerFunc) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	originalURL := gdaBaseURL
+	gdaBaseURL = server.URL
+	t.Cleanup(func() { gdaBaseURL = originalURL })
+}
+
+func testParams() parameters.ParamValues {
+	return parameters.ParamValues{
+		{Name: "user_query_with_context", Value: "How many rows?"},
+		{Name: "table_references", Value: `[{"projectId": "p", "datasetId": "d", "tableId": "t"}]`},
+	}
+}
+
+func initTool(t *testing.T, cfg Config) (Tool, fakeSourceProvider) {
+	t.Helper()
+	cfg.Name, cfg.Type, cfg.Source, cfg.Description = "ask", resourceType, "src", "d"
+	source := &fakeSource{}
+	rawTool, err := cfg.Initialize(map[string]sources.Source{"src": source})
+	if err != nil {
+		t.Fatalf("unexpected error initializing tool: %s", err)
+	}
+	return rawTool.(Tool), fakeSourceProvider{source: source}
+}
+
+func TestInitializeRejectsReservedHeaders(t *testing.T) {
+	for _, name := range []string{"Authorization", "x-goog-user-project", "X-Goog-API-Client"} {
+		cfg := Config{Name: "ask", Type: resourceType, Source: "src", Description: "d", ExtraHeaders: map[string]string{name: "v"}}
+		if _, err := cfg.Initialize(map[string]sources.Source{"src": &fakeSource{}}); err == nil {
+			t.Errorf("expected extraHeaders with %q to be rejected at initialization", name)
+		}
+	}
+}
+
+func TestInvokeSendsExtraHeaders(t *testing.T) {
+	var got http.Header
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		fmt.Fprint(w, `[]`)
+	})
+
+	tool, provider := initTool(t, Config{
+		ExtraHeaders:    map[string]string{"X-Routing-Key": "team-a"},
+		APIClientSuffix: "partner/acme",
+	})
+	if _, tbErr := tool.Invoke(context.Background(), provider, testParams(), ""); tbErr != nil {
+		t.Fatalf("unexpected invoke error: %s", tbErr)
+	}
+
+	if v := got.Get("X-Routing-Key"); v != "team-a" {
+		t.Errorf("expected extra header to be sent, got %q", v)
+	}
+	if v, want := got.Get("X-Goog-API-Client"), util.GDAClientID+" partner/acme"; v != want {
+		t.Errorf("got X-Goog-API-Client %q, want %q", v, want)
+	}
+	if v := got.Get("Authorization"); v != "Bearer adc-token" {
+		t.Errorf("got Authorization %q, want the source credentials", v)
+	}
+}
+
+func TestPlanDoesNotSendRequest(t *testing.T) {
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL)
+	})
+
+	tool, provider := initTool(t, Config{ExtraHeaders: map[string]string{"X-Routing-Key": "team-a"}})
+	got, tbErr := tool.Plan(context.Background(), provider, testParams(), "")
+	if tbErr != nil {
+		t.Fatalf("unexpected plan error: %s", tbErr)
+	}
+	plan := got.(map[string]any)
+	if want := gdaBaseURL + "/projects/test-project/locations/us:chat"; plan["url"] != want {
+		t.Errorf("got url %v, want %q", plan["url"], want)
+	}
+	headers := plan["headers"].(map[string]string)
+	for _, name := range []string{"Authorization", "X-Routing-Key"} {
+		if headers[name] != parameters.RedactedValue {
+			t.Errorf("expected header %q to be redacted, got %q", name, headers[name])
+		}
+	}
+	payload := plan["payload"].(CAPayload)
+	if diff := cmp.Diff([]BQTableReference{{ProjectID: "p", DatasetID: "d", TableID: "t"}}, payload.InlineContext.DatasourceReferences.BQ.TableReferences); diff != "" {
+		t.Errorf("unexpected table references (-want +got):\n%s", diff)
+	}
+}
+
+func TestInvokeDataAgentContext(t *testing.T) {
+	var got map[string]any
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("unable to decode request body: %s", err)
+		}
+		fmt.Fprint(w, `[]`)
+	})
+	tool, provider := initTool(t, Config{})
+
+	tcs := []struct {
+		desc    string
+		args    map[string]any
+		want    map[string]any
+		wantErr string
+	}{
+		{
+			desc: "published by default",
+			args: map[string]any{"data_agent_id": "my-agent"},
+			want: map[string]any{"dataAgent": "projects/test-project/locations/global/dataAgents/my-agent", "contextVersion": "PUBLISHED"},
+		},
+		{
+			desc: "staging",
+			args: map[string]any{"data_agent_id": "my-agent", "context_version": "staging"},
+			want: map[string]any{"dataAgent": "projects/test-project/locations/global/dataAgents/my-agent", "contextVersion": "STAGING"},
+		},
+		{
+			desc:    "with table references",
+			args:    map[string]any{"data_agent_id": "my-agent", "table_references": `[{"projectId": "p", "datasetId": "d", "tableId": "t"}]`},
+			wantErr: "cannot be used with",
+		},
+		{
+			desc:    "without context",
+			args:    map[string]any{},
+			wantErr: "either 'table_references' or 'data_agent_id' is required",
+		},
+		{
+			desc:    "staging without data agent",
+			args:    map[string]any{"table_references": `[{"projectId": "p", "datasetId": "d", "tableId": "t"}]`, "context_version": "staging"},
+			wantErr: "requires 'data_agent_id'",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			got = nil
+			args := map[string]any{"user_query_with_context": "How many rows?"}
+			maps.Copy(args, tc.args)
+			params, err := parameters.ParseParams(tool.Parameters, args, nil)
+			if err != nil {
+				t.Fatalf("unexpected error parsing params: %s", err)
+			}
+			_, tbErr := tool.Invoke(context.Background(), provider, params, "")
+			if tc.wantErr != "" {
+				if tbErr == nil || !strings.Contains(tbErr.Error(), tc.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.wantErr, tbErr)
+				}
+				if got != nil {
+					t.Errorf("expected no request to be sent, got %v", got)
+				}
+				return
+			}
+			if tbErr != nil {
+				t.Fatalf("unexpected invoke error: %s", tbErr)
+			}
+			if diff := cmp.Diff(tc.want, got["dataAgentContext"]); diff != "" {
+				t.Errorf("unexpected dataAgentContext (-want +got):\n%s", diff)
+			}
+			if _, ok := got["inlineContext"]; ok {
+				t.Errorf("expected no inline context with a data agent, got %v", got["inlineContext"])
+			}
+		})
+	}
+}
+
+func TestInvokeRateLimit(t *testing.T) {
+	var calls int
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, `[]`)
+	})
+
+	sourceLimiter, err := bigqueryds.ChatRateLimitConfig{RequestsPerMinute: 1, MaxWait: "0s"}.NewLimiter("source/src")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	tcs := []struct {
+		desc   string
+		cfg    Config
+		source *fakeSource
+	}{
+		{desc: "tool limit", cfg: Config{RateLimit: &bigqueryds.ChatRateLimitConfig{RequestsPerMinute: 1, Burst: 2, MaxWait: "0s"}}, source: &fakeSource{}},
+		{desc: "source limit", cfg: Config{}, source: &fakeSource{chatRateLimiter: sourceLimiter}},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			calls = 0
+			cfg := tc.cfg
+			cfg.Name, cfg.Type, cfg.Source, cfg.Description = "ask", resourceType, "src", "d"
+			tool, err := cfg.Initialize(map[string]sources.Source{"src": tc.source})
+			if err != nil {
+				t.Fatalf("unexpected error initializing tool: %s", err)
+			}
+			provider := fakeSourceProvider{source: tc.source}
+
+			burst := 1
+			if cfg.RateLimit != nil {
+				burst = cfg.RateLimit.Burst
+			}
+			for i := 0; i < burst; i++ {
+				if _, tbErr := tool.Invoke(context.Background(), provider, testParams(), ""); tbErr != nil {
+					t.Fatalf("request %d of the burst: unexpected invoke error: %s", i, tbErr)
+				}
+			}
+			_, tbErr := tool.Invoke(context.Background(), provider, testParams(), "")
+			if tbErr == nil {
+				t.Fatalf("expected the request after the burst to be rate limited")
+			}
+			info := tbErr.ErrorInfo()
+			if info.Code != util.ErrorCodeRateLimited || !info.Retryable {
+				t.Errorf("got error info %+v, want a retryable rate_limited error", info)
+			}
+			if ms, _ := info.Details["retryAfterMs"].(int64); ms <= 0 || ms > 60000 {
+				t.Errorf("got retryAfterMs %v, want the time until the next request is let through", info.Details["retryAfterMs"])
+			}
+			if calls != burst {
+				t.Errorf("expected %d requests to reach the API, got %d", burst, calls)
+			}
+		})
+	}
+
+	if _, err := (Config{Name: "ask", Type: resourceType, Source: "src", Description: "d", RateLimit: &bigqueryds.ChatRateLimitConfig{}}).Initialize(map[string]sources.Source{"src": &fakeSource{}}); err == nil {
+		t.Errorf("expected a rateLimit without requestsPerMinute to be rejected")
+	}
+}
+
+func TestInvokeRetryAfterThrottlesRateLimit(t *testing.T) {
+	var calls atomic.Int32
+	throttled := make(chan struct{})
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			close(throttled)
+			return
+		}
+		fmt.Fprint(w, `[]`)
+	})
+
+	// The limiter lets every request through, until the API asks to wait.
+	tool, provider := initTool(t, Config{RateLimit: &bigqueryds.ChatRateLimitConfig{RequestsPerMinute: 6000, Burst: 10, MaxWait: "0s"}})
+	done := make(chan util.ToolboxError)
+	go func() {
+		_, tbErr := tool.Invoke(context.Background(), provider, testParams(), "")
+		done <- tbErr
+	}()
+	<-throttled
+	time.Sleep(100 * time.Millisecond)
+
+	// Other requests wait for the Retry-After instead of being sent to fail.
+	_, tbErr := tool.Invoke(context.Background(), provider, testParams(), "")
+	if tbErr == nil || tbErr.ErrorInfo().Code != util.ErrorCodeRateLimited {
+		t.Fatalf("expected a request during the Retry-After to be rate limited, got %v", tbErr)
+	}
+	if ms, _ := tbErr.ErrorInfo().Details["retryAfterMs"].(int64); ms <= 0 || ms > 1000 {
+		t.Errorf("got retryAfterMs %v, want the rest of the Retry-After", tbErr.ErrorInfo().Details["retryAfterMs"])
+	}
+
+	// The throttled request itself is retried without waiting for the
+	// limiter again.
+	if tbErr := <-done; tbErr != nil {
+		t.Fatalf("unexpected invoke error of the throttled request: %s", tbErr)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("expected the throttled request to be retried once, got %d calls", got)
+	}
+}
+
+func TestInvokeHonorsAuthTokenScheme(t *testing.T) {
+	var got http.Header
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		fmt.Fprint(w, `[]`)
+	})
+
+	cfg := Config{Name: "ask", Type: resourceType, Source: "src", Description: "d"}
+	source := &fakeSource{mode: "required"}
+	tool, err := tools.WithCommonOptions(cfg, tools.CommonOptions{
+		AuthTokenHeader: "X-Serverless-Authorization",
+		AuthTokenScheme: tools.AuthTokenSchemeRaw,
+	}).Initialize(map[string]sources.Source{"src": source})
+	if err != nil {
+		t.Fatalf("unexpected error initializing tool: %s", err)
+	}
+	provider := fakeSourceProvider{source: source}
+
+	header, err := tool.GetAuthTokenHeaderName(provider)
+	if err != nil || header != "X-Serverless-Authorization" {
+		t.Fatalf("got auth token header %q (%v), want the configured one", header, err)
+	}
+	if _, tbErr := tool.Invoke(context.Background(), provider, testParams(), "user-token"); tbErr != nil {
+		t.Fatalf("unexpected invoke error: %s", tbErr)
+	}
+	// the outbound request always uses a bearer token
+	if v := got.Get("Authorization"); v != "Bearer user-token" {
+		t.Errorf("got Authorization %q, want the client token", v)
+	}
+
+	if _, tbErr := tool.Invoke(context.Background(), provider, testParams(), "Bearer user-token"); tbErr == nil {
+		t.Errorf("expected a token with a scheme to be rejected by the raw scheme")
+	}
+}
+
+func TestInvokeClientAuthorizationModes(t *testing.T) {
+	var got string
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Authorization")
+		fmt.Fprint(w, `[]`)
+	})
+
+	tcs := []struct {
+		desc    string
+		mode    string
+		token   tools.AccessToken
+		want    string
+		wantErr bool
+	}{
+		{desc: "required with token", mode: "required", token: "Bearer user-token", want: "Bearer user-token"},
+		{desc: "required without token", mode: "required", token: "", wantErr: true},
+		{desc: "preferred with token", mode: "preferred", token: "Bearer user-token", want: "Bearer user-token"},
+		{desc: "preferred without token", mode: "preferred", token: "", want: "Bearer adc-token"},
+		{desc: "preferred with invalid token", mode: "preferred", token: "user-token", want: "Bearer adc-token"},
+		{desc: "disabled with token", mode: "disabled", token: "Bearer user-token", want: "Bearer adc-token"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			got = ""
+			cfg := Config{Name: "ask", Type: resourceType, Source: "src", Description: "d"}
+			source := &fakeSource{mode: tc.mode}
+			tool, err := cfg.Initialize(map[string]sources.Source{"src": source})
+			if err != nil {
+				t.Fatalf("unexpected error initializing tool: %s", err)
+			}
+			provider := fakeSourceProvider{source: source}
+
+			requires, err := tool.RequiresClientAuthorization(provider)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if want := tc.mode == "required"; requires != want {
+				t.Errorf("RequiresClientAuthorization() = %t, want %t", requires, want)
+			}
+
+			_, tbErr := tool.Invoke(context.Background(), provider, testParams(), tc.token)
+			if tc.wantErr {
+				if tbErr == nil {
+					t.Fatalf("expected invoke to fail")
+				}
+				return
+			}
+			if tbErr != nil {
+				t.Fatalf("unexpected invoke error: %s", tbErr)
+			}
+			if got != tc.want {
+				t.Errorf("got Authorization %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestInvokeSourceAllowedProjects(t *testing.T) {
+	var gotPath string
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		fmt.Fprint(w, `[]`)
+	})
+
+	tcs := []struct {
+		desc     string
+		project  string
+		wantPath string
+		wantErr  bool
+	}{
+		{desc: "default project", project: "", wantPath: "/projects/test-project/locations/us:chat"},
+		{desc: "allowed project", project: "tenant-a", wantPath: "/projects/tenant-a/locations/us:chat"},
+		{desc: "rejected project", project: "tenant-b", wantErr: true},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			gotPath = ""
+			cfg := Config{Name: "ask", Type: resourceType, Source: "src", Description: "d"}
+			source := &fakeSource{allowedProjects: []string{"tenant-a"}}
+			tool, err := cfg.Initialize(map[string]sources.Source{"src": source})
+			if err != nil {
+				t.Fatalf("unexpected error initializing tool: %s", err)
+			}
+			params := append(testParams(), parameters.ParamValue{Name: "project", Value: tc.project})
+			_, tbErr := tool.Invoke(context.Background(), fakeSourceProvider{source: source}, params, "")
+			if tc.wantErr {
+				if tbErr == nil {
+					t.Fatalf("expected invoke to fail")
+				}
+				if got := tbErr.ErrorInfo().Code; got != util.ErrorCodePermissionDenied {
+					t.Errorf("got error code %q, want %q", got, util.ErrorCodePermissionDenied)
+				}
+				if gotPath != "" {
+					t.Errorf("expected the API not to be called, got a call to %q", gotPath)
+				}
+				return
+			}
+			if tbErr != nil {
+				t.Fatalf("unexpected invoke error: %s", tbErr)
+			}
+			if gotPath != tc.wantPath {
+				t.Errorf("got path %q, want %q", gotPath, tc.wantPath)
+			}
+		})
+	}
+}
+
+func TestInvokeChecksDatasetLocations(t *testing.T) {
+	var gotPath string
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		fmt.Fprint(w, `[]`)
+	})
+
+	tcs := []struct {
+		desc         string
+		toolLocation string
+		location     string
+		detected     string
+		tableRefs    string
+		wantPath     string
+		wantErr      string
+	}{
+		{
+			desc:      "datasets in the location",
+			tableRefs: `[{"projectId": "p", "datasetId": "us_sales", "tableId": "orders"}]`,
+			wantPath:  "/projects/test-project/locations/us:chat",
+		},
+		{
+			desc:      "dataset in another region",
+			tableRefs: `[{"projectId": "p", "datasetId": "us_sales", "tableId": "orders"}, {"projectId": "p", "datasetId": "eu_sales", "tableId": "orders"}]`,
+			wantErr:   "in location 'us' cannot read the tables of datasets 'p.eu_sales' (europe-west1)",
+		},
+		{
+			desc:      "regional location",
+			location:  "europe-west1",
+			tableRefs: `[{"projectId": "p", "datasetId": "eu_sales", "tableId": "orders"}]`,
+			wantPath:  "/projects/test-project/locations/europe-west1:chat",
+		},
+		{
+			desc:         "location of the tool",
+			toolLocation: "europe-west1",
+			location:     "us",
+			tableRefs:    `[{"projectId": "p", "datasetId": "eu_sales", "tableId": "orders"}]`,
+			wantPath:     "/projects/test-project/locations/europe-west1:chat",
+		},
+		{
+			desc:      "location detected from the allowed datasets",
+			detected:  "europe-west1",
+			tableRefs: `[{"projectId": "p", "datasetId": "eu_sales", "tableId": "orders"}]`,
+			wantPath:  "/projects/test-project/locations/europe-west1:chat",
+		},
+		{
+			desc:      "location of the source before the detected location",
+			location:  "us",
+			detected:  "europe-west1",
+			tableRefs: `[{"projectId": "p", "datasetId": "us_sales", "tableId": "orders"}]`,
+			wantPath:  "/projects/test-project/locations/us:chat",
+		},
+		{
+			desc:      "unknown dataset is left to the API",
+			tableRefs: `[{"projectId": "p", "datasetId": "missing", "tableId": "orders"}]`,
+			wantPath:  "/projects/test-project/locations/us:chat",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			gotPath = ""
+			source := &fakeSource{location: tc.location, detectedLocation: tc.detected, datasetLocations: map[string]string{"p.us_sales": "US", "p.eu_sales": "europe-west1"}}
+			tool, err := Config{Name: "ask", Type: resourceType, Source: "src", Description: "d", Location: tc.toolLocation}.Initialize(map[string]sources.Source{"src": source})
+			if err != nil {
+				t.Fatalf("unexpected error initializing tool: %s", err)
+			}
+			params := parameters.ParamValues{
+				{Name: "user_query_with_context", Value: "How many orders?"},
+				{Name: "table_references", Value: tc.tableRefs},
+			}
+			_, tbErr := tool.Invoke(context.Background(), fakeSourceProvider{source: source}, params, "")
+			if tc.wantErr != "" {
+				if tbErr == nil || !strings.Contains(tbErr.Error(), tc.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.wantErr, tbErr)
+				}
+				if gotPath != "" {
+					t.Errorf("expected the API not to be called, got a call to %q", gotPath)
+				}
+				return
+			}
+			if tbErr != nil {
+				t.Fatalf("unexpected invoke error: %s", tbErr)
+			}
+			if gotPath != tc.wantPath {
+				t.Errorf("got path %q, want %q", gotPath, tc.wantPath)
+			}
+		})
+	}
+}
+
+// invalidatingSource caches the locations of datasets: invalidating them
+// looks up their current locations.
+type invalidatingSource struct {
+	*fakeSource
+	// current are the current locations of the datasets.
+	current     map[string]string
+	invalidated []string
+}
+
+func (s *invalidatingSource) InvalidateDataset(projectID, datasetID string) bool {
+	key := projectID + "." + datasetID
+	s.invalidated = append(s.invalidated, key)
+	s.datasetLocations[key] = s.current[key]
+	return true
+}
+
+func TestInvokeRetriesStaleDatasets(t *testing.T) {
+	var calls int
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			http.Error(w, `{"error": {"code": 404, "message": "Not found: Dataset p:sales", "status": "NOT_FOUND"}}`, http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `[]`)
+	})
+	params := parameters.ParamValues{
+		{Name: "user_query_with_context", Value: "How many orders?"},
+		{Name: "table_references", Value: `[{"projectId": "p", "datasetId": "sales", "tableId": "orders"}]`},
+	}
+	invoke := func(source sources.Source) util.ToolboxError {
+		t.Helper()
+		calls = 0
+		tool, err := Config{Name: "ask", Type: resourceType, Source: "src", Description: "d"}.Initialize(map[string]sources.Source{"src": source})
+		if err != nil {
+			t.Fatalf("unexpected error initializing tool: %s", err)
+		}
+		_, tbErr := tool.Invoke(context.Background(), fakeSourceProvider{source: source}, params, "")
+		return tbErr
+	}
+
+	t.Run("dataset created again in the location", func(t *testing.T) {
+		source := &invalidatingSource{
+			fakeSource: &fakeSource{datasetLocations: map[string]string{"p.sales": "US"}},
+			current:    map[string]string{"p.sales": "us-central1"},
+		}
+		if tbErr := invoke(source); tbErr != nil {
+			t.Fatalf("unexpected invoke error: %s", tbErr)
+		}
+		if calls != 2 {
+			t.Errorf("expected the chat to be retried once, got %d calls", calls)
+		}
+		if diff := cmp.Diff([]string{"p.sales"}, source.invalidated); diff != "" {
+			t.Errorf("unexpected invalidated datasets (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("dataset created again in another location", func(t *testing.T) {
+		source := &invalidatingSource{
+			fakeSource: &fakeSource{datasetLocations: map[string]string{"p.sales": "US"}},
+			current:    map[string]string{"p.sales": "europe-west1"},
+		}
+		tbErr := invoke(source)
+		if want := "cannot read the tables of datasets 'p.sales' (europe-west1)"; tbErr == nil || !strings.Contains(tbErr.Error(), want) {
+			t.Fatalf("expected error containing %q, got %v", want, tbErr)
+		}
+		if calls != 1 {
+			t.Errorf("expected the chat not to be retried, got %d calls", calls)
+		}
+	})
+
+	t.Run("source without cache", func(t *testing.T) {
+		tbErr := invoke(&fakeSource{datasetLocations: map[string]string{"p.sales": "US"}})
+		if tbErr == nil || tbErr.ErrorInfo().Code != util.ErrorCodeNotFound {
+			t.Fatalf("expected a not found error, got %v", tbErr)
+		}
+		if calls != 1 {
+			t.Errorf("expected the chat not to be retried, got %d calls", calls)
+		}
+	})
+}
+
+func TestInvokeChecksDataAgentDatasets(t *testing.T) {
+	agents := map[string]string{
+		"allowed": `{"dataAnalyticsAgent": {"publishedContext": {"datasourceReferences": {"bq": {"tableReferences": [{"projectId": "p", "datasetId": "sales", "tableId": "orders"}]}}}}}`,
+		"mixed":   `{"dataAnalyticsAgent": {"published_context": {"datasource_references": {"bq": {"table_references": [{"project_id": "p", "dataset_id": "sales", "table_id": "orders"}, {"project_id": "p", "dataset_id": "hr", "table_id": "salaries"}]}}}}}`,
+		"looker":  `{"dataAnalyticsAgent": {"publishedContext": {"datasourceReferences": {"looker": {"exploreReferences": [{"lookmlModel": "m", "explore": "e"}]}}}}}`,
+	}
+	var chats int
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			agent, ok := agents[r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprint(w, agent)
+			return
+		}
+		chats++
+		fmt.Fprint(w, `[]`)
+	})
+
+	tcs := []struct {
+		desc         string
+		mode         string
+		agent        string
+		wantErr      string
+		wantWarnings []string
+	}{
+		{desc: "unchecked", agent: "allowed", wantErr: "unless the tool sets checkDataAgentDatasets"},
+		{desc: "allowed", mode: "enforce", agent: "allowed"},
+		{desc: "mixed", mode: "enforce", agent: "mixed", wantErr: "the data agent uses datasets that are not in the allowed list: 'p.hr'"},
+		{
+			desc:         "mixed with warnings",
+			mode:         "warn",
+			agent:        "mixed",
+			wantWarnings: []string{"the data agent uses datasets that are not in the allowed list: 'p.hr'"},
+		},
+		{desc: "looker", mode: "enforce", agent: "looker"},
+		{desc: "missing", mode: "enforce", agent: "missing", wantErr: `failed to get data agent "projects/test-project/locations/global/dataAgents/missing"`},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			chats = 0
+			source := &fakeSource{allowedDatasets: []string{"p.sales"}}
+			tool, err := Config{Name: "ask", Type: resourceType, Source: "src", Description: "d", CheckDataAgentDatasets: tc.mode}.Initialize(map[string]sources.Source{"src": source})
+			if err != nil {
+				t.Fatalf("unexpected error initializing tool: %s", err)
+			}
+			params := parameters.ParamValues{
+				{Name: "user_query_with_context", Value: "How many orders?"},
+				{Name: "data_agent_id", Value: tc.agent},
+			}
+			res, tbErr := tool.Invoke(context.Background(), fakeSourceProvider{source: source}, params, "")
+			if tc.wantErr != "" {
+				if tbErr == nil || !strings.Contains(tbErr.Error(), tc.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.wantErr, tbErr)
+				}
+				if chats != 0 {
+					t.Errorf("expected no chat request, got %d", chats)
+				}
+				return
+			}
+			if tbErr != nil {
+				t.Fatalf("unexpected invoke error: %s", tbErr)
+			}
+			result := res.(tools.Result)
+			warnings, _ := result.Metadata["datasetWarnings"].([]string)
+			if diff := cmp.Diff(tc.wantWarnings, warnings); diff != "" {
+				t.Errorf("unexpected warnings (-want +got):\n%s", diff)
+			}
+			if result.IncludeMetadata != (len(tc.wantWarnings) > 0) {
+				t.Errorf("got IncludeMetadata %t, want it only with warnings", result.IncludeMetadata)
+			}
+		})
+	}
+
+	cfg := Config{Name: "ask", Type: resourceType, Source: "src", Description: "d", CheckDataAgentDatasets: "always"}
+	if _, err := cfg.Initialize(map[string]sources.Source{"src": &fakeSource{}}); err == nil {
+		t.Errorf("expected an invalid checkDataAgentDatasets to be rejected")
+	}
+}
+
+type otherSource struct{}
+
+func (otherSource) SourceType() string             { return "other" }
+func (otherSource) ToConfig() sources.SourceConfig { return nil }
+
+func TestInitializeIncompatibleSource(t *testing.T) {
+	cfg := Config{Name: "ask", Type: resourceType, Source: "src", Description: "d"}
+	_, err := cfg.Initialize(map[string]sources.Source{"src": otherSource{}, "bq": &fakeSource{}})
+	if err == nil {
+		t.Fatalf("expected an incompatible source to be rejected")
+	}
+	for _, want := range []string{`of type "other"`, "BigQueryProject", "compatible configured source types: bigquery"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+}
+
+func TestExpandedConfigRoundTrip(t *testing.T) {
+	t.Setenv("CA_TEST_TENANT", "tenant-a")
+	tools.RegisterSecretResolver("ca-test-secret", func(ctx context.Context, ref string) (string, error) {
+		return "team-" + strings.TrimPrefix(ref, "ca-test-secret://"), nil
+	})
+	in := `
+kind: tools
+name: ask
+type: bigquery-conversational-analytics
+source: src
+description: d
+allowedProjects:
+  - ${CA_TEST_TENANT}
+  - ${CA_TEST_OTHER:-tenant-b}
+extraHeaders:
+  X-Routing-Key: ca-test-secret://routing
+`
+	_, _, _, toolConfigs, _, _, err := server.UnmarshalResourceConfig(context.Background(), []byte(in))
+	if err != nil {
+		t.Fatalf("unable to unmarshal: %s", err)
+	}
+	rawTool, err := toolConfigs["ask"].Initialize(map[string]sources.Source{"src": &fakeSource{}})
+	if err != nil {
+		t.Fatalf("unexpected error initializing tool: %s", err)
+	}
+	got := rawTool.ToConfig().(Config)
+	if diff := cmp.Diff([]string{"tenant-a", "tenant-b"}, got.AllowedProjects); diff != "" {
+		t.Errorf("unexpected allowedProjects (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]string{"X-Routing-Key": "team-routing"}, got.ExtraHeaders); diff != "" {
+		t.Errorf("unexpected extraHeaders (-want +got):\n%s", diff)
+	}
+
+	// The exported config decodes to the same config.
+	out, err := yaml.Marshal(got)
+	if err != nil {
+		t.Fatalf("unable to marshal config: %s", err)
+	}
+	_, _, _, reloaded, _, _, err := server.UnmarshalResourceConfig(context.Background(), append([]byte("kind: tools\n"), out...))
+	if err != nil {
+		t.Fatalf("unable to unmarshal exported config: %s", err)
+	}
+	if diff := cmp.Diff(got, reloaded["ask"]); diff != "" {
+		t.Errorf("exported config does not round-trip (-want +got):\n%s", diff)
+	}
+
+	t.Setenv("CA_TEST_TENANT", "")
+	os.Unsetenv("CA_TEST_TENANT")
+	_, _, _, _, _, _, err = server.UnmarshalResourceConfig(context.Background(), []byte(in))
+	if err == nil || !strings.Contains(err.Error(), `field "allowedProjects[0]": environment variable "CA_TEST_TENANT" is not set`) {
+		t.Errorf("expected the unset variable to be reported with its field, got %v", err)
+	}
+}
+
+func TestInvokeValidatesGeneratedSQL(t *testing.T) {
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"systemMessage": {"data": {"generatedSql": "SELECT COUNT(*) FROM p.sales.orders"}}},
+			{"systemMessage": {"data": {"generatedSql": "SELECT * FROM p.sales.orders JOIN p.hr.salaries USING (id)"}}},
+			{"systemMessage": {"text": {"parts": ["There are 3 orders."]}}}
+		]`)
+	})
+	params := parameters.ParamValues{
+		{Name: "user_query_with_context", Value: "How many orders?"},
+		{Name: "table_references", Value: `[{"projectId": "p", "datasetId": "sales", "tableId": "orders"}]`},
+	}
+
+	tcs := []struct {
+		desc string
+		mode string
+		want []any
+	}{
+		{desc: "not validated", want: []any{nil, nil, nil}},
+		{
+			desc: "parse",
+			mode: "parse",
+			want: []any{
+				map[string]any{"verdict": "allowed", "warnings": []any{map[string]any{
+					"reason":      "parserFallback",
+					"identifiers": []any{"p.sales.orders"},
+					"message":     "the dry run of the query reported no tables, so its tables are found by parsing the query",
+				}}},
+				map[string]any{
+					"verdict":  "disallowed",
+					"datasets": []any{"p.hr"},
+					"message":  "query accesses dataset 'p.hr', which is not in the allowed list",
+				},
+				nil,
+			},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			source := &fakeSource{allowedDatasets: []string{"p.sales"}, datasetLocations: map[string]string{"p.sales": "US"}}
+			tool, err := Config{Name: "ask", Type: resourceType, Source: "src", Description: "d", ValidateGeneratedSQL: tc.mode}.Initialize(map[string]sources.Source{"src": source})
+			if err != nil {
+				t.Fatalf("unexpected error initializing tool: %s", err)
+			}
+			res, tbErr := tool.Invoke(context.Background(), fakeSourceProvider{source: source}, params, "")
+			if tbErr != nil {
+				t.Fatalf("unexpected invoke error: %s", tbErr)
+			}
+			// the response is returned even with disallowed SQL
+			decoder := json.NewDecoder(strings.NewReader(res.(tools.Result).Data.(string)))
+			var got []any
+			for decoder.More() {
+				var msg map[string]any
+				if err := decoder.Decode(&msg); err != nil {
+					t.Fatalf("unable to decode response: %s", err)
+				}
+				got = append(got, msg["SQL Validation"])
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected validations (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	for _, mode := range []string{"always", "dryRun"} {
+		cfg := Config{Name: "ask", Type: resourceType, Source: "src", Description: "d", ValidateGeneratedSQL: mode}
+		if _, err := cfg.Initialize(map[string]sources.Source{"src": &fakeSource{}}); err == nil {
+			t.Errorf("expected validateGeneratedSql %q to be rejected for a source that cannot dry run queries", mode)
+		}
+	}
+}
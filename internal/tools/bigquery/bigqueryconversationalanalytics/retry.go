@@ -0,0 +1,143 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigqueryconversationalanalytics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	bigqueryds "github.com/googleapis/genai-toolbox/internal/sources/bigquery"
+)
+
+// defaultMaxRetries is the number of times a throttled or failed request is
+// retried when the config does not set maxRetries.
+const defaultMaxRetries = 3
+
+// maxRetryAfter caps how long a single Retry-After header can delay a retry.
+const maxRetryAfter = 60 * time.Second
+
+// retryBaseBackoff is the initial backoff used when the API does not send a
+// Retry-After header. It doubles with every attempt. It can be overridden for testing.
+var retryBaseBackoff = time.Second
+
+// retryStats records how a request was retried.
+type retryStats struct {
+	Attempts          int
+	TotalBackoff      time.Duration
+	RetryAfterHonored bool
+	Latency           time.Duration
+}
+
+// meta returns the stats in the form included in tool results under `_meta`.
+func (s retryStats) meta() map[string]any {
+	return map[string]any{
+		"attempts":          s.Attempts,
+		"totalBackoffMs":    s.TotalBackoff.Milliseconds(),
+		"retryAfterHonored": s.RetryAfterHonored,
+		"latencyMs":         s.Latency.Milliseconds(),
+	}
+}
+
+// isRetryableStatus reports whether a response status indicates a transient
+// failure that is worth retrying.
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseRetryAfter parses a Retry-After header value, which is either a number
+// of seconds or an HTTP date. It reports false if the value is missing or invalid.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	d := date.Sub(now)
+	if d < 0 {
+		d = 0
+	}
+	return d, true
+}
+
+// doWithRetry sends the request built by newRequest, retrying transient
+// failures up to maxRetries times. Retry-After headers are honored, capped at
+// maxRetryAfter; otherwise an exponential backoff is used. The last response
+// is returned for the caller to handle, whatever its status.
+//
+// If limiter is not nil, the Retry-After of a throttled response also pauses
+// it, so that other requests wait for the API rather than fail the same way.
+// The retries themselves do not wait for the limiter.
+func doWithRetry(ctx context.Context, client *http.Client, maxRetries int, limiter *bigqueryds.ChatRateLimiter, newRequest func() (*http.Request, error)) (*http.Response, retryStats, error) {
+	var stats retryStats
+	start := time.Now()
+
+	backoff := retryBaseBackoff
+	for {
+		req, err := newRequest()
+		if err != nil {
+			stats.Latency = time.Since(start)
+			return nil, stats, err
+		}
+		stats.Attempts++
+		resp, err := client.Do(req)
+		if err != nil {
+			stats.Latency = time.Since(start)
+			return nil, stats, fmt.Errorf("failed to send request: %w", err)
+		}
+		if !isRetryableStatus(resp.StatusCode) || stats.Attempts > maxRetries {
+			stats.Latency = time.Since(start)
+			return resp, stats, nil
+		}
+
+		wait := backoff
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			wait = min(d, maxRetryAfter)
+			stats.RetryAfterHonored = true
+			if limiter != nil && resp.StatusCode == http.StatusTooManyRequests {
+				limiter.Throttle(wait)
+			}
+		}
+		resp.Body.Close()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			stats.Latency = time.Since(start)
+			return nil, stats, ctx.Err()
+		case <-timer.C:
+		}
+		stats.TotalBackoff += wait
+		backoff *= 2
+	}
+}
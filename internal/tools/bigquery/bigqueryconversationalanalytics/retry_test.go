@@ -0,0 +1,176 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigqueryconversationalanalytics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	"github.com/googleapis/genai-toolbox/internal/util"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	tcs := []struct {
+		desc   string
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{desc: "seconds", value: "7", want: 7 * time.Second, wantOK: true},
+		{desc: "zero seconds", value: "0", want: 0, wantOK: true},
+		{desc: "http date", value: "Wed, 01 Jan 2025 10:00:30 GMT", want: 30 * time.Second, wantOK: true},
+		{desc: "http date in the past", value: "Wed, 01 Jan 2025 09:59:00 GMT", want: 0, wantOK: true},
+		{desc: "empty", value: "", wantOK: false},
+		{desc: "negative seconds", value: "-3", wantOK: false},
+		{desc: "garbage", value: "soon", wantOK: false},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, ok := parseRetryAfter(tc.value, now)
+			if ok != tc.wantOK || got != tc.want {
+				t.Fatalf("parseRetryAfter(%q) = %v, %t; want %v, %t", tc.value, got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
+
+// throttlingHandler responds with 429 and the given Retry-After header for the
+// first `throttled` requests and succeeds afterwards.
+func throttlingHandler(throttled int, retryAfter string, calls *int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		if *calls <= throttled {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, `[{"systemMessage": {"text": {"parts": ["done"]}}}]`)
+	}
+}
+
+func TestInvokeRetryMetadata(t *testing.T) {
+	originalBackoff := retryBaseBackoff
+	retryBaseBackoff = time.Millisecond
+	t.Cleanup(func() { retryBaseBackoff = originalBackoff })
+
+	tcs := []struct {
+		desc           string
+		retryAfter     string
+		wantRetryAfter bool
+	}{
+		{desc: "seconds form", retryAfter: "0", wantRetryAfter: true},
+		{desc: "http date form", retryAfter: time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), wantRetryAfter: true},
+		{desc: "no header", retryAfter: "", wantRetryAfter: false},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			calls := 0
+			newTestServer(t, throttlingHandler(2, tc.retryAfter, &calls))
+			tool, provider := initTool(t, Config{IncludeRetryMetadata: true})
+
+			res, tbErr := tool.Invoke(context.Background(), provider, testParams(), "")
+			if tbErr != nil {
+				t.Fatalf("unexpected invoke error: %s", tbErr)
+			}
+			// includeRetryMetadata sends the metadata to all clients
+			data, meta := tools.UnwrapResult(res, false)
+			if data == "" {
+				t.Errorf("expected the API response to be included")
+			}
+			if meta == nil {
+				t.Fatalf("expected retry metadata, got %v", res)
+			}
+			if meta["attempts"] != 3 {
+				t.Errorf("expected 3 attempts, got %v", meta["attempts"])
+			}
+			if meta["retryAfterHonored"] != tc.wantRetryAfter {
+				t.Errorf("expected retryAfterHonored=%t, got %v", tc.wantRetryAfter, meta["retryAfterHonored"])
+			}
+			if _, ok := meta["latencyMs"].(int64); !ok {
+				t.Errorf("expected latencyMs in _meta, got %v", meta)
+			}
+		})
+	}
+}
+
+func TestInvokeRetriesExhausted(t *testing.T) {
+	calls := 0
+	newTestServer(t, throttlingHandler(10, "0", &calls))
+	maxRetries := 1
+	tool, provider := initTool(t, Config{MaxRetries: &maxRetries})
+
+	_, tbErr := tool.Invoke(context.Background(), provider, testParams(), "")
+	if tbErr == nil {
+		t.Fatalf("expected an error once retries are exhausted")
+	}
+	if info := tbErr.ErrorInfo(); info.Code != util.ErrorCodeRateLimited || !info.Retryable {
+		t.Fatalf("got error info %+v, want a retryable rate_limited error", info)
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 attempts with maxRetries=1, got %d", calls)
+	}
+}
+
+func TestInvokeOmitsMetadataByDefault(t *testing.T) {
+	calls := 0
+	newTestServer(t, throttlingHandler(0, "", &calls))
+	tool, provider := initTool(t, Config{})
+
+	res, tbErr := tool.Invoke(context.Background(), provider, testParams(), "")
+	if tbErr != nil {
+		t.Fatalf("unexpected invoke error: %s", tbErr)
+	}
+	data, meta := tools.UnwrapResult(res, false)
+	if _, ok := data.(string); !ok || meta != nil {
+		t.Fatalf("expected the plain response without metadata, got %v", res)
+	}
+	// clients can still request the metadata
+	if _, meta := tools.UnwrapResult(res, true); meta["attempts"] != 1 {
+		t.Fatalf("expected requested metadata with 1 attempt, got %v", meta)
+	}
+}
+
+func TestTranslateAPIError(t *testing.T) {
+	tcs := []struct {
+		desc          string
+		err           error
+		wantCode      util.ErrorCode
+		wantRetryable bool
+	}{
+		{desc: "bad request", err: &apiError{StatusCode: http.StatusBadRequest}, wantCode: util.ErrorCodeInvalidArgument},
+		{desc: "not found", err: &apiError{StatusCode: http.StatusNotFound}, wantCode: util.ErrorCodeNotFound},
+		{desc: "throttled", err: &apiError{StatusCode: http.StatusTooManyRequests}, wantCode: util.ErrorCodeRateLimited, wantRetryable: true},
+		{desc: "unavailable", err: &apiError{StatusCode: http.StatusServiceUnavailable}, wantCode: util.ErrorCodeBackendUnavailable, wantRetryable: true},
+		{desc: "not implemented", err: &apiError{StatusCode: http.StatusNotImplemented}, wantCode: util.ErrorCodeBackendUnavailable},
+		{desc: "request not sent", err: &url.Error{Op: "Post", URL: "https://example.com", Err: fmt.Errorf("connection refused")}, wantCode: util.ErrorCodeBackendUnavailable, wantRetryable: true},
+		{desc: "other", err: fmt.Errorf("failed to decode response"), wantCode: util.ErrorCodeInternal},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			info := translateAPIError(context.Background(), "tool", fmt.Errorf("wrapped: %w", tc.err)).ErrorInfo()
+			if info.Code != tc.wantCode || info.Retryable != tc.wantRetryable {
+				t.Fatalf("got error info %+v, want code %q and retryable %t", info, tc.wantCode, tc.wantRetryable)
+			}
+		})
+	}
+}
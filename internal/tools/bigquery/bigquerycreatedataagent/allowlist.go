@@ -0,0 +1,76 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycreatedataagent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	bigqueryapi "cloud.google.com/go/bigquery"
+	"google.golang.org/api/iterator"
+)
+
+// allowlistTables is the result of enumerating the tables of the source's
+// allowed datasets.
+type allowlistTables struct {
+	TableReferences []BQTableReference
+	// DatasetErrors maps `project.dataset` to the error hit while listing its
+	// tables. Failing datasets are skipped rather than aborting enumeration.
+	DatasetErrors map[string]string
+	// Truncated is set when enumeration stopped at the max tables limit.
+	Truncated bool
+}
+
+// tablesFromAllowlist lists the tables of every dataset in allowedDatasets,
+// given as `project.dataset`, stopping once maxTables tables were found.
+func tablesFromAllowlist(ctx context.Context, client *bigqueryapi.Client, allowedDatasets []string, maxTables int) allowlistTables {
+	datasets := append([]string(nil), allowedDatasets...)
+	sort.Strings(datasets)
+
+	result := allowlistTables{DatasetErrors: map[string]string{}}
+	for _, ds := range datasets {
+		projectID, datasetID, ok := strings.Cut(ds, ".")
+		if !ok {
+			result.DatasetErrors[ds] = "invalid dataset name, expected 'project.dataset'"
+			continue
+		}
+
+		var refs []BQTableReference
+		it := client.DatasetInProject(projectID, datasetID).Tables(ctx)
+		for {
+			table, err := it.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				result.DatasetErrors[ds] = fmt.Sprintf("failed to list tables: %s", err)
+				refs = nil
+				break
+			}
+			if len(result.TableReferences)+len(refs) >= maxTables {
+				result.Truncated = true
+				break
+			}
+			refs = append(refs, BQTableReference{ProjectID: table.ProjectID, DatasetID: table.DatasetID, TableID: table.TableID})
+		}
+		result.TableReferences = append(result.TableReferences, refs...)
+		if result.Truncated {
+			break
+		}
+	}
+	return result
+}
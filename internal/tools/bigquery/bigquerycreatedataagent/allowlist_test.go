@@ -0,0 +1,317 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycreatedataagent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	bigqueryapi "cloud.google.com/go/bigquery"
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	"github.com/googleapis/genai-toolbox/internal/testutils/gdafake"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+	"golang.org/x/oauth2"
+	bigqueryrestapi "google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/option"
+)
+
+var tablesListPath = regexp.MustCompile(`/projects/([^/]+)/datasets/([^/]+)/tables$`)
+
+// newTablesServer stubs the BigQuery tables.list API. Tables are keyed by
+// `project.dataset` and served one per page. Datasets listed in failing
+// respond with an error instead.
+func newTablesServer(t *testing.T, tables map[string][]string, failing ...string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m := tablesListPath.FindStringSubmatch(r.URL.Path)
+		if m == nil {
+			http.NotFound(w, r)
+			return
+		}
+		ds := m[1] + "." + m[2]
+		for _, f := range failing {
+			if f == ds {
+				http.Error(w, `{"error": {"code": 403, "message": "access denied"}}`, http.StatusForbidden)
+				return
+			}
+		}
+		var page int
+		if tok := r.URL.Query().Get("pageToken"); tok != "" {
+			fmt.Sscanf(tok, "page-%d", &page)
+		}
+		resp := map[string]any{}
+		if names := tables[ds]; page < len(names) {
+			resp["tables"] = []map[string]any{{
+				"tableReference": map[string]string{"projectId": m[1], "datasetId": m[2], "tableId": names[page]},
+			}}
+			if page+1 < len(names) {
+				resp["nextPageToken"] = fmt.Sprintf("page-%d", page+1)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestClient(t *testing.T, serverURL string) *bigqueryapi.Client {
+	t.Helper()
+	client, err := bigqueryapi.NewClient(context.Background(), "test-project", option.WithEndpoint(serverURL), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("unexpected error creating client: %s", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestTablesFromAllowlist(t *testing.T) {
+	tables := map[string][]string{
+		"p1.sales": {"orders", "customers"},
+		"p2.logs":  {"events"},
+	}
+	tcs := []struct {
+		desc      string
+		datasets  []string
+		failing   []string
+		maxTables int
+		want      allowlistTables
+	}{
+		{
+			desc:      "multiple datasets",
+			datasets:  []string{"p2.logs", "p1.sales"},
+			maxTables: 10,
+			want: allowlistTables{
+				TableReferences: []BQTableReference{
+					{ProjectID: "p1", DatasetID: "sales", TableID: "orders"},
+					{ProjectID: "p1", DatasetID: "sales", TableID: "customers"},
+					{ProjectID: "p2", DatasetID: "logs", TableID: "events"},
+				},
+				DatasetErrors: map[string]string{},
+			},
+		},
+		{
+			desc:      "failing dataset is reported",
+			datasets:  []string{"p1.sales", "p2.logs"},
+			failing:   []string{"p1.sales"},
+			maxTables: 10,
+			want: allowlistTables{
+				TableReferences: []BQTableReference{
+					{ProjectID: "p2", DatasetID: "logs", TableID: "events"},
+				},
+				DatasetErrors: map[string]string{"p1.sales": "failed to list tables"},
+			},
+		},
+		{
+			desc:      "truncated at max tables",
+			datasets:  []string{"p1.sales", "p2.logs"},
+			maxTables: 2,
+			want: allowlistTables{
+				TableReferences: []BQTableReference{
+					{ProjectID: "p1", DatasetID: "sales", TableID: "orders"},
+					{ProjectID: "p1", DatasetID: "sales", TableID: "customers"},
+				},
+				DatasetErrors: map[string]string{},
+				Truncated:     true,
+			},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			server := newTablesServer(t, tables, tc.failing...)
+			client := newTestClient(t, server.URL)
+
+			got := tablesFromAllowlist(context.Background(), client, tc.datasets, tc.maxTables)
+			// Only compare the prefix of error messages, the rest comes from the client library.
+			for ds, msg := range got.DatasetErrors {
+				if want, ok := tc.want.DatasetErrors[ds]; ok && strings.HasPrefix(msg, want) {
+					got.DatasetErrors[ds] = want
+				}
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("unexpected result (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+type fakeSource struct {
+	client          *bigqueryapi.Client
+	allowedDatasets []string
+	location        string
+	// datasetLocations are the locations of the datasets, by
+	// "project.dataset". Other datasets cannot be looked up.
+	datasetLocations map[string]string
+}
+
+func (s *fakeSource) SourceType() string                { return "bigquery" }
+func (s *fakeSource) ToConfig() sources.SourceConfig    { return nil }
+func (s *fakeSource) BigQueryProject() string           { return "test-project" }
+func (s *fakeSource) BigQueryLocation() string          { return s.location }
+func (s *fakeSource) UseClientAuthorization() bool      { return false }
+func (s *fakeSource) ClientAuthorizationMode() string   { return "disabled" }
+func (s *fakeSource) BigQueryAllowedDatasets() []string { return s.allowedDatasets }
+func (s *fakeSource) IsDatasetAllowed(projectID, datasetID string) bool {
+	if len(s.allowedDatasets) == 0 {
+		return true
+	}
+	for _, ds := range s.allowedDatasets {
+		if ds == projectID+"."+datasetID {
+			return true
+		}
+	}
+	return false
+}
+func (s *fakeSource) BigQueryTokenSourceWithScope(ctx context.Context, scopes []string) (oauth2.TokenSource, error) {
+	return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "adc-token"}), nil
+}
+func (s *fakeSource) DatasetLocation(ctx context.Context, accessToken tools.AccessToken, projectID, datasetID string) (string, error) {
+	if location, ok := s.datasetLocations[projectID+"."+datasetID]; ok {
+		return location, nil
+	}
+	return "", fmt.Errorf("dataset %s.%s not found", projectID, datasetID)
+}
+func (s *fakeSource) RetrieveClientAndService(context.Context, tools.AccessToken) (*bigqueryapi.Client, *bigqueryrestapi.Service, error) {
+	return s.client, nil, nil
+}
+
+type fakeSourceProvider struct {
+	source sources.Source
+}
+
+func (p fakeSourceProvider) GetSource(name string) (sources.Source, bool) {
+	return p.source, true
+}
+
+func TestInvokeUseSourceAllowlist(t *testing.T) {
+	tablesServer := newTablesServer(t, map[string][]string{"p1.sales": {"orders"}}, "p2.logs")
+
+	var gotURL string
+	var gotBody map[string]any
+	gdaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotURL = r.URL.String()
+		if err := json.NewDecoder(r.Body).Decode(&gotBody); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"name": "projects/test-project/locations/global/operations/op-1"}`))
+	}))
+	defer gdaServer.Close()
+	originalURL := gdaBaseURL
+	gdaBaseURL = gdaServer.URL
+	t.Cleanup(func() { gdaBaseURL = originalURL })
+
+	source := &fakeSource{client: newTestClient(t, tablesServer.URL), allowedDatasets: []string{"p1.sales", "p2.logs"}}
+	rawTool, err := Config{Name: "create", Type: resourceType, Source: "src", Description: "d"}.Initialize(map[string]sources.Source{"src": source})
+	if err != nil {
+		t.Fatalf("unexpected error initializing tool: %s", err)
+	}
+	params := parameters.ParamValues{
+		{Name: dataAgentIDKey, Value: "my-agent"},
+		{Name: useSourceAllowlistKey, Value: true},
+	}
+	res, tbErr := rawTool.(Tool).Invoke(context.Background(), fakeSourceProvider{source: source}, params, "")
+	if tbErr != nil {
+		t.Fatalf("unexpected invoke error: %s", tbErr)
+	}
+
+	if want := "/projects/test-project/locations/global/dataAgents?dataAgentId=my-agent"; gotURL != want {
+		t.Errorf("unexpected request URL: got %q, want %q", gotURL, want)
+	}
+	wantBody := map[string]any{
+		"dataAnalyticsAgent": map[string]any{
+			"publishedContext": map[string]any{
+				"datasourceReferences": map[string]any{
+					"bq": map[string]any{
+						"tableReferences": []any{
+							map[string]any{"projectId": "p1", "datasetId": "sales", "tableId": "orders"},
+						},
+					},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(wantBody, gotBody); diff != "" {
+		t.Errorf("unexpected request body (-want +got):\n%s", diff)
+	}
+
+	result := res.(map[string]any)
+	if _, ok := result["datasetErrors"].(map[string]string)["p2.logs"]; !ok {
+		t.Errorf("expected an error to be reported for dataset p2.logs, got %v", result["datasetErrors"])
+	}
+	if result["truncated"] != false {
+		t.Errorf("expected result not to be truncated")
+	}
+}
+
+func TestInvokeUseSourceAllowlistWithoutAllowedDatasets(t *testing.T) {
+	source := &fakeSource{}
+	rawTool, err := Config{Name: "create", Type: resourceType, Source: "src", Description: "d"}.Initialize(map[string]sources.Source{"src": source})
+	if err != nil {
+		t.Fatalf("unexpected error initializing tool: %s", err)
+	}
+	params := parameters.ParamValues{
+		{Name: dataAgentIDKey, Value: "my-agent"},
+		{Name: useSourceAllowlistKey, Value: true},
+	}
+	if _, tbErr := rawTool.(Tool).Invoke(context.Background(), fakeSourceProvider{source: source}, params, ""); tbErr == nil {
+		t.Fatalf("expected an error when the source has no allowed datasets")
+	}
+}
+
+func TestInvokeChecksDatasetLocations(t *testing.T) {
+	fake := gdafake.New(t)
+	originalURL := gdaBaseURL
+	gdaBaseURL = fake.URL
+	t.Cleanup(func() { gdaBaseURL = originalURL })
+
+	source := &fakeSource{location: "us", datasetLocations: map[string]string{"p.us_sales": "US", "p.eu_sales": "EU"}}
+	rawTool, err := Config{Name: "create", Type: resourceType, Source: "src", Description: "d"}.Initialize(map[string]sources.Source{"src": source})
+	if err != nil {
+		t.Fatalf("unexpected error initializing tool: %s", err)
+	}
+	invoke := func(tableRefs string) error {
+		params := parameters.ParamValues{
+			{Name: dataAgentIDKey, Value: "my-agent"},
+			{Name: tableReferencesKey, Value: tableRefs},
+		}
+		_, tbErr := rawTool.(Tool).Invoke(context.Background(), fakeSourceProvider{source: source}, params, "")
+		if tbErr != nil {
+			return tbErr
+		}
+		return nil
+	}
+
+	err = invoke(`[{"projectId": "p", "datasetId": "eu_sales", "tableId": "orders"}]`)
+	if err == nil || !strings.Contains(err.Error(), "cannot read the tables of datasets 'p.eu_sales' (EU)") {
+		t.Fatalf("expected a location error, got %v", err)
+	}
+	if paths := fake.Paths(); len(paths) != 0 {
+		t.Fatalf("expected the API not to be called, got %v", paths)
+	}
+	if err := invoke(`[{"projectId": "p", "datasetId": "us_sales", "tableId": "orders"}]`); err != nil {
+		t.Fatalf("unexpected invoke error: %s", err)
+	}
+	if _, ok := fake.DataAgent("projects/test-project/locations/us/dataAgents/my-agent"); !ok {
+		t.Fatalf("expected the data agent to be created, got requests to %v", fake.Paths())
+	}
+}
@@ -0,0 +1,382 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycreatedataagent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	bigqueryapi "cloud.google.com/go/bigquery"
+	yaml "github.com/goccy/go-yaml"
+	"github.com/googleapis/genai-toolbox/internal/embeddingmodels"
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	bqutil "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+	"github.com/googleapis/genai-toolbox/internal/tools/googlehttp"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+	"golang.org/x/oauth2"
+	bigqueryrestapi "google.golang.org/api/bigquery/v2"
+)
+
+const resourceType string = "bigquery-create-data-agent"
+
+const (
+	dataAgentIDKey        string = "data_agent_id"
+	descriptionKey        string = "description"
+	systemInstructionKey  string = "system_instruction"
+	tableReferencesKey    string = "table_references"
+	useSourceAllowlistKey string = "use_source_allowlist"
+)
+
+// dataAgentIDPattern restricts data agent IDs to the characters accepted by the
+// Gemini Data Analytics API, so that bad IDs are rejected before calling it.
+const (
+	dataAgentIDPattern            = `^[A-Za-z0-9][A-Za-z0-9_-]{0,62}$`
+	dataAgentIDPatternDescription = "1 to 63 letters, digits, hyphens or underscores, starting with a letter or digit"
+)
+
+// defaultDataAgentLocation is used when the source does not configure a location.
+const defaultDataAgentLocation = "global"
+
+// defaultMaxAllowlistTables bounds the number of tables enumerated from the
+// source's allowed datasets when maxAllowlistTables is not set.
+const defaultMaxAllowlistTables = 100
+
+// gdaBaseURL is the Gemini Data Analytics API endpoint. It can be overridden for testing.
+var gdaBaseURL = "https://geminidataanalytics.googleapis.com/v1beta"
+
+func init() {
+	if !tools.Register(resourceType, newConfig) {
+		panic(fmt.Sprintf("tool type %q already registered", resourceType))
+	}
+}
+
+func newConfig(ctx context.Context, name string, decoder *yaml.Decoder) (tools.ToolConfig, error) {
+	actual := Config{Name: name}
+	if err := decoder.DecodeContext(ctx, &actual); err != nil {
+		return nil, err
+	}
+	return actual, nil
+}
+
+type compatibleSource interface {
+	BigQueryTokenSourceWithScope(ctx context.Context, scopes []string) (oauth2.TokenSource, error)
+	BigQueryProject() string
+	BigQueryLocation() string
+	UseClientAuthorization() bool
+	ClientAuthorizationMode() string
+	IsDatasetAllowed(projectID, datasetID string) bool
+	BigQueryAllowedDatasets() []string
+	RetrieveClientAndService(context.Context, tools.AccessToken) (*bigqueryapi.Client, *bigqueryrestapi.Service, error)
+	DatasetLocation(ctx context.Context, accessToken tools.AccessToken, projectID, datasetID string) (string, error)
+}
+
+// BQTableReference identifies a BigQuery table used as a data agent datasource.
+type BQTableReference struct {
+	ProjectID string `json:"projectId"`
+	DatasetID string `json:"datasetId"`
+	TableID   string `json:"tableId"`
+}
+
+type Config struct {
+	Name         string                 `yaml:"name" validate:"required"`
+	Type         string                 `yaml:"type" validate:"required"`
+	Source       string                 `yaml:"source" validate:"required"`
+	Description  string                 `yaml:"description" validate:"required"`
+	AuthRequired []string               `yaml:"authRequired"`
+	Annotations  *tools.ToolAnnotations `yaml:"annotations,omitempty"`
+	// AllowedProjects lists the other projects data agents can be created in.
+	AllowedProjects []string `yaml:"allowedProjects" expand:"true"`
+	// MaxAllowlistTables bounds the number of tables used when the agent is
+	// seeded from the source's allowed datasets. Defaults to 100.
+	MaxAllowlistTables int `yaml:"maxAllowlistTables"`
+	// MaxWait bounds how long the tool waits for the creation to complete
+	// when the `wait` parameter is true, e.g. "2m". Defaults to 2m.
+	MaxWait string `yaml:"maxWait"`
+	// ExpandDescriptions expands the source properties referenced in the
+	// descriptions of the tool and its parameters, e.g. {{ .Source.Project }}.
+	ExpandDescriptions bool `yaml:"expandDescriptions"`
+}
+
+// validate interface
+var _ tools.ToolConfigWithFields = Config{}
+
+func (cfg Config) ToolConfigType() string {
+	return resourceType
+}
+
+// DeclaredFields returns the fields that policies can set.
+func (cfg Config) DeclaredFields() []string {
+	return []string{"allowedProjects"}
+}
+
+func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error) {
+	// verify source exists
+	rawS, ok := srcs[cfg.Source]
+	if !ok {
+		return nil, fmt.Errorf("no source named %q configured", cfg.Source)
+	}
+
+	// verify the source is compatible
+	s, ok := rawS.(compatibleSource)
+	if !ok {
+		return nil, tools.IncompatibleSourceError[compatibleSource](rawS, cfg.Source, resourceType, srcs)
+	}
+
+	if cfg.MaxAllowlistTables < 0 {
+		return nil, fmt.Errorf("invalid maxAllowlistTables %d for tool %q: must not be negative", cfg.MaxAllowlistTables, cfg.Name)
+	}
+	maxWait, err := bqutil.ParseOperationMaxWait(cfg.MaxWait)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config for tool %q: %w", cfg.Name, err)
+	}
+
+	tableRefsDescription := `A JSON string of a list of BigQuery tables the data agent can query. Each object in the list must contain 'projectId', 'datasetId', and 'tableId'. Example: '[{"projectId": "my-gcp-project", "datasetId": "my_dataset", "tableId": "my_table"}]'. Ignored if 'use_source_allowlist' is true.`
+	allowlistDescription := "If true, the data agent is created over the tables of the source's allowed datasets instead of 'table_references'."
+	if len(s.BigQueryAllowedDatasets()) == 0 {
+		allowlistDescription += " Not supported by this source, which has no allowed datasets configured."
+	}
+
+	params := parameters.Parameters{
+		parameters.WithExamples(parameters.NewStringParameterWithPattern(dataAgentIDKey, "The ID of the data agent to create.", dataAgentIDPattern, dataAgentIDPatternDescription), bqutil.ExampleDataAgentID),
+		bqutil.InitializeProjectParameter(s.BigQueryProject(), cfg.AllowedProjects, "The Google Cloud project ID to create the data agent in. Defaults to the project of the source."),
+		parameters.NewStringParameterWithDefault(descriptionKey, "", "A description of the data agent."),
+		parameters.NewStringParameterWithDefault(systemInstructionKey, "", "Instructions describing how the data agent should answer questions."),
+		parameters.NewStringParameterWithDefault(tableReferencesKey, "", tableRefsDescription),
+		parameters.NewBooleanParameterWithDefault(useSourceAllowlistKey, false, allowlistDescription),
+		bqutil.NewWaitParameter("If true, waits for the data agent to be created and returns it, or the status of the creation if it takes too long. If false, returns the long-running operation of the creation at once."),
+	}
+
+	description, err := bqutil.ExpandDescriptions(cfg.Name, cfg.ExpandDescriptions, rawS, cfg.Description, params)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Description = description
+
+	annotations := tools.GetAnnotationsOrDefault(cfg.Annotations, func() *tools.ToolAnnotations {
+		readOnly, destructive := false, false
+		return &tools.ToolAnnotations{ReadOnlyHint: &readOnly, DestructiveHint: &destructive}
+	})
+	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, params, annotations, nil)
+
+	// finish tool setup
+	t := Tool{
+		Config:      cfg,
+		Parameters:  params,
+		manifest:    tools.Manifest{Description: cfg.Description, Parameters: params.Manifest(), AuthRequired: cfg.AuthRequired},
+		mcpManifest: mcpManifest,
+		maxWait:     maxWait,
+	}
+	return t, nil
+}
+
+// validate interface
+var _ tools.Tool = Tool{}
+
+type Tool struct {
+	Config
+	Parameters  parameters.Parameters `yaml:"parameters"`
+	manifest    tools.Manifest
+	mcpManifest tools.McpManifest
+	maxWait     time.Duration
+}
+
+func (t Tool) ToConfig() tools.ToolConfig {
+	return t.Config
+}
+
+func (t Tool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, params parameters.ParamValues, accessToken tools.AccessToken) (any, util.ToolboxError) {
+	source, err := tools.GetCompatibleSource[compatibleSource](resourceMgr, t.Source, t.Name, t.Type)
+	if err != nil {
+		return nil, util.NewClientServerError("source used is not compatible with the tool", http.StatusInternalServerError, err)
+	}
+
+	mapParams := params.AsMap()
+	dataAgentID, ok := mapParams[dataAgentIDKey].(string)
+	if !ok || dataAgentID == "" {
+		return nil, util.NewAgentError(fmt.Sprintf("invalid or missing '%s' parameter; expected a non-empty string", dataAgentIDKey), nil)
+	}
+	description, _ := mapParams[descriptionKey].(string)
+	systemInstruction, _ := mapParams[systemInstructionKey].(string)
+	useAllowlist, _ := mapParams[useSourceAllowlistKey].(bool)
+	wait, _ := mapParams[bqutil.WaitKey].(bool)
+
+	requestedProject, _ := mapParams[bqutil.ProjectKey].(string)
+	projectID, err := bqutil.ResolveProject(requestedProject, source.BigQueryProject(), t.AllowedProjects)
+	if err != nil {
+		return nil, util.NewAgentError(fmt.Sprintf("invalid '%s' parameter", bqutil.ProjectKey), err)
+	}
+	location := source.BigQueryLocation()
+	if location == "" {
+		location = defaultDataAgentLocation
+	}
+
+	var tableRefs []BQTableReference
+	var seeded allowlistTables
+	if useAllowlist {
+		allowedDatasets := source.BigQueryAllowedDatasets()
+		if len(allowedDatasets) == 0 {
+			return nil, util.NewAgentError(fmt.Sprintf("'%s' requires the source to have allowedDatasets configured", useSourceAllowlistKey), nil)
+		}
+		bqClient, _, err := source.RetrieveClientAndService(ctx, accessToken)
+		if err != nil {
+			return nil, util.NewClientServerError("failed to retrieve BigQuery client", http.StatusInternalServerError, err)
+		}
+		maxTables := t.MaxAllowlistTables
+		if maxTables == 0 {
+			maxTables = defaultMaxAllowlistTables
+		}
+		seeded = tablesFromAllowlist(ctx, bqClient, allowedDatasets, maxTables)
+		if len(seeded.TableReferences) == 0 {
+			return nil, util.NewAgentError("no tables found in the source's allowed datasets", fmt.Errorf("dataset errors: %v", seeded.DatasetErrors))
+		}
+		tableRefs = seeded.TableReferences
+	} else {
+		tableRefsJSON, _ := mapParams[tableReferencesKey].(string)
+		if tableRefsJSON == "" {
+			return nil, util.NewAgentError(fmt.Sprintf("either '%s' or '%s' must be set", tableReferencesKey, useSourceAllowlistKey), nil)
+		}
+		if err := json.Unmarshal([]byte(tableRefsJSON), &tableRefs); err != nil {
+			return nil, util.NewAgentError(fmt.Sprintf("failed to parse '%s' JSON string", tableReferencesKey), err)
+		}
+		for _, tableRef := range tableRefs {
+			if !source.IsDatasetAllowed(tableRef.ProjectID, tableRef.DatasetID) {
+				return nil, bqutil.RestrictionError(ctx, t.Name, fmt.Sprintf("access to dataset '%s.%s' (from table '%s') is not allowed", tableRef.ProjectID, tableRef.DatasetID, tableRef.TableID), tableRef.ProjectID+"."+tableRef.DatasetID)
+			}
+		}
+	}
+
+	datasets := make([]string, 0, len(tableRefs))
+	for _, tableRef := range tableRefs {
+		datasets = append(datasets, tableRef.ProjectID+"."+tableRef.DatasetID)
+	}
+	if tbErr := bqutil.CheckDatasetLocations(ctx, t.Name, source, accessToken, location, datasets); tbErr != nil {
+		return nil, tbErr
+	}
+
+	// Get credentials for the API call
+	tokenStr, _, tbErr := bqutil.GetAPIToken(ctx, t.Name, source, accessToken, nil)
+	if tbErr != nil {
+		return nil, tbErr
+	}
+
+	agentContext := map[string]any{
+		"datasourceReferences": map[string]any{
+			"bq": map[string]any{"tableReferences": tableRefs},
+		},
+	}
+	if systemInstruction != "" {
+		agentContext["systemInstruction"] = systemInstruction
+	}
+	payload := map[string]any{
+		"dataAnalyticsAgent": map[string]any{"publishedContext": agentContext},
+	}
+	if description != "" {
+		payload["description"] = description
+	}
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return nil, util.NewClientServerError("failed to marshal request payload", http.StatusInternalServerError, err)
+	}
+
+	reqURL := fmt.Sprintf("%s/projects/%s/locations/%s/dataAgents?%s", gdaBaseURL, projectID, location, url.Values{"dataAgentId": {dataAgentID}}.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL, bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		return nil, util.NewClientServerError("failed to create request", http.StatusInternalServerError, err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", tokenStr))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Goog-API-Client", util.GDAClientID)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, util.NewClientServerError("failed to send request", http.StatusInternalServerError, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, util.NewClientServerError("failed to read response body", http.StatusInternalServerError, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		apiErr := fmt.Errorf("API returned non-200 status: %d %s", resp.StatusCode, string(body))
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			return nil, util.NewClientServerError(fmt.Sprintf("failed to create data agent %q", dataAgentID), resp.StatusCode, apiErr)
+		}
+		return nil, util.NewAgentError(fmt.Sprintf("failed to create data agent %q", dataAgentID), apiErr)
+	}
+
+	var operation any
+	if wait {
+		var op googlehttp.Operation
+		if err := json.Unmarshal(body, &op); err != nil {
+			return nil, util.NewClientServerError("failed to decode create data agent response", http.StatusInternalServerError, err)
+		}
+		operation, tbErr = bqutil.WaitForOperation(ctx, gdaBaseURL, tokenStr, op, t.maxWait, fmt.Sprintf("failed to create data agent %q", dataAgentID))
+		if tbErr != nil {
+			return nil, tbErr
+		}
+	} else if err := json.Unmarshal(body, &operation); err != nil {
+		return nil, util.NewClientServerError("failed to decode create data agent response", http.StatusInternalServerError, err)
+	}
+	if !useAllowlist {
+		return operation, nil
+	}
+	return map[string]any{
+		"operation":       operation,
+		"tableReferences": seeded.TableReferences,
+		"datasetErrors":   seeded.DatasetErrors,
+		"truncated":       seeded.Truncated,
+	}, nil
+}
+
+func (t Tool) EmbedParams(ctx context.Context, paramValues parameters.ParamValues, embeddingModelsMap map[string]embeddingmodels.EmbeddingModel) (parameters.ParamValues, error) {
+	return parameters.EmbedParams(ctx, t.Parameters, paramValues, embeddingModelsMap, nil)
+}
+
+func (t Tool) Manifest() tools.Manifest {
+	return t.manifest
+}
+
+func (t Tool) McpManifest() tools.McpManifest {
+	return t.mcpManifest
+}
+
+func (t Tool) Authorized(verifiedAuthServices []string) bool {
+	return tools.IsAuthorized(t.AuthRequired, verifiedAuthServices)
+}
+
+func (t Tool) RequiresClientAuthorization(resourceMgr tools.SourceProvider) (bool, error) {
+	source, err := tools.GetCompatibleSource[compatibleSource](resourceMgr, t.Source, t.Name, t.Type)
+	if err != nil {
+		return false, err
+	}
+	return source.UseClientAuthorization(), nil
+}
+
+func (t Tool) GetAuthTokenHeaderName(resourceMgr tools.SourceProvider) (string, error) {
+	return "Authorization", nil
+}
+
+func (t Tool) GetParameters() parameters.Parameters {
+	return t.Parameters
+}
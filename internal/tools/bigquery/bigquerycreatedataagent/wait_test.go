@@ -0,0 +1,114 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerycreatedataagent
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	"github.com/googleapis/genai-toolbox/internal/tools/googlehttp"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+)
+
+func TestInvokeWait(t *testing.T) {
+	const opName = "projects/test-project/locations/us/operations/op-1"
+	const agentName = "projects/test-project/locations/us/dataAgents/my-agent"
+	tcs := []struct {
+		desc    string
+		wait    bool
+		maxWait string
+		// final is the status of the operation when it is polled.
+		final   string
+		want    any
+		wantErr string
+	}{
+		{
+			desc: "without waiting",
+			want: map[string]any{"name": opName, "metadata": map[string]any{"verb": "create"}},
+		},
+		{
+			desc:  "created",
+			wait:  true,
+			final: fmt.Sprintf(`{"name": %q, "done": true, "response": {"name": %q}}`, opName, agentName),
+			want:  map[string]any{"name": agentName},
+		},
+		{
+			desc:    "operation error",
+			wait:    true,
+			final:   fmt.Sprintf(`{"name": %q, "done": true, "error": {"code": 6, "message": "data agent exists"}}`, opName),
+			wantErr: "ALREADY_EXISTS: data agent exists",
+		},
+		{
+			desc:    "timeout",
+			wait:    true,
+			maxWait: "10ms",
+			want:    googlehttp.Operation{Name: opName, Metadata: map[string]any{"verb": "create"}},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/dataAgents"):
+					fmt.Fprintf(w, `{"name": %q, "metadata": {"verb": "create"}}`, opName)
+				case r.Method == http.MethodGet && r.URL.Path == "/"+opName && tc.final != "":
+					fmt.Fprint(w, tc.final)
+				default:
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+			originalURL := gdaBaseURL
+			gdaBaseURL = server.URL
+			defer func() { gdaBaseURL = originalURL }()
+
+			source := &fakeSource{location: "us", datasetLocations: map[string]string{"p.d": "US"}}
+			rawTool, err := Config{Name: "create", Type: resourceType, Source: "src", Description: "d", MaxWait: tc.maxWait}.Initialize(map[string]sources.Source{"src": source})
+			if err != nil {
+				t.Fatalf("unexpected error initializing tool: %s", err)
+			}
+			tool := rawTool.(Tool)
+			args := map[string]any{dataAgentIDKey: "my-agent", tableReferencesKey: `[{"projectId": "p", "datasetId": "d", "tableId": "t"}]`, "wait": tc.wait}
+			params, err := parameters.ParseParams(tool.Parameters, args, nil)
+			if err != nil {
+				t.Fatalf("unexpected error parsing params: %s", err)
+			}
+			res, tbErr := tool.Invoke(context.Background(), fakeSourceProvider{source: source}, params, "")
+			if tc.wantErr != "" {
+				if tbErr == nil || !strings.Contains(tbErr.Error(), tc.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.wantErr, tbErr)
+				}
+				return
+			}
+			if tbErr != nil {
+				t.Fatalf("unexpected invoke error: %s", tbErr)
+			}
+			if diff := cmp.Diff(tc.want, res); diff != "" {
+				t.Errorf("unexpected result (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, err := (Config{Name: "create", Type: resourceType, Source: "src", Description: "d", MaxWait: "soon"}).Initialize(map[string]sources.Source{"src": &fakeSource{}}); err == nil {
+		t.Errorf("expected an invalid maxWait to be rejected")
+	}
+}
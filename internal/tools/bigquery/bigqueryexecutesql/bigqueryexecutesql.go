@@ -17,8 +17,10 @@ package bigqueryexecutesql
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 
 	bigqueryapi "cloud.google.com/go/bigquery"
@@ -35,6 +37,10 @@ import (
 
 const resourceType string = "bigquery-execute-sql"
 
+// confirmKey is the parameter that confirms destructive statements, added
+// with confirmDestructive.
+const confirmKey = "confirm"
+
 func init() {
 	if !tools.Register(resourceType, newConfig) {
 		panic(fmt.Sprintf("tool type %q already registered", resourceType))
@@ -50,22 +56,72 @@ func newConfig(ctx context.Context, name string, decoder *yaml.Decoder) (tools.T
 }
 
 type compatibleSource interface {
-	BigQueryClient() *bigqueryapi.Client
+	bqutil.ProjectOverrideSource
+	bqutil.SQLRedactionSource
 	BigQuerySession() bigqueryds.BigQuerySessionProvider
 	BigQueryWriteMode() string
 	UseClientAuthorization() bool
 	IsDatasetAllowed(projectID, datasetID string) bool
 	BigQueryAllowedDatasets() []string
-	RetrieveClientAndService(tools.AccessToken) (*bigqueryapi.Client, *bigqueryrestapi.Service, error)
-	RunSQL(context.Context, *bigqueryapi.Client, string, string, []bigqueryapi.QueryParameter, []*bigqueryapi.ConnectionProperty) (any, error)
+	BigQueryRestrictsConnections() bool
+	IsConnectionAllowed(string) bool
+	BigQueryRestrictsStorageURIs() bool
+	IsStorageURIAllowed(string) bool
+	RetrieveClientAndService(context.Context, tools.AccessToken) (*bigqueryapi.Client, *bigqueryrestapi.Service, error)
+	RunSQL(context.Context, *bigqueryapi.Client, string, string, []bigqueryapi.QueryParameter, []*bigqueryapi.ConnectionProperty, bigqueryds.JobOptions) (any, error)
+	BigQueryJobOptions(bigqueryds.JobOptions) bigqueryds.JobOptions
 }
 
 type Config struct {
-	Name         string   `yaml:"name" validate:"required"`
-	Type         string   `yaml:"type" validate:"required"`
-	Source       string   `yaml:"source" validate:"required"`
-	Description  string   `yaml:"description" validate:"required"`
-	AuthRequired []string `yaml:"authRequired"`
+	Name         string                 `yaml:"name" validate:"required"`
+	Type         string                 `yaml:"type" validate:"required"`
+	Source       string                 `yaml:"source" validate:"required"`
+	Description  string                 `yaml:"description" validate:"required"`
+	AuthRequired []string               `yaml:"authRequired"`
+	Annotations  *tools.ToolAnnotations `yaml:"annotations,omitempty"`
+	// JobLabels are added to the query jobs and dry runs of the tool, and
+	// override the defaultJobLabels of the source with the same keys.
+	JobLabels map[string]string `yaml:"jobLabels"`
+	// MaximumBytesBilled overrides the maximumBytesBilled of the source.
+	MaximumBytesBilled *int64 `yaml:"maximumBytesBilled"`
+	// QueryLabel is set as the query_label connection property of the jobs
+	// of the tool, as "key:value".
+	QueryLabel string `yaml:"queryLabel"`
+	// Priority is the priority of the query jobs of the tool, "interactive"
+	// or "batch".
+	Priority string `yaml:"priority"`
+	// UseQueryCache sets whether queries may be answered from cached results.
+	UseQueryCache *bool `yaml:"useQueryCache"`
+	// PinnedParameters hides the use_query_cache and priority parameters,
+	// so that the UseQueryCache and Priority of the tool always apply.
+	PinnedParameters []string `yaml:"pinnedParameters"`
+	// UseStorageReadAPI overrides the useStorageReadApi of the source.
+	UseStorageReadAPI *bool `yaml:"useStorageReadApi"`
+	// MaxColumns rejects queries whose result has more columns.
+	MaxColumns *int `yaml:"maxColumns"`
+	// MaxCellBytes rejects queries whose rows are estimated to be larger.
+	MaxCellBytes *int64 `yaml:"maxCellBytes"`
+	// ConfirmDestructive refuses to run statements that drop, truncate or
+	// delete all rows of tables, unless the confirm parameter is true.
+	ConfirmDestructive bool `yaml:"confirmDestructive"`
+	// ReportValidationWarnings logs the decisions of the validation against
+	// the allowed datasets that let queries through without fully verifying
+	// them, and adds them to the metadata of the results.
+	ReportValidationWarnings bool `yaml:"reportValidationWarnings"`
+	// ReportReferencedTables adds the tables that queries reference, with the
+	// allowed datasets they matched, to the metadata of the results.
+	ReportReferencedTables bool `yaml:"reportReferencedTables"`
+	// VerifyExecutedTables checks the tables that queries referenced when
+	// they ran against the allowed datasets again: "warn" logs and reports
+	// those outside of them, "strict" also fails the query. Disabled if empty.
+	VerifyExecutedTables string `yaml:"verifyExecutedTables"`
+	// AllowedStatementTypes restricts the statements that the tool runs to
+	// these types of the dry run, e.g. SELECT or CREATE_TABLE. Any statement
+	// runs if empty.
+	AllowedStatementTypes []string `yaml:"allowedStatementTypes"`
+	// ExpandDescriptions expands the source properties referenced in the
+	// descriptions of the tool and its parameters, e.g. {{ .Source.Project }}.
+	ExpandDescriptions bool `yaml:"expandDescriptions"`
 }
 
 // validate interface
@@ -76,6 +132,34 @@ func (cfg Config) ToolConfigType() string {
 }
 
 func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error) {
+	jobOptions, err := bqutil.ToolJobOptions(bqutil.JobConfig{
+		Labels:             cfg.JobLabels,
+		MaximumBytesBilled: cfg.MaximumBytesBilled,
+		UseStorageReadAPI:  cfg.UseStorageReadAPI,
+		QueryLabel:         cfg.QueryLabel,
+		Priority:           cfg.Priority,
+		UseQueryCache:      cfg.UseQueryCache,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration of tool %q: %w", cfg.Name, err)
+	}
+	if err := bqutil.ValidatePinnedParameters(cfg.PinnedParameters); err != nil {
+		return nil, fmt.Errorf("invalid configuration of tool %q: %w", cfg.Name, err)
+	}
+	resultLimits, err := bqutil.ToolResultLimits(cfg.MaxColumns, cfg.MaxCellBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration of tool %q: %w", cfg.Name, err)
+	}
+	allowedStatementTypes, err := bqutil.NormalizeStatementTypes(cfg.AllowedStatementTypes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration of tool %q: %w", cfg.Name, err)
+	}
+	switch cfg.VerifyExecutedTables {
+	case "", bqutil.ExecutedTablesWarn, bqutil.ExecutedTablesStrict:
+	default:
+		return nil, fmt.Errorf("invalid configuration of tool %q: verifyExecutedTables must be %q or %q, got %q", cfg.Name, bqutil.ExecutedTablesWarn, bqutil.ExecutedTablesStrict, cfg.VerifyExecutedTables)
+	}
+
 	// verify source exists
 	rawS, ok := srcs[cfg.Source]
 	if !ok {
@@ -85,7 +169,7 @@ func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error)
 	// verify the source is compatible
 	s, ok := rawS.(compatibleSource)
 	if !ok {
-		return nil, fmt.Errorf("invalid source for %q tool: source %q not compatible", resourceType, cfg.Source)
+		return nil, tools.IncompatibleSourceError[compatibleSource](rawS, cfg.Source, resourceType, srcs)
 	}
 
 	var sqlDescriptionBuilder strings.Builder
@@ -126,52 +210,116 @@ func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error)
 		"If set to true, the query will be validated and information about the execution will be returned "+
 			"without running the query. Defaults to false.",
 	)
-	params := parameters.Parameters{sqlParameter, dryRunParameter}
-	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, params, nil)
+	params := bqutil.AppendJobParameters(parameters.Parameters{sqlParameter, dryRunParameter}, cfg.PinnedParameters)
+	if cfg.ConfirmDestructive {
+		params = append(params, parameters.NewBooleanParameterWithDefault(
+			confirmKey,
+			false,
+			"Must be set to true to run statements that drop objects, truncate tables, or delete rows without a WHERE clause, "+
+				"after the user confirmed them. Defaults to false.",
+		))
+	}
+	params = bqutil.AppendProjectOverrideParameter(params, s)
+	description, err := bqutil.ExpandDescriptions(cfg.Name, cfg.ExpandDescriptions, rawS, cfg.Description, params)
+	if err != nil {
+		return nil, err
+	}
+	if len(allowedStatementTypes) > 0 {
+		// so that agents do not attempt other statements
+		description += " " + bqutil.StatementTypesDescription(allowedStatementTypes)
+	}
+	cfg.Description = description
+
+	annotations := tools.GetAnnotationsOrDefault(cfg.Annotations, func() *tools.ToolAnnotations {
+		switch s.BigQueryWriteMode() {
+		case bigqueryds.WriteModeBlocked:
+			return tools.NewReadOnlyAnnotations()
+		case bigqueryds.WriteModeProtected:
+			// Writes are limited to the session's temporary dataset.
+			readOnly, destructive := false, false
+			return &tools.ToolAnnotations{ReadOnlyHint: &readOnly, DestructiveHint: &destructive}
+		default: // WriteModeAllowed
+			return tools.NewDestructiveAnnotations()
+		}
+	})
+	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, params, annotations, nil)
 
 	// finish tool setup
 	t := Tool{
-		Config:      cfg,
-		Parameters:  params,
-		manifest:    tools.Manifest{Description: cfg.Description, Parameters: params.Manifest(), AuthRequired: cfg.AuthRequired},
-		mcpManifest: mcpManifest,
+		Config:         cfg,
+		jobOptions:     jobOptions,
+		resultLimits:   resultLimits,
+		statementTypes: allowedStatementTypes,
+		Parameters:     params,
+		manifest:       tools.Manifest{Description: cfg.Description, Parameters: params.Manifest(), AuthRequired: cfg.AuthRequired},
+		mcpManifest:    mcpManifest,
 	}
 	return t, nil
 }
 
 // validate interface
-var _ tools.Tool = Tool{}
+var _ tools.PlannableTool = Tool{}
+var _ tools.RedactingTool = Tool{}
 
 type Tool struct {
 	Config
-	Parameters  parameters.Parameters `yaml:"parameters"`
-	manifest    tools.Manifest
-	mcpManifest tools.McpManifest
+	Parameters   parameters.Parameters `yaml:"parameters"`
+	manifest     tools.Manifest
+	mcpManifest  tools.McpManifest
+	jobOptions   bigqueryds.JobOptions
+	resultLimits bqutil.ResultLimits
+	// statementTypes are the AllowedStatementTypes, in upper case.
+	statementTypes []string
 }
 
 func (t Tool) ToConfig() tools.ToolConfig {
 	return t.Config
 }
 
-func (t Tool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, params parameters.ParamValues, accessToken tools.AccessToken) (any, util.ToolboxError) {
+// query is a statement validated against the write mode and the allowed
+// datasets of the source by a dry run.
+type query struct {
+	source    compatibleSource
+	client    *bigqueryapi.Client
+	sql       string
+	connProps []*bigqueryapi.ConnectionProperty
+	jobOpts   bigqueryds.JobOptions
+	dryRunJob *bigqueryrestapi.Job
+	// restService looks up the child jobs of scripts that fail.
+	restService *bigqueryrestapi.Service
+	// storageURIs are the URIs of the external data read by the statement.
+	storageURIs []string
+	// warnings are the lenient decisions of the validation against the
+	// allowed datasets.
+	warnings []bqutil.ValidationWarning
+	// references are the tables of the statement, with the allowed datasets
+	// they matched.
+	references []bqutil.TableReference
+}
+
+// prepareQuery validates the statement of params with a dry run. It returns
+// the context to run it with, in the requested project.
+func (t Tool) prepareQuery(ctx context.Context, resourceMgr tools.SourceProvider, params parameters.ParamValues, accessToken tools.AccessToken) (context.Context, query, util.ToolboxError) {
 	source, err := tools.GetCompatibleSource[compatibleSource](resourceMgr, t.Source, t.Name, t.Type)
 	if err != nil {
-		return nil, util.NewClientServerError("source used is not compatible with the tool", http.StatusInternalServerError, err)
+		return nil, query{}, util.NewClientServerError("source used is not compatible with the tool", http.StatusInternalServerError, err)
 	}
 
 	paramsMap := params.AsMap()
 	sql, ok := paramsMap["sql"].(string)
 	if !ok {
-		return nil, util.NewAgentError(fmt.Sprintf("unable to cast sql parameter %s", paramsMap["sql"]), nil)
+		return nil, query{}, util.NewAgentError(fmt.Sprintf("unable to cast sql parameter %s", paramsMap["sql"]), nil)
 	}
-	dryRun, ok := paramsMap["dry_run"].(bool)
-	if !ok {
-		return nil, util.NewAgentError(fmt.Sprintf("unable to cast dry_run parameter %s", paramsMap["dry_run"]), nil)
+
+	ctx, tbErr := bqutil.WithProjectOverride(ctx, t.Name, source, paramsMap)
+	if tbErr != nil {
+		return nil, query{}, tbErr
 	}
 
-	bqClient, restService, err := source.RetrieveClientAndService(accessToken)
+	jobOpts := bqutil.ResolveJobOptions(source, t.jobOptions, bqutil.InvocationJobOptions(paramsMap))
+	bqClient, restService, err := source.RetrieveClientAndService(ctx, accessToken)
 	if err != nil {
-		return nil, util.NewClientServerError("failed to retrieve BigQuery client", http.StatusInternalServerError, err)
+		return nil, query{}, util.NewClientServerError("failed to retrieve BigQuery client", http.StatusInternalServerError, err)
 	}
 
 	var connProps []*bigqueryapi.ConnectionProperty
@@ -179,16 +327,16 @@ func (t Tool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, para
 	if source.BigQueryWriteMode() == bigqueryds.WriteModeProtected {
 		session, err = source.BigQuerySession()(ctx)
 		if err != nil {
-			return nil, util.NewClientServerError("failed to get BigQuery session for protected mode", http.StatusInternalServerError, err)
+			return nil, query{}, util.NewClientServerError("failed to get BigQuery session for protected mode", http.StatusInternalServerError, err)
 		}
 		connProps = []*bigqueryapi.ConnectionProperty{
 			{Key: "session_id", Value: session.ID},
 		}
 	}
 
-	dryRunJob, err := bqutil.DryRunQuery(ctx, restService, bqClient.Project(), bqClient.Location, sql, nil, connProps)
+	dryRunJob, err := bqutil.DryRunQuery(ctx, restService, bqClient.Project(), bqClient.Location, sql, nil, "", connProps, jobOpts)
 	if err != nil {
-		return nil, util.NewClientServerError("query validation failed", http.StatusInternalServerError, err)
+		return nil, query{}, bqutil.ProcessQueryError("query validation failed", source, err, sql)
 	}
 
 	statementType := dryRunJob.Statistics.Query.StatementType
@@ -196,68 +344,151 @@ func (t Tool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, para
 	switch source.BigQueryWriteMode() {
 	case bigqueryds.WriteModeBlocked:
 		if statementType != "SELECT" {
-			return nil, util.NewAgentError("write mode is 'blocked', only SELECT statements are allowed", nil)
+			return nil, query{}, bqutil.RestrictionError(ctx, t.Name, "write mode is 'blocked', only SELECT statements are allowed")
 		}
 	case bigqueryds.WriteModeProtected:
 		if dryRunJob.Configuration != nil && dryRunJob.Configuration.Query != nil {
 			if dest := dryRunJob.Configuration.Query.DestinationTable; dest != nil && dest.DatasetId != session.DatasetID {
-				return nil, util.NewAgentError(fmt.Sprintf("protected write mode only supports SELECT statements, or write operations in the anonymous "+
-					"dataset of a BigQuery session, but destination was %q", dest.DatasetId), nil)
+				return nil, query{}, util.NewAgentError(fmt.Sprintf("protected write mode only supports SELECT statements, or write operations in the anonymous "+
+					"dataset of a BigQuery session, but destination was %q", dest.DatasetId), nil).WithCode(util.ErrorCodePermissionDenied)
 			}
 		}
 	}
 
-	if len(source.BigQueryAllowedDatasets()) > 0 {
-		switch statementType {
-		case "CREATE_SCHEMA", "DROP_SCHEMA", "ALTER_SCHEMA":
-			return nil, util.NewAgentError(fmt.Sprintf("dataset-level operations like '%s' are not allowed when dataset restrictions are in place", statementType), nil)
-		case "CREATE_FUNCTION", "CREATE_TABLE_FUNCTION", "CREATE_PROCEDURE":
-			return nil, util.NewAgentError(fmt.Sprintf("creating stored routines ('%s') is not allowed when dataset restrictions are in place, as their contents cannot be safely analyzed", statementType), nil)
-		case "CALL":
-			return nil, util.NewAgentError(fmt.Sprintf("calling stored procedures ('%s') is not allowed when dataset restrictions are in place, as their contents cannot be safely analyzed", statementType), nil)
-		}
-
-		// Use a map to avoid duplicate table names.
-		tableIDSet := make(map[string]struct{})
+	if tbErr := bqutil.CheckStatementTypes(ctx, t.Name, t.statementTypes, sql, statementType); tbErr != nil {
+		return nil, query{}, tbErr
+	}
 
-		// Get all tables from the dry run result. This is the most reliable method.
-		queryStats := dryRunJob.Statistics.Query
-		if queryStats != nil {
-			for _, tableRef := range queryStats.ReferencedTables {
-				tableIDSet[fmt.Sprintf("%s.%s.%s", tableRef.ProjectId, tableRef.DatasetId, tableRef.TableId)] = struct{}{}
-			}
-			if tableRef := queryStats.DdlTargetTable; tableRef != nil {
-				tableIDSet[fmt.Sprintf("%s.%s.%s", tableRef.ProjectId, tableRef.DatasetId, tableRef.TableId)] = struct{}{}
+	validation, tbErr := bqutil.ValidateQueryAgainstAllowedDatasets(ctx, t.Name, source, accessToken, sql, bqClient.Project(), dryRunJob)
+	if tbErr != nil {
+		return nil, query{}, tbErr
+	}
+	if t.ReportValidationWarnings {
+		bqutil.LogValidationWarnings(ctx, t.Name, validation.Warnings)
+	}
+	refs, parseErr := bqutil.ParseSQL(ctx, sql, bqutil.ParseOptions{DefaultProjectID: bqClient.Project()})
+	if parseErr != nil && (source.BigQueryRestrictsConnections() || source.BigQueryRestrictsStorageURIs()) {
+		if tbErr := bqutil.ParseTimeoutError(parseErr); tbErr != nil {
+			return nil, query{}, tbErr
+		}
+	}
+	if source.BigQueryRestrictsConnections() {
+		// the dry run only reports the connections of the tables defined by
+		// the job, the parser finds those of the query
+		if parseErr != nil {
+			return nil, query{}, util.NewAgentError("could not parse connections from query to validate against allowed connections", parseErr)
+		}
+		var violations []string
+		for _, id := range slices.Concat(bqutil.ReferencedConnections(dryRunJob, bqClient.Project()), refs.ConnectionIDs) {
+			if !source.IsConnectionAllowed(id) && !slices.Contains(violations, id) {
+				violations = append(violations, id)
 			}
-			if tableRef := queryStats.DdlDestinationTable; tableRef != nil {
-				tableIDSet[fmt.Sprintf("%s.%s.%s", tableRef.ProjectId, tableRef.DatasetId, tableRef.TableId)] = struct{}{}
+		}
+		if len(violations) > 0 {
+			slices.Sort(violations)
+			msg := fmt.Sprintf("query uses connection '%s', which is not in the allowed list", violations[0])
+			if len(violations) > 1 {
+				msg = fmt.Sprintf("query uses connections '%s', which are not in the allowed list", strings.Join(violations, "', '"))
 			}
+			return nil, query{}, bqutil.RestrictionError(ctx, t.Name, msg)
+		}
+	}
+	// like connections, the dry run only reports the URIs of the tables
+	// defined by the job, the parser finds those of the statements
+	if parseErr != nil && source.BigQueryRestrictsStorageURIs() {
+		return nil, query{}, util.NewAgentError("could not parse storage URIs from query to validate against allowed storage URI prefixes", parseErr)
+	}
+	storageURIs := slices.Concat(bqutil.ReferencedStorageURIs(dryRunJob), refs.StorageURIs)
+	slices.Sort(storageURIs)
+	storageURIs = slices.Compact(storageURIs)
+	var uriViolations []string
+	for _, uri := range storageURIs {
+		if !source.IsStorageURIAllowed(uri) {
+			uriViolations = append(uriViolations, uri)
 		}
+	}
+	if len(uriViolations) > 0 {
+		msg := fmt.Sprintf("query reads external data from '%s', which is not under the allowed storage URI prefixes", uriViolations[0])
+		if len(uriViolations) > 1 {
+			msg = fmt.Sprintf("query reads external data from '%s', which are not under the allowed storage URI prefixes", strings.Join(uriViolations, "', '"))
+		}
+		return nil, query{}, bqutil.RestrictionError(ctx, t.Name, msg)
+	}
+	if tbErr := bqutil.CheckResultLimits(dryRunJob, t.resultLimits); tbErr != nil {
+		return nil, query{}, tbErr
+	}
 
-		var tableNames []string
-		if len(tableIDSet) > 0 {
-			for tableID := range tableIDSet {
-				tableNames = append(tableNames, tableID)
-			}
-		} else if statementType != "SELECT" {
-			// If dry run yields no tables, fall back to the parser for non-SELECT statements
-			// to catch unsafe operations like EXECUTE IMMEDIATE.
-			parsedTables, parseErr := bqutil.TableParser(sql, source.BigQueryClient().Project())
-			if parseErr != nil {
-				// If parsing fails (e.g., EXECUTE IMMEDIATE), we cannot guarantee safety, so we must fail.
-				return nil, util.NewAgentError("could not parse tables from query to validate against allowed datasets", parseErr)
-			}
-			tableNames = parsedTables
+	return ctx, query{
+		source:      source,
+		client:      bqClient,
+		restService: restService,
+		sql:         sql,
+		connProps:   connProps,
+		jobOpts:     jobOpts,
+		dryRunJob:   dryRunJob,
+		storageURIs: storageURIs,
+		warnings:    validation.Warnings,
+		references:  validation.References,
+	}, nil
+}
+
+// errExecutedTables fails the queries whose job referenced tables outside of
+// the allowed datasets, in the strict mode of VerifyExecutedTables.
+var errExecutedTables = errors.New("the executed query referenced tables outside of the allowed datasets")
+
+// checkDestructive returns an error listing the destructive statements of
+// the query, unless the invocation confirms them. The statements are found in
+// the SQL, as the dry runs of scripts do not report them.
+func (t Tool) checkDestructive(q query, paramsMap map[string]any) util.ToolboxError {
+	if confirm, _ := paramsMap[confirmKey].(bool); confirm {
+		return nil
+	}
+	statements := bqutil.DestructiveStatements(q.sql)
+	if len(statements) == 0 {
+		return nil
+	}
+	if q.source.BigQueryRedactSQL() {
+		for i, stmt := range statements {
+			statements[i] = bqutil.RedactSQL(stmt)
 		}
+	}
+	msg := fmt.Sprintf("the query has destructive statements, which only run when '%s' is true: %s. Confirm them with the user, then invoke the tool again with '%s' set to true", confirmKey, strings.Join(statements, "; "), confirmKey)
+	return util.NewAgentError(msg, nil).WithDetails(map[string]any{"destructiveStatements": statements})
+}
 
-		for _, tableID := range tableNames {
-			parts := strings.Split(tableID, ".")
-			if len(parts) == 3 {
-				projectID, datasetID := parts[0], parts[1]
-				if !source.IsDatasetAllowed(projectID, datasetID) {
-					return nil, util.NewAgentError(fmt.Sprintf("query accesses dataset '%s.%s', which is not in the allowed list", projectID, datasetID), nil)
-				}
-			}
+func (t Tool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, params parameters.ParamValues, accessToken tools.AccessToken) (any, util.ToolboxError) {
+	paramsMap := params.AsMap()
+	dryRun, ok := paramsMap["dry_run"].(bool)
+	if !ok {
+		return nil, util.NewAgentError(fmt.Sprintf("unable to cast dry_run parameter %s", paramsMap["dry_run"]), nil)
+	}
+
+	ctx, q, tbErr := t.prepareQuery(ctx, resourceMgr, params, accessToken)
+	if tbErr != nil {
+		return nil, tbErr
+	}
+	dryRunJob, sql := q.dryRunJob, q.sql
+	statementType := dryRunJob.Statistics.Query.StatementType
+
+	// metadata of the validation, sent to clients that request it
+	metadata := map[string]any{
+		"dryRun":              dryRun,
+		"statementType":       statementType,
+		"totalBytesProcessed": dryRunJob.Statistics.TotalBytesProcessed,
+	}
+	if len(q.storageURIs) > 0 {
+		metadata["storageUris"] = q.storageURIs
+	}
+	if t.ReportValidationWarnings && len(q.warnings) > 0 {
+		metadata["validationWarnings"] = q.warnings
+	}
+	if t.ReportReferencedTables && len(q.references) > 0 {
+		metadata["referencedTables"] = q.references
+	}
+
+	if !dryRun && t.ConfirmDestructive {
+		if tbErr := t.checkDestructive(q, paramsMap); tbErr != nil {
+			return nil, tbErr
 		}
 	}
 
@@ -267,23 +498,70 @@ func (t Tool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, para
 			if err != nil {
 				return nil, util.NewClientServerError("failed to marshal dry run job to JSON", http.StatusInternalServerError, err)
 			}
-			return string(jobJSON), nil
+			return tools.NewResult(string(jobJSON), metadata), nil
 		}
 		// This case should not be reached, but as a fallback, we return a message.
 		return "Dry run was requested, but no job information was returned.", nil
 	}
 
 	// Log the query executed for debugging.
-	logger, err := util.LoggerFromContext(ctx)
-	if err != nil {
+	if err := bqutil.LogQuery(ctx, q.source, resourceType, sql); err != nil {
 		return nil, util.NewClientServerError("error getting logger", http.StatusInternalServerError, err)
 	}
-	logger.DebugContext(ctx, fmt.Sprintf("executing `%s` tool query: %s", resourceType, sql))
-	resp, err := source.RunSQL(ctx, bqClient, sql, statementType, nil, connProps)
+	jobOpts := q.jobOpts
+	var disallowed []string
+	if t.VerifyExecutedTables != "" {
+		jobOpts.VerifyStatistics = func(ctx context.Context, stats *bigqueryapi.JobStatistics) error {
+			disallowed = bqutil.DisallowedExecutedTables(q.source, stats)
+			if len(disallowed) > 0 && t.VerifyExecutedTables == bqutil.ExecutedTablesStrict {
+				return errExecutedTables
+			}
+			return nil
+		}
+	}
+	resp, err := q.source.RunSQL(ctx, q.client, sql, statementType, nil, q.connProps, jobOpts)
+	if len(disallowed) > 0 {
+		msg := fmt.Sprintf("the executed query referenced tables outside of the allowed datasets, which its dry run did not: '%s'", strings.Join(disallowed, "', '"))
+		if t.VerifyExecutedTables == bqutil.ExecutedTablesStrict {
+			return nil, bqutil.RestrictionError(ctx, t.Name, msg)
+		}
+		if logger, logErr := util.LoggerFromContext(ctx); logErr == nil {
+			logger.WarnContext(ctx, fmt.Sprintf("tool %q: %s", t.Name, msg))
+		}
+		metadata["disallowedExecutedTables"] = disallowed
+	}
+	if err != nil {
+		return nil, bqutil.ProcessScriptError(ctx, "error running sql", q.source, q.restService, err, sql)
+	}
+	return tools.NewResult(resp, metadata), nil
+}
+
+// Plan validates the statement like Invoke, and returns the statistics of its
+// dry run, the tables it references and the URIs of the external data it
+// reads, without running it.
+func (t Tool) Plan(ctx context.Context, resourceMgr tools.SourceProvider, params parameters.ParamValues, accessToken tools.AccessToken) (any, util.ToolboxError) {
+	_, q, tbErr := t.prepareQuery(ctx, resourceMgr, params, accessToken)
+	if tbErr != nil {
+		return nil, tbErr
+	}
+	plan := bqutil.QueryPlan(q.sql, q.dryRunJob)
+	if len(q.storageURIs) > 0 {
+		plan["storageUris"] = q.storageURIs
+	}
+	if t.ReportValidationWarnings && len(q.warnings) > 0 {
+		plan["validationWarnings"] = q.warnings
+	}
+	return plan, nil
+}
+
+// RedactParams redacts the string literals of the SQL of the sql parameter if
+// the source redacts SQL.
+func (t Tool) RedactParams(resourceMgr tools.SourceProvider, params parameters.ParamValues) map[string]any {
+	source, err := tools.GetCompatibleSource[compatibleSource](resourceMgr, t.Source, t.Name, t.Type)
 	if err != nil {
-		return nil, util.NewClientServerError("error running sql", http.StatusInternalServerError, err)
+		return params.AsRedactedMap()
 	}
-	return resp, nil
+	return bqutil.RedactSQLParams(source, params, "sql")
 }
 
 func (t Tool) EmbedParams(ctx context.Context, paramValues parameters.ParamValues, embeddingModelsMap map[string]embeddingmodels.EmbeddingModel) (parameters.ParamValues, error) {
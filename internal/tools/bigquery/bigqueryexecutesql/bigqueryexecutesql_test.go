@@ -19,7 +19,10 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/googleapis/genai-toolbox/internal/server"
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	bigqueryds "github.com/googleapis/genai-toolbox/internal/sources/bigquery"
 	"github.com/googleapis/genai-toolbox/internal/testutils"
+	"github.com/googleapis/genai-toolbox/internal/tools"
 	"github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigqueryexecutesql"
 )
 
@@ -28,6 +31,10 @@ func TestParseFromYamlBigQueryExecuteSql(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %s", err)
 	}
+	readOnly := true
+	maximumBytesBilled := int64(1000000)
+	useStorageReadAPI := true
+	maxColumns, maxCellBytes := 50, int64(4096)
 	tcs := []struct {
 		desc string
 		in   string
@@ -52,6 +59,77 @@ func TestParseFromYamlBigQueryExecuteSql(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "with annotations",
+			in: `
+            kind: tools
+            name: example_tool
+            type: bigquery-execute-sql
+            source: my-instance
+            description: some description
+            annotations:
+              readOnlyHint: true
+            `,
+			want: server.ToolConfigs{
+				"example_tool": bigqueryexecutesql.Config{
+					Name:         "example_tool",
+					Type:         "bigquery-execute-sql",
+					Source:       "my-instance",
+					Description:  "some description",
+					AuthRequired: []string{},
+					Annotations:  &tools.ToolAnnotations{ReadOnlyHint: &readOnly},
+				},
+			},
+		},
+		{
+			desc: "with job options",
+			in: `
+            kind: tools
+            name: example_tool
+            type: bigquery-execute-sql
+            source: my-instance
+            description: some description
+            jobLabels:
+              team: data
+            maximumBytesBilled: 1000000
+            useStorageReadApi: true
+            `,
+			want: server.ToolConfigs{
+				"example_tool": bigqueryexecutesql.Config{
+					Name:               "example_tool",
+					Type:               "bigquery-execute-sql",
+					Source:             "my-instance",
+					Description:        "some description",
+					AuthRequired:       []string{},
+					JobLabels:          map[string]string{"team": "data"},
+					MaximumBytesBilled: &maximumBytesBilled,
+					UseStorageReadAPI:  &useStorageReadAPI,
+				},
+			},
+		},
+		{
+			desc: "with result limits",
+			in: `
+            kind: tools
+            name: example_tool
+            type: bigquery-execute-sql
+            source: my-instance
+            description: some description
+            maxColumns: 50
+            maxCellBytes: 4096
+            `,
+			want: server.ToolConfigs{
+				"example_tool": bigqueryexecutesql.Config{
+					Name:         "example_tool",
+					Type:         "bigquery-execute-sql",
+					Source:       "my-instance",
+					Description:  "some description",
+					AuthRequired: []string{},
+					MaxColumns:   &maxColumns,
+					MaxCellBytes: &maxCellBytes,
+				},
+			},
+		},
 	}
 	for _, tc := range tcs {
 		t.Run(tc.desc, func(t *testing.T) {
@@ -67,3 +145,63 @@ func TestParseFromYamlBigQueryExecuteSql(t *testing.T) {
 	}
 
 }
+
+func TestBigQueryExecuteSqlAnnotations(t *testing.T) {
+	readOnly := true
+	tcs := []struct {
+		desc            string
+		writeMode       string
+		annotations     *tools.ToolAnnotations
+		wantReadOnly    bool
+		wantDestructive bool
+	}{
+		{desc: "writes allowed", writeMode: bigqueryds.WriteModeAllowed, wantDestructive: true},
+		{desc: "writes protected", writeMode: bigqueryds.WriteModeProtected},
+		{desc: "writes blocked", writeMode: bigqueryds.WriteModeBlocked, wantReadOnly: true},
+		{
+			desc:         "configured annotations",
+			writeMode:    bigqueryds.WriteModeAllowed,
+			annotations:  &tools.ToolAnnotations{ReadOnlyHint: &readOnly},
+			wantReadOnly: true,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			src := &bigqueryds.Source{Config: bigqueryds.Config{WriteMode: tc.writeMode}}
+			cfg := bigqueryexecutesql.Config{Name: "execute_sql", Type: "bigquery-execute-sql", Source: "src", Description: "d", Annotations: tc.annotations}
+			tool, err := cfg.Initialize(map[string]sources.Source{"src": src})
+			if err != nil {
+				t.Fatalf("unable to initialize tool: %s", err)
+			}
+			got := tool.McpManifest().Annotations
+			if got.IsDestructive() != tc.wantDestructive {
+				t.Fatalf("unexpected destructiveHint in %+v", got)
+			}
+			if gotReadOnly := got.ReadOnlyHint != nil && *got.ReadOnlyHint; gotReadOnly != tc.wantReadOnly {
+				t.Fatalf("unexpected readOnlyHint in %+v", got)
+			}
+		})
+	}
+}
+
+func TestBigQueryExecuteSqlInvalidJobOptions(t *testing.T) {
+	zero, zeroColumns := int64(0), 0
+	tcs := []struct {
+		desc string
+		cfg  bigqueryexecutesql.Config
+	}{
+		{desc: "invalid label key", cfg: bigqueryexecutesql.Config{JobLabels: map[string]string{"Team": "data"}}},
+		{desc: "non-positive byte limit", cfg: bigqueryexecutesql.Config{MaximumBytesBilled: &zero}},
+		{desc: "non-positive column limit", cfg: bigqueryexecutesql.Config{MaxColumns: &zeroColumns}},
+		{desc: "non-positive cell bytes limit", cfg: bigqueryexecutesql.Config{MaxCellBytes: &zero}},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			src := &bigqueryds.Source{Config: bigqueryds.Config{WriteMode: bigqueryds.WriteModeAllowed}}
+			tc.cfg.Name, tc.cfg.Type, tc.cfg.Source, tc.cfg.Description = "execute_sql", "bigquery-execute-sql", "src", "d"
+			if _, err := tc.cfg.Initialize(map[string]sources.Source{"src": src}); err == nil {
+				t.Fatalf("expected the tool configuration to be rejected")
+			}
+		})
+	}
+}
@@ -0,0 +1,975 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigqueryexecutesql_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	bigqueryapi "cloud.google.com/go/bigquery"
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	bigqueryds "github.com/googleapis/genai-toolbox/internal/sources/bigquery"
+	"github.com/googleapis/genai-toolbox/internal/testutils"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	bqutil "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+	"github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigqueryexecutesql"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	bigqueryrestapi "google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/option"
+)
+
+type sourceProvider map[string]sources.Source
+
+func (p sourceProvider) GetSource(name string) (sources.Source, bool) {
+	s, ok := p[name]
+	return s, ok
+}
+
+// newDryRunSource returns a BigQuery source whose dry runs are answered by
+// handler.
+func newDryRunSource(t *testing.T, handler http.HandlerFunc) *bigqueryds.Source {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	ctx := context.Background()
+	client, err := bigqueryapi.NewClient(ctx, "my-project", option.WithEndpoint(server.URL), option.WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatalf("unable to create client: %s", err)
+	}
+	restService, err := bigqueryrestapi.NewService(ctx, option.WithEndpoint(server.URL), option.WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatalf("unable to create REST service: %s", err)
+	}
+	return &bigqueryds.Source{
+		Config:      bigqueryds.Config{Name: "my-bq", Type: "bigquery", Project: "my-project", WriteMode: bigqueryds.WriteModeAllowed},
+		Client:      client,
+		RestService: restService,
+	}
+}
+
+// recordSpans records the spans of the test with the OTel SDK.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	return recorder
+}
+
+func spanAttr(span sdktrace.ReadOnlySpan, key string) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if string(kv.Key) == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestInvocationSpans(t *testing.T) {
+	tcs := []struct {
+		desc        string
+		status      int
+		wantOutcome string
+		wantCode    util.ErrorCode
+	}{
+		{desc: "valid query", status: http.StatusOK, wantOutcome: "success"},
+		{desc: "invalid query", status: http.StatusBadRequest, wantOutcome: "error", wantCode: util.ErrorCodeInvalidArgument},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			recorder := recordSpans(t)
+			source := newDryRunSource(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				if tc.status != http.StatusOK {
+					_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{
+						"code":    tc.status,
+						"message": "Syntax error",
+						"errors":  []map[string]any{{"reason": "invalidQuery", "location": "q", "message": "Syntax error"}},
+					}})
+					return
+				}
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"statistics": map[string]any{
+						"totalBytesProcessed": "42",
+						"query":               map[string]any{"statementType": "SELECT"},
+					},
+				})
+			})
+			ctx, err := testutils.ContextWithNewLogger()
+			if err != nil {
+				t.Fatalf("unable to create logger: %s", err)
+			}
+			srcs := sourceProvider{"my-bq": source}
+			cfg := bigqueryexecutesql.Config{Name: "execute_sql", Type: "bigquery-execute-sql", Source: "my-bq", Description: "run sql"}
+			tool, err := cfg.Initialize(srcs)
+			if err != nil {
+				t.Fatalf("unable to initialize tool: %s", err)
+			}
+			params, err := parameters.ParseParams(tool.GetParameters(), map[string]any{"sql": "SELECT 1", "dry_run": true}, nil)
+			if err != nil {
+				t.Fatalf("unable to parse params: %s", err)
+			}
+
+			// the span of the server request is the parent of the invocation
+			ctx, parent := otel.Tracer("test").Start(ctx, "toolbox/server/tool/invoke")
+			_, tbErr := tools.InvokeWithTimeout(ctx, "execute_sql", tool, srcs, params, "")
+			parent.End()
+			if (tbErr != nil) != (tc.wantOutcome == "error") {
+				t.Fatalf("unexpected error: %v", tbErr)
+			}
+
+			spans := map[string]sdktrace.ReadOnlySpan{}
+			for _, s := range recorder.Ended() {
+				spans[s.Name()] = s
+			}
+			invocation, ok := spans[tools.InvocationSpanName("execute_sql")]
+			if !ok {
+				t.Fatalf("missing invocation span, got %v", spans)
+			}
+			dryRun, ok := spans[bqutil.DryRunSpanName]
+			if !ok {
+				t.Fatalf("missing dry run span, got %v", spans)
+			}
+			if invocation.Parent().SpanID() != parent.SpanContext().SpanID() {
+				t.Errorf("expected the invocation span to be a child of the request span")
+			}
+			if dryRun.Parent().SpanID() != invocation.SpanContext().SpanID() {
+				t.Errorf("expected the dry run span to be a child of the invocation span")
+			}
+
+			wantAttrs := map[string]attribute.Value{
+				"tool_name":       attribute.StringValue("execute_sql"),
+				"tool_type":       attribute.StringValue("bigquery-execute-sql"),
+				"source_name":     attribute.StringValue("my-bq"),
+				"auth_mode":       attribute.StringValue("none"),
+				"invocation_mode": attribute.StringValue(tools.InvocationModeInvoke),
+				"param_count":     attribute.IntValue(len(params)),
+				"outcome":         attribute.StringValue(tc.wantOutcome),
+			}
+			for key, want := range wantAttrs {
+				if got := spanAttr(invocation, key); got != want {
+					t.Errorf("unexpected %s attribute: got %v, want %v", key, got.Emit(), want.Emit())
+				}
+			}
+			for _, kv := range invocation.Attributes() {
+				if kv.Value.Emit() == "SELECT 1" {
+					t.Errorf("expected no parameter values in the span, got %s", kv.Key)
+				}
+			}
+			if tc.wantOutcome == "success" {
+				if invocation.Status().Code == codes.Error {
+					t.Errorf("unexpected error status: %v", invocation.Status())
+				}
+				if got := spanAttr(dryRun, "statement_type").AsString(); got != "SELECT" {
+					t.Errorf("unexpected statement type of the dry run: %q", got)
+				}
+				return
+			}
+			if invocation.Status().Code != codes.Error || invocation.Status().Description != string(tc.wantCode) {
+				t.Errorf("unexpected status: %v", invocation.Status())
+			}
+			if got := spanAttr(invocation, "error_code").AsString(); got != string(tc.wantCode) {
+				t.Errorf("unexpected error code: %q", got)
+			}
+			if dryRun.Status().Code != codes.Error {
+				t.Errorf("expected the failed dry run to have an error status")
+			}
+		})
+	}
+}
+
+func TestTempFunctionsWithAllowedDatasets(t *testing.T) {
+	tcs := []struct {
+		desc    string
+		sql     string
+		wantErr string
+	}{
+		{
+			desc: "sql temp function",
+			sql:  "CREATE TEMP FUNCTION f(x FLOAT64) RETURNS FLOAT64 AS (x * 2)",
+		},
+		{
+			desc:    "javascript temp function",
+			sql:     `CREATE TEMP FUNCTION f(x FLOAT64) RETURNS FLOAT64 LANGUAGE js AS "return x;"`,
+			wantErr: "JavaScript UDF 'f' cannot be analyzed when dataset restrictions are in place",
+		},
+		{
+			desc:    "stored function",
+			sql:     "CREATE FUNCTION my_dataset.f(x FLOAT64) RETURNS FLOAT64 AS (x * 2)",
+			wantErr: "creating stored routines ('CREATE_FUNCTION') is not allowed",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			source := newDryRunSource(t, func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"statistics": map[string]any{
+						"query": map[string]any{"statementType": "CREATE_FUNCTION"},
+					},
+				})
+			})
+			source.AllowedDatasets = map[string]struct{}{"my-project.my_dataset": {}}
+			srcs := sourceProvider{"my-bq": source}
+			cfg := bigqueryexecutesql.Config{Name: "execute_sql", Type: "bigquery-execute-sql", Source: "my-bq", Description: "run sql"}
+			tool, err := cfg.Initialize(srcs)
+			if err != nil {
+				t.Fatalf("unable to initialize tool: %s", err)
+			}
+			params, err := parameters.ParseParams(tool.GetParameters(), map[string]any{"sql": tc.sql, "dry_run": true}, nil)
+			if err != nil {
+				t.Fatalf("unable to parse params: %s", err)
+			}
+			_, tbErr := tools.InvokeWithTimeout(context.Background(), "execute_sql", tool, srcs, params, "")
+			if tc.wantErr == "" {
+				if tbErr != nil {
+					t.Fatalf("unexpected error: %s", tbErr)
+				}
+				return
+			}
+			if tbErr == nil || !strings.Contains(tbErr.Error(), tc.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantErr, tbErr)
+			}
+			if tbErr.Category() != util.CategoryAgent {
+				t.Fatalf("unexpected error category: %s", tbErr.Category())
+			}
+		})
+	}
+}
+
+func TestResultLimits(t *testing.T) {
+	// the dry run of SELECT * on a wide table
+	fields := make([]map[string]any, 400)
+	for i := range fields {
+		fields[i] = map[string]any{"name": fmt.Sprintf("col%d", i), "type": "STRING"}
+	}
+	source := newDryRunSource(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"statistics": map[string]any{
+				"query": map[string]any{"statementType": "SELECT", "schema": map[string]any{"fields": fields}},
+			},
+		})
+	})
+	srcs := sourceProvider{"my-bq": source}
+	maxColumns := 50
+	cfg := bigqueryexecutesql.Config{Name: "execute_sql", Type: "bigquery-execute-sql", Source: "my-bq", Description: "run sql", MaxColumns: &maxColumns}
+	tool, err := cfg.Initialize(srcs)
+	if err != nil {
+		t.Fatalf("unable to initialize tool: %s", err)
+	}
+	params, err := parameters.ParseParams(tool.GetParameters(), map[string]any{"sql": "SELECT * FROM my_dataset.wide", "dry_run": true}, nil)
+	if err != nil {
+		t.Fatalf("unable to parse params: %s", err)
+	}
+	_, tbErr := tools.InvokeWithTimeout(context.Background(), "execute_sql", tool, srcs, params, "")
+	want := "the result of the query would have 400 columns, more than the 50 allowed (400 columns: col0, col1,"
+	if tbErr == nil || !strings.Contains(tbErr.Error(), want) {
+		t.Fatalf("expected error containing %q, got %v", want, tbErr)
+	}
+	if tbErr.Category() != util.CategoryAgent {
+		t.Fatalf("unexpected error category: %s", tbErr.Category())
+	}
+}
+
+func TestAllowedConnections(t *testing.T) {
+	tcs := []struct {
+		desc string
+		sql  string
+		// tableDefinitions are reported by the configuration of the dry run
+		tableDefinitions map[string]any
+		datasetsOnly     bool
+		wantErr          string
+	}{
+		{
+			desc: "allowed external query",
+			sql:  "SELECT * FROM EXTERNAL_QUERY('my-project.us.sql_conn', 'SELECT * FROM customers')",
+		},
+		{
+			desc:    "external query with unlisted connection",
+			sql:     "SELECT * FROM EXTERNAL_QUERY('my-project.us.spanner_conn', 'SELECT * FROM customers')",
+			wantErr: "query uses connection 'my-project.us.spanner_conn', which is not in the allowed list",
+		},
+		{
+			desc:    "external table with unlisted connection",
+			sql:     "CREATE EXTERNAL TABLE my_dataset.ext WITH CONNECTION `us.lake_conn` OPTIONS (format = 'PARQUET', uris = ['gs://b/*'])",
+			wantErr: "query uses connection 'my-project.us.lake_conn', which is not in the allowed list",
+		},
+		{
+			desc:             "table definition with unlisted connection",
+			sql:              "SELECT * FROM ext",
+			tableDefinitions: map[string]any{"ext": map[string]any{"connectionId": "projects/my-project/locations/us/connections/lake_conn"}},
+			wantErr:          "query uses connection 'my-project.us.lake_conn', which is not in the allowed list",
+		},
+		{
+			desc:         "connections restricted by allowed datasets",
+			sql:          "SELECT * FROM EXTERNAL_QUERY('my-project.us.sql_conn', 'SELECT * FROM customers')",
+			datasetsOnly: true,
+			wantErr:      "query uses connection 'my-project.us.sql_conn', which is not in the allowed list",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			source := newDryRunSource(t, func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"configuration": map[string]any{
+						"query": map[string]any{"tableDefinitions": tc.tableDefinitions},
+					},
+					"statistics": map[string]any{
+						"query": map[string]any{
+							"statementType":    "SELECT",
+							"referencedTables": []map[string]any{{"projectId": "my-project", "datasetId": "my_dataset", "tableId": "t"}},
+						},
+					},
+				})
+			})
+			source.AllowedDatasets = map[string]struct{}{"my-project.my_dataset": {}}
+			if !tc.datasetsOnly {
+				source.AllowedConnections = map[string]struct{}{"my-project.us.sql_conn": {}}
+			}
+			srcs := sourceProvider{"my-bq": source}
+			cfg := bigqueryexecutesql.Config{Name: "execute_sql", Type: "bigquery-execute-sql", Source: "my-bq", Description: "run sql"}
+			tool, err := cfg.Initialize(srcs)
+			if err != nil {
+				t.Fatalf("unable to initialize tool: %s", err)
+			}
+			params, err := parameters.ParseParams(tool.GetParameters(), map[string]any{"sql": tc.sql, "dry_run": true}, nil)
+			if err != nil {
+				t.Fatalf("unable to parse params: %s", err)
+			}
+			_, tbErr := tools.InvokeWithTimeout(context.Background(), "execute_sql", tool, srcs, params, "")
+			if tc.wantErr == "" {
+				if tbErr != nil {
+					t.Fatalf("unexpected error: %s", tbErr)
+				}
+				return
+			}
+			if tbErr == nil || !strings.Contains(tbErr.Error(), tc.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantErr, tbErr)
+			}
+			if code := tbErr.ErrorInfo().Code; code != util.ErrorCodePermissionDenied {
+				t.Fatalf("unexpected error code: %s", code)
+			}
+		})
+	}
+}
+
+func TestConfirmDestructive(t *testing.T) {
+	tcs := []struct {
+		desc    string
+		sql     string
+		confirm bool
+		wantRun bool
+		wantErr string
+	}{
+		{desc: "delete with where", sql: "DELETE FROM my_dataset.users WHERE id = 1", wantRun: true},
+		{desc: "delete without where", sql: "DELETE FROM my_dataset.users", wantErr: "destructive statements, which only run when 'confirm' is true: DELETE FROM my_dataset.users."},
+		{
+			desc:    "script",
+			sql:     "DELETE FROM my_dataset.users WHERE id = 1; DROP TABLE my_dataset.orders; TRUNCATE TABLE my_dataset.events",
+			wantErr: "DROP TABLE my_dataset.orders; TRUNCATE TABLE my_dataset.events.",
+		},
+		{desc: "confirmed", sql: "DROP TABLE my_dataset.orders", confirm: true, wantRun: true},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			ran := false
+			source := newDryRunSource(t, func(w http.ResponseWriter, r *http.Request) {
+				ref := map[string]any{"projectId": "my-project", "jobId": "job", "location": "US"}
+				if r.Method == http.MethodGet {
+					_ = json.NewEncoder(w).Encode(map[string]any{"jobComplete": true, "jobReference": ref, "totalRows": "0"})
+					return
+				}
+				var job bigqueryrestapi.Job
+				_ = json.NewDecoder(r.Body).Decode(&job)
+				if !job.Configuration.DryRun {
+					ran = true
+					_ = json.NewEncoder(w).Encode(map[string]any{
+						"jobReference":  ref,
+						"configuration": map[string]any{"query": map[string]any{"query": tc.sql}},
+						"status":        map[string]any{"state": "DONE"},
+					})
+					return
+				}
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"statistics": map[string]any{"query": map[string]any{"statementType": "SCRIPT"}},
+				})
+			})
+			srcs := sourceProvider{"my-bq": source}
+			cfg := bigqueryexecutesql.Config{Name: "execute_sql", Type: "bigquery-execute-sql", Source: "my-bq", Description: "run sql", ConfirmDestructive: true}
+			tool, err := cfg.Initialize(srcs)
+			if err != nil {
+				t.Fatalf("unable to initialize tool: %s", err)
+			}
+			params, err := parameters.ParseParams(tool.GetParameters(), map[string]any{"sql": tc.sql, "confirm": tc.confirm}, nil)
+			if err != nil {
+				t.Fatalf("unable to parse params: %s", err)
+			}
+			ctx, err := testutils.ContextWithNewLogger()
+			if err != nil {
+				t.Fatalf("unable to create logger: %s", err)
+			}
+			_, tbErr := tools.InvokeWithTimeout(ctx, "execute_sql", tool, srcs, params, "")
+			if ran != tc.wantRun {
+				t.Errorf("got query run %t, want %t", ran, tc.wantRun)
+			}
+			if tc.wantErr == "" {
+				if tbErr != nil {
+					t.Fatalf("unexpected error: %s", tbErr)
+				}
+				return
+			}
+			if tbErr == nil || !strings.Contains(tbErr.Error(), tc.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantErr, tbErr)
+			}
+			if tbErr.Category() != util.CategoryAgent {
+				t.Errorf("unexpected error category: %s", tbErr.Category())
+			}
+		})
+	}
+}
+
+func TestAllowedStorageURIPrefixes(t *testing.T) {
+	tcs := []struct {
+		desc string
+		sql  string
+		// tableDefinitions are reported by the configuration of the dry run
+		tableDefinitions map[string]any
+		unrestricted     bool
+		wantURIs         []string
+		wantErr          string
+	}{
+		{
+			desc:     "allowed external table",
+			sql:      "CREATE EXTERNAL TABLE my_dataset.ext OPTIONS (format = 'PARQUET', uris = ['gs://lake/raw/*', 'gs://lake/raw/2026/*.parquet'])",
+			wantURIs: []string{"gs://lake/raw/*", "gs://lake/raw/2026/*.parquet"},
+		},
+		{
+			desc:    "external table outside of the prefixes",
+			sql:     "CREATE EXTERNAL TABLE my_dataset.ext OPTIONS (format = 'PARQUET', uris = ['gs://lake/raw/*', 'gs://other/*', 'gs://lake/*'])",
+			wantErr: "query reads external data from 'gs://lake/*', 'gs://other/*', which are not under the allowed storage URI prefixes",
+		},
+		{
+			desc:             "table definition outside of the prefixes",
+			sql:              "SELECT * FROM ext",
+			tableDefinitions: map[string]any{"ext": map[string]any{"sourceUris": []string{"gs://other/a.csv"}}},
+			wantErr:          "query reads external data from 'gs://other/a.csv', which is not under the allowed storage URI prefixes",
+		},
+		{
+			desc:    "unanalyzable uris",
+			sql:     "CREATE EXTERNAL TABLE my_dataset.ext OPTIONS (format = 'CSV', uris = @uris)",
+			wantErr: "could not parse storage URIs from query",
+		},
+		{
+			desc:         "unrestricted external table",
+			sql:          "CREATE EXTERNAL TABLE my_dataset.ext OPTIONS (format = 'PARQUET', uris = ['gs://other/*'])",
+			unrestricted: true,
+			wantURIs:     []string{"gs://other/*"},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			source := newDryRunSource(t, func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"configuration": map[string]any{
+						"query": map[string]any{"tableDefinitions": tc.tableDefinitions},
+					},
+					"statistics": map[string]any{
+						"query": map[string]any{"statementType": "CREATE_TABLE"},
+					},
+				})
+			})
+			if !tc.unrestricted {
+				source.AllowedStorageURIPrefixes = []string{"gs://lake/raw/"}
+			}
+			srcs := sourceProvider{"my-bq": source}
+			cfg := bigqueryexecutesql.Config{Name: "execute_sql", Type: "bigquery-execute-sql", Source: "my-bq", Description: "run sql"}
+			tool, err := cfg.Initialize(srcs)
+			if err != nil {
+				t.Fatalf("unable to initialize tool: %s", err)
+			}
+			params, err := parameters.ParseParams(tool.GetParameters(), map[string]any{"sql": tc.sql, "dry_run": true}, nil)
+			if err != nil {
+				t.Fatalf("unable to parse params: %s", err)
+			}
+			plan, tbErr := tool.(tools.PlannableTool).Plan(context.Background(), srcs, params, "")
+			if tc.wantErr != "" {
+				if tbErr == nil || !strings.Contains(tbErr.Error(), tc.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.wantErr, tbErr)
+				}
+				return
+			}
+			if tbErr != nil {
+				t.Fatalf("unexpected error: %s", tbErr)
+			}
+			if diff := cmp.Diff(tc.wantURIs, plan.(map[string]any)["storageUris"]); diff != "" {
+				t.Fatalf("unexpected storage URIs of the plan (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReportValidationWarnings(t *testing.T) {
+	// the dry run of a script reports no tables
+	source := newDryRunSource(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"statistics": map[string]any{
+				"query": map[string]any{"statementType": "SCRIPT"},
+			},
+		})
+	})
+	source.AllowedDatasets = map[string]struct{}{"my-project.my_dataset": {}}
+	srcs := sourceProvider{"my-bq": source}
+	sql := "CREATE TEMP TABLE staged AS SELECT * FROM my_dataset.orders; SELECT * FROM staged"
+
+	for _, report := range []bool{false, true} {
+		cfg := bigqueryexecutesql.Config{Name: "execute_sql", Type: "bigquery-execute-sql", Source: "my-bq", Description: "run sql", ReportValidationWarnings: report}
+		tool, err := cfg.Initialize(srcs)
+		if err != nil {
+			t.Fatalf("unable to initialize tool: %s", err)
+		}
+		params, err := parameters.ParseParams(tool.GetParameters(), map[string]any{"sql": sql, "dry_run": true}, nil)
+		if err != nil {
+			t.Fatalf("unable to parse params: %s", err)
+		}
+		res, tbErr := tools.InvokeWithTimeout(context.Background(), "execute_sql", tool, srcs, params, "")
+		if tbErr != nil {
+			t.Fatalf("unexpected error: %s", tbErr)
+		}
+		_, metadata := tools.UnwrapResult(res, true)
+		warnings, ok := metadata["validationWarnings"].([]bqutil.ValidationWarning)
+		if !report {
+			if ok {
+				t.Errorf("expected no warnings unless the tool reports them, got %+v", warnings)
+			}
+			continue
+		}
+		var reasons []string
+		for _, w := range warnings {
+			reasons = append(reasons, w.Reason)
+		}
+		want := []string{bqutil.WarningParserFallback, bqutil.WarningUnresolvedTables}
+		if !slices.Equal(reasons, want) {
+			t.Errorf("got warnings %+v, want reasons %v", warnings, want)
+		}
+	}
+}
+
+func TestReportReferencedTables(t *testing.T) {
+	source := newDryRunSource(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"statistics": map[string]any{
+				"query": map[string]any{
+					"statementType": "SELECT",
+					"referencedTables": []map[string]any{
+						{"projectId": "My-Project", "datasetId": "sales", "tableId": "orders"},
+						{"projectId": "other-project", "datasetId": "crm", "tableId": "customers"},
+						{"projectId": "my-project", "datasetId": "sales", "tableId": "orders"},
+					},
+				},
+			},
+		})
+	})
+	source.AllowedDatasets = map[string]struct{}{"my-project.sales": {}, "other-project.crm": {}}
+	srcs := sourceProvider{"my-bq": source}
+	sql := "SELECT * FROM sales.orders o JOIN `other-project.crm.customers` c ON o.customer_id = c.id"
+
+	for _, report := range []bool{false, true} {
+		cfg := bigqueryexecutesql.Config{Name: "execute_sql", Type: "bigquery-execute-sql", Source: "my-bq", Description: "run sql", ReportReferencedTables: report}
+		tool, err := cfg.Initialize(srcs)
+		if err != nil {
+			t.Fatalf("unable to initialize tool: %s", err)
+		}
+		params, err := parameters.ParseParams(tool.GetParameters(), map[string]any{"sql": sql, "dry_run": true}, nil)
+		if err != nil {
+			t.Fatalf("unable to parse params: %s", err)
+		}
+		res, tbErr := tools.InvokeWithTimeout(context.Background(), "execute_sql", tool, srcs, params, "")
+		if tbErr != nil {
+			t.Fatalf("unexpected error: %s", tbErr)
+		}
+		_, metadata := tools.UnwrapResult(res, true)
+		references, ok := metadata["referencedTables"].([]bqutil.TableReference)
+		if !report {
+			if ok {
+				t.Errorf("expected no referenced tables unless the tool reports them, got %+v", references)
+			}
+			continue
+		}
+		want := []bqutil.TableReference{
+			{Table: "my-project.sales.orders", AllowedDataset: "my-project.sales"},
+			{Table: "other-project.crm.customers", AllowedDataset: "other-project.crm"},
+		}
+		if diff := cmp.Diff(want, references); diff != "" {
+			t.Errorf("unexpected referenced tables (-want +got):\n%s", diff)
+		}
+	}
+}
+
+func TestVerifyExecutedTables(t *testing.T) {
+	// the dry run reports an allowed table, but the job also reads a table
+	// outside of the allowed datasets, e.g. through a view that changed
+	source := newDryRunSource(t, func(w http.ResponseWriter, r *http.Request) {
+		ref := map[string]any{"projectId": "my-project", "jobId": "job", "location": "US"}
+		job := map[string]any{
+			"jobReference":  ref,
+			"configuration": map[string]any{"query": map[string]any{"query": "SELECT * FROM my_dataset.report"}},
+			"status":        map[string]any{"state": "DONE"},
+			"statistics": map[string]any{"query": map[string]any{
+				"statementType": "SELECT",
+				"referencedTables": []map[string]any{
+					{"projectId": "my-project", "datasetId": "my_dataset", "tableId": "report"},
+					{"projectId": "my-project", "datasetId": "hr", "tableId": "salaries"},
+				},
+			}},
+		}
+		switch {
+		case r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/queries/"):
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"jobComplete":  true,
+				"jobReference": ref,
+				"schema":       map[string]any{"fields": []any{map[string]any{"name": "n", "type": "INTEGER"}}},
+				"rows":         []any{map[string]any{"f": []any{map[string]any{"v": "1"}}}},
+				"totalRows":    "1",
+			})
+		case r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(job)
+		default:
+			var req bigqueryrestapi.Job
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if req.Configuration.DryRun {
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"statistics": map[string]any{"query": map[string]any{
+						"statementType":    "SELECT",
+						"referencedTables": []map[string]any{{"projectId": "my-project", "datasetId": "my_dataset", "tableId": "report"}},
+					}},
+				})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(job)
+		}
+	})
+	source.AllowedDatasets = map[string]struct{}{"my-project.my_dataset": {}}
+	srcs := sourceProvider{"my-bq": source}
+
+	tcs := []struct {
+		mode         string
+		wantErr      string
+		wantMetadata any
+	}{
+		{mode: ""},
+		{mode: "warn", wantMetadata: []string{"my-project.hr.salaries"}},
+		{mode: "strict", wantErr: "referenced tables outside of the allowed datasets, which its dry run did not: 'my-project.hr.salaries'"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.mode, func(t *testing.T) {
+			cfg := bigqueryexecutesql.Config{Name: "execute_sql", Type: "bigquery-execute-sql", Source: "my-bq", Description: "run sql", VerifyExecutedTables: tc.mode}
+			tool, err := cfg.Initialize(srcs)
+			if err != nil {
+				t.Fatalf("unable to initialize tool: %s", err)
+			}
+			params, err := parameters.ParseParams(tool.GetParameters(), map[string]any{"sql": "SELECT * FROM my_dataset.report"}, nil)
+			if err != nil {
+				t.Fatalf("unable to parse params: %s", err)
+			}
+			ctx, err := testutils.ContextWithNewLogger()
+			if err != nil {
+				t.Fatalf("unable to create logger: %s", err)
+			}
+			res, tbErr := tools.InvokeWithTimeout(ctx, "execute_sql", tool, srcs, params, "")
+			if tc.wantErr != "" {
+				if tbErr == nil || !strings.Contains(tbErr.Error(), tc.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.wantErr, tbErr)
+				}
+				if code := tbErr.ErrorInfo().Code; code != util.ErrorCodePermissionDenied {
+					t.Fatalf("unexpected error code: %s", code)
+				}
+				return
+			}
+			if tbErr != nil {
+				t.Fatalf("unexpected error: %s", tbErr)
+			}
+			rows, metadata := tools.UnwrapResult(res, true)
+			if got, ok := rows.([]any); !ok || len(got) != 1 {
+				t.Errorf("expected the rows of the query, got %v", rows)
+			}
+			if diff := cmp.Diff(tc.wantMetadata, metadata["disallowedExecutedTables"]); diff != "" {
+				t.Errorf("unexpected disallowed tables (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	cfg := bigqueryexecutesql.Config{Name: "execute_sql", Type: "bigquery-execute-sql", Source: "my-bq", Description: "run sql", VerifyExecutedTables: "always"}
+	if _, err := cfg.Initialize(srcs); err == nil || !strings.Contains(err.Error(), "verifyExecutedTables") {
+		t.Errorf("expected an invalid configuration error, got %v", err)
+	}
+}
+
+func TestAllowedStatementTypes(t *testing.T) {
+	source := newDryRunSource(t, func(w http.ResponseWriter, r *http.Request) {
+		var job bigqueryrestapi.Job
+		_ = json.NewDecoder(r.Body).Decode(&job)
+		statementType := "SCRIPT"
+		if !strings.Contains(job.Configuration.Query.Query, ";") {
+			statementType = strings.ToUpper(strings.Fields(job.Configuration.Query.Query)[0])
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"statistics": map[string]any{"query": map[string]any{"statementType": statementType}},
+		})
+	})
+	srcs := sourceProvider{"my-bq": source}
+	cfg := bigqueryexecutesql.Config{Name: "execute_sql", Type: "bigquery-execute-sql", Source: "my-bq", Description: "run sql", AllowedStatementTypes: []string{"select"}}
+	tool, err := cfg.Initialize(srcs)
+	if err != nil {
+		t.Fatalf("unable to initialize tool: %s", err)
+	}
+	if desc := tool.Manifest().Description; !strings.HasSuffix(desc, "This tool only runs statements of the types SELECT; other statements are rejected.") {
+		t.Errorf("expected the description to name the allowed statement types, got %q", desc)
+	}
+
+	tcs := []struct {
+		desc    string
+		sql     string
+		wantErr string
+	}{
+		{desc: "select", sql: "SELECT 1"},
+		{desc: "delete", sql: "DELETE FROM my_dataset.t WHERE TRUE", wantErr: "statement type 'DELETE' is not allowed by this tool, which only runs statements of the types SELECT"},
+		{desc: "script of selects", sql: "SELECT 1; SELECT 2"},
+		{desc: "script with a later update", sql: "SELECT 1; UPDATE my_dataset.t SET x = 1 WHERE TRUE", wantErr: "statement type 'UPDATE' is not allowed"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			params, err := parameters.ParseParams(tool.GetParameters(), map[string]any{"sql": tc.sql, "dry_run": true}, nil)
+			if err != nil {
+				t.Fatalf("unable to parse params: %s", err)
+			}
+			_, tbErr := tools.InvokeWithTimeout(context.Background(), "execute_sql", tool, srcs, params, "")
+			if tc.wantErr == "" {
+				if tbErr != nil {
+					t.Fatalf("unexpected error: %s", tbErr)
+				}
+				return
+			}
+			if tbErr == nil || !strings.Contains(tbErr.Error(), tc.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantErr, tbErr)
+			}
+			if code := tbErr.ErrorInfo().Code; code != util.ErrorCodePermissionDenied {
+				t.Fatalf("unexpected error code: %s", code)
+			}
+		})
+	}
+}
+
+func TestJobParameters(t *testing.T) {
+	var mu sync.Mutex
+	var dryRun, run *bigqueryrestapi.JobConfigurationQuery
+	source := newDryRunSource(t, func(w http.ResponseWriter, r *http.Request) {
+		ref := map[string]any{"projectId": "my-project", "jobId": "job", "location": "US"}
+		if r.Method == http.MethodGet {
+			_ = json.NewEncoder(w).Encode(map[string]any{"jobComplete": true, "jobReference": ref, "totalRows": "0"})
+			return
+		}
+		var job bigqueryrestapi.Job
+		_ = json.NewDecoder(r.Body).Decode(&job)
+		mu.Lock()
+		defer mu.Unlock()
+		if job.Configuration.DryRun {
+			dryRun = job.Configuration.Query
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"statistics": map[string]any{"query": map[string]any{"statementType": "SELECT"}},
+			})
+			return
+		}
+		run = job.Configuration.Query
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"jobReference":  ref,
+			"configuration": map[string]any{"query": map[string]any{"query": "SELECT 1"}},
+			"status":        map[string]any{"state": "DONE"},
+		})
+	})
+	srcs := sourceProvider{"my-bq": source}
+	yes, no := true, false
+
+	tcs := []struct {
+		desc          string
+		cfg           bigqueryexecutesql.Config
+		params        map[string]any
+		wantCache     *bool
+		wantPriority  string
+		wantParamsErr string
+	}{
+		{desc: "defaults"},
+		{desc: "configuration", cfg: bigqueryexecutesql.Config{UseQueryCache: &no, Priority: "batch"}, wantCache: &no, wantPriority: "BATCH"},
+		{desc: "parameters", params: map[string]any{"use_query_cache": false, "priority": "batch"}, wantCache: &no, wantPriority: "BATCH"},
+		{
+			desc:         "parameters override the configuration",
+			cfg:          bigqueryexecutesql.Config{UseQueryCache: &no, Priority: "batch"},
+			params:       map[string]any{"use_query_cache": true, "priority": "INTERACTIVE"},
+			wantCache:    &yes,
+			wantPriority: "INTERACTIVE",
+		},
+		{
+			desc:         "pinned use_query_cache",
+			cfg:          bigqueryexecutesql.Config{UseQueryCache: &no, PinnedParameters: []string{"use_query_cache"}},
+			params:       map[string]any{"priority": "BATCH"},
+			wantCache:    &no,
+			wantPriority: "BATCH",
+		},
+		{
+			desc:         "pinned priority",
+			cfg:          bigqueryexecutesql.Config{Priority: "interactive", PinnedParameters: []string{"priority"}},
+			params:       map[string]any{"priority": "BATCH"},
+			wantPriority: "INTERACTIVE",
+		},
+		{desc: "invalid priority", params: map[string]any{"priority": "urgent"}, wantParamsErr: "not one of the allowed values"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			cfg := tc.cfg
+			cfg.Name, cfg.Type, cfg.Source, cfg.Description = "execute_sql", "bigquery-execute-sql", "my-bq", "run sql"
+			tool, err := cfg.Initialize(srcs)
+			if err != nil {
+				t.Fatalf("unable to initialize tool: %s", err)
+			}
+			for _, name := range tc.cfg.PinnedParameters {
+				if slices.ContainsFunc(tool.GetParameters(), func(p parameters.Parameter) bool { return p.GetName() == name }) {
+					t.Errorf("expected the pinned parameter %q to be hidden", name)
+				}
+			}
+			values := map[string]any{"sql": "SELECT 1"}
+			maps.Copy(values, tc.params)
+			params, err := parameters.ParseParams(tool.GetParameters(), values, nil)
+			if tc.wantParamsErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantParamsErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.wantParamsErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unable to parse params: %s", err)
+			}
+			ctx, err := testutils.ContextWithNewLogger()
+			if err != nil {
+				t.Fatalf("unable to create logger: %s", err)
+			}
+			mu.Lock()
+			dryRun, run = nil, nil
+			mu.Unlock()
+			if _, tbErr := tools.InvokeWithTimeout(ctx, "execute_sql", tool, srcs, params, ""); tbErr != nil {
+				t.Fatalf("unexpected error: %s", tbErr)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if dryRun == nil || run == nil {
+				t.Fatalf("expected a dry run and a query job, got %v and %v", dryRun, run)
+			}
+			// the dry run is estimated with the cache setting of the query
+			if diff := cmp.Diff(tc.wantCache, dryRun.UseQueryCache); diff != "" {
+				t.Errorf("unexpected useQueryCache of the dry run (-want +got):\n%s", diff)
+			}
+			// the client of the query job only sets useQueryCache to disable
+			// the cache, which BigQuery uses by default
+			usesCache := func(b *bool) bool { return b == nil || *b }
+			if got, want := usesCache(run.UseQueryCache), usesCache(tc.wantCache); got != want {
+				t.Errorf("got query job using the cache %t, want %t", got, want)
+			}
+			if run.Priority != tc.wantPriority {
+				t.Errorf("got priority %q, want %q", run.Priority, tc.wantPriority)
+			}
+		})
+	}
+
+	cfg := bigqueryexecutesql.Config{Name: "execute_sql", Type: "bigquery-execute-sql", Source: "my-bq", Description: "run sql", PinnedParameters: []string{"dry_run"}}
+	if _, err := cfg.Initialize(srcs); err == nil || !strings.Contains(err.Error(), "pinnedParameters") {
+		t.Errorf("expected an invalid configuration error, got %v", err)
+	}
+}
+
+func TestScriptErrors(t *testing.T) {
+	sql := "DECLARE n INT64 DEFAULT 0;\nSELECT 1;\nSELECT 1 / n;\nSELECT 2"
+	source := newDryRunSource(t, func(w http.ResponseWriter, r *http.Request) {
+		ref := map[string]any{"projectId": "my-project", "jobId": "script", "location": "US"}
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/jobs") && r.URL.Query().Get("parentJobId") == "script":
+			_ = json.NewEncoder(w).Encode(map[string]any{"jobs": []any{
+				map[string]any{
+					"jobReference": map[string]any{"projectId": "my-project", "jobId": "script_job_2"},
+					"errorResult":  map[string]any{"reason": "invalidQuery", "message": "division by zero: 1 / 0"},
+					"statistics":   map[string]any{"scriptStatistics": map[string]any{"stackFrames": []any{map[string]any{"startLine": 3, "startColumn": 1}}}},
+				},
+			}})
+		case r.Method == http.MethodGet:
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{
+				"code":    http.StatusBadRequest,
+				"message": "Query error: division by zero: 1 / 0",
+				"errors":  []any{map[string]any{"reason": "invalidQuery", "message": "Query error: division by zero: 1 / 0"}},
+			}})
+		default:
+			var job bigqueryrestapi.Job
+			_ = json.NewDecoder(r.Body).Decode(&job)
+			if job.Configuration.DryRun {
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"statistics": map[string]any{"query": map[string]any{"statementType": "SCRIPT"}},
+				})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"jobReference":  ref,
+				"configuration": map[string]any{"query": map[string]any{"query": sql}},
+				"status":        map[string]any{"state": "RUNNING"},
+			})
+		}
+	})
+	srcs := sourceProvider{"my-bq": source}
+	cfg := bigqueryexecutesql.Config{Name: "execute_sql", Type: "bigquery-execute-sql", Source: "my-bq", Description: "run sql"}
+	tool, err := cfg.Initialize(srcs)
+	if err != nil {
+		t.Fatalf("unable to initialize tool: %s", err)
+	}
+	params, err := parameters.ParseParams(tool.GetParameters(), map[string]any{"sql": sql}, nil)
+	if err != nil {
+		t.Fatalf("unable to parse params: %s", err)
+	}
+	ctx, err := testutils.ContextWithNewLogger()
+	if err != nil {
+		t.Fatalf("unable to create logger: %s", err)
+	}
+	_, tbErr := tools.InvokeWithTimeout(ctx, "execute_sql", tool, srcs, params, "")
+	if tbErr == nil {
+		t.Fatalf("expected the script to fail")
+	}
+	details := tbErr.ErrorInfo().Details
+	want := map[string]any{"statementIndex": 3, "statement": "SELECT 1 / n", "childJobId": "script_job_2"}
+	for key, value := range want {
+		if diff := cmp.Diff(value, details[key]); diff != "" {
+			t.Errorf("unexpected %s (-want +got):\n%s", key, diff)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerygetconversationtranscript
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	yaml "github.com/goccy/go-yaml"
+	"github.com/googleapis/genai-toolbox/internal/embeddingmodels"
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	bqutil "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+	"github.com/googleapis/genai-toolbox/internal/tools/googlehttp"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+	"golang.org/x/oauth2"
+)
+
+const resourceType string = "bigquery-get-conversation-transcript"
+
+const (
+	conversationIDKey string = "conversation_id"
+	formatKey         string = "format"
+)
+
+const (
+	formatJSON     = "json"
+	formatMarkdown = "markdown"
+)
+
+// defaultConversationLocation is used when the source does not configure a location.
+const defaultConversationLocation = "global"
+
+// messagesPageSize is the number of messages requested per page.
+const messagesPageSize = 100
+
+// maxMessagePages bounds the number of pages fetched for a single transcript.
+const maxMessagePages = 100
+
+var gdaBaseURL = bqutil.GDABaseURL
+
+func init() {
+	if !tools.Register(resourceType, newConfig) {
+		panic(fmt.Sprintf("tool type %q already registered", resourceType))
+	}
+}
+
+func newConfig(ctx context.Context, name string, decoder *yaml.Decoder) (tools.ToolConfig, error) {
+	actual := Config{Name: name}
+	if err := decoder.DecodeContext(ctx, &actual); err != nil {
+		return nil, err
+	}
+	return actual, nil
+}
+
+type compatibleSource interface {
+	BigQueryTokenSourceWithScope(ctx context.Context, scopes []string) (oauth2.TokenSource, error)
+	BigQueryProject() string
+	BigQueryLocation() string
+	GetMaxQueryResultRows() int
+	UseClientAuthorization() bool
+	ClientAuthorizationMode() string
+}
+
+type Config struct {
+	Name         string                 `yaml:"name" validate:"required"`
+	Type         string                 `yaml:"type" validate:"required"`
+	Source       string                 `yaml:"source" validate:"required"`
+	Description  string                 `yaml:"description" validate:"required"`
+	AuthRequired []string               `yaml:"authRequired"`
+	Annotations  *tools.ToolAnnotations `yaml:"annotations,omitempty"`
+	// AllowedProjects lists the other projects conversations can be read from.
+	AllowedProjects []string `yaml:"allowedProjects" expand:"true"`
+	// MaxResultRows limits the rows kept per embedded result table. Defaults
+	// to the source's maxQueryResultRows.
+	MaxResultRows int `yaml:"maxResultRows"`
+	// ExpandDescriptions expands the source properties referenced in the
+	// descriptions of the tool and its parameters, e.g. {{ .Source.Project }}.
+	ExpandDescriptions bool `yaml:"expandDescriptions"`
+}
+
+// validate interface
+var _ tools.ToolConfigWithFields = Config{}
+
+func (cfg Config) ToolConfigType() string {
+	return resourceType
+}
+
+// DeclaredFields returns the fields that policies can set.
+func (cfg Config) DeclaredFields() []string {
+	return []string{"allowedProjects"}
+}
+
+func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error) {
+	// verify source exists
+	rawS, ok := srcs[cfg.Source]
+	if !ok {
+		return nil, fmt.Errorf("no source named %q configured", cfg.Source)
+	}
+
+	// verify the source is compatible
+	s, ok := rawS.(compatibleSource)
+	if !ok {
+		return nil, tools.IncompatibleSourceError[compatibleSource](rawS, cfg.Source, resourceType, srcs)
+	}
+
+	if cfg.MaxResultRows < 0 {
+		return nil, fmt.Errorf("invalid maxResultRows %d for tool %q: must not be negative", cfg.MaxResultRows, cfg.Name)
+	}
+
+	conversationIDParameter := parameters.NewStringParameter(conversationIDKey, "The ID or full resource name of the conversation to export.")
+	projectParameter := bqutil.InitializeProjectParameter(s.BigQueryProject(), cfg.AllowedProjects, "The Google Cloud project ID containing the conversation. Defaults to the project of the source.")
+	formatParameter := parameters.NewStringParameterWithDefault(formatKey, formatJSON, "The transcript format, either `json` or `markdown`.")
+	formatParameter.AllowedValues = []any{formatJSON, formatMarkdown}
+	params := parameters.Parameters{conversationIDParameter, projectParameter, formatParameter}
+
+	description, err := bqutil.ExpandDescriptions(cfg.Name, cfg.ExpandDescriptions, rawS, cfg.Description, params)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Description = description
+
+	annotations := tools.GetAnnotationsOrDefault(cfg.Annotations, tools.NewReadOnlyAnnotations)
+	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, params, annotations, nil)
+
+	// finish tool setup
+	t := Tool{
+		Config:      cfg,
+		Parameters:  params,
+		manifest:    tools.Manifest{Description: cfg.Description, Parameters: params.Manifest(), AuthRequired: cfg.AuthRequired},
+		mcpManifest: mcpManifest,
+	}
+	return t, nil
+}
+
+// validate interface
+var _ tools.Tool = Tool{}
+
+type Tool struct {
+	Config
+	Parameters  parameters.Parameters `yaml:"parameters"`
+	manifest    tools.Manifest
+	mcpManifest tools.McpManifest
+}
+
+func (t Tool) ToConfig() tools.ToolConfig {
+	return t.Config
+}
+
+func (t Tool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, params parameters.ParamValues, accessToken tools.AccessToken) (any, util.ToolboxError) {
+	source, err := tools.GetCompatibleSource[compatibleSource](resourceMgr, t.Source, t.Name, t.Type)
+	if err != nil {
+		return nil, util.NewClientServerError("source used is not compatible with the tool", http.StatusInternalServerError, err)
+	}
+
+	mapParams := params.AsMap()
+	conversationID, ok := mapParams[conversationIDKey].(string)
+	if !ok || conversationID == "" {
+		return nil, util.NewAgentError(fmt.Sprintf("invalid or missing '%s' parameter; expected a non-empty string", conversationIDKey), nil)
+	}
+	format, _ := mapParams[formatKey].(string)
+	if format == "" {
+		format = formatJSON
+	}
+
+	location := source.BigQueryLocation()
+	if location == "" {
+		location = defaultConversationLocation
+	}
+	requestedProject, _ := mapParams[bqutil.ProjectKey].(string)
+	projectID, err := bqutil.ResolveProject(requestedProject, source.BigQueryProject(), t.AllowedProjects)
+	if err != nil {
+		return nil, util.NewAgentError(fmt.Sprintf("invalid '%s' parameter", bqutil.ProjectKey), err)
+	}
+	resourceName, err := bqutil.ResolveConversationName(conversationID, projectID, location)
+	if err != nil {
+		return nil, util.NewAgentError(fmt.Sprintf("invalid '%s' parameter", conversationIDKey), err)
+	}
+
+	// Get credentials for the API call
+	tokenStr, _, tbErr := bqutil.GetAPIToken(ctx, t.Name, source, accessToken, nil)
+	if tbErr != nil {
+		return nil, tbErr
+	}
+
+	messages, tbErr := listMessages(ctx, resourceName, tokenStr)
+	if tbErr != nil {
+		return nil, tbErr
+	}
+
+	maxRows := t.MaxResultRows
+	if maxRows == 0 {
+		maxRows = source.GetMaxQueryResultRows()
+	}
+	transcript := buildTranscript(resourceName, messages, maxRows)
+	if format == formatMarkdown {
+		return transcript.markdown(), nil
+	}
+	return transcript, nil
+}
+
+// listMessages fetches every message of a conversation, following page tokens.
+func listMessages(ctx context.Context, resourceName, tokenStr string) ([]storageMessage, util.ToolboxError) {
+	client := googlehttp.Client{Token: googlehttp.StaticToken(tokenStr)}
+	var messages []storageMessage
+	seen := make(map[string]bool)
+	pageToken := ""
+	for page := 0; ; page++ {
+		if page == maxMessagePages {
+			return nil, util.NewAgentError(fmt.Sprintf("conversation %q has more than %d pages of messages", resourceName, maxMessagePages), nil)
+		}
+
+		query := url.Values{"pageSize": {fmt.Sprint(messagesPageSize)}}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		reqURL := fmt.Sprintf("%s/%s/messages?%s", gdaBaseURL, resourceName, query.Encode())
+		var listResp listMessagesResponse
+		if err := client.DoJSON(ctx, http.MethodGet, reqURL, nil, &listResp); err != nil {
+			return nil, googlehttp.ToolboxError(err, fmt.Sprintf("failed to list messages of conversation %q", resourceName))
+		}
+		messages = append(messages, listResp.Messages...)
+
+		pageToken = listResp.NextPageToken
+		if pageToken == "" {
+			return messages, nil
+		}
+		if seen[pageToken] {
+			return nil, util.NewClientServerError(fmt.Sprintf("API returned page token %q twice while listing messages", pageToken), http.StatusInternalServerError, nil)
+		}
+		seen[pageToken] = true
+	}
+}
+
+func (t Tool) EmbedParams(ctx context.Context, paramValues parameters.ParamValues, embeddingModelsMap map[string]embeddingmodels.EmbeddingModel) (parameters.ParamValues, error) {
+	return parameters.EmbedParams(ctx, t.Parameters, paramValues, embeddingModelsMap, nil)
+}
+
+func (t Tool) Manifest() tools.Manifest {
+	return t.manifest
+}
+
+func (t Tool) McpManifest() tools.McpManifest {
+	return t.mcpManifest
+}
+
+func (t Tool) Authorized(verifiedAuthServices []string) bool {
+	return tools.IsAuthorized(t.AuthRequired, verifiedAuthServices)
+}
+
+func (t Tool) RequiresClientAuthorization(resourceMgr tools.SourceProvider) (bool, error) {
+	source, err := tools.GetCompatibleSource[compatibleSource](resourceMgr, t.Source, t.Name, t.Type)
+	if err != nil {
+		return false, err
+	}
+	return source.UseClientAuthorization(), nil
+}
+
+func (t Tool) GetAuthTokenHeaderName(resourceMgr tools.SourceProvider) (string, error) {
+	return "Authorization", nil
+}
+
+func (t Tool) GetParameters() parameters.Parameters {
+	return t.Parameters
+}
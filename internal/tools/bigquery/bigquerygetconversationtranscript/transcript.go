@@ -0,0 +1,249 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerygetconversationtranscript
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// listMessagesResponse is a page of the conversations.messages.list API.
+type listMessagesResponse struct {
+	Messages      []storageMessage `json:"messages"`
+	NextPageToken string           `json:"nextPageToken"`
+}
+
+// storageMessage is a message stored in a conversation.
+type storageMessage struct {
+	MessageID string      `json:"messageId"`
+	Message   chatMessage `json:"message"`
+}
+
+type chatMessage struct {
+	Timestamp     string         `json:"timestamp"`
+	UserMessage   *userMessage   `json:"userMessage,omitempty"`
+	SystemMessage *systemMessage `json:"systemMessage,omitempty"`
+}
+
+type userMessage struct {
+	Text string `json:"text"`
+}
+
+type systemMessage struct {
+	Text   *textMessage    `json:"text,omitempty"`
+	Schema json.RawMessage `json:"schema,omitempty"`
+	Data   *dataMessage    `json:"data,omitempty"`
+	Chart  json.RawMessage `json:"chart,omitempty"`
+	Error  *errorMessage   `json:"error,omitempty"`
+}
+
+type textMessage struct {
+	Parts []string `json:"parts"`
+}
+
+type dataMessage struct {
+	Query *struct {
+		Question string `json:"question"`
+	} `json:"query,omitempty"`
+	GeneratedSQL string      `json:"generatedSql,omitempty"`
+	Result       *dataResult `json:"result,omitempty"`
+}
+
+type dataResult struct {
+	Schema struct {
+		Fields []struct {
+			Name string `json:"name"`
+		} `json:"fields"`
+	} `json:"schema"`
+	Data []map[string]any `json:"data"`
+}
+
+type errorMessage struct {
+	Text string `json:"text"`
+}
+
+// TranscriptEntry is a single message of a conversation transcript.
+type TranscriptEntry struct {
+	MessageID string            `json:"messageId,omitempty"`
+	Timestamp string            `json:"timestamp,omitempty"`
+	Role      string            `json:"role"`
+	Type      string            `json:"type"`
+	Text      string            `json:"text,omitempty"`
+	SQL       string            `json:"sql,omitempty"`
+	Result    *TranscriptResult `json:"result,omitempty"`
+}
+
+// TranscriptResult is a result table embedded in a transcript, truncated to
+// the configured number of rows.
+type TranscriptResult struct {
+	Headers   []string `json:"headers"`
+	Rows      [][]any  `json:"rows"`
+	TotalRows int      `json:"totalRows"`
+	Truncated bool     `json:"truncated"`
+}
+
+// Transcript is an ordered record of a conversation.
+type Transcript struct {
+	Conversation string            `json:"conversation"`
+	Messages     []TranscriptEntry `json:"messages"`
+}
+
+// buildTranscript converts stored messages into transcript entries ordered by
+// timestamp. If any timestamp cannot be parsed, the API order is kept.
+func buildTranscript(conversation string, messages []storageMessage, maxRows int) Transcript {
+	sorted := make([]storageMessage, len(messages))
+	copy(sorted, messages)
+	times := make(map[string]time.Time, len(sorted))
+	sortable := true
+	for _, m := range sorted {
+		ts, err := time.Parse(time.RFC3339Nano, m.Message.Timestamp)
+		if err != nil {
+			sortable = false
+			break
+		}
+		times[m.Message.Timestamp] = ts
+	}
+	if sortable {
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return times[sorted[i].Message.Timestamp].Before(times[sorted[j].Message.Timestamp])
+		})
+	}
+
+	entries := make([]TranscriptEntry, 0, len(sorted))
+	for _, m := range sorted {
+		entry := TranscriptEntry{MessageID: m.MessageID, Timestamp: m.Message.Timestamp}
+		switch {
+		case m.Message.UserMessage != nil:
+			entry.Role = "user"
+			entry.Type = "text"
+			entry.Text = m.Message.UserMessage.Text
+		case m.Message.SystemMessage != nil:
+			entry.Role = "system"
+			renderSystemMessage(&entry, m.Message.SystemMessage, maxRows)
+		default:
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return Transcript{Conversation: conversation, Messages: entries}
+}
+
+func renderSystemMessage(entry *TranscriptEntry, msg *systemMessage, maxRows int) {
+	switch {
+	case msg.Text != nil:
+		entry.Type = "text"
+		entry.Text = strings.Join(msg.Text.Parts, "")
+	case msg.Data != nil:
+		entry.Type = "data"
+		if msg.Data.Query != nil {
+			entry.Text = msg.Data.Query.Question
+		}
+		entry.SQL = msg.Data.GeneratedSQL
+		if msg.Data.Result != nil {
+			entry.Result = truncateResult(msg.Data.Result, maxRows)
+		}
+	case msg.Error != nil:
+		entry.Type = "error"
+		entry.Text = msg.Error.Text
+	case len(msg.Schema) != 0:
+		entry.Type = "schema"
+	case len(msg.Chart) != 0:
+		entry.Type = "chart"
+	default:
+		entry.Type = "unknown"
+	}
+}
+
+// truncateResult keeps at most maxRows rows of a result table. A maxRows of 0
+// or less keeps no rows.
+func truncateResult(result *dataResult, maxRows int) *TranscriptResult {
+	headers := make([]string, 0, len(result.Schema.Fields))
+	for _, f := range result.Schema.Fields {
+		headers = append(headers, f.Name)
+	}
+	n := min(len(result.Data), max(maxRows, 0))
+	rows := make([][]any, 0, n)
+	for _, row := range result.Data[:n] {
+		values := make([]any, 0, len(headers))
+		for _, h := range headers {
+			values = append(values, row[h])
+		}
+		rows = append(rows, values)
+	}
+	return &TranscriptResult{
+		Headers:   headers,
+		Rows:      rows,
+		TotalRows: len(result.Data),
+		Truncated: n < len(result.Data),
+	}
+}
+
+// markdown renders the transcript as a Markdown document.
+func (t Transcript) markdown() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Conversation `%s`\n", t.Conversation)
+	for _, e := range t.Messages {
+		heading := "User"
+		if e.Role == "system" {
+			heading = "System"
+			if e.Type != "text" {
+				heading += " (" + e.Type + ")"
+			}
+		}
+		if e.Timestamp != "" {
+			heading += " — " + e.Timestamp
+		}
+		fmt.Fprintf(&sb, "\n## %s\n", heading)
+		if e.Text != "" {
+			fmt.Fprintf(&sb, "\n%s\n", e.Text)
+		}
+		if e.SQL != "" {
+			fmt.Fprintf(&sb, "\n```sql\n%s\n```\n", strings.TrimRight(e.SQL, "\n"))
+		}
+		if e.Result != nil {
+			sb.WriteString("\n")
+			writeMarkdownTable(&sb, e.Result)
+		}
+	}
+	return sb.String()
+}
+
+func writeMarkdownTable(sb *strings.Builder, r *TranscriptResult) {
+	if len(r.Headers) > 0 {
+		cells := make([]string, len(r.Headers))
+		for i, h := range r.Headers {
+			cells[i] = escapeMarkdownCell(h)
+		}
+		fmt.Fprintf(sb, "| %s |\n", strings.Join(cells, " | "))
+		fmt.Fprintf(sb, "|%s\n", strings.Repeat(" --- |", len(r.Headers)))
+		for _, row := range r.Rows {
+			for i, v := range row {
+				cells[i] = escapeMarkdownCell(fmt.Sprint(v))
+			}
+			fmt.Fprintf(sb, "| %s |\n", strings.Join(cells, " | "))
+		}
+	}
+	if r.Truncated {
+		fmt.Fprintf(sb, "\n_Showing the first %d of %d rows._\n", len(r.Rows), r.TotalRows)
+	}
+}
+
+func escapeMarkdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
@@ -0,0 +1,208 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerygetconversationtranscript
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+	"golang.org/x/oauth2"
+)
+
+type fakeSource struct{}
+
+func (s *fakeSource) SourceType() string              { return "bigquery" }
+func (s *fakeSource) ToConfig() sources.SourceConfig  { return nil }
+func (s *fakeSource) BigQueryProject() string         { return "test-project" }
+func (s *fakeSource) BigQueryLocation() string        { return "" }
+func (s *fakeSource) GetMaxQueryResultRows() int      { return 2 }
+func (s *fakeSource) UseClientAuthorization() bool    { return false }
+func (s *fakeSource) ClientAuthorizationMode() string { return "disabled" }
+func (s *fakeSource) BigQueryTokenSourceWithScope(ctx context.Context, scopes []string) (oauth2.TokenSource, error) {
+	return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "adc-token"}), nil
+}
+
+type fakeSourceProvider struct {
+	source sources.Source
+}
+
+func (p fakeSourceProvider) GetSource(name string) (sources.Source, bool) {
+	return p.source, true
+}
+
+const conversationPath = "/projects/test-project/locations/global/conversations/conv-1/messages"
+
+// messagePages is a multi-page fixture of the messages.list API. The pages are
+// returned newest first, as the API does.
+var messagePages = map[string]string{
+	"": `{
+		"messages": [
+			{"messageId": "m4", "message": {"timestamp": "2025-01-01T10:00:04Z", "systemMessage": {"text": {"parts": ["There are ", "3 rows."]}}}},
+			{"messageId": "m3", "message": {"timestamp": "2025-01-01T10:00:03Z", "systemMessage": {"data": {
+				"query": {"question": "count rows"},
+				"generatedSql": "SELECT * FROM t",
+				"result": {"schema": {"fields": [{"name": "id"}, {"name": "name"}]}, "data": [{"id": 1, "name": "a|b"}, {"id": 2, "name": "c"}, {"id": 3, "name": "d"}]}
+			}}}}
+		],
+		"nextPageToken": "page-2"
+	}`,
+	"page-2": `{
+		"messages": [
+			{"messageId": "m2", "message": {"timestamp": "2025-01-01T10:00:02Z", "systemMessage": {"schema": {"query": {"question": "q"}}}}},
+			{"messageId": "m5", "message": {"timestamp": "2025-01-01T10:00:05Z", "systemMessage": {"error": {"text": "quota exceeded"}}}}
+		],
+		"nextPageToken": "page-3"
+	}`,
+	"page-3": `{
+		"messages": [
+			{"messageId": "m1", "message": {"timestamp": "2025-01-01T10:00:01Z", "userMessage": {"text": "How many rows?"}}}
+		]
+	}`,
+}
+
+func newTestTool(t *testing.T, pages map[string]string, cfg Config) (Tool, fakeSourceProvider, *[]string) {
+	t.Helper()
+	var tokens []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != conversationPath {
+			t.Errorf("unexpected request path: %s", r.URL.Path)
+		}
+		token := r.URL.Query().Get("pageToken")
+		tokens = append(tokens, token)
+		page, ok := pages[token]
+		if !ok {
+			http.Error(w, "unknown page token", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(page))
+	}))
+	t.Cleanup(server.Close)
+	originalURL := gdaBaseURL
+	gdaBaseURL = server.URL
+	t.Cleanup(func() { gdaBaseURL = originalURL })
+
+	cfg.Name, cfg.Type, cfg.Source, cfg.Description = "transcript", resourceType, "src", "d"
+	source := &fakeSource{}
+	rawTool, err := cfg.Initialize(map[string]sources.Source{"src": source})
+	if err != nil {
+		t.Fatalf("unexpected error initializing tool: %s", err)
+	}
+	return rawTool.(Tool), fakeSourceProvider{source: source}, &tokens
+}
+
+func invokeParams(format string) parameters.ParamValues {
+	return parameters.ParamValues{
+		{Name: conversationIDKey, Value: "conv-1"},
+		{Name: formatKey, Value: format},
+	}
+}
+
+func TestInvokeJSONTranscript(t *testing.T) {
+	tool, provider, tokens := newTestTool(t, messagePages, Config{})
+	res, tbErr := tool.Invoke(context.Background(), provider, invokeParams(formatJSON), "")
+	if tbErr != nil {
+		t.Fatalf("unexpected invoke error: %s", tbErr)
+	}
+	if diff := cmp.Diff([]string{"", "page-2", "page-3"}, *tokens); diff != "" {
+		t.Fatalf("unexpected page tokens requested: diff %v", diff)
+	}
+
+	want := Transcript{
+		Conversation: "projects/test-project/locations/global/conversations/conv-1",
+		Messages: []TranscriptEntry{
+			{MessageID: "m1", Timestamp: "2025-01-01T10:00:01Z", Role: "user", Type: "text", Text: "How many rows?"},
+			{MessageID: "m2", Timestamp: "2025-01-01T10:00:02Z", Role: "system", Type: "schema"},
+			{MessageID: "m3", Timestamp: "2025-01-01T10:00:03Z", Role: "system", Type: "data", Text: "count rows", SQL: "SELECT * FROM t", Result: &TranscriptResult{
+				Headers:   []string{"id", "name"},
+				Rows:      [][]any{{float64(1), "a|b"}, {float64(2), "c"}},
+				TotalRows: 3,
+				Truncated: true,
+			}},
+			{MessageID: "m4", Timestamp: "2025-01-01T10:00:04Z", Role: "system", Type: "text", Text: "There are 3 rows."},
+			{MessageID: "m5", Timestamp: "2025-01-01T10:00:05Z", Role: "system", Type: "error", Text: "quota exceeded"},
+		},
+	}
+	if diff := cmp.Diff(want, res); diff != "" {
+		t.Fatalf("incorrect transcript: diff %v", diff)
+	}
+}
+
+func TestInvokeMarkdownTranscript(t *testing.T) {
+	tool, provider, _ := newTestTool(t, messagePages, Config{MaxResultRows: 1})
+	res, tbErr := tool.Invoke(context.Background(), provider, invokeParams(formatMarkdown), "")
+	if tbErr != nil {
+		t.Fatalf("unexpected invoke error: %s", tbErr)
+	}
+	got, ok := res.(string)
+	if !ok {
+		t.Fatalf("expected a Markdown string, got %T", res)
+	}
+
+	want := "# Conversation `projects/test-project/locations/global/conversations/conv-1`\n" +
+		"\n## User — 2025-01-01T10:00:01Z\n\nHow many rows?\n" +
+		"\n## System (schema) — 2025-01-01T10:00:02Z\n" +
+		"\n## System (data) — 2025-01-01T10:00:03Z\n\ncount rows\n\n```sql\nSELECT * FROM t\n```\n" +
+		"\n| id | name |\n| --- | --- |\n| 1 | a\\|b |\n\n_Showing the first 1 of 3 rows._\n" +
+		"\n## System — 2025-01-01T10:00:04Z\n\nThere are 3 rows.\n" +
+		"\n## System (error) — 2025-01-01T10:00:05Z\n\nquota exceeded\n"
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("incorrect Markdown transcript: diff %v", diff)
+	}
+}
+
+func TestTruncateResult(t *testing.T) {
+	result := &dataResult{Data: []map[string]any{{"a": 1}, {"a": 2}}}
+	result.Schema.Fields = append(result.Schema.Fields, struct {
+		Name string `json:"name"`
+	}{Name: "a"})
+
+	tcs := []struct {
+		maxRows       int
+		wantRows      int
+		wantTruncated bool
+	}{
+		{maxRows: 0, wantRows: 0, wantTruncated: true},
+		{maxRows: 1, wantRows: 1, wantTruncated: true},
+		{maxRows: 2, wantRows: 2, wantTruncated: false},
+		{maxRows: 10, wantRows: 2, wantTruncated: false},
+	}
+	for _, tc := range tcs {
+		got := truncateResult(result, tc.maxRows)
+		if len(got.Rows) != tc.wantRows || got.Truncated != tc.wantTruncated || got.TotalRows != 2 {
+			t.Errorf("truncateResult(maxRows=%d) = %d rows, truncated=%t, total=%d", tc.maxRows, len(got.Rows), got.Truncated, got.TotalRows)
+		}
+	}
+}
+
+func TestInvokeRepeatedPageToken(t *testing.T) {
+	pages := map[string]string{
+		"":     `{"messages": [], "nextPageToken": "loop"}`,
+		"loop": `{"messages": [], "nextPageToken": "loop"}`,
+	}
+	tool, provider, tokens := newTestTool(t, pages, Config{})
+	_, tbErr := tool.Invoke(context.Background(), provider, invokeParams(formatJSON), "")
+	if tbErr == nil || !strings.Contains(tbErr.Error(), "twice") {
+		t.Fatalf("expected an error for a repeated page token, got %v", tbErr)
+	}
+	if len(*tokens) != 2 {
+		t.Fatalf("expected pagination to stop after the repeated token, got %d requests", len(*tokens))
+	}
+}
@@ -0,0 +1,459 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerygetdataagentinfo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	yaml "github.com/goccy/go-yaml"
+	"github.com/googleapis/genai-toolbox/internal/embeddingmodels"
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	bqutil "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+	"github.com/googleapis/genai-toolbox/internal/tools/googlehttp"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+	"golang.org/x/oauth2"
+)
+
+const resourceType string = "bigquery-get-data-agent-info"
+
+const dataAgentIDKey string = "data_agent_id"
+const forceRefreshKey string = "force_refresh"
+
+// defaultDataAgentLocation is used when the source does not configure a location.
+const defaultDataAgentLocation = "global"
+
+// verifyTimeout bounds the startup check of the default data agent.
+const verifyTimeout = 30 * time.Second
+
+// defaultCacheSize bounds the number of cached data agents when caching is
+// enabled without an explicit cacheSize.
+const defaultCacheSize = 100
+
+// maxRetries is the number of times a lookup is retried after a transient
+// error.
+const maxRetries = 2
+
+// elideArrays is the ResponseTruncation that elides the items of the largest
+// arrays of the result.
+const elideArrays = "elideArrays"
+
+// gdaBaseURL is the Gemini Data Analytics API endpoint. It can be overridden for testing.
+var gdaBaseURL = "https://geminidataanalytics.googleapis.com/v1beta"
+
+// outputSchema describes the data agent returned by the tool in its MCP
+// manifest. Only the commonly used fields are listed; the API may return more.
+var outputSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"name": map[string]any{
+			"type":        "string",
+			"description": "The resource name of the data agent.",
+		},
+		"displayName": map[string]any{
+			"type":        "string",
+			"description": "The display name of the data agent.",
+		},
+		"description": map[string]any{
+			"type":        "string",
+			"description": "The description of the data agent.",
+		},
+		"createTime": map[string]any{
+			"type":   "string",
+			"format": "date-time",
+		},
+		"updateTime": map[string]any{
+			"type":   "string",
+			"format": "date-time",
+		},
+		"dataAnalyticsAgent": map[string]any{
+			"type":        "object",
+			"description": "The configuration of the agent, including the context selected by the `context_version` parameter.",
+		},
+		"systemInstruction": map[string]any{
+			"type":        "string",
+			"description": "With `summarize`, the system instruction of the selected context.",
+		},
+		"tables": map[string]any{
+			"type":        "array",
+			"description": "With `summarize`, the BigQuery tables of the selected context, with their project, dataset, table and columns.",
+		},
+		"explores": map[string]any{
+			"type":        "array",
+			"description": "With `summarize`, the Looker explores of the selected context, with their columns.",
+		},
+	},
+	"required": []any{"name"},
+}
+
+func init() {
+	if !tools.Register(resourceType, newConfig) {
+		panic(fmt.Sprintf("tool type %q already registered", resourceType))
+	}
+}
+
+func newConfig(ctx context.Context, name string, decoder *yaml.Decoder) (tools.ToolConfig, error) {
+	actual := Config{Name: name}
+	if err := decoder.DecodeContext(ctx, &actual); err != nil {
+		return nil, err
+	}
+	return actual, nil
+}
+
+type compatibleSource interface {
+	BigQueryTokenSourceWithScope(ctx context.Context, scopes []string) (oauth2.TokenSource, error)
+	BigQueryProject() string
+	BigQueryLocation() string
+	UseClientAuthorization() bool
+	ClientAuthorizationMode() string
+	IsDatasetAllowed(projectID, datasetID string) bool
+	BigQueryAllowedDatasets() []string
+}
+
+type Config struct {
+	Name         string                 `yaml:"name" validate:"required"`
+	Type         string                 `yaml:"type" validate:"required"`
+	Source       string                 `yaml:"source" validate:"required"`
+	Description  string                 `yaml:"description" validate:"required"`
+	AuthRequired []string               `yaml:"authRequired"`
+	Annotations  *tools.ToolAnnotations `yaml:"annotations,omitempty"`
+	// AllowedProjects lists the other projects data agents can be looked up in.
+	AllowedProjects []string `yaml:"allowedProjects" expand:"true"`
+	// CacheTTL enables caching of data agent lookups for the given duration (e.g. "5m").
+	CacheTTL string `yaml:"cacheTtl"`
+	// CacheSize is the maximum number of cached data agents. Defaults to 100.
+	CacheSize int `yaml:"cacheSize"`
+	// DefaultAgent is the data agent retrieved when the `data_agent_id`
+	// parameter is omitted. Either a bare ID or a full resource name.
+	DefaultAgent string `yaml:"defaultAgent" expand:"true"`
+	// VerifyOnStartup checks that DefaultAgent exists and is accessible with
+	// the source's credentials when the tool is initialized. The check is
+	// skipped for sources using client OAuth.
+	VerifyOnStartup bool `yaml:"verifyOnStartup"`
+	// ParameterDescriptions overrides the descriptions of the parameters, by
+	// parameter name.
+	ParameterDescriptions tools.ParameterDescriptions `yaml:"parameterDescriptions"`
+	// CheckDataAgentDatasets checks the tables of the data agent against the
+	// allowed datasets of the source: "enforce" refuses data agents with
+	// other tables, "warn" lists them in the result metadata.
+	CheckDataAgentDatasets string `yaml:"checkDataAgentDatasets"`
+	// MaxResponseBytes limits the size of the data agent documents read from
+	// the API. With ResponseTruncation, it limits the size of the result
+	// instead.
+	MaxResponseBytes int64 `yaml:"maxResponseBytes"`
+	// ResponseTruncation shortens the results larger than MaxResponseBytes
+	// instead of failing: "elideArrays" keeps the fields of the result and
+	// drops the last items of its largest arrays, see googlehttp.ElideArrays.
+	ResponseTruncation string `yaml:"responseTruncation"`
+	// ExpandDescriptions expands the source properties referenced in the
+	// descriptions of the tool and its parameters, e.g. {{ .Source.Project }}.
+	ExpandDescriptions bool `yaml:"expandDescriptions"`
+}
+
+// validate interface
+var _ tools.ToolConfigWithContext = Config{}
+var _ tools.ToolConfigWithFields = Config{}
+
+func (cfg Config) ToolConfigType() string {
+	return resourceType
+}
+
+// DeclaredFields returns the fields that policies can set.
+func (cfg Config) DeclaredFields() []string {
+	return []string{"allowedProjects"}
+}
+
+func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error) {
+	return cfg.InitializeWithContext(context.Background(), srcs)
+}
+
+func (cfg Config) InitializeWithContext(ctx context.Context, srcs map[string]sources.Source) (tools.Tool, error) {
+	// verify source exists
+	rawS, ok := srcs[cfg.Source]
+	if !ok {
+		return nil, fmt.Errorf("no source named %q configured", cfg.Source)
+	}
+
+	// verify the source is compatible
+	s, ok := rawS.(compatibleSource)
+	if !ok {
+		return nil, tools.IncompatibleSourceError[compatibleSource](rawS, cfg.Source, resourceType, srcs)
+	}
+
+	var cache *agentCache
+	if cfg.CacheTTL != "" {
+		ttl, err := time.ParseDuration(cfg.CacheTTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cacheTtl %q for tool %q: %w", cfg.CacheTTL, cfg.Name, err)
+		}
+		if ttl <= 0 {
+			return nil, fmt.Errorf("invalid cacheTtl %q for tool %q: must be positive", cfg.CacheTTL, cfg.Name)
+		}
+		size := cfg.CacheSize
+		if size < 0 {
+			return nil, fmt.Errorf("invalid cacheSize %d for tool %q: must not be negative", cfg.CacheSize, cfg.Name)
+		}
+		if size == 0 {
+			size = defaultCacheSize
+		}
+		cache = newAgentCache(ttl, size)
+	}
+
+	if err := bqutil.ValidateDataAgentDatasetsMode(cfg.CheckDataAgentDatasets); err != nil {
+		return nil, fmt.Errorf("invalid config for tool %q: %w", cfg.Name, err)
+	}
+
+	if cfg.MaxResponseBytes < 0 {
+		return nil, fmt.Errorf("invalid maxResponseBytes %d for tool %q: must not be negative", cfg.MaxResponseBytes, cfg.Name)
+	}
+	switch cfg.ResponseTruncation {
+	case "":
+	case elideArrays:
+		if cfg.MaxResponseBytes == 0 {
+			return nil, fmt.Errorf("responseTruncation requires maxResponseBytes to be set for tool %q", cfg.Name)
+		}
+	default:
+		return nil, fmt.Errorf("invalid responseTruncation %q for tool %q: must be %q", cfg.ResponseTruncation, cfg.Name, elideArrays)
+	}
+
+	if cfg.VerifyOnStartup && cfg.DefaultAgent == "" {
+		return nil, fmt.Errorf("verifyOnStartup requires defaultAgent to be set for tool %q", cfg.Name)
+	}
+
+	dataAgentIDDescription := cfg.ParameterDescriptions.Describe(dataAgentIDKey, "The ID of the data agent to retrieve. Either a bare ID (e.g. `my-agent`) or a full resource name (e.g. `projects/my-project/locations/global/dataAgents/my-agent`).")
+	var dataAgentIDParameter parameters.Parameter
+	if cfg.DefaultAgent != "" {
+		location := s.BigQueryLocation()
+		if location == "" {
+			location = defaultDataAgentLocation
+		}
+		resourceName, err := bqutil.ResolveDataAgentName(cfg.DefaultAgent, s.BigQueryProject(), location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid defaultAgent for tool %q: %w", cfg.Name, err)
+		}
+		if cfg.VerifyOnStartup {
+			if err := verifyDataAgent(ctx, s, resourceName); err != nil {
+				return nil, fmt.Errorf("unable to verify defaultAgent of tool %q: %w", cfg.Name, err)
+			}
+		}
+		dataAgentIDParameter = parameters.WithExamples(parameters.NewStringParameterWithDefault(dataAgentIDKey, cfg.DefaultAgent, dataAgentIDDescription), bqutil.DataAgentIDExamples()...)
+	} else {
+		dataAgentIDParameter = parameters.WithExamples(parameters.NewStringParameter(dataAgentIDKey, dataAgentIDDescription), bqutil.DataAgentIDExamples()...)
+	}
+	projectParameter := bqutil.InitializeProjectParameter(s.BigQueryProject(), cfg.AllowedProjects, cfg.ParameterDescriptions.Describe(bqutil.ProjectKey, "The Google Cloud project ID containing the data agent. Defaults to the project of the source."))
+	contextVersionParameter := bqutil.NewContextVersionParameter(cfg.ParameterDescriptions.Describe(bqutil.ContextVersionKey, "The context of the data agent to return: `published` (the default) or `staging`, which holds unpublished changes. The other context is left out of the result."))
+	summarizeParameter := parameters.NewBooleanParameterWithDefault(bqutil.SummarizeKey, false, cfg.ParameterDescriptions.Describe(bqutil.SummarizeKey, "If true, returns a summary of the data agent instead of its document: its display name, system instruction, and the tables of its datasources with their columns."))
+	params := parameters.Parameters{dataAgentIDParameter, projectParameter, contextVersionParameter, summarizeParameter}
+	if cache != nil {
+		params = append(params, parameters.NewBooleanParameterWithDefault(forceRefreshKey, false, cfg.ParameterDescriptions.Describe(forceRefreshKey, "If true, bypasses the cache and fetches the latest version of the data agent.")))
+	}
+
+	if err := cfg.ParameterDescriptions.Validate(cfg.Name, params); err != nil {
+		return nil, err
+	}
+
+	description, err := bqutil.ExpandDescriptions(cfg.Name, cfg.ExpandDescriptions, rawS, cfg.Description, params)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Description = description
+
+	annotations := tools.GetAnnotationsOrDefault(cfg.Annotations, tools.NewReadOnlyAnnotations)
+	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, params, annotations, outputSchema)
+
+	// finish tool setup
+	t := Tool{
+		Config:      cfg,
+		Parameters:  params,
+		manifest:    tools.Manifest{Description: cfg.Description, Parameters: params.Manifest(), AuthRequired: cfg.AuthRequired},
+		mcpManifest: mcpManifest,
+		cache:       cache,
+	}
+	return t, nil
+}
+
+// validate interface
+var _ tools.Tool = Tool{}
+
+type Tool struct {
+	Config
+	Parameters  parameters.Parameters `yaml:"parameters"`
+	manifest    tools.Manifest
+	mcpManifest tools.McpManifest
+	cache       *agentCache
+}
+
+func (t Tool) ToConfig() tools.ToolConfig {
+	return t.Config
+}
+
+func (t Tool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, params parameters.ParamValues, accessToken tools.AccessToken) (any, util.ToolboxError) {
+	source, err := tools.GetCompatibleSource[compatibleSource](resourceMgr, t.Source, t.Name, t.Type)
+	if err != nil {
+		return nil, util.NewClientServerError("source used is not compatible with the tool", http.StatusInternalServerError, err)
+	}
+
+	mapParams := params.AsMap()
+	dataAgentID, ok := mapParams[dataAgentIDKey].(string)
+	if !ok || dataAgentID == "" {
+		return nil, util.NewAgentError(fmt.Sprintf("invalid or missing '%s' parameter; expected a non-empty string", dataAgentIDKey), nil)
+	}
+	forceRefresh, _ := mapParams[forceRefreshKey].(bool)
+	contextVersion, _ := mapParams[bqutil.ContextVersionKey].(string)
+	summarize, _ := mapParams[bqutil.SummarizeKey].(bool)
+
+	location := source.BigQueryLocation()
+	if location == "" {
+		location = defaultDataAgentLocation
+	}
+	requestedProject, _ := mapParams[bqutil.ProjectKey].(string)
+	projectID, err := bqutil.ResolveProject(requestedProject, source.BigQueryProject(), t.AllowedProjects)
+	if err != nil {
+		return nil, util.NewAgentError(fmt.Sprintf("invalid '%s' parameter", bqutil.ProjectKey), err)
+	}
+	resourceName, err := bqutil.ResolveDataAgentName(dataAgentID, projectID, location)
+	if err != nil {
+		return nil, util.NewAgentError(fmt.Sprintf("invalid '%s' parameter", dataAgentIDKey), err)
+	}
+
+	// Get credentials for the API call
+	tokenStr, client, tbErr := bqutil.GetAPIToken(ctx, t.Name, source, accessToken, nil)
+	if tbErr != nil {
+		return nil, tbErr
+	}
+
+	var key string
+	if t.cache != nil {
+		key = cacheKey(cacheIdentity(tokenStr, client), t.Source, resourceName)
+		if !forceRefresh {
+			if agent, ok := t.cache.get(key); ok {
+				return t.result(ctx, source, agent, contextVersion, summarize)
+			}
+		}
+	}
+
+	var maxBytes int64
+	if t.ResponseTruncation == "" {
+		maxBytes = t.MaxResponseBytes
+	}
+	agent, tbErr := getDataAgent(ctx, resourceName, googlehttp.StaticToken(tokenStr), maxBytes)
+	if tbErr != nil {
+		return nil, tbErr
+	}
+
+	if t.cache != nil {
+		t.cache.set(key, agent)
+	}
+	return t.result(ctx, source, agent, contextVersion, summarize)
+}
+
+// result returns the given context version of agent, or its summary, after
+// checking its datasets if the tool sets checkDataAgentDatasets.
+func (t Tool) result(ctx context.Context, source compatibleSource, agent map[string]any, contextVersion string, summarize bool) (any, util.ToolboxError) {
+	var selected any
+	if summarize {
+		selected = bqutil.SummarizeDataAgent(agent, contextVersion)
+	} else {
+		selected = bqutil.SelectContext(agent, contextVersion)
+	}
+	if t.ResponseTruncation == elideArrays {
+		elided, err := googlehttp.ElideArrays(selected, int(t.MaxResponseBytes))
+		if err != nil {
+			return nil, util.NewAgentError(fmt.Sprintf("the data agent is too large to return within maxResponseBytes; try '%s'", bqutil.SummarizeKey), err)
+		}
+		selected = elided
+	}
+	if t.CheckDataAgentDatasets == "" {
+		return selected, nil
+	}
+	warnings, tbErr := bqutil.CheckDataAgentDatasets(ctx, t.Name, source, t.CheckDataAgentDatasets, agent, contextVersion)
+	if tbErr != nil {
+		return nil, tbErr
+	}
+	if len(warnings) == 0 {
+		return selected, nil
+	}
+	return tools.Result{Data: selected, Metadata: map[string]any{bqutil.DataAgentDatasetsWarningsKey: warnings}, IncludeMetadata: true}, nil
+}
+
+// verifyDataAgent checks that the data agent exists and is accessible with
+// the source's ADC credentials. Sources using client OAuth have no credentials
+// at startup, so the check is skipped for them.
+func verifyDataAgent(ctx context.Context, s compatibleSource, resourceName string) error {
+	if s.UseClientAuthorization() {
+		if logger, err := util.LoggerFromContext(ctx); err == nil {
+			logger.WarnContext(ctx, fmt.Sprintf("skipping startup verification of data agent %q: source uses client OAuth", resourceName))
+		}
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, verifyTimeout)
+	defer cancel()
+
+	tokenSource, err := s.BigQueryTokenSourceWithScope(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get token source: %w", err)
+	}
+	if _, tbErr := getDataAgent(ctx, resourceName, googlehttp.FromTokenSource(tokenSource), 0); tbErr != nil {
+		return tbErr
+	}
+	return nil
+}
+
+// getDataAgent fetches a data agent document from the Gemini Data Analytics
+// API. Documents larger than maxBytes, if not zero, are refused.
+func getDataAgent(ctx context.Context, resourceName string, token googlehttp.TokenFunc, maxBytes int64) (map[string]any, util.ToolboxError) {
+	client := googlehttp.Client{Token: token, MaxRetries: maxRetries, MaxResponseBytes: maxBytes}
+	var agent map[string]any
+	if err := client.DoJSON(ctx, http.MethodGet, fmt.Sprintf("%s/%s", gdaBaseURL, resourceName), nil, &agent); err != nil {
+		return nil, googlehttp.ToolboxError(err, fmt.Sprintf("failed to get data agent %q", resourceName))
+	}
+	return agent, nil
+}
+
+func (t Tool) EmbedParams(ctx context.Context, paramValues parameters.ParamValues, embeddingModelsMap map[string]embeddingmodels.EmbeddingModel) (parameters.ParamValues, error) {
+	return parameters.EmbedParams(ctx, t.Parameters, paramValues, embeddingModelsMap, nil)
+}
+
+func (t Tool) Manifest() tools.Manifest {
+	return t.manifest
+}
+
+func (t Tool) McpManifest() tools.McpManifest {
+	return t.mcpManifest
+}
+
+func (t Tool) Authorized(verifiedAuthServices []string) bool {
+	return tools.IsAuthorized(t.AuthRequired, verifiedAuthServices)
+}
+
+func (t Tool) RequiresClientAuthorization(resourceMgr tools.SourceProvider) (bool, error) {
+	source, err := tools.GetCompatibleSource[compatibleSource](resourceMgr, t.Source, t.Name, t.Type)
+	if err != nil {
+		return false, err
+	}
+	return source.UseClientAuthorization(), nil
+}
+
+func (t Tool) GetAuthTokenHeaderName(resourceMgr tools.SourceProvider) (string, error) {
+	return "Authorization", nil
+}
+
+func (t Tool) GetParameters() parameters.Parameters {
+	return t.Parameters
+}
@@ -0,0 +1,120 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerygetdataagentinfo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// adcIdentity is the cache identity used for requests authorized with the
+// source's Application Default Credentials.
+const adcIdentity = "adc"
+
+// agentCacheEntry holds a cached data agent document and its expiration time.
+type agentCacheEntry struct {
+	value     map[string]any
+	expiresAt time.Time
+}
+
+// agentCache is a thread-safe, size- and TTL-bounded cache of data agent
+// documents. Entries are keyed by the caller's identity and the agent's full
+// resource name so that cached documents are never shared across credentials.
+type agentCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]agentCacheEntry
+	now        func() time.Time
+}
+
+func newAgentCache(ttl time.Duration, maxEntries int) *agentCache {
+	return &agentCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]agentCacheEntry),
+		now:        time.Now,
+	}
+}
+
+// cacheIdentity returns the identity component of a cache key. Client OAuth
+// tokens are hashed so that raw credentials are never kept in memory as keys.
+func cacheIdentity(tokenStr string, useClientOAuth bool) string {
+	if !useClientOAuth {
+		return adcIdentity
+	}
+	sum := sha256.Sum256([]byte(tokenStr))
+	return "oauth:" + hex.EncodeToString(sum[:])
+}
+
+// cacheKey returns the key of the document of resourceName fetched with
+// identity from the source named sourceName, as the sources a tool selects
+// among may use different credentials under the same identity.
+func cacheKey(identity, sourceName, resourceName string) string {
+	return identity + "|" + sourceName + "|" + resourceName
+}
+
+// get returns the cached document for key if present and not expired.
+func (c *agentCache) get(key string) (map[string]any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// set stores value under key. When the cache is full, expired entries are
+// dropped first and then the entry closest to expiring is evicted.
+func (c *agentCache) set(key string, value map[string]any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if _, exists := c.entries[key]; !exists && len(c.entries) >= c.maxEntries {
+		for k, e := range c.entries {
+			if !now.Before(e.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= c.maxEntries {
+			var oldestKey string
+			var oldest time.Time
+			for k, e := range c.entries {
+				if oldestKey == "" || e.expiresAt.Before(oldest) {
+					oldestKey, oldest = k, e.expiresAt
+				}
+			}
+			delete(c.entries, oldestKey)
+		}
+	}
+	c.entries[key] = agentCacheEntry{value: value, expiresAt: now.Add(c.ttl)}
+}
+
+// len returns the number of entries currently held, including expired ones
+// that have not been evicted yet.
+func (c *agentCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
@@ -0,0 +1,319 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerygetdataagentinfo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	"github.com/googleapis/genai-toolbox/internal/testutils/gdafake"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	bqutil "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+	"golang.org/x/oauth2"
+)
+
+type fakeSource struct {
+	useClientOAuth bool
+	// allowedDatasets are the allowed datasets, "project.dataset". If empty,
+	// all datasets are allowed.
+	allowedDatasets []string
+}
+
+func (s *fakeSource) SourceType() string             { return "bigquery" }
+func (s *fakeSource) ToConfig() sources.SourceConfig { return nil }
+func (s *fakeSource) BigQueryProject() string        { return "test-project" }
+func (s *fakeSource) BigQueryLocation() string       { return "" }
+func (s *fakeSource) UseClientAuthorization() bool   { return s.useClientOAuth }
+func (s *fakeSource) ClientAuthorizationMode() string {
+	if s.useClientOAuth {
+		return "required"
+	}
+	return "disabled"
+}
+func (s *fakeSource) IsDatasetAllowed(projectID, datasetID string) bool {
+	return len(s.allowedDatasets) == 0 || slices.Contains(s.allowedDatasets, projectID+"."+datasetID)
+}
+func (s *fakeSource) BigQueryAllowedDatasets() []string { return s.allowedDatasets }
+func (s *fakeSource) BigQueryTokenSourceWithScope(ctx context.Context, scopes []string) (oauth2.TokenSource, error) {
+	return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "adc-token"}), nil
+}
+
+// newFakeAPI starts a fake of the Gemini Data Analytics API with the data
+// agents named agents, and points the tool at it.
+func newFakeAPI(t *testing.T, agents ...string) *gdafake.Server {
+	t.Helper()
+	fake := gdafake.New(t)
+	for _, name := range agents {
+		fake.AddDataAgent(name, nil)
+	}
+	originalURL := gdaBaseURL
+	gdaBaseURL = fake.URL
+	t.Cleanup(func() { gdaBaseURL = originalURL })
+	return fake
+}
+
+type fakeSourceProvider struct {
+	source sources.Source
+}
+
+func (p fakeSourceProvider) GetSource(name string) (sources.Source, bool) {
+	return p.source, true
+}
+
+func TestAgentCacheExpiry(t *testing.T) {
+	now := time.Now()
+	c := newAgentCache(5*time.Minute, 10)
+	c.now = func() time.Time { return now }
+
+	c.set("k", map[string]any{"name": "a"})
+	if _, ok := c.get("k"); !ok {
+		t.Fatalf("expected cache hit before expiry")
+	}
+
+	now = now.Add(5 * time.Minute)
+	if _, ok := c.get("k"); ok {
+		t.Fatalf("expected cache miss after expiry")
+	}
+	if got := c.len(); got != 0 {
+		t.Fatalf("expected expired entry to be removed, cache has %d entries", got)
+	}
+}
+
+func TestAgentCacheSizeBound(t *testing.T) {
+	now := time.Now()
+	c := newAgentCache(time.Minute, 2)
+	c.now = func() time.Time { return now }
+
+	c.set("a", map[string]any{})
+	now = now.Add(time.Second)
+	c.set("b", map[string]any{})
+	now = now.Add(time.Second)
+	c.set("c", map[string]any{})
+
+	if got := c.len(); got != 2 {
+		t.Fatalf("expected 2 entries, got %d", got)
+	}
+	if _, ok := c.get("a"); ok {
+		t.Errorf("expected oldest entry %q to be evicted", "a")
+	}
+	for _, k := range []string{"b", "c"} {
+		if _, ok := c.get(k); !ok {
+			t.Errorf("expected entry %q to be cached", k)
+		}
+	}
+}
+
+func TestAgentCacheIdentityIsolation(t *testing.T) {
+	c := newAgentCache(time.Minute, 10)
+	resource := "projects/p/locations/global/dataAgents/a"
+
+	userA := cacheKey(cacheIdentity("token-a", true), "src", resource)
+	userB := cacheKey(cacheIdentity("token-b", true), "src", resource)
+	adc := cacheKey(cacheIdentity("token-a", false), "src", resource)
+	otherSource := cacheKey(cacheIdentity("token-a", false), "other-src", resource)
+
+	c.set(userA, map[string]any{"owner": "a"})
+	if _, ok := c.get(userB); ok {
+		t.Errorf("client OAuth user B must not see user A's cached agent")
+	}
+	if _, ok := c.get(adc); ok {
+		t.Errorf("ADC lookups must not see client OAuth cached agents")
+	}
+
+	c.set(adc, map[string]any{"owner": "adc"})
+	if _, ok := c.get(otherSource); ok {
+		t.Errorf("ADC lookups of another source must not see the cached agents of the source")
+	}
+}
+
+func TestAgentCacheConcurrentAccess(t *testing.T) {
+	c := newAgentCache(time.Minute, 8)
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				key := fmt.Sprintf("key-%d", (i+j)%12)
+				c.set(key, map[string]any{"i": i})
+				c.get(key)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if got := c.len(); got > 8 {
+		t.Fatalf("cache exceeded its bound: %d entries", got)
+	}
+}
+
+func TestInvokeUsesCache(t *testing.T) {
+	const agent = "projects/test-project/locations/global/dataAgents/my-agent"
+	fake := newFakeAPI(t)
+	fake.AddDataAgent(agent, map[string]any{"description": "v1"})
+	calls := func() int { return len(fake.Requests()) }
+
+	source := &fakeSource{useClientOAuth: true}
+	rawTool, err := Config{Name: "get_agent", Type: resourceType, Source: "src", Description: "d", CacheTTL: "5m"}.Initialize(map[string]sources.Source{"src": source})
+	if err != nil {
+		t.Fatalf("unexpected error initializing tool: %s", err)
+	}
+	tool := rawTool.(Tool)
+	provider := fakeSourceProvider{source: source}
+
+	invoke := func(token string, forceRefresh bool) map[string]any {
+		t.Helper()
+		params := parameters.ParamValues{
+			{Name: dataAgentIDKey, Value: "my-agent"},
+			{Name: forceRefreshKey, Value: forceRefresh},
+		}
+		res, tbErr := tool.Invoke(context.Background(), provider, params, tools.AccessToken("Bearer "+token))
+		if tbErr != nil {
+			t.Fatalf("unexpected invoke error: %s", tbErr)
+		}
+		return res.(map[string]any)
+	}
+
+	invoke("user-a", false)
+	// A change to the agent is only seen by lookups that miss the cache.
+	fake.AddDataAgent(agent, map[string]any{"description": "v2"})
+	if res := invoke("user-a", false); res["description"] != "v1" || calls() != 1 {
+		t.Fatalf("expected repeated lookup to be served from cache, got %v after %d API calls", res, calls())
+	}
+
+	res := invoke("user-b", false)
+	if got := calls(); got != 2 {
+		t.Fatalf("expected a different credential to miss the cache, got %d API calls", got)
+	}
+	if res["description"] != "v2" {
+		t.Fatalf("user B received a document fetched with another credential: %v", res)
+	}
+	if got := fake.Requests()[1].Header.Get("Authorization"); got != "Bearer user-b" {
+		t.Fatalf("expected user B's lookup to use their credential, got %q", got)
+	}
+
+	invoke("user-a", true)
+	if got := calls(); got != 3 {
+		t.Fatalf("expected force_refresh to bypass the cache, got %d API calls", got)
+	}
+	for _, r := range fake.Requests() {
+		if r.Path != "/"+agent {
+			t.Errorf("unexpected request path: %s", r.Path)
+		}
+	}
+}
+
+func TestInitializeInvalidCacheTTL(t *testing.T) {
+	srcs := map[string]sources.Source{"src": &fakeSource{}}
+	for _, ttl := range []string{"five minutes", "-1m", "0s"} {
+		_, err := Config{Name: "get_agent", Type: resourceType, Source: "src", Description: "d", CacheTTL: ttl}.Initialize(srcs)
+		if err == nil {
+			t.Errorf("expected error for cacheTtl %q", ttl)
+		}
+	}
+}
+
+func TestInvokeResourceNameForms(t *testing.T) {
+	fake := newFakeAPI(t, "projects/test-project/locations/global/dataAgents/my-agent")
+
+	source := &fakeSource{}
+	rawTool, err := Config{Name: "get_agent", Type: resourceType, Source: "src", Description: "d"}.Initialize(map[string]sources.Source{"src": source})
+	if err != nil {
+		t.Fatalf("unexpected error initializing tool: %s", err)
+	}
+	tool := rawTool.(Tool)
+	provider := fakeSourceProvider{source: source}
+
+	for _, id := range []string{"my-agent", "projects/test-project/locations/global/dataAgents/my-agent"} {
+		params := parameters.ParamValues{{Name: dataAgentIDKey, Value: id}}
+		if _, tbErr := tool.Invoke(context.Background(), provider, params, ""); tbErr != nil {
+			t.Fatalf("unexpected invoke error for %q: %s", id, tbErr)
+		}
+	}
+	want := "/projects/test-project/locations/global/dataAgents/my-agent"
+	if paths := fake.Paths(); len(paths) != 2 || paths[0] != want || paths[1] != want {
+		t.Fatalf("expected both input forms to request %q, got %v", want, paths)
+	}
+
+	params := parameters.ParamValues{{Name: dataAgentIDKey, Value: "projects/other-project/locations/global/dataAgents/my-agent"}}
+	_, tbErr := tool.Invoke(context.Background(), provider, params, "")
+	if tbErr == nil {
+		t.Fatalf("expected an error for a data agent in another project")
+	}
+	if tbErr.Category() != util.CategoryAgent {
+		t.Errorf("expected an agent error, got %s", tbErr.Category())
+	}
+	if len(fake.Paths()) != 2 {
+		t.Errorf("expected no API call for a rejected resource name")
+	}
+}
+
+func TestInvokeProjectOverride(t *testing.T) {
+	fake := newFakeAPI(t,
+		"projects/test-project/locations/global/dataAgents/my-agent",
+		"projects/agents-project/locations/global/dataAgents/my-agent",
+	)
+
+	source := &fakeSource{}
+	cfg := Config{Name: "get_agent", Type: resourceType, Source: "src", Description: "d", AllowedProjects: []string{"agents-project"}}
+	rawTool, err := cfg.Initialize(map[string]sources.Source{"src": source})
+	if err != nil {
+		t.Fatalf("unexpected error initializing tool: %s", err)
+	}
+	tool := rawTool.(Tool)
+	provider := fakeSourceProvider{source: source}
+
+	tcs := []struct {
+		desc     string
+		project  string
+		wantPath string
+		wantErr  bool
+	}{
+		{desc: "default project", project: "", wantPath: "/projects/test-project/locations/global/dataAgents/my-agent"},
+		{desc: "allowed override", project: "agents-project", wantPath: "/projects/agents-project/locations/global/dataAgents/my-agent"},
+		{desc: "rejected override", project: "other-project", wantErr: true},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			fake.ResetRequests()
+			params := parameters.ParamValues{
+				{Name: dataAgentIDKey, Value: "my-agent"},
+				{Name: bqutil.ProjectKey, Value: tc.project},
+			}
+			_, tbErr := tool.Invoke(context.Background(), provider, params, "")
+			if tc.wantErr {
+				if tbErr == nil || tbErr.Category() != util.CategoryAgent {
+					t.Fatalf("expected an agent error, got %v", tbErr)
+				}
+				if paths := fake.Paths(); len(paths) != 0 {
+					t.Fatalf("expected no API call for a rejected project, got %v", paths)
+				}
+				return
+			}
+			if tbErr != nil {
+				t.Fatalf("unexpected invoke error: %s", tbErr)
+			}
+			if paths := fake.Paths(); len(paths) != 1 || paths[0] != tc.wantPath {
+				t.Fatalf("expected a request to %q, got %v", tc.wantPath, paths)
+			}
+		})
+	}
+}
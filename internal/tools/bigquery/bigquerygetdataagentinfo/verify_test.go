@@ -0,0 +1,419 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerygetdataagentinfo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	"github.com/googleapis/genai-toolbox/internal/testutils/gdafake"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	bqutil "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+)
+
+func TestInitializeVerifyOnStartup(t *testing.T) {
+	fake := newFakeAPI(t,
+		"projects/test-project/locations/global/dataAgents/my-agent",
+		"projects/test-project/locations/global/dataAgents/forbidden-agent",
+	)
+	fake.Inject(gdafake.Fault{PathSuffix: "/dataAgents/forbidden-agent", Status: http.StatusForbidden})
+
+	tcs := []struct {
+		desc           string
+		defaultAgent   string
+		useClientOAuth bool
+		wantErr        string
+		wantCalls      int
+	}{
+		{desc: "existing agent", defaultAgent: "my-agent", wantCalls: 1},
+		{desc: "full resource name", defaultAgent: "projects/test-project/locations/global/dataAgents/my-agent", wantCalls: 1},
+		{desc: "missing agent", defaultAgent: "my-agnet", wantErr: "404", wantCalls: 1},
+		{desc: "inaccessible agent", defaultAgent: "forbidden-agent", wantErr: "403", wantCalls: 1},
+		{desc: "client OAuth skips check", defaultAgent: "my-agnet", useClientOAuth: true},
+		{desc: "agent in another project", defaultAgent: "projects/other/locations/global/dataAgents/my-agent", wantErr: "invalid defaultAgent"},
+		{desc: "no default agent", wantErr: "requires defaultAgent"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			fake.ResetRequests()
+			cfg := Config{Name: "get_agent", Type: resourceType, Source: "src", Description: "d", DefaultAgent: tc.defaultAgent, VerifyOnStartup: true}
+			_, err := cfg.InitializeWithContext(context.Background(), map[string]sources.Source{"src": &fakeSource{useClientOAuth: tc.useClientOAuth}})
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+			if paths := fake.Paths(); len(paths) != tc.wantCalls {
+				t.Fatalf("expected %d API calls, got %v", tc.wantCalls, paths)
+			}
+		})
+	}
+}
+
+func TestInvokeDefaultAgent(t *testing.T) {
+	fake := newFakeAPI(t, "projects/test-project/locations/global/dataAgents/my-agent")
+
+	source := &fakeSource{}
+	rawTool, err := Config{Name: "get_agent", Type: resourceType, Source: "src", Description: "d", DefaultAgent: "my-agent"}.Initialize(map[string]sources.Source{"src": source})
+	if err != nil {
+		t.Fatalf("unexpected error initializing tool: %s", err)
+	}
+	if paths := fake.Paths(); len(paths) != 0 {
+		t.Fatalf("expected no startup check without verifyOnStartup, got %v", paths)
+	}
+	tool := rawTool.(Tool)
+
+	params, err := parameters.ParseParams(tool.Parameters, map[string]any{}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error parsing params: %s", err)
+	}
+	if _, tbErr := tool.Invoke(context.Background(), fakeSourceProvider{source: source}, params, ""); tbErr != nil {
+		t.Fatalf("unexpected invoke error: %s", tbErr)
+	}
+	want := "/projects/test-project/locations/global/dataAgents/my-agent"
+	if paths := fake.Paths(); len(paths) != 1 || paths[0] != want {
+		t.Fatalf("expected a request to %q, got %v", want, paths)
+	}
+}
+
+func TestInvokeContextVersion(t *testing.T) {
+	const agent = "projects/test-project/locations/global/dataAgents/my-agent"
+	fake := newFakeAPI(t)
+	fake.AddDataAgent(agent, map[string]any{"dataAnalyticsAgent": map[string]any{
+		"publishedContext":     map[string]any{"systemInstruction": "published"},
+		"lastPublishedContext": map[string]any{"systemInstruction": "published"},
+		"stagingContext":       map[string]any{"systemInstruction": "staging"},
+	}})
+
+	source := &fakeSource{}
+	rawTool, err := Config{Name: "get_agent", Type: resourceType, Source: "src", Description: "d", CacheTTL: "5m"}.Initialize(map[string]sources.Source{"src": source})
+	if err != nil {
+		t.Fatalf("unexpected error initializing tool: %s", err)
+	}
+	tool := rawTool.(Tool)
+
+	tcs := []struct {
+		desc string
+		args map[string]any
+		want []string
+	}{
+		{desc: "default", args: map[string]any{dataAgentIDKey: "my-agent"}, want: []string{"lastPublishedContext", "publishedContext"}},
+		{desc: "staging", args: map[string]any{dataAgentIDKey: "my-agent", "context_version": "staging"}, want: []string{"stagingContext"}},
+		{desc: "published", args: map[string]any{dataAgentIDKey: "my-agent", "context_version": "PUBLISHED"}, want: []string{"lastPublishedContext", "publishedContext"}},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			params, err := parameters.ParseParams(tool.Parameters, tc.args, nil)
+			if err != nil {
+				t.Fatalf("unexpected error parsing params: %s", err)
+			}
+			res, tbErr := tool.Invoke(context.Background(), fakeSourceProvider{source: source}, params, "")
+			if tbErr != nil {
+				t.Fatalf("unexpected invoke error: %s", tbErr)
+			}
+			got := slices.Sorted(maps.Keys(res.(map[string]any)["dataAnalyticsAgent"].(map[string]any)))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected contexts (-want +got):\n%s", diff)
+			}
+		})
+	}
+	// Filtering must not alter the cached document shared by the lookups.
+	if got := len(fake.Requests()); got != 1 {
+		t.Errorf("expected the lookups to share a cached document, got %d API calls", got)
+	}
+
+	params, err := parameters.ParseParams(tool.Parameters, map[string]any{dataAgentIDKey: "my-agent", "context_version": "draft"}, nil)
+	if err == nil {
+		t.Fatalf("expected an unknown context version to be rejected, got %v", params)
+	}
+}
+
+func TestInvokeSummarize(t *testing.T) {
+	const agent = "projects/test-project/locations/global/dataAgents/my-agent"
+	fake := newFakeAPI(t)
+	fake.AddDataAgent(agent, map[string]any{
+		"displayName": "My agent",
+		"dataAnalyticsAgent": map[string]any{
+			"publishedContext": map[string]any{
+				"systemInstruction": "published",
+				"datasourceReferences": map[string]any{"bq": map[string]any{"tableReferences": []any{
+					map[string]any{"projectId": "p", "datasetId": "d", "tableId": "t", "schema": map[string]any{
+						"fields": []any{map[string]any{"name": "id", "type": "INT64", "mode": "REQUIRED"}},
+					}},
+				}}},
+			},
+			"stagingContext": map[string]any{"systemInstruction": "staging"},
+		},
+	})
+
+	source := &fakeSource{}
+	rawTool, err := Config{Name: "get_agent", Type: resourceType, Source: "src", Description: "d"}.Initialize(map[string]sources.Source{"src": source})
+	if err != nil {
+		t.Fatalf("unexpected error initializing tool: %s", err)
+	}
+	tool := rawTool.(Tool)
+	params, err := parameters.ParseParams(tool.Parameters, map[string]any{dataAgentIDKey: "my-agent", "summarize": true}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error parsing params: %s", err)
+	}
+	res, tbErr := tool.Invoke(context.Background(), fakeSourceProvider{source: source}, params, "")
+	if tbErr != nil {
+		t.Fatalf("unexpected invoke error: %s", tbErr)
+	}
+	want := bqutil.DataAgentSummary{
+		Name:              agent,
+		DisplayName:       "My agent",
+		SystemInstruction: "published",
+		Tables: []bqutil.TableSummary{
+			{Project: "p", Dataset: "d", Table: "t", Columns: []bqutil.ColumnSummary{{Name: "id", Type: "INT64"}}},
+		},
+	}
+	if diff := cmp.Diff(want, res); diff != "" {
+		t.Errorf("unexpected summary (-want +got):\n%s", diff)
+	}
+}
+
+func TestInvokeMaxResponseBytes(t *testing.T) {
+	const agent = "projects/test-project/locations/global/dataAgents/my-agent"
+	refs := make([]any, 0, 50)
+	for i := range 50 {
+		refs = append(refs, map[string]any{"projectId": "p", "datasetId": "d", "tableId": fmt.Sprintf("table_%d", i)})
+	}
+	fake := newFakeAPI(t)
+	fake.AddDataAgent(agent, map[string]any{
+		"displayName": "My agent",
+		"dataAnalyticsAgent": map[string]any{
+			"publishedContext": map[string]any{
+				"systemInstruction":    "published",
+				"datasourceReferences": map[string]any{"bq": map[string]any{"tableReferences": refs}},
+			},
+		},
+	})
+	source := &fakeSource{}
+	invoke := func(cfg Config) (any, util.ToolboxError) {
+		t.Helper()
+		rawTool, err := cfg.Initialize(map[string]sources.Source{"src": source})
+		if err != nil {
+			t.Fatalf("unexpected error initializing tool: %s", err)
+		}
+		tool := rawTool.(Tool)
+		params, err := parameters.ParseParams(tool.Parameters, map[string]any{dataAgentIDKey: "my-agent"}, nil)
+		if err != nil {
+			t.Fatalf("unexpected error parsing params: %s", err)
+		}
+		return tool.Invoke(context.Background(), fakeSourceProvider{source: source}, params, "")
+	}
+	cfg := Config{Name: "get_agent", Type: resourceType, Source: "src", Description: "d", MaxResponseBytes: 1000}
+
+	// without truncation, the document is refused
+	_, tbErr := invoke(cfg)
+	if tbErr == nil || !strings.Contains(tbErr.Error(), "larger than the limit of 1000 bytes") {
+		t.Fatalf("expected the response to be refused, got %v", tbErr)
+	}
+	if tbErr.Category() != util.CategoryAgent {
+		t.Errorf("expected an agent error, got %s", tbErr.Category())
+	}
+
+	cfg.ResponseTruncation = elideArrays
+	res, tbErr := invoke(cfg)
+	if tbErr != nil {
+		t.Fatalf("unexpected invoke error: %s", tbErr)
+	}
+	b, err := json.Marshal(res)
+	if err != nil {
+		t.Fatalf("unable to encode result: %s", err)
+	}
+	if len(b) > 1000 {
+		t.Errorf("got a result of %d bytes, want at most 1000", len(b))
+	}
+	for _, want := range []string{`"displayName":"My agent"`, `"systemInstruction":"published"`, `"table_0"`, `"elidedItems"`} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("result %s does not contain %s", b, want)
+		}
+	}
+
+	cfg.MaxResponseBytes = 10
+	if _, tbErr := invoke(cfg); tbErr == nil || !strings.Contains(tbErr.Error(), "too large to return within maxResponseBytes") {
+		t.Errorf("expected an error for a result that cannot be elided, got %v", tbErr)
+	}
+}
+
+func TestInitializeInvalidResponseTruncation(t *testing.T) {
+	srcs := map[string]sources.Source{"src": &fakeSource{}}
+	tcs := []struct {
+		desc    string
+		cfg     Config
+		wantErr string
+	}{
+		{desc: "negative limit", cfg: Config{MaxResponseBytes: -1}, wantErr: "must not be negative"},
+		{desc: "truncation without limit", cfg: Config{ResponseTruncation: elideArrays}, wantErr: "requires maxResponseBytes"},
+		{desc: "unknown truncation", cfg: Config{MaxResponseBytes: 100, ResponseTruncation: "truncate"}, wantErr: `invalid responseTruncation "truncate"`},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			cfg := tc.cfg
+			cfg.Name, cfg.Type, cfg.Source, cfg.Description = "get_agent", resourceType, "src", "d"
+			_, err := cfg.Initialize(srcs)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("got error %v, want it to contain %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestInvokeChecksDataAgentDatasets(t *testing.T) {
+	tables := func(datasets ...string) map[string]any {
+		refs := make([]any, 0, len(datasets))
+		for _, dataset := range datasets {
+			refs = append(refs, map[string]any{"projectId": "p", "datasetId": dataset, "tableId": "t"})
+		}
+		return map[string]any{"bq": map[string]any{"tableReferences": refs}}
+	}
+	fake := newFakeAPI(t)
+	for name, refs := range map[string]map[string]any{
+		"allowed": tables("sales"),
+		"mixed":   tables("sales", "hr"),
+		"looker":  {"looker": map[string]any{"exploreReferences": []any{map[string]any{"lookmlModel": "m", "explore": "e"}}}},
+	} {
+		fake.AddDataAgent("projects/test-project/locations/global/dataAgents/"+name, map[string]any{"dataAnalyticsAgent": map[string]any{
+			"publishedContext": map[string]any{"datasourceReferences": refs},
+		}})
+	}
+
+	tcs := []struct {
+		desc         string
+		mode         string
+		agent        string
+		wantErr      string
+		wantWarnings []string
+	}{
+		{desc: "unchecked", agent: "mixed"},
+		{desc: "allowed", mode: "enforce", agent: "allowed"},
+		{desc: "mixed", mode: "enforce", agent: "mixed", wantErr: "not in the allowed list: 'p.hr'"},
+		{desc: "mixed with warnings", mode: "warn", agent: "mixed", wantWarnings: []string{"the data agent uses datasets that are not in the allowed list: 'p.hr'"}},
+		{desc: "looker", mode: "enforce", agent: "looker"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			source := &fakeSource{allowedDatasets: []string{"p.sales"}}
+			rawTool, err := Config{Name: "get_agent", Type: resourceType, Source: "src", Description: "d", CheckDataAgentDatasets: tc.mode}.Initialize(map[string]sources.Source{"src": source})
+			if err != nil {
+				t.Fatalf("unexpected error initializing tool: %s", err)
+			}
+			params := parameters.ParamValues{{Name: dataAgentIDKey, Value: tc.agent}}
+			res, tbErr := rawTool.Invoke(context.Background(), fakeSourceProvider{source: source}, params, "")
+			if tc.wantErr != "" {
+				if tbErr == nil || !strings.Contains(tbErr.Error(), tc.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.wantErr, tbErr)
+				}
+				return
+			}
+			if tbErr != nil {
+				t.Fatalf("unexpected invoke error: %s", tbErr)
+			}
+			result, ok := res.(tools.Result)
+			if len(tc.wantWarnings) == 0 {
+				if ok {
+					t.Fatalf("expected the data agent without warnings, got %v", result)
+				}
+				return
+			}
+			if !ok || !result.IncludeMetadata {
+				t.Fatalf("expected a result with metadata, got %v", res)
+			}
+			if diff := cmp.Diff(tc.wantWarnings, result.Metadata["datasetWarnings"]); diff != "" {
+				t.Errorf("unexpected warnings (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMcpManifestOutputSchema(t *testing.T) {
+	tool, err := Config{Name: "get_agent", Type: resourceType, Source: "src", Description: "d"}.Initialize(map[string]sources.Source{"src": &fakeSource{}})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	b, err := json.Marshal(tool.McpManifest())
+	if err != nil {
+		t.Fatalf("unable to marshal manifest: %s", err)
+	}
+	var got struct {
+		OutputSchema map[string]any `json:"outputSchema"`
+	}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("unable to unmarshal manifest: %s", err)
+	}
+	if got.OutputSchema["type"] != "object" {
+		t.Fatalf("expected an object output schema, got %v", got.OutputSchema)
+	}
+	if _, ok := got.OutputSchema["properties"].(map[string]any)["dataAnalyticsAgent"]; !ok {
+		t.Fatalf("expected the output schema to describe dataAnalyticsAgent, got %v", got.OutputSchema)
+	}
+}
+
+func TestParameterDescriptions(t *testing.T) {
+	cfg := Config{
+		Name:         "get_agent",
+		Type:         resourceType,
+		Source:       "src",
+		Description:  "d",
+		DefaultAgent: "my-agent",
+		ParameterDescriptions: tools.ParameterDescriptions{
+			dataAgentIDKey: "L'identifiant de l'agent.",
+		},
+	}
+	tool, err := cfg.Initialize(map[string]sources.Source{"src": &fakeSource{}})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var found bool
+	for _, p := range tool.Manifest().Parameters {
+		if p.Name != dataAgentIDKey {
+			continue
+		}
+		found = true
+		if p.Description != "L'identifiant de l'agent." || p.Default != "my-agent" || p.Required {
+			t.Fatalf("expected the description to be overridden and the default kept, got %+v", p)
+		}
+	}
+	if !found {
+		t.Fatalf("no %s parameter in the manifest", dataAgentIDKey)
+	}
+	mcpProps := tool.McpManifest().InputSchema.Properties
+	if got := mcpProps[dataAgentIDKey].Description; got != "L'identifiant de l'agent." {
+		t.Fatalf("expected the MCP manifest to use the override, got %q", got)
+	}
+
+	// force_refresh is only generated with a cache
+	cfg.ParameterDescriptions = tools.ParameterDescriptions{forceRefreshKey: "Forcer."}
+	if _, err := cfg.Initialize(map[string]sources.Source{"src": &fakeSource{}}); err == nil || !strings.Contains(err.Error(), "no parameters named force_refresh") {
+		t.Fatalf("expected an error for a parameter that is not generated, got %v", err)
+	}
+	cfg.CacheTTL = "5m"
+	if _, err := cfg.Initialize(map[string]sources.Source{"src": &fakeSource{}}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
@@ -31,8 +31,6 @@ import (
 )
 
 const resourceType string = "bigquery-get-dataset-info"
-const projectKey string = "project"
-const datasetKey string = "dataset"
 
 func init() {
 	if !tools.Register(resourceType, newConfig) {
@@ -53,15 +51,25 @@ type compatibleSource interface {
 	UseClientAuthorization() bool
 	IsDatasetAllowed(projectID, datasetID string) bool
 	BigQueryAllowedDatasets() []string
-	RetrieveClientAndService(tools.AccessToken) (*bigqueryapi.Client, *bigqueryrestapi.Service, error)
+	RetrieveClientAndService(context.Context, tools.AccessToken) (*bigqueryapi.Client, *bigqueryrestapi.Service, error)
 }
 
 type Config struct {
-	Name         string   `yaml:"name" validate:"required"`
-	Type         string   `yaml:"type" validate:"required"`
-	Source       string   `yaml:"source" validate:"required"`
-	Description  string   `yaml:"description" validate:"required"`
-	AuthRequired []string `yaml:"authRequired"`
+	Name         string                 `yaml:"name" validate:"required"`
+	Type         string                 `yaml:"type" validate:"required"`
+	Source       string                 `yaml:"source" validate:"required"`
+	Description  string                 `yaml:"description" validate:"required"`
+	AuthRequired []string               `yaml:"authRequired"`
+	Annotations  *tools.ToolAnnotations `yaml:"annotations,omitempty"`
+	// ParameterDescriptions overrides the descriptions of the project and
+	// dataset parameters, by parameter name.
+	ParameterDescriptions tools.ParameterDescriptions `yaml:"parameterDescriptions"`
+	// ParameterAliases are other names the values of the parameters are
+	// accepted under, by alias.
+	ParameterAliases tools.ParameterAliases `yaml:"parameterAliases"`
+	// ExpandDescriptions expands the source properties referenced in the
+	// descriptions of the tool and its parameters, e.g. {{ .Source.Project }}.
+	ExpandDescriptions bool `yaml:"expandDescriptions"`
 }
 
 // validate interface
@@ -81,23 +89,30 @@ func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error)
 	// verify the source is compatible
 	s, ok := rawS.(compatibleSource)
 	if !ok {
-		return nil, fmt.Errorf("invalid source for %q tool: source %q not compatible", resourceType, cfg.Source)
+		return nil, tools.IncompatibleSourceError[compatibleSource](rawS, cfg.Source, resourceType, srcs)
 	}
 
-	defaultProjectID := s.BigQueryProject()
-	projectDescription := "The Google Cloud project ID containing the dataset."
-	datasetDescription := "The dataset to get metadata information. Can be in `project.dataset` format."
-	var datasetParameter parameters.Parameter
-	var projectParameter parameters.Parameter
-
-	projectParameter, datasetParameter = bqutil.InitializeDatasetParameters(
-		s.BigQueryAllowedDatasets(),
-		defaultProjectID,
-		projectKey, datasetKey,
-		projectDescription, datasetDescription)
+	projectParameter, datasetParameter, err := bqutil.InitializeDatasetParameters(s.BigQueryAllowedDatasets(), s.BigQueryProject(), cfg.ParameterDescriptions)
+	if err != nil {
+		return nil, err
+	}
 	params := parameters.Parameters{projectParameter, datasetParameter}
 
-	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, params, nil)
+	if err := cfg.ParameterDescriptions.Validate(cfg.Name, params); err != nil {
+		return nil, err
+	}
+	if err := cfg.ParameterAliases.Apply(cfg.Name, params); err != nil {
+		return nil, err
+	}
+
+	description, err := bqutil.ExpandDescriptions(cfg.Name, cfg.ExpandDescriptions, rawS, cfg.Description, params)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Description = description
+
+	annotations := tools.GetAnnotationsOrDefault(cfg.Annotations, tools.NewReadOnlyAnnotations)
+	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, params, annotations, nil)
 
 	// finish tool setup
 	t := Tool{
@@ -129,18 +144,18 @@ func (t Tool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, para
 	}
 
 	mapParams := params.AsMap()
-	projectId, ok := mapParams[projectKey].(string)
+	projectId, ok := mapParams[bqutil.ProjectKey].(string)
 	if !ok {
 		// Updated: Use fmt.Sprintf for formatting, pass nil as cause
-		return nil, util.NewAgentError(fmt.Sprintf("invalid or missing '%s' parameter; expected a string", projectKey), nil)
+		return nil, util.NewAgentError(fmt.Sprintf("invalid or missing '%s' parameter; expected a string", bqutil.ProjectKey), nil)
 	}
 
-	datasetId, ok := mapParams[datasetKey].(string)
+	datasetId, ok := mapParams[bqutil.DatasetKey].(string)
 	if !ok {
-		return nil, util.NewAgentError(fmt.Sprintf("invalid or missing '%s' parameter; expected a string", datasetKey), nil)
+		return nil, util.NewAgentError(fmt.Sprintf("invalid or missing '%s' parameter; expected a string", bqutil.DatasetKey), nil)
 	}
 
-	bqClient, _, err := source.RetrieveClientAndService(accessToken)
+	bqClient, _, err := source.RetrieveClientAndService(ctx, accessToken)
 	if err != nil {
 		return nil, util.NewClientServerError("failed to retrieve BigQuery client", http.StatusInternalServerError, err)
 	}
@@ -153,7 +168,7 @@ func (t Tool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, para
 
 	metadata, err := dsHandle.Metadata(ctx)
 	if err != nil {
-		return nil, util.ProcessGcpError(err)
+		return nil, bqutil.ProcessAPIError("error processing GCP request", err)
 	}
 
 	return metadata, nil
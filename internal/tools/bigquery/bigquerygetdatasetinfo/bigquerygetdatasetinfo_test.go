@@ -15,12 +15,17 @@
 package bigquerygetdatasetinfo_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/googleapis/genai-toolbox/internal/server"
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	bigqueryds "github.com/googleapis/genai-toolbox/internal/sources/bigquery"
 	"github.com/googleapis/genai-toolbox/internal/testutils"
+	"github.com/googleapis/genai-toolbox/internal/tools"
 	"github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerygetdatasetinfo"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
 )
 
 func TestParseFromYamlBigQueryGetDatasetInfo(t *testing.T) {
@@ -52,6 +57,51 @@ func TestParseFromYamlBigQueryGetDatasetInfo(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "with parameter descriptions",
+			in: `
+            kind: tools
+            name: example_tool
+            type: bigquery-get-dataset-info
+            source: my-instance
+            description: some description
+            parameterDescriptions:
+                dataset: Le jeu de données à décrire.
+            `,
+			want: server.ToolConfigs{
+				"example_tool": bigquerygetdatasetinfo.Config{
+					Name:                  "example_tool",
+					Type:                  "bigquery-get-dataset-info",
+					Source:                "my-instance",
+					Description:           "some description",
+					AuthRequired:          []string{},
+					ParameterDescriptions: tools.ParameterDescriptions{"dataset": "Le jeu de données à décrire."},
+				},
+			},
+		},
+		{
+			desc: "with parameter aliases",
+			in: `
+            kind: tools
+            name: example_tool
+            type: bigquery-get-dataset-info
+            source: my-instance
+            description: some description
+            parameterAliases:
+                project_id: project
+                dataset_id: dataset
+            `,
+			want: server.ToolConfigs{
+				"example_tool": bigquerygetdatasetinfo.Config{
+					Name:             "example_tool",
+					Type:             "bigquery-get-dataset-info",
+					Source:           "my-instance",
+					Description:      "some description",
+					AuthRequired:     []string{},
+					ParameterAliases: tools.ParameterAliases{"project_id": "project", "dataset_id": "dataset"},
+				},
+			},
+		},
 	}
 	for _, tc := range tcs {
 		t.Run(tc.desc, func(t *testing.T) {
@@ -65,5 +115,85 @@ func TestParseFromYamlBigQueryGetDatasetInfo(t *testing.T) {
 			}
 		})
 	}
+}
 
+func TestParameterDescriptions(t *testing.T) {
+	src := &bigqueryds.Source{
+		Config:          bigqueryds.Config{Name: "my-bq", Type: "bigquery", Project: "my-project"},
+		AllowedDatasets: map[string]struct{}{"my-project.sales": {}},
+	}
+	srcs := map[string]sources.Source{"my-bq": src}
+
+	cfg := bigquerygetdatasetinfo.Config{
+		Name:        "get_dataset",
+		Type:        "bigquery-get-dataset-info",
+		Source:      "my-bq",
+		Description: "d",
+		ParameterDescriptions: tools.ParameterDescriptions{
+			"project": "Le projet du jeu de données.",
+			"dataset": "Le jeu de données à décrire.",
+		},
+	}
+	tool, err := cfg.Initialize(srcs)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	got := map[string]parameters.ParameterManifest{}
+	for _, p := range tool.Manifest().Parameters {
+		got[p.Name] = p
+	}
+	// the overrides keep the default of the parameters and the allowed
+	// datasets added to their descriptions
+	if want := "Le jeu de données à décrire. Must be `sales`."; got["dataset"].Description != want {
+		t.Fatalf("got dataset description %q, want %q", got["dataset"].Description, want)
+	}
+	if got["dataset"].Default != "sales" {
+		t.Fatalf("got dataset default %v, want sales", got["dataset"].Default)
+	}
+	if want := "Le projet du jeu de données."; got["project"].Description != want {
+		t.Fatalf("got project description %q, want %q", got["project"].Description, want)
+	}
+	if got["project"].Default != "my-project" {
+		t.Fatalf("got project default %v, want my-project", got["project"].Default)
+	}
+
+	cfg.ParameterDescriptions = tools.ParameterDescriptions{"datset": "typo"}
+	if _, err := cfg.Initialize(srcs); err == nil || !strings.Contains(err.Error(), "no parameters named datset") {
+		t.Fatalf("expected an error for an unknown parameter, got %v", err)
+	}
+}
+
+func TestParameterAliases(t *testing.T) {
+	src := &bigqueryds.Source{Config: bigqueryds.Config{Name: "my-bq", Type: "bigquery", Project: "my-project"}}
+	srcs := map[string]sources.Source{"my-bq": src}
+
+	cfg := bigquerygetdatasetinfo.Config{
+		Name:             "get_dataset",
+		Type:             "bigquery-get-dataset-info",
+		Source:           "my-bq",
+		Description:      "d",
+		ParameterAliases: tools.ParameterAliases{"project_id": "project", "dataset_id": "dataset"},
+	}
+	tool, err := cfg.Initialize(srcs)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	got, err := parameters.ParseParams(tool.GetParameters(), map[string]any{"project_id": "other-project", "dataset_id": "sales"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diff := cmp.Diff(map[string]any{"project": "other-project", "dataset": "sales"}, got.AsMap()); diff != "" {
+		t.Fatalf("unexpected values (-want +got):\n%s", diff)
+	}
+	// the aliases are not shown to clients
+	for _, p := range tool.Manifest().Parameters {
+		if p.Name != "project" && p.Name != "dataset" {
+			t.Fatalf("unexpected parameter %q in the manifest", p.Name)
+		}
+	}
+
+	cfg.ParameterAliases = tools.ParameterAliases{"table_id": "table"}
+	if _, err := cfg.Initialize(srcs); err == nil || !strings.Contains(err.Error(), `alias "table_id" of unknown parameter "table"`) {
+		t.Fatalf("expected an error for an alias of an unknown parameter, got %v", err)
+	}
 }
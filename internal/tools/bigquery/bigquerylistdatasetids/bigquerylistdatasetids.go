@@ -24,6 +24,7 @@ import (
 	"github.com/googleapis/genai-toolbox/internal/embeddingmodels"
 	"github.com/googleapis/genai-toolbox/internal/sources"
 	"github.com/googleapis/genai-toolbox/internal/tools"
+	bqutil "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
 	"github.com/googleapis/genai-toolbox/internal/util"
 	"github.com/googleapis/genai-toolbox/internal/util/parameters"
 	bigqueryrestapi "google.golang.org/api/bigquery/v2"
@@ -31,7 +32,6 @@ import (
 )
 
 const resourceType string = "bigquery-list-dataset-ids"
-const projectKey string = "project"
 
 func init() {
 	if !tools.Register(resourceType, newConfig) {
@@ -51,15 +51,23 @@ type compatibleSource interface {
 	BigQueryProject() string
 	UseClientAuthorization() bool
 	BigQueryAllowedDatasets() []string
-	RetrieveClientAndService(tools.AccessToken) (*bigqueryapi.Client, *bigqueryrestapi.Service, error)
+	IsDatasetAllowed(projectID, datasetID string) bool
+	RetrieveClientAndService(context.Context, tools.AccessToken) (*bigqueryapi.Client, *bigqueryrestapi.Service, error)
 }
 
 type Config struct {
-	Name         string   `yaml:"name" validate:"required"`
-	Type         string   `yaml:"type" validate:"required"`
-	Source       string   `yaml:"source" validate:"required"`
-	Description  string   `yaml:"description" validate:"required"`
-	AuthRequired []string `yaml:"authRequired"`
+	Name         string                 `yaml:"name" validate:"required"`
+	Type         string                 `yaml:"type" validate:"required"`
+	Source       string                 `yaml:"source" validate:"required"`
+	Description  string                 `yaml:"description" validate:"required"`
+	AuthRequired []string               `yaml:"authRequired"`
+	Annotations  *tools.ToolAnnotations `yaml:"annotations,omitempty"`
+	// ParameterAliases are other names the value of the project parameter
+	// is accepted under, by alias.
+	ParameterAliases tools.ParameterAliases `yaml:"parameterAliases"`
+	// ExpandDescriptions expands the source properties referenced in the
+	// descriptions of the tool and its parameters, e.g. {{ .Source.Project }}.
+	ExpandDescriptions bool `yaml:"expandDescriptions"`
 }
 
 // validate interface
@@ -79,7 +87,7 @@ func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error)
 	// verify the source is compatible
 	s, ok := rawS.(compatibleSource)
 	if !ok {
-		return nil, fmt.Errorf("invalid source for %q tool: source %q not compatible", resourceType, cfg.Source)
+		return nil, tools.IncompatibleSourceError[compatibleSource](rawS, cfg.Source, resourceType, srcs)
 	}
 
 	var projectParameter parameters.Parameter
@@ -92,11 +100,22 @@ func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error)
 		projectParameterDescription = "The Google Cloud project to list dataset ids."
 	}
 
-	projectParameter = parameters.NewStringParameterWithDefault(projectKey, s.BigQueryProject(), projectParameterDescription)
+	projectParameter = parameters.NewStringParameterWithDefault(bqutil.ProjectKey, s.BigQueryProject(), projectParameterDescription)
 
 	params := parameters.Parameters{projectParameter}
 
-	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, params, nil)
+	if err := cfg.ParameterAliases.Apply(cfg.Name, params); err != nil {
+		return nil, err
+	}
+
+	description, err := bqutil.ExpandDescriptions(cfg.Name, cfg.ExpandDescriptions, rawS, cfg.Description, params)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Description = description
+
+	annotations := tools.GetAnnotationsOrDefault(cfg.Annotations, tools.NewReadOnlyAnnotations)
+	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, params, annotations, nil)
 
 	// finish tool setup
 	t := Tool{
@@ -132,19 +151,19 @@ func (t Tool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, para
 		return source.BigQueryAllowedDatasets(), nil
 	}
 	mapParams := params.AsMap()
-	projectId, ok := mapParams[projectKey].(string)
+	projectId, ok := mapParams[bqutil.ProjectKey].(string)
 	if !ok {
-		return nil, util.NewAgentError(fmt.Sprintf("invalid or missing '%s' parameter; expected a string", projectKey), nil)
+		return nil, util.NewAgentError(fmt.Sprintf("invalid or missing '%s' parameter; expected a string", bqutil.ProjectKey), nil)
 	}
 
-	bqClient, _, err := source.RetrieveClientAndService(accessToken)
+	bqClient, _, err := source.RetrieveClientAndService(ctx, accessToken)
 	if err != nil {
 		return nil, util.NewClientServerError("failed to retrieve BigQuery client", http.StatusInternalServerError, err)
 	}
 	datasetIterator := bqClient.Datasets(ctx)
 	datasetIterator.ProjectID = projectId
 
-	var datasetIds []any
+	var refs []bqutil.DatasetRef
 	for {
 		dataset, err := datasetIterator.Next()
 		if err == iterator.Done {
@@ -159,9 +178,13 @@ func (t Tool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, para
 		if len(id) >= 2 && id[0] == '"' && id[len(id)-1] == '"' {
 			id = id[1 : len(id)-1]
 		}
-		datasetIds = append(datasetIds, id)
+		refs = append(refs, bqutil.DatasetRef{ProjectID: projectId, DatasetID: id})
 	}
 
+	var datasetIds []any
+	for _, ref := range bqutil.FilterAllowedDatasets(source, refs) {
+		datasetIds = append(datasetIds, ref.DatasetID)
+	}
 	return datasetIds, nil
 }
 
@@ -0,0 +1,289 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerysetdataagentiampolicy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	yaml "github.com/goccy/go-yaml"
+	"github.com/googleapis/genai-toolbox/internal/embeddingmodels"
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	bqutil "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+	"golang.org/x/oauth2"
+)
+
+const resourceType string = "bigquery-set-data-agent-iam-policy"
+
+const (
+	dataAgentIDKey string = "data_agent_id"
+	roleKey        string = "role"
+	membersKey     string = "members"
+	actionKey      string = "action"
+)
+
+const (
+	actionAdd    = "add"
+	actionRemove = "remove"
+)
+
+// defaultDataAgentLocation is used when the source does not configure a location.
+const defaultDataAgentLocation = "global"
+
+var gdaBaseURL = bqutil.GDABaseURL
+
+func init() {
+	if !tools.Register(resourceType, newConfig) {
+		panic(fmt.Sprintf("tool type %q already registered", resourceType))
+	}
+}
+
+func newConfig(ctx context.Context, name string, decoder *yaml.Decoder) (tools.ToolConfig, error) {
+	actual := Config{Name: name}
+	if err := decoder.DecodeContext(ctx, &actual); err != nil {
+		return nil, err
+	}
+	return actual, nil
+}
+
+type compatibleSource interface {
+	BigQueryTokenSourceWithScope(ctx context.Context, scopes []string) (oauth2.TokenSource, error)
+	BigQueryProject() string
+	BigQueryLocation() string
+	UseClientAuthorization() bool
+	ClientAuthorizationMode() string
+}
+
+type Config struct {
+	Name         string                 `yaml:"name" validate:"required"`
+	Type         string                 `yaml:"type" validate:"required"`
+	Source       string                 `yaml:"source" validate:"required"`
+	Description  string                 `yaml:"description" validate:"required"`
+	AuthRequired []string               `yaml:"authRequired"`
+	Annotations  *tools.ToolAnnotations `yaml:"annotations,omitempty"`
+	// AllowedProjects lists the other projects whose data agents can be shared.
+	AllowedProjects []string `yaml:"allowedProjects" expand:"true"`
+	// ExpandDescriptions expands the source properties referenced in the
+	// descriptions of the tool and its parameters, e.g. {{ .Source.Project }}.
+	ExpandDescriptions bool `yaml:"expandDescriptions"`
+}
+
+// validate interface
+var _ tools.ToolConfigWithFields = Config{}
+
+func (cfg Config) ToolConfigType() string {
+	return resourceType
+}
+
+// DeclaredFields returns the fields that policies can set.
+func (cfg Config) DeclaredFields() []string {
+	return []string{"allowedProjects"}
+}
+
+func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error) {
+	// verify source exists
+	rawS, ok := srcs[cfg.Source]
+	if !ok {
+		return nil, fmt.Errorf("no source named %q configured", cfg.Source)
+	}
+
+	// verify the source is compatible
+	s, ok := rawS.(compatibleSource)
+	if !ok {
+		return nil, tools.IncompatibleSourceError[compatibleSource](rawS, cfg.Source, resourceType, srcs)
+	}
+
+	params := parameters.Parameters{
+		parameters.WithExamples(parameters.NewStringParameter(dataAgentIDKey, "The ID or full resource name of the data agent whose IAM policy is updated."), bqutil.DataAgentIDExamples()...),
+		bqutil.InitializeProjectParameter(s.BigQueryProject(), cfg.AllowedProjects, "The Google Cloud project ID containing the data agent. Defaults to the project of the source."),
+		parameters.NewStringParameter(roleKey, "The IAM role to grant or revoke, e.g. `roles/geminidataanalytics.dataAgentUser`."),
+		parameters.NewArrayParameter(membersKey, "The members to grant or revoke the role for, e.g. `user:alice@example.com`, `group:team@example.com`, `serviceAccount:sa@project.iam.gserviceaccount.com` or `domain:example.com`.", parameters.NewStringParameter("member", "An IAM member.")),
+		parameters.NewStringParameterWithAllowedValues(actionKey, "Whether to `add` the members to the role or `remove` them from it.", []any{actionAdd, actionRemove}),
+	}
+
+	description, err := bqutil.ExpandDescriptions(cfg.Name, cfg.ExpandDescriptions, rawS, cfg.Description, params)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Description = description
+
+	annotations := tools.GetAnnotationsOrDefault(cfg.Annotations, func() *tools.ToolAnnotations {
+		// The policy replaces the existing one.
+		annotations, idempotent := tools.NewDestructiveAnnotations(), true
+		annotations.IdempotentHint = &idempotent
+		return annotations
+	})
+	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, params, annotations, nil)
+
+	// finish tool setup
+	t := Tool{
+		Config:      cfg,
+		Parameters:  params,
+		manifest:    tools.Manifest{Description: cfg.Description, Parameters: params.Manifest(), AuthRequired: cfg.AuthRequired},
+		mcpManifest: mcpManifest,
+	}
+	return t, nil
+}
+
+// validate interface
+var _ tools.Tool = Tool{}
+
+type Tool struct {
+	Config
+	Parameters  parameters.Parameters `yaml:"parameters"`
+	manifest    tools.Manifest
+	mcpManifest tools.McpManifest
+}
+
+func (t Tool) ToConfig() tools.ToolConfig {
+	return t.Config
+}
+
+func (t Tool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, params parameters.ParamValues, accessToken tools.AccessToken) (any, util.ToolboxError) {
+	source, err := tools.GetCompatibleSource[compatibleSource](resourceMgr, t.Source, t.Name, t.Type)
+	if err != nil {
+		return nil, util.NewClientServerError("source used is not compatible with the tool", http.StatusInternalServerError, err)
+	}
+
+	mapParams := params.AsMap()
+	dataAgentID, ok := mapParams[dataAgentIDKey].(string)
+	if !ok || dataAgentID == "" {
+		return nil, util.NewAgentError(fmt.Sprintf("invalid or missing '%s' parameter; expected a non-empty string", dataAgentIDKey), nil)
+	}
+	role, ok := mapParams[roleKey].(string)
+	if !ok || !isValidRole(role) {
+		return nil, util.NewAgentError(fmt.Sprintf("invalid '%s' parameter %q; expected a role name such as 'roles/geminidataanalytics.dataAgentUser'", roleKey, role), nil)
+	}
+	action, _ := mapParams[actionKey].(string)
+	rawMembers, ok := mapParams[membersKey].([]any)
+	if !ok || len(rawMembers) == 0 {
+		return nil, util.NewAgentError(fmt.Sprintf("invalid or missing '%s' parameter; expected a non-empty list of members", membersKey), nil)
+	}
+	members := make([]string, 0, len(rawMembers))
+	for _, m := range rawMembers {
+		member, ok := m.(string)
+		if !ok {
+			return nil, util.NewAgentError(fmt.Sprintf("invalid '%s' parameter; expected a list of strings", membersKey), nil)
+		}
+		if err := bqutil.ValidateIAMMember(member); err != nil {
+			return nil, util.NewAgentError(fmt.Sprintf("invalid '%s' parameter", membersKey), err)
+		}
+		members = append(members, member)
+	}
+
+	location := source.BigQueryLocation()
+	if location == "" {
+		location = defaultDataAgentLocation
+	}
+	requestedProject, _ := mapParams[bqutil.ProjectKey].(string)
+	projectID, err := bqutil.ResolveProject(requestedProject, source.BigQueryProject(), t.AllowedProjects)
+	if err != nil {
+		return nil, util.NewAgentError(fmt.Sprintf("invalid '%s' parameter", bqutil.ProjectKey), err)
+	}
+	resourceName, err := bqutil.ResolveDataAgentName(dataAgentID, projectID, location)
+	if err != nil {
+		return nil, util.NewAgentError(fmt.Sprintf("invalid '%s' parameter", dataAgentIDKey), err)
+	}
+
+	// Get credentials for the API call
+	tokenStr, _, tbErr := bqutil.GetAPIToken(ctx, t.Name, source, accessToken, nil)
+	if tbErr != nil {
+		return nil, tbErr
+	}
+
+	get := func(ctx context.Context) (*bqutil.IAMPolicy, error) {
+		payload := map[string]any{"options": map[string]any{"requestedPolicyVersion": bqutil.IAMPolicyVersion}}
+		return callIAMMethod(ctx, resourceName, "getIamPolicy", tokenStr, payload)
+	}
+	set := func(ctx context.Context, policy *bqutil.IAMPolicy) (*bqutil.IAMPolicy, error) {
+		return callIAMMethod(ctx, resourceName, "setIamPolicy", tokenStr, map[string]any{"policy": policy})
+	}
+	modify := func(policy *bqutil.IAMPolicy) bool {
+		if action == actionRemove {
+			return policy.RemoveMembers(role, members)
+		}
+		return policy.AddMembers(role, members)
+	}
+
+	policy, err := bqutil.UpdateIAMPolicy(ctx, bqutil.DefaultIAMUpdateAttempts, get, set, modify)
+	if err != nil {
+		var tbErr util.ToolboxError
+		if errors.As(err, &tbErr) {
+			return nil, tbErr
+		}
+		if errors.Is(err, bqutil.ErrIAMPolicyConflict) {
+			return nil, util.NewAgentError(fmt.Sprintf("failed to update IAM policy of data agent %q", resourceName), err)
+		}
+		return nil, util.NewClientServerError(fmt.Sprintf("failed to update IAM policy of data agent %q", resourceName), http.StatusInternalServerError, err)
+	}
+	return policy, nil
+}
+
+// isValidRole reports whether role looks like a predefined or custom IAM role name.
+func isValidRole(role string) bool {
+	if strings.HasPrefix(role, "roles/") {
+		return len(role) > len("roles/")
+	}
+	parts := strings.Split(role, "/")
+	return len(parts) == 4 && (parts[0] == "projects" || parts[0] == "organizations") && parts[1] != "" && parts[2] == "roles" && parts[3] != ""
+}
+
+// callIAMMethod invokes an IAM method on a data agent and decodes the returned
+// policy.
+func callIAMMethod(ctx context.Context, resourceName, method, tokenStr string, payload any) (*bqutil.IAMPolicy, error) {
+	var policy bqutil.IAMPolicy
+	if tbErr := bqutil.CallDataAgentIAMMethod(ctx, gdaBaseURL, resourceName, method, tokenStr, payload, &policy, fmt.Sprintf("failed to call %s on data agent %q", method, resourceName)); tbErr != nil {
+		return nil, tbErr
+	}
+	return &policy, nil
+}
+
+func (t Tool) EmbedParams(ctx context.Context, paramValues parameters.ParamValues, embeddingModelsMap map[string]embeddingmodels.EmbeddingModel) (parameters.ParamValues, error) {
+	return parameters.EmbedParams(ctx, t.Parameters, paramValues, embeddingModelsMap, nil)
+}
+
+func (t Tool) Manifest() tools.Manifest {
+	return t.manifest
+}
+
+func (t Tool) McpManifest() tools.McpManifest {
+	return t.mcpManifest
+}
+
+func (t Tool) Authorized(verifiedAuthServices []string) bool {
+	return tools.IsAuthorized(t.AuthRequired, verifiedAuthServices)
+}
+
+func (t Tool) RequiresClientAuthorization(resourceMgr tools.SourceProvider) (bool, error) {
+	source, err := tools.GetCompatibleSource[compatibleSource](resourceMgr, t.Source, t.Name, t.Type)
+	if err != nil {
+		return false, err
+	}
+	return source.UseClientAuthorization(), nil
+}
+
+func (t Tool) GetAuthTokenHeaderName(resourceMgr tools.SourceProvider) (string, error) {
+	return "Authorization", nil
+}
+
+func (t Tool) GetParameters() parameters.Parameters {
+	return t.Parameters
+}
@@ -0,0 +1,224 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerysetdataagentiampolicy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	bqutil "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+	"golang.org/x/oauth2"
+)
+
+type fakeSource struct{}
+
+func (s *fakeSource) SourceType() string              { return "bigquery" }
+func (s *fakeSource) ToConfig() sources.SourceConfig  { return nil }
+func (s *fakeSource) BigQueryProject() string         { return "test-project" }
+func (s *fakeSource) BigQueryLocation() string        { return "" }
+func (s *fakeSource) UseClientAuthorization() bool    { return false }
+func (s *fakeSource) ClientAuthorizationMode() string { return "disabled" }
+func (s *fakeSource) BigQueryTokenSourceWithScope(ctx context.Context, scopes []string) (oauth2.TokenSource, error) {
+	return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "adc-token"}), nil
+}
+
+type fakeSourceProvider struct {
+	source sources.Source
+}
+
+func (p fakeSourceProvider) GetSource(name string) (sources.Source, bool) {
+	return p.source, true
+}
+
+// fakeIAMServer serves getIamPolicy and setIamPolicy for a single data agent.
+// The stored policy's etag changes on every write, and the first `conflicts`
+// writes fail as if another client had modified the policy concurrently.
+type fakeIAMServer struct {
+	mu        sync.Mutex
+	policy    bqutil.IAMPolicy
+	version   int
+	conflicts int
+	gets      int
+	sets      int
+}
+
+func (f *fakeIAMServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case strings.HasSuffix(r.URL.Path, ":getIamPolicy"):
+		f.gets++
+		_ = json.NewEncoder(w).Encode(f.policy)
+	case strings.HasSuffix(r.URL.Path, ":setIamPolicy"):
+		f.sets++
+		var req struct {
+			Policy bqutil.IAMPolicy `json:"policy"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if f.conflicts > 0 {
+			f.conflicts--
+			f.bump()
+		}
+		if req.Policy.Etag != f.policy.Etag {
+			http.Error(w, `{"error": {"status": "ABORTED"}}`, http.StatusConflict)
+			return
+		}
+		f.policy = req.Policy
+		f.bump()
+		_ = json.NewEncoder(w).Encode(f.policy)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeIAMServer) bump() {
+	f.version++
+	f.policy.Etag = fmt.Sprintf("etag-%d", f.version)
+}
+
+func newTestTool(t *testing.T, serverURL string) (Tool, fakeSourceProvider) {
+	t.Helper()
+	originalURL := gdaBaseURL
+	gdaBaseURL = serverURL
+	t.Cleanup(func() { gdaBaseURL = originalURL })
+
+	source := &fakeSource{}
+	rawTool, err := Config{Name: "set_policy", Type: resourceType, Source: "src", Description: "d"}.Initialize(map[string]sources.Source{"src": source})
+	if err != nil {
+		t.Fatalf("unexpected error initializing tool: %s", err)
+	}
+	return rawTool.(Tool), fakeSourceProvider{source: source}
+}
+
+func invokeParams(role, action string, members ...any) parameters.ParamValues {
+	return parameters.ParamValues{
+		{Name: dataAgentIDKey, Value: "my-agent"},
+		{Name: roleKey, Value: role},
+		{Name: membersKey, Value: members},
+		{Name: actionKey, Value: action},
+	}
+}
+
+func TestInvokeRetriesOnEtagConflict(t *testing.T) {
+	tcs := []struct {
+		desc      string
+		conflicts int
+		wantSets  int
+		wantErr   bool
+	}{
+		{desc: "no conflict", conflicts: 0, wantSets: 1},
+		{desc: "conflict then success", conflicts: 2, wantSets: 3},
+		{desc: "conflicts exhaust retries", conflicts: 5, wantSets: 3, wantErr: true},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			fake := &fakeIAMServer{
+				policy:    bqutil.IAMPolicy{Version: 1, Etag: "etag-0", Bindings: []bqutil.IAMBinding{{Role: "roles/viewer", Members: []string{"user:bob@example.com"}}}},
+				conflicts: tc.conflicts,
+			}
+			server := httptest.NewServer(fake)
+			defer server.Close()
+			tool, provider := newTestTool(t, server.URL)
+
+			res, tbErr := tool.Invoke(context.Background(), provider, invokeParams("roles/viewer", actionAdd, "user:alice@example.com"), "")
+			if fake.sets != tc.wantSets {
+				t.Errorf("expected %d setIamPolicy calls, got %d", tc.wantSets, fake.sets)
+			}
+			if fake.gets != fake.sets {
+				t.Errorf("expected every write to be preceded by a fresh read, got %d reads and %d writes", fake.gets, fake.sets)
+			}
+			if tc.wantErr {
+				if tbErr == nil {
+					t.Fatalf("expected an error after exhausting retries")
+				}
+				if tbErr.Category() != util.CategoryAgent {
+					t.Errorf("expected an agent error, got %s", tbErr.Category())
+				}
+				return
+			}
+			if tbErr != nil {
+				t.Fatalf("unexpected invoke error: %s", tbErr)
+			}
+			policy := res.(*bqutil.IAMPolicy)
+			want := []string{"user:bob@example.com", "user:alice@example.com"}
+			if len(policy.Bindings) != 1 || strings.Join(policy.Bindings[0].Members, ",") != strings.Join(want, ",") {
+				t.Fatalf("unexpected bindings in updated policy: %+v", policy.Bindings)
+			}
+		})
+	}
+}
+
+func TestInvokeRemoveMembers(t *testing.T) {
+	fake := &fakeIAMServer{
+		policy: bqutil.IAMPolicy{Etag: "etag-0", Bindings: []bqutil.IAMBinding{{Role: "roles/viewer", Members: []string{"user:bob@example.com"}}}},
+	}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	tool, provider := newTestTool(t, server.URL)
+
+	res, tbErr := tool.Invoke(context.Background(), provider, invokeParams("roles/viewer", actionRemove, "user:bob@example.com"), "")
+	if tbErr != nil {
+		t.Fatalf("unexpected invoke error: %s", tbErr)
+	}
+	if policy := res.(*bqutil.IAMPolicy); len(policy.Bindings) != 0 {
+		t.Fatalf("expected the emptied binding to be dropped, got %+v", policy.Bindings)
+	}
+}
+
+func TestInvokeRejectsMalformedInput(t *testing.T) {
+	fake := &fakeIAMServer{policy: bqutil.IAMPolicy{Etag: "etag-0"}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	tool, provider := newTestTool(t, server.URL)
+
+	tcs := []struct {
+		desc   string
+		params parameters.ParamValues
+	}{
+		{desc: "member without type", params: invokeParams("roles/viewer", actionAdd, "alice@example.com")},
+		{desc: "user member without email", params: invokeParams("roles/viewer", actionAdd, "user:alice")},
+		{desc: "unknown member type", params: invokeParams("roles/viewer", actionAdd, "robot:alice@example.com")},
+		{desc: "one malformed member among valid ones", params: invokeParams("roles/viewer", actionAdd, "user:alice@example.com", "group:")},
+		{desc: "empty member list", params: invokeParams("roles/viewer", actionAdd)},
+		{desc: "invalid role", params: invokeParams("viewer", actionAdd, "user:alice@example.com")},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, tbErr := tool.Invoke(context.Background(), provider, tc.params, "")
+			if tbErr == nil {
+				t.Fatalf("expected an error")
+			}
+			if tbErr.Category() != util.CategoryAgent {
+				t.Errorf("expected an agent error, got %s", tbErr.Category())
+			}
+		})
+	}
+	if fake.gets != 0 || fake.sets != 0 {
+		t.Fatalf("malformed input must be rejected before calling the API, got %d reads and %d writes", fake.gets, fake.sets)
+	}
+}
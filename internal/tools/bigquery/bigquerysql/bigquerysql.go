@@ -18,8 +18,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"reflect"
-	"strings"
+	"slices"
 
 	bigqueryapi "cloud.google.com/go/bigquery"
 	yaml "github.com/goccy/go-yaml"
@@ -35,6 +34,10 @@ import (
 
 const resourceType string = "bigquery-sql"
 
+// streamBatchSize is the number of rows of the chunks of streamed
+// invocations.
+const streamBatchSize = 100
+
 func init() {
 	if !tools.Register(resourceType, newConfig) {
 		panic(fmt.Sprintf("tool type %q already registered", resourceType))
@@ -52,19 +55,51 @@ func newConfig(ctx context.Context, name string, decoder *yaml.Decoder) (tools.T
 type compatibleSource interface {
 	BigQuerySession() bigqueryds.BigQuerySessionProvider
 	UseClientAuthorization() bool
-	RetrieveClientAndService(tools.AccessToken) (*bigqueryapi.Client, *bigqueryrestapi.Service, error)
-	RunSQL(context.Context, *bigqueryapi.Client, string, string, []bigqueryapi.QueryParameter, []*bigqueryapi.ConnectionProperty) (any, error)
+	RetrieveClientAndService(context.Context, tools.AccessToken) (*bigqueryapi.Client, *bigqueryrestapi.Service, error)
+	RunSQL(context.Context, *bigqueryapi.Client, string, string, []bigqueryapi.QueryParameter, []*bigqueryapi.ConnectionProperty, bigqueryds.JobOptions) (any, error)
+	StreamSQL(context.Context, *bigqueryapi.Client, string, []bigqueryapi.QueryParameter, []*bigqueryapi.ConnectionProperty, bigqueryds.JobOptions, int, func([]any) error) error
+	BigQueryJobOptions(bigqueryds.JobOptions) bigqueryds.JobOptions
 }
 
 type Config struct {
-	Name               string                `yaml:"name" validate:"required"`
-	Type               string                `yaml:"type" validate:"required"`
-	Source             string                `yaml:"source" validate:"required"`
-	Description        string                `yaml:"description" validate:"required"`
-	Statement          string                `yaml:"statement" validate:"required"`
-	AuthRequired       []string              `yaml:"authRequired"`
-	Parameters         parameters.Parameters `yaml:"parameters"`
-	TemplateParameters parameters.Parameters `yaml:"templateParameters"`
+	Name               string                 `yaml:"name" validate:"required"`
+	Type               string                 `yaml:"type" validate:"required"`
+	Source             string                 `yaml:"source" validate:"required"`
+	Description        string                 `yaml:"description" validate:"required"`
+	Statement          string                 `yaml:"statement" validate:"required"`
+	AuthRequired       []string               `yaml:"authRequired"`
+	Annotations        *tools.ToolAnnotations `yaml:"annotations,omitempty"`
+	Parameters         parameters.Parameters  `yaml:"parameters"`
+	TemplateParameters parameters.Parameters  `yaml:"templateParameters"`
+	// JobLabels are added to the query jobs and dry runs of the tool, and
+	// override the defaultJobLabels of the source with the same keys.
+	JobLabels map[string]string `yaml:"jobLabels"`
+	// MaximumBytesBilled overrides the maximumBytesBilled of the source.
+	MaximumBytesBilled *int64 `yaml:"maximumBytesBilled"`
+	// QueryLabel is set as the query_label connection property of the jobs
+	// of the tool, as "key:value".
+	QueryLabel string `yaml:"queryLabel"`
+	// Priority is the priority of the query jobs of the tool, "interactive"
+	// or "batch".
+	Priority string `yaml:"priority"`
+	// UseQueryCache sets whether queries may be answered from cached results.
+	UseQueryCache *bool `yaml:"useQueryCache"`
+	// DestinationTable receives the results of the queries of the tool,
+	// "project.dataset.table".
+	DestinationTable string `yaml:"destinationTable"`
+	// UseStorageReadAPI overrides the useStorageReadApi of the source.
+	UseStorageReadAPI *bool `yaml:"useStorageReadApi"`
+	// MaxColumns rejects queries whose result has more columns.
+	MaxColumns *int `yaml:"maxColumns"`
+	// MaxCellBytes rejects queries whose rows are estimated to be larger.
+	MaxCellBytes *int64 `yaml:"maxCellBytes"`
+	// ParameterMode is the style of the query parameters of the statement,
+	// "named" (@name) or "positional" (?). It defaults to the style that the
+	// statement uses.
+	ParameterMode string `yaml:"parameterMode"`
+	// ExpandDescriptions expands the source properties referenced in the
+	// descriptions of the tool and its parameters, e.g. {{ .Source.Project }}.
+	ExpandDescriptions bool `yaml:"expandDescriptions"`
 }
 
 // validate interface
@@ -75,123 +110,119 @@ func (cfg Config) ToolConfigType() string {
 }
 
 func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error) {
+	jobOptions, err := bqutil.ToolJobOptions(bqutil.JobConfig{
+		Labels:             cfg.JobLabels,
+		MaximumBytesBilled: cfg.MaximumBytesBilled,
+		UseStorageReadAPI:  cfg.UseStorageReadAPI,
+		QueryLabel:         cfg.QueryLabel,
+		DestinationTable:   cfg.DestinationTable,
+		Priority:           cfg.Priority,
+		UseQueryCache:      cfg.UseQueryCache,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration of tool %q: %w", cfg.Name, err)
+	}
+	resultLimits, err := bqutil.ToolResultLimits(cfg.MaxColumns, cfg.MaxCellBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration of tool %q: %w", cfg.Name, err)
+	}
+	parameterMode, err := bqutil.ParseParameterMode(cfg.ParameterMode)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration of tool %q: %w", cfg.Name, err)
+	}
+	// Statements with template parameters are checked when they are resolved.
+	if len(cfg.TemplateParameters) == 0 {
+		if _, err := bqutil.ResolveParameterMode(cfg.Statement, parameterMode); err != nil {
+			return nil, fmt.Errorf("invalid configuration of tool %q: %w", cfg.Name, err)
+		}
+	}
+
+	description, err := bqutil.ExpandDescriptions(cfg.Name, cfg.ExpandDescriptions, srcs[cfg.Source], cfg.Description, slices.Concat(cfg.Parameters, cfg.TemplateParameters))
+	if err != nil {
+		return nil, err
+	}
+	cfg.Description = description
+
 	allParameters, paramManifest, err := parameters.ProcessParameters(cfg.TemplateParameters, cfg.Parameters)
 	if err != nil {
 		return nil, err
 	}
 
-	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, allParameters, nil)
+	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, allParameters, cfg.Annotations, nil)
 
 	// finish tool setup
 	t := Tool{
-		Config:      cfg,
-		AllParams:   allParameters,
-		manifest:    tools.Manifest{Description: cfg.Description, Parameters: paramManifest, AuthRequired: cfg.AuthRequired},
-		mcpManifest: mcpManifest,
+		Config:        cfg,
+		jobOptions:    jobOptions,
+		resultLimits:  resultLimits,
+		parameterMode: parameterMode,
+		AllParams:     allParameters,
+		manifest:      tools.Manifest{Description: cfg.Description, Parameters: paramManifest, AuthRequired: cfg.AuthRequired},
+		mcpManifest:   mcpManifest,
 	}
 	return t, nil
 }
 
 // validate interface
-var _ tools.Tool = Tool{}
+var _ tools.PlannableTool = Tool{}
+var _ tools.StreamingTool = Tool{}
+var _ bqutil.StaticQueryTool = Tool{}
 
 type Tool struct {
 	Config
-	AllParams   parameters.Parameters `yaml:"allParams"`
-	manifest    tools.Manifest
-	mcpManifest tools.McpManifest
+	AllParams    parameters.Parameters `yaml:"allParams"`
+	manifest     tools.Manifest
+	mcpManifest  tools.McpManifest
+	jobOptions   bigqueryds.JobOptions
+	resultLimits bqutil.ResultLimits
+	// parameterMode is the declared mode of the query parameters, if any.
+	parameterMode bqutil.ParameterMode
 }
 
 func (t Tool) ToConfig() tools.ToolConfig {
 	return t.Config
 }
-func (t Tool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, params parameters.ParamValues, accessToken tools.AccessToken) (any, util.ToolboxError) {
+
+// query is the statement of an invocation with its parameters, validated by a
+// dry run.
+type query struct {
+	source    compatibleSource
+	client    *bigqueryapi.Client
+	statement string
+	params    []bigqueryapi.QueryParameter
+	connProps []*bigqueryapi.ConnectionProperty
+	jobOpts   bigqueryds.JobOptions
+	dryRunJob *bigqueryrestapi.Job
+}
+
+// prepareQuery resolves the statement and the query parameters of params, and
+// validates them with a dry run.
+func (t Tool) prepareQuery(ctx context.Context, resourceMgr tools.SourceProvider, params parameters.ParamValues, accessToken tools.AccessToken) (query, util.ToolboxError) {
 	source, err := tools.GetCompatibleSource[compatibleSource](resourceMgr, t.Source, t.Name, t.Type)
 	if err != nil {
-		return nil, util.NewClientServerError("source used is not compatible with the tool", http.StatusInternalServerError, err)
+		return query{}, util.NewClientServerError("source used is not compatible with the tool", http.StatusInternalServerError, err)
 	}
 
-	highLevelParams := make([]bigqueryapi.QueryParameter, 0, len(t.Parameters))
-	lowLevelParams := make([]*bigqueryrestapi.QueryParameter, 0, len(t.Parameters))
-
 	paramsMap := params.AsMap()
 	newStatement, err := parameters.ResolveTemplateParams(t.TemplateParameters, t.Statement, paramsMap)
 	if err != nil {
-		return nil, util.NewAgentError("unable to extract template params", err)
-	}
-
-	for _, p := range t.Parameters {
-		name := p.GetName()
-		value := paramsMap[name]
-
-		// This block for converting []any to typed slices is still necessary and correct.
-		if arrayParam, ok := p.(*parameters.ArrayParameter); ok {
-			arrayParamValue, ok := value.([]any)
-			if !ok {
-				return nil, util.NewAgentError(fmt.Sprintf("unable to convert parameter `%s` to []any", name), nil)
-			}
-			itemType := arrayParam.GetItems().GetType()
-			var err error
-			value, err = parameters.ConvertAnySliceToTyped(arrayParamValue, itemType)
-			if err != nil {
-				return nil, util.NewAgentError(fmt.Sprintf("unable to convert parameter `%s` from []any to typed slice", name), err)
-			}
-		}
-
-		// Determine if the parameter is named or positional for the high-level client.
-		var paramNameForHighLevel string
-		if strings.Contains(newStatement, "@"+name) {
-			paramNameForHighLevel = name
-		}
-
-		// 1. Create the high-level parameter for the final query execution.
-		highLevelParams = append(highLevelParams, bigqueryapi.QueryParameter{
-			Name:  paramNameForHighLevel,
-			Value: value,
-		})
-
-		// 2. Create the low-level parameter for the dry run, using the defined type from `p`.
-		lowLevelParam := &bigqueryrestapi.QueryParameter{
-			Name:           paramNameForHighLevel,
-			ParameterType:  &bigqueryrestapi.QueryParameterType{},
-			ParameterValue: &bigqueryrestapi.QueryParameterValue{},
-		}
+		return query{}, util.NewAgentError("unable to extract template params", err)
+	}
 
-		if arrayParam, ok := p.(*parameters.ArrayParameter); ok {
-			// Handle array types based on their defined item type.
-			lowLevelParam.ParameterType.Type = "ARRAY"
-			itemType, err := bqutil.BQTypeStringFromToolType(arrayParam.GetItems().GetType())
-			if err != nil {
-				return nil, util.NewAgentError("unable to get BigQuery type from tool parameter type", err)
-			}
-			lowLevelParam.ParameterType.ArrayType = &bigqueryrestapi.QueryParameterType{Type: itemType}
-
-			// Build the array values.
-			sliceVal := reflect.ValueOf(value)
-			arrayValues := make([]*bigqueryrestapi.QueryParameterValue, sliceVal.Len())
-			for i := 0; i < sliceVal.Len(); i++ {
-				arrayValues[i] = &bigqueryrestapi.QueryParameterValue{
-					Value: fmt.Sprintf("%v", sliceVal.Index(i).Interface()),
-				}
-			}
-			lowLevelParam.ParameterValue.ArrayValues = arrayValues
-		} else {
-			// Handle scalar types based on their defined type.
-			bqType, err := bqutil.BQTypeStringFromToolType(p.GetType())
-			if err != nil {
-				return nil, util.NewAgentError("unable to get BigQuery type from tool parameter type", err)
-			}
-			lowLevelParam.ParameterType.Type = bqType
-			lowLevelParam.ParameterValue.Value = fmt.Sprintf("%v", value)
-		}
-		lowLevelParams = append(lowLevelParams, lowLevelParam)
+	mode, err := bqutil.ResolveParameterMode(newStatement, t.parameterMode)
+	if err != nil {
+		return query{}, util.NewAgentError("invalid query parameters", err)
+	}
+	highLevelParams, lowLevelParams, err := bqutil.BuildQueryParameters(newStatement, mode, t.Parameters, paramsMap)
+	if err != nil {
+		return query{}, util.NewAgentError("invalid query parameters", err)
 	}
 
 	connProps := []*bigqueryapi.ConnectionProperty{}
 	if source.BigQuerySession() != nil {
 		session, err := source.BigQuerySession()(ctx)
 		if err != nil {
-			return nil, util.NewClientServerError("failed to get BigQuery session", http.StatusInternalServerError, err)
+			return query{}, util.NewClientServerError("failed to get BigQuery session", http.StatusInternalServerError, err)
 		}
 		if session != nil {
 			// Add session ID to the connection properties for subsequent calls.
@@ -199,24 +230,89 @@ func (t Tool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, para
 		}
 	}
 
-	bqClient, restService, err := source.RetrieveClientAndService(accessToken)
+	jobOpts := bqutil.ResolveJobOptions(source, t.jobOptions, bqutil.JobOptions{})
+	bqClient, restService, err := source.RetrieveClientAndService(ctx, accessToken)
 	if err != nil {
-		return nil, util.NewClientServerError("failed to retrieve BigQuery client", http.StatusInternalServerError, err)
+		return query{}, util.NewClientServerError("failed to retrieve BigQuery client", http.StatusInternalServerError, err)
 	}
 
-	dryRunJob, err := bqutil.DryRunQuery(ctx, restService, bqClient.Project(), bqClient.Location, newStatement, lowLevelParams, connProps)
+	dryRunJob, err := bqutil.DryRunQuery(ctx, restService, bqClient.Project(), bqClient.Location, newStatement, lowLevelParams, mode, connProps, jobOpts)
 	if err != nil {
-		return nil, util.ProcessGcpError(err)
+		return query{}, bqutil.ProcessAPIError("error processing GCP request", err)
 	}
+	if tbErr := bqutil.CheckResultLimits(dryRunJob, t.resultLimits); tbErr != nil {
+		return query{}, tbErr
+	}
+
+	return query{
+		source:    source,
+		client:    bqClient,
+		statement: newStatement,
+		params:    highLevelParams,
+		connProps: connProps,
+		jobOpts:   jobOpts,
+		dryRunJob: dryRunJob,
+	}, nil
+}
 
-	statementType := dryRunJob.Statistics.Query.StatementType
-	resp, err := source.RunSQL(ctx, bqClient, newStatement, statementType, highLevelParams, connProps)
+func (t Tool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, params parameters.ParamValues, accessToken tools.AccessToken) (any, util.ToolboxError) {
+	q, tbErr := t.prepareQuery(ctx, resourceMgr, params, accessToken)
+	if tbErr != nil {
+		return nil, tbErr
+	}
+	statementType := q.dryRunJob.Statistics.Query.StatementType
+	resp, err := q.source.RunSQL(ctx, q.client, q.statement, statementType, q.params, q.connProps, q.jobOpts)
 	if err != nil {
-		return nil, util.ProcessGcpError(err)
+		return nil, bqutil.ProcessAPIError("error processing GCP request", err)
 	}
 	return resp, nil
 }
 
+// InvokeStream runs the statement like Invoke, and sends its rows to sink in
+// batches of streamBatchSize rows as they are read.
+func (t Tool) InvokeStream(ctx context.Context, resourceMgr tools.SourceProvider, params parameters.ParamValues, accessToken tools.AccessToken, sink tools.ChunkSink) util.ToolboxError {
+	q, tbErr := t.prepareQuery(ctx, resourceMgr, params, accessToken)
+	if tbErr != nil {
+		return tbErr
+	}
+	sent := false
+	err := q.source.StreamSQL(ctx, q.client, q.statement, q.params, q.connProps, q.jobOpts, streamBatchSize, func(rows []any) error {
+		sent = true
+		return sink(rows)
+	})
+	if err == nil && !sent {
+		err = sink(bigqueryds.NoRowsResult(q.dryRunJob.Statistics.Query.StatementType))
+	}
+	if err != nil {
+		return bqutil.ProcessAPIError("error processing GCP request", err)
+	}
+	return nil
+}
+
+// Plan resolves and validates the statement like Invoke, and returns the
+// statistics of its dry run and the tables it references, without running it.
+func (t Tool) Plan(ctx context.Context, resourceMgr tools.SourceProvider, params parameters.ParamValues, accessToken tools.AccessToken) (any, util.ToolboxError) {
+	q, tbErr := t.prepareQuery(ctx, resourceMgr, params, accessToken)
+	if tbErr != nil {
+		return nil, tbErr
+	}
+	return bqutil.QueryPlan(q.statement, q.dryRunJob), nil
+}
+
+// StaticQuery returns the statement of the tool, unless it has template
+// parameters.
+func (t Tool) StaticQuery() (bqutil.StaticQuery, bool) {
+	if len(t.TemplateParameters) > 0 {
+		return bqutil.StaticQuery{}, false
+	}
+	return bqutil.StaticQuery{
+		Statement:     t.Statement,
+		Parameters:    t.Parameters,
+		ParameterMode: t.parameterMode,
+		JobOptions:    t.jobOptions,
+	}, true
+}
+
 func (t Tool) EmbedParams(ctx context.Context, paramValues parameters.ParamValues, embeddingModelsMap map[string]embeddingmodels.EmbeddingModel) (parameters.ParamValues, error) {
 	return parameters.EmbedParams(ctx, t.AllParams, paramValues, embeddingModelsMap, nil)
 }
@@ -19,6 +19,8 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/googleapis/genai-toolbox/internal/server"
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	bigqueryds "github.com/googleapis/genai-toolbox/internal/sources/bigquery"
 	"github.com/googleapis/genai-toolbox/internal/testutils"
 	"github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerysql"
 	"github.com/googleapis/genai-toolbox/internal/util/parameters"
@@ -146,3 +148,35 @@ func TestParseFromYamlWithTemplateBigQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestInitializeParameterMode(t *testing.T) {
+	tcs := []struct {
+		desc    string
+		cfg     bigquerysql.Config
+		wantErr bool
+	}{
+		{desc: "named", cfg: bigquerysql.Config{Statement: "SELECT * FROM t WHERE id = @id"}},
+		{desc: "positional", cfg: bigquerysql.Config{Statement: "SELECT * FROM t WHERE id = ?"}},
+		{desc: "declared positional", cfg: bigquerysql.Config{Statement: "SELECT * FROM t WHERE id = ?", ParameterMode: "positional"}},
+		{desc: "invalid mode", cfg: bigquerysql.Config{Statement: "SELECT 1", ParameterMode: "ordinal"}, wantErr: true},
+		{desc: "mixed styles", cfg: bigquerysql.Config{Statement: "SELECT * FROM t WHERE id = @id AND name = ?"}, wantErr: true},
+		{desc: "style does not match the mode", cfg: bigquerysql.Config{Statement: "SELECT * FROM t WHERE id = ?", ParameterMode: "named"}, wantErr: true},
+		{
+			desc: "templated statements are checked on invocation",
+			cfg: bigquerysql.Config{
+				Statement:          "SELECT * FROM {{.table}} WHERE id = @id AND name = ?",
+				TemplateParameters: parameters.Parameters{parameters.NewStringParameter("table", "the table")},
+			},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			src := &bigqueryds.Source{Config: bigqueryds.Config{WriteMode: bigqueryds.WriteModeAllowed}}
+			tc.cfg.Name, tc.cfg.Type, tc.cfg.Source, tc.cfg.Description = "sql_tool", "bigquery-sql", "src", "d"
+			_, err := tc.cfg.Initialize(map[string]sources.Source{"src": src})
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,339 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigqueryupdatedataagent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	yaml "github.com/goccy/go-yaml"
+	"github.com/googleapis/genai-toolbox/internal/embeddingmodels"
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	bqutil "github.com/googleapis/genai-toolbox/internal/tools/bigquery/bigquerycommon"
+	"github.com/googleapis/genai-toolbox/internal/tools/googlehttp"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+	"golang.org/x/oauth2"
+)
+
+const resourceType string = "bigquery-update-data-agent"
+
+const (
+	dataAgentIDKey       string = "data_agent_id"
+	descriptionKey       string = "description"
+	systemInstructionKey string = "system_instruction"
+	tableReferencesKey   string = "table_references"
+)
+
+// Update mask paths of the data agent fields that can be updated.
+const (
+	descriptionPath       = "description"
+	systemInstructionPath = "data_analytics_agent.published_context.system_instruction"
+	tableReferencesPath   = "data_analytics_agent.published_context.datasource_references"
+)
+
+// defaultDataAgentLocation is used when the source does not configure a location.
+const defaultDataAgentLocation = "global"
+
+// gdaBaseURL is the Gemini Data Analytics API endpoint. It can be overridden for testing.
+var gdaBaseURL = "https://geminidataanalytics.googleapis.com/v1beta"
+
+func init() {
+	if !tools.Register(resourceType, newConfig) {
+		panic(fmt.Sprintf("tool type %q already registered", resourceType))
+	}
+}
+
+func newConfig(ctx context.Context, name string, decoder *yaml.Decoder) (tools.ToolConfig, error) {
+	actual := Config{Name: name}
+	if err := decoder.DecodeContext(ctx, &actual); err != nil {
+		return nil, err
+	}
+	return actual, nil
+}
+
+type compatibleSource interface {
+	BigQueryTokenSourceWithScope(ctx context.Context, scopes []string) (oauth2.TokenSource, error)
+	BigQueryProject() string
+	BigQueryLocation() string
+	UseClientAuthorization() bool
+	ClientAuthorizationMode() string
+	IsDatasetAllowed(projectID, datasetID string) bool
+}
+
+// BQTableReference identifies a BigQuery table used as a data agent datasource.
+type BQTableReference struct {
+	ProjectID string `json:"projectId"`
+	DatasetID string `json:"datasetId"`
+	TableID   string `json:"tableId"`
+}
+
+type Config struct {
+	Name         string                 `yaml:"name" validate:"required"`
+	Type         string                 `yaml:"type" validate:"required"`
+	Source       string                 `yaml:"source" validate:"required"`
+	Description  string                 `yaml:"description" validate:"required"`
+	AuthRequired []string               `yaml:"authRequired"`
+	Annotations  *tools.ToolAnnotations `yaml:"annotations,omitempty"`
+	// MaxWait bounds how long the tool waits for the update to complete
+	// when the `wait` parameter is true, e.g. "2m". Defaults to 2m.
+	MaxWait string `yaml:"maxWait"`
+	// ExpandDescriptions expands the source properties referenced in the
+	// descriptions of the tool and its parameters, e.g. {{ .Source.Project }}.
+	ExpandDescriptions bool `yaml:"expandDescriptions"`
+}
+
+// validate interface
+var _ tools.ToolConfig = Config{}
+
+func (cfg Config) ToolConfigType() string {
+	return resourceType
+}
+
+func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error) {
+	// verify source exists
+	rawS, ok := srcs[cfg.Source]
+	if !ok {
+		return nil, fmt.Errorf("no source named %q configured", cfg.Source)
+	}
+
+	// verify the source is compatible
+	if _, ok := rawS.(compatibleSource); !ok {
+		return nil, tools.IncompatibleSourceError[compatibleSource](rawS, cfg.Source, resourceType, srcs)
+	}
+	maxWait, err := bqutil.ParseOperationMaxWait(cfg.MaxWait)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config for tool %q: %w", cfg.Name, err)
+	}
+
+	descriptionParameter := parameters.NewStringParameterWithRequired(descriptionKey, "The new description of the data agent. Pass null to clear it, or omit it to leave it unchanged.", false)
+	descriptionParameter.Nullable = true
+	systemInstructionParameter := parameters.NewStringParameterWithRequired(systemInstructionKey, "The new instructions describing how the data agent should answer questions. Pass null to clear them, or omit them to leave them unchanged.", false)
+	systemInstructionParameter.Nullable = true
+	tableRefsDescription := `A JSON string of a list of BigQuery tables replacing the tables the data agent can query. Each object in the list must contain 'projectId', 'datasetId', and 'tableId'. Example: '[{"projectId": "my-gcp-project", "datasetId": "my_dataset", "tableId": "my_table"}]'. Omit it to leave the tables unchanged.`
+
+	params := parameters.Parameters{
+		parameters.WithExamples(parameters.NewStringParameter(dataAgentIDKey, "The ID or full resource name of the data agent to update."), bqutil.DataAgentIDExamples()...),
+		descriptionParameter,
+		systemInstructionParameter,
+		parameters.NewStringParameterWithRequired(tableReferencesKey, tableRefsDescription, false),
+		bqutil.NewWaitParameter("If true, waits for the data agent to be updated and returns it, or the status of the update if it takes too long. If false, returns the long-running operation of the update at once."),
+	}
+
+	description, err := bqutil.ExpandDescriptions(cfg.Name, cfg.ExpandDescriptions, rawS, cfg.Description, params)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Description = description
+
+	annotations := tools.GetAnnotationsOrDefault(cfg.Annotations, func() *tools.ToolAnnotations {
+		// Updates overwrite the data agent's context.
+		annotations, idempotent := tools.NewDestructiveAnnotations(), true
+		annotations.IdempotentHint = &idempotent
+		return annotations
+	})
+	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, params, annotations, nil)
+
+	// finish tool setup
+	t := Tool{
+		Config:      cfg,
+		Parameters:  params,
+		manifest:    tools.Manifest{Description: cfg.Description, Parameters: params.Manifest(), AuthRequired: cfg.AuthRequired},
+		mcpManifest: mcpManifest,
+		maxWait:     maxWait,
+	}
+	return t, nil
+}
+
+// validate interface
+var _ tools.Tool = Tool{}
+
+type Tool struct {
+	Config
+	Parameters  parameters.Parameters `yaml:"parameters"`
+	manifest    tools.Manifest
+	mcpManifest tools.McpManifest
+	maxWait     time.Duration
+}
+
+func (t Tool) ToConfig() tools.ToolConfig {
+	return t.Config
+}
+
+// buildUpdate returns the data agent fields to send and the update mask for
+// the provided parameters. Omitted parameters leave their field unchanged,
+// while an explicit null clears it by listing it in the mask without a value.
+func buildUpdate(params parameters.ParamValues, isDatasetAllowed func(projectID, datasetID string) bool) (map[string]any, []string, error) {
+	mapParams := params.AsMap()
+	agentContext := map[string]any{}
+	payload := map[string]any{}
+	var mask []string
+
+	if params.WasProvided(descriptionKey) {
+		mask = append(mask, descriptionPath)
+		if description, ok := mapParams[descriptionKey].(string); ok {
+			payload["description"] = description
+		}
+	}
+	if params.WasProvided(systemInstructionKey) {
+		mask = append(mask, systemInstructionPath)
+		if systemInstruction, ok := mapParams[systemInstructionKey].(string); ok {
+			agentContext["systemInstruction"] = systemInstruction
+		}
+	}
+	if params.WasProvided(tableReferencesKey) {
+		tableRefsJSON, _ := mapParams[tableReferencesKey].(string)
+		var tableRefs []BQTableReference
+		if err := json.Unmarshal([]byte(tableRefsJSON), &tableRefs); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse '%s' JSON string: %w", tableReferencesKey, err)
+		}
+		if len(tableRefs) == 0 {
+			return nil, nil, fmt.Errorf("'%s' must list at least one table", tableReferencesKey)
+		}
+		for _, tableRef := range tableRefs {
+			if !isDatasetAllowed(tableRef.ProjectID, tableRef.DatasetID) {
+				return nil, nil, fmt.Errorf("access to dataset '%s.%s' (from table '%s') is not allowed", tableRef.ProjectID, tableRef.DatasetID, tableRef.TableID)
+			}
+		}
+		mask = append(mask, tableReferencesPath)
+		agentContext["datasourceReferences"] = map[string]any{
+			"bq": map[string]any{"tableReferences": tableRefs},
+		}
+	}
+
+	if len(mask) == 0 {
+		return nil, nil, fmt.Errorf("at least one of '%s', '%s' or '%s' must be provided", descriptionKey, systemInstructionKey, tableReferencesKey)
+	}
+	if len(agentContext) > 0 {
+		payload["dataAnalyticsAgent"] = map[string]any{"publishedContext": agentContext}
+	}
+	return payload, mask, nil
+}
+
+func (t Tool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, params parameters.ParamValues, accessToken tools.AccessToken) (any, util.ToolboxError) {
+	source, err := tools.GetCompatibleSource[compatibleSource](resourceMgr, t.Source, t.Name, t.Type)
+	if err != nil {
+		return nil, util.NewClientServerError("source used is not compatible with the tool", http.StatusInternalServerError, err)
+	}
+
+	dataAgentID, ok := params.AsMap()[dataAgentIDKey].(string)
+	if !ok || dataAgentID == "" {
+		return nil, util.NewAgentError(fmt.Sprintf("invalid or missing '%s' parameter; expected a non-empty string", dataAgentIDKey), nil)
+	}
+	location := source.BigQueryLocation()
+	if location == "" {
+		location = defaultDataAgentLocation
+	}
+	name, err := bqutil.ResolveDataAgentName(dataAgentID, source.BigQueryProject(), location)
+	if err != nil {
+		return nil, util.NewAgentError(fmt.Sprintf("invalid '%s' parameter", dataAgentIDKey), err)
+	}
+
+	payload, mask, err := buildUpdate(params, source.IsDatasetAllowed)
+	if err != nil {
+		return nil, util.NewAgentError("invalid data agent update", err)
+	}
+
+	// Get credentials for the API call
+	tokenStr, _, tbErr := bqutil.GetAPIToken(ctx, t.Name, source, accessToken, nil)
+	if tbErr != nil {
+		return nil, tbErr
+	}
+
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return nil, util.NewClientServerError("failed to marshal request payload", http.StatusInternalServerError, err)
+	}
+
+	reqURL := fmt.Sprintf("%s/%s?%s", gdaBaseURL, name, url.Values{"updateMask": {strings.Join(mask, ",")}}.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, reqURL, bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		return nil, util.NewClientServerError("failed to create request", http.StatusInternalServerError, err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", tokenStr))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Goog-API-Client", util.GDAClientID)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, util.NewClientServerError("failed to send request", http.StatusInternalServerError, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, util.NewClientServerError("failed to read response body", http.StatusInternalServerError, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		apiErr := fmt.Errorf("API returned non-200 status: %d %s", resp.StatusCode, string(body))
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			return nil, util.NewClientServerError(fmt.Sprintf("failed to update data agent %q", dataAgentID), resp.StatusCode, apiErr)
+		}
+		return nil, util.NewAgentError(fmt.Sprintf("failed to update data agent %q", dataAgentID), apiErr)
+	}
+
+	if wait, _ := params.AsMap()[bqutil.WaitKey].(bool); wait {
+		var op googlehttp.Operation
+		if err := json.Unmarshal(body, &op); err != nil {
+			return nil, util.NewClientServerError("failed to decode update data agent response", http.StatusInternalServerError, err)
+		}
+		return bqutil.WaitForOperation(ctx, gdaBaseURL, tokenStr, op, t.maxWait, fmt.Sprintf("failed to update data agent %q", dataAgentID))
+	}
+	var operation map[string]any
+	if err := json.Unmarshal(body, &operation); err != nil {
+		return nil, util.NewClientServerError("failed to decode update data agent response", http.StatusInternalServerError, err)
+	}
+	return operation, nil
+}
+
+func (t Tool) EmbedParams(ctx context.Context, paramValues parameters.ParamValues, embeddingModelsMap map[string]embeddingmodels.EmbeddingModel) (parameters.ParamValues, error) {
+	return parameters.EmbedParams(ctx, t.Parameters, paramValues, embeddingModelsMap, nil)
+}
+
+func (t Tool) Manifest() tools.Manifest {
+	return t.manifest
+}
+
+func (t Tool) McpManifest() tools.McpManifest {
+	return t.mcpManifest
+}
+
+func (t Tool) Authorized(verifiedAuthServices []string) bool {
+	return tools.IsAuthorized(t.AuthRequired, verifiedAuthServices)
+}
+
+func (t Tool) RequiresClientAuthorization(resourceMgr tools.SourceProvider) (bool, error) {
+	source, err := tools.GetCompatibleSource[compatibleSource](resourceMgr, t.Source, t.Name, t.Type)
+	if err != nil {
+		return false, err
+	}
+	return source.UseClientAuthorization(), nil
+}
+
+func (t Tool) GetAuthTokenHeaderName(resourceMgr tools.SourceProvider) (string, error) {
+	return "Authorization", nil
+}
+
+func (t Tool) GetParameters() parameters.Parameters {
+	return t.Parameters
+}
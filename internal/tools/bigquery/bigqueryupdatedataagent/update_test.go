@@ -0,0 +1,203 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigqueryupdatedataagent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	"github.com/googleapis/genai-toolbox/internal/testutils/gdafake"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+	"golang.org/x/oauth2"
+)
+
+type fakeSource struct{}
+
+func (s *fakeSource) SourceType() string              { return "bigquery" }
+func (s *fakeSource) ToConfig() sources.SourceConfig  { return nil }
+func (s *fakeSource) BigQueryProject() string         { return "test-project" }
+func (s *fakeSource) BigQueryLocation() string        { return "" }
+func (s *fakeSource) UseClientAuthorization() bool    { return false }
+func (s *fakeSource) ClientAuthorizationMode() string { return "disabled" }
+func (s *fakeSource) IsDatasetAllowed(projectID, datasetID string) bool {
+	return datasetID != "secret"
+}
+func (s *fakeSource) BigQueryTokenSourceWithScope(ctx context.Context, scopes []string) (oauth2.TokenSource, error) {
+	return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "adc-token"}), nil
+}
+
+type fakeSourceProvider struct {
+	source sources.Source
+}
+
+func (p fakeSourceProvider) GetSource(name string) (sources.Source, bool) {
+	return p.source, true
+}
+
+func newTestTool(t *testing.T) Tool {
+	t.Helper()
+	rawTool, err := Config{Name: "update_agent", Type: resourceType, Source: "src", Description: "d"}.Initialize(map[string]sources.Source{"src": &fakeSource{}})
+	if err != nil {
+		t.Fatalf("unexpected error initializing tool: %s", err)
+	}
+	return rawTool.(Tool)
+}
+
+func TestBuildUpdate(t *testing.T) {
+	tool := newTestTool(t)
+	tcs := []struct {
+		desc        string
+		in          map[string]any
+		wantPayload map[string]any
+		wantMask    []string
+		wantErr     string
+	}{
+		{
+			desc:        "omitted fields are left unchanged",
+			in:          map[string]any{"data_agent_id": "a", "description": "new"},
+			wantPayload: map[string]any{"description": "new"},
+			wantMask:    []string{descriptionPath},
+		},
+		{
+			desc:        "null clears the field",
+			in:          map[string]any{"data_agent_id": "a", "description": nil},
+			wantPayload: map[string]any{},
+			wantMask:    []string{descriptionPath},
+		},
+		{
+			desc: "values, nulls and omitted fields together",
+			in:   map[string]any{"data_agent_id": "a", "system_instruction": nil, "table_references": `[{"projectId": "p", "datasetId": "d", "tableId": "t"}]`},
+			wantPayload: map[string]any{
+				"dataAnalyticsAgent": map[string]any{"publishedContext": map[string]any{
+					"datasourceReferences": map[string]any{"bq": map[string]any{"tableReferences": []BQTableReference{{ProjectID: "p", DatasetID: "d", TableID: "t"}}}},
+				}},
+			},
+			wantMask: []string{systemInstructionPath, tableReferencesPath},
+		},
+		{
+			desc:    "nothing to update",
+			in:      map[string]any{"data_agent_id": "a"},
+			wantErr: "at least one of",
+		},
+		{
+			desc:    "table references cannot be cleared",
+			in:      map[string]any{"data_agent_id": "a", "table_references": nil},
+			wantErr: "at least one of",
+		},
+		{
+			desc:    "disallowed dataset",
+			in:      map[string]any{"data_agent_id": "a", "table_references": `[{"projectId": "p", "datasetId": "secret", "tableId": "t"}]`},
+			wantErr: "access to dataset 'p.secret'",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			params, err := parameters.ParseParams(tool.Parameters, tc.in, nil)
+			if err != nil {
+				t.Fatalf("unexpected error parsing params: %s", err)
+			}
+			payload, mask, err := buildUpdate(params, (&fakeSource{}).IsDatasetAllowed)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(tc.wantPayload, payload); diff != "" {
+				t.Errorf("unexpected payload (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantMask, mask); diff != "" {
+				t.Errorf("unexpected update mask (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestInvoke(t *testing.T) {
+	var gotMethod, gotPath, gotMask string
+	var gotBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotPath, gotMask = r.Method, r.URL.Path, r.URL.Query().Get("updateMask")
+		if err := json.NewDecoder(r.Body).Decode(&gotBody); err != nil {
+			t.Errorf("unable to decode request body: %s", err)
+		}
+		w.Write([]byte(`{"name": "operations/op"}`))
+	}))
+	defer server.Close()
+
+	originalURL := gdaBaseURL
+	gdaBaseURL = server.URL
+	defer func() { gdaBaseURL = originalURL }()
+
+	tool := newTestTool(t)
+	params, err := parameters.ParseParams(tool.Parameters, map[string]any{"data_agent_id": "my-agent", "description": nil, "system_instruction": "be brief"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error parsing params: %s", err)
+	}
+	res, tbErr := tool.Invoke(context.Background(), fakeSourceProvider{source: &fakeSource{}}, params, tools.AccessToken(""))
+	if tbErr != nil {
+		t.Fatalf("unexpected invoke error: %s", tbErr)
+	}
+	if diff := cmp.Diff(map[string]any{"name": "operations/op"}, res); diff != "" {
+		t.Errorf("unexpected result (-want +got):\n%s", diff)
+	}
+	if gotMethod != http.MethodPatch {
+		t.Errorf("expected a PATCH request, got %s", gotMethod)
+	}
+	if want := "/projects/test-project/locations/global/dataAgents/my-agent"; gotPath != want {
+		t.Errorf("unexpected path: got %q, want %q", gotPath, want)
+	}
+	if want := descriptionPath + "," + systemInstructionPath; gotMask != want {
+		t.Errorf("unexpected update mask: got %q, want %q", gotMask, want)
+	}
+	wantBody := map[string]any{"dataAnalyticsAgent": map[string]any{"publishedContext": map[string]any{"systemInstruction": "be brief"}}}
+	if diff := cmp.Diff(wantBody, gotBody); diff != "" {
+		t.Errorf("unexpected request body (-want +got):\n%s", diff)
+	}
+}
+
+func TestInvokeWait(t *testing.T) {
+	const agent = "projects/test-project/locations/global/dataAgents/my-agent"
+	fake := gdafake.New(t)
+	fake.AddDataAgent(agent, map[string]any{"description": "old"})
+	originalURL := gdaBaseURL
+	gdaBaseURL = fake.URL
+	defer func() { gdaBaseURL = originalURL }()
+
+	tool := newTestTool(t)
+	params, err := parameters.ParseParams(tool.Parameters, map[string]any{"data_agent_id": "my-agent", "description": "new", "wait": true}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error parsing params: %s", err)
+	}
+	res, tbErr := tool.Invoke(context.Background(), fakeSourceProvider{source: &fakeSource{}}, params, tools.AccessToken(""))
+	if tbErr != nil {
+		t.Fatalf("unexpected invoke error: %s", tbErr)
+	}
+	// The fake completes operations at once, so the updated data agent is
+	// returned instead of the operation.
+	if diff := cmp.Diff(map[string]any{"name": agent, "description": "new"}, res); diff != "" {
+		t.Errorf("unexpected result (-want +got):\n%s", diff)
+	}
+}
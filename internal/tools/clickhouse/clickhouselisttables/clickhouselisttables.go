@@ -68,7 +68,7 @@ func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error)
 	params := parameters.Parameters{databaseParameter}
 
 	allParameters, paramManifest, _ := parameters.ProcessParameters(nil, params)
-	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, allParameters, nil)
+	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, allParameters, nil, nil)
 
 	t := Tool{
 		Config:      cfg,
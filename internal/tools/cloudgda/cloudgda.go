@@ -114,7 +114,7 @@ type Config struct {
 	Type              string             `yaml:"type" validate:"required"`
 	Source            string             `yaml:"source" validate:"required"`
 	Description       string             `yaml:"description" validate:"required"`
-	Location          string             `yaml:"location" validate:"required"`
+	Location          string             `yaml:"location" validate:"required" expand:"true"`
 	Context           *QueryDataContext  `yaml:"context" validate:"required"`
 	GenerationOptions *GenerationOptions `yaml:"generationOptions,omitempty"`
 	AuthRequired      []string           `yaml:"authRequired"`
@@ -141,7 +141,7 @@ func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error)
 	} else {
 		cfg.Description = guidance
 	}
-	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, allParameters, nil)
+	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, allParameters, nil, nil)
 
 	t := Tool{
 		Config:      cfg,
@@ -0,0 +1,81 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/googleapis/genai-toolbox/internal/sources"
+)
+
+// sourcesMapProvider is implemented by SourceProviders that can list their
+// sources, which lets incompatible source errors name the compatible ones.
+type sourcesMapProvider interface {
+	GetSourcesMap() map[string]sources.Source
+}
+
+// IncompatibleSourceError returns the error for a source s, named sourceName,
+// that does not implement the interface T required by tools of type toolType.
+// The error names the type of s, the methods of T that s lacks, and the types
+// of the sources in srcs that implement T, if any.
+func IncompatibleSourceError[T any](s sources.Source, sourceName, toolType string, srcs map[string]sources.Source) error {
+	msg := fmt.Sprintf("invalid source for %q tool: source %q of type %q is not compatible", toolType, sourceName, s.SourceType())
+	if missing := missingMethods[T](s); len(missing) > 0 {
+		msg += fmt.Sprintf(", it lacks %s", strings.Join(missing, ", "))
+	}
+	if compatible := compatibleSourceTypes[T](srcs); len(compatible) > 0 {
+		msg += fmt.Sprintf("; compatible configured source types: %s", strings.Join(compatible, ", "))
+	}
+	return errors.New(msg)
+}
+
+// missingMethods returns the methods of the interface T that the type of s
+// does not have, or has with another signature.
+func missingMethods[T any](s sources.Source) []string {
+	iface := reflect.TypeFor[T]()
+	if iface.Kind() != reflect.Interface {
+		return nil
+	}
+	v := reflect.ValueOf(s)
+	var missing []string
+	for i := 0; i < iface.NumMethod(); i++ {
+		want := iface.Method(i)
+		got := v.MethodByName(want.Name)
+		switch {
+		case !got.IsValid():
+			missing = append(missing, want.Name)
+		case got.Type() != want.Type:
+			missing = append(missing, fmt.Sprintf("%s (has %s, want %s)", want.Name, got.Type(), want.Type))
+		}
+	}
+	return missing
+}
+
+// compatibleSourceTypes returns the sorted, unique types of the sources in
+// srcs that implement T.
+func compatibleSourceTypes[T any](srcs map[string]sources.Source) []string {
+	var types []string
+	for _, s := range srcs {
+		if _, ok := s.(T); ok && !slices.Contains(types, s.SourceType()) {
+			types = append(types, s.SourceType())
+		}
+	}
+	slices.Sort(types)
+	return types
+}
@@ -77,11 +77,9 @@ func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error)
 		desc = "Lists and filters Dataproc clusters"
 	}
 
-	allParameters := parameters.Parameters{
+	allParameters := append(parameters.Parameters{
 		parameters.NewStringParameterWithRequired("filter", `A filter constraining the clusters to list. Filters are case-sensitive and have the following syntax: field = value [AND [field = value]] ...  where field is one of status.state, clusterName, or labels.[KEY], and [KEY] is a label key. value can be * to match all values. status.state can be one of the following: ACTIVE, INACTIVE, CREATING, RUNNING, ERROR, DELETING, UPDATING, STOPPING, or STOPPED. ACTIVE contains the CREATING, UPDATING, and RUNNING states. INACTIVE contains the DELETING, ERROR, STOPPING, and STOPPED states. clusterName is the name of the cluster provided at creation time. Only the logical AND operator is supported; space-separated items are treated as having an implicit AND operator.`, false),
-		parameters.NewIntParameterWithDefault("pageSize", 20, "The maximum number of clusters to return in a single page (default 20)"),
-		parameters.NewStringParameterWithRequired("pageToken", "A page token, received from a previous `ListClusters` call", false),
-	}
+	}, parameters.NewPaginationParameters("clusters", 20)...)
 	inputSchema, _ := allParameters.McpManifest()
 
 	mcpManifest := tools.McpManifest{
@@ -111,7 +109,7 @@ type Tool struct {
 }
 
 type compatibleSource interface {
-	ListClusters(context.Context, *int, string, string) (any, error)
+	ListClusters(context.Context, parameters.PageRequest, string) (any, error)
 }
 
 // Invoke executes the tool's operation.
@@ -121,19 +119,13 @@ func (t Tool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, para
 		return nil, util.NewClientServerError("source used is not compatible with the tool", http.StatusInternalServerError, err)
 	}
 
-	paramMap := params.AsMap()
-	var pageSize *int
-	if ps, ok := paramMap["pageSize"]; ok && ps != nil {
-		pageSizeV := ps.(int)
-		if pageSizeV <= 0 {
-			return nil, util.NewAgentError(fmt.Sprintf("pageSize must be positive: %d", pageSizeV), nil)
-		}
-		pageSize = &pageSizeV
+	page, err := parameters.ParsePageRequest(params)
+	if err != nil {
+		return nil, util.NewAgentError(err.Error(), err)
 	}
-	pt, _ := paramMap["pageToken"].(string)
-	filter, _ := paramMap["filter"].(string)
+	filter, _ := params.AsMap()["filter"].(string)
 
-	res, err := source.ListClusters(ctx, pageSize, pt, filter)
+	res, err := source.ListClusters(ctx, page, filter)
 	if err != nil {
 		return nil, util.ProcessGcpError(err)
 	}
@@ -77,12 +77,10 @@ func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error)
 		desc = "Lists and filters Dataproc jobs"
 	}
 
-	allParameters := parameters.Parameters{
+	allParameters := append(parameters.Parameters{
 		parameters.NewStringParameterWithRequired("filter", `A filter constraining the jobs to list. Filters are case-sensitive and have the following syntax: field = value [AND [field = value]] ... where field is clusterName, status.state, or labels.[KEY], and [KEY] is a label key. value can be * to match all values. status.state can be one of the following: PENDING, RUNNING, CANCEL_PENDING, JOB_STATE_CANCELLED, DONE, ERROR, or ATTEMPT_FAILURE. Only the logical AND operator is supported; space-separated items are treated as having an implicit AND operator. Filtering by clusterName is recommended to improve query performance.`, false),
 		parameters.NewStringParameterWithRequired("jobStateMatcher", "Specifies if the job state matcher should match ALL jobs, only ACTIVE jobs, or only NON_ACTIVE jobs. Defaults to ALL. Supported values: ALL, ACTIVE, NON_ACTIVE.", false),
-		parameters.NewIntParameterWithDefault("pageSize", 20, "The maximum number of jobs to return in a single page (default 20)"),
-		parameters.NewStringParameterWithRequired("pageToken", "A page token, received from a previous `ListJobs` call", false),
-	}
+	}, parameters.NewPaginationParameters("jobs", 20)...)
 	inputSchema, _ := allParameters.McpManifest()
 
 	mcpManifest := tools.McpManifest{
@@ -112,7 +110,7 @@ type Tool struct {
 }
 
 type compatibleSource interface {
-	ListJobs(context.Context, *int, string, string, string) (any, error)
+	ListJobs(context.Context, parameters.PageRequest, string, string) (any, error)
 }
 
 // Invoke executes the tool's operation.
@@ -122,20 +120,15 @@ func (t Tool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, para
 		return nil, util.NewClientServerError("source used is not compatible with the tool", http.StatusInternalServerError, err)
 	}
 
-	paramMap := params.AsMap()
-	var pageSize *int
-	if ps, ok := paramMap["pageSize"]; ok && ps != nil {
-		pageSizeV := ps.(int)
-		if pageSizeV <= 0 {
-			return nil, util.NewAgentError(fmt.Sprintf("pageSize must be positive: %d", pageSizeV), nil)
-		}
-		pageSize = &pageSizeV
+	page, err := parameters.ParsePageRequest(params)
+	if err != nil {
+		return nil, util.NewAgentError(err.Error(), err)
 	}
-	pt, _ := paramMap["pageToken"].(string)
+	paramMap := params.AsMap()
 	filter, _ := paramMap["filter"].(string)
 	matcher, _ := paramMap["jobStateMatcher"].(string)
 
-	res, err := source.ListJobs(ctx, pageSize, pt, filter, matcher)
+	res, err := source.ListJobs(ctx, page, filter, matcher)
 	if err != nil {
 		return nil, util.ProcessGcpError(err)
 	}
@@ -62,14 +62,20 @@ type Config struct {
 }
 
 // validate interface
-var _ tools.ToolConfig = Config{}
+var _ tools.ToolConfigWithFields = Config{}
 
 func (cfg Config) ToolConfigType() string {
 	return resourceType
 }
 
+// DeclaredFields reports that the timeout of the tool is its own field, which
+// is sent with the query, and not the common timeout option.
+func (cfg Config) DeclaredFields() []string {
+	return []string{"timeout"}
+}
+
 func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error) {
-	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, cfg.Parameters, nil)
+	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, cfg.Parameters, nil, nil)
 
 	// finish tool setup
 	t := Tool{
@@ -55,12 +55,18 @@ type Config struct {
 	Parameters   parameters.Parameters `yaml:"parameters"`
 }
 
-var _ tools.ToolConfig = Config{}
+var _ tools.ToolConfigWithFields = Config{}
 
 func (c Config) ToolConfigType() string {
 	return resourceType
 }
 
+// DeclaredFields reports that the timeout of the tool is its own field, the
+// timeout of the query in seconds, and not the common timeout option.
+func (c Config) DeclaredFields() []string {
+	return []string{"timeout"}
+}
+
 func newConfig(ctx context.Context, name string, decoder *yaml.Decoder) (tools.ToolConfig, error) {
 	actual := Config{Name: name}
 	if err := decoder.DecodeContext(ctx, &actual); err != nil {
@@ -78,7 +84,7 @@ type Tool struct {
 var _ tools.Tool = Tool{}
 
 func (c Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error) {
-	mcpManifest := tools.GetMcpManifest(c.Name, c.Description, c.AuthRequired, c.Parameters, nil)
+	mcpManifest := tools.GetMcpManifest(c.Name, c.Description, c.AuthRequired, c.Parameters, nil, nil)
 
 	return Tool{
 		Config:      c,
@@ -0,0 +1,203 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// SecretResolver returns the value of the secret reference ref, e.g.
+// "secret://projects/my-project/secrets/my-secret".
+type SecretResolver func(ctx context.Context, ref string) (string, error)
+
+var (
+	secretResolversMu sync.RWMutex
+	secretResolvers   = map[string]SecretResolver{}
+)
+
+// RegisterSecretResolver registers the resolver of the secret references with
+// the URI scheme scheme, e.g. "secret" for "secret://...". It returns false if
+// the scheme already has a resolver.
+func RegisterSecretResolver(scheme string, resolver SecretResolver) bool {
+	secretResolversMu.Lock()
+	defer secretResolversMu.Unlock()
+	if _, exists := secretResolvers[scheme]; exists {
+		return false
+	}
+	secretResolvers[scheme] = resolver
+	return true
+}
+
+func secretResolver(value string) (SecretResolver, bool) {
+	scheme, _, ok := strings.Cut(value, "://")
+	if !ok {
+		return nil, false
+	}
+	secretResolversMu.RLock()
+	defer secretResolversMu.RUnlock()
+	resolver, ok := secretResolvers[scheme]
+	return resolver, ok
+}
+
+// envVarRegex matches the references to environment variables, ${NAME},
+// ${NAME:-default} or ${NAME:default} as in the rest of the tools file.
+var envVarRegex = regexp.MustCompile(`\$\{(\w+)(:-?([^}]*))?\}`)
+
+// ExpandConfig returns cfg with the values of the fields tagged
+// `expand:"true"` expanded. References to environment variables, ${NAME},
+// ${NAME:-default} or ${NAME:default}, are replaced with their values, and values that are
+// secret references of a scheme registered with RegisterSecretResolver, e.g.
+// "secret://projects/p/secrets/s", are replaced with the secret. Tagged fields
+// can be strings, or slices, maps and pointers of strings. Errors report the
+// YAML path of the field.
+func ExpandConfig(ctx context.Context, cfg ToolConfig) (ToolConfig, error) {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Struct {
+		return cfg, nil
+	}
+	// Expand a copy, as cfg may share slices and maps with other configs.
+	expanded := reflect.New(v.Type()).Elem()
+	expanded.Set(v)
+	if err := expandStruct(ctx, expanded, ""); err != nil {
+		return nil, err
+	}
+	return expanded.Interface().(ToolConfig), nil
+}
+
+func expandStruct(ctx context.Context, v reflect.Value, path string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			name = field.Name
+		}
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+		fv := v.Field(i)
+		if field.Tag.Get("expand") == "true" {
+			if err := expandValue(ctx, fv, fieldPath); err != nil {
+				return err
+			}
+			continue
+		}
+		// Look for tagged fields in nested configs.
+		if fv.Kind() == reflect.Pointer && !fv.IsNil() && fv.Elem().Kind() == reflect.Struct {
+			elem := reflect.New(fv.Elem().Type())
+			elem.Elem().Set(fv.Elem())
+			if err := expandStruct(ctx, elem.Elem(), fieldPath); err != nil {
+				return err
+			}
+			fv.Set(elem)
+		} else if fv.Kind() == reflect.Struct {
+			if err := expandStruct(ctx, fv, fieldPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func expandValue(ctx context.Context, v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.String:
+		s, err := expandString(ctx, v.String())
+		if err != nil {
+			return fmt.Errorf("field %q: %w", path, err)
+		}
+		v.SetString(s)
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		elem := reflect.New(v.Elem().Type())
+		elem.Elem().Set(v.Elem())
+		if err := expandValue(ctx, elem.Elem(), path); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		s := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(s, v)
+		for i := 0; i < s.Len(); i++ {
+			if err := expandValue(ctx, s.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		m := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			elem := reflect.New(iter.Value().Type()).Elem()
+			elem.Set(iter.Value())
+			if err := expandValue(ctx, elem, fmt.Sprintf("%s.%v", path, iter.Key())); err != nil {
+				return err
+			}
+			m.SetMapIndex(iter.Key(), elem)
+		}
+		v.Set(m)
+	default:
+		return fmt.Errorf("field %q: only strings, and slices, maps and pointers of strings can be expanded, got %s", path, v.Type())
+	}
+	return nil
+}
+
+// expandString replaces the references to environment variables in s, and
+// resolves s if it is a secret reference.
+func expandString(ctx context.Context, s string) (string, error) {
+	var err error
+	s = envVarRegex.ReplaceAllStringFunc(s, func(match string) string {
+		parts := envVarRegex.FindStringSubmatch(match)
+		if value, found := os.LookupEnv(parts[1]); found {
+			return value
+		}
+		if parts[2] != "" {
+			return parts[3]
+		}
+		if err == nil {
+			err = fmt.Errorf("environment variable %q is not set", parts[1])
+		}
+		return ""
+	})
+	if err != nil {
+		return "", err
+	}
+	if resolver, ok := secretResolver(s); ok {
+		secret, err := resolver(ctx, s)
+		if err != nil {
+			return "", fmt.Errorf("unable to resolve secret %q: %w", s, err)
+		}
+		return secret, nil
+	}
+	return s, nil
+}
@@ -0,0 +1,27 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import "testing"
+
+// RegisterForTest registers the tool type resourceType for the duration of
+// the test t.
+func RegisterForTest(t testing.TB, resourceType string, factory ToolConfigFactory) {
+	t.Helper()
+	if !Register(resourceType, factory) {
+		t.Fatalf("tool type %q already registered", resourceType)
+	}
+	t.Cleanup(func() { delete(toolRegistry, resourceType) })
+}
@@ -0,0 +1,325 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package googlehttp calls Google APIs over HTTP with JSON bodies, for tools
+// that are not backed by a client library.
+package googlehttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/googleapis/genai-toolbox/internal/telemetry"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// APIClientHeader is the header identifying the toolbox to Google APIs.
+	APIClientHeader = "X-Goog-API-Client"
+	// UserProjectHeader is the header selecting the project billed for a
+	// call.
+	UserProjectHeader = "X-Goog-User-Project"
+)
+
+// SpanName is the name of the span of calls, which is a child of the span of
+// the invocation making them.
+const SpanName = "toolbox/googlehttp/call"
+
+// defaultBackoff is the delay before the first retry when Client.Backoff is
+// not set. It doubles with each retry.
+const defaultBackoff = 500 * time.Millisecond
+
+// TokenFunc returns the access token a call is authorized with.
+type TokenFunc func(ctx context.Context) (string, error)
+
+// StaticToken returns a TokenFunc that always returns token, for tokens that
+// were already resolved for the invocation, e.g. the client's token.
+func StaticToken(token string) TokenFunc {
+	return func(context.Context) (string, error) {
+		return token, nil
+	}
+}
+
+// FromTokenSource returns a TokenFunc that gets tokens from ts.
+func FromTokenSource(ts oauth2.TokenSource) TokenFunc {
+	return func(context.Context) (string, error) {
+		if ts == nil {
+			return "", fmt.Errorf("token source is missing")
+		}
+		token, err := ts.Token()
+		if err != nil {
+			return "", fmt.Errorf("failed to get token from token source: %w", err)
+		}
+		return token.AccessToken, nil
+	}
+}
+
+// Client calls a Google API. The zero value is not usable; Token must be set.
+type Client struct {
+	// Token returns the token of each call.
+	Token TokenFunc
+	// APIClient is the value of the X-Goog-API-Client header. Defaults to
+	// util.GDAClientID.
+	APIClient string
+	// UserProject is the value of the X-Goog-User-Project header, which is
+	// not sent if empty.
+	UserProject string
+	// MaxRetries is the number of times an idempotent call is retried after
+	// a transport error or a 429, 502, 503 or 504 response.
+	MaxRetries int
+	// Backoff is the delay before the first retry. Defaults to 500ms.
+	Backoff time.Duration
+	// HTTPClient sends the requests. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+	// MaxResponseBytes is the maximum size of the body of a response, which
+	// is not read further. Larger responses fail with a
+	// *ResponseTooLargeError. If zero, the size is not limited.
+	MaxResponseBytes int64
+}
+
+// APIError is the error of a call that got a non-2xx response.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Status is the canonical status of the error, e.g. "NOT_FOUND", if the
+	// response has a Google API error body.
+	Status string
+	// Message is the error message of the response, or its body if it has
+	// no Google API error body.
+	Message string
+}
+
+func (e *APIError) Error() string {
+	if e.Status != "" {
+		return fmt.Sprintf("API returned non-2xx status: %d %s: %s", e.StatusCode, e.Status, e.Message)
+	}
+	return fmt.Sprintf("API returned non-2xx status: %d %s", e.StatusCode, e.Message)
+}
+
+// ResponseTooLargeError is the error of a call whose response is larger than
+// the MaxResponseBytes of the client.
+type ResponseTooLargeError struct {
+	// Limit is the MaxResponseBytes of the client.
+	Limit int64
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("the response of the API is larger than the limit of %d bytes", e.Limit)
+}
+
+// DoJSON sends body, if not nil, as JSON to url and decodes the JSON response
+// into out, if not nil. Errors of non-2xx responses are *APIError.
+func (c *Client) DoJSON(ctx context.Context, method, url string, body, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request body: %w", err)
+		}
+	}
+	token, err := c.Token(ctx)
+	if err != nil {
+		return err
+	}
+
+	ctx, span := telemetry.Tracer().Start(ctx, SpanName, trace.WithAttributes(
+		attribute.String("http.request.method", method),
+		attribute.String("url.full", url),
+	))
+	defer span.End()
+	err = c.doWithRetries(ctx, method, url, token, payload, out)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		span.SetAttributes(attribute.Int("http.response.status_code", apiErr.StatusCode))
+	}
+	if err != nil {
+		span.SetStatus(codes.Error, "call failed")
+	}
+	return err
+}
+
+// doWithRetries sends a call, retrying it if it is idempotent.
+func (c *Client) doWithRetries(ctx context.Context, method, url, token string, payload []byte, out any) error {
+	span := trace.SpanFromContext(ctx)
+	retries := 0
+	if isIdempotent(method) {
+		retries = c.MaxRetries
+	}
+	backoff := c.Backoff
+	if backoff <= 0 {
+		backoff = defaultBackoff
+	}
+	for attempt := 0; ; attempt++ {
+		respBody, err := c.do(ctx, method, url, token, payload)
+		if err == nil {
+			if out == nil || len(bytes.TrimSpace(respBody)) == 0 {
+				return nil
+			}
+			if err := json.Unmarshal(respBody, out); err != nil {
+				return fmt.Errorf("failed to decode response body: %w", err)
+			}
+			return nil
+		}
+		if attempt >= retries || !isRetryable(err) {
+			return err
+		}
+		span.AddEvent("retry", trace.WithAttributes(attribute.Int("attempt", attempt+1)))
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(backoff << attempt):
+		}
+	}
+}
+
+// do sends a single request and returns the body of its response.
+func (c *Client) do(ctx context.Context, method, url, token string, payload []byte) ([]byte, error) {
+	var reqBody io.Reader
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	apiClient := c.APIClient
+	if apiClient == "" {
+		apiClient = util.GDAClientID
+	}
+	req.Header.Set(APIClientHeader, apiClient)
+	if c.UserProject != "" {
+		req.Header.Set(UserProjectHeader, c.UserProject)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, &transportError{err: err}
+	}
+	defer resp.Body.Close()
+
+	var body io.Reader = resp.Body
+	if c.MaxResponseBytes > 0 {
+		// one more byte tells a response of the limit from a larger one
+		body = io.LimitReader(resp.Body, c.MaxResponseBytes+1)
+	}
+	respBody, err := io.ReadAll(body)
+	if err != nil {
+		return nil, &transportError{err: fmt.Errorf("failed to read response body: %w", err)}
+	}
+	if c.MaxResponseBytes > 0 && int64(len(respBody)) > c.MaxResponseBytes {
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return nil, newAPIError(resp.StatusCode, respBody[:c.MaxResponseBytes])
+		}
+		return nil, &ResponseTooLargeError{Limit: c.MaxResponseBytes}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, newAPIError(resp.StatusCode, respBody)
+	}
+	return respBody, nil
+}
+
+// newAPIError decodes the Google API error of a response, falling back to
+// the raw body.
+func newAPIError(statusCode int, body []byte) *APIError {
+	var errBody struct {
+		Error struct {
+			Message string `json:"message"`
+			Status  string `json:"status"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &errBody); err == nil && errBody.Error.Message != "" {
+		return &APIError{StatusCode: statusCode, Status: errBody.Error.Status, Message: errBody.Error.Message}
+	}
+	return &APIError{StatusCode: statusCode, Message: string(body)}
+}
+
+// transportError is an error sending a request or reading its response.
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string { return e.err.Error() }
+
+func (e *transportError) Unwrap() error { return e.err }
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func isRetryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	var tErr *transportError
+	return errors.As(err, &tErr) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// ToolboxError converts an error of DoJSON or WaitOperation into the error of
+// a tool invocation, with msg describing the call. Authorization failures are
+// returned to the client, other API and operation errors to the agent, and
+// ToolboxErrors of the TokenFunc as is.
+func ToolboxError(err error, msg string) util.ToolboxError {
+	var tbErr util.ToolboxError
+	if errors.As(err, &tbErr) {
+		return tbErr
+	}
+	var status int
+	var apiErr *APIError
+	var opErr *OperationError
+	var sizeErr *ResponseTooLargeError
+	switch {
+	case errors.As(err, &sizeErr):
+		return util.NewAgentError(msg, err)
+	case errors.As(err, &apiErr):
+		status = apiErr.StatusCode
+	case errors.As(err, &opErr):
+		status = opErr.StatusCode()
+	default:
+		return util.NewClientServerError(msg, http.StatusInternalServerError, err)
+	}
+	if status == http.StatusUnauthorized || status == http.StatusForbidden {
+		return util.NewClientServerError(msg, status, err)
+	}
+	code, _ := util.ErrorCodeFromStatus(status)
+	return util.NewAgentError(msg, err).WithCode(code)
+}
@@ -0,0 +1,328 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package googlehttp_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/tools/googlehttp"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"golang.org/x/oauth2"
+)
+
+func TestDoJSONHeaders(t *testing.T) {
+	tcs := []struct {
+		desc    string
+		client  googlehttp.Client
+		body    any
+		wantHdr map[string]string
+	}{
+		{
+			desc:   "defaults",
+			client: googlehttp.Client{Token: googlehttp.StaticToken("my-token")},
+			wantHdr: map[string]string{
+				"Authorization":              "Bearer my-token",
+				googlehttp.APIClientHeader:   util.GDAClientID,
+				googlehttp.UserProjectHeader: "",
+				"Content-Type":               "",
+			},
+		},
+		{
+			desc: "custom client and user project with a body",
+			client: googlehttp.Client{
+				Token:       googlehttp.StaticToken("my-token"),
+				APIClient:   "my-client/1.0",
+				UserProject: "my-project",
+			},
+			body: map[string]any{"a": 1},
+			wantHdr: map[string]string{
+				"Authorization":              "Bearer my-token",
+				googlehttp.APIClientHeader:   "my-client/1.0",
+				googlehttp.UserProjectHeader: "my-project",
+				"Content-Type":               "application/json",
+			},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			var got http.Header
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Clone()
+				_, _ = w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+
+			if err := tc.client.DoJSON(context.Background(), http.MethodPost, server.URL, tc.body, nil); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			for name, want := range tc.wantHdr {
+				if got.Get(name) != want {
+					t.Errorf("unexpected %s header: got %q, want %q", name, got.Get(name), want)
+				}
+			}
+		})
+	}
+}
+
+func TestDoJSONBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			t.Errorf("unexpected method: %s", r.Method)
+		}
+		var in map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			t.Errorf("unable to decode request body: %s", err)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"echo": in["name"]})
+	}))
+	defer server.Close()
+
+	client := googlehttp.Client{Token: googlehttp.StaticToken("my-token")}
+	var out map[string]any
+	if err := client.DoJSON(context.Background(), http.MethodPatch, server.URL, map[string]any{"name": "agent"}, &out); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diff := cmp.Diff(map[string]any{"echo": "agent"}, out); diff != "" {
+		t.Fatalf("unexpected output (-want +got):\n%s", diff)
+	}
+}
+
+func TestDoJSONEmptyResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := googlehttp.Client{Token: googlehttp.StaticToken("my-token")}
+	var out map[string]any
+	if err := client.DoJSON(context.Background(), http.MethodDelete, server.URL, nil, &out); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if out != nil {
+		t.Fatalf("expected no output, got %v", out)
+	}
+}
+
+func TestDoJSONMaxResponseBytes(t *testing.T) {
+	const body = `{"name": "agent"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	}))
+	defer server.Close()
+
+	client := googlehttp.Client{Token: googlehttp.StaticToken("my-token"), MaxResponseBytes: int64(len(body))}
+	var out map[string]any
+	if err := client.DoJSON(context.Background(), http.MethodGet, server.URL, nil, &out); err != nil {
+		t.Fatalf("unexpected error for a response of the limit: %s", err)
+	}
+
+	client.MaxResponseBytes--
+	err := client.DoJSON(context.Background(), http.MethodGet, server.URL, nil, &out)
+	var sizeErr *googlehttp.ResponseTooLargeError
+	if !errors.As(err, &sizeErr) || sizeErr.Limit != int64(len(body))-1 {
+		t.Fatalf("expected a *ResponseTooLargeError with the limit, got %v", err)
+	}
+	if want := fmt.Sprintf("larger than the limit of %d bytes", len(body)-1); !strings.Contains(err.Error(), want) {
+		t.Errorf("error %q does not contain %q", err, want)
+	}
+	if tbErr := googlehttp.ToolboxError(err, "failed"); tbErr.Category() != util.CategoryAgent {
+		t.Errorf("expected an agent error, got %s", tbErr.Category())
+	}
+}
+
+func TestDoJSONAPIError(t *testing.T) {
+	tcs := []struct {
+		desc   string
+		status int
+		body   string
+		want   googlehttp.APIError
+	}{
+		{
+			desc:   "google api error",
+			status: http.StatusNotFound,
+			body:   `{"error": {"code": 404, "message": "agent not found", "status": "NOT_FOUND"}}`,
+			want:   googlehttp.APIError{StatusCode: http.StatusNotFound, Status: "NOT_FOUND", Message: "agent not found"},
+		},
+		{
+			desc:   "raw body",
+			status: http.StatusBadRequest,
+			body:   "bad request",
+			want:   googlehttp.APIError{StatusCode: http.StatusBadRequest, Message: "bad request"},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			client := googlehttp.Client{Token: googlehttp.StaticToken("my-token")}
+			err := client.DoJSON(context.Background(), http.MethodGet, server.URL, nil, nil)
+			var apiErr *googlehttp.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected an APIError, got %v", err)
+			}
+			if diff := cmp.Diff(tc.want, *apiErr); diff != "" {
+				t.Fatalf("unexpected error (-want +got):\n%s", diff)
+			}
+			if !strings.Contains(err.Error(), fmt.Sprint(tc.status)) {
+				t.Fatalf("expected the error to contain the status code, got %q", err)
+			}
+		})
+	}
+}
+
+func TestDoJSONRetries(t *testing.T) {
+	tcs := []struct {
+		desc      string
+		method    string
+		statuses  []int
+		wantCalls int32
+		wantErr   bool
+	}{
+		{desc: "retried until success", method: http.MethodGet, statuses: []int{503, 429, 200}, wantCalls: 3},
+		{desc: "retries exhausted", method: http.MethodGet, statuses: []int{503, 502, 504, 200}, wantCalls: 3, wantErr: true},
+		{desc: "client errors are not retried", method: http.MethodGet, statuses: []int{404, 200}, wantCalls: 1, wantErr: true},
+		{desc: "non-idempotent calls are not retried", method: http.MethodPost, statuses: []int{503, 200}, wantCalls: 1, wantErr: true},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := calls.Add(1)
+				w.WriteHeader(tc.statuses[n-1])
+				_, _ = w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+
+			client := googlehttp.Client{Token: googlehttp.StaticToken("my-token"), MaxRetries: 2, Backoff: time.Millisecond}
+			err := client.DoJSON(context.Background(), tc.method, server.URL, nil, nil)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := calls.Load(); got != tc.wantCalls {
+				t.Fatalf("unexpected number of calls: got %d, want %d", got, tc.wantCalls)
+			}
+		})
+	}
+}
+
+func TestDoJSONContextCanceledDuringBackoff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	client := googlehttp.Client{Token: googlehttp.StaticToken("my-token"), MaxRetries: 5, Backoff: time.Minute}
+	start := time.Now()
+	err := client.DoJSON(ctx, http.MethodGet, server.URL, nil, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline error, got %v", err)
+	}
+	if time.Since(start) > 10*time.Second {
+		t.Fatalf("backoff did not stop when the context was done")
+	}
+}
+
+func TestDoJSONToken(t *testing.T) {
+	tokenErr := util.NewClientServerError("no token", http.StatusUnauthorized, nil)
+	client := googlehttp.Client{Token: func(context.Context) (string, error) { return "", tokenErr }}
+	err := client.DoJSON(context.Background(), http.MethodGet, "http://127.0.0.1:0", nil, nil)
+	if got := googlehttp.ToolboxError(err, "failed"); got != tokenErr {
+		t.Fatalf("expected the token error, got %v", got)
+	}
+
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "adc-token"})
+	client = googlehttp.Client{Token: googlehttp.FromTokenSource(ts)}
+	if err := client.DoJSON(context.Background(), http.MethodGet, server.URL, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if gotAuth != "Bearer adc-token" {
+		t.Fatalf("unexpected Authorization header: %q", gotAuth)
+	}
+
+	client = googlehttp.Client{Token: googlehttp.FromTokenSource(nil)}
+	if err := client.DoJSON(context.Background(), http.MethodGet, server.URL, nil, nil); err == nil {
+		t.Fatalf("expected an error for a missing token source")
+	}
+}
+
+func TestToolboxError(t *testing.T) {
+	tcs := []struct {
+		desc         string
+		err          error
+		wantCategory util.ErrorCategory
+		wantCode     util.ErrorCode
+	}{
+		{
+			desc:         "forbidden",
+			err:          &googlehttp.APIError{StatusCode: http.StatusForbidden},
+			wantCategory: util.CategoryServer,
+			wantCode:     util.ErrorCodePermissionDenied,
+		},
+		{
+			desc:         "not found",
+			err:          &googlehttp.APIError{StatusCode: http.StatusNotFound},
+			wantCategory: util.CategoryAgent,
+			wantCode:     util.ErrorCodeNotFound,
+		},
+		{
+			desc:         "rate limited",
+			err:          &googlehttp.APIError{StatusCode: http.StatusTooManyRequests},
+			wantCategory: util.CategoryAgent,
+			wantCode:     util.ErrorCodeRateLimited,
+		},
+		{
+			desc:         "transport error",
+			err:          io.ErrUnexpectedEOF,
+			wantCategory: util.CategoryServer,
+			wantCode:     util.ErrorCodeInternal,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := googlehttp.ToolboxError(tc.err, "failed")
+			if got.Category() != tc.wantCategory {
+				t.Errorf("unexpected category: got %s, want %s", got.Category(), tc.wantCategory)
+			}
+			if code := got.ErrorInfo().Code; code != tc.wantCode {
+				t.Errorf("unexpected code: got %s, want %s", code, tc.wantCode)
+			}
+			if !errors.Is(got, tc.err) {
+				t.Errorf("expected the error to wrap %v", tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package googlehttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// defaultPollInterval is the delay before the first poll of an
+	// operation when WaitOptions.Interval is not set.
+	defaultPollInterval = time.Second
+	// defaultMaxPollInterval bounds the delay between polls when
+	// WaitOptions.MaxInterval is not set.
+	defaultMaxPollInterval = 10 * time.Second
+)
+
+// Operation is a long-running operation of a Google API.
+type Operation struct {
+	// Name is the resource name of the operation, e.g.
+	// "projects/p/locations/l/operations/o".
+	Name string `json:"name"`
+	// Done is whether the operation completed, with Error or Response.
+	Done bool `json:"done"`
+	// Metadata is the progress of the operation, as reported by the API.
+	Metadata map[string]any `json:"metadata,omitempty"`
+	// Error is the status of a failed operation.
+	Error *OperationStatus `json:"error,omitempty"`
+	// Response is the result of a successful operation, e.g. the created
+	// resource.
+	Response map[string]any `json:"response,omitempty"`
+}
+
+// OperationStatus is the google.rpc.Status of a failed operation.
+type OperationStatus struct {
+	// Code is the canonical gRPC code of the error, e.g. 5 for NOT_FOUND.
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Details []any  `json:"details,omitempty"`
+}
+
+// OperationError is the error of an operation that completed with an error.
+type OperationError struct {
+	// Name is the resource name of the operation.
+	Name   string
+	Status OperationStatus
+}
+
+func (e *OperationError) Error() string {
+	return fmt.Sprintf("operation %q failed: %s: %s", e.Name, codeNames[e.Status.Code], e.Status.Message)
+}
+
+// StatusCode returns the HTTP status of the code of the error, following the
+// mapping of Google APIs.
+func (e *OperationError) StatusCode() int {
+	if status, ok := codeStatuses[e.Status.Code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// codeNames are the names of the canonical gRPC codes.
+var codeNames = map[int]string{
+	0: "OK", 1: "CANCELLED", 2: "UNKNOWN", 3: "INVALID_ARGUMENT", 4: "DEADLINE_EXCEEDED",
+	5: "NOT_FOUND", 6: "ALREADY_EXISTS", 7: "PERMISSION_DENIED", 8: "RESOURCE_EXHAUSTED",
+	9: "FAILED_PRECONDITION", 10: "ABORTED", 11: "OUT_OF_RANGE", 12: "UNIMPLEMENTED",
+	13: "INTERNAL", 14: "UNAVAILABLE", 15: "DATA_LOSS", 16: "UNAUTHENTICATED",
+}
+
+// codeStatuses are the HTTP statuses of the canonical gRPC codes.
+var codeStatuses = map[int]int{
+	1:  499,
+	2:  http.StatusInternalServerError,
+	3:  http.StatusBadRequest,
+	4:  http.StatusGatewayTimeout,
+	5:  http.StatusNotFound,
+	6:  http.StatusConflict,
+	7:  http.StatusForbidden,
+	8:  http.StatusTooManyRequests,
+	9:  http.StatusBadRequest,
+	10: http.StatusConflict,
+	11: http.StatusBadRequest,
+	12: http.StatusNotImplemented,
+	13: http.StatusInternalServerError,
+	14: http.StatusServiceUnavailable,
+	15: http.StatusInternalServerError,
+	16: http.StatusUnauthorized,
+}
+
+// ErrOperationTimeout is the error of WaitOperation when the operation is not
+// done within its MaxWait or before the deadline of its context.
+var ErrOperationTimeout = errors.New("timed out waiting for the operation")
+
+// WaitOptions configures WaitOperation.
+type WaitOptions struct {
+	// MaxWait bounds how long the operation is polled for. If zero, it is
+	// polled until the context is done.
+	MaxWait time.Duration
+	// Interval is the delay before the first poll. It doubles after each
+	// poll, up to MaxInterval. Defaults to 1s.
+	Interval time.Duration
+	// MaxInterval bounds the delay between polls. Defaults to 10s.
+	MaxInterval time.Duration
+}
+
+// WaitOperation polls op, with GET requests on baseURL/op.Name, until it is
+// done, and returns the done operation. It returns an *OperationError if the
+// operation failed. If the operation is not done within opts.MaxWait or
+// before the deadline of ctx, it returns the last status of the operation
+// and an error wrapping ErrOperationTimeout, so that callers can report the
+// progress made.
+func (c *Client) WaitOperation(ctx context.Context, baseURL string, op Operation, opts WaitOptions) (Operation, error) {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	maxInterval := opts.MaxInterval
+	if maxInterval <= 0 {
+		maxInterval = defaultMaxPollInterval
+	}
+	pollCtx := ctx
+	if opts.MaxWait > 0 {
+		var cancel context.CancelFunc
+		pollCtx, cancel = context.WithTimeout(ctx, opts.MaxWait)
+		defer cancel()
+	}
+
+	start := time.Now()
+	timeout := func() (Operation, error) {
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return op, ctx.Err()
+		}
+		return op, fmt.Errorf("%w: operation %q is not done after %s", ErrOperationTimeout, op.Name, time.Since(start).Round(time.Millisecond))
+	}
+	for !op.Done {
+		if op.Name == "" {
+			return op, fmt.Errorf("operation has no name to poll")
+		}
+		select {
+		case <-pollCtx.Done():
+			return timeout()
+		case <-time.After(interval):
+		}
+		var next Operation
+		if err := c.DoJSON(pollCtx, http.MethodGet, fmt.Sprintf("%s/%s", baseURL, op.Name), nil, &next); err != nil {
+			if pollCtx.Err() != nil {
+				return timeout()
+			}
+			return op, fmt.Errorf("failed to poll operation %q: %w", op.Name, err)
+		}
+		op = next
+		interval = min(interval*2, maxInterval)
+	}
+	if op.Error != nil {
+		return op, &OperationError{Name: op.Name, Status: *op.Error}
+	}
+	return op, nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package googlehttp_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/tools/googlehttp"
+	"github.com/googleapis/genai-toolbox/internal/util"
+)
+
+// newOperationServer serves the operation "operations/op", which is done
+// after doneAfter polls with the final status final.
+func newOperationServer(t *testing.T, doneAfter int32, final googlehttp.Operation) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var polls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/operations/op" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		n := polls.Add(1)
+		op := googlehttp.Operation{Name: "operations/op", Metadata: map[string]any{"polls": float64(n)}}
+		if doneAfter > 0 && n >= doneAfter {
+			op = final
+		}
+		_ = json.NewEncoder(w).Encode(op)
+	}))
+	t.Cleanup(server.Close)
+	return server, &polls
+}
+
+func TestWaitOperation(t *testing.T) {
+	opts := googlehttp.WaitOptions{MaxWait: 5 * time.Second, Interval: time.Millisecond, MaxInterval: 4 * time.Millisecond}
+	client := googlehttp.Client{Token: googlehttp.StaticToken("my-token")}
+	pending := googlehttp.Operation{Name: "operations/op"}
+
+	t.Run("success", func(t *testing.T) {
+		final := googlehttp.Operation{Name: "operations/op", Done: true, Response: map[string]any{"name": "agents/a"}}
+		server, polls := newOperationServer(t, 3, final)
+		got, err := client.WaitOperation(context.Background(), server.URL, pending, opts)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if diff := cmp.Diff(final, got); diff != "" {
+			t.Errorf("unexpected operation (-want +got):\n%s", diff)
+		}
+		if n := polls.Load(); n != 3 {
+			t.Errorf("got %d polls, want 3", n)
+		}
+	})
+
+	t.Run("already done", func(t *testing.T) {
+		done := googlehttp.Operation{Name: "operations/op", Done: true}
+		got, err := client.WaitOperation(context.Background(), "http://unused.invalid", done, opts)
+		if err != nil || !got.Done {
+			t.Fatalf("expected a done operation not to be polled, got %v, %v", got, err)
+		}
+	})
+
+	t.Run("operation error", func(t *testing.T) {
+		final := googlehttp.Operation{Name: "operations/op", Done: true, Error: &googlehttp.OperationStatus{Code: 6, Message: "agent exists"}}
+		server, _ := newOperationServer(t, 1, final)
+		_, err := client.WaitOperation(context.Background(), server.URL, pending, opts)
+		var opErr *googlehttp.OperationError
+		if !errors.As(err, &opErr) {
+			t.Fatalf("expected an OperationError, got %v", err)
+		}
+		if opErr.StatusCode() != http.StatusConflict {
+			t.Errorf("got status %d, want %d", opErr.StatusCode(), http.StatusConflict)
+		}
+		if want := `operation "operations/op" failed: ALREADY_EXISTS: agent exists`; err.Error() != want {
+			t.Errorf("got error %q, want %q", err, want)
+		}
+		tbErr := googlehttp.ToolboxError(err, "failed")
+		if tbErr.Category() != util.CategoryAgent || tbErr.ErrorInfo().Code != util.ErrorCodeInvalidArgument {
+			t.Errorf("unexpected toolbox error: %s %s", tbErr.Category(), tbErr.ErrorInfo().Code)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		server, _ := newOperationServer(t, 0, googlehttp.Operation{})
+		timeoutOpts := opts
+		timeoutOpts.MaxWait = 50 * time.Millisecond
+		got, err := client.WaitOperation(context.Background(), server.URL, pending, timeoutOpts)
+		if !errors.Is(err, googlehttp.ErrOperationTimeout) {
+			t.Fatalf("expected a timeout, got %v", err)
+		}
+		if got.Done || got.Metadata["polls"] == nil {
+			t.Errorf("expected the last status of the operation, got %v", got)
+		}
+	})
+
+	t.Run("context deadline", func(t *testing.T) {
+		server, _ := newOperationServer(t, 0, googlehttp.Operation{})
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		got, err := client.WaitOperation(ctx, server.URL, pending, opts)
+		if !errors.Is(err, googlehttp.ErrOperationTimeout) {
+			t.Fatalf("expected a timeout, got %v", err)
+		}
+		if got.Name != "operations/op" {
+			t.Errorf("expected the last status of the operation, got %v", got)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		server, _ := newOperationServer(t, 0, googlehttp.Operation{})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := client.WaitOperation(ctx, server.URL, pending, opts); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected the context's error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,114 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package googlehttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// ElidedItemsKey is the key of the marker that ElideArrays appends to the
+// arrays it shortens, whose value is the number of items that were dropped.
+const ElidedItemsKey = "elidedItems"
+
+// elidedArray is an array of a document, see ElideArrays.
+type elidedArray struct {
+	// kept are the items of the array that are kept.
+	kept []any
+	// dropped is the number of items that were dropped.
+	dropped int
+	// set replaces the array in the document.
+	set func(v any)
+}
+
+// ElideArrays returns v, a value that encodes to JSON, shortened so that its
+// JSON encoding is at most maxBytes bytes. The fields of objects are kept,
+// and the largest arrays lose their last items first, ending with an object
+// with the number of dropped items under ElidedItemsKey, e.g.
+// {"elidedItems": 42}. It returns v if it is not larger, and an error if it
+// is still larger without the items of its arrays.
+func ElideArrays(v any, maxBytes int) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode response: %w", err)
+	}
+	if len(b) <= maxBytes {
+		return v, nil
+	}
+	var doc any
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	root := &doc
+	for len(b) > maxBytes {
+		var largest *elidedArray
+		largestSize := 0
+		for _, a := range arrays(*root, func(v any) { *root = v }) {
+			if len(a.kept) == 0 {
+				continue
+			}
+			ab, err := json.Marshal(a.kept)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode response: %w", err)
+			}
+			if len(ab) > largestSize {
+				largest, largestSize = a, len(ab)
+			}
+		}
+		if largest == nil {
+			return nil, fmt.Errorf("the response is larger than %d bytes even without the items of its arrays", maxBytes)
+		}
+		// halving the items of the largest array converges in few
+		// iterations on documents with long arrays
+		keep := len(largest.kept) / 2
+		items := append(largest.kept[:keep:keep], map[string]any{ElidedItemsKey: largest.dropped + len(largest.kept) - keep})
+		largest.set(items)
+		if b, err = json.Marshal(*root); err != nil {
+			return nil, fmt.Errorf("failed to encode response: %w", err)
+		}
+	}
+	return *root, nil
+}
+
+// arrays returns the arrays of v, a decoded JSON value, with set replacing v
+// in its parent. The fields of objects are visited in order, so that arrays
+// of the same size are elided in the same order every time.
+func arrays(v any, set func(v any)) []*elidedArray {
+	var found []*elidedArray
+	switch v := v.(type) {
+	case map[string]any:
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			found = append(found, arrays(v[key], func(c any) { v[key] = c })...)
+		}
+	case []any:
+		a := &elidedArray{kept: v, set: set}
+		if n := len(v); n > 0 {
+			// the markers hold an int, which the numbers of decoded JSON
+			// are not
+			if marker, ok := v[n-1].(map[string]any); ok && len(marker) == 1 {
+				if dropped, ok := marker[ElidedItemsKey].(int); ok {
+					a.kept, a.dropped = v[:n-1], dropped
+				}
+			}
+		}
+		found = append(found, a)
+		for i, child := range a.kept {
+			found = append(found, arrays(child, func(c any) { v[i] = c })...)
+		}
+	}
+	return found
+}
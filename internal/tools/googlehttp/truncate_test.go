@@ -0,0 +1,71 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package googlehttp_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/tools/googlehttp"
+)
+
+func TestElideArrays(t *testing.T) {
+	items := func(n int) []any {
+		var v []any
+		for i := range n {
+			v = append(v, map[string]any{"id": float64(i), "name": strings.Repeat("x", 10)})
+		}
+		return v
+	}
+	doc := map[string]any{
+		"name":   "agent",
+		"tables": items(16),
+		"tags":   []any{"a", "b"},
+	}
+
+	got, err := googlehttp.ElideArrays(doc, 200)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	b, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("unable to encode result: %s", err)
+	}
+	if len(b) > 200 {
+		t.Errorf("got %d bytes, want at most 200: %s", len(b), b)
+	}
+	// the largest array loses its last items, the others are kept
+	want := map[string]any{
+		"name":   "agent",
+		"tables": append(items(4), map[string]any{googlehttp.ElidedItemsKey: 12}),
+		"tags":   []any{"a", "b"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected result (-want +got):\n%s", diff)
+	}
+	if len(doc["tables"].([]any)) != 16 {
+		t.Errorf("expected the document not to be modified")
+	}
+
+	if got, err := googlehttp.ElideArrays(doc, 1<<20); err != nil || !cmp.Equal(doc, got) {
+		t.Errorf("expected a small document to be returned as is, got %v, %v", got, err)
+	}
+
+	if _, err := googlehttp.ElideArrays(map[string]any{"name": strings.Repeat("x", 100), "tags": []any{"a"}}, 50); err == nil || !strings.Contains(err.Error(), "even without the items of its arrays") {
+		t.Errorf("expected an error for a document too large without its arrays, got %v", err)
+	}
+}
@@ -61,10 +61,10 @@ type Config struct {
 	Source       string                `yaml:"source" validate:"required"`
 	Description  string                `yaml:"description" validate:"required"`
 	AuthRequired []string              `yaml:"authRequired"`
-	Path         string                `yaml:"path" validate:"required"`
+	Path         string                `yaml:"path" validate:"required" expand:"true"`
 	Method       tools.HTTPMethod      `yaml:"method" validate:"required"`
-	Headers      map[string]string     `yaml:"headers"`
-	RequestBody  string                `yaml:"requestBody"`
+	Headers      map[string]string     `yaml:"headers" expand:"true"`
+	RequestBody  string                `yaml:"requestBody" expand:"true"`
 	PathParams   parameters.Parameters `yaml:"pathParams"`
 	QueryParams  parameters.Parameters `yaml:"queryParams"`
 	BodyParams   parameters.Parameters `yaml:"bodyParams"`
@@ -114,7 +114,7 @@ func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error)
 	}
 
 	// Create MCP manifest
-	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, allParameters, nil)
+	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, allParameters, nil, nil)
 
 	// finish tool setup
 	return Tool{
@@ -86,7 +86,7 @@ func TestFailParseFromYamlLookerHealthVacuum(t *testing.T) {
 			source: my-instance
 			invalid_field: true
 			`,
-			err: "unable to parse tool \"example_tool\" as type \"looker-health-vacuum\": [2:1] unknown field \"invalid_field\"",
+			err: "unable to parse tool \"example_tool\" as type \"looker-health-vacuum\": field \"invalid_field\" (line 6): unknown field \"invalid_field\"",
 		},
 	}
 	for _, tc := range tcs {
@@ -89,7 +89,7 @@ func TestFailParseFromYamlLookerRunDashboard(t *testing.T) {
             method: GOT
             description: some description
 			`,
-			err: "error unmarshaling tools: unable to parse tool \"example_tool\" as type \"looker-run-dashboard\": [3:1] unknown field \"method\"\n   1 | authRequired: []\n   2 | description: some description\n>  3 | method: GOT\n       ^\n   4 | name: example_tool\n   5 | source: my-instance\n   6 | type: looker-run-dashboard",
+			err: "error unmarshaling tools: unable to parse tool \"example_tool\" as type \"looker-run-dashboard\": field \"method\" (line 6): unknown field \"method\"",
 		},
 	}
 	for _, tc := range tcs {
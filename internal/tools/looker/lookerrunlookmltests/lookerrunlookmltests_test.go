@@ -89,7 +89,7 @@ func TestFailParseFromYamlLookerRunLookmlTests(t *testing.T) {
             method: GOT
             description: some description
 			`,
-			err: "error unmarshaling tools: unable to parse tool \"example_tool\" as type \"looker-run-lookml-tests\": [3:1] unknown field \"method\"",
+			err: "error unmarshaling tools: unable to parse tool \"example_tool\" as type \"looker-run-lookml-tests\": field \"method\" (line 6): unknown field \"method\"",
 		},
 	}
 	for _, tc := range tcs {
@@ -0,0 +1,487 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"time"
+
+	yaml "github.com/goccy/go-yaml"
+	"github.com/googleapis/genai-toolbox/internal/log"
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	"github.com/googleapis/genai-toolbox/internal/telemetry"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CommonOptions are the options available to every tool type. They are set by
+// fields that are removed from a tool's config before it is decoded for the
+// tool's type.
+type CommonOptions struct {
+	// Timeout bounds the invocations of the tool, if positive.
+	Timeout time.Duration
+	// AuthRequiredMode is how the auth services in authRequired must verify
+	// invocations. Defaults to AuthRequiredAny.
+	AuthRequiredMode AuthRequiredMode
+	// AuthTokenHeader is the header client access tokens are read from,
+	// instead of the tool's default.
+	AuthTokenHeader string
+	// AuthTokenScheme is the scheme of client access tokens, one of
+	// AuthTokenSchemeBearer, AuthTokenSchemeRaw, or a custom prefix. Defaults
+	// to AuthTokenSchemeBearer.
+	AuthTokenScheme string
+	// LogLevel is the minimum level logged during the invocations of the
+	// tool, instead of the server's, e.g. "DEBUG".
+	LogLevel string
+	// Sources lists the sources each invocation may select with the source
+	// parameter, including the tool's configured source, which is the
+	// default. Tools with a single source leave it empty.
+	Sources []string
+}
+
+// CommonConfig is a ToolConfig with the options available to every tool type.
+type CommonConfig struct {
+	ToolConfig
+	CommonOptions
+}
+
+var _ ToolConfigWithContext = CommonConfig{}
+
+// WithCommonOptions returns a ToolConfig whose tools use opts.
+func WithCommonOptions(cfg ToolConfig, opts CommonOptions) ToolConfig {
+	return CommonConfig{ToolConfig: cfg, CommonOptions: opts}
+}
+
+// MarshalYAML marshals the config of the tool's type with the fields of the
+// options that are set, so that the config decodes to c again.
+func (c CommonConfig) MarshalYAML() (any, error) {
+	b, err := yaml.Marshal(c.ToolConfig)
+	if err != nil {
+		return nil, err
+	}
+	var fields yaml.MapSlice
+	if err := yaml.UnmarshalWithOptions(b, &fields, yaml.UseOrderedMap()); err != nil {
+		return nil, err
+	}
+	if c.Timeout > 0 {
+		fields = append(fields, yaml.MapItem{Key: "timeout", Value: c.Timeout.String()})
+	}
+	if c.AuthRequiredMode != "" {
+		fields = append(fields, yaml.MapItem{Key: "authRequiredMode", Value: string(c.AuthRequiredMode)})
+	}
+	if c.AuthTokenHeader != "" {
+		fields = append(fields, yaml.MapItem{Key: "authTokenHeader", Value: c.AuthTokenHeader})
+	}
+	if c.AuthTokenScheme != "" {
+		fields = append(fields, yaml.MapItem{Key: "authTokenScheme", Value: c.AuthTokenScheme})
+	}
+	if c.LogLevel != "" {
+		fields = append(fields, yaml.MapItem{Key: "logLevel", Value: c.LogLevel})
+	}
+	if len(c.Sources) > 0 {
+		fields = append(fields, yaml.MapItem{Key: "sources", Value: c.Sources})
+	}
+	return fields, nil
+}
+
+func (c CommonConfig) Initialize(srcs map[string]sources.Source) (Tool, error) {
+	initialize := func(cfg ToolConfig) (Tool, error) { return cfg.Initialize(srcs) }
+	t, err := initialize(c.ToolConfig)
+	if err != nil {
+		return nil, err
+	}
+	return c.newTool(t, srcs, initialize)
+}
+
+func (c CommonConfig) InitializeWithContext(ctx context.Context, srcs map[string]sources.Source) (Tool, error) {
+	if _, ok := c.ToolConfig.(ToolConfigWithContext); !ok {
+		return c.Initialize(srcs)
+	}
+	initialize := func(cfg ToolConfig) (Tool, error) {
+		return cfg.(ToolConfigWithContext).InitializeWithContext(ctx, srcs)
+	}
+	t, err := initialize(c.ToolConfig)
+	if err != nil {
+		return nil, err
+	}
+	return c.newTool(t, srcs, initialize)
+}
+
+// newTool wraps the tool t, initialized with srcs, to use the options. Tools
+// with several sources are initialized again with each of the others.
+func (c CommonConfig) newTool(t Tool, srcs map[string]sources.Source, initialize func(ToolConfig) (Tool, error)) (Tool, error) {
+	tool := commonTool{Tool: t, opts: c.CommonOptions}
+	if len(c.Sources) > 0 {
+		bySource, err := tool.initializeSources(t.McpManifest().Name, c.ToolConfig, srcs, initialize)
+		if err != nil {
+			return nil, err
+		}
+		tool.bySource = bySource
+	}
+	return tool, nil
+}
+
+type commonTool struct {
+	Tool
+	opts CommonOptions
+	// bySource holds the tool initialized with each of the sources of
+	// opts.Sources, if it has several.
+	bySource map[string]Tool
+}
+
+func (t commonTool) ToConfig() ToolConfig {
+	return CommonConfig{ToolConfig: t.Tool.ToConfig(), CommonOptions: t.opts}
+}
+
+func (t commonTool) Invoke(ctx context.Context, resourceMgr SourceProvider, params parameters.ParamValues, accessToken AccessToken) (any, util.ToolboxError) {
+	accessToken, err := t.bearerToken(accessToken)
+	if err != nil {
+		return nil, err
+	}
+	tool, params, err := t.selectSource(params)
+	if err != nil {
+		return nil, err
+	}
+	return tool.Invoke(ctx, resourceMgr, params, accessToken)
+}
+
+// Plan plans the invocation with the tool's type, if it is a PlannableTool.
+// Use SupportsPlanning to know if it is.
+func (t commonTool) Plan(ctx context.Context, resourceMgr SourceProvider, params parameters.ParamValues, accessToken AccessToken) (any, util.ToolboxError) {
+	if _, ok := t.Tool.(PlannableTool); !ok {
+		return nil, util.NewClientServerError("tool does not support planning", http.StatusBadRequest, nil)
+	}
+	accessToken, err := t.bearerToken(accessToken)
+	if err != nil {
+		return nil, err
+	}
+	tool, params, err := t.selectSource(params)
+	if err != nil {
+		return nil, err
+	}
+	return tool.(PlannableTool).Plan(ctx, resourceMgr, params, accessToken)
+}
+
+// bearerToken returns accessToken as a bearer token, as tools parse client
+// access tokens as bearer tokens, if the tool sets another scheme.
+func (t commonTool) bearerToken(accessToken AccessToken) (AccessToken, util.ToolboxError) {
+	if accessToken == "" || t.opts.AuthTokenScheme == "" {
+		return accessToken, nil
+	}
+	token, err := accessToken.ParseToken(t.opts.AuthTokenScheme)
+	if err != nil {
+		return "", err
+	}
+	return AccessToken("Bearer " + token), nil
+}
+
+func (t commonTool) GetAuthTokenHeaderName(resourceMgr SourceProvider) (string, error) {
+	if t.opts.AuthTokenHeader != "" {
+		return t.opts.AuthTokenHeader, nil
+	}
+	return t.Tool.GetAuthTokenHeaderName(resourceMgr)
+}
+
+func (t commonTool) Authorized(verifiedAuthServices []string) bool {
+	if t.opts.AuthRequiredMode != AuthRequiredAll {
+		return t.Tool.Authorized(verifiedAuthServices)
+	}
+	return IsAuthorizedWithMode(AuthRequiredAll, t.Tool.Manifest().AuthRequired, verifiedAuthServices)
+}
+
+func (t commonTool) Manifest() Manifest {
+	m := t.Tool.Manifest()
+	if t.opts.AuthRequiredMode == AuthRequiredAll {
+		m.AuthRequiredMode = AuthRequiredAll
+	}
+	if p := t.sourceParameter(); p != nil {
+		m.Parameters = append(slices.Clone(m.Parameters), p.Manifest())
+	}
+	return m
+}
+
+// GetParameters returns the parameters of the tool, followed by the source
+// parameter if the tool has several sources.
+func (t commonTool) GetParameters() parameters.Parameters {
+	params := t.Tool.GetParameters()
+	if p := t.sourceParameter(); p != nil {
+		params = append(slices.Clone(params), p)
+	}
+	return params
+}
+
+// sourceParameter returns the parameter selecting the source of the
+// invocations, if the tool has several sources.
+func (t commonTool) sourceParameter() *parameters.StringParameter {
+	if len(t.opts.Sources) == 0 {
+		return nil
+	}
+	configured, _ := SourceName(t.Tool.ToConfig())
+	return sourceParameter(t.opts.Sources, configured)
+}
+
+func (t commonTool) McpManifest() McpManifest {
+	m := t.Tool.McpManifest()
+	if t.opts.AuthRequiredMode == AuthRequiredAll && len(t.Tool.Manifest().AuthRequired) > 0 {
+		// copy the metadata, so that the tool's manifest is not modified
+		metadata := maps.Clone(m.Metadata)
+		if metadata == nil {
+			metadata = make(map[string]any)
+		}
+		metadata["toolbox/authInvokeMode"] = AuthRequiredAll
+		m.Metadata = metadata
+	}
+	if p := t.sourceParameter(); p != nil {
+		// copy the properties, so that the tool's manifest is not modified
+		properties := maps.Clone(m.InputSchema.Properties)
+		if properties == nil {
+			properties = make(map[string]parameters.ParameterMcpManifest)
+		}
+		properties[SourceParameterName], _ = p.McpManifest()
+		m.InputSchema.Properties = properties
+	}
+	return m
+}
+
+// GetCommonOptions returns the options available to every tool type that
+// tool uses.
+func GetCommonOptions(tool Tool) CommonOptions {
+	if t, ok := tool.(commonTool); ok {
+		return t.opts
+	}
+	return CommonOptions{}
+}
+
+// WithToolLogLevel returns ctx with its logger logging at the log level of
+// tool, if it sets one, for the invocations of the tool.
+func WithToolLogLevel(ctx context.Context, tool Tool) context.Context {
+	logLevel := GetCommonOptions(tool).LogLevel
+	if logLevel == "" {
+		return ctx
+	}
+	level, err := log.SeverityToLevel(logLevel)
+	if err != nil {
+		// the level is validated when the config is loaded
+		return ctx
+	}
+	return util.WithLogLevel(ctx, level)
+}
+
+// InvokeWithTimeout invokes the tool named toolName, bounding the invocation
+// by the tool's timeout, if any. An invocation that exceeds the timeout
+// returns an error naming the tool and the timeout.
+//
+// If the tool's source limits its concurrent invocations, the invocation
+// first waits for a slot of the source, and returns a rate_limited error if
+// none frees up within the source's invocation queue timeout.
+//
+// The invocation is traced with a span named after the tool, see
+// startInvocationSpan, which is the parent of the spans of the backend calls
+// of the tool.
+func InvokeWithTimeout(ctx context.Context, toolName string, tool Tool, resourceMgr SourceProvider, params parameters.ParamValues, accessToken AccessToken) (res any, err util.ToolboxError) {
+	ctx, end := startInvocationSpan(ctx, InvocationModeInvoke, toolName, tool, resourceMgr, params)
+	defer func() { end(err) }()
+	release, err := acquireSourceSlot(ctx, toolName, tool, resourceMgr, params)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return withTimeout(ctx, toolName, tool, func(ctx context.Context) (any, util.ToolboxError) {
+		return tool.Invoke(ctx, resourceMgr, params, accessToken)
+	})
+}
+
+const (
+	// InvocationModeInvoke is the invocation_mode attribute of the spans of
+	// invocations.
+	InvocationModeInvoke = "invoke"
+	// InvocationModePlan is the invocation_mode attribute of the spans of
+	// planned invocations.
+	InvocationModePlan = "plan"
+)
+
+// InvocationSpanName returns the name of the span of the invocations of the
+// tool named toolName.
+func InvocationSpanName(toolName string) string {
+	return "toolbox/tool/" + toolName
+}
+
+// startInvocationSpan starts the span of an invocation of the tool named
+// toolName, with attributes identifying the tool, its source and its auth
+// mode, and the number of parameters of the invocation, but not their values.
+// The returned func ends the span with the outcome of the invocation, and the
+// error code if it failed. The latency is the duration of the span.
+func startInvocationSpan(ctx context.Context, mode, toolName string, tool Tool, resourceMgr SourceProvider, params parameters.ParamValues) (context.Context, func(util.ToolboxError)) {
+	attrs := []attribute.KeyValue{
+		attribute.String("tool_name", toolName),
+		attribute.String("invocation_mode", mode),
+		attribute.String("auth_mode", authMode(tool, resourceMgr)),
+		attribute.Int("param_count", len(params)),
+	}
+	cfg := tool.ToConfig()
+	if cc, ok := cfg.(CommonConfig); ok {
+		cfg = cc.ToolConfig
+	}
+	if cfg != nil {
+		attrs = append(attrs, attribute.String("tool_type", cfg.ToolConfigType()))
+	}
+	if sourceName, ok := selectedSourceName(tool, params); ok {
+		attrs = append(attrs, attribute.String("source_name", sourceName))
+	}
+	ctx, span := telemetry.Tracer().Start(ctx, InvocationSpanName(toolName), trace.WithAttributes(attrs...))
+	return ctx, func(err util.ToolboxError) {
+		defer span.End()
+		if err == nil {
+			span.SetAttributes(attribute.String("outcome", "success"))
+			return
+		}
+		info := err.ErrorInfo()
+		span.SetAttributes(
+			attribute.String("outcome", "error"),
+			attribute.String("error_category", string(err.Category())),
+			attribute.String("error_code", string(info.Code)),
+			attribute.Bool("error_retryable", info.Retryable),
+		)
+		span.SetStatus(codes.Error, string(info.Code))
+	}
+}
+
+// authMode returns how the invocations of tool are authorized: "client" for
+// tools using the client's credentials, the auth required mode for tools
+// requiring auth services, or "none".
+func authMode(tool Tool, resourceMgr SourceProvider) string {
+	if resourceMgr != nil {
+		if clientAuth, err := tool.RequiresClientAuthorization(resourceMgr); err == nil && clientAuth {
+			return "client"
+		}
+	}
+	if len(tool.Manifest().AuthRequired) == 0 {
+		return "none"
+	}
+	if mode := GetCommonOptions(tool).AuthRequiredMode; mode != "" {
+		return "auth_required_" + string(mode)
+	}
+	return "auth_required_" + string(AuthRequiredAny)
+}
+
+// SourceLimiterProvider is implemented by SourceProviders whose sources may
+// limit the concurrent invocations of their tools.
+type SourceLimiterProvider interface {
+	// GetSourceLimiter returns the limiter of the source named sourceName, if
+	// it limits its concurrent invocations.
+	GetSourceLimiter(sourceName string) (*sources.Limiter, bool)
+}
+
+// SourceName returns the name of the source the tool of cfg is bound to, from
+// the `Source` field of its config. It returns false for configs without one,
+// such as tools that use several sources.
+func SourceName(cfg ToolConfig) (string, bool) {
+	if cc, ok := cfg.(CommonConfig); ok {
+		cfg = cc.ToolConfig
+	}
+	v := reflect.Indirect(reflect.ValueOf(cfg))
+	if v.Kind() != reflect.Struct {
+		return "", false
+	}
+	f := v.FieldByName("Source")
+	if !f.IsValid() || f.Kind() != reflect.String {
+		return "", false
+	}
+	return f.String(), true
+}
+
+// acquireSourceSlot waits for a slot of the source of the invocation of tool
+// with params, if resourceMgr limits its concurrent invocations, and returns
+// the func releasing it.
+func acquireSourceSlot(ctx context.Context, toolName string, tool Tool, resourceMgr SourceProvider, params parameters.ParamValues) (func(), util.ToolboxError) {
+	noop := func() {}
+	provider, ok := resourceMgr.(SourceLimiterProvider)
+	if !ok {
+		return noop, nil
+	}
+	sourceName, ok := selectedSourceName(tool, params)
+	if !ok {
+		return noop, nil
+	}
+	limiter, ok := provider.GetSourceLimiter(sourceName)
+	if !ok {
+		return noop, nil
+	}
+	release, err := limiter.Acquire(ctx)
+	if errors.Is(err, sources.ErrInvocationQueueTimeout) {
+		return nil, util.NewAgentError(fmt.Sprintf("tool %q is rate limited, try again later", toolName), err).WithCode(util.ErrorCodeRateLimited)
+	}
+	if err != nil {
+		return nil, util.NewAgentError(fmt.Sprintf("tool %q was canceled while waiting for its source", toolName), err)
+	}
+	return release, nil
+}
+
+// SupportsPlanning returns whether tool is a PlannableTool, whether or not it
+// uses the options available to every tool type.
+func SupportsPlanning(tool Tool) bool {
+	_, ok := BaseTool(tool).(PlannableTool)
+	return ok
+}
+
+// BaseTool returns the tool of the tool's type, without the common options
+// wrapping it, so that the interfaces of its type can be checked.
+func BaseTool(tool Tool) Tool {
+	if t, ok := tool.(commonTool); ok {
+		return t.Tool
+	}
+	return tool
+}
+
+// PlanWithTimeout plans the invocation of the tool named toolName, bounding
+// it by the tool's timeout, if any, like InvokeWithTimeout. Tools that do not
+// support planning return an error.
+func PlanWithTimeout(ctx context.Context, toolName string, tool Tool, resourceMgr SourceProvider, params parameters.ParamValues, accessToken AccessToken) (res any, err util.ToolboxError) {
+	if !SupportsPlanning(tool) {
+		return nil, util.NewClientServerError(fmt.Sprintf("tool %q does not support planning", toolName), http.StatusBadRequest, nil)
+	}
+	ctx, end := startInvocationSpan(ctx, InvocationModePlan, toolName, tool, resourceMgr, params)
+	defer func() { end(err) }()
+	planner := tool.(PlannableTool)
+	return withTimeout(ctx, toolName, tool, func(ctx context.Context) (any, util.ToolboxError) {
+		return planner.Plan(ctx, resourceMgr, params, accessToken)
+	})
+}
+
+func withTimeout(ctx context.Context, toolName string, tool Tool, call func(context.Context) (any, util.ToolboxError)) (any, util.ToolboxError) {
+	timeout := GetCommonOptions(tool).Timeout
+	if timeout <= 0 {
+		return call(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	res, err := call(ctx)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, util.NewAgentError(fmt.Sprintf("tool %q timed out after %s", toolName, timeout), err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
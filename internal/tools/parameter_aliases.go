@@ -0,0 +1,59 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+)
+
+// ParameterAliases maps other names to the parameters of a tool whose values
+// are accepted under them, e.g. the former names of renamed parameters, so
+// that agents prompted with them keep working. Tools that support it set it
+// from the parameterAliases field of their config. The aliases are not shown
+// in the manifests of the tool.
+type ParameterAliases map[string]string
+
+// Apply adds the aliases of a to params, the parameters of the tool toolName.
+// It returns an error if an alias is the name of a parameter, or names a
+// parameter that is not one of params.
+func (a ParameterAliases) Apply(toolName string, params parameters.Parameters) error {
+	names := make([]string, 0, len(params))
+	for _, p := range params {
+		names = append(names, p.GetName())
+	}
+	aliases := make([]string, 0, len(a))
+	for alias := range a {
+		aliases = append(aliases, alias)
+	}
+	slices.Sort(aliases)
+	for _, alias := range aliases {
+		name := a[alias]
+		if slices.Contains(names, alias) {
+			return fmt.Errorf("invalid parameterAliases of tool %q: %q is the name of a parameter", toolName, alias)
+		}
+		i := slices.Index(names, name)
+		if i < 0 {
+			return fmt.Errorf("invalid parameterAliases of tool %q: alias %q of unknown parameter %q, the parameters are %s", toolName, alias, name, strings.Join(names, ", "))
+		}
+		if !parameters.AddAliases(params[i], alias) {
+			return fmt.Errorf("invalid parameterAliases of tool %q: parameter %q does not accept aliases", toolName, name)
+		}
+	}
+	return nil
+}
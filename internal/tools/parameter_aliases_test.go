@@ -0,0 +1,59 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+)
+
+func TestParameterAliases(t *testing.T) {
+	newParams := func() parameters.Parameters {
+		return parameters.Parameters{
+			parameters.NewStringParameter("project", "The project."),
+			parameters.NewStringParameter("dataset", "The dataset."),
+		}
+	}
+
+	params := newParams()
+	a := tools.ParameterAliases{"project_id": "project", "dataset_id": "dataset", "datasetId": "dataset"}
+	if err := a.Apply("my-tool", params); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	got, err := parameters.ParseParams(params, map[string]any{"project_id": "p", "datasetId": "d"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diff := cmp.Diff(map[string]any{"project": "p", "dataset": "d"}, got.AsMap()); diff != "" {
+		t.Fatalf("unexpected values (-want +got):\n%s", diff)
+	}
+
+	for _, tc := range []struct {
+		aliases tools.ParameterAliases
+		want    string
+	}{
+		{tools.ParameterAliases{"table_id": "table"}, `invalid parameterAliases of tool "my-tool": alias "table_id" of unknown parameter "table", the parameters are project, dataset`},
+		{tools.ParameterAliases{"dataset": "project"}, `invalid parameterAliases of tool "my-tool": "dataset" is the name of a parameter`},
+	} {
+		err := tc.aliases.Apply("my-tool", newParams())
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("got error %v, want %q", err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+)
+
+// ParameterDescriptions overrides the descriptions of the parameters a tool
+// generates, by parameter name. Tools that support it set it from the
+// parameterDescriptions field of their config.
+type ParameterDescriptions map[string]string
+
+// Describe returns the description of the parameter name: its override, if
+// any, otherwise desc. Tools describe their generated parameters with it
+// before adding the constraints of the source, such as the allowed values, so
+// that overrides keep them.
+func (d ParameterDescriptions) Describe(name, desc string) string {
+	if override, ok := d[name]; ok {
+		return override
+	}
+	return desc
+}
+
+// Validate returns an error if d overrides the description of a parameter
+// that is not one of params, the parameters of the tool toolName.
+func (d ParameterDescriptions) Validate(toolName string, params parameters.Parameters) error {
+	names := make([]string, 0, len(params))
+	for _, p := range params {
+		names = append(names, p.GetName())
+	}
+	var unknown []string
+	for name := range d {
+		if !slices.Contains(names, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		slices.Sort(unknown)
+		return fmt.Errorf("invalid parameterDescriptions of tool %q: no parameters named %s, the parameters are %s", toolName, strings.Join(unknown, ", "), strings.Join(names, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+)
+
+func TestParameterDescriptions(t *testing.T) {
+	d := tools.ParameterDescriptions{"dataset": "Le jeu de données."}
+	if got := d.Describe("dataset", "The dataset."); got != "Le jeu de données." {
+		t.Fatalf("got %q, want the override", got)
+	}
+	if got := d.Describe("project", "The project."); got != "The project." {
+		t.Fatalf("got %q, want the built-in description", got)
+	}
+	if got := tools.ParameterDescriptions(nil).Describe("project", "The project."); got != "The project." {
+		t.Fatalf("got %q, want the built-in description", got)
+	}
+
+	params := parameters.Parameters{
+		parameters.NewStringParameter("project", "The project."),
+		parameters.NewStringParameter("dataset", "The dataset."),
+	}
+	if err := d.Validate("my-tool", params); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	d["table"], d["column"] = "La table.", "La colonne."
+	err := d.Validate("my-tool", params)
+	if err == nil || !strings.Contains(err.Error(), `invalid parameterDescriptions of tool "my-tool": no parameters named column, table, the parameters are project, dataset`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
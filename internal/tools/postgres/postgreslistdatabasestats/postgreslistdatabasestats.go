@@ -162,7 +162,7 @@ func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error)
 				"number of active connections to the database, the timestamp of the " +
 				"last statistics reset, and total database size in bytes."
 	}
-	mcpManifest := tools.GetMcpManifest(cfg.Name, description, cfg.AuthRequired, allParameters, nil)
+	mcpManifest := tools.GetMcpManifest(cfg.Name, description, cfg.AuthRequired, allParameters, nil, nil)
 
 	return Tool{
 		Config:    cfg,
@@ -132,7 +132,7 @@ func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error)
         for the last_vacuum, last_autovacuum, and last_autoanalyze operations.`
 	}
 
-	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, allParameters, nil)
+	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, allParameters, nil, nil)
 
 	return Tool{
 		Config:    cfg,
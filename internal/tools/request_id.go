@@ -0,0 +1,62 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader is the header that holds the ID of a tool invocation. Clients
+// can set it to choose the ID, and the HTTP API returns it in responses.
+const RequestIDHeader = "X-Toolbox-Request-Id"
+
+// maxRequestIDLength is the maximum length of a request ID set by a client.
+const maxRequestIDLength = 128
+
+// WithRequestID returns ctx with the ID of the tool invocation of a request
+// with header, and the ID. The ID is the one the client set in the
+// RequestIDHeader if it is valid, and a new one otherwise. It is also added as
+// an attribute of the span of ctx.
+func WithRequestID(ctx context.Context, header http.Header) (context.Context, string) {
+	requestID := header.Get(RequestIDHeader)
+	if !validRequestID(requestID) {
+		requestID = uuid.New().String()
+	}
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("toolbox.request_id", requestID))
+	return util.WithRequestID(ctx, requestID), requestID
+}
+
+// validRequestID reports whether id is non-empty, and short and plain enough
+// to be logged and returned as is.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
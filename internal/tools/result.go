@@ -0,0 +1,58 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+// IncludeMetadataHeader is the header, set to "true", that requests the
+// execution metadata of a tool result through the HTTP API.
+const IncludeMetadataHeader = "X-Toolbox-Include-Metadata"
+
+// IncludeMetadataMetaKey is the key, set to true, in the `_meta` of an MCP
+// tools/call request that requests the execution metadata of the result.
+const IncludeMetadataMetaKey = "toolbox/includeMetadata"
+
+// ErrorInfoMetaKey is the key in the `_meta` of an MCP tool execution error
+// that holds the util.ErrorInfo of the error, if metadata was requested.
+const ErrorInfoMetaKey = "toolbox/error"
+
+// Result is an envelope that tools can return from Invoke to attach execution
+// metadata, such as a job ID or retry counts, to the data they return. The
+// server sends the metadata separately from the data, and only to clients
+// that request it, so that the data is the same for all clients.
+type Result struct {
+	Data     any
+	Metadata map[string]any
+	// IncludeMetadata sends the metadata even if the client did not request
+	// it, e.g. because the tool config asks for it.
+	IncludeMetadata bool
+}
+
+// NewResult returns a Result holding data and its metadata.
+func NewResult(data any, metadata map[string]any) Result {
+	return Result{Data: data, Metadata: metadata}
+}
+
+// UnwrapResult returns the data of a tool result, and the metadata to send
+// with it. The metadata is nil unless res is a Result and either include is
+// true or the Result always includes its metadata.
+func UnwrapResult(res any, include bool) (any, map[string]any) {
+	r, ok := res.(Result)
+	if !ok {
+		return res, nil
+	}
+	if !include && !r.IncludeMetadata {
+		return r.Data, nil
+	}
+	return r.Data, r.Metadata
+}
@@ -46,7 +46,7 @@ func newConfig(ctx context.Context, name string, decoder *yaml.Decoder) (tools.T
 
 type compatibleSource interface {
 	GetBatchControllerClient() *dataproc.BatchControllerClient
-	ListBatches(context.Context, *int, string, string) (any, error)
+	ListBatches(context.Context, parameters.PageRequest, string) (any, error)
 }
 
 type Config struct {
@@ -72,11 +72,9 @@ func (cfg Config) Initialize(srcs map[string]sources.Source) (tools.Tool, error)
 		desc = "Lists available Serverless Spark (aka Dataproc Serverless) batches"
 	}
 
-	allParameters := parameters.Parameters{
+	allParameters := append(parameters.Parameters{
 		parameters.NewStringParameterWithRequired("filter", `Filter expression to limit the batches. Filters are case sensitive, and may contain multiple clauses combined with logical operators (AND/OR, case sensitive). Supported fields are batch_id, batch_uuid, state, create_time, and labels. e.g. state = RUNNING AND create_time < "2023-01-01T00:00:00Z" filters for batches in state RUNNING that were created before 2023-01-01. state = RUNNING AND labels.environment=production filters for batches in state in a RUNNING state that have a production environment label. Valid states are STATE_UNSPECIFIED, PENDING, RUNNING, CANCELLING, CANCELLED, SUCCEEDED, FAILED. Valid operators are < > <= >= = !=, and : as "has" for labels, meaning any non-empty value)`, false),
-		parameters.NewIntParameterWithDefault("pageSize", 20, "The maximum number of batches to return in a single page (default 20)"),
-		parameters.NewStringParameterWithRequired("pageToken", "A page token, received from a previous `ListBatches` call", false),
-	}
+	}, parameters.NewPaginationParameters("batches", 20)...)
 	inputSchema, _ := allParameters.McpManifest()
 
 	mcpManifest := tools.McpManifest{
@@ -108,29 +106,13 @@ func (t Tool) Invoke(ctx context.Context, resourceMgr tools.SourceProvider, para
 		return nil, util.NewClientServerError("source used is not compatible with the tool", http.StatusInternalServerError, err)
 	}
 
-	paramMap := params.AsMap()
-	var pageSize *int
-	if ps, ok := paramMap["pageSize"]; ok && ps != nil {
-		pageSizeV, ok := ps.(int)
-		if !ok {
-			// Handle float64 case if unmarshaled from JSON usually
-			if f, ok := ps.(float64); ok {
-				pageSizeV = int(f)
-			} else {
-				return nil, util.NewAgentError("pageSize must be an integer", nil)
-			}
-		}
-
-		if pageSizeV <= 0 {
-			return nil, util.NewAgentError(fmt.Sprintf("pageSize must be positive: %d", pageSizeV), nil)
-		}
-		pageSize = &pageSizeV
+	page, err := parameters.ParsePageRequest(params)
+	if err != nil {
+		return nil, util.NewAgentError(err.Error(), err)
 	}
+	filter, _ := params.AsMap()["filter"].(string)
 
-	pt, _ := paramMap["pageToken"].(string)
-	filter, _ := paramMap["filter"].(string)
-
-	resp, err := source.ListBatches(ctx, pageSize, pt, filter)
+	resp, err := source.ListBatches(ctx, page, filter)
 	if err != nil {
 		return nil, util.ProcessGcpError(err)
 	}
@@ -0,0 +1,173 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+)
+
+// SourceParameterName is the name of the parameter that selects the source
+// of an invocation of a tool with several sources, see CommonOptions.Sources.
+const SourceParameterName = "source"
+
+// sourceParameter returns the parameter selecting one of srcs, defaulting to
+// the tool's configured source.
+func sourceParameter(srcs []string, configured string) *parameters.StringParameter {
+	p := parameters.NewStringParameterWithDefault(SourceParameterName, configured, fmt.Sprintf("The source to run the tool against, one of: %s.", strings.Join(srcs, ", ")))
+	p.Enum = slices.Clone(srcs)
+	return p
+}
+
+// withSourceName returns a copy of cfg bound to the source named sourceName,
+// by setting the `Source` field read by SourceName.
+func withSourceName(cfg ToolConfig, sourceName string) (ToolConfig, bool) {
+	v := reflect.ValueOf(cfg)
+	isPointer := v.Kind() == reflect.Pointer
+	v = reflect.Indirect(v)
+	if v.Kind() != reflect.Struct {
+		return nil, false
+	}
+	c := reflect.New(v.Type())
+	c.Elem().Set(v)
+	f := c.Elem().FieldByName("Source")
+	if !f.IsValid() || f.Kind() != reflect.String || !f.CanSet() {
+		return nil, false
+	}
+	f.SetString(sourceName)
+	if !isPointer {
+		c = c.Elem()
+	}
+	copied, ok := c.Interface().(ToolConfig)
+	return copied, ok
+}
+
+// SourceNames returns the names of the sources the tool of cfg may run
+// against: the sources of its common options, if it has several, or else its
+// configured source. It returns false for configs without a `Source` field,
+// like SourceName.
+func SourceNames(cfg ToolConfig) ([]string, bool) {
+	name, ok := SourceName(cfg)
+	if !ok {
+		return nil, false
+	}
+	if cc, isCommon := cfg.(CommonConfig); isCommon && len(cc.Sources) > 0 {
+		return cc.Sources, true
+	}
+	return []string{name}, true
+}
+
+// sourceMap is a SourceProvider of the sources tools are initialized with.
+type sourceMap map[string]sources.Source
+
+func (m sourceMap) GetSource(sourceName string) (sources.Source, bool) {
+	s, ok := m[sourceName]
+	return s, ok
+}
+
+func (m sourceMap) GetSourcesMap() map[string]sources.Source {
+	return m
+}
+
+// selectedSourceName returns the name of the source of an invocation of tool
+// with params: the one selected by the source parameter, if the tool has
+// several sources, or else its configured source.
+func selectedSourceName(tool Tool, params parameters.ParamValues) (string, bool) {
+	configured, ok := SourceName(tool.ToConfig())
+	if !ok || len(GetCommonOptions(tool).Sources) == 0 {
+		return configured, ok
+	}
+	for _, p := range params {
+		if s, isString := p.Value.(string); p.Name == SourceParameterName && isString && s != "" {
+			return s, true
+		}
+	}
+	return configured, true
+}
+
+// initializeSources checks that the tool t, initialized from cfg with srcs,
+// is compatible with each of its sources, and that none of its own parameters
+// is the source parameter. It returns the tool initialized with each source,
+// so that the parameters of an invocation, and the values they allow, are the
+// ones of the source it selects.
+func (t commonTool) initializeSources(name string, cfg ToolConfig, srcs map[string]sources.Source, initialize func(ToolConfig) (Tool, error)) (map[string]Tool, error) {
+	configured, ok := SourceName(cfg)
+	if !ok {
+		return nil, fmt.Errorf("tool %q config error: 'sources' requires a tool type with a 'source'", name)
+	}
+	if !slices.Contains(t.opts.Sources, configured) {
+		return nil, fmt.Errorf("tool %q config error: 'source' %q must be one of 'sources' %q", name, configured, t.opts.Sources)
+	}
+	if slices.ContainsFunc(t.Tool.GetParameters(), func(p parameters.Parameter) bool { return p.GetName() == SourceParameterName }) {
+		return nil, fmt.Errorf("tool %q config error: 'sources' requires no parameter named %q", name, SourceParameterName)
+	}
+	bySource := map[string]Tool{configured: t.Tool}
+	for _, s := range t.opts.Sources {
+		if _, ok := srcs[s]; !ok {
+			return nil, fmt.Errorf("tool %q config error: source %q of 'sources' is not defined", name, s)
+		}
+		if _, ok := bySource[s]; ok {
+			continue
+		}
+		sourceCfg, ok := withSourceName(cfg, s)
+		if !ok {
+			return nil, fmt.Errorf("tool %q config error: 'sources' requires a tool type with a 'source'", name)
+		}
+		tool, err := initialize(sourceCfg)
+		if err != nil {
+			return nil, fmt.Errorf("tool %q config error: unable to initialize with source %q of 'sources': %w", name, s, err)
+		}
+		// tools check the compatibility of their source when they get it
+		if _, err := tool.RequiresClientAuthorization(sourceMap(srcs)); err != nil {
+			return nil, fmt.Errorf("tool %q config error: source %q of 'sources' is not compatible: %w", name, s, err)
+		}
+		bySource[s] = tool
+	}
+	return bySource, nil
+}
+
+// selectSource returns the tool and the parameters of an invocation with
+// params: if the tool has several sources, the tool initialized with the one
+// selected by the source parameter, which is removed from the parameters.
+func (t commonTool) selectSource(params parameters.ParamValues) (Tool, parameters.ParamValues, util.ToolboxError) {
+	if len(t.opts.Sources) == 0 {
+		return t.Tool, params, nil
+	}
+	selected, _ := selectedSourceName(t, params)
+	tool, ok := t.bySource[selected]
+	if !ok {
+		return nil, nil, util.NewAgentError(fmt.Sprintf("source %q is not allowed, it must be one of %q", selected, t.opts.Sources), nil)
+	}
+	params = slices.DeleteFunc(slices.Clone(params), func(p parameters.ParamValue) bool { return p.Name == SourceParameterName })
+	return tool, params, nil
+}
+
+// ParseParams parses the parameters of an invocation of tool from data. The
+// parameters of tools with several sources are the ones of the source that
+// data selects, so that their defaults and allowed values follow it.
+func ParseParams(tool Tool, data map[string]any, claimsMap map[string]map[string]any) (parameters.ParamValues, error) {
+	if t, ok := tool.(commonTool); ok && len(t.opts.Sources) > 0 {
+		if selected, ok := t.bySource[fmt.Sprint(data[SourceParameterName])]; ok {
+			return parameters.ParseParams(append(slices.Clone(selected.GetParameters()), t.sourceParameter()), data, claimsMap)
+		}
+	}
+	return parameters.ParseParams(tool.GetParameters(), data, claimsMap)
+}
@@ -0,0 +1,381 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools_test
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	yaml "github.com/goccy/go-yaml"
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/server"
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+)
+
+// teamSource is a queryableSource whose project is the team it belongs to.
+type teamSource struct {
+	team string
+}
+
+func (teamSource) SourceType() string                         { return "team" }
+func (teamSource) ToConfig() sources.SourceConfig             { return nil }
+func (teamSource) Query(context.Context, string) (any, error) { return nil, nil }
+func (s teamSource) ProjectID() string                        { return s.team }
+
+// selectTestConfig is the config of a tool that returns the project of its
+// source and the values of its parameters.
+type selectTestConfig struct {
+	Name         string                `yaml:"name"`
+	Type         string                `yaml:"type"`
+	Source       string                `yaml:"source"`
+	Description  string                `yaml:"description"`
+	Parameters   parameters.Parameters `yaml:"parameters"`
+	AuthRequired []string              `yaml:"authRequired"`
+	// ProjectParameter adds a parameter defaulting to the project of the
+	// source.
+	ProjectParameter bool `yaml:"projectParameter"`
+}
+
+func (c selectTestConfig) ToolConfigType() string { return "select-test-type" }
+func (c selectTestConfig) Initialize(srcs map[string]sources.Source) (tools.Tool, error) {
+	if c.ProjectParameter {
+		s, ok := srcs[c.Source].(teamSource)
+		if !ok {
+			return nil, fmt.Errorf("source %q is not a team source", c.Source)
+		}
+		c.Parameters = append(slices.Clone(c.Parameters), parameters.NewStringParameterWithDefault("project", s.team, "a project"))
+	}
+	return selectTestTool{Config: c}, nil
+}
+
+type selectTestTool struct {
+	tools.Tool
+	Config selectTestConfig
+}
+
+func (t selectTestTool) Invoke(_ context.Context, resourceMgr tools.SourceProvider, params parameters.ParamValues, _ tools.AccessToken) (any, util.ToolboxError) {
+	source, err := tools.GetCompatibleSource[queryableSource](resourceMgr, t.Config.Source, t.Config.Name, t.Config.Type)
+	if err != nil {
+		return nil, util.NewClientServerError("source used is not compatible with the tool", 500, err)
+	}
+	return fmt.Sprintf("%s %v", source.ProjectID(), params.AsMap()), nil
+}
+
+func (t selectTestTool) RequiresClientAuthorization(resourceMgr tools.SourceProvider) (bool, error) {
+	_, err := tools.GetCompatibleSource[queryableSource](resourceMgr, t.Config.Source, t.Config.Name, t.Config.Type)
+	return false, err
+}
+
+func (t selectTestTool) ToConfig() tools.ToolConfig { return t.Config }
+
+func (t selectTestTool) GetParameters() parameters.Parameters { return t.Config.Parameters }
+
+func (t selectTestTool) Manifest() tools.Manifest {
+	return tools.Manifest{Description: t.Config.Description, Parameters: t.Config.Parameters.Manifest()}
+}
+
+func (t selectTestTool) McpManifest() tools.McpManifest {
+	return tools.GetMcpManifest(t.Config.Name, t.Config.Description, nil, t.Config.Parameters, nil, nil)
+}
+
+var selectTestSources = map[string]sources.Source{
+	"team_a": teamSource{team: "a"},
+	"team_b": teamSource{team: "b"},
+	"plain":  plainSource{},
+}
+
+func newSelectTestConfig(source string, srcs ...string) tools.ToolConfig {
+	cfg := selectTestConfig{
+		Name:        "my_tool",
+		Type:        "select-test-type",
+		Source:      source,
+		Description: "some description",
+		Parameters:  parameters.Parameters{parameters.NewStringParameter("q", "a query")},
+	}
+	if len(srcs) == 0 {
+		return cfg
+	}
+	return tools.WithCommonOptions(cfg, tools.CommonOptions{Sources: srcs})
+}
+
+func TestSourceSelection(t *testing.T) {
+	tool, err := newSelectTestConfig("team_a", "team_a", "team_b").Initialize(selectTestSources)
+	if err != nil {
+		t.Fatalf("unable to initialize tool: %s", err)
+	}
+	provider := mapSourceProvider(selectTestSources)
+
+	tcs := []struct {
+		desc     string
+		args     map[string]any
+		want     string
+		wantErr  string
+		parseErr bool
+	}{
+		{
+			desc: "default source",
+			args: map[string]any{"q": "x"},
+			want: "a map[q:x]",
+		},
+		{
+			desc: "selected source",
+			args: map[string]any{"q": "x", "source": "team_b"},
+			want: "b map[q:x]",
+		},
+		{
+			desc:     "disallowed source",
+			args:     map[string]any{"q": "x", "source": "plain"},
+			parseErr: true,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			params, err := parameters.ParseParams(tool.GetParameters(), tc.args, nil)
+			if tc.parseErr {
+				if err == nil {
+					t.Fatalf("expected the source parameter to be invalid")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unable to parse params: %s", err)
+			}
+			got, tbErr := tools.InvokeWithTimeout(context.Background(), "my_tool", tool, provider, params, "")
+			if tbErr != nil {
+				t.Fatalf("unexpected error: %s", tbErr)
+			}
+			if got != tc.want {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	// sources that are not listed are refused even if the parameters were
+	// not validated against the manifest
+	params := parameters.ParamValues{{Name: "q", Value: "x"}, {Name: tools.SourceParameterName, Value: "plain"}}
+	_, tbErr := tools.InvokeWithTimeout(context.Background(), "my_tool", tool, provider, params, "")
+	if tbErr == nil || !strings.Contains(tbErr.Error(), `source "plain" is not allowed`) {
+		t.Fatalf("expected the source to be refused, got %v", tbErr)
+	}
+	if tbErr.Category() != util.CategoryAgent {
+		t.Fatalf("unexpected error category: %s", tbErr.Category())
+	}
+}
+
+func TestSourceSelectionParameters(t *testing.T) {
+	cfg := selectTestConfig{
+		Name:             "my_tool",
+		Type:             "select-test-type",
+		Source:           "team_a",
+		Parameters:       parameters.Parameters{parameters.NewStringParameter("q", "a query")},
+		ProjectParameter: true,
+	}
+	tool, err := tools.WithCommonOptions(cfg, tools.CommonOptions{Sources: []string{"team_a", "team_b"}}).Initialize(selectTestSources)
+	if err != nil {
+		t.Fatalf("unable to initialize tool: %s", err)
+	}
+
+	tcs := []struct {
+		desc string
+		args map[string]any
+		want string
+	}{
+		{
+			desc: "default source",
+			args: map[string]any{"q": "x"},
+			want: "a map[project:a q:x]",
+		},
+		{
+			desc: "selected source",
+			args: map[string]any{"q": "x", "source": "team_b"},
+			want: "b map[project:b q:x]",
+		},
+		{
+			desc: "selected source with a parameter",
+			args: map[string]any{"q": "x", "source": "team_b", "project": "c"},
+			want: "b map[project:c q:x]",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			params, err := tools.ParseParams(tool, tc.args, nil)
+			if err != nil {
+				t.Fatalf("unable to parse params: %s", err)
+			}
+			got, tbErr := tools.InvokeWithTimeout(context.Background(), "my_tool", tool, mapSourceProvider(selectTestSources), params, "")
+			if tbErr != nil {
+				t.Fatalf("unexpected error: %s", tbErr)
+			}
+			if got != tc.want {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSourceSelectionManifests(t *testing.T) {
+	tool, err := newSelectTestConfig("team_a", "team_a", "team_b").Initialize(selectTestSources)
+	if err != nil {
+		t.Fatalf("unable to initialize tool: %s", err)
+	}
+	var names []string
+	for _, p := range tool.Manifest().Parameters {
+		names = append(names, p.Name)
+	}
+	if diff := cmp.Diff([]string{"q", "source"}, names); diff != "" {
+		t.Fatalf("unexpected parameters of the manifest (-want +got):\n%s", diff)
+	}
+	schema := tool.McpManifest().InputSchema
+	property, ok := schema.Properties[tools.SourceParameterName]
+	if !ok {
+		t.Fatalf("missing source property in %v", schema.Properties)
+	}
+	if diff := cmp.Diff([]any{"team_a", "team_b"}, property.Enum); diff != "" {
+		t.Fatalf("unexpected enum of the source property (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"q"}, schema.Required); diff != "" {
+		t.Fatalf("unexpected required properties (-want +got):\n%s", diff)
+	}
+}
+
+func TestSingleSource(t *testing.T) {
+	tool, err := newSelectTestConfig("team_b").Initialize(selectTestSources)
+	if err != nil {
+		t.Fatalf("unable to initialize tool: %s", err)
+	}
+	if _, ok := tool.McpManifest().InputSchema.Properties[tools.SourceParameterName]; ok {
+		t.Fatalf("expected no source property for a tool with a single source")
+	}
+	if len(tool.GetParameters()) != 1 {
+		t.Fatalf("unexpected parameters: %v", tool.GetParameters())
+	}
+	params, err := parameters.ParseParams(tool.GetParameters(), map[string]any{"q": "x"}, nil)
+	if err != nil {
+		t.Fatalf("unable to parse params: %s", err)
+	}
+	got, tbErr := tools.InvokeWithTimeout(context.Background(), "my_tool", tool, mapSourceProvider(selectTestSources), params, "")
+	if tbErr != nil {
+		t.Fatalf("unexpected error: %s", tbErr)
+	}
+	if got != "b map[q:x]" {
+		t.Fatalf("got %v, want b map[q:x]", got)
+	}
+}
+
+func TestSourceSelectionInitialize(t *testing.T) {
+	sourceParam := selectTestConfig{
+		Name:       "my_tool",
+		Source:     "team_a",
+		Parameters: parameters.Parameters{parameters.NewStringParameter("source", "a source")},
+	}
+	tcs := []struct {
+		desc    string
+		cfg     tools.ToolConfig
+		wantErr string
+	}{
+		{
+			desc:    "configured source not listed",
+			cfg:     newSelectTestConfig("team_a", "team_b"),
+			wantErr: `'source' "team_a" must be one of 'sources'`,
+		},
+		{
+			desc:    "undefined source",
+			cfg:     newSelectTestConfig("team_a", "team_a", "team_c"),
+			wantErr: `source "team_c" of 'sources' is not defined`,
+		},
+		{
+			desc:    "incompatible source",
+			cfg:     newSelectTestConfig("team_a", "team_a", "plain"),
+			wantErr: `source "plain" of 'sources' is not compatible`,
+		},
+		{
+			desc:    "parameter named source",
+			cfg:     tools.WithCommonOptions(sourceParam, tools.CommonOptions{Sources: []string{"team_a", "team_b"}}),
+			wantErr: `'sources' requires no parameter named "source"`,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := tc.cfg.Initialize(selectTestSources)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSourcesConfig(t *testing.T) {
+	if !tools.Register("select-test-type", func(ctx context.Context, name string, decoder *yaml.Decoder) (tools.ToolConfig, error) {
+		actual := selectTestConfig{Name: name}
+		if err := decoder.DecodeContext(ctx, &actual); err != nil {
+			return nil, err
+		}
+		return actual, nil
+	}) {
+		t.Fatalf("unable to register select-test-type")
+	}
+	ctx := context.Background()
+	tcs := []struct {
+		desc    string
+		fields  string
+		want    tools.ToolConfig
+		wantErr string
+	}{
+		{
+			desc:   "default source",
+			fields: "sources: [team_a, team_b]",
+			want:   tools.CommonConfig{ToolConfig: selectTestConfig{Name: "my_tool", Type: "select-test-type", Source: "team_a", Description: "some description", AuthRequired: []string{}}, CommonOptions: tools.CommonOptions{Sources: []string{"team_a", "team_b"}}},
+		},
+		{
+			desc:   "configured source",
+			fields: "source: team_b\nsources: [team_a, team_b]",
+			want:   tools.CommonConfig{ToolConfig: selectTestConfig{Name: "my_tool", Type: "select-test-type", Source: "team_b", Description: "some description", AuthRequired: []string{}}, CommonOptions: tools.CommonOptions{Sources: []string{"team_a", "team_b"}}},
+		},
+		{
+			desc:    "duplicate sources",
+			fields:  "sources: [team_a, team_a]",
+			wantErr: "'sources' must be a list of unique source names",
+		},
+		{
+			desc:    "empty sources",
+			fields:  "sources: []",
+			wantErr: "'sources' must list at least one source",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			in := "kind: tools\nname: my_tool\ntype: select-test-type\ndescription: some description\n" + tc.fields + "\n"
+			_, _, _, got, _, _, err := server.UnmarshalResourceConfig(ctx, []byte(in))
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unable to unmarshal: %s", err)
+			}
+			if diff := cmp.Diff(server.ToolConfigs{"my_tool": tc.want}, got); diff != "" {
+				t.Fatalf("incorrect parse: diff %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+)
+
+// StreamHeader is the header, set to "true", that requests the result of an
+// invocation to be streamed as newline-delimited JSON chunks, if the tool
+// supports streaming.
+const StreamHeader = "X-Toolbox-Stream"
+
+// StreamChunkMetaKey is the key, in the `_meta` of the MCP progress
+// notifications of a streamed invocation, of the chunk they report.
+const StreamChunkMetaKey = "toolbox/chunk"
+
+// DefaultMaxBufferedChunks is the number of chunks of a streamed invocation
+// that are buffered, waiting to be sent to the client, before the tool is
+// paused.
+const DefaultMaxBufferedChunks = 16
+
+// ChunkSink receives the chunks of a streamed invocation, in order. It
+// returns an error if the chunk cannot be accepted, in which case the tool
+// must stop streaming.
+type ChunkSink func(chunk any) error
+
+// StreamingTool is implemented by tools that can send the result of an
+// invocation incrementally. Each chunk is a []any holding the next items of
+// the result, such as rows, or a single value if the result is not a list.
+// Invoke remains the fallback for clients that cannot receive streams.
+type StreamingTool interface {
+	Tool
+	// InvokeStream runs the invocation and sends its result to sink, chunk
+	// by chunk. It returns once the result is sent, or as soon as sink or ctx
+	// fail.
+	InvokeStream(context.Context, SourceProvider, parameters.ParamValues, AccessToken, ChunkSink) util.ToolboxError
+}
+
+// InvocationModeStream is the invocation_mode attribute of the spans of
+// streamed invocations.
+const InvocationModeStream = "stream"
+
+// InvokeStream streams the invocation with the tool's type, if it is a
+// StreamingTool. Use SupportsStreaming to know if it is.
+func (t commonTool) InvokeStream(ctx context.Context, resourceMgr SourceProvider, params parameters.ParamValues, accessToken AccessToken, sink ChunkSink) util.ToolboxError {
+	if _, ok := t.Tool.(StreamingTool); !ok {
+		return util.NewClientServerError("tool does not support streaming", http.StatusBadRequest, nil)
+	}
+	accessToken, err := t.bearerToken(accessToken)
+	if err != nil {
+		return err
+	}
+	tool, params, err := t.selectSource(params)
+	if err != nil {
+		return err
+	}
+	return tool.(StreamingTool).InvokeStream(ctx, resourceMgr, params, accessToken, sink)
+}
+
+// SupportsStreaming returns whether tool is a StreamingTool, whether or not
+// it uses the options available to every tool type.
+func SupportsStreaming(tool Tool) bool {
+	if t, ok := tool.(commonTool); ok {
+		tool = t.Tool
+	}
+	_, ok := tool.(StreamingTool)
+	return ok
+}
+
+// StreamWithTimeout streams the invocation of the tool named toolName like
+// InvokeWithTimeout, and passes its chunks to emit, in order.
+//
+// The tool runs concurrently with emit: up to maxBuffered chunks, or
+// DefaultMaxBufferedChunks if it is not positive, wait for emit before the
+// tool is paused, so a slow client slows the tool down instead of piling up
+// its result in memory. If emit fails, or ctx is done, the invocation is
+// canceled.
+func StreamWithTimeout(ctx context.Context, toolName string, tool Tool, resourceMgr SourceProvider, params parameters.ParamValues, accessToken AccessToken, maxBuffered int, emit ChunkSink) (err util.ToolboxError) {
+	if !SupportsStreaming(tool) {
+		return util.NewClientServerError(fmt.Sprintf("tool %q does not support streaming", toolName), http.StatusBadRequest, nil)
+	}
+	ctx, end := startInvocationSpan(ctx, InvocationModeStream, toolName, tool, resourceMgr, params)
+	defer func() { end(err) }()
+	release, err := acquireSourceSlot(ctx, toolName, tool, resourceMgr, params)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if maxBuffered <= 0 {
+		maxBuffered = DefaultMaxBufferedChunks
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	chunks := make(chan any, maxBuffered)
+	done := make(chan util.ToolboxError, 1)
+	streamer := tool.(StreamingTool)
+	go func() {
+		defer close(chunks)
+		_, err := withTimeout(ctx, toolName, tool, func(ctx context.Context) (any, util.ToolboxError) {
+			return nil, streamer.InvokeStream(ctx, resourceMgr, params, accessToken, func(chunk any) error {
+				// a canceled stream stops even if the buffer has room
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				select {
+				case chunks <- chunk:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			})
+		})
+		done <- err
+	}()
+
+	var emitErr error
+	for chunk := range chunks {
+		// once emit fails, the remaining chunks are dropped until the tool
+		// stops
+		if emitErr != nil {
+			continue
+		}
+		if emitErr = emit(chunk); emitErr != nil {
+			cancel()
+		}
+	}
+	err = <-done
+	if emitErr != nil {
+		return util.NewClientServerError(fmt.Sprintf("unable to send the result of tool %q", toolName), http.StatusInternalServerError, emitErr)
+	}
+	return err
+}
+
+// CollectChunks returns a ChunkSink that appends the items of the chunks it
+// receives to *result, to build the result that Invoke would return.
+func CollectChunks(result *[]any) ChunkSink {
+	return func(chunk any) error {
+		if items, ok := chunk.([]any); ok {
+			*result = append(*result, items...)
+			return nil
+		}
+		*result = append(*result, chunk)
+		return nil
+	}
+}
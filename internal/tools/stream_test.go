@@ -0,0 +1,164 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/sources"
+	"github.com/googleapis/genai-toolbox/internal/tools"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+)
+
+// streamTestTool is a tool that streams the chunks [0], [1], ... [n-1], and
+// counts the chunks its sink accepted.
+type streamTestTool struct {
+	tokenTestTool
+	n    int
+	sent *atomic.Int64
+}
+
+func (t streamTestTool) InvokeStream(ctx context.Context, _ tools.SourceProvider, _ parameters.ParamValues, _ tools.AccessToken, sink tools.ChunkSink) util.ToolboxError {
+	for i := 0; i < t.n; i++ {
+		if err := sink([]any{i}); err != nil {
+			return util.NewAgentError("stream stopped", err)
+		}
+		t.sent.Add(1)
+	}
+	return nil
+}
+
+type streamTestConfig struct {
+	n    int
+	sent *atomic.Int64
+}
+
+func (c streamTestConfig) ToolConfigType() string { return "stream" }
+func (c streamTestConfig) Initialize(map[string]sources.Source) (tools.Tool, error) {
+	return streamTestTool{n: c.n, sent: c.sent}, nil
+}
+
+func TestStreamWithTimeoutOrdering(t *testing.T) {
+	for _, cfg := range []tools.ToolConfig{
+		streamTestConfig{n: 50, sent: &atomic.Int64{}},
+		tools.WithCommonOptions(streamTestConfig{n: 50, sent: &atomic.Int64{}}, tools.CommonOptions{Timeout: time.Minute}),
+	} {
+		tool, err := cfg.Initialize(nil)
+		if err != nil {
+			t.Fatalf("unable to initialize tool: %s", err)
+		}
+		if !tools.SupportsStreaming(tool) {
+			t.Fatalf("expected %T to support streaming", tool)
+		}
+		var got []any
+		tbErr := tools.StreamWithTimeout(context.Background(), "my_tool", tool, nil, nil, "", 1, func(chunk any) error {
+			// a slow client must not reorder the chunks
+			time.Sleep(100 * time.Microsecond)
+			return tools.CollectChunks(&got)(chunk)
+		})
+		if tbErr != nil {
+			t.Fatalf("unexpected error: %s", tbErr)
+		}
+		want := make([]any, 50)
+		for i := range want {
+			want[i] = i
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Fatalf("unexpected chunks (-want +got):\n%s", diff)
+		}
+	}
+}
+
+func TestStreamWithTimeoutCancellation(t *testing.T) {
+	tcs := []struct {
+		desc    string
+		cancel  bool
+		wantErr string
+	}{
+		{
+			desc:    "client fails",
+			wantErr: `unable to send the result of tool "my_tool"`,
+		},
+		{
+			desc:    "context canceled",
+			cancel:  true,
+			wantErr: "stream stopped",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			sent := &atomic.Int64{}
+			tool, err := streamTestConfig{n: 1000, sent: sent}.Initialize(nil)
+			if err != nil {
+				t.Fatalf("unable to initialize tool: %s", err)
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			received := 0
+			tbErr := tools.StreamWithTimeout(ctx, "my_tool", tool, nil, nil, "", 2, func(chunk any) error {
+				received++
+				if received < 3 {
+					return nil
+				}
+				if tc.cancel {
+					cancel()
+					return nil
+				}
+				return errors.New("connection reset")
+			})
+			if tbErr == nil || !strings.Contains(tbErr.Error(), tc.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantErr, tbErr)
+			}
+			// the tool stops once the buffer of 2 chunks is full
+			if got := sent.Load(); got > 3+2+1 {
+				t.Fatalf("expected the tool to stop streaming, it sent %d chunks", got)
+			}
+		})
+	}
+}
+
+func TestStreamWithTimeoutFallback(t *testing.T) {
+	for _, cfg := range []tools.ToolConfig{
+		tokenTestConfig{},
+		tools.WithCommonOptions(tokenTestConfig{}, tools.CommonOptions{Timeout: time.Minute}),
+	} {
+		tool, err := cfg.Initialize(nil)
+		if err != nil {
+			t.Fatalf("unable to initialize tool: %s", err)
+		}
+		if tools.SupportsStreaming(tool) {
+			t.Fatalf("expected %T not to support streaming", tool)
+		}
+		tbErr := tools.StreamWithTimeout(context.Background(), "my_tool", tool, nil, nil, "", 0, func(any) error { return nil })
+		if tbErr == nil || !strings.Contains(tbErr.Error(), `tool "my_tool" does not support streaming`) {
+			t.Fatalf("unexpected error: %v", tbErr)
+		}
+		// clients that cannot stream invoke the tool instead
+		got, tbErr := tools.InvokeWithTimeout(context.Background(), "my_tool", tool, nil, nil, "Bearer abc")
+		if tbErr != nil {
+			t.Fatalf("unexpected error: %s", tbErr)
+		}
+		if got != "abc" {
+			t.Fatalf("got %v, want abc", got)
+		}
+	}
+}
@@ -18,8 +18,12 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"regexp"
 	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
 
 	yaml "github.com/goccy/go-yaml"
 	"github.com/googleapis/genai-toolbox/internal/embeddingmodels"
@@ -35,24 +39,105 @@ type ToolConfigFactory func(ctx context.Context, name string, decoder *yaml.Deco
 
 var toolRegistry = make(map[string]ToolConfigFactory)
 
+// Alias is an alternative name of a registered tool type, e.g. its name
+// before it was renamed.
+type Alias struct {
+	Name string
+	// Deprecated aliases log a warning naming the canonical type the first
+	// time they are used.
+	Deprecated bool
+}
+
+// toolAliases maps alias names to the canonical type they resolve to.
+var toolAliases = make(map[string]aliasTarget)
+
+type aliasTarget struct {
+	resourceType string
+	deprecated   bool
+	// warned is set once the deprecation warning has been logged.
+	warned *sync.Once
+}
+
 // Register allows individual tool packages to register their configuration
 // factory function. This is typically called from an init() function in the
-// tool's package. It associates a 'type' string with a function that can
-// produce the specific ToolConfig type. It returns true if the registration was
-// successful, and false if a tool with the same type was already registered.
-func Register(resourceType string, factory ToolConfigFactory) bool {
-	if _, exists := toolRegistry[resourceType]; exists {
+// tool's package. It associates a 'type' string, and optionally alias names,
+// with a function that can produce the specific ToolConfig type. It returns
+// true if the registration was successful, and false if the type or one of the
+// aliases is already registered as a type or an alias, in which case nothing
+// is registered.
+func Register(resourceType string, factory ToolConfigFactory, aliases ...Alias) bool {
+	if isRegisteredName(resourceType) {
 		// Tool with this type already exists, do not overwrite.
 		return false
 	}
+	for i, alias := range aliases {
+		if alias.Name == resourceType || isRegisteredName(alias.Name) || slices.ContainsFunc(aliases[:i], func(a Alias) bool { return a.Name == alias.Name }) {
+			return false
+		}
+	}
 	toolRegistry[resourceType] = factory
+	for _, alias := range aliases {
+		toolAliases[alias.Name] = aliasTarget{resourceType: resourceType, deprecated: alias.Deprecated, warned: &sync.Once{}}
+	}
 	return true
 }
 
-// DecodeConfig looks up the registered factory for the given type and uses it
-// to decode the tool configuration.
+func isRegisteredName(name string) bool {
+	_, isType := toolRegistry[name]
+	_, isAlias := toolAliases[name]
+	return isType || isAlias
+}
+
+// ResolveType returns the canonical type of resourceType, which may be an
+// alias. Using a deprecated alias logs a warning, once, to the logger in ctx.
+// Types that are not aliases are returned unchanged.
+func ResolveType(ctx context.Context, resourceType string) string {
+	target, ok := toolAliases[resourceType]
+	if !ok {
+		return resourceType
+	}
+	if target.deprecated {
+		target.warned.Do(func() {
+			if logger, err := util.LoggerFromContext(ctx); err == nil {
+				logger.WarnContext(ctx, fmt.Sprintf("tool type %q is deprecated, use %q instead", resourceType, target.resourceType))
+			}
+		})
+	}
+	return target.resourceType
+}
+
+// RegisteredType describes a registered tool type.
+type RegisteredType struct {
+	Type    string
+	Aliases []Alias
+}
+
+// RegisteredTypes returns the registered tool types with their aliases, sorted
+// by type and alias name.
+func RegisteredTypes() []RegisteredType {
+	types := make([]RegisteredType, 0, len(toolRegistry))
+	index := make(map[string]int, len(toolRegistry))
+	for resourceType := range toolRegistry {
+		types = append(types, RegisteredType{Type: resourceType})
+	}
+	slices.SortFunc(types, func(a, b RegisteredType) int { return strings.Compare(a.Type, b.Type) })
+	for i, t := range types {
+		index[t.Type] = i
+	}
+	for name, target := range toolAliases {
+		i := index[target.resourceType]
+		types[i].Aliases = append(types[i].Aliases, Alias{Name: name, Deprecated: target.deprecated})
+	}
+	for _, t := range types {
+		slices.SortFunc(t.Aliases, func(a, b Alias) int { return strings.Compare(a.Name, b.Name) })
+	}
+	return types
+}
+
+// DecodeConfig looks up the registered factory for the given type, or the
+// type an alias resolves to, and uses it to decode the tool configuration.
 func DecodeConfig(ctx context.Context, resourceType string, name string, decoder *yaml.Decoder) (ToolConfig, error) {
-	factory, found := toolRegistry[resourceType]
+	factory, found := toolRegistry[ResolveType(ctx, resourceType)]
 	if !found {
 		return nil, fmt.Errorf("unknown tool type: %q", resourceType)
 	}
@@ -68,6 +153,24 @@ type ToolConfig interface {
 	Initialize(map[string]sources.Source) (Tool, error)
 }
 
+// ToolConfigWithContext is implemented by tool configs whose initialization
+// needs a context, e.g. to make network calls. The server calls
+// InitializeWithContext instead of Initialize for these configs.
+type ToolConfigWithContext interface {
+	ToolConfig
+	InitializeWithContext(context.Context, map[string]sources.Source) (Tool, error)
+}
+
+// ToolConfigWithFields is implemented by tool configs that declare fields
+// the server otherwise handles for every tool, such as `timeout`, or sets from
+// a policy, such as `allowedProjects`. The server leaves the fields listed by
+// DeclaredFields to the config.
+type ToolConfigWithFields interface {
+	ToolConfig
+	// DeclaredFields returns the YAML names of these fields.
+	DeclaredFields() []string
+}
+
 // https://modelcontextprotocol.io/specification/2025-06-18/schema#toolannotations
 type ToolAnnotations struct {
 	DestructiveHint *bool `json:"destructiveHint,omitempty" yaml:"destructiveHint,omitempty"`
@@ -76,14 +179,151 @@ type ToolAnnotations struct {
 	ReadOnlyHint    *bool `json:"readOnlyHint,omitempty" yaml:"readOnlyHint,omitempty"`
 }
 
+// ConfirmDestructiveHeader is the header, set to "true", that confirms the
+// invocation of a destructive tool through the HTTP API when the server
+// requires confirmation.
+const ConfirmDestructiveHeader = "X-Toolbox-Confirm-Destructive"
+
+// ConfirmDestructiveMetaKey is the key, set to true, in the `_meta` of an MCP
+// tools/call request that confirms the invocation of a destructive tool when
+// the server requires confirmation.
+const ConfirmDestructiveMetaKey = "toolbox/confirmDestructive"
+
+// PlanHeader is the header, set to "true", that requests the plan of an
+// invocation through the HTTP API instead of its result.
+const PlanHeader = "X-Toolbox-Plan"
+
+// PlanMetaKey is the key, set to true, in the `_meta` of an MCP tools/call
+// request that requests the plan of an invocation instead of its result.
+const PlanMetaKey = "toolbox/plan"
+
+// NewReadOnlyAnnotations returns the annotations of a tool that does not
+// modify its environment, and whose repeated calls have no additional effect.
+func NewReadOnlyAnnotations() *ToolAnnotations {
+	readOnly, idempotent := true, true
+	return &ToolAnnotations{ReadOnlyHint: &readOnly, IdempotentHint: &idempotent}
+}
+
+// NewDestructiveAnnotations returns the annotations of a tool that may delete
+// or overwrite data.
+func NewDestructiveAnnotations() *ToolAnnotations {
+	readOnly, destructive := false, true
+	return &ToolAnnotations{ReadOnlyHint: &readOnly, DestructiveHint: &destructive}
+}
+
+// GetAnnotationsOrDefault returns annotations, or the result of defaultFn if
+// annotations is nil. It allows tool configs to override the default
+// annotations of their type.
+func GetAnnotationsOrDefault(annotations *ToolAnnotations, defaultFn func() *ToolAnnotations) *ToolAnnotations {
+	if annotations != nil {
+		return annotations
+	}
+	return defaultFn()
+}
+
+// IsDestructive returns whether the annotations explicitly mark a tool as
+// destructive.
+func (a *ToolAnnotations) IsDestructive() bool {
+	return a != nil && a.DestructiveHint != nil && *a.DestructiveHint
+}
+
+// DefaultAuthTokenHeader is the header client access tokens are read from,
+// unless a tool configures another one.
+const DefaultAuthTokenHeader = "Authorization"
+
+const (
+	// AuthTokenSchemeBearer is the scheme of tokens sent as "Bearer <token>".
+	// It is the default.
+	AuthTokenSchemeBearer = "bearer"
+	// AuthTokenSchemeRaw is the scheme of tokens sent without a prefix.
+	AuthTokenSchemeRaw = "raw"
+)
+
 type AccessToken string
 
+// strictBearerTokens requires bearer tokens to be sent with the "Bearer"
+// scheme. See SetStrictBearerTokens.
+var strictBearerTokens atomic.Bool
+
+// SetStrictBearerTokens sets whether bearer tokens must be sent as
+// "Bearer <token>". By default, a token sent without a scheme is also
+// accepted if it looks like an OAuth2 access token or a JWT.
+func SetStrictBearerTokens(strict bool) {
+	strictBearerTokens.Store(strict)
+}
+
+var (
+	// jwtPattern matches the three base64url segments of a JWT.
+	jwtPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+$`)
+	// b64TokenPattern matches the b64token syntax of bearer tokens in RFC 6750,
+	// e.g. Google OAuth2 access tokens such as "ya29.a0Af...".
+	b64TokenPattern = regexp.MustCompile(`^[A-Za-z0-9\-._~+/]+=*$`)
+)
+
+// minBareTokenLength is the shortest token sent without a scheme that is
+// accepted as an OAuth2 access token.
+const minBareTokenLength = 20
+
+// looksLikeBearerToken reports whether s, sent without a scheme, is
+// structurally an OAuth2 access token or a JWT.
+func looksLikeBearerToken(s string) bool {
+	return jwtPattern.MatchString(s) || (len(s) >= minBareTokenLength && b64TokenPattern.MatchString(s))
+}
+
 func (token AccessToken) ParseBearerToken() (string, error) {
-	headerParts := strings.Split(string(token), " ")
-	if len(headerParts) != 2 || strings.ToLower(headerParts[0]) != "bearer" {
-		return "", util.NewClientServerError("authorization header must be in the format 'Bearer <token>'", http.StatusUnauthorized, nil)
+	tokenStr, err := token.ParseToken(AuthTokenSchemeBearer)
+	if err != nil {
+		return "", err
+	}
+	return tokenStr, nil
+}
+
+// ParseToken returns the token sent with scheme, which is
+// AuthTokenSchemeBearer, AuthTokenSchemeRaw, or a custom prefix such as
+// "Token". Schemes are matched case-insensitively and surrounding whitespace
+// is ignored. Unless SetStrictBearerTokens is set, a bearer token may also be
+// sent without a scheme if it looks like an OAuth2 access token or a JWT.
+func (token AccessToken) ParseToken(scheme string) (string, util.ToolboxError) {
+	if scheme == "" {
+		scheme = AuthTokenSchemeBearer
+	}
+	header := strings.TrimSpace(string(token))
+	if strings.EqualFold(scheme, AuthTokenSchemeRaw) {
+		if header == "" || strings.ContainsAny(header, " \t") {
+			return "", util.NewClientServerError("authorization header must contain only the token", http.StatusUnauthorized, nil)
+		}
+		return header, nil
+	}
+	bearer := strings.EqualFold(scheme, AuthTokenSchemeBearer)
+	format := fmt.Sprintf("must be in the format '%s <token>'", scheme)
+	if bearer {
+		format = "must be in the format 'Bearer <token>'"
+	}
+	unauthorized := func(problem string) util.ToolboxError {
+		return util.NewClientServerError(fmt.Sprintf("authorization header %s, %s", problem, format), http.StatusUnauthorized, nil)
+	}
+	if header == "" {
+		return "", unauthorized("is empty")
 	}
-	return headerParts[1], nil
+	fields := strings.Fields(header)
+	if len(fields) == 1 {
+		switch {
+		case strings.EqualFold(fields[0], scheme):
+			return "", unauthorized("is missing the token")
+		case !bearer || strictBearerTokens.Load():
+			return "", unauthorized("has an unrecognized scheme")
+		case !looksLikeBearerToken(fields[0]):
+			return "", unauthorized("has a malformed token")
+		}
+		return fields[0], nil
+	}
+	if !strings.EqualFold(fields[0], scheme) {
+		return "", unauthorized("has an unrecognized scheme")
+	}
+	if len(fields) > 2 || (bearer && !b64TokenPattern.MatchString(fields[1])) {
+		return "", unauthorized("has a malformed token")
+	}
+	return fields[1], nil
 }
 
 type Tool interface {
@@ -98,6 +338,34 @@ type Tool interface {
 	GetParameters() parameters.Parameters
 }
 
+// PlannableTool is implemented by tools that can describe what an invocation
+// would do without doing it, e.g. the statistics of a dry run of a query, or
+// the request that would be sent to an API. Plan validates params as Invoke
+// does, but has no side effects.
+type PlannableTool interface {
+	Tool
+	Plan(context.Context, SourceProvider, parameters.ParamValues, AccessToken) (any, util.ToolboxError)
+}
+
+// RedactingTool is implemented by tools whose parameter values can hold data
+// that must not be logged beyond the values of sensitive parameters, such as
+// the string literals of SQL when the source redacts SQL.
+type RedactingTool interface {
+	Tool
+	// RedactParams returns the values of params as they can be logged.
+	RedactParams(SourceProvider, parameters.ParamValues) map[string]any
+}
+
+// LoggableParams returns the values of params of tool as they can be logged:
+// redacted by the tool if it is a RedactingTool, and with the values of
+// sensitive parameters redacted otherwise.
+func LoggableParams(tool Tool, resourceMgr SourceProvider, params parameters.ParamValues) map[string]any {
+	if t, ok := tool.(RedactingTool); ok {
+		return t.RedactParams(resourceMgr, params)
+	}
+	return params.AsRedactedMap()
+}
+
 // SourceProvider defines the minimal view of the server.ResourceManager
 // that the Tool package needs.
 // This is implemented to prevent import cycles.
@@ -110,6 +378,9 @@ type Manifest struct {
 	Description  string                         `json:"description"`
 	Parameters   []parameters.ParameterManifest `json:"parameters"`
 	AuthRequired []string                       `json:"authRequired"`
+	// AuthRequiredMode is set to "all" if all of the auth services in
+	// AuthRequired must be attached, instead of any one of them.
+	AuthRequiredMode AuthRequiredMode `json:"authRequiredMode,omitempty"`
 }
 
 // Definition for a tool the MCP client can call.
@@ -121,16 +392,22 @@ type McpManifest struct {
 	Annotations *ToolAnnotations `json:"annotations,omitempty"`
 	// A JSON Schema object defining the expected parameters for the tool.
 	InputSchema parameters.McpToolsSchema `json:"inputSchema,omitempty"`
-	Metadata    map[string]any            `json:"_meta,omitempty"`
+	// An optional JSON Schema object defining the structure of the tool's
+	// result.
+	OutputSchema map[string]any `json:"outputSchema,omitempty"`
+	Metadata     map[string]any `json:"_meta,omitempty"`
 }
 
-func GetMcpManifest(name, desc string, authInvoke []string, params parameters.Parameters, annotations *ToolAnnotations) McpManifest {
+// GetMcpManifest returns the McpManifest of a tool. outputSchema is the JSON
+// Schema of the tool's result, or nil if the tool does not declare one.
+func GetMcpManifest(name, desc string, authInvoke []string, params parameters.Parameters, annotations *ToolAnnotations, outputSchema map[string]any) McpManifest {
 	inputSchema, authParams := params.McpManifest()
 	mcpManifest := McpManifest{
-		Name:        name,
-		Description: desc,
-		InputSchema: inputSchema,
-		Annotations: annotations,
+		Name:         name,
+		Description:  desc,
+		InputSchema:  inputSchema,
+		OutputSchema: outputSchema,
+		Annotations:  annotations,
 	}
 
 	// construct metadata, if applicable
@@ -147,12 +424,62 @@ func GetMcpManifest(name, desc string, authInvoke []string, params parameters.Pa
 	return mcpManifest
 }
 
-// Helper function that returns if a tool invocation request is authorized
+// ExpandDescription expands a description written as a text/template, such as
+// "Tables in `{{ .Source.Project }}`.", using data. Referencing a field that is
+// not in data is an error. Literal braces can be written as {{"{{"}}.
+// Descriptions without "{{" are returned unchanged.
+func ExpandDescription(toolName, desc string, data map[string]any) (string, error) {
+	if !strings.Contains(desc, "{{") {
+		return desc, nil
+	}
+	tmpl, err := template.New(toolName).
+		Option("missingkey=error").
+		Funcs(template.FuncMap{"join": func(elems []string, sep string) string { return strings.Join(elems, sep) }}).
+		Parse(desc)
+	if err != nil {
+		return "", fmt.Errorf("invalid description template for tool %q: %w", toolName, err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("unable to expand description for tool %q: %w", toolName, err)
+	}
+	return b.String(), nil
+}
+
+// AuthRequiredMode is how the auth services listed in a tool's authRequired
+// must verify an invocation.
+type AuthRequiredMode string
+
+const (
+	// AuthRequiredAny requires any one of the listed auth services to verify
+	// an invocation.
+	AuthRequiredAny AuthRequiredMode = "any"
+	// AuthRequiredAll requires all of the listed auth services to verify an
+	// invocation.
+	AuthRequiredAll AuthRequiredMode = "all"
+)
+
+// Helper function that returns if a tool invocation request is authorized,
+// i.e. if any of the required auth services verified it
 func IsAuthorized(authRequiredSources []string, verifiedAuthServices []string) bool {
+	return IsAuthorizedWithMode(AuthRequiredAny, authRequiredSources, verifiedAuthServices)
+}
+
+// IsAuthorizedWithMode returns if a tool invocation request is authorized by
+// the required auth services, according to mode.
+func IsAuthorizedWithMode(mode AuthRequiredMode, authRequiredSources []string, verifiedAuthServices []string) bool {
 	if len(authRequiredSources) == 0 {
 		// no authorization requirement
 		return true
 	}
+	if mode == AuthRequiredAll {
+		for _, a := range authRequiredSources {
+			if !slices.Contains(verifiedAuthServices, a) {
+				return false
+			}
+		}
+		return true
+	}
 	for _, a := range authRequiredSources {
 		if slices.Contains(verifiedAuthServices, a) {
 			return true
@@ -169,7 +496,11 @@ func GetCompatibleSource[T any](resourceMgr SourceProvider, sourceName, toolName
 	}
 	source, ok := s.(T)
 	if !ok {
-		return zero, fmt.Errorf("invalid source for %q tool: source %q is not a compatible type", toolType, sourceName)
+		var srcs map[string]sources.Source
+		if p, ok := resourceMgr.(sourcesMapProvider); ok {
+			srcs = p.GetSourcesMap()
+		}
+		return zero, IncompatibleSourceError[T](s, sourceName, toolType, srcs)
 	}
 	return source, nil
 }
@@ -51,17 +51,23 @@ type Config struct {
 	AuthRequired []string `yaml:"authRequired"`
 }
 
-var _ tools.ToolConfig = Config{}
+var _ tools.ToolConfigWithFields = Config{}
 
 func (cfg Config) ToolConfigType() string {
 	return resourceType
 }
 
+// DeclaredFields reports that the timeout of the tool is its own field, the
+// maximum duration to wait for, and not the common timeout option.
+func (cfg Config) DeclaredFields() []string {
+	return []string{"timeout"}
+}
+
 func (cfg Config) Initialize(_ map[string]sources.Source) (tools.Tool, error) {
 	durationParameter := parameters.NewStringParameter("duration", "The duration to wait for, specified as a string (e.g., '10s', '2m', '1h').")
 	params := parameters.Parameters{durationParameter}
 
-	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, params, nil)
+	mcpManifest := tools.GetMcpManifest(cfg.Name, cfg.Description, cfg.AuthRequired, params, nil, nil)
 
 	t := Tool{
 		Config:      cfg,
@@ -13,6 +13,7 @@
 package util
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -28,18 +29,75 @@ const (
 	CategoryServer ErrorCategory = "SERVER_ERROR"
 )
 
+// ErrorCode is a stable, machine-readable code of a ToolboxError that clients
+// can act on without parsing the error message.
+type ErrorCode string
+
+const (
+	ErrorCodeInvalidArgument    ErrorCode = "invalid_argument"
+	ErrorCodePermissionDenied   ErrorCode = "permission_denied"
+	ErrorCodeNotFound           ErrorCode = "not_found"
+	ErrorCodeRateLimited        ErrorCode = "rate_limited"
+	ErrorCodeBackendUnavailable ErrorCode = "backend_unavailable"
+	ErrorCodeInternal           ErrorCode = "internal"
+)
+
+// ErrorInfo is the machine-readable part of a ToolboxError sent to clients.
+type ErrorInfo struct {
+	Code      ErrorCode `json:"code"`
+	Retryable bool      `json:"retryable"`
+	// Details holds structured information about the error, e.g. the
+	// datasets that a query is not allowed to access.
+	Details map[string]any `json:"details,omitempty"`
+	// RequestID is the ID of the request that failed, which is also logged
+	// with the messages of the request.
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// RequestErrorInfo returns the error info of err, for the request of ctx.
+func RequestErrorInfo(ctx context.Context, err ToolboxError) ErrorInfo {
+	info := err.ErrorInfo()
+	info.RequestID = RequestIDFromContext(ctx)
+	return info
+}
+
+// ErrorCodeFromStatus returns the error code for an HTTP status code, and
+// whether errors with the status are retryable.
+func ErrorCodeFromStatus(status int) (ErrorCode, bool) {
+	switch status {
+	case http.StatusBadRequest, http.StatusConflict, http.StatusPreconditionFailed, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity:
+		return ErrorCodeInvalidArgument, false
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrorCodePermissionDenied, false
+	case http.StatusNotFound:
+		return ErrorCodeNotFound, false
+	case http.StatusTooManyRequests:
+		return ErrorCodeRateLimited, true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return ErrorCodeBackendUnavailable, true
+	default:
+		return ErrorCodeInternal, false
+	}
+}
+
 // ToolboxError is the interface all custom errors must satisfy
 type ToolboxError interface {
 	error
 	Category() ErrorCategory
 	Error() string
 	Unwrap() error
+	ErrorInfo() ErrorInfo
 }
 
 // Agent Errors return 200 to the sender
 type AgentError struct {
 	Msg   string
 	Cause error
+	// ErrCode defaults to ErrorCodeInvalidArgument, as agents can usually
+	// correct their request.
+	ErrCode   ErrorCode
+	Retryable bool
+	Details   map[string]any
 }
 
 var _ ToolboxError = &AgentError{}
@@ -55,6 +113,27 @@ func (e *AgentError) Category() ErrorCategory { return CategoryAgent }
 
 func (e *AgentError) Unwrap() error { return e.Cause }
 
+func (e *AgentError) ErrorInfo() ErrorInfo {
+	code := e.ErrCode
+	if code == "" {
+		code = ErrorCodeInvalidArgument
+	}
+	return ErrorInfo{Code: code, Retryable: e.Retryable, Details: e.Details}
+}
+
+// WithCode sets the error code, and whether the error is retryable to the
+// default of the code.
+func (e *AgentError) WithCode(code ErrorCode) *AgentError {
+	e.ErrCode, e.Retryable = code, code == ErrorCodeRateLimited || code == ErrorCodeBackendUnavailable
+	return e
+}
+
+// WithDetails sets the structured details of the error.
+func (e *AgentError) WithDetails(details map[string]any) *AgentError {
+	e.Details = details
+	return e
+}
+
 func NewAgentError(msg string, cause error) *AgentError {
 	return &AgentError{Msg: msg, Cause: cause}
 }
@@ -66,6 +145,10 @@ type ClientServerError struct {
 	Msg   string
 	Code  int
 	Cause error
+	// ErrCode and Retryable default to the ones of Code.
+	ErrCode   ErrorCode
+	Retryable bool
+	Details   map[string]any
 }
 
 var _ ToolboxError = &ClientServerError{}
@@ -81,6 +164,27 @@ func (e *ClientServerError) Category() ErrorCategory { return CategoryServer }
 
 func (e *ClientServerError) Unwrap() error { return e.Cause }
 
+func (e *ClientServerError) ErrorInfo() ErrorInfo {
+	if e.ErrCode == "" {
+		code, retryable := ErrorCodeFromStatus(e.Code)
+		return ErrorInfo{Code: code, Retryable: retryable, Details: e.Details}
+	}
+	return ErrorInfo{Code: e.ErrCode, Retryable: e.Retryable, Details: e.Details}
+}
+
+// WithCode sets the error code, and whether the error is retryable to the
+// default of the code.
+func (e *ClientServerError) WithCode(code ErrorCode) *ClientServerError {
+	e.ErrCode, e.Retryable = code, code == ErrorCodeRateLimited || code == ErrorCodeBackendUnavailable
+	return e
+}
+
+// WithDetails sets the structured details of the error.
+func (e *ClientServerError) WithDetails(details map[string]any) *ClientServerError {
+	e.Details = details
+	return e
+}
+
 func NewClientServerError(msg string, code int, cause error) *ClientServerError {
 	return &ClientServerError{Msg: msg, Code: code, Cause: cause}
 }
@@ -104,6 +208,8 @@ func ProcessGcpError(err error) ToolboxError {
 				err,
 			)
 		}
+		code, _ := ErrorCodeFromStatus(gErr.Code)
+		return NewAgentError("error processing GCP request", err).WithCode(code)
 	}
 	return NewAgentError("error processing GCP request", err)
 }
@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"text/template"
+	"time"
 )
 
 // ConvertAnySliceToTyped a []any to typed slice ([]string, []int, []float etc.)
@@ -65,6 +66,26 @@ func ConvertAnySliceToTyped(s []any, itemType string) (any, error) {
 			tempSlice[j] = b
 		}
 		typedSlice = tempSlice
+	case TypeTimestamp:
+		tempSlice := make([]time.Time, len(s))
+		for j, item := range s {
+			t, ok := item.(time.Time)
+			if !ok {
+				return nil, fmt.Errorf("expected item at index %d to be timestamp, got %T", j, item)
+			}
+			tempSlice[j] = t
+		}
+		typedSlice = tempSlice
+	case TypeDate:
+		tempSlice := make([]string, len(s))
+		for j, item := range s {
+			d, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected item at index %d to be date, got %T", j, item)
+			}
+			tempSlice[j] = d
+		}
+		typedSlice = tempSlice
 	}
 	return typedSlice, nil
 }
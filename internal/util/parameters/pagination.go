@@ -0,0 +1,88 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parameters
+
+import "fmt"
+
+const (
+	// PageSizeParamName is the name of the parameter that limits the number
+	// of items in a page.
+	PageSizeParamName = "pageSize"
+	// PageTokenParamName is the name of the parameter that selects the page
+	// to list.
+	PageTokenParamName = "pageToken"
+	// MaxPageSize caps the page size requested by clients, so that a single
+	// invocation cannot list an unbounded number of items.
+	MaxPageSize = 1000
+)
+
+// NewPaginationParameters returns the standard pageSize and pageToken
+// parameters of a tool listing the given kind of items, e.g. "clusters".
+func NewPaginationParameters(items string, defaultPageSize int) Parameters {
+	return Parameters{
+		NewIntParameterWithDefault(PageSizeParamName, defaultPageSize, fmt.Sprintf("The maximum number of %s to return in a single page (default %d, at most %d)", items, defaultPageSize, MaxPageSize)),
+		NewStringParameterWithRequired(PageTokenParamName, "A page token, received as nextPageToken from a previous call. Leave empty to list the first page.", false),
+	}
+}
+
+// PageRequest is the page requested with the pagination parameters.
+type PageRequest struct {
+	// Size is the maximum number of items in the page, or 0 to use the API
+	// default.
+	Size int
+	// Token selects the page, or is empty for the first page.
+	Token string
+}
+
+// ParsePageRequest reads the pagination parameters from params. Page sizes
+// above MaxPageSize are lowered to it.
+func ParsePageRequest(params ParamValues) (PageRequest, error) {
+	var req PageRequest
+	paramMap := params.AsMap()
+	if v, ok := paramMap[PageSizeParamName]; ok && v != nil {
+		size, ok := v.(int)
+		if !ok {
+			return PageRequest{}, fmt.Errorf("%s must be an integer, got %T", PageSizeParamName, v)
+		}
+		if size <= 0 {
+			return PageRequest{}, fmt.Errorf("%s must be positive: %d", PageSizeParamName, size)
+		}
+		req.Size = min(size, MaxPageSize)
+	}
+	if v, ok := paramMap[PageTokenParamName]; ok && v != nil {
+		token, ok := v.(string)
+		if !ok {
+			return PageRequest{}, fmt.Errorf("%s must be a string, got %T", PageTokenParamName, v)
+		}
+		req.Token = token
+	}
+	return req, nil
+}
+
+// Page is the result of a listing tool. NextPageToken is empty on the last
+// page, and TotalSize is only set when the API reports it.
+type Page[T any] struct {
+	Items         []T    `json:"items"`
+	NextPageToken string `json:"nextPageToken,omitempty"`
+	TotalSize     *int   `json:"totalSize,omitempty"`
+}
+
+// NewPage wraps a page of items. A nil slice is returned as an empty list.
+func NewPage[T any](items []T, nextPageToken string) Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	return Page[T]{Items: items, NextPageToken: nextPageToken}
+}
@@ -0,0 +1,140 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parameters_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+)
+
+func TestNewPaginationParameters(t *testing.T) {
+	ps := parameters.NewPaginationParameters("clusters", 20)
+	schema, _ := ps.McpManifest()
+	if len(schema.Required) != 0 {
+		t.Fatalf("pagination parameters should be optional, got required %v", schema.Required)
+	}
+	pageSize, ok := schema.Properties[parameters.PageSizeParamName]
+	if !ok {
+		t.Fatalf("missing %s parameter", parameters.PageSizeParamName)
+	}
+	if pageSize.Default != 20 {
+		t.Errorf("unexpected pageSize default: got %v, want 20", pageSize.Default)
+	}
+	if !strings.Contains(pageSize.Description, "clusters") {
+		t.Errorf("pageSize description should name the items, got %q", pageSize.Description)
+	}
+	if _, ok := schema.Properties[parameters.PageTokenParamName]; !ok {
+		t.Fatalf("missing %s parameter", parameters.PageTokenParamName)
+	}
+}
+
+func TestParsePageRequest(t *testing.T) {
+	ps := parameters.NewPaginationParameters("clusters", 20)
+	tcs := []struct {
+		name    string
+		data    map[string]any
+		want    parameters.PageRequest
+		wantErr string
+	}{
+		{
+			name: "defaults",
+			data: map[string]any{},
+			want: parameters.PageRequest{Size: 20},
+		},
+		{
+			name: "token passthrough",
+			data: map[string]any{"pageSize": 5, "pageToken": "page-2"},
+			want: parameters.PageRequest{Size: 5, Token: "page-2"},
+		},
+		{
+			name: "page size is capped",
+			data: map[string]any{"pageSize": parameters.MaxPageSize + 1},
+			want: parameters.PageRequest{Size: parameters.MaxPageSize},
+		},
+		{
+			name:    "zero page size",
+			data:    map[string]any{"pageSize": 0},
+			wantErr: "pageSize must be positive: 0",
+		},
+		{
+			name:    "negative page size",
+			data:    map[string]any{"pageSize": -1},
+			wantErr: "pageSize must be positive: -1",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			params, err := parameters.ParseParams(ps, tc.data, nil)
+			if err != nil {
+				t.Fatalf("unexpected error parsing params: %s", err)
+			}
+			got, err := parameters.ParsePageRequest(params)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("unexpected error: got %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("unexpected page request (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPageJSON(t *testing.T) {
+	total := 3
+	withTotal := parameters.NewPage([]string{"a", "b"}, "page-2")
+	withTotal.TotalSize = &total
+	tcs := []struct {
+		name string
+		page any
+		want string
+	}{
+		{
+			name: "last page",
+			page: parameters.NewPage([]string{"a"}, ""),
+			want: `{"items":["a"]}`,
+		},
+		{
+			name: "empty page",
+			page: parameters.NewPage[string](nil, ""),
+			want: `{"items":[]}`,
+		},
+		{
+			name: "next page and total size",
+			page: withTotal,
+			want: `{"items":["a","b"],"nextPageToken":"page-2","totalSize":3}`,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := json.Marshal(tc.page)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if string(got) != tc.want {
+				t.Fatalf("unexpected JSON: got %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
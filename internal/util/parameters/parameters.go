@@ -18,25 +18,32 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"reflect"
 	"regexp"
 	"slices"
 	"strings"
+	"sync"
 	"text/template"
+	"time"
 
 	embeddingmodels "github.com/googleapis/genai-toolbox/internal/embeddingmodels"
 	"github.com/googleapis/genai-toolbox/internal/util"
 )
 
 const (
-	TypeString = "string"
-	TypeInt    = "integer"
-	TypeFloat  = "float"
-	TypeBool   = "boolean"
-	TypeArray  = "array"
-	TypeMap    = "map"
+	TypeString    = "string"
+	TypeInt       = "integer"
+	TypeFloat     = "float"
+	TypeBool      = "boolean"
+	TypeArray     = "array"
+	TypeMap       = "map"
+	TypeObject    = "object"
+	TypeTimestamp = "timestamp"
+	TypeDate      = "date"
 )
 
 // delimiters for string parameter escaping
@@ -54,6 +61,42 @@ type ParamValues []ParamValue
 type ParamValue struct {
 	Name  string
 	Value any
+	// Omitted is set when the caller did not provide the parameter, in which
+	// case Value holds its default, if any. A provided null has a nil Value
+	// and Omitted unset.
+	Omitted bool
+	// Sensitive is set for parameters whose value must not be logged.
+	Sensitive bool
+	// Coerced is set when a string input was converted to the parameter's
+	// type.
+	Coerced bool
+}
+
+// RedactedValue replaces the value of sensitive parameters in logs and error
+// messages.
+const RedactedValue = "[REDACTED]"
+
+// WasProvided reports whether the caller provided a value, including an
+// explicit null, for the named parameter.
+func (p ParamValues) WasProvided(name string) bool {
+	for _, v := range p {
+		if v.Name == name {
+			return !v.Omitted
+		}
+	}
+	return false
+}
+
+// Coerced returns the names of the parameters whose string inputs were
+// converted to their type.
+func (p ParamValues) Coerced() []string {
+	var names []string
+	for _, v := range p {
+		if v.Coerced {
+			names = append(names, v.Name)
+		}
+	}
+	return names
 }
 
 // AsSlice returns a slice of the Param's values (in order).
@@ -66,6 +109,20 @@ func (p ParamValues) AsSlice() []any {
 	return params
 }
 
+// AsRedactedSlice returns a slice of the Param's values (in order), with the
+// values of sensitive parameters replaced by RedactedValue.
+func (p ParamValues) AsRedactedSlice() []any {
+	params := []any{}
+	for _, p := range p {
+		if p.Sensitive {
+			params = append(params, RedactedValue)
+			continue
+		}
+		params = append(params, p.Value)
+	}
+	return params
+}
+
 // AsMap returns a map of ParamValue's names to values.
 func (p ParamValues) AsMap() map[string]interface{} {
 	params := make(map[string]interface{})
@@ -75,6 +132,21 @@ func (p ParamValues) AsMap() map[string]interface{} {
 	return params
 }
 
+// AsRedactedMap returns a map of ParamValue's names to values, with the
+// values of sensitive parameters replaced by RedactedValue. It should be used
+// whenever parameter values are logged or included in error messages.
+func (p ParamValues) AsRedactedMap() map[string]interface{} {
+	params := make(map[string]interface{})
+	for _, p := range p {
+		if p.Sensitive {
+			params[p.Name] = RedactedValue
+			continue
+		}
+		params[p.Name] = p.Value
+	}
+	return params
+}
+
 // AsMapByOrderedKeys returns a map of a key's position to it's value, as necessary for Spanner PSQL.
 // Example { $1 -> "value1", $2 -> "value2" }
 func (p ParamValues) AsMapByOrderedKeys() map[string]interface{} {
@@ -136,6 +208,7 @@ func ParseParams(ps Parameters, data map[string]any, claimsMap map[string]map[st
 		paramAuthServices := p.GetAuthServices()
 		name := p.GetName()
 
+		omitted := false
 		sourceParamName := p.GetValueFromParam()
 		if sourceParamName != "" {
 			v = data[sourceParamName]
@@ -144,7 +217,16 @@ func ParseParams(ps Parameters, data map[string]any, claimsMap map[string]map[st
 			// parse non auth-required parameter
 			var ok bool
 			v, ok = data[name]
+			if !ok {
+				v, ok = aliasedValue(p, data)
+			}
+			if ok && v == nil && p.GetNullable() {
+				// an explicit null is kept as is for nullable parameters
+				params = append(params, ParamValue{Name: name, Value: nil, Sensitive: p.GetSensitive()})
+				continue
+			}
 			if !ok || v == nil {
+				omitted = true
 				v = p.GetDefault()
 				// if the parameter is required and no value given, throw an error
 				if CheckParamRequired(p.GetRequired(), v) {
@@ -158,47 +240,159 @@ func ParseParams(ps Parameters, data map[string]any, claimsMap map[string]map[st
 				return nil, util.NewClientServerError(fmt.Sprintf("error parsing authenticated parameter %q", name), http.StatusUnauthorized, err)
 			}
 		}
+		coerced := false
+		if c, ok := p.(stringCoercer); ok && v != nil && !omitted {
+			v, coerced = c.coerce(v)
+		}
 		if v != nil {
 			newV, err = p.Parse(v)
 			if err != nil {
+				if p.GetSensitive() {
+					err = redactError(err, v)
+				}
 				return nil, util.NewAgentError(fmt.Sprintf("unable to parse value for %q", name), err)
 			}
 		}
-		params = append(params, ParamValue{Name: name, Value: newV})
+		params = append(params, ParamValue{Name: name, Value: newV, Omitted: omitted, Sensitive: p.GetSensitive(), Coerced: coerced})
 	}
 	return params, nil
 }
 
+// stringCoercer is implemented by Parameters that can opt in to converting
+// string inputs, such as "5" or "true", to their type. coerce returns the
+// converted value and true, or v unchanged and false when v is not a string
+// that unambiguously represents a value of the type.
+type stringCoercer interface {
+	coerce(v any) (any, bool)
+}
+
+// jsonNumber matches numbers in JSON syntax, which excludes values such as
+// "NaN", "0x10" or " 5".
+var jsonNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// coerceString converts s to a value of the scalar parameter type typ.
+func coerceString(typ string, s string) (any, bool) {
+	switch typ {
+	case TypeInt, TypeFloat:
+		if !jsonNumber.MatchString(s) {
+			return nil, false
+		}
+		return json.Number(s), true
+	case TypeBool:
+		switch strings.ToLower(s) {
+		case "true":
+			return true, true
+		case "false":
+			return false, true
+		}
+	}
+	return nil, false
+}
+
+// RedactError returns err with the values of sensitive parameters replaced by
+// RedactedValue. It should be used for errors that may contain parameter
+// values, such as database errors, before they are logged or returned.
+func (p ParamValues) RedactError(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, p := range p {
+		if p.Sensitive && p.Value != nil {
+			err = redactError(err, p.Value)
+		}
+	}
+	return err
+}
+
+// redactError returns an error with the same message as err, but with any
+// occurrence of the value v replaced by RedactedValue.
+func redactError(err error, v any) error {
+	msg := err.Error()
+	for _, s := range []string{fmt.Sprintf("%q", v), fmt.Sprintf("%v", v)} {
+		if s != "" {
+			msg = strings.ReplaceAll(msg, s, RedactedValue)
+		}
+	}
+	return errors.New(msg)
+}
+
+// EmbedParams replaces the values of parameters with an embeddedBy model by
+// their embeddings. All texts to embed with the same model and embedding
+// options, including array elements, are sent in a single batch. The caller's
+// ParamValues are not modified, so they are left intact if any model fails.
 func EmbedParams(ctx context.Context, ps Parameters, paramValues ParamValues, embeddingModelsMap map[string]embeddingmodels.EmbeddingModel, formatter embeddingmodels.VectorFormatter) (ParamValues, error) {
 
 	type ParamToEmbed struct {
 		OriginalValue string
 		Index         int // The index in the original Parameters slice
+		Element       int // The index in the array value, or -1 if the value is not an array
+	}
+
+	// batch holds the parameters embedded in a single call to a model.
+	type batch struct {
+		modelName string
+		opts      embeddingmodels.EmbedOptions
+		params    []ParamToEmbed
+	}
+	// Batches in the order of their first parameter, so that models are called
+	// in a deterministic order.
+	var batches []*batch
+	batchFor := func(modelName string, opts embeddingmodels.EmbedOptions) *batch {
+		for _, b := range batches {
+			if b.modelName == modelName && sameEmbedOptions(b.opts, opts) {
+				return b
+			}
+		}
+		b := &batch{modelName: modelName, opts: opts}
+		batches = append(batches, b)
+		return b
 	}
 
-	// Map: modelName -> list of ParamToEmbed
-	parametersToEmbed := make(map[string][]ParamToEmbed)
-
+	paramValues = slices.Clone(paramValues)
 	for i, p := range ps {
 		modelName := p.GetEmbeddedBy()
 		if modelName == "" {
 			continue
 		}
+		b := batchFor(modelName, p.GetEmbedOptions())
 
-		// Get parameter's value to be embedded
-		valueStr, ok := paramValues[i].Value.(string)
-		if !ok {
+		// Get parameter's value to be embedded. Arrays are embedded element-wise.
+		switch value := paramValues[i].Value.(type) {
+		case nil:
+			// null or omitted optional values have nothing to embed
+			continue
+		case string:
+			b.params = append(b.params, ParamToEmbed{
+				OriginalValue: value,
+				Index:         i,
+				Element:       -1,
+			})
+		case []any:
+			for j, elem := range value {
+				elemStr, ok := elem.(string)
+				if !ok {
+					return nil, fmt.Errorf("parameter '%s' is marked for embedding but element #%d has a non-string value (type: %T)", p.GetName(), j, elem)
+				}
+				b.params = append(b.params, ParamToEmbed{
+					OriginalValue: elemStr,
+					Index:         i,
+					Element:       j,
+				})
+			}
+			// Replace the value with a copy, so that embedding does not modify
+			// the caller's slice.
+			paramValues[i].Value = make([]any, len(value))
+		default:
 			return nil, fmt.Errorf("parameter '%s' is marked for embedding but has a non-string value (type: %T)", p.GetName(), paramValues[i].Value)
 		}
-
-		parametersToEmbed[modelName] = append(parametersToEmbed[modelName], ParamToEmbed{
-			OriginalValue: valueStr,
-			Index:         i,
-		})
 	}
 
 	// Batch embedding request sent to each model
-	for modelName, params := range parametersToEmbed {
+	for _, b := range batches {
+		modelName, params := b.modelName, b.params
+		if len(params) == 0 {
+			continue
+		}
 		model, ok := embeddingModelsMap[modelName]
 		if !ok {
 			return nil, fmt.Errorf("embedding model does not exist: %s", modelName)
@@ -210,7 +404,7 @@ func EmbedParams(ctx context.Context, ps Parameters, paramValues ParamValues, em
 			stringBatch[i] = paramStr.OriginalValue
 		}
 
-		embeddings, err := model.EmbedParameters(ctx, stringBatch)
+		embeddings, err := model.EmbedParameters(ctx, stringBatch, b.opts)
 		if err != nil {
 			return nil, fmt.Errorf("error embedding parameters with model %s: %w", modelName, err)
 		}
@@ -222,23 +416,56 @@ func EmbedParams(ctx context.Context, ps Parameters, paramValues ParamValues, em
 		for i, rawVector := range embeddings {
 
 			item := params[i]
+			if dims := b.opts.OutputDimensionality; dims > 0 && len(rawVector) != int(dims) {
+				return nil, fmt.Errorf("model %s returned an embedding with %d dimensions for parameter '%s', expected %d", modelName, len(rawVector), ps[item.Index].GetName(), dims)
+			}
 
 			// Call vector formatter
 			var finalValue any = rawVector
+			if formatter != nil {
+				finalValue = formatter(rawVector)
+			}
 
-			if formatter == nil {
-				paramValues[item.Index].Value = finalValue
+			if item.Element >= 0 {
+				paramValues[item.Index].Value.([]any)[item.Element] = finalValue
 				continue
 			}
-
-			formattedVector := formatter(rawVector)
-			finalValue = formattedVector
 			paramValues[item.Index].Value = finalValue
 		}
 	}
 	return paramValues, nil
 }
 
+// sameEmbedOptions returns whether a and b embed texts the same way.
+func sameEmbedOptions(a, b embeddingmodels.EmbedOptions) bool {
+	if a.TaskType != b.TaskType || a.OutputDimensionality != b.OutputDimensionality {
+		return false
+	}
+	if a.Truncate == nil || b.Truncate == nil {
+		return a.Truncate == b.Truncate
+	}
+	return *a.Truncate == *b.Truncate
+}
+
+// ValidateEmbedOptions checks that the embedding model of each parameter with
+// an embeddedBy model exists and supports the parameter's embedding options.
+func (ps Parameters) ValidateEmbedOptions(embeddingModelsMap map[string]embeddingmodels.EmbeddingModel) error {
+	for _, p := range ps {
+		modelName := p.GetEmbeddedBy()
+		if modelName == "" {
+			continue
+		}
+		model, ok := embeddingModelsMap[modelName]
+		if !ok {
+			return fmt.Errorf("parameter %q: embedding model does not exist: %s", p.GetName(), modelName)
+		}
+		if err := model.ValidateEmbedOptions(p.GetEmbedOptions()); err != nil {
+			return fmt.Errorf("parameter %q: embedding model %s: %w", p.GetName(), modelName, err)
+		}
+	}
+	return nil
+}
+
 // helper function to convert a string array parameter to a comma separated string
 func ConvertArrayParamToString(param any) (string, error) {
 	switch v := param.(type) {
@@ -315,6 +542,36 @@ func ProcessParameters(templateParams Parameters, params Parameters) (Parameters
 	return allParameters, paramManifest, nil
 }
 
+// ExpandDescriptions replaces the description of each Parameter, including
+// array items and object properties, with the result of expand.
+func (ps Parameters) ExpandDescriptions(expand func(string) (string, error)) error {
+	for _, p := range ps {
+		if err := expandDescription(p, expand); err != nil {
+			return fmt.Errorf("parameter %q: %w", p.GetName(), err)
+		}
+	}
+	return nil
+}
+
+func expandDescription(p Parameter, expand func(string) (string, error)) error {
+	if d, ok := p.(interface{ description() *string }); ok {
+		desc, err := expand(*d.description())
+		if err != nil {
+			return err
+		}
+		*d.description() = desc
+	}
+	switch p := p.(type) {
+	case *ArrayParameter:
+		if p.Items != nil {
+			return expandDescription(p.Items, expand)
+		}
+	case *ObjectParameter:
+		return p.Properties.ExpandDescriptions(expand)
+	}
+	return nil
+}
+
 type Parameter interface {
 	// Note: It's typically not idiomatic to include "Get" in the function name,
 	// but this is done to differentiate it from the fields in CommonParameter.
@@ -324,7 +581,11 @@ type Parameter interface {
 	GetRequired() bool
 	GetAuthServices() []ParamAuthService
 	GetEmbeddedBy() string
+	GetEmbedOptions() embeddingmodels.EmbedOptions
 	GetValueFromParam() string
+	GetNullable() bool
+	GetSensitive() bool
+	GetExamples() []any
 	Parse(any) (any, error)
 	Manifest() ParameterManifest
 	McpManifest() (ParameterMcpManifest, []string)
@@ -370,7 +631,20 @@ func parseParamFromDelayedUnmarshaler(ctx context.Context, u *util.DelayedUnmars
 		return nil, fmt.Errorf("parameter is missing 'type' field")
 	}
 
-	return ParseParameter(ctx, p, t.(string))
+	param, err := ParseParameter(ctx, p, t.(string))
+	if err != nil {
+		return nil, err
+	}
+	if param.GetEmbeddedBy() == "" && param.GetEmbedOptions() != (embeddingmodels.EmbedOptions{}) {
+		return nil, fmt.Errorf("parameter %q can only specify 'taskType', 'outputDimensionality' and 'truncate' with 'embeddedBy'", param.GetName())
+	}
+	if param.GetEmbedOptions().OutputDimensionality < 0 {
+		return nil, fmt.Errorf("parameter %q: 'outputDimensionality' must be positive", param.GetName())
+	}
+	if err := validateExamples(param); err != nil {
+		return nil, fmt.Errorf("invalid parameter %q: %w", param.GetName(), err)
+	}
+	return param, nil
 }
 
 // ParseParameter parses a raw map into a Parameter object based on its "type" field.
@@ -394,6 +668,12 @@ func ParseParameter(ctx context.Context, p map[string]any, paramType string) (Pa
 			a.AuthServices = append(a.AuthServices, a.AuthSources...)
 			a.AuthSources = nil
 		}
+		if err := a.validateEnum(); err != nil {
+			return nil, fmt.Errorf("invalid parameter %q: %w", a.Name, err)
+		}
+		if err := a.validatePattern(); err != nil {
+			return nil, fmt.Errorf("invalid parameter %q: %w", a.Name, err)
+		}
 		return a, nil
 	case TypeInt:
 		a := &IntParameter{}
@@ -408,6 +688,9 @@ func ParseParameter(ctx context.Context, p map[string]any, paramType string) (Pa
 			a.AuthServices = append(a.AuthServices, a.AuthSources...)
 			a.AuthSources = nil
 		}
+		if err := a.validateBounds(); err != nil {
+			return nil, fmt.Errorf("invalid parameter %q: %w", a.Name, err)
+		}
 		return a, nil
 	case TypeFloat:
 		a := &FloatParameter{}
@@ -422,6 +705,9 @@ func ParseParameter(ctx context.Context, p map[string]any, paramType string) (Pa
 			a.AuthServices = append(a.AuthServices, a.AuthSources...)
 			a.AuthSources = nil
 		}
+		if err := a.validateBounds(); err != nil {
+			return nil, fmt.Errorf("invalid parameter %q: %w", a.Name, err)
+		}
 		return a, nil
 	case TypeBool:
 		a := &BooleanParameter{}
@@ -442,8 +728,11 @@ func ParseParameter(ctx context.Context, p map[string]any, paramType string) (Pa
 		if err := dec.DecodeContext(ctx, a); err != nil {
 			return nil, fmt.Errorf("unable to parse as %q: %w", paramType, err)
 		}
-		if a.GetEmbeddedBy() != "" {
-			return nil, fmt.Errorf("parameter type %q cannot specify 'embeddedBy'", paramType)
+		if a.GetEmbeddedBy() != "" && a.Items.GetType() != TypeString {
+			return nil, fmt.Errorf("parameter type %q can only specify 'embeddedBy' for items of type %q", paramType, TypeString)
+		}
+		if err := a.validateDefault(); err != nil {
+			return nil, fmt.Errorf("invalid parameter %q: %w", a.Name, err)
 		}
 		if a.AuthSources != nil {
 			logger.WarnContext(ctx, "`authSources` is deprecated, use `authServices` for parameters instead")
@@ -465,6 +754,42 @@ func ParseParameter(ctx context.Context, p map[string]any, paramType string) (Pa
 			a.AuthSources = nil
 		}
 		return a, nil
+	case TypeTimestamp, TypeDate:
+		a := &DateTimeParameter{}
+		if err := dec.DecodeContext(ctx, a); err != nil {
+			return nil, fmt.Errorf("unable to parse as %q: %w", paramType, err)
+		}
+		if a.GetEmbeddedBy() != "" {
+			return nil, fmt.Errorf("parameter type %q cannot specify 'embeddedBy'", paramType)
+		}
+		if a.Default != nil {
+			if _, err := a.Parse(*a.Default); err != nil {
+				return nil, fmt.Errorf("invalid parameter %q: default value: %w", a.Name, err)
+			}
+		}
+		if a.AuthSources != nil {
+			logger.WarnContext(ctx, "`authSources` is deprecated, use `authServices` for parameters instead")
+			a.AuthServices = append(a.AuthServices, a.AuthSources...)
+			a.AuthSources = nil
+		}
+		return a, nil
+	case TypeObject:
+		a := &ObjectParameter{}
+		if err := dec.DecodeContext(ctx, a); err != nil {
+			return nil, fmt.Errorf("unable to parse as %q: %w", paramType, err)
+		}
+		if a.GetEmbeddedBy() != "" {
+			return nil, fmt.Errorf("parameter type %q cannot specify 'embeddedBy'", paramType)
+		}
+		if err := a.validateDefault(); err != nil {
+			return nil, fmt.Errorf("invalid parameter %q: %w", a.Name, err)
+		}
+		if a.AuthSources != nil {
+			logger.WarnContext(ctx, "`authSources` is deprecated, use `authServices` for parameters instead")
+			a.AuthServices = append(a.AuthServices, a.AuthSources...)
+			a.AuthSources = nil
+		}
+		return a, nil
 	}
 	return nil, fmt.Errorf("%q is not valid type for a parameter", paramType)
 }
@@ -475,7 +800,16 @@ func (ps Parameters) Manifest() []ParameterManifest {
 		if p.GetValueFromParam() != "" {
 			continue
 		}
-		rtn = append(rtn, p.Manifest())
+		m := p.Manifest()
+		m.Nullable = p.GetNullable()
+		m.Examples = p.GetExamples()
+		if p.GetSensitive() {
+			// never expose the default or examples of a sensitive parameter
+			m.Default = nil
+			m.Examples = nil
+			m.Sensitive = true
+		}
+		rtn = append(rtn, m)
 	}
 	return rtn
 }
@@ -492,19 +826,18 @@ func (ps Parameters) McpManifest() (McpToolsSchema, map[string][]string) {
 		}
 
 		name := p.GetName()
-		paramManifest, authParamList := p.McpManifest()
-		defaultV := p.GetDefault()
-		if defaultV != nil {
-			paramManifest.Default = defaultV
+		paramManifest, authParamList := mcpPropertySchema(p)
+		// Authenticated parameters are filled from verified claims, so
+		// clients never provide them and they are left out of the schema.
+		if len(authParamList) > 0 {
+			authParam[name] = authParamList
+			continue
 		}
 		properties[name] = paramManifest
 		// parameters that doesn't have a default value are added to the required field
-		if CheckParamRequired(p.GetRequired(), defaultV) {
+		if CheckParamRequired(p.GetRequired(), p.GetDefault()) {
 			required = append(required, name)
 		}
-		if len(authParamList) > 0 {
-			authParam[name] = authParamList
-		}
 	}
 	return McpToolsSchema{
 		Type:       "object",
@@ -513,27 +846,77 @@ func (ps Parameters) McpManifest() (McpToolsSchema, map[string][]string) {
 	}, authParam
 }
 
+// mcpPropertySchema returns the JSON schema of the values of p, with the
+// annotations shared by every kind of parameter: its default and examples,
+// unless it is sensitive, and whether it is nullable.
+func mcpPropertySchema(p Parameter) (ParameterMcpManifest, []string) {
+	paramManifest, authParamList := p.McpManifest()
+	if defaultV := p.GetDefault(); defaultV != nil && !p.GetSensitive() {
+		paramManifest.Default = defaultV
+	}
+	if examples := p.GetExamples(); len(examples) > 0 && !p.GetSensitive() {
+		paramManifest.Examples = examples
+	}
+	if p.GetSensitive() {
+		// let clients use password-style inputs for sensitive values
+		paramManifest.WriteOnly = true
+		if paramManifest.Type == TypeString && paramManifest.Format == "" {
+			paramManifest.Format = "password"
+		}
+	}
+	if p.GetNullable() {
+		paramManifest.Type = []any{paramManifest.Type, "null"}
+		// enum restricts the values of every type, so null must be listed
+		if len(paramManifest.Enum) > 0 {
+			paramManifest.Enum = append(paramManifest.Enum, nil)
+		}
+	}
+	return paramManifest, authParamList
+}
+
 // ParameterManifest represents parameters when served as part of a ToolManifest.
 type ParameterManifest struct {
-	Name                 string             `json:"name"`
-	Type                 string             `json:"type"`
-	Required             bool               `json:"required"`
-	Description          string             `json:"description"`
-	AuthServices         []string           `json:"authSources"`
-	Items                *ParameterManifest `json:"items,omitempty"`
-	Default              any                `json:"default,omitempty"`
-	AdditionalProperties any                `json:"additionalProperties,omitempty"`
-	EmbeddedBy           string             `json:"embeddedBy,omitempty"`
-	ValueFromParam       string             `json:"valueFromParam,omitempty"`
+	Name                 string              `json:"name"`
+	Type                 string              `json:"type"`
+	Required             bool                `json:"required"`
+	Description          string              `json:"description"`
+	AuthServices         []string            `json:"authSources"`
+	Items                *ParameterManifest  `json:"items,omitempty"`
+	Properties           []ParameterManifest `json:"properties,omitempty"`
+	Default              any                 `json:"default,omitempty"`
+	AdditionalProperties any                 `json:"additionalProperties,omitempty"`
+	EmbeddedBy           string              `json:"embeddedBy,omitempty"`
+	ValueFromParam       string              `json:"valueFromParam,omitempty"`
+	Nullable             bool                `json:"nullable,omitempty"`
+	Sensitive            bool                `json:"sensitive,omitempty"`
+	Enum                 []string            `json:"enum,omitempty"`
+	Pattern              string              `json:"pattern,omitempty"`
+	Format               string              `json:"format,omitempty"`
+	Minimum              any                 `json:"minimum,omitempty"`
+	Maximum              any                 `json:"maximum,omitempty"`
+	ExclusiveMinimum     any                 `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     any                 `json:"exclusiveMaximum,omitempty"`
+	Examples             []any               `json:"examples,omitempty"`
 }
 
 // ParameterMcpManifest represents properties when served as part of a ToolMcpManifest.
 type ParameterMcpManifest struct {
-	Type                 string                `json:"type"`
-	Description          string                `json:"description"`
-	Items                *ParameterMcpManifest `json:"items,omitempty"`
-	Default              any                   `json:"default,omitempty"`
-	AdditionalProperties any                   `json:"additionalProperties,omitempty"`
+	Type                 any                             `json:"type"`
+	Description          string                          `json:"description"`
+	Items                *ParameterMcpManifest           `json:"items,omitempty"`
+	Properties           map[string]ParameterMcpManifest `json:"properties,omitempty"`
+	Required             []string                        `json:"required,omitempty"`
+	Default              any                             `json:"default,omitempty"`
+	AdditionalProperties any                             `json:"additionalProperties,omitempty"`
+	Enum                 []any                           `json:"enum,omitempty"`
+	Pattern              string                          `json:"pattern,omitempty"`
+	Format               string                          `json:"format,omitempty"`
+	WriteOnly            bool                            `json:"writeOnly,omitempty"`
+	Minimum              any                             `json:"minimum,omitempty"`
+	Maximum              any                             `json:"maximum,omitempty"`
+	ExclusiveMinimum     any                             `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     any                             `json:"exclusiveMaximum,omitempty"`
+	Examples             []any                           `json:"examples,omitempty"`
 }
 
 // CommonParameter are default fields that are emebdding in most Parameter implementations. Embedding this stuct will give the object Name() and Type() functions.
@@ -547,7 +930,111 @@ type CommonParameter struct {
 	AuthServices   []ParamAuthService `yaml:"authServices"`
 	AuthSources    []ParamAuthService `yaml:"authSources"` // Deprecated: Kept for compatibility.
 	EmbeddedBy     string             `yaml:"embeddedBy"`
-	ValueFromParam string             `yaml:"valueFromParam"`
+	// TaskType, OutputDimensionality and Truncate configure how the value is
+	// embedded by EmbeddedBy.
+	TaskType             string `yaml:"taskType"`
+	OutputDimensionality int32  `yaml:"outputDimensionality"`
+	Truncate             *bool  `yaml:"truncate"`
+	ValueFromParam       string `yaml:"valueFromParam"`
+	// Nullable allows callers to pass an explicit null, which is kept instead
+	// of being replaced by the default value.
+	Nullable bool `yaml:"nullable"`
+	// Sensitive marks values, such as credentials, that are redacted from logs
+	// and error messages.
+	Sensitive bool `yaml:"sensitive"`
+	// Examples are sample values shown to clients to illustrate the expected
+	// format of the value. They must be valid values of the Parameter.
+	Examples []any `yaml:"examples"`
+	// Aliases are other names the value of the Parameter is accepted under
+	// when it is not given under its name, e.g. the names of a renamed
+	// Parameter. They are set by tools, see AddAliases, and are not shown
+	// to clients.
+	Aliases []string `yaml:"-"`
+}
+
+// GetExamples returns the example values of the Parameter.
+func (p *CommonParameter) GetExamples() []any {
+	return p.Examples
+}
+
+// setExamples sets the example values of the Parameter.
+func (p *CommonParameter) setExamples(examples []any) {
+	p.Examples = examples
+}
+
+// WithExamples sets the example values of p and returns it, e.g.
+// WithExamples(NewStringParameter("dataset", "The dataset."), "sales").
+func WithExamples[P interface {
+	Parameter
+	setExamples([]any)
+}](p P, examples ...any) P {
+	p.setExamples(examples)
+	return p
+}
+
+// GetAliases returns the other names the value of the Parameter is accepted
+// under.
+func (p *CommonParameter) GetAliases() []string {
+	return p.Aliases
+}
+
+// AddAliases adds aliases to the other names the value of p is accepted
+// under, e.g. the former names of a renamed parameter. It returns false if p
+// does not accept aliases.
+func AddAliases(p Parameter, aliases ...string) bool {
+	c, ok := p.(interface{ addAliases([]string) })
+	if ok {
+		c.addAliases(aliases)
+	}
+	return ok
+}
+
+// addAliases adds aliases to the other names the value of the Parameter is
+// accepted under.
+func (p *CommonParameter) addAliases(aliases []string) {
+	p.Aliases = append(p.Aliases, aliases...)
+}
+
+// aliasedValue returns the value of the first alias of p in data, if p has
+// aliases, see AddAliases.
+func aliasedValue(p Parameter, data map[string]any) (any, bool) {
+	aliased, ok := p.(interface{ GetAliases() []string })
+	if !ok {
+		return nil, false
+	}
+	for _, alias := range aliased.GetAliases() {
+		if v, ok := data[alias]; ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// validateExamples checks that the examples of p are valid values of p, i.e.
+// that they satisfy its type, enum, pattern and bounds.
+func validateExamples(p Parameter) error {
+	for i, example := range p.GetExamples() {
+		if _, err := p.Parse(example); err != nil {
+			return fmt.Errorf("example %d is invalid: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// GetSensitive returns whether the Parameter's value must be redacted.
+func (p *CommonParameter) GetSensitive() bool {
+	return p.Sensitive
+}
+
+// GetNullable returns whether the Parameter accepts an explicit null.
+func (p *CommonParameter) GetNullable() bool {
+	return p.Nullable
+}
+
+// description returns a pointer to the Parameter's description, so that it
+// can be expanded in place.
+func (p *CommonParameter) description() *string {
+	return &p.Desc
 }
 
 // GetName returns the name specified for the Parameter.
@@ -610,6 +1097,15 @@ func (p *CommonParameter) GetEmbeddedBy() string {
 	return p.EmbeddedBy
 }
 
+// GetEmbedOptions returns the options for embedding the Parameter's value.
+func (p *CommonParameter) GetEmbedOptions() embeddingmodels.EmbedOptions {
+	return embeddingmodels.EmbedOptions{
+		TaskType:             p.TaskType,
+		OutputDimensionality: p.OutputDimensionality,
+		Truncate:             p.Truncate,
+	}
+}
+
 // GetValueFromParam returns the param value to copy from.
 func (p *CommonParameter) GetValueFromParam() string {
 	return p.ValueFromParam
@@ -630,6 +1126,59 @@ func MatchStringOrRegex(input, target any) bool {
 	return re.MatchString(inputS)
 }
 
+// number is the constraint for the value types of numeric parameters.
+type number interface {
+	int | float64
+}
+
+// checkBounds checks v against the inclusive and exclusive bounds of a
+// numeric parameter. Nil bounds are not checked.
+func checkBounds[T number](v T, minV, maxV, exclusiveMinV, exclusiveMaxV *T) error {
+	if minV != nil && v < *minV {
+		return fmt.Errorf("%v is under the minimum value %v", v, *minV)
+	}
+	if maxV != nil && v > *maxV {
+		return fmt.Errorf("%v is above the maximum value %v", v, *maxV)
+	}
+	if exclusiveMinV != nil && v <= *exclusiveMinV {
+		return fmt.Errorf("%v must be greater than %v", v, *exclusiveMinV)
+	}
+	if exclusiveMaxV != nil && v >= *exclusiveMaxV {
+		return fmt.Errorf("%v must be less than %v", v, *exclusiveMaxV)
+	}
+	return nil
+}
+
+// validateBounds checks that the bounds of a numeric parameter do not
+// contradict each other and that its default value satisfies them.
+func validateBounds[T number](defaultV, minV, maxV, exclusiveMinV, exclusiveMaxV *T) error {
+	lower, upper := minV, maxV
+	if lower == nil {
+		lower = exclusiveMinV
+	}
+	if upper == nil {
+		upper = exclusiveMaxV
+	}
+	if lower != nil && upper != nil && *lower > *upper {
+		return fmt.Errorf("lower bound %v is greater than upper bound %v", *lower, *upper)
+	}
+	if defaultV != nil {
+		if err := checkBounds(*defaultV, minV, maxV, exclusiveMinV, exclusiveMaxV); err != nil {
+			return fmt.Errorf("default value is out of bounds: %w", err)
+		}
+	}
+	return nil
+}
+
+// boundValue returns the value of a bound for use in manifests, or nil if the
+// bound is not set.
+func boundValue[T number](bound *T) any {
+	if bound == nil {
+		return nil
+	}
+	return *bound
+}
+
 // McpManifest returns the MCP manifest for the Parameter.
 func (p *CommonParameter) McpManifest() (ParameterMcpManifest, []string) {
 	authServiceNames := getAuthServiceNames(p.AuthServices)
@@ -740,6 +1289,51 @@ func NewStringParameterWithAllowedValues(name string, desc string, allowedValues
 	}
 }
 
+// NewStringParameterWithEnum is a convenience function for initializing a StringParameter that only accepts the given values.
+func NewStringParameterWithEnum(name string, desc string, enum []string) *StringParameter {
+	return &StringParameter{
+		CommonParameter: CommonParameter{
+			Name:         name,
+			Type:         TypeString,
+			Desc:         desc,
+			AuthServices: nil,
+		},
+		Enum: enum,
+	}
+}
+
+// NewStringParameterWithEnumDefault is a convenience function for initializing a StringParameter that only accepts the given values, with a default value.
+func NewStringParameterWithEnumDefault(name string, defaultV string, desc string, enum []string) *StringParameter {
+	return &StringParameter{
+		CommonParameter: CommonParameter{
+			Name:         name,
+			Type:         TypeString,
+			Desc:         desc,
+			AuthServices: nil,
+		},
+		Default: &defaultV,
+		Enum:    enum,
+	}
+}
+
+// NewStringParameterWithPattern is a convenience function for initializing a StringParameter whose values must match an RE2 pattern.
+// It panics if the pattern does not compile.
+func NewStringParameterWithPattern(name string, desc string, pattern string, patternDesc string) *StringParameter {
+	if _, err := compilePattern(pattern); err != nil {
+		panic(fmt.Sprintf("invalid pattern for parameter %q: %s", name, err))
+	}
+	return &StringParameter{
+		CommonParameter: CommonParameter{
+			Name:         name,
+			Type:         TypeString,
+			Desc:         desc,
+			AuthServices: nil,
+		},
+		Pattern:            pattern,
+		PatternDescription: patternDesc,
+	}
+}
+
 // NewStringParameterWithExcludedValues is a convenience function for initializing a StringParameter with a list of excludedValues
 func NewStringParameterWithExcludedValues(name string, desc string, excludedValues []any) *StringParameter {
 	return &StringParameter{
@@ -760,6 +1354,18 @@ type StringParameter struct {
 	CommonParameter `yaml:",inline"`
 	Default         *string `yaml:"default"`
 	Escape          *string `yaml:"escape"`
+	// Enum lists the only values accepted for the parameter. Unlike
+	// allowedValues, values are matched literally and advertised to clients.
+	Enum []string `yaml:"enum"`
+	// EnumIgnoreCase matches values against Enum case-insensitively. Matched
+	// values are replaced by their spelling in Enum.
+	EnumIgnoreCase bool `yaml:"enumIgnoreCase"`
+	// Pattern is an RE2 regular expression that values must match. Unlike
+	// allowedValues, it is advertised to clients as part of the JSON schema.
+	Pattern string `yaml:"pattern"`
+	// PatternDescription is a human-readable description of Pattern included
+	// in validation errors.
+	PatternDescription string `yaml:"patternDescription"`
 }
 
 // Parse casts the value "v" as a "string".
@@ -768,6 +1374,16 @@ func (p *StringParameter) Parse(v any) (any, error) {
 	if !ok {
 		return nil, &ParseTypeError{p.Name, p.Type, v}
 	}
+	if len(p.Enum) > 0 {
+		enumV, ok := p.matchEnum(newV)
+		if !ok {
+			return nil, fmt.Errorf("%q is not one of the allowed values: %s", newV, quoteEnum(p.Enum))
+		}
+		newV = enumV
+	}
+	if err := p.matchPattern(newV); err != nil {
+		return nil, err
+	}
 	if !p.IsAllowedValues(newV) {
 		return nil, fmt.Errorf("%s is not an allowed value", newV)
 	}
@@ -780,33 +1396,119 @@ func (p *StringParameter) Parse(v any) (any, error) {
 	return newV, nil
 }
 
-func applyEscape(escape, v string) (any, error) {
-	switch escape {
-	case escapeBackticks:
-		return fmt.Sprintf("`%s`", v), nil
-	case escapeDoubleQuotes:
-		return fmt.Sprintf(`"%s"`, v), nil
-	case escapeSingleQuotes:
-		return fmt.Sprintf(`'%s'`, v), nil
-	case escapeSquareBrackets:
-		return fmt.Sprintf("[%s]", v), nil
-	default:
-		return nil, fmt.Errorf("%s is not an allowed escaping delimiter", escape)
+// matchEnum returns the value of Enum matching v.
+func (p *StringParameter) matchEnum(v string) (string, bool) {
+	for _, e := range p.Enum {
+		if e == v || (p.EnumIgnoreCase && strings.EqualFold(e, v)) {
+			return e, true
+		}
 	}
+	return "", false
 }
 
-func (p *StringParameter) GetAuthServices() []ParamAuthService {
-	return p.AuthServices
+// validateEnum checks that the default value of the StringParameter is one of
+// its Enum values.
+func (p *StringParameter) validateEnum() error {
+	if len(p.Enum) == 0 || p.Default == nil {
+		return nil
+	}
+	if _, ok := p.matchEnum(*p.Default); !ok {
+		return fmt.Errorf("default value %q is not one of the allowed values: %s", *p.Default, quoteEnum(p.Enum))
+	}
+	return nil
 }
 
-func (p *StringParameter) GetDefault() any {
-	if p.Default == nil {
-		return nil
+// patterns caches compiled Pattern values, so that each is compiled once.
+var patterns sync.Map
+
+// compilePattern compiles an RE2 pattern, reusing a previously compiled one.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
 	}
-	return *p.Default
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(pattern, re)
+	return re, nil
 }
 
-// Manifest returns the manifest for the StringParameter.
+// matchPattern returns an error if v does not match the Pattern of the
+// StringParameter.
+func (p *StringParameter) matchPattern(v string) error {
+	if p.Pattern == "" {
+		return nil
+	}
+	re, err := compilePattern(p.Pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", p.Pattern, err)
+	}
+	if re.MatchString(v) {
+		return nil
+	}
+	if p.PatternDescription != "" {
+		return fmt.Errorf("%q does not match the pattern %q (%s)", v, p.Pattern, p.PatternDescription)
+	}
+	return fmt.Errorf("%q does not match the pattern %q", v, p.Pattern)
+}
+
+// validatePattern compiles the Pattern of the StringParameter and checks that
+// its default value matches it.
+func (p *StringParameter) validatePattern() error {
+	if p.Pattern == "" {
+		if p.PatternDescription != "" {
+			return fmt.Errorf("'patternDescription' requires 'pattern'")
+		}
+		return nil
+	}
+	if _, err := compilePattern(p.Pattern); err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", p.Pattern, err)
+	}
+	if p.Default == nil {
+		return nil
+	}
+	if err := p.matchPattern(*p.Default); err != nil {
+		return fmt.Errorf("default value: %w", err)
+	}
+	return nil
+}
+
+func quoteEnum(enum []string) string {
+	quoted := make([]string, len(enum))
+	for i, e := range enum {
+		quoted[i] = fmt.Sprintf("%q", e)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func applyEscape(escape, v string) (any, error) {
+	switch escape {
+	case escapeBackticks:
+		return fmt.Sprintf("`%s`", v), nil
+	case escapeDoubleQuotes:
+		return fmt.Sprintf(`"%s"`, v), nil
+	case escapeSingleQuotes:
+		return fmt.Sprintf(`'%s'`, v), nil
+	case escapeSquareBrackets:
+		return fmt.Sprintf("[%s]", v), nil
+	default:
+		return nil, fmt.Errorf("%s is not an allowed escaping delimiter", escape)
+	}
+}
+
+func (p *StringParameter) GetAuthServices() []ParamAuthService {
+	return p.AuthServices
+}
+
+func (p *StringParameter) GetDefault() any {
+	if p.Default == nil {
+		return nil
+	}
+	return *p.Default
+}
+
+// Manifest returns the manifest for the StringParameter.
 func (p *StringParameter) Manifest() ParameterManifest {
 	// only list ParamAuthService names (without fields) in manifest
 	authServiceNames := getAuthServiceNames(p.AuthServices)
@@ -818,7 +1520,24 @@ func (p *StringParameter) Manifest() ParameterManifest {
 		Description:  p.Desc,
 		AuthServices: authServiceNames,
 		Default:      p.GetDefault(),
+		Enum:         p.Enum,
+		Pattern:      p.Pattern,
+	}
+}
+
+// McpManifest returns the MCP manifest for the StringParameter.
+func (p *StringParameter) McpManifest() (ParameterMcpManifest, []string) {
+	authServiceNames := getAuthServiceNames(p.AuthServices)
+	var enum []any
+	for _, e := range p.Enum {
+		enum = append(enum, e)
 	}
+	return ParameterMcpManifest{
+		Type:        p.Type,
+		Description: p.Desc,
+		Enum:        enum,
+		Pattern:     p.Pattern,
+	}, authServiceNames
 }
 
 // NewIntParameter is a convenience function for initializing a IntParameter.
@@ -847,6 +1566,20 @@ func NewIntParameterWithRange(name string, desc string, minValue *int, maxValue
 	}
 }
 
+// NewIntParameterWithExclusiveRange is a convenience function for initializing a IntParameter with exclusive bounds.
+func NewIntParameterWithExclusiveRange(name string, desc string, exclusiveMinValue *int, exclusiveMaxValue *int) *IntParameter {
+	return &IntParameter{
+		CommonParameter: CommonParameter{
+			Name:         name,
+			Type:         TypeInt,
+			Desc:         desc,
+			AuthServices: nil,
+		},
+		ExclusiveMinValue: exclusiveMinValue,
+		ExclusiveMaxValue: exclusiveMaxValue,
+	}
+}
+
 // NewIntParameterWithDefault is a convenience function for initializing a IntParameter with default value.
 func NewIntParameterWithDefault(name string, defaultV int, desc string) *IntParameter {
 	return &IntParameter{
@@ -915,10 +1648,23 @@ var _ Parameter = &IntParameter{}
 
 // IntParameter is a parameter representing the "int" type.
 type IntParameter struct {
-	CommonParameter `yaml:",inline"`
-	Default         *int `yaml:"default"`
-	MinValue        *int `yaml:"minValue"`
-	MaxValue        *int `yaml:"maxValue"`
+	CommonParameter   `yaml:",inline"`
+	Default           *int `yaml:"default"`
+	MinValue          *int `yaml:"minValue"`
+	MaxValue          *int `yaml:"maxValue"`
+	ExclusiveMinValue *int `yaml:"exclusiveMinValue"`
+	ExclusiveMaxValue *int `yaml:"exclusiveMaxValue"`
+	// Coerce accepts integers sent as strings, such as "5".
+	Coerce bool `yaml:"coerce"`
+}
+
+func (p *IntParameter) coerce(v any) (any, bool) {
+	if s, ok := v.(string); ok && p.Coerce {
+		if out, ok := coerceString(p.Type, s); ok {
+			return out, true
+		}
+	}
+	return v, false
 }
 
 func (p *IntParameter) Parse(v any) (any, error) {
@@ -932,6 +1678,12 @@ func (p *IntParameter) Parse(v any) (any, error) {
 		out = int(newV)
 	case int64:
 		out = int(newV)
+	case uint64:
+		// YAML decodes positive integers, e.g. in array defaults, as uint64.
+		if newV > math.MaxInt {
+			return nil, &ParseTypeError{p.Name, p.Type, v}
+		}
+		out = int(newV)
 	case json.Number:
 		newI, err := newV.Int64()
 		if err != nil {
@@ -945,15 +1697,18 @@ func (p *IntParameter) Parse(v any) (any, error) {
 	if p.IsExcludedValues(out) {
 		return nil, fmt.Errorf("%d is an excluded value", out)
 	}
-	if p.MinValue != nil && out < *p.MinValue {
-		return nil, fmt.Errorf("%d is under the minimum value", out)
-	}
-	if p.MaxValue != nil && out > *p.MaxValue {
-		return nil, fmt.Errorf("%d is above the maximum value", out)
+	if err := checkBounds(out, p.MinValue, p.MaxValue, p.ExclusiveMinValue, p.ExclusiveMaxValue); err != nil {
+		return nil, err
 	}
 	return out, nil
 }
 
+// validateBounds checks that the bounds of the IntParameter are consistent
+// and that its default value satisfies them.
+func (p *IntParameter) validateBounds() error {
+	return validateBounds(p.Default, p.MinValue, p.MaxValue, p.ExclusiveMinValue, p.ExclusiveMaxValue)
+}
+
 func (p *IntParameter) GetAuthServices() []ParamAuthService {
 	return p.AuthServices
 }
@@ -971,15 +1726,32 @@ func (p *IntParameter) Manifest() ParameterManifest {
 	authServiceNames := getAuthServiceNames(p.AuthServices)
 	r := CheckParamRequired(p.GetRequired(), p.GetDefault())
 	return ParameterManifest{
-		Name:         p.Name,
-		Type:         p.Type,
-		Required:     r,
-		Description:  p.Desc,
-		AuthServices: authServiceNames,
-		Default:      p.GetDefault(),
+		Name:             p.Name,
+		Type:             p.Type,
+		Required:         r,
+		Description:      p.Desc,
+		AuthServices:     authServiceNames,
+		Default:          p.GetDefault(),
+		Minimum:          boundValue(p.MinValue),
+		Maximum:          boundValue(p.MaxValue),
+		ExclusiveMinimum: boundValue(p.ExclusiveMinValue),
+		ExclusiveMaximum: boundValue(p.ExclusiveMaxValue),
 	}
 }
 
+// McpManifest returns the MCP manifest for the IntParameter.
+func (p *IntParameter) McpManifest() (ParameterMcpManifest, []string) {
+	authServiceNames := getAuthServiceNames(p.AuthServices)
+	return ParameterMcpManifest{
+		Type:             p.Type,
+		Description:      p.Desc,
+		Minimum:          boundValue(p.MinValue),
+		Maximum:          boundValue(p.MaxValue),
+		ExclusiveMinimum: boundValue(p.ExclusiveMinValue),
+		ExclusiveMaximum: boundValue(p.ExclusiveMaxValue),
+	}, authServiceNames
+}
+
 // NewFloatParameter is a convenience function for initializing a FloatParameter.
 func NewFloatParameter(name string, desc string) *FloatParameter {
 	return &FloatParameter{
@@ -1006,6 +1778,20 @@ func NewFloatParameterWithRange(name string, desc string, minValue *float64, max
 	}
 }
 
+// NewFloatParameterWithExclusiveRange is a convenience function for initializing a FloatParameter with exclusive bounds.
+func NewFloatParameterWithExclusiveRange(name string, desc string, exclusiveMinValue *float64, exclusiveMaxValue *float64) *FloatParameter {
+	return &FloatParameter{
+		CommonParameter: CommonParameter{
+			Name:         name,
+			Type:         TypeFloat,
+			Desc:         desc,
+			AuthServices: nil,
+		},
+		ExclusiveMinValue: exclusiveMinValue,
+		ExclusiveMaxValue: exclusiveMaxValue,
+	}
+}
+
 // NewFloatParameterWithDefault is a convenience function for initializing a FloatParameter with default value.
 func NewFloatParameterWithDefault(name string, defaultV float64, desc string) *FloatParameter {
 	return &FloatParameter{
@@ -1074,10 +1860,23 @@ var _ Parameter = &FloatParameter{}
 
 // FloatParameter is a parameter representing the "float" type.
 type FloatParameter struct {
-	CommonParameter `yaml:",inline"`
-	Default         *float64 `yaml:"default"`
-	MinValue        *float64 `yaml:"minValue"`
-	MaxValue        *float64 `yaml:"maxValue"`
+	CommonParameter   `yaml:",inline"`
+	Default           *float64 `yaml:"default"`
+	MinValue          *float64 `yaml:"minValue"`
+	MaxValue          *float64 `yaml:"maxValue"`
+	ExclusiveMinValue *float64 `yaml:"exclusiveMinValue"`
+	ExclusiveMaxValue *float64 `yaml:"exclusiveMaxValue"`
+	// Coerce accepts numbers sent as strings, such as "1.5".
+	Coerce bool `yaml:"coerce"`
+}
+
+func (p *FloatParameter) coerce(v any) (any, bool) {
+	if s, ok := v.(string); ok && p.Coerce {
+		if out, ok := coerceString(p.Type, s); ok {
+			return out, true
+		}
+	}
+	return v, false
 }
 
 func (p *FloatParameter) Parse(v any) (any, error) {
@@ -1089,6 +1888,13 @@ func (p *FloatParameter) Parse(v any) (any, error) {
 		out = float64(newV)
 	case float64:
 		out = newV
+	case int:
+		out = float64(newV)
+	case int64:
+		out = float64(newV)
+	case uint64:
+		// YAML decodes positive integers, e.g. in array defaults, as uint64.
+		out = float64(newV)
 	case json.Number:
 		newI, err := newV.Float64()
 		if err != nil {
@@ -1102,15 +1908,18 @@ func (p *FloatParameter) Parse(v any) (any, error) {
 	if p.IsExcludedValues(out) {
 		return nil, fmt.Errorf("%g is an excluded value", out)
 	}
-	if p.MinValue != nil && out < *p.MinValue {
-		return nil, fmt.Errorf("%g is under the minimum value", out)
-	}
-	if p.MaxValue != nil && out > *p.MaxValue {
-		return nil, fmt.Errorf("%g is above the maximum value", out)
+	if err := checkBounds(out, p.MinValue, p.MaxValue, p.ExclusiveMinValue, p.ExclusiveMaxValue); err != nil {
+		return nil, err
 	}
 	return out, nil
 }
 
+// validateBounds checks that the bounds of the FloatParameter are consistent
+// and that its default value satisfies them.
+func (p *FloatParameter) validateBounds() error {
+	return validateBounds(p.Default, p.MinValue, p.MaxValue, p.ExclusiveMinValue, p.ExclusiveMaxValue)
+}
+
 func (p *FloatParameter) GetAuthServices() []ParamAuthService {
 	return p.AuthServices
 }
@@ -1128,12 +1937,16 @@ func (p *FloatParameter) Manifest() ParameterManifest {
 	authServiceNames := getAuthServiceNames(p.AuthServices)
 	r := CheckParamRequired(p.GetRequired(), p.GetDefault())
 	return ParameterManifest{
-		Name:         p.Name,
-		Type:         p.Type,
-		Required:     r,
-		Description:  p.Desc,
-		AuthServices: authServiceNames,
-		Default:      p.GetDefault(),
+		Name:             p.Name,
+		Type:             p.Type,
+		Required:         r,
+		Description:      p.Desc,
+		AuthServices:     authServiceNames,
+		Default:          p.GetDefault(),
+		Minimum:          boundValue(p.MinValue),
+		Maximum:          boundValue(p.MaxValue),
+		ExclusiveMinimum: boundValue(p.ExclusiveMinValue),
+		ExclusiveMaximum: boundValue(p.ExclusiveMaxValue),
 	}
 }
 
@@ -1142,8 +1955,12 @@ func (p *FloatParameter) Manifest() ParameterManifest {
 func (p *FloatParameter) McpManifest() (ParameterMcpManifest, []string) {
 	authServiceNames := getAuthServiceNames(p.AuthServices)
 	return ParameterMcpManifest{
-		Type:        "number",
-		Description: p.Desc,
+		Type:             "number",
+		Description:      p.Desc,
+		Minimum:          boundValue(p.MinValue),
+		Maximum:          boundValue(p.MaxValue),
+		ExclusiveMinimum: boundValue(p.ExclusiveMinValue),
+		ExclusiveMaximum: boundValue(p.ExclusiveMaxValue),
 	}, authServiceNames
 }
 
@@ -1229,6 +2046,17 @@ var _ Parameter = &BooleanParameter{}
 type BooleanParameter struct {
 	CommonParameter `yaml:",inline"`
 	Default         *bool `yaml:"default"`
+	// Coerce accepts "true" and "false" sent as strings, in any case.
+	Coerce bool `yaml:"coerce"`
+}
+
+func (p *BooleanParameter) coerce(v any) (any, bool) {
+	if s, ok := v.(string); ok && p.Coerce {
+		if out, ok := coerceString(p.Type, s); ok {
+			return out, true
+		}
+	}
+	return v, false
 }
 
 func (p *BooleanParameter) Parse(v any) (any, error) {
@@ -1360,6 +2188,50 @@ type ArrayParameter struct {
 	CommonParameter `yaml:",inline"`
 	Default         *[]any    `yaml:"default"`
 	Items           Parameter `yaml:"items"`
+	// Coerce accepts arrays sent as a JSON string, such as "[1, 2]", and
+	// elements sent as strings when the items are integers, floats or
+	// booleans.
+	Coerce bool `yaml:"coerce"`
+}
+
+func (p *ArrayParameter) coerce(v any) (any, bool) {
+	if !p.Coerce {
+		return v, false
+	}
+	coerced := false
+	if s, ok := v.(string); ok {
+		d := json.NewDecoder(strings.NewReader(s))
+		d.UseNumber()
+		var arr []any
+		if err := d.Decode(&arr); err != nil || d.More() {
+			return v, false
+		}
+		v, coerced = arr, true
+	}
+	arr, ok := v.([]any)
+	if !ok || p.Items == nil {
+		return v, coerced
+	}
+	var out []any
+	for i, elem := range arr {
+		s, ok := elem.(string)
+		if !ok {
+			continue
+		}
+		newElem, ok := coerceString(p.Items.GetType(), s)
+		if !ok {
+			continue
+		}
+		if out == nil {
+			// copy the array, so that the caller's input is not modified
+			out = slices.Clone(arr)
+		}
+		out[i] = newElem
+	}
+	if out == nil {
+		return v, coerced
+	}
+	return out, true
 }
 
 func (p *ArrayParameter) UnmarshalYAML(ctx context.Context, unmarshal func(interface{}) error) error {
@@ -1367,12 +2239,14 @@ func (p *ArrayParameter) UnmarshalYAML(ctx context.Context, unmarshal func(inter
 		CommonParameter `yaml:",inline"`
 		Default         *[]any                  `yaml:"default"`
 		Items           util.DelayedUnmarshaler `yaml:"items"`
+		Coerce          bool                    `yaml:"coerce"`
 	}
 	if err := unmarshal(&rawItem); err != nil {
 		return err
 	}
 	p.CommonParameter = rawItem.CommonParameter
 	p.Default = rawItem.Default
+	p.Coerce = rawItem.Coerce
 	i, err := parseParamFromDelayedUnmarshaler(ctx, &rawItem.Items)
 	if err != nil {
 		return fmt.Errorf("unable to parse 'items' field: %w", err)
@@ -1414,7 +2288,7 @@ func (p *ArrayParameter) IsExcludedValues(v []any) bool {
 func (p *ArrayParameter) Parse(v any) (any, error) {
 	arrVal, ok := v.([]any)
 	if !ok {
-		return nil, &ParseTypeError{p.Name, p.Type, arrVal}
+		return nil, &ParseTypeError{p.Name, p.Type, v}
 	}
 	if !p.IsAllowedValues(arrVal) {
 		return nil, fmt.Errorf("%s is not an allowed value", arrVal)
@@ -1433,6 +2307,20 @@ func (p *ArrayParameter) Parse(v any) (any, error) {
 	return rtn, nil
 }
 
+// validateDefault checks that every element of the default value of the
+// ArrayParameter is a valid item.
+func (p *ArrayParameter) validateDefault() error {
+	if p.Default == nil {
+		return nil
+	}
+	for idx, val := range *p.Default {
+		if _, err := p.Items.Parse(val); err != nil {
+			return fmt.Errorf("default value: unable to parse element #%d: %w", idx, err)
+		}
+	}
+	return nil
+}
+
 func (p *ArrayParameter) GetAuthServices() []ParamAuthService {
 	return p.AuthServices
 }
@@ -1471,7 +2359,7 @@ func (p *ArrayParameter) Manifest() ParameterManifest {
 func (p *ArrayParameter) McpManifest() (ParameterMcpManifest, []string) {
 	// only list ParamAuthService names (without fields) in manifest
 	authServiceNames := getAuthServiceNames(p.AuthServices)
-	items, _ := p.Items.McpManifest()
+	items, _ := mcpPropertySchema(p.Items)
 	return ParameterMcpManifest{
 		Type:        p.Type,
 		Description: p.Desc,
@@ -1729,12 +2617,13 @@ func (p *MapParameter) McpManifest() (ParameterMcpManifest, []string) {
 	authServiceNames := getAuthServiceNames(p.AuthServices)
 	var additionalProperties any
 	if p.ValueType != "" {
-		_, err := getPrototypeParameter(p.ValueType)
+		prototype, err := getPrototypeParameter(p.ValueType)
 		if err != nil {
 			panic(err)
 		}
-		valueSchema := map[string]any{"type": p.ValueType}
-		additionalProperties = valueSchema
+		// use the JSON schema type of the values, e.g. "number" for "float"
+		valueSchema, _ := prototype.McpManifest()
+		additionalProperties = map[string]any{"type": valueSchema.Type}
 	} else {
 		// If no valueType is given, allow any properties.
 		additionalProperties = true
@@ -1746,3 +2635,409 @@ func (p *MapParameter) McpManifest() (ParameterMcpManifest, []string) {
 		AdditionalProperties: additionalProperties,
 	}, authServiceNames
 }
+
+// ObjectParameter is a parameter representing a JSON object with declared
+// properties. Each property is itself a parameter, validated recursively when
+// the object is parsed. Properties that are not declared are rejected unless
+// AdditionalProperties is set.
+type ObjectParameter struct {
+	CommonParameter      `yaml:",inline"`
+	Default              *map[string]any `yaml:"default"`
+	Properties           Parameters      `yaml:"properties"`
+	AdditionalProperties bool            `yaml:"additionalProperties"`
+}
+
+// Ensure ObjectParameter implements the Parameter interface.
+var _ Parameter = &ObjectParameter{}
+
+// NewObjectParameter is a convenience function for initializing an ObjectParameter.
+func NewObjectParameter(name string, desc string, properties Parameters) *ObjectParameter {
+	return &ObjectParameter{
+		CommonParameter: CommonParameter{
+			Name:         name,
+			Type:         TypeObject,
+			Desc:         desc,
+			AuthServices: nil,
+		},
+		Properties: properties,
+	}
+}
+
+// NewObjectParameterWithDefault is a convenience function for initializing an ObjectParameter with default value.
+func NewObjectParameterWithDefault(name string, defaultV map[string]any, desc string, properties Parameters) *ObjectParameter {
+	return &ObjectParameter{
+		CommonParameter: CommonParameter{
+			Name:         name,
+			Type:         TypeObject,
+			Desc:         desc,
+			AuthServices: nil,
+		},
+		Default:    &defaultV,
+		Properties: properties,
+	}
+}
+
+// NewObjectParameterWithRequired is a convenience function for initializing an ObjectParameter.
+func NewObjectParameterWithRequired(name string, desc string, required bool, properties Parameters) *ObjectParameter {
+	return &ObjectParameter{
+		CommonParameter: CommonParameter{
+			Name:         name,
+			Type:         TypeObject,
+			Desc:         desc,
+			Required:     &required,
+			AuthServices: nil,
+		},
+		Properties: properties,
+	}
+}
+
+// UnmarshalYAML handles parsing the ObjectParameter from YAML input.
+func (p *ObjectParameter) UnmarshalYAML(ctx context.Context, unmarshal func(interface{}) error) error {
+	var rawItem struct {
+		CommonParameter      `yaml:",inline"`
+		Default              *map[string]any `yaml:"default"`
+		Properties           Parameters      `yaml:"properties"`
+		AdditionalProperties bool            `yaml:"additionalProperties"`
+	}
+	if err := unmarshal(&rawItem); err != nil {
+		return err
+	}
+	names := make(map[string]bool, len(rawItem.Properties))
+	for _, prop := range rawItem.Properties {
+		name := prop.GetName()
+		if names[name] {
+			return fmt.Errorf("duplicate property %q", name)
+		}
+		names[name] = true
+		if len(prop.GetAuthServices()) != 0 {
+			return fmt.Errorf("property %q: nested properties should not have auth services", name)
+		}
+		if prop.GetValueFromParam() != "" {
+			return fmt.Errorf("property %q: nested properties should not have 'valueFromParam'", name)
+		}
+		if prop.GetEmbeddedBy() != "" {
+			return fmt.Errorf("property %q: nested properties should not have 'embeddedBy'", name)
+		}
+	}
+	p.CommonParameter = rawItem.CommonParameter
+	p.Default = rawItem.Default
+	p.Properties = rawItem.Properties
+	p.AdditionalProperties = rawItem.AdditionalProperties
+	return nil
+}
+
+func (p *ObjectParameter) IsAllowedValues(v map[string]any) bool {
+	a := p.GetAllowedValues()
+	if len(a) == 0 {
+		return true
+	}
+	for _, av := range a {
+		if reflect.DeepEqual(v, av) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *ObjectParameter) IsExcludedValues(v map[string]any) bool {
+	a := p.GetExcludedValues()
+	if len(a) == 0 {
+		return false
+	}
+	for _, av := range a {
+		if reflect.DeepEqual(v, av) {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse validates and parses an incoming value for the object parameter.
+// Missing properties take their default value, if any. Optional properties
+// without a default are left out of the result.
+func (p *ObjectParameter) Parse(v any) (any, error) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, &ParseTypeError{p.Name, p.Type, v}
+	}
+	declared := make(map[string]bool, len(p.Properties))
+	for _, prop := range p.Properties {
+		declared[prop.GetName()] = true
+	}
+
+	rtn := make(map[string]any, len(m))
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		if declared[key] {
+			continue
+		}
+		if !p.AdditionalProperties {
+			return nil, fmt.Errorf("unknown property %q", key)
+		}
+		val, err := util.ConvertNumbers(m[key])
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse property %q: %w", key, err)
+		}
+		rtn[key] = val
+	}
+
+	for _, prop := range p.Properties {
+		name := prop.GetName()
+		val, ok := m[name]
+		if ok && val == nil && prop.GetNullable() {
+			rtn[name] = nil
+			continue
+		}
+		if !ok || val == nil {
+			val = prop.GetDefault()
+			if CheckParamRequired(prop.GetRequired(), val) {
+				return nil, fmt.Errorf("property %q is required", name)
+			}
+			if val == nil {
+				continue
+			}
+		}
+		parsed, err := prop.Parse(val)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse property %q: %w", name, err)
+		}
+		rtn[name] = parsed
+	}
+
+	if !p.IsAllowedValues(rtn) {
+		return nil, fmt.Errorf("%s is not an allowed value", rtn)
+	}
+	if p.IsExcludedValues(rtn) {
+		return nil, fmt.Errorf("%s is an excluded value", rtn)
+	}
+	return rtn, nil
+}
+
+// validateDefault checks that the default value of the ObjectParameter is a
+// valid object.
+func (p *ObjectParameter) validateDefault() error {
+	if p.Default == nil {
+		return nil
+	}
+	if _, err := p.Parse(*p.Default); err != nil {
+		return fmt.Errorf("default value: %w", err)
+	}
+	return nil
+}
+
+func (p *ObjectParameter) GetAuthServices() []ParamAuthService {
+	return p.AuthServices
+}
+
+func (p *ObjectParameter) GetDefault() any {
+	if p.Default == nil {
+		return nil
+	}
+	return *p.Default
+}
+
+// GetProperties returns the declared properties of the ObjectParameter.
+func (p *ObjectParameter) GetProperties() Parameters {
+	return p.Properties
+}
+
+// Manifest returns the manifest for the ObjectParameter.
+func (p *ObjectParameter) Manifest() ParameterManifest {
+	authServiceNames := getAuthServiceNames(p.AuthServices)
+	r := CheckParamRequired(p.GetRequired(), p.GetDefault())
+	return ParameterManifest{
+		Name:                 p.Name,
+		Type:                 p.Type,
+		Required:             r,
+		Description:          p.Desc,
+		AuthServices:         authServiceNames,
+		Properties:           p.Properties.Manifest(),
+		AdditionalProperties: p.AdditionalProperties,
+		Default:              p.GetDefault(),
+	}
+}
+
+// McpManifest returns the MCP manifest for the ObjectParameter.
+func (p *ObjectParameter) McpManifest() (ParameterMcpManifest, []string) {
+	authServiceNames := getAuthServiceNames(p.AuthServices)
+	schema, _ := p.Properties.McpManifest()
+	return ParameterMcpManifest{
+		Type:                 p.Type,
+		Description:          p.Desc,
+		Properties:           schema.Properties,
+		Required:             schema.Required,
+		AdditionalProperties: p.AdditionalProperties,
+	}, authServiceNames
+}
+
+// dateTimeFormat is a layout accepted by a DateTimeParameter, with the name
+// used to describe it in errors.
+type dateTimeFormat struct {
+	name   string
+	layout string
+}
+
+var (
+	timestampFormats        = []dateTimeFormat{{"RFC3339", time.RFC3339Nano}}
+	lenientTimestampFormats = []dateTimeFormat{
+		{"2006-01-02T15:04:05", "2006-01-02T15:04:05"},
+		{"2006-01-02 15:04:05", time.DateTime},
+		{"2006-01-02 15:04:05Z07:00", "2006-01-02 15:04:05Z07:00"},
+		{"2006-01-02", time.DateOnly},
+	}
+	dateFormats        = []dateTimeFormat{{"2006-01-02", time.DateOnly}}
+	lenientDateFormats = []dateTimeFormat{
+		{"2006/01/02", "2006/01/02"},
+		{"20060102", "20060102"},
+	}
+)
+
+// NewTimestampParameter is a convenience function for initializing a timestamp DateTimeParameter.
+func NewTimestampParameter(name string, desc string) *DateTimeParameter {
+	return &DateTimeParameter{
+		CommonParameter: CommonParameter{
+			Name:         name,
+			Type:         TypeTimestamp,
+			Desc:         desc,
+			AuthServices: nil,
+		},
+	}
+}
+
+// NewTimestampParameterWithRequired is a convenience function for initializing a timestamp DateTimeParameter.
+func NewTimestampParameterWithRequired(name string, desc string, required bool) *DateTimeParameter {
+	return &DateTimeParameter{
+		CommonParameter: CommonParameter{
+			Name:         name,
+			Type:         TypeTimestamp,
+			Desc:         desc,
+			Required:     &required,
+			AuthServices: nil,
+		},
+	}
+}
+
+// NewDateParameter is a convenience function for initializing a date DateTimeParameter.
+func NewDateParameter(name string, desc string) *DateTimeParameter {
+	return &DateTimeParameter{
+		CommonParameter: CommonParameter{
+			Name:         name,
+			Type:         TypeDate,
+			Desc:         desc,
+			AuthServices: nil,
+		},
+	}
+}
+
+// NewDateParameterWithDefault is a convenience function for initializing a date DateTimeParameter with default value.
+func NewDateParameterWithDefault(name string, defaultV string, desc string) *DateTimeParameter {
+	return &DateTimeParameter{
+		CommonParameter: CommonParameter{
+			Name:         name,
+			Type:         TypeDate,
+			Desc:         desc,
+			AuthServices: nil,
+		},
+		Default: &defaultV,
+	}
+}
+
+var _ Parameter = &DateTimeParameter{}
+
+// DateTimeParameter is a parameter representing the "timestamp" and "date"
+// types. Values are passed as strings and normalized when parsed: timestamps
+// to a time.Time in UTC, and dates to a "YYYY-MM-DD" string.
+type DateTimeParameter struct {
+	CommonParameter `yaml:",inline"`
+	Default         *string `yaml:"default"`
+	// Lenient also accepts a few common formats besides RFC3339 timestamps
+	// and "YYYY-MM-DD" dates. Timestamps without a time zone are read as UTC.
+	Lenient bool `yaml:"lenient"`
+}
+
+// formats returns the formats accepted by the DateTimeParameter.
+func (p *DateTimeParameter) formats() []dateTimeFormat {
+	if p.Type == TypeDate {
+		if p.Lenient {
+			return slices.Concat(dateFormats, lenientDateFormats)
+		}
+		return dateFormats
+	}
+	if p.Lenient {
+		return slices.Concat(timestampFormats, lenientTimestampFormats)
+	}
+	return timestampFormats
+}
+
+// Parse parses the value "v" as a timestamp or a date.
+func (p *DateTimeParameter) Parse(v any) (any, error) {
+	newV, ok := v.(string)
+	if !ok {
+		return nil, &ParseTypeError{p.Name, p.Type, v}
+	}
+	formats := p.formats()
+	for _, f := range formats {
+		t, err := time.Parse(f.layout, newV)
+		if err != nil {
+			continue
+		}
+		if p.Type == TypeDate {
+			return t.Format(time.DateOnly), nil
+		}
+		return t.UTC(), nil
+	}
+	names := make([]string, len(formats))
+	for i, f := range formats {
+		names[i] = fmt.Sprintf("%q", f.name)
+	}
+	return nil, fmt.Errorf("%q is not a valid %s, expected one of the formats: %s", newV, p.Type, strings.Join(names, ", "))
+}
+
+func (p *DateTimeParameter) GetAuthServices() []ParamAuthService {
+	return p.AuthServices
+}
+
+func (p *DateTimeParameter) GetDefault() any {
+	if p.Default == nil {
+		return nil
+	}
+	return *p.Default
+}
+
+// schemaFormat returns the JSON schema format of the DateTimeParameter.
+func (p *DateTimeParameter) schemaFormat() string {
+	if p.Type == TypeDate {
+		return "date"
+	}
+	return "date-time"
+}
+
+// Manifest returns the manifest for the DateTimeParameter. Values are sent as
+// strings, so the type is "string" with the JSON schema format of the value.
+func (p *DateTimeParameter) Manifest() ParameterManifest {
+	authServiceNames := getAuthServiceNames(p.AuthServices)
+	r := CheckParamRequired(p.GetRequired(), p.GetDefault())
+	return ParameterManifest{
+		Name:         p.Name,
+		Type:         TypeString,
+		Required:     r,
+		Description:  p.Desc,
+		AuthServices: authServiceNames,
+		Default:      p.GetDefault(),
+		Format:       p.schemaFormat(),
+	}
+}
+
+// McpManifest returns the MCP manifest for the DateTimeParameter.
+func (p *DateTimeParameter) McpManifest() (ParameterMcpManifest, []string) {
+	authServiceNames := getAuthServiceNames(p.AuthServices)
+	return ParameterMcpManifest{
+		Type:        TypeString,
+		Description: p.Desc,
+		Format:      p.schemaFormat(),
+	}, authServiceNames
+}
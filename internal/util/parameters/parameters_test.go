@@ -16,16 +16,21 @@ package parameters_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"math"
 	"reflect"
 	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/goccy/go-yaml"
 	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/embeddingmodels"
 	"github.com/googleapis/genai-toolbox/internal/testutils"
+	"github.com/googleapis/genai-toolbox/internal/util"
 	"github.com/googleapis/genai-toolbox/internal/util/parameters"
 )
 
@@ -66,6 +71,36 @@ func TestParametersMarshal(t *testing.T) {
 				parameters.NewStringParameterWithRequired("my_string", "this param is a string", false),
 			},
 		},
+		{
+			name: "string with enum",
+			in: []map[string]any{
+				{
+					"name":        "my_string",
+					"type":        "string",
+					"description": "this param is a string",
+					"default":     "json",
+					"enum":        []string{"json", "markdown"},
+				},
+			},
+			want: parameters.Parameters{
+				parameters.NewStringParameterWithEnumDefault("my_string", "json", "this param is a string", []string{"json", "markdown"}),
+			},
+		},
+		{
+			name: "string with pattern",
+			in: []map[string]any{
+				{
+					"name":               "my_string",
+					"type":               "string",
+					"description":        "this param is a string",
+					"pattern":            "^[a-z]+$",
+					"patternDescription": "lowercase letters",
+				},
+			},
+			want: parameters.Parameters{
+				parameters.NewStringParameterWithPattern("my_string", "this param is a string", "^[a-z]+$", "lowercase letters"),
+			},
+		},
 		{
 			name: "int",
 			in: []map[string]any{
@@ -970,6 +1005,105 @@ func TestParametersParse(t *testing.T) {
 				"my_int": 3,
 			},
 		},
+		{
+			name: "string enum",
+			params: parameters.Parameters{
+				parameters.NewStringParameterWithEnum("my_string", "this param is a string", []string{"ASC", "DESC"}),
+			},
+			in: map[string]any{
+				"my_string": "DESC",
+			},
+			want: parameters.ParamValues{parameters.ParamValue{Name: "my_string", Value: "DESC"}},
+		},
+		{
+			name: "string enum disallow",
+			params: parameters.Parameters{
+				parameters.NewStringParameterWithEnum("my_string", "this param is a string", []string{"ASC", "DESC"}),
+			},
+			in: map[string]any{
+				"my_string": "desc",
+			},
+		},
+		{
+			name: "string enum is not a regex",
+			params: parameters.Parameters{
+				parameters.NewStringParameterWithEnum("my_string", "this param is a string", []string{"A.C"}),
+			},
+			in: map[string]any{
+				"my_string": "ABC",
+			},
+		},
+		{
+			name: "string pattern",
+			params: parameters.Parameters{
+				parameters.NewStringParameterWithPattern("my_string", "this param is a string", "^[a-z_]+$", ""),
+			},
+			in: map[string]any{
+				"my_string": "my_agent",
+			},
+			want: parameters.ParamValues{parameters.ParamValue{Name: "my_string", Value: "my_agent"}},
+		},
+		{
+			name: "string pattern disallow",
+			params: parameters.Parameters{
+				parameters.NewStringParameterWithPattern("my_string", "this param is a string", "^[a-z_]+$", ""),
+			},
+			in: map[string]any{
+				"my_string": "projects/p",
+			},
+		},
+		{
+			name: "string enum ignore case",
+			params: parameters.Parameters{
+				&parameters.StringParameter{
+					CommonParameter: parameters.CommonParameter{Name: "my_string", Type: "string", Desc: "this param is a string"},
+					Enum:            []string{"ASC", "DESC"},
+					EnumIgnoreCase:  true,
+				},
+			},
+			in: map[string]any{
+				"my_string": "desc",
+			},
+			want: parameters.ParamValues{parameters.ParamValue{Name: "my_string", Value: "DESC"}},
+		},
+		{
+			name: "int minValue boundary",
+			params: parameters.Parameters{
+				parameters.NewIntParameterWithRange("my_int", "this param is an int", &intValue, &intValue),
+			},
+			in: map[string]any{
+				"my_int": 2,
+			},
+			want: parameters.ParamValues{parameters.ParamValue{Name: "my_int", Value: 2}},
+		},
+		{
+			name: "int exclusiveMinValue",
+			params: parameters.Parameters{
+				parameters.NewIntParameterWithExclusiveRange("my_int", "this param is an int", &intValue, nil),
+			},
+			in: map[string]any{
+				"my_int": 3,
+			},
+			want: parameters.ParamValues{parameters.ParamValue{Name: "my_int", Value: 3}},
+		},
+		{
+			name: "int exclusiveMinValue boundary disallow",
+			params: parameters.Parameters{
+				parameters.NewIntParameterWithExclusiveRange("my_int", "this param is an int", &intValue, nil),
+			},
+			in: map[string]any{
+				"my_int": 2,
+			},
+		},
+		{
+			name: "int exclusiveMaxValue boundary disallow",
+			params: parameters.Parameters{
+				parameters.NewIntParameterWithExclusiveRange("my_int", "this param is an int", nil, &intValue),
+			},
+			in: map[string]any{
+				"my_int": 2,
+			},
+		},
 		{
 			name: "float",
 			params: parameters.Parameters{
@@ -1104,6 +1238,25 @@ func TestParametersParse(t *testing.T) {
 				"my_float": 1.8,
 			},
 		},
+		{
+			name: "float exclusiveMaxValue",
+			params: parameters.Parameters{
+				parameters.NewFloatParameterWithExclusiveRange("my_float", "this param is a float", nil, &floatValue),
+			},
+			in: map[string]any{
+				"my_float": 1.49,
+			},
+			want: parameters.ParamValues{parameters.ParamValue{Name: "my_float", Value: 1.49}},
+		},
+		{
+			name: "float exclusiveMaxValue boundary disallow",
+			params: parameters.Parameters{
+				parameters.NewFloatParameterWithExclusiveRange("my_float", "this param is a float", nil, &floatValue),
+			},
+			in: map[string]any{
+				"my_float": 1.5,
+			},
+		},
 		{
 			name: "bool",
 			params: parameters.Parameters{
@@ -1167,7 +1320,7 @@ func TestParametersParse(t *testing.T) {
 				parameters.NewStringParameterWithDefault("my_string", "foo", "this param is a string"),
 			},
 			in:   map[string]any{},
-			want: parameters.ParamValues{parameters.ParamValue{Name: "my_string", Value: "foo"}},
+			want: parameters.ParamValues{parameters.ParamValue{Name: "my_string", Value: "foo", Omitted: true}},
 		},
 		{
 			name: "int default",
@@ -1175,7 +1328,7 @@ func TestParametersParse(t *testing.T) {
 				parameters.NewIntParameterWithDefault("my_int", 100, "this param is an int"),
 			},
 			in:   map[string]any{},
-			want: parameters.ParamValues{parameters.ParamValue{Name: "my_int", Value: 100}},
+			want: parameters.ParamValues{parameters.ParamValue{Name: "my_int", Value: 100, Omitted: true}},
 		},
 		{
 			name: "int (big)",
@@ -1183,7 +1336,7 @@ func TestParametersParse(t *testing.T) {
 				parameters.NewIntParameterWithDefault("my_big_int", math.MaxInt64, "this param is an int"),
 			},
 			in:   map[string]any{},
-			want: parameters.ParamValues{parameters.ParamValue{Name: "my_big_int", Value: math.MaxInt64}},
+			want: parameters.ParamValues{parameters.ParamValue{Name: "my_big_int", Value: math.MaxInt64, Omitted: true}},
 		},
 		{
 			name: "float default",
@@ -1191,7 +1344,7 @@ func TestParametersParse(t *testing.T) {
 				parameters.NewFloatParameterWithDefault("my_float", 1.1, "this param is a float"),
 			},
 			in:   map[string]any{},
-			want: parameters.ParamValues{parameters.ParamValue{Name: "my_float", Value: 1.1}},
+			want: parameters.ParamValues{parameters.ParamValue{Name: "my_float", Value: 1.1, Omitted: true}},
 		},
 		{
 			name: "bool default",
@@ -1199,7 +1352,7 @@ func TestParametersParse(t *testing.T) {
 				parameters.NewBooleanParameterWithDefault("my_bool", true, "this param is a bool"),
 			},
 			in:   map[string]any{},
-			want: parameters.ParamValues{parameters.ParamValue{Name: "my_bool", Value: true}},
+			want: parameters.ParamValues{parameters.ParamValue{Name: "my_bool", Value: true, Omitted: true}},
 		},
 		{
 			name: "string not required",
@@ -1207,7 +1360,7 @@ func TestParametersParse(t *testing.T) {
 				parameters.NewStringParameterWithRequired("my_string", "this param is a string", false),
 			},
 			in:   map[string]any{},
-			want: parameters.ParamValues{parameters.ParamValue{Name: "my_string", Value: nil}},
+			want: parameters.ParamValues{parameters.ParamValue{Name: "my_string", Value: nil, Omitted: true}},
 		},
 		{
 			name: "int not required",
@@ -1215,7 +1368,7 @@ func TestParametersParse(t *testing.T) {
 				parameters.NewIntParameterWithRequired("my_int", "this param is an int", false),
 			},
 			in:   map[string]any{},
-			want: parameters.ParamValues{parameters.ParamValue{Name: "my_int", Value: nil}},
+			want: parameters.ParamValues{parameters.ParamValue{Name: "my_int", Value: nil, Omitted: true}},
 		},
 		{
 			name: "float not required",
@@ -1223,7 +1376,7 @@ func TestParametersParse(t *testing.T) {
 				parameters.NewFloatParameterWithRequired("my_float", "this param is a float", false),
 			},
 			in:   map[string]any{},
-			want: parameters.ParamValues{parameters.ParamValue{Name: "my_float", Value: nil}},
+			want: parameters.ParamValues{parameters.ParamValue{Name: "my_float", Value: nil, Omitted: true}},
 		},
 		{
 			name: "bool not required",
@@ -1231,7 +1384,7 @@ func TestParametersParse(t *testing.T) {
 				parameters.NewBooleanParameterWithRequired("my_bool", "this param is a bool", false),
 			},
 			in:   map[string]any{},
-			want: parameters.ParamValues{parameters.ParamValue{Name: "my_bool", Value: nil}},
+			want: parameters.ParamValues{parameters.ParamValue{Name: "my_bool", Value: nil, Omitted: true}},
 		},
 		{
 			name: "array with string escape",
@@ -1278,7 +1431,7 @@ func TestParametersParse(t *testing.T) {
 				parameters.NewMapParameterWithDefault("my_map_default", map[string]any{"default_key": "default_val"}, "a map", "string"),
 			},
 			in:   map[string]any{},
-			want: parameters.ParamValues{parameters.ParamValue{Name: "my_map_default", Value: map[string]any{"default_key": "default_val"}}},
+			want: parameters.ParamValues{parameters.ParamValue{Name: "my_map_default", Value: map[string]any{"default_key": "default_val"}, Omitted: true}},
 		},
 		{
 			name: "map not required",
@@ -1286,7 +1439,7 @@ func TestParametersParse(t *testing.T) {
 				parameters.NewMapParameterWithRequired("my_map_not_required", "a map", false, "string"),
 			},
 			in:   map[string]any{},
-			want: parameters.ParamValues{parameters.ParamValue{Name: "my_map_not_required", Value: nil}},
+			want: parameters.ParamValues{parameters.ParamValue{Name: "my_map_not_required", Value: nil, Omitted: true}},
 		},
 		{
 			name: "map allowed",
@@ -1353,7 +1506,7 @@ func TestParametersParse(t *testing.T) {
 				t.Fatalf("unexpected error from ParseParams: %s", err)
 			}
 			if wantErr {
-				t.Fatalf("expected error but Param parsed successfully: %s", gotAll)
+				t.Fatalf("expected error but Param parsed successfully: %v", gotAll)
 			}
 
 			// Use cmp.Diff for robust comparison
@@ -1383,6 +1536,67 @@ func TestParametersParse(t *testing.T) {
 	})
 }
 
+func TestParametersParseBoundsError(t *testing.T) {
+	maxValue := 100
+	params := parameters.Parameters{
+		parameters.NewIntParameterWithRange("page_size", "the page size", nil, &maxValue),
+	}
+	_, err := parameters.ParseParams(params, map[string]any{"page_size": 10000}, nil)
+	if err == nil {
+		t.Fatalf("expected an error for a value above the maximum")
+	}
+	var agentErr *util.AgentError
+	if !errors.As(err, &agentErr) {
+		t.Fatalf("expected an agent error, got %T", err)
+	}
+	if want := `unable to parse value for "page_size": 10000 is above the maximum value 100`; err.Error() != want {
+		t.Fatalf("unexpected error: got %q, want %q", err.Error(), want)
+	}
+}
+
+func TestParametersParseEnumError(t *testing.T) {
+	params := parameters.Parameters{
+		parameters.NewStringParameterWithEnum("order", "the sort order", []string{"ASC", "DESC"}),
+	}
+	_, err := parameters.ParseParams(params, map[string]any{"order": "up"}, nil)
+	if err == nil {
+		t.Fatalf("expected an error for a value outside the enum")
+	}
+	if want := `unable to parse value for "order": "up" is not one of the allowed values: "ASC", "DESC"`; err.Error() != want {
+		t.Fatalf("unexpected error: got %q, want %q", err.Error(), want)
+	}
+}
+
+func TestParametersParsePatternError(t *testing.T) {
+	tcs := []struct {
+		name  string
+		param *parameters.StringParameter
+		want  string
+	}{
+		{
+			name:  "without description",
+			param: parameters.NewStringParameterWithPattern("id", "an id", "^[a-z]+$", ""),
+			want:  `unable to parse value for "id": "Bad-ID" does not match the pattern "^[a-z]+$"`,
+		},
+		{
+			name:  "with description",
+			param: parameters.NewStringParameterWithPattern("id", "an id", "^[a-z]+$", "lowercase letters only"),
+			want:  `unable to parse value for "id": "Bad-ID" does not match the pattern "^[a-z]+$" (lowercase letters only)`,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parameters.ParseParams(parameters.Parameters{tc.param}, map[string]any{"id": "Bad-ID"}, nil)
+			if err == nil {
+				t.Fatalf("expected an error for a value not matching the pattern")
+			}
+			if err.Error() != tc.want {
+				t.Fatalf("unexpected error: got %q, want %q", err.Error(), tc.want)
+			}
+		})
+	}
+}
+
 func TestAuthParametersParse(t *testing.T) {
 	authServices := []parameters.ParamAuthService{
 		{
@@ -1604,6 +1818,7 @@ func TestParamValues(t *testing.T) {
 }
 
 func TestParamManifest(t *testing.T) {
+	zero, hundred, zeroFloat := 0, 100, 0.0
 	tcs := []struct {
 		name string
 		in   parameters.Parameter
@@ -1624,6 +1839,26 @@ func TestParamManifest(t *testing.T) {
 			in:   parameters.NewFloatParameter("foo-float", "bar"),
 			want: parameters.ParameterManifest{Name: "foo-float", Type: "float", Required: true, Description: "bar", AuthServices: []string{}},
 		},
+		{
+			name: "string with enum",
+			in:   parameters.NewStringParameterWithEnum("foo-string", "bar", []string{"a", "b"}),
+			want: parameters.ParameterManifest{Name: "foo-string", Type: "string", Required: true, Description: "bar", AuthServices: []string{}, Enum: []string{"a", "b"}},
+		},
+		{
+			name: "string with pattern",
+			in:   parameters.NewStringParameterWithPattern("foo-string", "bar", "^[a-z]+$", "lowercase letters"),
+			want: parameters.ParameterManifest{Name: "foo-string", Type: "string", Required: true, Description: "bar", AuthServices: []string{}, Pattern: "^[a-z]+$"},
+		},
+		{
+			name: "int with range",
+			in:   parameters.NewIntParameterWithRange("foo-int", "bar", &zero, &hundred),
+			want: parameters.ParameterManifest{Name: "foo-int", Type: "integer", Required: true, Description: "bar", AuthServices: []string{}, Minimum: 0, Maximum: 100},
+		},
+		{
+			name: "float with exclusive range",
+			in:   parameters.NewFloatParameterWithExclusiveRange("foo-float", "bar", &zeroFloat, nil),
+			want: parameters.ParameterManifest{Name: "foo-float", Type: "float", Required: true, Description: "bar", AuthServices: []string{}, ExclusiveMinimum: 0.0},
+		},
 		{
 			name: "boolean",
 			in:   parameters.NewBooleanParameter("foo-bool", "bar"),
@@ -1754,6 +1989,7 @@ func TestParamManifest(t *testing.T) {
 }
 
 func TestParamMcpManifest(t *testing.T) {
+	zero, hundred, oneFloat := 0, 100, 1.0
 	tcs := []struct {
 		name          string
 		in            parameters.Parameter
@@ -1778,6 +2014,30 @@ func TestParamMcpManifest(t *testing.T) {
 			want:          parameters.ParameterMcpManifest{Type: "number", Description: "bar"},
 			wantAuthParam: []string{},
 		},
+		{
+			name:          "string with enum",
+			in:            parameters.NewStringParameterWithEnum("foo-string", "bar", []string{"a", "b"}),
+			want:          parameters.ParameterMcpManifest{Type: "string", Description: "bar", Enum: []any{"a", "b"}},
+			wantAuthParam: []string{},
+		},
+		{
+			name:          "string with pattern",
+			in:            parameters.NewStringParameterWithPattern("foo-string", "bar", "^[a-z]+$", ""),
+			want:          parameters.ParameterMcpManifest{Type: "string", Description: "bar", Pattern: "^[a-z]+$"},
+			wantAuthParam: []string{},
+		},
+		{
+			name:          "int with range",
+			in:            parameters.NewIntParameterWithRange("foo-int", "bar", &zero, &hundred),
+			want:          parameters.ParameterMcpManifest{Type: "integer", Description: "bar", Minimum: 0, Maximum: 100},
+			wantAuthParam: []string{},
+		},
+		{
+			name:          "float with exclusive range",
+			in:            parameters.NewFloatParameterWithExclusiveRange("foo-float", "bar", nil, &oneFloat),
+			want:          parameters.ParameterMcpManifest{Type: "number", Description: "bar", ExclusiveMaximum: 1.0},
+			wantAuthParam: []string{},
+		},
 		{
 			name:          "boolean",
 			in:            parameters.NewBooleanParameter("foo-bool", "bar"),
@@ -1861,11 +2121,10 @@ func TestMcpManifest(t *testing.T) {
 			wantSchema: parameters.McpToolsSchema{
 				Type: "object",
 				Properties: map[string]parameters.ParameterMcpManifest{
-					"foo-string":       {Type: "string", Description: "bar", Default: "foo"},
-					"foo-string2":      {Type: "string", Description: "bar"},
-					"foo-string3-auth": {Type: "string", Description: "bar"},
-					"foo-int2":         {Type: "integer", Description: "bar"},
-					"foo-float":        {Type: "number", Description: "bar"},
+					"foo-string":  {Type: "string", Description: "bar", Default: "foo"},
+					"foo-string2": {Type: "string", Description: "bar"},
+					"foo-int2":    {Type: "integer", Description: "bar"},
+					"foo-float":   {Type: "number", Description: "bar"},
 					"foo-array2": {
 						Type:        "array",
 						Description: "bar",
@@ -1882,7 +2141,7 @@ func TestMcpManifest(t *testing.T) {
 						AdditionalProperties: true,
 					},
 				},
-				Required: []string{"foo-string2", "foo-string3-auth", "foo-int2", "foo-float", "foo-array2", "foo-map-int", "foo-map-any"},
+				Required: []string{"foo-string2", "foo-int2", "foo-float", "foo-array2", "foo-map-int", "foo-map-any"},
 			},
 			wantAuthParam: map[string][]string{
 				"foo-string3-auth": []string{"my-google-auth-service", "other-auth-service"},
@@ -1991,6 +2250,121 @@ func TestFailParametersUnmarshal(t *testing.T) {
 			},
 			err: "unsupported valueType \"not-a-real-type\" for map parameter",
 		},
+		{
+			name: "string default not in enum",
+			in: []map[string]any{
+				{
+					"name":        "format",
+					"type":        "string",
+					"description": "output format",
+					"default":     "yaml",
+					"enum":        []string{"json", "markdown"},
+				},
+			},
+			err: "invalid parameter \"format\": default value \"yaml\" is not one of the allowed values: \"json\", \"markdown\"",
+		},
+		{
+			name: "string invalid pattern",
+			in: []map[string]any{
+				{
+					"name":        "id",
+					"type":        "string",
+					"description": "an id",
+					"pattern":     "[a-z",
+				},
+			},
+			err: "invalid parameter \"id\": invalid pattern \"[a-z\": error parsing regexp: missing closing ]: `[a-z`",
+		},
+		{
+			name: "string default not matching pattern",
+			in: []map[string]any{
+				{
+					"name":        "id",
+					"type":        "string",
+					"description": "an id",
+					"default":     "Bad-ID",
+					"pattern":     "^[a-z]+$",
+				},
+			},
+			err: "invalid parameter \"id\": default value: \"Bad-ID\" does not match the pattern \"^[a-z]+$\"",
+		},
+		{
+			name: "int default below minValue",
+			in: []map[string]any{
+				{
+					"name":        "page_size",
+					"type":        "integer",
+					"description": "page size",
+					"default":     0,
+					"minValue":    1,
+				},
+			},
+			err: "invalid parameter \"page_size\": default value is out of bounds: 0 is under the minimum value 1",
+		},
+		{
+			name: "float default at exclusiveMaxValue",
+			in: []map[string]any{
+				{
+					"name":              "ratio",
+					"type":              "float",
+					"description":       "a ratio",
+					"default":           1.0,
+					"exclusiveMaxValue": 1.0,
+				},
+			},
+			err: "invalid parameter \"ratio\": default value is out of bounds: 1 must be less than 1",
+		},
+		{
+			name: "int minValue above maxValue",
+			in: []map[string]any{
+				{
+					"name":        "page_size",
+					"type":        "integer",
+					"description": "page size",
+					"minValue":    10,
+					"maxValue":    1,
+				},
+			},
+			err: "invalid parameter \"page_size\": lower bound 10 is greater than upper bound 1",
+		},
+		{
+			name: "example of wrong type",
+			in: []map[string]any{
+				{
+					"name":        "page_size",
+					"type":        "integer",
+					"description": "page size",
+					"examples":    []any{10, "ten"},
+				},
+			},
+			err: "invalid parameter \"page_size\": example 1 is invalid:",
+		},
+		{
+			name: "example not in enum",
+			in: []map[string]any{
+				{
+					"name":        "format",
+					"type":        "string",
+					"description": "output format",
+					"enum":        []string{"json", "markdown"},
+					"examples":    []any{"yaml"},
+				},
+			},
+			err: "invalid parameter \"format\": example 0 is invalid:",
+		},
+		{
+			name: "example not matching pattern",
+			in: []map[string]any{
+				{
+					"name":        "id",
+					"type":        "string",
+					"description": "an id",
+					"pattern":     "^[a-z]+$",
+					"examples":    []any{"Bad-ID"},
+				},
+			},
+			err: "invalid parameter \"id\": example 0 is invalid:",
+		},
 	}
 	for _, tc := range tcs {
 		t.Run(tc.name, func(t *testing.T) {
@@ -2366,3 +2740,1186 @@ func TestCheckParamRequired(t *testing.T) {
 		})
 	}
 }
+
+func TestArrayParametersParse(t *testing.T) {
+	ctx, err := testutils.ContextWithNewLogger()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var params parameters.Parameters
+	in := `
+- name: ids
+  type: array
+  description: some ids
+  default: [1, 2]
+  items:
+    name: id
+    type: integer
+    description: an id
+- name: names
+  type: array
+  description: some names
+  items:
+    name: name
+    type: string
+    description: a name
+`
+	if err := yaml.UnmarshalContext(ctx, []byte(in), &params); err != nil {
+		t.Fatalf("unable to unmarshal: %s", err)
+	}
+
+	tcs := []struct {
+		name    string
+		in      map[string]any
+		want    parameters.ParamValues
+		wantErr string
+	}{
+		{
+			name: "missing array with default",
+			in:   map[string]any{"names": []any{"a"}},
+			want: parameters.ParamValues{{Name: "ids", Value: []any{1, 2}, Omitted: true}, {Name: "names", Value: []any{"a"}}},
+		},
+		{
+			name: "empty arrays are kept",
+			in:   map[string]any{"ids": []any{}, "names": []any{}},
+			want: parameters.ParamValues{{Name: "ids", Value: []any{}}, {Name: "names", Value: []any{}}},
+		},
+		{
+			name:    "missing required array",
+			in:      map[string]any{"ids": []any{json.Number("3")}},
+			wantErr: `parameter "names" is required`,
+		},
+		{
+			name:    "heterogeneous array",
+			in:      map[string]any{"names": []any{"a", json.Number("1")}},
+			wantErr: `unable to parse value for "names": unable to parse element #1`,
+		},
+		{
+			name:    "not an array",
+			in:      map[string]any{"names": "a"},
+			wantErr: `unable to parse value for "names": "a" not type "array"`,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parameters.ParseParams(params, tc.in, nil)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("ParseParams() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFailArrayParametersUnmarshal(t *testing.T) {
+	ctx, err := testutils.ContextWithNewLogger()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	tcs := []struct {
+		name string
+		in   string
+		err  string
+	}{
+		{
+			name: "heterogeneous default",
+			in: `
+- name: names
+  type: array
+  description: some names
+  default: ["a", 1]
+  items:
+    name: name
+    type: string
+    description: a name
+`,
+			err: `invalid parameter "names": default value: unable to parse element #1`,
+		},
+		{
+			name: "embeddedBy on non-string items",
+			in: `
+- name: ids
+  type: array
+  description: some ids
+  embeddedBy: my-model
+  items:
+    name: id
+    type: integer
+    description: an id
+`,
+			err: `parameter type "array" can only specify 'embeddedBy' for items of type "string"`,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			var got parameters.Parameters
+			err := yaml.UnmarshalContext(ctx, []byte(tc.in), &got)
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Fatalf("expected error containing %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+// fakeEmbeddingModel embeds a text as a vector holding its length.
+type fakeEmbeddingModel struct {
+	batches [][]string
+	opts    []embeddingmodels.EmbedOptions
+	err     error
+	// unsupported is returned when validating embedding options with a task
+	// type.
+	unsupported error
+}
+
+func (m *fakeEmbeddingModel) EmbeddingModelType() string                     { return "fake" }
+func (m *fakeEmbeddingModel) ToConfig() embeddingmodels.EmbeddingModelConfig { return nil }
+func (m *fakeEmbeddingModel) ValidateEmbedOptions(opts embeddingmodels.EmbedOptions) error {
+	if opts.TaskType != "" {
+		return m.unsupported
+	}
+	return nil
+}
+func (m *fakeEmbeddingModel) EmbedParameters(ctx context.Context, texts []string, opts embeddingmodels.EmbedOptions) ([][]float32, error) {
+	m.batches = append(m.batches, texts)
+	m.opts = append(m.opts, opts)
+	if m.err != nil {
+		return nil, m.err
+	}
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = []float32{float32(len(text))}
+	}
+	return vectors, nil
+}
+
+func TestEmbedParamsArrays(t *testing.T) {
+	query := parameters.NewStringParameter("query", "a query")
+	query.EmbeddedBy = "model"
+	texts := parameters.NewArrayParameter("texts", "some texts", parameters.NewStringParameter("text", "a text"))
+	texts.EmbeddedBy = "model"
+	params := parameters.Parameters{query, texts, parameters.NewIntParameter("limit", "a limit")}
+
+	textsValue := []any{"a", "bbb"}
+	values := parameters.ParamValues{
+		{Name: "query", Value: "cc"},
+		{Name: "texts", Value: textsValue},
+		{Name: "limit", Value: 5},
+	}
+	model := &fakeEmbeddingModel{}
+	got, err := parameters.EmbedParams(context.Background(), params, values, map[string]embeddingmodels.EmbeddingModel{"model": model}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := parameters.ParamValues{
+		{Name: "query", Value: []float32{2}},
+		{Name: "texts", Value: []any{[]float32{1}, []float32{3}}},
+		{Name: "limit", Value: 5},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("EmbedParams() mismatch (-want +got):\n%s", diff)
+	}
+	if len(model.batches) != 1 || len(model.batches[0]) != 3 {
+		t.Fatalf("expected all texts to be embedded in a single batch, got %v", model.batches)
+	}
+	if textsValue[0] != "a" {
+		t.Fatalf("EmbedParams modified the caller's array")
+	}
+
+	values = parameters.ParamValues{
+		{Name: "query", Value: "cc"},
+		{Name: "texts", Value: []any{"a", 1}},
+		{Name: "limit", Value: 5},
+	}
+	if _, err := parameters.EmbedParams(context.Background(), params, values, map[string]embeddingmodels.EmbeddingModel{"model": model}, nil); err == nil {
+		t.Fatalf("expected an error for a non-string array element")
+	}
+}
+
+func TestEmbedParamsBatching(t *testing.T) {
+	title := parameters.NewStringParameter("title", "a title")
+	title.EmbeddedBy = "model_a"
+	body := parameters.NewStringParameter("body", "a body")
+	body.EmbeddedBy = "model_b"
+	summary := parameters.NewStringParameter("summary", "a summary")
+	summary.EmbeddedBy = "model_a"
+	tags := parameters.NewArrayParameter("tags", "some tags", parameters.NewStringParameter("tag", "a tag"))
+	tags.EmbeddedBy = "model_a"
+	params := parameters.Parameters{title, body, summary, tags}
+	newValues := func() parameters.ParamValues {
+		return parameters.ParamValues{
+			{Name: "title", Value: "t"},
+			{Name: "body", Value: "bb"},
+			{Name: "summary", Value: "sss"},
+			{Name: "tags", Value: []any{"dddd", "eeeee"}},
+		}
+	}
+
+	modelA, modelB := &fakeEmbeddingModel{}, &fakeEmbeddingModel{}
+	models := map[string]embeddingmodels.EmbeddingModel{"model_a": modelA, "model_b": modelB}
+	got, err := parameters.EmbedParams(context.Background(), params, newValues(), models, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := parameters.ParamValues{
+		{Name: "title", Value: []float32{1}},
+		{Name: "body", Value: []float32{2}},
+		{Name: "summary", Value: []float32{3}},
+		{Name: "tags", Value: []any{[]float32{4}, []float32{5}}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("EmbedParams() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([][]string{{"t", "sss", "dddd", "eeeee"}}, modelA.batches); diff != "" {
+		t.Fatalf("expected a single batch for model_a (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([][]string{{"bb"}}, modelB.batches); diff != "" {
+		t.Fatalf("expected a single batch for model_b (-want +got):\n%s", diff)
+	}
+
+	// A failing model leaves the caller's values intact, even if another
+	// model succeeded.
+	modelA = &fakeEmbeddingModel{}
+	modelB = &fakeEmbeddingModel{err: errors.New("quota exceeded")}
+	models = map[string]embeddingmodels.EmbeddingModel{"model_a": modelA, "model_b": modelB}
+	values := newValues()
+	_, err = parameters.EmbedParams(context.Background(), params, values, models, nil)
+	if err == nil || !strings.Contains(err.Error(), "model_b") || !strings.Contains(err.Error(), "quota exceeded") {
+		t.Fatalf("expected an error from model_b, got %v", err)
+	}
+	if len(modelA.batches) != 1 {
+		t.Fatalf("expected model_a to be called once before model_b, got %v", modelA.batches)
+	}
+	if diff := cmp.Diff(newValues(), values); diff != "" {
+		t.Fatalf("EmbedParams modified the caller's values (-want +got):\n%s", diff)
+	}
+}
+
+func TestEmbedParamsOptions(t *testing.T) {
+	ctx, err := testutils.ContextWithNewLogger()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var params parameters.Parameters
+	in := `
+- name: query
+  type: string
+  description: a query
+  embeddedBy: model
+  taskType: RETRIEVAL_QUERY
+- name: document
+  type: string
+  description: a document
+  embeddedBy: model
+  taskType: RETRIEVAL_DOCUMENT
+  truncate: true
+- name: title
+  type: string
+  description: a title
+  embeddedBy: model
+  taskType: RETRIEVAL_QUERY
+`
+	if err := yaml.UnmarshalContext(ctx, []byte(in), &params); err != nil {
+		t.Fatalf("unable to unmarshal: %s", err)
+	}
+	values := parameters.ParamValues{
+		{Name: "query", Value: "q"},
+		{Name: "document", Value: "dd"},
+		{Name: "title", Value: "ttt"},
+	}
+	model := &fakeEmbeddingModel{}
+	models := map[string]embeddingmodels.EmbeddingModel{"model": model}
+	if _, err := parameters.EmbedParams(ctx, params, values, models, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// parameters with the same options are embedded together
+	if diff := cmp.Diff([][]string{{"q", "ttt"}, {"dd"}}, model.batches); diff != "" {
+		t.Fatalf("unexpected batches (-want +got):\n%s", diff)
+	}
+	truncate := true
+	wantOpts := []embeddingmodels.EmbedOptions{
+		{TaskType: "RETRIEVAL_QUERY"},
+		{TaskType: "RETRIEVAL_DOCUMENT", Truncate: &truncate},
+	}
+	if diff := cmp.Diff(wantOpts, model.opts); diff != "" {
+		t.Fatalf("unexpected embedding options (-want +got):\n%s", diff)
+	}
+
+	// embeddings must have the requested dimensionality
+	query := parameters.NewStringParameter("query", "a query")
+	query.EmbeddedBy = "model"
+	query.OutputDimensionality = 768
+	_, err = parameters.EmbedParams(ctx, parameters.Parameters{query}, parameters.ParamValues{{Name: "query", Value: "q"}}, models, nil)
+	if err == nil || !strings.Contains(err.Error(), "returned an embedding with 1 dimensions for parameter 'query', expected 768") {
+		t.Fatalf("expected a dimensionality error, got %v", err)
+	}
+
+	if err := params.ValidateEmbedOptions(models); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	model.unsupported = errors.New("task types are not supported")
+	if err := params.ValidateEmbedOptions(models); err == nil || !strings.Contains(err.Error(), `parameter "query": embedding model model: task types are not supported`) {
+		t.Fatalf("expected unsupported options to be rejected, got %v", err)
+	}
+	if err := params.ValidateEmbedOptions(nil); err == nil || !strings.Contains(err.Error(), "embedding model does not exist: model") {
+		t.Fatalf("expected a missing model to be rejected, got %v", err)
+	}
+
+	in = `
+- name: query
+  type: string
+  description: a query
+  taskType: RETRIEVAL_QUERY
+`
+	if err := yaml.UnmarshalContext(ctx, []byte(in), &params); err == nil || !strings.Contains(err.Error(), "with 'embeddedBy'") {
+		t.Fatalf("expected embedding options without embeddedBy to be rejected, got %v", err)
+	}
+}
+
+func TestObjectParametersParse(t *testing.T) {
+	ctx, err := testutils.ContextWithNewLogger()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var params parameters.Parameters
+	in := `
+- name: options
+  type: object
+  description: analysis options
+  default:
+    mode: fast
+  properties:
+    - name: mode
+      type: string
+      description: the analysis mode
+    - name: limit
+      type: integer
+      description: the row limit
+      default: 10
+    - name: chart
+      type: object
+      description: chart options
+      required: false
+      properties:
+        - name: kind
+          type: string
+          description: the chart kind
+        - name: size
+          type: object
+          description: the chart size
+          default:
+            width: 640
+          properties:
+            - name: width
+              type: integer
+              description: the chart width
+            - name: height
+              type: integer
+              description: the chart height
+              default: 480
+- name: row
+  type: object
+  description: a row to insert
+  additionalProperties: true
+  required: false
+  properties:
+    - name: id
+      type: integer
+      description: the row id
+`
+	if err := yaml.UnmarshalContext(ctx, []byte(in), &params); err != nil {
+		t.Fatalf("unable to unmarshal: %s", err)
+	}
+
+	tcs := []struct {
+		name    string
+		in      map[string]any
+		want    parameters.ParamValues
+		wantErr string
+	}{
+		{
+			name: "object default and property defaults",
+			in:   map[string]any{},
+			want: parameters.ParamValues{
+				{Name: "options", Value: map[string]any{"mode": "fast", "limit": 10}, Omitted: true},
+				{Name: "row", Value: nil, Omitted: true},
+			},
+		},
+		{
+			name: "deeply nested object",
+			in: map[string]any{
+				"options": map[string]any{
+					"mode":  "deep",
+					"limit": json.Number("5"),
+					"chart": map[string]any{
+						"kind": "bar",
+						"size": map[string]any{"width": json.Number("100")},
+					},
+				},
+			},
+			want: parameters.ParamValues{
+				{Name: "options", Value: map[string]any{
+					"mode":  "deep",
+					"limit": 5,
+					"chart": map[string]any{
+						"kind": "bar",
+						"size": map[string]any{"width": 100, "height": 480},
+					},
+				}},
+				{Name: "row", Value: nil, Omitted: true},
+			},
+		},
+		{
+			name: "nested property default",
+			in: map[string]any{
+				"options": map[string]any{"mode": "deep", "chart": map[string]any{"kind": "pie"}},
+			},
+			want: parameters.ParamValues{
+				{Name: "options", Value: map[string]any{
+					"mode":  "deep",
+					"limit": 10,
+					"chart": map[string]any{"kind": "pie", "size": map[string]any{"width": 640, "height": 480}},
+				}},
+				{Name: "row", Value: nil, Omitted: true},
+			},
+		},
+		{
+			name: "additional properties",
+			in: map[string]any{
+				"row": map[string]any{"id": json.Number("1"), "name": "a", "score": json.Number("1.5")},
+			},
+			want: parameters.ParamValues{
+				{Name: "options", Value: map[string]any{"mode": "fast", "limit": 10}, Omitted: true},
+				{Name: "row", Value: map[string]any{"id": 1, "name": "a", "score": 1.5}},
+			},
+		},
+		{
+			name:    "unknown property",
+			in:      map[string]any{"options": map[string]any{"mode": "fast", "colour": "red"}},
+			wantErr: `unable to parse value for "options": unknown property "colour"`,
+		},
+		{
+			name:    "unknown nested property",
+			in:      map[string]any{"options": map[string]any{"mode": "fast", "chart": map[string]any{"kind": "bar", "size": map[string]any{"depth": json.Number("1")}}}},
+			wantErr: `unable to parse value for "options": unable to parse property "chart": unable to parse property "size": unknown property "depth"`,
+		},
+		{
+			name:    "missing required property",
+			in:      map[string]any{"options": map[string]any{"limit": json.Number("1")}},
+			wantErr: `unable to parse value for "options": property "mode" is required`,
+		},
+		{
+			name:    "missing required nested property",
+			in:      map[string]any{"options": map[string]any{"mode": "fast", "chart": map[string]any{}}},
+			wantErr: `unable to parse value for "options": unable to parse property "chart": property "kind" is required`,
+		},
+		{
+			name:    "wrong property type",
+			in:      map[string]any{"options": map[string]any{"mode": "fast", "limit": "ten"}},
+			wantErr: `unable to parse value for "options": unable to parse property "limit"`,
+		},
+		{
+			name:    "not an object",
+			in:      map[string]any{"options": []any{"fast"}},
+			wantErr: `unable to parse value for "options"`,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parameters.ParseParams(params, tc.in, nil)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("ParseParams() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFailObjectParametersUnmarshal(t *testing.T) {
+	ctx, err := testutils.ContextWithNewLogger()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	tcs := []struct {
+		name string
+		in   string
+		err  string
+	}{
+		{
+			name: "default with unknown property",
+			in: `
+- name: options
+  type: object
+  description: options
+  default:
+    colour: red
+  properties:
+    - name: mode
+      type: string
+      description: the mode
+`,
+			err: `invalid parameter "options": default value: unknown property "colour"`,
+		},
+		{
+			name: "default missing required property",
+			in: `
+- name: options
+  type: object
+  description: options
+  default: {}
+  properties:
+    - name: mode
+      type: string
+      description: the mode
+`,
+			err: `invalid parameter "options": default value: property "mode" is required`,
+		},
+		{
+			name: "duplicate property",
+			in: `
+- name: options
+  type: object
+  description: options
+  properties:
+    - name: mode
+      type: string
+      description: the mode
+    - name: mode
+      type: integer
+      description: the mode again
+`,
+			err: `duplicate property "mode"`,
+		},
+		{
+			name: "invalid nested property",
+			in: `
+- name: options
+  type: object
+  description: options
+  properties:
+    - name: mode
+      type: not-a-type
+      description: the mode
+`,
+			err: `"not-a-type" is not valid type for a parameter`,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			var got parameters.Parameters
+			err := yaml.UnmarshalContext(ctx, []byte(tc.in), &got)
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Fatalf("expected error containing %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestObjectParameterManifest(t *testing.T) {
+	size := parameters.NewObjectParameterWithDefault("size", map[string]any{"width": 640}, "the chart size", parameters.Parameters{
+		parameters.NewIntParameter("width", "the chart width"),
+		parameters.NewIntParameterWithDefault("height", 480, "the chart height"),
+	})
+	param := parameters.NewObjectParameter("chart", "chart options", parameters.Parameters{
+		parameters.NewStringParameter("kind", "the chart kind"),
+		size,
+	})
+
+	wantManifest := parameters.ParameterManifest{
+		Name:         "chart",
+		Type:         "object",
+		Required:     true,
+		Description:  "chart options",
+		AuthServices: []string{},
+		Properties: []parameters.ParameterManifest{
+			{Name: "kind", Type: "string", Required: true, Description: "the chart kind", AuthServices: []string{}},
+			{
+				Name:         "size",
+				Type:         "object",
+				Required:     false,
+				Description:  "the chart size",
+				AuthServices: []string{},
+				Default:      map[string]any{"width": 640},
+				Properties: []parameters.ParameterManifest{
+					{Name: "width", Type: "integer", Required: true, Description: "the chart width", AuthServices: []string{}},
+					{Name: "height", Type: "integer", Required: false, Description: "the chart height", AuthServices: []string{}, Default: 480},
+				},
+				AdditionalProperties: false,
+			},
+		},
+		AdditionalProperties: false,
+	}
+	if diff := cmp.Diff(wantManifest, param.Manifest()); diff != "" {
+		t.Fatalf("Manifest() mismatch (-want +got):\n%s", diff)
+	}
+
+	got, _ := param.McpManifest()
+	b, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("unable to marshal manifest: %s", err)
+	}
+	want := `{"type":"object","description":"chart options",` +
+		`"properties":{"kind":{"type":"string","description":"the chart kind"},` +
+		`"size":{"type":"object","description":"the chart size",` +
+		`"properties":{"height":{"type":"integer","description":"the chart height","default":480},"width":{"type":"integer","description":"the chart width"}},` +
+		`"required":["width"],"default":{"width":640},"additionalProperties":false}},` +
+		`"required":["kind"],"additionalProperties":false}`
+	if string(b) != want {
+		t.Fatalf("unexpected MCP manifest:\ngot  %s\nwant %s", b, want)
+	}
+}
+
+func TestParseParamsNullable(t *testing.T) {
+	nullableDesc := parameters.NewStringParameterWithDefault("description", "default", "a description")
+	nullableDesc.Nullable = true
+	nullableRequired := parameters.NewStringParameter("instruction", "an instruction")
+	nullableRequired.Nullable = true
+	params := parameters.Parameters{
+		nullableDesc,
+		nullableRequired,
+		parameters.NewStringParameterWithDefault("name", "default", "a name"),
+	}
+
+	tcs := []struct {
+		name         string
+		in           map[string]any
+		want         parameters.ParamValues
+		wantProvided map[string]bool
+	}{
+		{
+			name: "omitted",
+			in:   map[string]any{"instruction": "be brief"},
+			want: parameters.ParamValues{
+				{Name: "description", Value: "default", Omitted: true},
+				{Name: "instruction", Value: "be brief"},
+				{Name: "name", Value: "default", Omitted: true},
+			},
+			wantProvided: map[string]bool{"description": false, "instruction": true, "name": false},
+		},
+		{
+			name: "explicit null",
+			in:   map[string]any{"description": nil, "instruction": nil, "name": nil},
+			want: parameters.ParamValues{
+				{Name: "description", Value: nil},
+				{Name: "instruction", Value: nil},
+				// non-nullable parameters treat null as omitted
+				{Name: "name", Value: "default", Omitted: true},
+			},
+			wantProvided: map[string]bool{"description": true, "instruction": true, "name": false},
+		},
+		{
+			name: "value",
+			in:   map[string]any{"description": "new", "instruction": "be brief", "name": "new"},
+			want: parameters.ParamValues{
+				{Name: "description", Value: "new"},
+				{Name: "instruction", Value: "be brief"},
+				{Name: "name", Value: "new"},
+			},
+			wantProvided: map[string]bool{"description": true, "instruction": true, "name": true},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parameters.ParseParams(params, tc.in, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("ParseParams() mismatch (-want +got):\n%s", diff)
+			}
+			for name, want := range tc.wantProvided {
+				if got.WasProvided(name) != want {
+					t.Errorf("WasProvided(%q) = %t, want %t", name, !want, want)
+				}
+			}
+		})
+	}
+
+	if _, err := parameters.ParseParams(params, map[string]any{}, nil); err == nil {
+		t.Fatalf("expected an error for an omitted required nullable parameter")
+	}
+}
+
+func TestNullableParameterManifest(t *testing.T) {
+	ctx, err := testutils.ContextWithNewLogger()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var params parameters.Parameters
+	in := `
+- name: description
+  type: string
+  description: a description
+  nullable: true
+  required: false
+- name: options
+  type: object
+  description: some options
+  properties:
+    - name: limit
+      type: integer
+      description: a limit
+      nullable: true
+`
+	if err := yaml.UnmarshalContext(ctx, []byte(in), &params); err != nil {
+		t.Fatalf("unable to unmarshal: %s", err)
+	}
+
+	manifest := params.Manifest()
+	if !manifest[0].Nullable || manifest[1].Nullable || !manifest[1].Properties[0].Nullable {
+		t.Fatalf("unexpected nullability in manifest: %+v", manifest)
+	}
+
+	schema, _ := params.McpManifest()
+	if diff := cmp.Diff([]any{"string", "null"}, schema.Properties["description"].Type); diff != "" {
+		t.Fatalf("unexpected type for nullable parameter (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]any{"integer", "null"}, schema.Properties["options"].Properties["limit"].Type); diff != "" {
+		t.Fatalf("unexpected type for nullable property (-want +got):\n%s", diff)
+	}
+
+	got, err := parameters.ParseParams(params, map[string]any{"options": map[string]any{"limit": nil}}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := parameters.ParamValues{
+		{Name: "description", Value: nil, Omitted: true},
+		{Name: "options", Value: map[string]any{"limit": nil}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("ParseParams() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDateTimeParametersParse(t *testing.T) {
+	lenientTimestamp := parameters.NewTimestampParameter("ts", "a timestamp")
+	lenientTimestamp.Lenient = true
+	lenientDate := parameters.NewDateParameter("d", "a date")
+	lenientDate.Lenient = true
+
+	tcs := []struct {
+		name    string
+		param   parameters.Parameter
+		in      any
+		want    any
+		wantErr string
+	}{
+		{
+			name:  "RFC3339 timestamp is normalized to UTC",
+			param: parameters.NewTimestampParameter("ts", "a timestamp"),
+			in:    "2025-03-04T05:06:07+02:00",
+			want:  time.Date(2025, 3, 4, 3, 6, 7, 0, time.UTC),
+		},
+		{
+			name:  "RFC3339 timestamp with fractional seconds",
+			param: parameters.NewTimestampParameter("ts", "a timestamp"),
+			in:    "2025-03-04T05:06:07.123Z",
+			want:  time.Date(2025, 3, 4, 5, 6, 7, 123000000, time.UTC),
+		},
+		{
+			name:    "strict timestamp rejects lenient formats",
+			param:   parameters.NewTimestampParameter("ts", "a timestamp"),
+			in:      "2025-03-04 05:06:07",
+			wantErr: `unable to parse value for "ts": "2025-03-04 05:06:07" is not a valid timestamp, expected one of the formats: "RFC3339"`,
+		},
+		{
+			name:  "lenient timestamp without time zone",
+			param: lenientTimestamp,
+			in:    "2025-03-04 05:06:07",
+			want:  time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC),
+		},
+		{
+			name:  "lenient timestamp from a date",
+			param: lenientTimestamp,
+			in:    "2025-03-04",
+			want:  time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "invalid lenient timestamp lists the formats",
+			param:   lenientTimestamp,
+			in:      "yesterday",
+			wantErr: `"yesterday" is not a valid timestamp, expected one of the formats: "RFC3339", "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02 15:04:05Z07:00", "2006-01-02"`,
+		},
+		{
+			name:  "date",
+			param: parameters.NewDateParameter("d", "a date"),
+			in:    "2025-03-04",
+			want:  "2025-03-04",
+		},
+		{
+			name:    "invalid date",
+			param:   parameters.NewDateParameter("d", "a date"),
+			in:      "2025-02-30",
+			wantErr: `"2025-02-30" is not a valid date, expected one of the formats: "2006-01-02"`,
+		},
+		{
+			name:  "lenient date is normalized",
+			param: lenientDate,
+			in:    "2025/03/04",
+			want:  "2025-03-04",
+		},
+		{
+			name:    "date must be a string",
+			param:   parameters.NewDateParameter("d", "a date"),
+			in:      json.Number("20250304"),
+			wantErr: `not type "date"`,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parameters.ParseParams(parameters.Parameters{tc.param}, map[string]any{tc.param.GetName(): tc.in}, nil)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(tc.want, got[0].Value); diff != "" {
+				t.Fatalf("ParseParams() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDateTimeParametersUnmarshal(t *testing.T) {
+	ctx, err := testutils.ContextWithNewLogger()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var params parameters.Parameters
+	in := `
+- name: since
+  type: timestamp
+  description: the start of the range
+  lenient: true
+- name: day
+  type: date
+  description: the day
+  default: "2025-03-04"
+`
+	if err := yaml.UnmarshalContext(ctx, []byte(in), &params); err != nil {
+		t.Fatalf("unable to unmarshal: %s", err)
+	}
+	since := parameters.NewTimestampParameter("since", "the start of the range")
+	since.Lenient = true
+	want := parameters.Parameters{since, parameters.NewDateParameterWithDefault("day", "2025-03-04", "the day")}
+	if diff := cmp.Diff(want, params); diff != "" {
+		t.Fatalf("incorrect parse (-want +got):\n%s", diff)
+	}
+
+	wantManifest := []parameters.ParameterManifest{
+		{Name: "since", Type: "string", Required: true, Description: "the start of the range", AuthServices: []string{}, Format: "date-time"},
+		{Name: "day", Type: "string", Required: false, Description: "the day", AuthServices: []string{}, Default: "2025-03-04", Format: "date"},
+	}
+	if diff := cmp.Diff(wantManifest, params.Manifest()); diff != "" {
+		t.Fatalf("Manifest() mismatch (-want +got):\n%s", diff)
+	}
+	schema, _ := params.McpManifest()
+	if got := schema.Properties["since"].Format; got != "date-time" {
+		t.Fatalf("unexpected MCP format for timestamp: %q", got)
+	}
+
+	invalid := `
+- name: day
+  type: date
+  description: the day
+  default: "03/04/2025"
+`
+	err = yaml.UnmarshalContext(ctx, []byte(invalid), &params)
+	if want := `invalid parameter "day": default value: "03/04/2025" is not a valid date`; err == nil || !strings.Contains(err.Error(), want) {
+		t.Fatalf("expected error containing %q, got %v", want, err)
+	}
+}
+
+func TestSensitiveParameters(t *testing.T) {
+	ctx, err := testutils.ContextWithNewLogger()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var params parameters.Parameters
+	in := `
+- name: user
+  type: string
+  description: a user name
+- name: api_key
+  type: string
+  description: an API key
+  sensitive: true
+  default: default-secret
+  pattern: "^[a-z]+-[a-z]+$"
+`
+	if err := yaml.UnmarshalContext(ctx, []byte(in), &params); err != nil {
+		t.Fatalf("unable to unmarshal: %s", err)
+	}
+
+	got, err := parameters.ParseParams(params, map[string]any{"user": "alice", "api_key": "top-secret"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diff := cmp.Diff(map[string]any{"user": "alice", "api_key": "top-secret"}, got.AsMap()); diff != "" {
+		t.Fatalf("AsMap() mismatch (-want +got):\n%s", diff)
+	}
+	wantRedacted := map[string]any{"user": "alice", "api_key": parameters.RedactedValue}
+	if diff := cmp.Diff(wantRedacted, got.AsRedactedMap()); diff != "" {
+		t.Fatalf("AsRedactedMap() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]any{"alice", parameters.RedactedValue}, got.AsRedactedSlice()); diff != "" {
+		t.Fatalf("AsRedactedSlice() mismatch (-want +got):\n%s", diff)
+	}
+	dbErr := got.RedactError(errors.New(`invalid input "top-secret" for alice`))
+	if want := "invalid input [REDACTED] for alice"; dbErr.Error() != want {
+		t.Fatalf("got RedactError() %q, want %q", dbErr, want)
+	}
+
+	_, err = parameters.ParseParams(params, map[string]any{"user": "alice", "api_key": "Not A Match"}, nil)
+	if err == nil {
+		t.Fatalf("expected an error for a value not matching the pattern")
+	}
+	if strings.Contains(err.Error(), "Not A Match") || !strings.Contains(err.Error(), parameters.RedactedValue) {
+		t.Fatalf("expected the sensitive value to be redacted, got %q", err)
+	}
+
+	manifest := params.Manifest()
+	if manifest[0].Sensitive || !manifest[1].Sensitive || manifest[1].Default != nil {
+		t.Fatalf("unexpected sensitive manifest: %+v", manifest)
+	}
+	schema, _ := params.McpManifest()
+	key := schema.Properties["api_key"]
+	if !key.WriteOnly || key.Format != "password" || key.Default != nil {
+		t.Fatalf("unexpected MCP manifest for sensitive parameter: %+v", key)
+	}
+	if user := schema.Properties["user"]; user.WriteOnly || user.Format != "" {
+		t.Fatalf("unexpected MCP manifest for non-sensitive parameter: %+v", user)
+	}
+}
+
+func TestParameterExamples(t *testing.T) {
+	ctx, err := testutils.ContextWithNewLogger()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var params parameters.Parameters
+	in := `
+- name: region
+  type: string
+  description: a region
+  enum: [us, eu]
+  examples: [eu]
+- name: page_size
+  type: integer
+  description: a page size
+  minValue: 1
+  examples: [10, 100]
+- name: api_key
+  type: string
+  description: an API key
+  sensitive: true
+  examples: [abc-def]
+`
+	if err := yaml.UnmarshalContext(ctx, []byte(in), &params); err != nil {
+		t.Fatalf("unable to unmarshal: %s", err)
+	}
+
+	manifest := params.Manifest()
+	if diff := cmp.Diff([]any{"eu"}, manifest[0].Examples); diff != "" {
+		t.Fatalf("unexpected examples in manifest (-want +got):\n%s", diff)
+	}
+	if len(manifest[1].Examples) != 2 || manifest[2].Examples != nil {
+		t.Fatalf("unexpected examples in manifest: %+v", manifest)
+	}
+
+	schema, _ := params.McpManifest()
+	if diff := cmp.Diff([]any{"eu"}, schema.Properties["region"].Examples); diff != "" {
+		t.Fatalf("unexpected examples in MCP manifest (-want +got):\n%s", diff)
+	}
+	if examples := schema.Properties["api_key"].Examples; examples != nil {
+		t.Fatalf("expected the examples of a sensitive parameter to be hidden, got %v", examples)
+	}
+
+	p := parameters.WithExamples(parameters.NewStringParameter("dataset", "a dataset"), "sales")
+	if diff := cmp.Diff([]any{"sales"}, parameters.Parameters{p}.Manifest()[0].Examples); diff != "" {
+		t.Fatalf("unexpected examples set by WithExamples (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseParamsAliases(t *testing.T) {
+	params := parameters.Parameters{
+		parameters.NewStringParameterWithDefault("project", "my-project", "a project"),
+		parameters.NewStringParameter("dataset", "a dataset"),
+	}
+	for i, aliases := range [][]string{{"project_id", "projectId"}, {"dataset_id"}} {
+		if !parameters.AddAliases(params[i], aliases...) {
+			t.Fatalf("expected parameter %q to accept aliases", params[i].GetName())
+		}
+	}
+	tcs := []struct {
+		name string
+		in   map[string]any
+		want parameters.ParamValues
+	}{
+		{
+			name: "names",
+			in:   map[string]any{"project": "p", "dataset": "d"},
+			want: parameters.ParamValues{{Name: "project", Value: "p"}, {Name: "dataset", Value: "d"}},
+		},
+		{
+			name: "aliases",
+			in:   map[string]any{"projectId": "p", "dataset_id": "d"},
+			want: parameters.ParamValues{{Name: "project", Value: "p"}, {Name: "dataset", Value: "d"}},
+		},
+		{
+			name: "names take precedence",
+			in:   map[string]any{"project": "p", "project_id": "other", "dataset": "d", "dataset_id": "other"},
+			want: parameters.ParamValues{{Name: "project", Value: "p"}, {Name: "dataset", Value: "d"}},
+		},
+		{
+			name: "omitted",
+			in:   map[string]any{"dataset_id": "d"},
+			want: parameters.ParamValues{{Name: "project", Value: "my-project", Omitted: true}, {Name: "dataset", Value: "d"}},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parameters.ParseParams(params, tc.in, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("unexpected values (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, err := parameters.ParseParams(params, map[string]any{"datasetId": "d"}, nil); err == nil {
+		t.Fatalf("expected an error for a required parameter given under another name")
+	}
+	if manifest := params.Manifest(); manifest[0].Name != "project" || len(manifest) != 2 {
+		t.Fatalf("expected the aliases to be hidden from the manifest, got %+v", manifest)
+	}
+}
+
+func TestParseParamsCoerce(t *testing.T) {
+	newParams := func(coerce bool) parameters.Parameters {
+		intP := parameters.NewIntParameter("int", "an integer")
+		intP.Coerce = coerce
+		floatP := parameters.NewFloatParameter("float", "a float")
+		floatP.Coerce = coerce
+		boolP := parameters.NewBooleanParameter("bool", "a boolean")
+		boolP.Coerce = coerce
+		arrayP := parameters.NewArrayParameter("array", "some integers", parameters.NewIntParameter("item", "an integer"))
+		arrayP.Coerce = coerce
+		strP := parameters.NewStringParameter("string", "a string")
+		for _, p := range []*parameters.CommonParameter{&intP.CommonParameter, &floatP.CommonParameter, &boolP.CommonParameter, &arrayP.CommonParameter, &strP.CommonParameter} {
+			p.Required = new(bool)
+		}
+		return parameters.Parameters{intP, floatP, boolP, arrayP, strP}
+	}
+
+	tcs := []struct {
+		name    string
+		param   string
+		in      any
+		want    any
+		wantErr bool
+	}{
+		{name: "int from string", param: "int", in: "5", want: 5},
+		{name: "negative int from string", param: "int", in: "-12", want: -12},
+		{name: "int from float string", param: "int", in: "5.5", wantErr: true},
+		{name: "int from padded string", param: "int", in: " 5", wantErr: true},
+		{name: "int from hex string", param: "int", in: "0x10", wantErr: true},
+		{name: "int from word", param: "int", in: "five", wantErr: true},
+		{name: "int unchanged", param: "int", in: 7, want: 7},
+		{name: "float from string", param: "float", in: "1.5", want: 1.5},
+		{name: "float from exponent string", param: "float", in: "2e3", want: 2000.0},
+		{name: "float from int string", param: "float", in: "3", want: 3.0},
+		{name: "float from NaN", param: "float", in: "NaN", wantErr: true},
+		{name: "float from Inf", param: "float", in: "Inf", wantErr: true},
+		{name: "bool from true", param: "bool", in: "true", want: true},
+		{name: "bool from FALSE", param: "bool", in: "FALSE", want: false},
+		{name: "bool from 1", param: "bool", in: "1", wantErr: true},
+		{name: "bool from yes", param: "bool", in: "yes", wantErr: true},
+		{name: "array from JSON string", param: "array", in: "[1, 2]", want: []any{1, 2}},
+		{name: "array of strings", param: "array", in: []any{"1", 2}, want: []any{1, 2}},
+		{name: "array from JSON string of strings", param: "array", in: `["1", "2"]`, want: []any{1, 2}},
+		{name: "array from malformed JSON", param: "array", in: "[1, 2", wantErr: true},
+		{name: "array from trailing JSON", param: "array", in: "[1] [2]", wantErr: true},
+		{name: "array with malformed element", param: "array", in: []any{"one"}, wantErr: true},
+		{name: "string is never coerced", param: "string", in: "5", want: "5"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			in := map[string]any{tc.param: tc.in}
+
+			got, err := parameters.ParseParams(newParams(true), in, nil)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", got)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				if diff := cmp.Diff(tc.want, got.AsMap()[tc.param]); diff != "" {
+					t.Fatalf("unexpected value (-want +got):\n%s", diff)
+				}
+				_, isString := tc.in.(string)
+				_, isArray := tc.in.([]any)
+				wantCoerced := (isString || isArray) && tc.param != "string"
+				if gotCoerced := slices.Contains(got.Coerced(), tc.param); gotCoerced != wantCoerced {
+					t.Fatalf("got coerced %t, want %t", gotCoerced, wantCoerced)
+				}
+			}
+
+			// the default remains strict
+			if _, isString := tc.in.(string); isString && tc.param != "string" {
+				if _, err := parameters.ParseParams(newParams(false), in, nil); err == nil {
+					t.Fatalf("expected strict parsing to reject %q", tc.in)
+				}
+			}
+		})
+	}
+
+	ctx, err := testutils.ContextWithNewLogger()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var params parameters.Parameters
+	in := `
+- name: ids
+  type: array
+  description: some ids
+  coerce: true
+  items:
+    name: id
+    type: integer
+    description: an id
+`
+	if err := yaml.UnmarshalContext(ctx, []byte(in), &params); err != nil {
+		t.Fatalf("unable to unmarshal: %s", err)
+	}
+	got, err := parameters.ParseParams(params, map[string]any{"ids": `["1", 2]`}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diff := cmp.Diff([]any{1, 2}, got.AsMap()["ids"]); diff != "" {
+		t.Fatalf("unexpected value (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parameters_test
+
+import (
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/genai-toolbox/internal/testutils"
+	"github.com/googleapis/genai-toolbox/internal/util/parameters"
+)
+
+// schemaTestParameters declares a parameter of every kind, with the options
+// that change its JSON schema.
+const schemaTestParameters = `
+- name: name
+  type: string
+  description: a name
+- name: mode
+  type: string
+  description: a mode
+  enum: [fast, slow]
+  default: fast
+- name: nullable_mode
+  type: string
+  description: a mode that may be null
+  enum: [fast, slow]
+  nullable: true
+  required: false
+- name: code
+  type: string
+  description: a code
+  pattern: "^[A-Z]{3}$"
+  examples: [ABC]
+- name: password
+  type: string
+  description: a password
+  sensitive: true
+  default: hunter2
+- name: limit
+  type: integer
+  description: a limit
+  minValue: 1
+  maxValue: 100
+  default: 10
+- name: ratio
+  type: float
+  description: a ratio
+  exclusiveMinValue: 0
+  default: 0.5
+  nullable: true
+- name: verbose
+  type: boolean
+  description: whether to be verbose
+  default: false
+- name: ids
+  type: array
+  description: some ids
+  default: [1, 2]
+  items:
+    name: id
+    type: integer
+    description: an id
+    minValue: 0
+- name: scores
+  type: array
+  description: some scores
+  items:
+    name: score
+    type: float
+    description: a score
+- name: matrix
+  type: array
+  description: a matrix
+  required: false
+  items:
+    name: row
+    type: array
+    description: a row
+    items:
+      name: cell
+      type: string
+      description: a cell
+      enum: [x, o]
+- name: weights
+  type: map
+  description: some weights
+  valueType: float
+  default:
+    a: 1.5
+- name: labels
+  type: map
+  description: some labels
+  required: false
+- name: filter
+  type: object
+  description: a filter
+  default:
+    column: id
+  properties:
+  - name: column
+    type: string
+    description: a column
+  - name: limit
+    type: integer
+    description: a limit
+    default: 5
+- name: since
+  type: timestamp
+  description: a time
+  default: "2026-01-02T03:04:05Z"
+- name: day
+  type: date
+  description: a day
+  required: false
+`
+
+func TestMcpManifestJSONSchema(t *testing.T) {
+	ctx, err := testutils.ContextWithNewLogger()
+	if err != nil {
+		t.Fatalf("unable to create logger: %s", err)
+	}
+	var params parameters.Parameters
+	if err := yaml.UnmarshalContext(ctx, []byte(schemaTestParameters), &params); err != nil {
+		t.Fatalf("unable to unmarshal: %s", err)
+	}
+	schema, _ := params.McpManifest()
+	if err := testutils.ValidateJSONSchema(schema); err != nil {
+		t.Fatalf("invalid input schema: %s", err)
+	}
+
+	wantRequired := []string{"name", "code", "scores"}
+	if diff := cmp.Diff(wantRequired, schema.Required); diff != "" {
+		t.Errorf("unexpected required properties (-want +got):\n%s", diff)
+	}
+
+	// values that clients may send must be valid against the schema
+	valid := map[string]any{
+		"name":          "Alice",
+		"code":          "ABC",
+		"nullable_mode": nil,
+		"ratio":         nil,
+		"ids":           []any{1, 2, 3},
+		"scores":        []any{1, 2.5},
+		"matrix":        []any{[]any{"x", "o"}},
+		"weights":       map[string]any{"a": 0.5},
+		"labels":        map[string]any{"a": "b", "c": 1},
+		"filter":        map[string]any{"column": "id"},
+		"since":         "2026-01-02T03:04:05Z",
+		"day":           "2026-01-02",
+	}
+	if err := testutils.ValidateJSONValue(schema, valid); err != nil {
+		t.Fatalf("expected valid arguments: %s", err)
+	}
+	invalid := map[string]map[string]any{
+		"string enum":   {"mode": "medium"},
+		"array items":   {"ids": []any{"one"}},
+		"nested items":  {"matrix": []any{[]any{"y"}}},
+		"map values":    {"weights": map[string]any{"a": "heavy"}},
+		"object field":  {"filter": map[string]any{"column": 1}},
+		"null not-null": {"limit": nil},
+	}
+	for desc, args := range invalid {
+		args["name"], args["code"], args["scores"] = "Alice", "ABC", []any{}
+		if err := testutils.ValidateJSONValue(schema, args); err == nil {
+			t.Errorf("expected %s to be invalid", desc)
+		}
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"strings"
 
@@ -168,14 +169,74 @@ func WithLogger(ctx context.Context, logger log.Logger) context.Context {
 	return context.WithValue(ctx, loggerKey, logger)
 }
 
-// LoggerFromContext retrieves the logger or return an error
+// LoggerFromContext retrieves the logger or return an error. If ctx has a
+// request ID, the logger adds it to every message it logs.
 func LoggerFromContext(ctx context.Context) (log.Logger, error) {
 	if logger, ok := ctx.Value(loggerKey).(log.Logger); ok {
+		if requestID := RequestIDFromContext(ctx); requestID != "" {
+			return requestLogger{Logger: logger, requestID: requestID}, nil
+		}
 		return logger, nil
 	}
 	return nil, fmt.Errorf("unable to retrieve logger")
 }
 
+// WithLogLevel wraps the logger in ctx, if any, to log messages at level and
+// above instead of its own level.
+func WithLogLevel(ctx context.Context, level slog.Level) context.Context {
+	if logger, ok := ctx.Value(loggerKey).(log.Logger); ok {
+		return WithLogger(ctx, log.WithLevel(logger, level))
+	}
+	return ctx
+}
+
+// requestIDKey is the key used to store the ID of a request within context
+const requestIDKey contextKey = "requestID"
+
+// RequestIDLogKey is the key of the request ID in log messages.
+const RequestIDLogKey = "requestId"
+
+// WithRequestID adds the ID of the request being served into the context
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestIDFromContext retrieves the ID of the request being served, or ""
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// requestLogger is a logger that adds a request ID to the messages it logs.
+type requestLogger struct {
+	log.Logger
+	requestID string
+}
+
+func (l requestLogger) withRequestID(keysAndValues []any) []any {
+	return append(keysAndValues[:len(keysAndValues):len(keysAndValues)], RequestIDLogKey, l.requestID)
+}
+
+func (l requestLogger) DebugContext(ctx context.Context, msg string, keysAndValues ...any) {
+	l.Logger.DebugContext(ctx, msg, l.withRequestID(keysAndValues)...)
+}
+
+func (l requestLogger) InfoContext(ctx context.Context, msg string, keysAndValues ...any) {
+	l.Logger.InfoContext(ctx, msg, l.withRequestID(keysAndValues)...)
+}
+
+func (l requestLogger) WarnContext(ctx context.Context, msg string, keysAndValues ...any) {
+	l.Logger.WarnContext(ctx, msg, l.withRequestID(keysAndValues)...)
+}
+
+func (l requestLogger) ErrorContext(ctx context.Context, msg string, keysAndValues ...any) {
+	l.Logger.ErrorContext(ctx, msg, l.withRequestID(keysAndValues)...)
+}
+
+func (l requestLogger) SlogLogger() *slog.Logger {
+	return l.Logger.SlogLogger().With(RequestIDLogKey, l.requestID)
+}
+
 const instrumentationKey contextKey = "instrumentation"
 
 // WithInstrumentation adds an instrumentation into the context as a value
@@ -0,0 +1,74 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"fmt"
+	"testing"
+
+	bigqueryds "github.com/googleapis/genai-toolbox/internal/sources/bigquery"
+	"github.com/googleapis/genai-toolbox/internal/testutils"
+	"github.com/googleapis/genai-toolbox/internal/util"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// BenchmarkRunSQLResultRetrieval compares reading a 100k-row result by paging
+// through it with reading it through the Storage Read API.
+func BenchmarkRunSQLResultRetrieval(b *testing.B) {
+	if BigqueryProject == "" {
+		b.Skip("'BIGQUERY_PROJECT' not set")
+	}
+	const rows = 100000
+	ctx, err := testutils.ContextWithNewLogger()
+	if err != nil {
+		b.Fatalf("unexpected error: %s", err)
+	}
+	ctx = util.WithUserAgent(ctx, "benchmark")
+	cfg := bigqueryds.Config{
+		Name:               "my-instance",
+		Type:               bigqueryds.SourceType,
+		Project:            BigqueryProject,
+		MaxQueryResultRows: rows,
+	}
+	src, err := cfg.Initialize(ctx, noop.NewTracerProvider().Tracer(""))
+	if err != nil {
+		b.Fatalf("unable to initialize source: %s", err)
+	}
+	source := src.(*bigqueryds.Source)
+	client, _, err := source.RetrieveClientAndService(ctx, "")
+	if err != nil {
+		b.Fatalf("unable to retrieve client: %s", err)
+	}
+	statement := fmt.Sprintf("SELECT x, CONCAT('row-', CAST(x AS STRING)) AS name, RAND() AS score FROM UNNEST(GENERATE_ARRAY(1, %d)) AS x", rows)
+
+	for _, useStorageReadAPI := range []bool{false, true} {
+		name := "jobs"
+		if useStorageReadAPI {
+			name = "storage"
+		}
+		b.Run(name, func(b *testing.B) {
+			opts := bigqueryds.JobOptions{UseStorageReadAPI: &useStorageReadAPI}
+			for b.Loop() {
+				got, err := source.RunSQL(ctx, client, statement, "SELECT", nil, nil, opts)
+				if err != nil {
+					b.Fatalf("unable to run query: %s", err)
+				}
+				if n := len(got.([]any)); n != rows {
+					b.Fatalf("got %d rows, want %d", n, rows)
+				}
+			}
+		})
+	}
+}
@@ -423,7 +423,7 @@ func runListClustersTest(t *testing.T, client *dataproc.ClusterControllerClient,
 				if err := json.Unmarshal([]byte(result), &listResponse); err != nil {
 					t.Fatalf("error unmarshalling result: %s", err)
 				}
-				actual = append(actual, listResponse.Clusters...)
+				actual = append(actual, listResponse.Items...)
 				pageToken = listResponse.NextPageToken
 			}
 
@@ -733,7 +733,7 @@ func runListJobsTest(t *testing.T, client *dataproc.JobControllerClient, ctx con
 				if err := json.Unmarshal([]byte(result), &listResponse); err != nil {
 					t.Fatalf("error unmarshalling result: %s", err)
 				}
-				actual = append(actual, listResponse.Jobs...)
+				actual = append(actual, listResponse.Items...)
 				pageToken = listResponse.NextPageToken
 			}
 
@@ -737,7 +737,7 @@ func runListBatchesTest(t *testing.T, client *dataproc.BatchControllerClient, ct
 				if err := json.Unmarshal([]byte(result), &listResponse); err != nil {
 					t.Fatalf("error unmarshalling result: %s", err)
 				}
-				actual = append(actual, listResponse.Batches...)
+				actual = append(actual, listResponse.Items...)
 				pageToken = listResponse.NextPageToken
 			}
 